@@ -3,6 +3,7 @@
 protoc -I vendor -I proto \
         proto/shared.proto \
         proto/raw_file_system.proto \
+        proto/trace.proto \
         --go_opt=paths=source_relative \
         --go_out=pb \
         --go-grpc_opt=paths=source_relative \
@@ -0,0 +1,338 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package blockcache keeps blocks of files in a directory on local disk,
+// so that reading them again needs no server, also after a remount. A
+// block is known by the inode of its file, the version of the file (its
+// mtime, ctime and size) and its index: a file that changes gets a new
+// version, and the blocks of older versions are never read again.
+//
+// The directory is bounded in size; the blocks least recently used go
+// first. Use one directory per exported file system, as inode numbers
+// are only unique within one.
+package blockcache
+
+import (
+	"container/list"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultSize      = 1 << 30
+	defaultBlockSize = 1 << 20
+)
+
+// Options sizes a Cache.
+type Options struct {
+	// Size is the most bytes of blocks kept, 1GiB if zero.
+	Size int64
+	// BlockSize is the size of the blocks, 1MiB if zero. Opening a
+	// directory with another block size starts from an empty cache.
+	BlockSize int
+}
+
+func (o *Options) withDefaults() Options {
+	var opts Options
+	if o != nil {
+		opts = *o
+	}
+	if opts.Size <= 0 {
+		opts.Size = defaultSize
+	}
+	if opts.BlockSize <= 0 {
+		opts.BlockSize = defaultBlockSize
+	}
+	return opts
+}
+
+// Key is a version of a file.
+type Key struct {
+	Ino  uint64
+	Size uint64
+	// Mtime and Ctime are in nanoseconds since the epoch.
+	Mtime, Ctime int64
+}
+
+func (k Key) String() string {
+	return fmt.Sprintf("%x-%x-%x-%x", k.Ino, k.Size, k.Mtime, k.Ctime)
+}
+
+// Stats are the blocks a Cache found and missed since opened, and what it
+// holds.
+type Stats struct {
+	Hits, Misses int64
+	Blocks       int
+	Bytes        int64
+}
+
+type entry struct {
+	name string
+	key  Key
+	size int64
+}
+
+// Cache is a directory of blocks. It is safe for concurrent use.
+type Cache struct {
+	dir       string
+	size      int64
+	blockSize int
+
+	mu sync.Mutex
+	// lru holds the *entry of every block, the most recently used first.
+	lru     *list.List
+	entries map[string]*list.Element
+	// inos holds the names of the blocks of each inode.
+	inos   map[uint64]map[string]bool
+	bytes  int64
+	hits   int64
+	misses int64
+}
+
+// Open opens the cache in dir, creating it if needed, with the blocks
+// left there by an earlier Cache.
+func Open(dir string, opts *Options) (*Cache, error) {
+	o := opts.withDefaults()
+	c := &Cache{
+		dir:       filepath.Join(dir, fmt.Sprintf("blocks-%d", o.BlockSize)),
+		size:      o.Size,
+		blockSize: o.BlockSize,
+		lru:       list.New(),
+		entries:   map[string]*list.Element{},
+		inos:      map[uint64]map[string]bool{},
+	}
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return nil, fmt.Errorf("blockcache: %v", err)
+	}
+	// Blocks of another block size are of no use.
+	if others, err := filepath.Glob(filepath.Join(dir, "blocks-*")); err == nil {
+		for _, other := range others {
+			if other != c.dir {
+				os.RemoveAll(other)
+			}
+		}
+	}
+	if err := c.load(); err != nil {
+		return nil, fmt.Errorf("blockcache: %v", err)
+	}
+	c.mu.Lock()
+	c.evict()
+	c.mu.Unlock()
+	return c, nil
+}
+
+// load reads the blocks in the directory, in the order they were last
+// used.
+func (c *Cache) load() error {
+	type found struct {
+		entry
+		used time.Time
+	}
+	var blocks []found
+	err := filepath.Walk(c.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		name, err := filepath.Rel(c.dir, path)
+		if err != nil {
+			return err
+		}
+		key, _, ok := parseName(name)
+		if !ok {
+			// A block left half written.
+			os.Remove(path)
+			return nil
+		}
+		blocks = append(blocks, found{entry{name: name, key: key, size: info.Size()}, info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].used.Before(blocks[j].used) })
+	for _, b := range blocks {
+		e := b.entry
+		c.add(&e)
+	}
+	return nil
+}
+
+// blockName is where block index of key is, relative to the directory.
+func blockName(key Key, index uint64) string {
+	return filepath.Join(fmt.Sprintf("%02x", key.Ino&0xff), fmt.Sprintf("%s.%d", key, index))
+}
+
+func parseName(name string) (Key, uint64, bool) {
+	base := filepath.Base(name)
+	dot := strings.LastIndexByte(base, '.')
+	if dot < 0 {
+		return Key{}, 0, false
+	}
+	index, err := strconv.ParseUint(base[dot+1:], 10, 64)
+	if err != nil {
+		return Key{}, 0, false
+	}
+	var key Key
+	if n, err := fmt.Sscanf(base[:dot], "%x-%x-%x-%x", &key.Ino, &key.Size, &key.Mtime, &key.Ctime); err != nil || n != 4 {
+		return Key{}, 0, false
+	}
+	if blockName(key, index) != name {
+		return Key{}, 0, false
+	}
+	return key, index, true
+}
+
+// BlockSize is the size of the blocks of the cache; only the last block
+// of a file is shorter.
+func (c *Cache) BlockSize() int {
+	return c.blockSize
+}
+
+// Get returns block index of key, if cached.
+func (c *Cache) Get(key Key, index uint64) ([]byte, bool) {
+	name := blockName(key, index)
+	c.mu.Lock()
+	elem, ok := c.entries[name]
+	if !ok {
+		c.misses++
+		c.mu.Unlock()
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	c.mu.Unlock()
+
+	path := filepath.Join(c.dir, name)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.Warnf("blockcache: %v", err)
+		c.mu.Lock()
+		c.misses++
+		c.remove(name)
+		c.mu.Unlock()
+		return nil, false
+	}
+	// The modification time of a block is when it was last used, for
+	// the next Open.
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	c.mu.Lock()
+	c.hits++
+	c.mu.Unlock()
+	return data, true
+}
+
+// Put stores block index of key.
+func (c *Cache) Put(key Key, index uint64, data []byte) error {
+	if int64(len(data)) > c.size {
+		return nil
+	}
+	name := blockName(key, index)
+	path := filepath.Join(c.dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("blockcache: %v", err)
+	}
+	// Written aside and renamed, so that a crash leaves no torn block.
+	f, err := ioutil.TempFile(filepath.Dir(path), ".tmp")
+	if err != nil {
+		return fmt.Errorf("blockcache: %v", err)
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("blockcache: %v", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[name]; ok {
+		c.bytes -= elem.Value.(*entry).size
+		c.lru.Remove(elem)
+	}
+	c.add(&entry{name: name, key: key, size: int64(len(data))})
+	c.evict()
+	return nil
+}
+
+// Drop removes the blocks of the other versions of the file of key.
+func (c *Cache) Drop(key Key) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name := range c.inos[key.Ino] {
+		if c.entries[name].Value.(*entry).key != key {
+			c.remove(name)
+		}
+	}
+}
+
+// add adds e as the block most recently used. c.mu must be held.
+func (c *Cache) add(e *entry) {
+	c.entries[e.name] = c.lru.PushFront(e)
+	c.bytes += e.size
+	names := c.inos[e.key.Ino]
+	if names == nil {
+		names = map[string]bool{}
+		c.inos[e.key.Ino] = names
+	}
+	names[e.name] = true
+}
+
+// evict removes the blocks least recently used until the cache fits.
+// c.mu must be held.
+func (c *Cache) evict() {
+	for c.bytes > c.size {
+		c.remove(c.lru.Back().Value.(*entry).name)
+	}
+}
+
+// remove removes the block name. c.mu must be held.
+func (c *Cache) remove(name string) {
+	elem, ok := c.entries[name]
+	if !ok {
+		return
+	}
+	e := elem.Value.(*entry)
+	c.lru.Remove(elem)
+	delete(c.entries, name)
+	if delete(c.inos[e.key.Ino], name); len(c.inos[e.key.Ino]) == 0 {
+		delete(c.inos, e.key.Ino)
+	}
+	c.bytes -= e.size
+	if err := os.Remove(filepath.Join(c.dir, name)); err != nil && !os.IsNotExist(err) {
+		log.Warnf("blockcache: %v", err)
+	}
+}
+
+// Stats returns the hits and misses so far and the size of the cache.
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Stats{Hits: c.hits, Misses: c.misses, Blocks: len(c.entries), Bytes: c.bytes}
+}
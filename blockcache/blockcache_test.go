@@ -0,0 +1,111 @@
+package blockcache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseName(t *testing.T) {
+	key := Key{Ino: 0x1234, Size: 100, Mtime: 1650000000123456789, Ctime: -5}
+	tests := []struct {
+		name  string
+		key   Key
+		index uint64
+		ok    bool
+	}{
+		{name: blockName(key, 7), key: key, index: 7, ok: true},
+		{name: blockName(Key{}, 0), key: Key{}, ok: true},
+		{name: "34/.tmp123"},
+		{name: "34/1234-64-1-1"},
+		{name: "34/1234-64-1.1"},
+		{name: "35/1234-64-1-1.1"},
+		{name: "34/1234-64-1-1.x"},
+	}
+	for _, tt := range tests {
+		key, index, ok := parseName(tt.name)
+		assert.Equal(t, tt.ok, ok, tt.name)
+		assert.Equal(t, tt.key, key, tt.name)
+		assert.Equal(t, tt.index, index, tt.name)
+	}
+}
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	c, err := Open(dir, &Options{Size: 12, BlockSize: 4})
+	require.NoError(t, err)
+	assert.Equal(t, 4, c.BlockSize())
+
+	v1 := Key{Ino: 1, Size: 6, Mtime: 1, Ctime: 1}
+	v2 := Key{Ino: 1, Size: 6, Mtime: 2, Ctime: 2}
+	_, ok := c.Get(v1, 0)
+	assert.False(t, ok)
+	require.NoError(t, c.Put(v1, 0, []byte("abcd")))
+	require.NoError(t, c.Put(v1, 1, []byte("ef")))
+	data, ok := c.Get(v1, 1)
+	require.True(t, ok)
+	assert.Equal(t, "ef", string(data))
+	assert.Equal(t, Stats{Hits: 1, Misses: 1, Blocks: 2, Bytes: 6}, c.Stats())
+
+	// Versions do not mix, and a new one drops the others.
+	_, ok = c.Get(v2, 0)
+	assert.False(t, ok)
+	require.NoError(t, c.Put(v2, 0, []byte("ABCD")))
+	c.Drop(v2)
+	_, ok = c.Get(v1, 0)
+	assert.False(t, ok)
+	data, ok = c.Get(v2, 0)
+	require.True(t, ok)
+	assert.Equal(t, "ABCD", string(data))
+
+	// The block least recently used goes first.
+	other := Key{Ino: 2}
+	require.NoError(t, c.Put(other, 0, []byte("0123")))
+	require.NoError(t, c.Put(other, 1, []byte("4567")))
+	_, ok = c.Get(v2, 0)
+	require.True(t, ok)
+	require.NoError(t, c.Put(other, 2, []byte("89")))
+	_, ok = c.Get(other, 0)
+	assert.False(t, ok)
+	for _, index := range []uint64{1, 2} {
+		_, ok = c.Get(other, index)
+		assert.True(t, ok, index)
+	}
+	assert.Equal(t, 10, int(c.Stats().Bytes))
+
+	// Blocks larger than the cache are not kept.
+	require.NoError(t, c.Put(Key{Ino: 3}, 0, make([]byte, 13)))
+	_, ok = c.Get(Key{Ino: 3}, 0)
+	assert.False(t, ok)
+
+	// The blocks outlive the cache, but not a half written one.
+	tmp := filepath.Join(c.dir, "02", ".tmp123")
+	require.NoError(t, ioutil.WriteFile(tmp, []byte("torn"), 0600))
+	c, err = Open(dir, &Options{Size: 12, BlockSize: 4})
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Blocks: 3, Bytes: 10}, c.Stats())
+	data, ok = c.Get(other, 1)
+	require.True(t, ok)
+	assert.Equal(t, "4567", string(data))
+	_, err = os.Stat(tmp)
+	assert.True(t, os.IsNotExist(err))
+
+	// Nor a change of block size.
+	c, err = Open(dir, &Options{Size: 12, BlockSize: 8})
+	require.NoError(t, err)
+	assert.Equal(t, Stats{}, c.Stats())
+	entries, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestOpenErrors(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, ioutil.WriteFile(file, nil, 0600))
+	_, err := Open(file, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"syscall"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+
+	"github.com/chiyutianyi/grpcfuse/fuse2grpc"
+	"github.com/chiyutianyi/grpcfuse/memfs"
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+// newBackend returns the server of an export.
+func newBackend(cfg ExportConfig) (pb.RawFileSystemServer, error) {
+	opts := &fs.Options{
+		AttrTimeout:  &cfg.AttrTimeout,
+		EntryTimeout: &cfg.EntryTimeout,
+		// Leave file permissions on "000" files as-is
+		NullPermissions: true,
+	}
+	var rfs fuse.RawFileSystem
+	switch cfg.Backend {
+	case "loopback":
+		root, err := fs.NewLoopbackRoot(cfg.Path)
+		if err != nil {
+			return nil, fmt.Errorf("export %s: %v", cfg.Name, err)
+		}
+		rfs = fs.NewNodeFS(root, opts)
+	case "memfs":
+		rfs = memfs.New(&memfs.Options{Capacity: cfg.Capacity, MaxInodes: cfg.MaxInodes, BlockSize: cfg.BlockSize}, opts)
+	default:
+		return nil, fmt.Errorf("export %s: unknown backend %q", cfg.Name, cfg.Backend)
+	}
+	if cfg.ReadOnly {
+		rfs = &readOnlyFS{rfs}
+	}
+	srv := fuse2grpc.NewServer(rfs)
+	if cfg.MsgSizeThreshold > 0 {
+		srv.SetMsgSizeThreshold(cfg.MsgSizeThreshold)
+	}
+	srv.SetInlineSize(cfg.InlineSize)
+	return srv, nil
+}
+
+// readOnlyFS fails the calls that change a file system with EROFS.
+type readOnlyFS struct {
+	fuse.RawFileSystem
+}
+
+var erofs = fuse.Status(syscall.EROFS)
+
+func (readOnlyFS) SetAttr(cancel <-chan struct{}, input *fuse.SetAttrIn, out *fuse.AttrOut) fuse.Status {
+	return erofs
+}
+
+func (readOnlyFS) Mknod(cancel <-chan struct{}, input *fuse.MknodIn, name string, out *fuse.EntryOut) fuse.Status {
+	return erofs
+}
+
+func (readOnlyFS) Mkdir(cancel <-chan struct{}, input *fuse.MkdirIn, name string, out *fuse.EntryOut) fuse.Status {
+	return erofs
+}
+
+func (readOnlyFS) Unlink(cancel <-chan struct{}, header *fuse.InHeader, name string) fuse.Status {
+	return erofs
+}
+
+func (readOnlyFS) Rmdir(cancel <-chan struct{}, header *fuse.InHeader, name string) fuse.Status {
+	return erofs
+}
+
+func (readOnlyFS) Rename(cancel <-chan struct{}, input *fuse.RenameIn, oldName string, newName string) fuse.Status {
+	return erofs
+}
+
+func (readOnlyFS) Link(cancel <-chan struct{}, input *fuse.LinkIn, filename string, out *fuse.EntryOut) fuse.Status {
+	return erofs
+}
+
+func (readOnlyFS) Symlink(cancel <-chan struct{}, header *fuse.InHeader, pointedTo string, linkName string, out *fuse.EntryOut) fuse.Status {
+	return erofs
+}
+
+func (readOnlyFS) SetXAttr(cancel <-chan struct{}, input *fuse.SetXAttrIn, attr string, data []byte) fuse.Status {
+	return erofs
+}
+
+func (readOnlyFS) RemoveXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string) fuse.Status {
+	return erofs
+}
+
+func (readOnlyFS) Create(cancel <-chan struct{}, input *fuse.CreateIn, name string, out *fuse.CreateOut) fuse.Status {
+	return erofs
+}
+
+func (r *readOnlyFS) Open(cancel <-chan struct{}, input *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
+	if input.Flags&(syscall.O_WRONLY|syscall.O_RDWR|syscall.O_TRUNC) != 0 {
+		return erofs
+	}
+	return r.RawFileSystem.Open(cancel, input, out)
+}
+
+func (readOnlyFS) Write(cancel <-chan struct{}, input *fuse.WriteIn, data []byte) (uint32, fuse.Status) {
+	return 0, erofs
+}
+
+func (readOnlyFS) CopyFileRange(cancel <-chan struct{}, input *fuse.CopyFileRangeIn) (uint32, fuse.Status) {
+	return 0, erofs
+}
+
+func (readOnlyFS) Fallocate(cancel <-chan struct{}, input *fuse.FallocateIn) fuse.Status {
+	return erofs
+}
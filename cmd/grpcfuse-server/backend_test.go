@@ -0,0 +1,65 @@
+package main
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chiyutianyi/grpcfuse/memfs"
+)
+
+func TestReadOnly(t *testing.T) {
+	backend := memfs.New(nil, nil)
+	root := fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}
+	var create fuse.CreateOut
+	require.Equal(t, fuse.OK, backend.Create(nil, &fuse.CreateIn{InHeader: root, Flags: syscall.O_RDWR, Mode: 0644}, "file", &create))
+	file := fuse.InHeader{NodeId: create.NodeId}
+	rfs := &readOnlyFS{backend}
+
+	var entry fuse.EntryOut
+	var attr fuse.AttrOut
+	var open fuse.OpenOut
+	tests := []struct {
+		name string
+		call func() fuse.Status
+		want fuse.Status
+	}{
+		{name: "lookup", call: func() fuse.Status { return rfs.Lookup(nil, &root, "file", &entry) }, want: fuse.OK},
+		{name: "open", call: func() fuse.Status { return rfs.Open(nil, &fuse.OpenIn{InHeader: file}, &open) }, want: fuse.OK},
+		{name: "open for writing", call: func() fuse.Status { return rfs.Open(nil, &fuse.OpenIn{InHeader: file, Flags: syscall.O_RDWR}, &open) }, want: erofs},
+		{name: "truncate", call: func() fuse.Status { return rfs.Open(nil, &fuse.OpenIn{InHeader: file, Flags: syscall.O_TRUNC}, &open) }, want: erofs},
+		{name: "create", call: func() fuse.Status { return rfs.Create(nil, &fuse.CreateIn{InHeader: root}, "new", &create) }, want: erofs},
+		{name: "mkdir", call: func() fuse.Status { return rfs.Mkdir(nil, &fuse.MkdirIn{InHeader: root}, "dir", &entry) }, want: erofs},
+		{name: "unlink", call: func() fuse.Status { return rfs.Unlink(nil, &root, "file") }, want: erofs},
+		{name: "rename", call: func() fuse.Status {
+			return rfs.Rename(nil, &fuse.RenameIn{InHeader: root, Newdir: fuse.FUSE_ROOT_ID}, "file", "new")
+		}, want: erofs},
+		{name: "setattr", call: func() fuse.Status {
+			return rfs.SetAttr(nil, &fuse.SetAttrIn{SetAttrInCommon: fuse.SetAttrInCommon{InHeader: file}}, &attr)
+		}, want: erofs},
+		{name: "write", call: func() fuse.Status {
+			_, st := rfs.Write(nil, &fuse.WriteIn{InHeader: file, Fh: create.Fh}, []byte("x"))
+			return st
+		}, want: erofs},
+		{name: "setxattr", call: func() fuse.Status { return rfs.SetXAttr(nil, &fuse.SetXAttrIn{InHeader: file}, "user.a", nil) }, want: erofs},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.call())
+		})
+	}
+}
+
+func TestNewBackend(t *testing.T) {
+	_, err := newBackend(ExportConfig{Name: "a", Backend: "memfs", MsgSizeThreshold: 100, InlineSize: 4096})
+	assert.NoError(t, err)
+	_, err = newBackend(ExportConfig{Name: "a", Backend: "loopback", Path: t.TempDir(), ReadOnly: true})
+	assert.NoError(t, err)
+	_, err = newBackend(ExportConfig{Name: "a", Backend: "loopback", Path: "/nonexistent"})
+	assert.Error(t, err)
+	_, err = newBackend(ExportConfig{Name: "a", Backend: "nfs"})
+	assert.Error(t, err)
+}
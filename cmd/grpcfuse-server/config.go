@@ -0,0 +1,240 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/chiyutianyi/grpcfuse/exports"
+)
+
+// Config is the configuration file of the server.
+type Config struct {
+	Listeners []ListenerConfig `yaml:"listeners"`
+	// Exports are the file systems served. Calls that name no export go
+	// to the first one.
+	Exports      []ExportConfig    `yaml:"exports"`
+	TLS          *TLSConfig        `yaml:"tls"`
+	Interceptors InterceptorConfig `yaml:"interceptors"`
+	Log          LogConfig         `yaml:"log"`
+	Metrics      MetricsConfig     `yaml:"metrics"`
+	// PeerCredentials checks the users connecting over Unix sockets.
+	PeerCredentials *PeerCredConfig `yaml:"peer_credentials"`
+	// ShutdownTimeout is how long calls in flight have to finish on
+	// shutdown before they are cut.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+// ListenerConfig is an address the server listens on.
+type ListenerConfig struct {
+	// Network is "tcp" or "unix".
+	Network string `yaml:"network"`
+	// Address is host:port, or the path of a Unix socket.
+	Address string `yaml:"address"`
+	// Mode is the permission of a Unix socket, such as 0660.
+	Mode FileMode `yaml:"mode"`
+	// SharedMemory takes the memfds of clients on a Unix socket next to
+	// this one, named Address + ".shm", to move Read and Write data
+	// through them.
+	SharedMemory bool `yaml:"shared_memory"`
+}
+
+func (l ListenerConfig) String() string {
+	return l.Network + ":" + l.Address
+}
+
+// FileMode is a permission written in octal.
+type FileMode os.FileMode
+
+// UnmarshalYAML reads 0660 and "0660" alike as octal.
+func (m *FileMode) UnmarshalYAML(node *yaml.Node) error {
+	v, err := strconv.ParseUint(node.Value, 8, 32)
+	if err != nil || v&^uint64(os.ModePerm) != 0 {
+		return fmt.Errorf("line %d: bad mode %q", node.Line, node.Value)
+	}
+	*m = FileMode(v)
+	return nil
+}
+
+// ExportConfig is a file system served under a name.
+type ExportConfig struct {
+	Name string `yaml:"name"`
+	// Backend is "loopback", serving the directory Path, or "memfs".
+	Backend  string `yaml:"backend"`
+	Path     string `yaml:"path"`
+	ReadOnly bool   `yaml:"read_only"`
+	// MsgSizeThreshold is the size above which replies are streamed, 1MiB
+	// if zero.
+	MsgSizeThreshold int `yaml:"msg_size_threshold"`
+	// InlineSize is the size up to which read-only opens return the
+	// file, for clients to read small files without a Read; 0 returns
+	// none.
+	InlineSize int `yaml:"inline_size"`
+	// AttrTimeout and EntryTimeout are how long clients may cache
+	// attributes and names.
+	AttrTimeout  time.Duration `yaml:"attr_timeout"`
+	EntryTimeout time.Duration `yaml:"entry_timeout"`
+	// Capacity, MaxInodes and BlockSize are the memfs.Options of a memfs
+	// export.
+	Capacity  uint64 `yaml:"capacity"`
+	MaxInodes uint64 `yaml:"max_inodes"`
+	BlockSize uint32 `yaml:"block_size"`
+}
+
+// TLSConfig turns on TLS, and client certificates if ClientCA is set.
+type TLSConfig struct {
+	Cert     string `yaml:"cert"`
+	Key      string `yaml:"key"`
+	ClientCA string `yaml:"client_ca"`
+}
+
+// PeerCredConfig is a peercred.Policy.
+type PeerCredConfig struct {
+	// AllowedUIDs are the users that may connect; empty allows all.
+	AllowedUIDs []uint32 `yaml:"allowed_uids"`
+	// TrustedUIDs may make calls for other users, as a mount does; calls
+	// of the others are made as themselves. Root if not set.
+	TrustedUIDs []uint32 `yaml:"trusted_uids"`
+}
+
+// InterceptorConfig chooses the interceptors of every call.
+type InterceptorConfig struct {
+	// Logging logs every call.
+	Logging bool `yaml:"logging"`
+	// Recovery turns panics of the backends into errors. On by default.
+	Recovery bool `yaml:"recovery"`
+	// Record records every call to this rpctrace file.
+	Record string `yaml:"record"`
+}
+
+// LogConfig sets up logrus.
+type LogConfig struct {
+	Level string `yaml:"level"`
+	// Format is "text" or "json".
+	Format string `yaml:"format"`
+}
+
+// MetricsConfig serves Prometheus metrics over HTTP.
+type MetricsConfig struct {
+	// Address is the host:port of the metrics; none are kept if empty.
+	Address string `yaml:"address"`
+	Path    string `yaml:"path"`
+	// Histogram keeps the latencies of calls. Once on, it stays on until
+	// restart.
+	Histogram bool `yaml:"histogram"`
+}
+
+func defaultConfig() *Config {
+	return &Config{
+		Interceptors:    InterceptorConfig{Recovery: true},
+		Log:             LogConfig{Level: "info", Format: "text"},
+		Metrics:         MetricsConfig{Path: "/metrics"},
+		ShutdownTimeout: 30 * time.Second,
+	}
+}
+
+// parseConfig parses and checks a configuration file.
+func parseConfig(data []byte) (*Config, error) {
+	cfg := defaultConfig()
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func loadConfig(file string) (*Config, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := parseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	return cfg, nil
+}
+
+func (cfg *Config) validate() error {
+	if len(cfg.Listeners) == 0 {
+		return fmt.Errorf("no listeners")
+	}
+	seen := map[ListenerConfig]bool{}
+	for _, l := range cfg.Listeners {
+		switch {
+		case l.Network != "tcp" && l.Network != "unix":
+			return fmt.Errorf("listener %s: network must be tcp or unix", l)
+		case l.Address == "":
+			return fmt.Errorf("listener %s: no address", l)
+		case l.Mode != 0 && l.Network != "unix":
+			return fmt.Errorf("listener %s: mode is for unix sockets", l)
+		case l.SharedMemory && l.Network != "unix":
+			return fmt.Errorf("listener %s: shared_memory is for unix sockets", l)
+		case seen[ListenerConfig{Network: l.Network, Address: l.Address}]:
+			return fmt.Errorf("listener %s: listed twice", l)
+		}
+		seen[ListenerConfig{Network: l.Network, Address: l.Address}] = true
+	}
+
+	if len(cfg.Exports) == 0 {
+		return fmt.Errorf("no exports")
+	}
+	names := map[string]bool{}
+	for i := range cfg.Exports {
+		e := &cfg.Exports[i]
+		e.Name = exports.Name(e.Name)
+		switch {
+		case e.Name == "":
+			return fmt.Errorf("export %d: no name", i)
+		case names[e.Name]:
+			return fmt.Errorf("export %s: listed twice", e.Name)
+		case e.Backend == "loopback" && e.Path == "":
+			return fmt.Errorf("export %s: no path", e.Name)
+		case e.Backend != "loopback" && e.Backend != "memfs":
+			return fmt.Errorf("export %s: backend must be loopback or memfs", e.Name)
+		case e.MsgSizeThreshold < 0:
+			return fmt.Errorf("export %s: negative msg_size_threshold", e.Name)
+		case e.InlineSize < 0:
+			return fmt.Errorf("export %s: negative inline_size", e.Name)
+		}
+		names[e.Name] = true
+	}
+
+	if cfg.PeerCredentials != nil && cfg.PeerCredentials.TrustedUIDs == nil {
+		cfg.PeerCredentials.TrustedUIDs = []uint32{0}
+	}
+	if cfg.TLS != nil && (cfg.TLS.Cert == "" || cfg.TLS.Key == "") {
+		return fmt.Errorf("tls: cert and key are needed")
+	}
+	switch cfg.Log.Format {
+	case "text", "json":
+	default:
+		return fmt.Errorf("log: format must be text or json")
+	}
+	if cfg.Metrics.Address != "" && cfg.Metrics.Path == "" {
+		return fmt.Errorf("metrics: no path")
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExampleConfig(t *testing.T) {
+	cfg, err := loadConfig("example.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, []ListenerConfig{
+		{Network: "tcp", Address: "127.0.0.1:8760"},
+		{Network: "unix", Address: "/run/grpcfuse.sock", Mode: 0660, SharedMemory: true},
+	}, cfg.Listeners)
+	assert.Equal(t, ExportConfig{Name: "data", Backend: "loopback", Path: "/srv/data", ReadOnly: true, InlineSize: 4096, AttrTimeout: time.Second, EntryTimeout: time.Second}, cfg.Exports[0])
+	assert.Equal(t, ExportConfig{Name: "scratch", Backend: "memfs", Capacity: 1 << 30, MaxInodes: 100000}, cfg.Exports[1])
+	assert.Nil(t, cfg.TLS)
+	assert.Equal(t, InterceptorConfig{Recovery: true}, cfg.Interceptors)
+	assert.Equal(t, MetricsConfig{Address: "127.0.0.1:9760", Path: "/metrics", Histogram: true}, cfg.Metrics)
+	assert.Equal(t, 30*time.Second, cfg.ShutdownTimeout)
+
+	_, err = loadConfig("nope.yaml")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+// conf is a configuration with a listener and an export unless others
+// are given.
+func conf(listeners, exports, rest string) []byte {
+	if listeners == "" {
+		listeners = "[{network: tcp, address: ':8760'}]"
+	}
+	if exports == "" {
+		exports = "[{name: a, backend: memfs}]"
+	}
+	return []byte("listeners: " + listeners + "\nexports: " + exports + "\n" + rest)
+}
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := parseConfig(conf("", "", ""))
+	require.NoError(t, err)
+	assert.Equal(t, InterceptorConfig{Recovery: true}, cfg.Interceptors)
+	assert.Equal(t, LogConfig{Level: "info", Format: "text"}, cfg.Log)
+
+	cfg, err = parseConfig(conf("[{network: unix, address: /s, mode: '600'}]", "[{name: /a/, backend: memfs}, {name: b, backend: loopback, path: /}]", ""))
+	require.NoError(t, err)
+	assert.Equal(t, "a", cfg.Exports[0].Name)
+	assert.Equal(t, FileMode(0600), cfg.Listeners[0].Mode)
+
+	tests := []struct {
+		name                       string
+		listeners, exports, config string
+		err                        string
+	}{
+		{name: "syntax", config: "tls: [", err: "yaml"},
+		{name: "no listeners", listeners: "[]", err: "no listeners"},
+		{name: "bad network", listeners: "[{network: udp, address: ':1'}]", err: "network must be tcp or unix"},
+		{name: "no address", listeners: "[{network: tcp}]", err: "no address"},
+		{name: "tcp mode", listeners: "[{network: tcp, address: ':1', mode: 0600}]", err: "mode is for unix"},
+		{name: "bad mode", listeners: "[{network: unix, address: /s, mode: 0999}]", err: "bad mode"},
+		{name: "tcp shared memory", listeners: "[{network: tcp, address: ':1', shared_memory: true}]", err: "shared_memory is for unix"},
+		{name: "listener twice", listeners: "[{network: tcp, address: ':1'}, {network: tcp, address: ':1', mode: 0}]", err: "listed twice"},
+		{name: "no exports", exports: "[]", err: "no exports"},
+		{name: "no name", exports: "[{name: /, backend: memfs}]", err: "no name"},
+		{name: "export twice", exports: "[{name: a, backend: memfs}, {name: a/, backend: memfs}]", err: "listed twice"},
+		{name: "no path", exports: "[{name: a, backend: loopback}]", err: "no path"},
+		{name: "bad backend", exports: "[{name: a, backend: nfs}]", err: "backend must be"},
+		{name: "threshold", exports: "[{name: a, backend: memfs, msg_size_threshold: -1}]", err: "negative"},
+		{name: "inline size", exports: "[{name: a, backend: memfs, inline_size: -1}]", err: "negative inline_size"},
+		{name: "tls", config: "tls: {cert: c.pem}", err: "cert and key"},
+		{name: "log format", config: "log: {format: xml}", err: "text or json"},
+		{name: "metrics path", config: "metrics: {address: ':1', path: ''}", err: "no path"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseConfig(conf(tt.listeners, tt.exports, tt.config))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestPeerCredConfig(t *testing.T) {
+	cfg, err := parseConfig(conf("", "", ""))
+	require.NoError(t, err)
+	assert.Nil(t, cfg.PeerCredentials)
+
+	cfg, err = parseConfig(conf("[{network: unix, address: /s, shared_memory: true}]", "", "peer_credentials: {allowed_uids: [1000, 1001]}"))
+	require.NoError(t, err)
+	assert.True(t, cfg.Listeners[0].SharedMemory)
+	assert.Equal(t, &PeerCredConfig{AllowedUIDs: []uint32{1000, 1001}, TrustedUIDs: []uint32{0}}, cfg.PeerCredentials)
+
+	cfg, err = parseConfig(conf("", "", "peer_credentials: {trusted_uids: []}"))
+	require.NoError(t, err)
+	assert.Empty(t, cfg.PeerCredentials.TrustedUIDs)
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Command grpcfuse-server serves file systems to grpc2fuse clients, as set
+// up by a configuration file:
+//
+//	listeners:
+//	  - network: tcp
+//	    address: 0.0.0.0:8760
+//	  - network: unix
+//	    address: /run/grpcfuse.sock
+//	    mode: 0660
+//	exports:
+//	  - name: data
+//	    backend: loopback
+//	    path: /srv/data
+//	    read_only: true
+//	  - name: scratch
+//	    backend: memfs
+//	    capacity: 1073741824
+//
+// SIGHUP reloads the file; SIGINT and SIGTERM shut down once the calls in
+// flight are done.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	grpc_logrus "github.com/grpc-ecosystem/go-grpc-middleware/logging/logrus"
+	log "github.com/sirupsen/logrus"
+)
+
+// run runs the server until a signal on sigCh asks it to stop, and returns
+// the exit code.
+func run(args []string, stderr io.Writer, sigCh <-chan os.Signal, ready func(*server)) int {
+	flags := flag.NewFlagSet("grpcfuse-server", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	config := flags.String("config", "/etc/grpcfuse/server.yaml", "configuration file")
+	check := flags.Bool("check", false, "check the configuration file and exit")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return 2
+	}
+
+	cfg, err := loadConfig(*config)
+	if err != nil {
+		fmt.Fprintf(stderr, "grpcfuse-server: %v\n", err)
+		return 1
+	}
+	if *check {
+		return 0
+	}
+
+	s := newServer()
+	if err := s.apply(cfg); err != nil {
+		fmt.Fprintf(stderr, "grpcfuse-server: %v\n", err)
+		s.shutdown()
+		return 1
+	}
+	if ready != nil {
+		ready(s)
+	}
+	for sig := range sigCh {
+		if sig != syscall.SIGHUP {
+			log.Infof("%v, shutting down", sig)
+			s.shutdown()
+			log.Info("Shutdown")
+			return 0
+		}
+		cfg, err := loadConfig(*config)
+		if err == nil {
+			err = s.apply(cfg)
+		}
+		if err != nil {
+			log.Errorf("Reload: %v", err)
+			continue
+		}
+		log.Infof("Reloaded %s", *config)
+	}
+	s.shutdown()
+	return 0
+}
+
+func main() {
+	grpc_logrus.ReplaceGrpcLogger(log.NewEntry(log.StandardLogger()))
+	signal.Ignore(syscall.SIGPIPE)
+	sigCh := make(chan os.Signal, 10)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	os.Exit(run(os.Args[1:], os.Stderr, sigCh, nil))
+}
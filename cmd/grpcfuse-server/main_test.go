@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	sock, config := filepath.Join(dir, "sock"), filepath.Join(dir, "server.yaml")
+	write := func(exports string) {
+		require.NoError(t, ioutil.WriteFile(config, []byte("listeners: [{network: unix, address: "+sock+"}]\nexports: "+exports+"\n"), 0644))
+	}
+	write("[{name: a, backend: memfs}]")
+
+	var stderr bytes.Buffer
+	assert.Equal(t, 0, run([]string{"-config", config, "-check"}, &stderr, nil, nil))
+	assert.Equal(t, 1, run([]string{"-config", filepath.Join(dir, "nope"), "-check"}, &stderr, nil, nil))
+	assert.Equal(t, 2, run([]string{"-config", config, "extra"}, &stderr, nil, nil))
+
+	sigCh := make(chan os.Signal)
+	ready := make(chan *server)
+	code := make(chan int)
+	go func() {
+		code <- run([]string{"-config", config}, &stderr, sigCh, func(s *server) { ready <- s })
+	}()
+	s := <-ready
+	require.NoError(t, dial(t, "unix:"+sock, "a").Mkdir("dir", 0755))
+
+	// A bad file is not loaded.
+	write("[]")
+	sigCh <- syscall.SIGHUP
+	_, err := dial(t, "unix:"+sock, "a").Stat("dir")
+	assert.NoError(t, err)
+
+	write("[{name: a, backend: memfs}, {name: b, backend: memfs}]")
+	sigCh <- syscall.SIGHUP
+	// The reload is done once the signal loop takes the next signal.
+	sigCh <- syscall.SIGHUP
+	assert.ElementsMatch(t, []string{"a", "b"}, s.router.Names())
+	_, err = dial(t, "unix:"+sock, "a").Stat("dir")
+	assert.NoError(t, err)
+
+	sigCh <- syscall.SIGTERM
+	assert.Equal(t, 0, <-code)
+	_, err = os.Stat(sock)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,395 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	grpc_logrus "github.com/grpc-ecosystem/go-grpc-middleware/logging/logrus"
+	grpc_recovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
+	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/chiyutianyi/grpcfuse/exports"
+	"github.com/chiyutianyi/grpcfuse/pb"
+	"github.com/chiyutianyi/grpcfuse/peercred"
+	"github.com/chiyutianyi/grpcfuse/pkg/utils"
+	"github.com/chiyutianyi/grpcfuse/rpctrace"
+	"github.com/chiyutianyi/grpcfuse/shm"
+)
+
+// export is a backend and the configuration it was made from.
+type export struct {
+	cfg ExportConfig
+	srv pb.RawFileSystemServer
+}
+
+// grpcServer is a gRPC server and the trace it records to.
+type grpcServer struct {
+	*grpc.Server
+	recorder *rpctrace.Recorder
+	record   *os.File
+}
+
+// server runs a configuration, and moves to a new one by changing only
+// what differs: backends of unchanged exports keep their state, and
+// listeners stay open.
+type server struct {
+	mu        sync.Mutex
+	cfg       *Config
+	router    *exports.Router
+	exports   map[string]*export
+	grpc      *grpcServer
+	listeners map[ListenerConfig]net.Listener
+	// shm takes the memfds of clients on the shm sockets of listeners
+	// with SharedMemory; its sessions outlive reloads.
+	shm          *shm.Server
+	shmListeners map[ListenerConfig]net.Listener
+	metrics      *http.Server
+	// metricsAddr is where metrics listens, for a port chosen by the
+	// system.
+	metricsAddr net.Addr
+	// stopping are the servers replaced by a reload, still finishing
+	// their calls.
+	stopping sync.WaitGroup
+}
+
+func newServer() *server {
+	return &server{
+		router:       exports.New(),
+		exports:      map[string]*export{},
+		listeners:    map[ListenerConfig]net.Listener{},
+		shm:          shm.NewServer(),
+		shmListeners: map[ListenerConfig]net.Listener{},
+	}
+}
+
+// apply moves the server to cfg. Nothing changes if a backend, TLS or the
+// trace cannot be set up; listeners that fail to open are reported and
+// the rest of cfg applied.
+func (s *server) apply(cfg *Config) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	exps := make(map[string]*export, len(cfg.Exports))
+	for _, ec := range cfg.Exports {
+		if old, ok := s.exports[ec.Name]; ok && old.cfg == ec {
+			exps[ec.Name] = old
+			continue
+		}
+		srv, err := newBackend(ec)
+		if err != nil {
+			return err
+		}
+		exps[ec.Name] = &export{cfg: ec, srv: srv}
+	}
+	var gs *grpcServer
+	if s.grpc == nil || !reflect.DeepEqual(s.cfg.TLS, cfg.TLS) || s.cfg.Interceptors != cfg.Interceptors ||
+		(s.cfg.Metrics.Address == "") != (cfg.Metrics.Address == "") ||
+		!reflect.DeepEqual(s.cfg.PeerCredentials, cfg.PeerCredentials) {
+		var err error
+		if gs, err = s.newGRPCServer(cfg); err != nil {
+			return err
+		}
+	}
+
+	log.SetLevel(utils.GetLogLevel(cfg.Log.Level))
+	if cfg.Log.Format == "json" {
+		log.SetFormatter(&log.JSONFormatter{})
+	} else {
+		log.SetFormatter(&log.TextFormatter{})
+	}
+
+	servers := make(map[string]pb.RawFileSystemServer, len(exps))
+	for name, e := range exps {
+		servers[name] = e.srv
+	}
+	s.router.Set(servers, cfg.Exports[0].Name)
+	s.exports = exps
+
+	if gs != nil {
+		// The listeners belong to the old server; open them again on the
+		// new one.
+		for lc := range s.listeners {
+			s.closeListener(lc)
+		}
+		if old := s.grpc; old != nil {
+			timeout := s.cfg.ShutdownTimeout
+			s.stopping.Add(1)
+			go func() {
+				defer s.stopping.Done()
+				old.stop(timeout)
+			}()
+		}
+		s.grpc = gs
+	}
+	errs := s.listen(cfg.Listeners)
+
+	if s.cfg == nil || s.cfg.Metrics != cfg.Metrics {
+		if err := s.serveMetrics(cfg.Metrics); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	s.cfg = cfg
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (s *server) newGRPCServer(cfg *Config) (*grpcServer, error) {
+	gs := &grpcServer{}
+	// Shared memory comes first, so that the other interceptors see the
+	// data of reads and writes.
+	streamInterceptors := []grpc.StreamServerInterceptor{s.shm.StreamServerInterceptor()}
+	unaryInterceptors := []grpc.UnaryServerInterceptor{s.shm.UnaryServerInterceptor()}
+	if cfg.Interceptors.Record != "" {
+		f, err := os.Create(cfg.Interceptors.Record)
+		if err != nil {
+			return nil, err
+		}
+		if gs.recorder, err = rpctrace.NewRecorder(f); err != nil {
+			f.Close()
+			return nil, err
+		}
+		gs.record = f
+		// Outermost, so that panics turned into errors by recovery are
+		// recorded too.
+		streamInterceptors = append(streamInterceptors, gs.recorder.StreamServerInterceptor())
+		unaryInterceptors = append(unaryInterceptors, gs.recorder.UnaryServerInterceptor())
+	}
+	if cfg.Metrics.Address != "" {
+		streamInterceptors = append(streamInterceptors, grpc_prometheus.StreamServerInterceptor)
+		unaryInterceptors = append(unaryInterceptors, grpc_prometheus.UnaryServerInterceptor)
+	}
+	if cfg.Interceptors.Logging {
+		logEntry := log.NewEntry(log.StandardLogger())
+		streamInterceptors = append(streamInterceptors, grpc_logrus.StreamServerInterceptor(logEntry))
+		unaryInterceptors = append(unaryInterceptors, grpc_logrus.UnaryServerInterceptor(logEntry))
+	}
+	if pc := cfg.PeerCredentials; pc != nil {
+		policy := &peercred.Policy{AllowedUIDs: pc.AllowedUIDs, TrustedUIDs: pc.TrustedUIDs}
+		streamInterceptors = append(streamInterceptors, policy.StreamServerInterceptor())
+		unaryInterceptors = append(unaryInterceptors, policy.UnaryServerInterceptor())
+	}
+	if cfg.Interceptors.Recovery {
+		streamInterceptors = append(streamInterceptors, grpc_recovery.StreamServerInterceptor())
+		unaryInterceptors = append(unaryInterceptors, grpc_recovery.UnaryServerInterceptor())
+	}
+	opts := []grpc.ServerOption{
+		grpc.StreamInterceptor(grpc_middleware.ChainStreamServer(streamInterceptors...)),
+		grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(unaryInterceptors...)),
+	}
+	var creds credentials.TransportCredentials
+	if cfg.TLS != nil {
+		var err error
+		if creds, err = serverCredentials(cfg.TLS); err != nil {
+			gs.closeRecord()
+			return nil, err
+		}
+	}
+	// Unix socket peers are known whether or not TLS is on.
+	opts = append(opts, grpc.Creds(peercred.NewCredentials(creds)))
+	gs.Server = grpc.NewServer(opts...)
+	s.router.Register(gs.Server)
+	if cfg.Metrics.Address != "" {
+		grpc_prometheus.Register(gs.Server)
+	}
+	return gs, nil
+}
+
+func serverCredentials(cfg *TLSConfig) (credentials.TransportCredentials, error) {
+	cert, err := tls.LoadX509KeyPair(cfg.Cert, cfg.Key)
+	if err != nil {
+		return nil, fmt.Errorf("tls: %v", err)
+	}
+	tc := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if cfg.ClientCA != "" {
+		pem, err := ioutil.ReadFile(cfg.ClientCA)
+		if err != nil {
+			return nil, fmt.Errorf("tls: %v", err)
+		}
+		tc.ClientCAs = x509.NewCertPool()
+		if !tc.ClientCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls: no certificates in %s", cfg.ClientCA)
+		}
+		tc.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return credentials.NewTLS(tc), nil
+}
+
+// stop stops gs, cutting the calls still running after timeout.
+func (gs *grpcServer) stop(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		gs.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		log.Warnf("Calls still running after %v, stopping", timeout)
+		gs.Stop()
+		<-done
+	}
+	gs.closeRecord()
+}
+
+func (gs *grpcServer) closeRecord() {
+	if gs.recorder == nil {
+		return
+	}
+	if err := gs.recorder.Flush(); err != nil {
+		log.Errorf("Record: %v", err)
+	}
+	if err := gs.record.Close(); err != nil {
+		log.Errorf("Record: %v", err)
+	}
+}
+
+// listen closes the listeners not in lcs and opens the missing ones.
+func (s *server) listen(lcs []ListenerConfig) []string {
+	want := map[ListenerConfig]bool{}
+	for _, lc := range lcs {
+		want[lc] = true
+	}
+	for lc := range s.listeners {
+		if !want[lc] {
+			s.closeListener(lc)
+			log.Infof("Stopped listening on %s", lc)
+		}
+	}
+	var errs []string
+	for _, lc := range lcs {
+		if _, ok := s.listeners[lc]; ok {
+			continue
+		}
+		l, err := openListener(lc)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if lc.SharedMemory {
+			sl, err := openListener(ListenerConfig{Network: "unix", Address: lc.Address + ".shm", Mode: lc.Mode})
+			if err != nil {
+				l.Close()
+				errs = append(errs, err.Error())
+				continue
+			}
+			s.shmListeners[lc] = sl
+			go s.shm.Serve(sl)
+			log.Infof("Shared memory on %s", sl.Addr())
+		}
+		s.listeners[lc] = l
+		go s.grpc.Serve(l)
+		log.Infof("Listen on %s", l.Addr())
+	}
+	return errs
+}
+
+func (s *server) closeListener(lc ListenerConfig) {
+	s.listeners[lc].Close()
+	delete(s.listeners, lc)
+	if sl, ok := s.shmListeners[lc]; ok {
+		sl.Close()
+		delete(s.shmListeners, lc)
+	}
+}
+
+func openListener(lc ListenerConfig) (net.Listener, error) {
+	if lc.Network == "unix" {
+		// A socket left behind by a server that did not shut down.
+		if fi, err := os.Lstat(lc.Address); err == nil && fi.Mode()&os.ModeSocket != 0 {
+			os.Remove(lc.Address)
+		}
+	}
+	l, err := net.Listen(lc.Network, lc.Address)
+	if err != nil {
+		return nil, err
+	}
+	if lc.Mode != 0 {
+		if err := os.Chmod(lc.Address, os.FileMode(lc.Mode)); err != nil {
+			l.Close()
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+func (s *server) serveMetrics(cfg MetricsConfig) error {
+	if s.metrics != nil {
+		s.metrics.Close()
+		s.metrics, s.metricsAddr = nil, nil
+	}
+	if cfg.Address == "" {
+		return nil
+	}
+	if cfg.Histogram {
+		grpc_prometheus.EnableHandlingTimeHistogram()
+	}
+	l, err := net.Listen("tcp", cfg.Address)
+	if err != nil {
+		return fmt.Errorf("metrics: %v", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle(cfg.Path, promhttp.Handler())
+	s.metrics = &http.Server{Handler: mux}
+	s.metricsAddr = l.Addr()
+	go s.metrics.Serve(l)
+	log.Infof("Metrics on http://%s%s", l.Addr(), cfg.Path)
+	return nil
+}
+
+// shutdown stops listening and waits for the calls in flight, up to the
+// shutdown timeout.
+func (s *server) shutdown() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.grpc != nil {
+		s.grpc.stop(s.cfg.ShutdownTimeout)
+		s.grpc = nil
+	}
+	s.stopping.Wait()
+	// Serve has closed the listeners.
+	s.listeners = map[ListenerConfig]net.Listener{}
+	for lc, sl := range s.shmListeners {
+		sl.Close()
+		delete(s.shmListeners, lc)
+	}
+	s.shm.Close()
+	if s.metrics != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout)
+		defer cancel()
+		s.metrics.Shutdown(ctx)
+		s.metrics = nil
+	}
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/chiyutianyi/grpcfuse/exports"
+	"github.com/chiyutianyi/grpcfuse/fsclient"
+	"github.com/chiyutianyi/grpcfuse/pb"
+	"github.com/chiyutianyi/grpcfuse/rpctrace"
+	"github.com/chiyutianyi/grpcfuse/shm"
+)
+
+func dial(t *testing.T, target, export string, opts ...grpc.DialOption) *fsclient.Client {
+	if len(opts) == 0 {
+		opts = []grpc.DialOption{grpc.WithInsecure()}
+	}
+	conn, err := grpc.Dial(target, append(opts, exports.DialOptions(export)...)...)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return fsclient.New(pb.NewRawFileSystemClient(conn), &fsclient.Options{Timeout: 5 * time.Second})
+}
+
+func mustParse(t *testing.T, data string) *Config {
+	cfg, err := parseConfig([]byte(data))
+	require.NoError(t, err)
+	return cfg
+}
+
+func TestServer(t *testing.T) {
+	dir := t.TempDir()
+	sock := filepath.Join(dir, "sock")
+	data := filepath.Join(dir, "data")
+	require.NoError(t, os.Mkdir(data, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(data, "file"), []byte("hello"), 0644))
+
+	s := newServer()
+	defer s.shutdown()
+	listeners := "listeners: [{network: unix, address: " + sock + ", mode: 0600}]\n"
+	require.NoError(t, s.apply(mustParse(t, listeners+
+		"exports: [{name: a, backend: memfs}, {name: b, backend: loopback, path: "+data+", read_only: true}]\n"+
+		"metrics: {address: '127.0.0.1:0'}\n")))
+
+	fi, err := os.Stat(sock)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	a, b := dial(t, "unix:"+sock, "a"), dial(t, "unix:"+sock, "/b")
+	require.NoError(t, a.Mkdir("dir", 0755))
+	content, err := fs.ReadFile(b, "file")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(content))
+	assert.ErrorIs(t, b.Mkdir("dir", 0755), syscall.EROFS)
+
+	res, err := http.Get("http://" + s.metricsAddr.String() + "/metrics")
+	require.NoError(t, err)
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Contains(t, string(body), `grpc_server_handled_total{grpc_code="OK",grpc_method="Mkdir"`)
+
+	// Dropping an export and adding a listener keeps the others as they
+	// were.
+	require.NoError(t, s.apply(mustParse(t, "listeners: [{network: unix, address: "+sock+", mode: 0600}, {network: tcp, address: '127.0.0.1:0'}]\n"+
+		"exports: [{name: a, backend: memfs}]\n")))
+	_, err = a.Stat("dir")
+	assert.NoError(t, err)
+	_, err = b.Stat("file")
+	assert.Error(t, err)
+	assert.Nil(t, s.metrics)
+	var tcp string
+	for lc, l := range s.listeners {
+		if lc.Network == "tcp" {
+			tcp = l.Addr().String()
+		}
+	}
+	_, err = dial(t, tcp, "").Stat("dir")
+	assert.NoError(t, err)
+
+	// A bad configuration changes nothing.
+	err = s.apply(mustParse(t, listeners+"exports: [{name: a, backend: memfs, msg_size_threshold: 10}, {name: c, backend: loopback, path: /nonexistent}]\n"))
+	assert.Error(t, err)
+	_, err = dial(t, tcp, "a").Stat("dir")
+	assert.NoError(t, err)
+
+	// New interceptors make a new gRPC server on the same listeners and
+	// backends.
+	require.NoError(t, s.apply(mustParse(t, listeners+"exports: [{name: a, backend: memfs}]\ninterceptors: {logging: true}\n")))
+	_, err = dial(t, "unix:"+sock, "a").Stat("dir")
+	assert.NoError(t, err)
+
+	s.shutdown()
+	_, err = os.Stat(sock)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+// writeCert writes a self-signed certificate for localhost and its key.
+func writeCert(t *testing.T, dir string) (certFile, keyFile string, pool *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644))
+	require.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	pool = x509.NewCertPool()
+	pool.AddCert(cert)
+	return certFile, keyFile, pool
+}
+
+func TestTLS(t *testing.T) {
+	dir := t.TempDir()
+	sock := filepath.Join(dir, "sock")
+	certFile, keyFile, pool := writeCert(t, dir)
+	clientCert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	require.NoError(t, err)
+
+	s := newServer()
+	defer s.shutdown()
+	require.NoError(t, s.apply(mustParse(t, "listeners: [{network: unix, address: "+sock+"}]\n"+
+		"exports: [{name: a, backend: memfs}]\n"+
+		"tls: {cert: "+certFile+", key: "+keyFile+", client_ca: "+certFile+"}\n")))
+
+	tests := []struct {
+		name string
+		opt  grpc.DialOption
+		ok   bool
+	}{
+		{name: "client certificate", opt: grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{ServerName: "localhost", RootCAs: pool, Certificates: []tls.Certificate{clientCert}})), ok: true},
+		{name: "no client certificate", opt: grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{ServerName: "localhost", RootCAs: pool}))},
+		{name: "insecure", opt: grpc.WithInsecure()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := dial(t, "unix:"+sock, "a", tt.opt).Stat(".")
+			if tt.ok {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+
+	err = s.apply(mustParse(t, "listeners: [{network: unix, address: "+sock+"}]\n"+
+		"exports: [{name: a, backend: memfs}]\n"+
+		"tls: {cert: "+keyFile+", key: "+keyFile+"}\n"))
+	assert.Error(t, err)
+}
+
+func TestRecord(t *testing.T) {
+	dir := t.TempDir()
+	sock, trace := filepath.Join(dir, "sock"), filepath.Join(dir, "trace")
+	s := newServer()
+	require.NoError(t, s.apply(mustParse(t, "listeners: [{network: unix, address: "+sock+"}]\n"+
+		"exports: [{name: a, backend: memfs}]\n"+
+		"interceptors: {record: "+trace+", recovery: false}\n")))
+	require.NoError(t, dial(t, "unix:"+sock, "a").Mkdir("dir", 0755))
+	s.shutdown()
+
+	f, err := os.Open(trace)
+	require.NoError(t, err)
+	defer f.Close()
+	records, err := rpctrace.ReadAll(f)
+	require.NoError(t, err)
+	var methods []string
+	for _, r := range records {
+		methods = append(methods, r.Method)
+	}
+	assert.Contains(t, methods, "/pb.RawFileSystem/Mkdir")
+}
+
+func TestUnixSocket(t *testing.T) {
+	dir := t.TempDir()
+	sock := filepath.Join(dir, "sock")
+	listeners := "listeners: [{network: unix, address: " + sock + ", shared_memory: true}]\n"
+	s := newServer()
+	defer s.shutdown()
+	require.NoError(t, s.apply(mustParse(t, listeners+"exports: [{name: a, backend: memfs}]\n"+
+		fmt.Sprintf("peer_credentials: {allowed_uids: [%d]}\n", os.Getuid()+1))))
+
+	_, err := dial(t, "unix:"+sock, "a").Stat(".")
+	assert.Error(t, err)
+
+	require.NoError(t, s.apply(mustParse(t, listeners+"exports: [{name: a, backend: memfs}]\n"+
+		fmt.Sprintf("peer_credentials: {allowed_uids: [%d]}\n", os.Getuid()))))
+	c, err := shm.Dial(sock+".shm", &shm.Options{Size: 1 << 20, SlotSize: 256 << 10, MinSize: 1})
+	require.NoError(t, err)
+	defer c.Close()
+	fsys := dial(t, "unix:"+sock, "a", grpc.WithInsecure(),
+		grpc.WithChainUnaryInterceptor(c.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(c.StreamClientInterceptor()))
+	data := make([]byte, 100<<10)
+	rand.Read(data)
+	writeFile := func(name string) {
+		f, err := fsys.Create(name)
+		require.NoError(t, err)
+		_, err = f.WriteAt(data, 0)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		got, err := fs.ReadFile(fsys, name)
+		require.NoError(t, err)
+		assert.Equal(t, data, got)
+	}
+	writeFile("file")
+	shared := c.Stats().Shared
+	assert.Greater(t, shared, int64(0))
+
+	// The session outlives a reload that makes a new gRPC server.
+	require.NoError(t, s.apply(mustParse(t, listeners+"exports: [{name: a, backend: memfs}]\n")))
+	writeFile("file2")
+	assert.Greater(t, c.Stats().Shared, shared)
+}
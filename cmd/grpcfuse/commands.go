@@ -0,0 +1,371 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"math"
+	"path"
+	"strconv"
+	"syscall"
+
+	"github.com/chiyutianyi/grpcfuse/fsclient"
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+// parse parses the flags of a command and checks it has between min and
+// max arguments; max < 0 means any number.
+func parse(flags *flag.FlagSet, args []string, min, max int) ([]string, error) {
+	flags.SetOutput(ioutil.Discard)
+	usage := commands[flags.Name()].usage
+	if err := flags.Parse(args); err != nil {
+		return nil, usageError{usage}
+	}
+	if flags.NArg() < min || (max >= 0 && flags.NArg() > max) {
+		return nil, usageError{usage}
+	}
+	return flags.Args(), nil
+}
+
+// humanSize formats n bytes with a binary unit.
+func humanSize(n uint64) string {
+	const units = "KMGTPE"
+	if n < 1024 {
+		return fmt.Sprintf("%dB", n)
+	}
+	v, i := float64(n)/1024, 0
+	for v >= 1024 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	return fmt.Sprintf("%.1f%ciB", v, units[i])
+}
+
+func (e *env) longEntry(name string, info fs.FileInfo) {
+	line := fmt.Sprintf("%s %10d %s %s", info.Mode(), info.Size(), info.ModTime().Format("2006-01-02 15:04"), name)
+	if info.Mode()&fs.ModeSymlink != 0 {
+		if target, err := e.c.Readlink(remotePath(name)); err == nil {
+			line += " -> " + target
+		}
+	}
+	fmt.Fprintln(e.out, line)
+}
+
+func ls(e *env, args []string) error {
+	flags := flag.NewFlagSet("ls", flag.ContinueOnError)
+	long := flags.Bool("l", false, "long listing")
+	args, err := parse(flags, args, 0, -1)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+	for i, arg := range args {
+		name := remotePath(arg)
+		info, err := e.c.Stat(name)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			if *long {
+				e.longEntry(name, info)
+			} else {
+				fmt.Fprintln(e.out, name)
+			}
+			continue
+		}
+		entries, err := e.c.ReadDir(name)
+		if err != nil {
+			return err
+		}
+		if len(args) > 1 {
+			if i > 0 {
+				fmt.Fprintln(e.out)
+			}
+			fmt.Fprintf(e.out, "%s:\n", name)
+		}
+		for _, entry := range entries {
+			if !*long {
+				fmt.Fprintln(e.out, entry.Name())
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			e.longEntry(path.Join(name, entry.Name()), info)
+		}
+	}
+	return nil
+}
+
+func stat(e *env, args []string) error {
+	args, err := parse(flag.NewFlagSet("stat", flag.ContinueOnError), args, 1, -1)
+	if err != nil {
+		return err
+	}
+	for _, arg := range args {
+		info, err := e.c.Stat(remotePath(arg))
+		if err != nil {
+			return err
+		}
+		attr := info.Sys().(*pb.Attr)
+		fmt.Fprintf(e.out, "  File: %s\n", arg)
+		fmt.Fprintf(e.out, "  Size: %d\tBlocks: %d\tMode: %s\n", info.Size(), attr.Blocks, info.Mode())
+		fmt.Fprintf(e.out, " Inode: %d\tLinks: %d\tUid: %d\tGid: %d\n", attr.Ino, attr.Nlink, attr.Owner.GetUid(), attr.Owner.GetGid())
+		fmt.Fprintf(e.out, "Modify: %s\n", info.ModTime())
+	}
+	return nil
+}
+
+func cat(e *env, args []string) error {
+	args, err := parse(flag.NewFlagSet("cat", flag.ContinueOnError), args, 1, -1)
+	if err != nil {
+		return err
+	}
+	for _, arg := range args {
+		f, err := e.c.Open(remotePath(arg))
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(e.out, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// target is where src goes when moved or copied to dst: into dst if it is
+// a directory.
+func (e *env) target(src, dst string) string {
+	if info, err := e.c.Stat(dst); err == nil && info.IsDir() {
+		return path.Join(dst, path.Base(src))
+	}
+	return dst
+}
+
+func mv(e *env, args []string) error {
+	args, err := parse(flag.NewFlagSet("mv", flag.ContinueOnError), args, 2, 2)
+	if err != nil {
+		return err
+	}
+	src := remotePath(args[0])
+	return e.c.Rename(src, e.target(src, remotePath(args[1])))
+}
+
+// removeAll removes name and everything below it, deepest first.
+func (e *env) removeAll(name string) error {
+	var names []string
+	err := fs.WalkDir(e.c, name, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		names = append(names, p)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for i := len(names) - 1; i >= 0; i-- {
+		if err := e.c.Remove(names[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func rm(e *env, args []string) error {
+	flags := flag.NewFlagSet("rm", flag.ContinueOnError)
+	recursive := flags.Bool("r", false, "remove directories and their contents")
+	args, err := parse(flags, args, 1, -1)
+	if err != nil {
+		return err
+	}
+	for _, arg := range args {
+		name := remotePath(arg)
+		if name == "." {
+			return fmt.Errorf("refusing to remove the root")
+		}
+		if *recursive {
+			err = e.removeAll(name)
+		} else {
+			err = e.c.Remove(name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mkdirAll creates name and its missing parents.
+func (e *env) mkdirAll(name string, perm fs.FileMode) error {
+	if name == "." {
+		return nil
+	}
+	info, err := e.c.Stat(name)
+	if err == nil {
+		if info.IsDir() {
+			return nil
+		}
+		return &fs.PathError{Op: "mkdir", Path: name, Err: syscall.ENOTDIR}
+	}
+	if err := e.mkdirAll(path.Dir(name), perm); err != nil {
+		return err
+	}
+	err = e.c.Mkdir(name, perm)
+	if errors.Is(err, fs.ErrExist) {
+		// Someone else was quicker.
+		return nil
+	}
+	return err
+}
+
+func mkdir(e *env, args []string) error {
+	flags := flag.NewFlagSet("mkdir", flag.ContinueOnError)
+	parents := flags.Bool("p", false, "create parents as needed, and no error if the directory exists")
+	args, err := parse(flags, args, 1, -1)
+	if err != nil {
+		return err
+	}
+	for _, arg := range args {
+		if *parents {
+			err = e.mkdirAll(remotePath(arg), 0755)
+		} else {
+			err = e.c.Mkdir(remotePath(arg), 0755)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ln(e *env, args []string) error {
+	flags := flag.NewFlagSet("ln", flag.ContinueOnError)
+	symbolic := flags.Bool("s", false, "make a symbolic link")
+	args, err := parse(flags, args, 2, 2)
+	if err != nil {
+		return err
+	}
+	link := remotePath(args[1])
+	if *symbolic {
+		// The target is stored as is, relative to the link.
+		return e.c.Symlink(args[0], e.target(args[0], link))
+	}
+	src := remotePath(args[0])
+	return e.c.Link(src, e.target(src, link))
+}
+
+func xattr(e *env, args []string) error {
+	usage := usageError{commands["xattr"].usage}
+	if len(args) < 2 {
+		return usage
+	}
+	name := remotePath(args[1])
+	switch {
+	case args[0] == "list" && len(args) == 2:
+		attrs, err := e.c.ListXAttr(name)
+		if err != nil {
+			return err
+		}
+		for _, attr := range attrs {
+			fmt.Fprintln(e.out, attr)
+		}
+		return nil
+	case args[0] == "get" && len(args) == 3:
+		value, err := e.c.GetXAttr(name, args[2])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(e.out, "%s\n", value)
+		return nil
+	case args[0] == "set" && len(args) == 4:
+		return e.c.SetXAttr(name, args[2], []byte(args[3]), 0)
+	case args[0] == "rm" && len(args) == 3:
+		return e.c.RemoveXAttr(name, args[2])
+	}
+	return usage
+}
+
+func df(e *env, args []string) error {
+	args, err := parse(flag.NewFlagSet("df", flag.ContinueOnError), args, 0, 1)
+	if err != nil {
+		return err
+	}
+	name := "."
+	if len(args) == 1 {
+		name = remotePath(args[0])
+	}
+	st, err := e.c.StatFs(name)
+	if err != nil {
+		return err
+	}
+	size := st.Blocks * uint64(st.Frsize)
+	used := (st.Blocks - st.Bfree) * uint64(st.Frsize)
+	avail := st.Bavail * uint64(st.Frsize)
+	pct := "-"
+	if used+avail > 0 {
+		pct = fmt.Sprintf("%.0f%%", math.Ceil(float64(used)*100/float64(used+avail)))
+	}
+	fmt.Fprintf(e.out, "%10s %10s %10s %5s %10s %10s %10s\n", "Size", "Used", "Avail", "Use%", "Inodes", "IUsed", "IFree")
+	fmt.Fprintf(e.out, "%10s %10s %10s %5s %10d %10d %10d\n",
+		humanSize(size), humanSize(used), humanSize(avail), pct, st.Files, st.Files-st.Ffree, st.Ffree)
+	return nil
+}
+
+func lock(e *env, args []string) error {
+	args, err := parse(flag.NewFlagSet("lock", flag.ContinueOnError), args, 1, 3)
+	if err != nil {
+		return err
+	}
+	lk := fsclient.Lock{Type: syscall.F_WRLCK, End: math.MaxInt64}
+	if len(args) > 1 {
+		if lk.Start, err = strconv.ParseUint(args[1], 10, 63); err != nil {
+			return err
+		}
+	}
+	if len(args) > 2 {
+		n, err := strconv.ParseUint(args[2], 10, 63)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			lk.End = lk.Start + n - 1
+		}
+	}
+	f, err := e.c.Open(remotePath(args[0]))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	// A write lock conflicts with any lock someone holds.
+	held, err := f.(*fsclient.File).GetLk(lk)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(e.out, held)
+	return nil
+}
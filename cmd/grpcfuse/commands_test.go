@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chiyutianyi/grpcfuse/fsclient"
+	"github.com/chiyutianyi/grpcfuse/grpcfusetest"
+	"github.com/chiyutianyi/grpcfuse/memfs"
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+func newEnv(t *testing.T, opts *memfs.Options) (*env, *bytes.Buffer) {
+	p := grpcfusetest.New(t, memfs.New(opts, nil), nil)
+	out := &bytes.Buffer{}
+	return &env{c: fsclient.New(pb.NewRawFileSystemClient(p.Conn), nil), out: out, log: &bytes.Buffer{}, jobs: 2}, out
+}
+
+func writeRemote(t *testing.T, e *env, name, content string) {
+	f, err := e.c.Create(name)
+	require.NoError(t, err)
+	_, err = f.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		name string
+		cmds [][]string
+		// out is the output of the last command.
+		out   string
+		err   string
+		check func(t *testing.T, e *env)
+	}{
+		{name: "ls", cmds: [][]string{{"ls"}}, out: "dir\nfile\n"},
+		{name: "ls file", cmds: [][]string{{"ls", "/dir/a"}}, out: "dir/a\n"},
+		{name: "ls several", cmds: [][]string{{"ls", "dir", "/"}}, out: "dir:\na\n\n.:\ndir\nfile\n"},
+		{name: "ls missing", cmds: [][]string{{"ls", "nope"}}, err: "no such file"},
+		{name: "ls bad flag", cmds: [][]string{{"ls", "-x"}}, err: "usage: grpcfuse ls [-l] [path...]"},
+		{name: "cat", cmds: [][]string{{"cat", "file", "dir/a"}}, out: "hello\naaa"},
+		{name: "cat no args", cmds: [][]string{{"cat"}}, err: "usage: grpcfuse cat path..."},
+		{name: "mv", cmds: [][]string{{"mv", "file", "dir"}, {"ls", "dir"}}, out: "a\nfile\n"},
+		{name: "mv rename", cmds: [][]string{{"mv", "file", "other"}, {"cat", "other"}}, out: "hello\n"},
+		{name: "rm", cmds: [][]string{{"rm", "file"}, {"ls"}}, out: "dir\n"},
+		{name: "rm dir", cmds: [][]string{{"rm", "dir"}}, err: "directory not empty"},
+		{name: "rm -r", cmds: [][]string{{"rm", "-r", "dir"}, {"ls"}}, out: "file\n"},
+		{name: "rm root", cmds: [][]string{{"rm", "-r", "/"}}, err: "refusing"},
+		{name: "mkdir", cmds: [][]string{{"mkdir", "new"}, {"ls"}}, out: "dir\nfile\nnew\n"},
+		{name: "mkdir exists", cmds: [][]string{{"mkdir", "dir"}}, err: "file exists"},
+		{name: "mkdir -p", cmds: [][]string{{"mkdir", "-p", "dir", "x/y/z"}, {"ls", "x/y"}}, out: "z\n"},
+		{name: "mkdir -p over file", cmds: [][]string{{"mkdir", "-p", "file/x"}}, err: "not a directory"},
+		{name: "ln", cmds: [][]string{{"ln", "file", "dir"}, {"cat", "dir/file"}}, out: "hello\n"},
+		{
+			name: "ln -s",
+			cmds: [][]string{{"ln", "-s", "../file", "dir/link"}},
+			check: func(t *testing.T, e *env) {
+				target, err := e.c.Readlink("dir/link")
+				require.NoError(t, err)
+				assert.Equal(t, "../file", target)
+			},
+		},
+		{name: "xattr", cmds: [][]string{{"xattr", "set", "file", "user.a", "1"}, {"xattr", "set", "file", "user.b", "2"}, {"xattr", "list", "file"}}, out: "user.a\nuser.b\n"},
+		{name: "xattr get", cmds: [][]string{{"xattr", "set", "file", "user.a", "1"}, {"xattr", "get", "file", "user.a"}}, out: "1\n"},
+		{name: "xattr rm", cmds: [][]string{{"xattr", "set", "file", "user.a", "1"}, {"xattr", "rm", "file", "user.a"}, {"xattr", "list", "file"}}, out: ""},
+		{name: "xattr missing", cmds: [][]string{{"xattr", "get", "file", "user.a"}}, err: "no data available"},
+		{name: "xattr usage", cmds: [][]string{{"xattr", "get", "file"}}, err: "usage: grpcfuse xattr"},
+		{
+			name: "df",
+			cmds: [][]string{{"df"}},
+			out: "      Size       Used      Avail  Use%     Inodes      IUsed      IFree\n" +
+				"    1.0MiB     8.0KiB  1016.0KiB    1%        100          4         96\n",
+		},
+		{name: "lock", cmds: [][]string{{"lock", "file", "0", "10"}}, out: "unlocked\n"},
+		{name: "lock bad start", cmds: [][]string{{"lock", "file", "x"}}, err: "invalid syntax"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, out := newEnv(t, &memfs.Options{Capacity: 1 << 20, MaxInodes: 100, BlockSize: 4096})
+			require.NoError(t, e.c.Mkdir("dir", 0755))
+			writeRemote(t, e, "dir/a", "aaa")
+			writeRemote(t, e, "file", "hello\n")
+
+			var err error
+			for _, args := range tt.cmds {
+				out.Reset()
+				err = commands[args[0]].run(e, args[1:])
+				if err != nil {
+					break
+				}
+			}
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.out, out.String())
+			if tt.check != nil {
+				tt.check(t, e)
+			}
+		})
+	}
+}
+
+func TestLongListing(t *testing.T) {
+	e, out := newEnv(t, nil)
+	writeRemote(t, e, "file", "hello\n")
+	require.NoError(t, e.c.Symlink("file", "link"))
+
+	require.NoError(t, ls(e, []string{"-l"}))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "-rw-rw-rw-          6 "), lines[0])
+	assert.True(t, strings.HasSuffix(lines[0], " file"), lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "L"), lines[1])
+	assert.True(t, strings.HasSuffix(lines[1], " link -> file"), lines[1])
+
+	out.Reset()
+	require.NoError(t, stat(e, []string{"file"}))
+	assert.Contains(t, out.String(), "  File: file\n  Size: 6\t")
+}
+
+func TestRemotePath(t *testing.T) {
+	for in, want := range map[string]string{
+		"":       ".",
+		"/":      ".",
+		".":      ".",
+		"a/b":    "a/b",
+		"/a/b/":  "a/b",
+		"./a/b":  "a/b",
+		"../a":   "a",
+		"a/../b": "b",
+	} {
+		assert.Equal(t, want, remotePath(in), in)
+	}
+}
+
+func TestHumanSize(t *testing.T) {
+	for n, want := range map[uint64]string{
+		0:       "0B",
+		1023:    "1023B",
+		1024:    "1.0KiB",
+		1536:    "1.5KiB",
+		1 << 30: "1.0GiB",
+	} {
+		assert.Equal(t, want, humanSize(n), n)
+	}
+}
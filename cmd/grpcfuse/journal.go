@@ -0,0 +1,78 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/chiyutianyi/grpcfuse/exports"
+	"github.com/chiyutianyi/grpcfuse/grpc2fuse"
+	"github.com/chiyutianyi/grpcfuse/journal"
+)
+
+// journalCmd shows the changes a mount of -addr with the cache dir made
+// offline and has yet to send, and the conflicts sending them met.
+func journalCmd(e *env, args []string) error {
+	flags := flag.NewFlagSet("journal", flag.ContinueOnError)
+	export := flags.String("export", "", "export of the mount")
+	clearConflicts := flags.Bool("clear", false, "forget the conflicts and their data")
+	args, err := parse(flags, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	dir := grpc2fuse.JournalDir(args[0], e.addr, exports.Name(*export))
+	if _, err := os.Stat(dir); err != nil {
+		return fmt.Errorf("no journal of %s in %s", e.addr, args[0])
+	}
+	if *clearConflicts {
+		// Open locks the journal, so this fails while a mount uses it.
+		j, err := journal.Open(dir)
+		if err != nil {
+			return err
+		}
+		defer j.Close()
+		return j.ClearConflicts()
+	}
+	j, err := journal.OpenReadOnly(dir)
+	if err != nil {
+		return err
+	}
+	conflicts, err := j.Conflicts()
+	if err != nil {
+		return err
+	}
+
+	pending := j.Pending()
+	fmt.Fprintf(e.out, "%d pending\n", len(pending))
+	for _, op := range pending {
+		fmt.Fprintf(e.out, "  %d %s %s\n", op.ID, op.Time.Format("2006-01-02 15:04:05"), op)
+	}
+	fmt.Fprintf(e.out, "%d conflicts\n", len(conflicts))
+	for _, c := range conflicts {
+		resolution := c.Resolution
+		if resolution == "" {
+			resolution = "dropped"
+		}
+		fmt.Fprintf(e.out, "  %d %s %s: %s; %s\n", c.Op.ID, c.Time.Format("2006-01-02 15:04:05"), c.Op, c.Reason, resolution)
+		if c.Op.Data != "" && c.Resolution == "" {
+			fmt.Fprintf(e.out, "    data in %s\n", j.DataPath(c.Op.Data))
+		}
+	}
+	return nil
+}
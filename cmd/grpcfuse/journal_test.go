@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chiyutianyi/grpcfuse/grpc2fuse"
+	"github.com/chiyutianyi/grpcfuse/journal"
+)
+
+func TestJournalCmd(t *testing.T) {
+	dir := t.TempDir()
+	j, err := journal.Open(grpc2fuse.JournalDir(dir, "server:8760", "data"))
+	require.NoError(t, err)
+	when := time.Date(2022, 5, 1, 12, 30, 0, 0, time.Local)
+	data, f, err := j.NewData()
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	put, err := j.Append(journal.Op{Kind: journal.Put, Path: "/d/f", Data: data, Time: when})
+	require.NoError(t, err)
+	_, err = j.Append(journal.Op{Kind: journal.Rename, Path: "/a", To: "/b", Time: when})
+	require.NoError(t, err)
+	require.NoError(t, j.Conflict(journal.Conflict{Op: put, Reason: "/d/f changed on the server", Time: when}))
+
+	run := func(args ...string) (string, error) {
+		out := &bytes.Buffer{}
+		err := journalCmd(&env{addr: "server:8760", out: out}, args)
+		return out.String(), err
+	}
+	out, err := run("-export", "/data/", dir)
+	require.NoError(t, err)
+	assert.Equal(t, "1 pending\n"+
+		"  2 2022-05-01 12:30:00 rename /a -> /b\n"+
+		"1 conflicts\n"+
+		"  1 2022-05-01 12:30:00 put /d/f: /d/f changed on the server; dropped\n"+
+		"    data in "+j.DataPath(data)+"\n", out)
+
+	// The mount that made them has the journal locked.
+	_, err = run("-export", "data", "-clear", dir)
+	assert.EqualError(t, err, "journal: "+j.Dir()+" is in use")
+	require.NoError(t, j.Close())
+	_, err = run("-export", "data", "-clear", dir)
+	require.NoError(t, err)
+	out, err = run("-export", "data", dir)
+	require.NoError(t, err)
+	assert.Contains(t, out, "0 conflicts\n")
+
+	_, err = run(dir)
+	assert.EqualError(t, err, "no journal of server:8760 in "+dir)
+	_, err = run()
+	assert.EqualError(t, err, "usage: grpcfuse journal [-export name] [-clear] cache_dir")
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Command grpcfuse works with the files of a fuse2grpc server without
+// mounting it:
+//
+//	grpcfuse -addr 127.0.0.1:8760 ls -l /dir
+//	grpcfuse -addr 127.0.0.1:8760 -j 8 -progress put -r ./data /backup
+//	grpcfuse -addr 127.0.0.1:8760 journal /var/cache/grpcfuse
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+
+	"github.com/chiyutianyi/grpcfuse/fsclient"
+	"github.com/chiyutianyi/grpcfuse/pb"
+	"github.com/chiyutianyi/grpcfuse/pkg/utils"
+)
+
+// env is what commands run with.
+type env struct {
+	c *fsclient.Client
+	// addr is the server, as mounted.
+	addr string
+	// out gets the output of commands, log the progress and warnings.
+	out, log io.Writer
+	// jobs is the number of files transferred at once.
+	jobs     int
+	progress bool
+}
+
+type command struct {
+	usage string
+	run   func(e *env, args []string) error
+}
+
+// commands are set in init, as they refer back to it for their usage.
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"ls":      {"ls [-l] [path...]", ls},
+		"stat":    {"stat path...", stat},
+		"cat":     {"cat path...", cat},
+		"get":     {"get [-r] remote local", get},
+		"put":     {"put [-r] local remote", put},
+		"cp":      {"cp [-r] src dst", cp},
+		"mv":      {"mv src dst", mv},
+		"rm":      {"rm [-r] path...", rm},
+		"mkdir":   {"mkdir [-p] path...", mkdir},
+		"ln":      {"ln [-s] target link", ln},
+		"xattr":   {"xattr get path name | set path name value | rm path name | list path", xattr},
+		"df":      {"df [path]", df},
+		"lock":    {"lock path [start [length]]", lock},
+		"journal": {"journal [-export name] [-clear] cache_dir", journalCmd},
+	}
+}
+
+func usage(w io.Writer, flags *flag.FlagSet) {
+	fmt.Fprintf(w, "Usage: grpcfuse [flags] command [args]\n\nFlags:\n")
+	flags.SetOutput(w)
+	flags.PrintDefaults()
+	fmt.Fprintf(w, "\nCommands:\n")
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s\n", commands[name].usage)
+	}
+}
+
+// usageError is returned for bad arguments to a command.
+type usageError struct {
+	usage string
+}
+
+func (e usageError) Error() string {
+	return "usage: grpcfuse " + e.usage
+}
+
+// run runs the command line args and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("grpcfuse", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	addr := flags.String("addr", "127.0.0.1:8760", "fuse2grpc server")
+	jobs := flags.Int("j", 4, "files transferred at once")
+	progress := flags.Bool("progress", false, "show the progress of transfers")
+	timeout := flags.Duration("timeout", time.Minute, "timeout of each call")
+	loggerLevel := flags.String("logger-level", "warn", "log level")
+	flags.Usage = func() { usage(stderr, flags) }
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() < 1 {
+		flags.Usage()
+		return 2
+	}
+	cmd, ok := commands[flags.Arg(0)]
+	if !ok {
+		fmt.Fprintf(stderr, "grpcfuse: unknown command %q\n", flags.Arg(0))
+		flags.Usage()
+		return 2
+	}
+	log.SetLevel(utils.GetLogLevel(*loggerLevel))
+
+	conn, err := grpc.Dial(*addr, grpc.WithInsecure())
+	if err != nil {
+		fmt.Fprintf(stderr, "grpcfuse: %v\n", err)
+		return 1
+	}
+	defer conn.Close()
+	e := &env{
+		c: fsclient.New(pb.NewRawFileSystemClient(conn), &fsclient.Options{
+			Uid:     uint32(os.Getuid()),
+			Gid:     uint32(os.Getgid()),
+			Pid:     uint32(os.Getpid()),
+			Timeout: *timeout,
+		}),
+		addr:     *addr,
+		out:      stdout,
+		log:      stderr,
+		jobs:     *jobs,
+		progress: *progress,
+	}
+	if err := cmd.run(e, flags.Args()[1:]); err != nil {
+		fmt.Fprintf(stderr, "grpcfuse: %v\n", err)
+		if _, ok := err.(usageError); ok {
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
+// remotePath turns "/a/b/", "a/b" or "./a/b" into "a/b", and "" or "/"
+// into ".", as fsclient wants.
+func remotePath(p string) string {
+	p = strings.TrimPrefix(path.Clean("/"+p), "/")
+	if p == "" {
+		return "."
+	}
+	return p
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/chiyutianyi/grpcfuse/fuse2grpc"
+	"github.com/chiyutianyi/grpcfuse/memfs"
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+func TestRun(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := grpc.NewServer()
+	pb.RegisterRawFileSystemServer(s, fuse2grpc.NewServer(memfs.New(nil, nil)))
+	go s.Serve(l)
+	defer s.Stop()
+	addr := l.Addr().String()
+
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{name: "mkdir", args: []string{"-addr", addr, "mkdir", "-p", "a/b"}},
+		{name: "ls", args: []string{"-addr", addr, "ls", "a"}, stdout: "b\n"},
+		{name: "no command", args: []string{"-addr", addr}, code: 2, stderr: "Usage: grpcfuse"},
+		{name: "unknown command", args: []string{"nope"}, code: 2, stderr: "unknown command \"nope\""},
+		{name: "bad flag", args: []string{"-nope"}, code: 2},
+		{name: "bad args", args: []string{"-addr", addr, "mv", "a"}, code: 2, stderr: "usage: grpcfuse mv src dst"},
+		{name: "error", args: []string{"-addr", addr, "cat", "nope"}, code: 1, stderr: "no such file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			assert.Equal(t, tt.code, run(tt.args, &stdout, &stderr), stderr.String())
+			assert.Equal(t, tt.stdout, stdout.String())
+			assert.Contains(t, stderr.String(), tt.stderr)
+		})
+	}
+}
@@ -0,0 +1,304 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/chiyutianyi/grpcfuse/fsclient"
+)
+
+// copyBufSize is the size of the reads and writes of a transfer.
+const copyBufSize = 1 << 20
+
+// side is one end of a transfer, with fs.ValidPath names.
+type side interface {
+	fs.StatFS
+	// mkdir creates a directory, or does nothing if it exists.
+	mkdir(name string, perm fs.FileMode) error
+	create(name string, perm fs.FileMode) (io.WriteCloser, error)
+}
+
+type remote struct {
+	*fsclient.Client
+}
+
+func (r remote) mkdir(name string, perm fs.FileMode) error {
+	err := r.Mkdir(name, perm)
+	if errors.Is(err, fs.ErrExist) {
+		if info, serr := r.Stat(name); serr == nil && info.IsDir() {
+			return nil
+		}
+	}
+	return err
+}
+
+func (r remote) create(name string, perm fs.FileMode) (io.WriteCloser, error) {
+	return r.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+}
+
+// local is the local file system from its root.
+type local struct {
+	fs.StatFS
+}
+
+func newLocal() local {
+	return local{os.DirFS("/").(fs.StatFS)}
+}
+
+// localPath turns a local path into a name of local.
+func localPath(p string) (string, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+	return remotePath(filepath.ToSlash(abs)), nil
+}
+
+func (local) mkdir(name string, perm fs.FileMode) error {
+	err := os.Mkdir("/"+name, perm)
+	if os.IsExist(err) {
+		if info, serr := os.Stat("/" + name); serr == nil && info.IsDir() {
+			return nil
+		}
+	}
+	return err
+}
+
+func (local) create(name string, perm fs.FileMode) (io.WriteCloser, error) {
+	return os.OpenFile("/"+name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+}
+
+// job is a file to copy.
+type job struct {
+	src, dst string
+	size     int64
+	perm     fs.FileMode
+}
+
+// progress counts what a transfer has done.
+type progress struct {
+	files, totalFiles int64
+	bytes, totalBytes int64
+}
+
+func (p *progress) String() string {
+	return fmt.Sprintf("%d/%d files, %s/%s",
+		atomic.LoadInt64(&p.files), p.totalFiles,
+		humanSize(uint64(atomic.LoadInt64(&p.bytes))), humanSize(uint64(p.totalBytes)))
+}
+
+// counter counts the bytes written through it.
+type counter struct {
+	w io.Writer
+	n *int64
+}
+
+func (c counter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	atomic.AddInt64(c.n, int64(n))
+	return n, err
+}
+
+// plan walks srcName and creates the directories of the copy at dstName,
+// returning the files to copy.
+func (e *env) plan(src side, srcName string, dst side, dstName string, recursive bool) ([]job, error) {
+	info, err := src.Stat(srcName)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() && !recursive {
+		return nil, fmt.Errorf("%s is a directory (not copied without -r)", srcName)
+	}
+	if dinfo, err := dst.Stat(dstName); err == nil && dinfo.IsDir() && srcName != "." {
+		dstName = path.Join(dstName, path.Base(srcName))
+	}
+
+	var jobs []job
+	err = fs.WalkDir(src, srcName, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		target := dstName
+		if p != srcName {
+			target = path.Join(dstName, strings.TrimPrefix(p, srcName+"/"))
+			if srcName == "." {
+				target = path.Join(dstName, p)
+			}
+		}
+		switch {
+		case d.IsDir():
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			return dst.mkdir(target, info.Mode().Perm()|0700)
+		case d.Type().IsRegular():
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			jobs = append(jobs, job{src: p, dst: target, size: info.Size(), perm: info.Mode().Perm()})
+		default:
+			fmt.Fprintf(e.log, "skipping %s: %s\n", p, d.Type())
+		}
+		return nil
+	})
+	return jobs, err
+}
+
+func copyFile(src, dst side, j job, buf []byte, prog *progress) (err error) {
+	r, err := src.Open(j.src)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	w, err := dst.create(j.dst, j.perm)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	_, err = io.CopyBuffer(counter{w: w, n: &prog.bytes}, r, buf)
+	return err
+}
+
+// transfer copies srcName of src to dstName of dst, e.jobs files at a
+// time.
+func (e *env) transfer(src side, srcName string, dst side, dstName string, recursive bool) error {
+	jobs, err := e.plan(src, srcName, dst, dstName, recursive)
+	if err != nil {
+		return err
+	}
+	prog := &progress{totalFiles: int64(len(jobs))}
+	for _, j := range jobs {
+		prog.totalBytes += j.size
+	}
+	if e.progress {
+		done := make(chan struct{})
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			t := time.NewTicker(200 * time.Millisecond)
+			defer t.Stop()
+			for {
+				select {
+				case <-t.C:
+					fmt.Fprintf(e.log, "\r%s", prog)
+				case <-done:
+					fmt.Fprintf(e.log, "\r%s\n", prog)
+					return
+				}
+			}
+		}()
+		defer func() {
+			close(done)
+			<-stopped
+		}()
+	}
+
+	workers := e.jobs
+	if workers < 1 {
+		workers = 1
+	}
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		next     int64 = -1
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, copyBufSize)
+			for {
+				i := int(atomic.AddInt64(&next, 1))
+				mu.Lock()
+				failed := firstErr != nil
+				mu.Unlock()
+				if i >= len(jobs) || failed {
+					return
+				}
+				if err := copyFile(src, dst, jobs[i], buf, prog); err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					return
+				}
+				atomic.AddInt64(&prog.files, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+func transferFlags(name string, args []string) (bool, []string, error) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	recursive := flags.Bool("r", false, "copy directories recursively")
+	args, err := parse(flags, args, 2, 2)
+	return *recursive, args, err
+}
+
+func get(e *env, args []string) error {
+	recursive, args, err := transferFlags("get", args)
+	if err != nil {
+		return err
+	}
+	dst, err := localPath(args[1])
+	if err != nil {
+		return err
+	}
+	return e.transfer(remote{e.c}, remotePath(args[0]), newLocal(), dst, recursive)
+}
+
+func put(e *env, args []string) error {
+	recursive, args, err := transferFlags("put", args)
+	if err != nil {
+		return err
+	}
+	src, err := localPath(args[0])
+	if err != nil {
+		return err
+	}
+	return e.transfer(newLocal(), src, remote{e.c}, remotePath(args[1]), recursive)
+}
+
+func cp(e *env, args []string) error {
+	recursive, args, err := transferFlags("cp", args)
+	if err != nil {
+		return err
+	}
+	return e.transfer(remote{e.c}, remotePath(args[0]), remote{e.c}, remotePath(args[1]), recursive)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tree reads the regular files below dir of fsys by their path from dir.
+func tree(t *testing.T, fsys fs.FS, dir string) map[string]string {
+	files := map[string]string{}
+	require.NoError(t, fs.WalkDir(fsys, dir, func(p string, d fs.DirEntry, err error) error {
+		require.NoError(t, err)
+		if d.Type().IsRegular() {
+			data, err := fs.ReadFile(fsys, p)
+			require.NoError(t, err)
+			files[strings.TrimPrefix(p, dir+"/")] = string(data)
+		}
+		return nil
+	}))
+	return files
+}
+
+func localTree(t *testing.T) (string, map[string]string) {
+	dir := t.TempDir()
+	want := map[string]string{
+		"a":       "a",
+		"b/c":     "ccc",
+		"b/d/e":   string(bytes.Repeat([]byte("e"), 3<<20)),
+		"b/empty": "",
+	}
+	for name, content := range want {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(t, ioutil.WriteFile(p, []byte(content), 0644))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "emptydir"), 0755))
+	require.NoError(t, os.Symlink("a", filepath.Join(dir, "link")))
+	return dir, want
+}
+
+func TestPutGet(t *testing.T) {
+	e, _ := newEnv(t, nil)
+	e.progress = true
+	src, want := localTree(t)
+
+	require.NoError(t, put(e, []string{"-r", src, "/up"}))
+	assert.Equal(t, want, tree(t, e.c, "up"))
+	info, err := e.c.Stat("up/emptydir")
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+	log := e.log.(*bytes.Buffer).String()
+	assert.Contains(t, log, "skipping ")
+	assert.Contains(t, log, "\r4/4 files, 3.0MiB/3.0MiB\n")
+
+	// Into an existing directory, like cp.
+	require.NoError(t, e.c.Mkdir("into", 0755))
+	require.NoError(t, put(e, []string{"-r", src, "into"}))
+	assert.Equal(t, want, tree(t, e.c, "into/"+filepath.Base(src)))
+
+	dst := t.TempDir()
+	require.NoError(t, get(e, []string{"-r", "up", filepath.Join(dst, "down")}))
+	assert.Equal(t, want, tree(t, os.DirFS(dst), "down"))
+
+	require.NoError(t, get(e, []string{"up/b/c", dst}))
+	data, err := ioutil.ReadFile(filepath.Join(dst, "c"))
+	require.NoError(t, err)
+	assert.Equal(t, "ccc", string(data))
+}
+
+func TestCp(t *testing.T) {
+	e, _ := newEnv(t, nil)
+	e.jobs = 1
+	require.NoError(t, e.c.Mkdir("dir", 0755))
+	require.NoError(t, e.c.Mkdir("dir/sub", 0755))
+	writeRemote(t, e, "dir/a", "aaa")
+	writeRemote(t, e, "dir/sub/b", "bb")
+
+	require.NoError(t, cp(e, []string{"-r", "dir", "copy"}))
+	assert.Equal(t, map[string]string{"a": "aaa", "sub/b": "bb"}, tree(t, e.c, "copy"))
+
+	// Files are overwritten.
+	writeRemote(t, e, "dir/a", "a")
+	require.NoError(t, cp(e, []string{"dir/a", "copy"}))
+	assert.Equal(t, map[string]string{"a": "a", "sub/b": "bb"}, tree(t, e.c, "copy"))
+
+	err := cp(e, []string{"dir", "other"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "-r")
+	assert.Error(t, cp(e, []string{"nope", "other"}))
+	assert.IsType(t, usageError{}, cp(e, []string{"dir"}))
+}
+
+func TestTransferError(t *testing.T) {
+	e, _ := newEnv(t, nil)
+	require.NoError(t, e.c.Mkdir("dir", 0755))
+	writeRemote(t, e, "dir/a", "aaa")
+	writeRemote(t, e, "file", "")
+
+	// dir/a cannot be created below a file.
+	err := cp(e, []string{"-r", "dir", "file/x"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,41 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import "syscall"
+
+// defaultMountFlags are those of fusermount.
+const defaultMountFlags = syscall.MS_NOSUID | syscall.MS_NODEV
+
+var mountFlags = map[string]mountFlag{
+	"nosuid":      {syscall.MS_NOSUID, true},
+	"suid":        {syscall.MS_NOSUID, false},
+	"nodev":       {syscall.MS_NODEV, true},
+	"dev":         {syscall.MS_NODEV, false},
+	"noexec":      {syscall.MS_NOEXEC, true},
+	"exec":        {syscall.MS_NOEXEC, false},
+	"noatime":     {syscall.MS_NOATIME, true},
+	"atime":       {syscall.MS_NOATIME, false},
+	"nodiratime":  {syscall.MS_NODIRATIME, true},
+	"diratime":    {syscall.MS_NODIRATIME, false},
+	"relatime":    {syscall.MS_RELATIME, true},
+	"norelatime":  {syscall.MS_RELATIME, false},
+	"strictatime": {syscall.MS_STRICTATIME, true},
+	"sync":        {syscall.MS_SYNCHRONOUS, true},
+	"async":       {syscall.MS_SYNCHRONOUS, false},
+	"dirsync":     {syscall.MS_DIRSYNC, true},
+}
@@ -0,0 +1,175 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Command mount.grpcfuse is the mount(8) helper of grpcfuse, so that
+// fstab entries like
+//
+//	server:8760:/export /mnt/data grpcfuse _netdev,ro,tls,ca=/etc/ca.pem 0 0
+//
+// and mount -t grpcfuse work. It goes to the background once the file
+// system is mounted, and exits with the codes of mount(8).
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/chiyutianyi/grpcfuse/grpc2fuse"
+)
+
+// Exit codes of mount(8).
+const (
+	exitOK      = 0
+	exitUsage   = 1
+	exitSystem  = 2
+	exitFailure = 32
+)
+
+const usage = "usage: mount.grpcfuse host:port[:/export] mountpoint [-sfnv] [-o options]"
+
+// daemonEnv is set in the environment of the background process.
+const daemonEnv = "_GRPCFUSE_MOUNT_DAEMON"
+
+// readyFd is where the background process reports the outcome of the
+// mount: a line "ok", or the error.
+const readyFd = 3
+
+func run(args []string, stderr io.Writer) int {
+	cfg, err := parseArgs(args)
+	if err != nil {
+		fmt.Fprintf(stderr, "mount.grpcfuse: %v\n%s\n", err, usage)
+		return exitUsage
+	}
+	if cfg.verbose {
+		fmt.Fprintf(stderr, "mount.grpcfuse: mounting %s (export %q) on %s\n", cfg.target, cfg.opts.Export, cfg.mountpoint)
+	}
+	if cfg.fake {
+		return exitOK
+	}
+	if cfg.foreground {
+		return serve(cfg, stderr, nil)
+	}
+	return daemonize(args, cfg.verbose, stderr)
+}
+
+// daemonize runs the mount in a new session and waits for it to report
+// that the file system is mounted.
+func daemonize(args []string, verbose bool, stderr io.Writer) int {
+	exe, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(stderr, "mount.grpcfuse: %v\n", err)
+		return exitSystem
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		fmt.Fprintf(stderr, "mount.grpcfuse: %v\n", err)
+		return exitSystem
+	}
+	defer r.Close()
+	cmd := exec.Command(exe, args...)
+	cmd.Env = append(os.Environ(), daemonEnv+"=1")
+	cmd.ExtraFiles = []*os.File{w}
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	// Not to keep a directory busy.
+	cmd.Dir = "/"
+	if verbose {
+		cmd.Stderr = stderr
+	}
+	err = cmd.Start()
+	w.Close()
+	if err != nil {
+		fmt.Fprintf(stderr, "mount.grpcfuse: %v\n", err)
+		return exitSystem
+	}
+	// The daemon outlives us.
+	go cmd.Wait()
+
+	line, err := bufio.NewReader(r).ReadString('\n')
+	line = strings.TrimSpace(line)
+	switch {
+	case line == "ok":
+		return exitOK
+	case line != "":
+		fmt.Fprintf(stderr, "mount.grpcfuse: %s\n", line)
+	default:
+		fmt.Fprintf(stderr, "mount.grpcfuse: mount process died: %v\n", err)
+	}
+	return exitFailure
+}
+
+// serve mounts the file system and serves it until it is unmounted. The
+// outcome of the mount goes to ready, if not nil.
+func serve(cfg *config, stderr io.Writer, ready io.WriteCloser) int {
+	if cfg.verbose {
+		log.SetLevel(log.DebugLevel)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.timeout)
+	m, err := grpc2fuse.Mount(ctx, cfg.mountpoint, cfg.target, &cfg.opts)
+	cancel()
+	if err != nil {
+		if ready != nil {
+			fmt.Fprintln(ready, err)
+			ready.Close()
+		} else {
+			fmt.Fprintf(stderr, "mount.grpcfuse: %v\n", err)
+		}
+		return exitFailure
+	}
+	if ready != nil {
+		fmt.Fprintln(ready, "ok")
+		ready.Close()
+	}
+
+	signal.Ignore(syscall.SIGPIPE, syscall.SIGHUP)
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		for range sigCh {
+			if err := m.Unmount(); err != nil {
+				log.Errorf("Unmount %s: %v", cfg.mountpoint, err)
+			}
+		}
+	}()
+	m.Wait()
+	return exitOK
+}
+
+// daemon is the background process started by daemonize.
+func daemon(args []string) int {
+	ready := os.NewFile(readyFd, "ready")
+	cfg, err := parseArgs(args)
+	if err != nil {
+		fmt.Fprintln(ready, err)
+		return exitUsage
+	}
+	return serve(cfg, os.Stderr, ready)
+}
+
+func main() {
+	if os.Getenv(daemonEnv) != "" {
+		os.Exit(daemon(os.Args[1:]))
+	}
+	os.Exit(run(os.Args[1:], os.Stderr))
+}
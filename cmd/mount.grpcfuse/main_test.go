@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/chiyutianyi/grpcfuse/exports"
+	"github.com/chiyutianyi/grpcfuse/fuse2grpc"
+	"github.com/chiyutianyi/grpcfuse/grpc2fuse"
+	"github.com/chiyutianyi/grpcfuse/memfs"
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+// TestMain runs the background process of the mounts the tests start.
+func TestMain(m *testing.M) {
+	if os.Getenv(daemonEnv) != "" {
+		os.Exit(daemon(os.Args[1:]))
+	}
+	os.Exit(m.Run())
+}
+
+// startServer serves memfs as export "a" over TCP.
+func startServer(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := grpc.NewServer()
+	r := exports.New()
+	r.Set(map[string]pb.RawFileSystemServer{"a": fuse2grpc.NewServer(memfs.New(nil, nil))}, "")
+	r.Register(s)
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+	return l.Addr().String()
+}
+
+func TestRunErrors(t *testing.T) {
+	addr := startServer(t)
+	mnt := t.TempDir()
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stderr string
+	}{
+		{name: "usage", args: []string{addr}, code: exitUsage, stderr: "usage: mount.grpcfuse"},
+		{name: "fake", args: []string{"-fv", addr + ":/a", mnt}, code: exitOK, stderr: `export "a"`},
+		{name: "unknown export", args: []string{addr + ":/nope", mnt, "-o", "timeout=5"}, code: exitFailure, stderr: "unknown export"},
+		{name: "no server", args: []string{"unix:" + filepath.Join(mnt, "nope"), mnt, "-o", "timeout=0.1"}, code: exitFailure, stderr: "handshake"},
+		{name: "foreground", args: []string{addr + ":/nope", mnt, "-o", "timeout=5,foreground"}, code: exitFailure, stderr: "unknown export"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			assert.Equal(t, tt.code, run(tt.args, &stderr))
+			assert.Contains(t, stderr.String(), tt.stderr)
+		})
+	}
+}
+
+func TestMount(t *testing.T) {
+	addr := startServer(t)
+	mnt := t.TempDir()
+
+	// Mounting may not be allowed where the tests run.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	probe, err := grpc2fuse.Mount(ctx, mnt, addr, &grpc2fuse.MountOptions{Export: "a"})
+	if err != nil {
+		t.Skipf("cannot mount: %v", err)
+	}
+	require.NoError(t, probe.Unmount())
+
+	var stderr bytes.Buffer
+	require.Equal(t, exitOK, run([]string{addr + ":/a", mnt, "-o", "timeout=5,noatime"}, &stderr), stderr.String())
+	mounted := true
+	defer func() {
+		if mounted {
+			syscall.Unmount(mnt, 0)
+		}
+	}()
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(mnt, "file"), []byte("hello"), 0644))
+	data, err := ioutil.ReadFile(filepath.Join(mnt, "file"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+	mounts, err := ioutil.ReadFile("/proc/self/mountinfo")
+	require.NoError(t, err)
+	assert.Contains(t, string(mounts), " fuse.grpcfuse "+addr+":/a ")
+
+	require.NoError(t, syscall.Unmount(mnt, 0))
+	mounted = false
+}
@@ -0,0 +1,349 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chiyutianyi/grpcfuse/exports"
+	"github.com/chiyutianyi/grpcfuse/grpc2fuse"
+)
+
+// mountFlag is a flag of mount(2) an option sets or clears.
+type mountFlag struct {
+	flag uintptr
+	set  bool
+}
+
+// ignored are the options meant for mount(8), fstab tools and systemd.
+var ignored = map[string]bool{
+	"_netdev":  true,
+	"defaults": true,
+	"auto":     true,
+	"noauto":   true,
+	"user":     true,
+	"nouser":   true,
+	"users":    true,
+	"owner":    true,
+	"group":    true,
+	"nofail":   true,
+	"rw":       true,
+}
+
+// config is what a command line asks to mount.
+type config struct {
+	source, mountpoint string
+	// target is the server to dial.
+	target string
+	opts   grpc2fuse.MountOptions
+	// timeout bounds dialing and mounting.
+	timeout time.Duration
+	// foreground stays in the foreground instead of daemonizing.
+	foreground bool
+	// fake does everything but mount, like mount -f.
+	fake    bool
+	verbose bool
+	sloppy  bool
+
+	useTLS                    bool
+	ca, cert, key, serverName string
+}
+
+// parseSource splits "host:port:/export" into the server and the export.
+// "unix:/path" is a Unix socket, "unix:/path:/export" an export on it.
+func parseSource(source string) (target, export string, err error) {
+	if source == "" {
+		return "", "", fmt.Errorf("no server given")
+	}
+	i := strings.LastIndex(source, ":/")
+	if i <= 0 || source[:i] == "unix" {
+		return source, "", nil
+	}
+	return source[:i], exports.Name(source[i+1:]), nil
+}
+
+// parseDuration reads seconds, as FUSE options such as attr_timeout=1.5
+// are given, or a Go duration such as 100ms.
+func parseDuration(s string) (time.Duration, error) {
+	if f, err := strconv.ParseFloat(s, 64); err == nil && f >= 0 {
+		return time.Duration(f * float64(time.Second)), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("bad duration %q", s)
+	}
+	return d, nil
+}
+
+// parseOptions applies the comma separated options of -o to cfg.
+func (cfg *config) parseOptions(options string) error {
+	for _, opt := range strings.Split(options, ",") {
+		if opt == "" {
+			continue
+		}
+		name, value := opt, ""
+		hasValue := false
+		if i := strings.IndexByte(opt, '='); i >= 0 {
+			name, value, hasValue = opt[:i], opt[i+1:], true
+		}
+		if err := cfg.parseOption(name, value, hasValue); err != nil {
+			if cfg.sloppy {
+				continue
+			}
+			return fmt.Errorf("option %s: %v", opt, err)
+		}
+	}
+	return nil
+}
+
+func (cfg *config) parseOption(name, value string, hasValue bool) error {
+	var err error
+	if mf, ok := mountFlags[name]; ok && !hasValue {
+		if mf.set {
+			cfg.opts.MountFlags |= mf.flag
+		} else {
+			cfg.opts.MountFlags &^= mf.flag
+		}
+		return nil
+	}
+	if ignored[name] && !hasValue || strings.HasPrefix(name, "x-") || name == "comment" {
+		return nil
+	}
+
+	flag := func(b *bool) error {
+		if hasValue {
+			return fmt.Errorf("takes no value")
+		}
+		*b = true
+		return nil
+	}
+	duration := func(d *time.Duration) error {
+		*d, err = parseDuration(value)
+		return err
+	}
+	number := func(n *int) error {
+		v, err := strconv.ParseUint(value, 10, 31)
+		*n = int(v)
+		return err
+	}
+	str := func(s *string) error {
+		if value == "" {
+			return fmt.Errorf("needs a value")
+		}
+		*s = value
+		return nil
+	}
+
+	switch name {
+	case "ro":
+		return flag(&cfg.opts.ReadOnly)
+	case "allow_other":
+		return flag(&cfg.opts.AllowOther)
+	case "default_permissions":
+		// Always on.
+		return flag(new(bool))
+	case "debug":
+		return flag(&cfg.opts.Debug)
+	case "foreground":
+		return flag(&cfg.foreground)
+	case "tls":
+		return flag(&cfg.useTLS)
+	case "shm":
+		return flag(&cfg.opts.SharedMemory)
+	case "offline":
+		return flag(&cfg.opts.Offline)
+	case "journal":
+		cfg.opts.Offline = true
+		return flag(&cfg.opts.Journal)
+	case "conflicts":
+		cfg.opts.Conflicts, err = grpc2fuse.ParseConflictPolicy(value)
+		return err
+	case "export":
+		cfg.opts.Export = exports.Name(value)
+		return nil
+	case "attr_timeout":
+		return duration(&cfg.opts.AttrTimeout)
+	case "entry_timeout":
+		return duration(&cfg.opts.EntryTimeout)
+	case "negative_timeout":
+		return duration(&cfg.opts.NegativeTimeout)
+	case "attr_cache":
+		return duration(&cfg.opts.AttrCache.AttrTimeout)
+	case "entry_cache":
+		return duration(&cfg.opts.AttrCache.EntryTimeout)
+	case "negative_cache":
+		return duration(&cfg.opts.AttrCache.NegativeTimeout)
+	case "readlink_cache":
+		return duration(&cfg.opts.AttrCache.ReadlinkTimeout)
+	case "retry_backoff":
+		return duration(&cfg.opts.RetryBackoff)
+	case "timeout":
+		return duration(&cfg.timeout)
+	case "retries":
+		return number(&cfg.opts.Retries)
+	case "max_write":
+		return number(&cfg.opts.MaxWrite)
+	case "max_readahead":
+		return number(&cfg.opts.MaxReadAhead)
+	case "conns":
+		return number(&cfg.opts.Connections)
+	case "stripe_size":
+		return number(&cfg.opts.StripeSize)
+	case "read_ahead":
+		return number(&cfg.opts.ReadAhead)
+	case "write_behind":
+		return number(&cfg.opts.WriteBehind)
+	case "small_files":
+		return number(&cfg.opts.SmallFiles)
+	case "cache_dir":
+		return str(&cfg.opts.CacheDir)
+	case "cache_size":
+		cfg.opts.CacheSize, err = strconv.ParseInt(value, 10, 64)
+		return err
+	case "max_read":
+		if _, err := strconv.ParseUint(value, 10, 31); err != nil {
+			return err
+		}
+		cfg.opts.Options = append(cfg.opts.Options, name+"="+value)
+		return nil
+	case "fsname":
+		return str(&cfg.opts.FsName)
+	case "subtype":
+		return str(&cfg.opts.Name)
+	case "ca":
+		cfg.useTLS = true
+		return str(&cfg.ca)
+	case "cert":
+		cfg.useTLS = true
+		return str(&cfg.cert)
+	case "key":
+		cfg.useTLS = true
+		return str(&cfg.key)
+	case "servername":
+		cfg.useTLS = true
+		return str(&cfg.serverName)
+	}
+	return fmt.Errorf("unknown option")
+}
+
+// tlsConfig returns the TLS configuration of the options, nil without tls.
+func (cfg *config) tlsConfig() (*tls.Config, error) {
+	if !cfg.useTLS {
+		return nil, nil
+	}
+	tc := &tls.Config{ServerName: cfg.serverName, MinVersion: tls.VersionTLS12}
+	if cfg.ca != "" {
+		pem, err := ioutil.ReadFile(cfg.ca)
+		if err != nil {
+			return nil, err
+		}
+		tc.RootCAs = x509.NewCertPool()
+		if !tc.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", cfg.ca)
+		}
+	}
+	if (cfg.cert == "") != (cfg.key == "") {
+		return nil, fmt.Errorf("cert and key go together")
+	}
+	if cfg.cert != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.cert, cfg.key)
+		if err != nil {
+			return nil, err
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	}
+	return tc, nil
+}
+
+// parseArgs parses the command line mount(8) runs helpers with:
+//
+//	mount.grpcfuse source mountpoint [-sfnv] [-o options] [-t type]
+func parseArgs(args []string) (*config, error) {
+	cfg := &config{timeout: 30 * time.Second}
+	cfg.opts.MountFlags = defaultMountFlags
+	var positional []string
+	var options []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			positional = append(positional, arg)
+			continue
+		}
+		switch arg {
+		case "-o", "-t", "-N":
+			if i+1 == len(args) {
+				return nil, fmt.Errorf("%s needs a value", arg)
+			}
+			i++
+			if arg == "-o" {
+				options = append(options, args[i])
+			}
+			continue
+		}
+		if strings.HasPrefix(arg, "-o") {
+			options = append(options, arg[2:])
+			continue
+		}
+		for _, c := range arg[1:] {
+			switch c {
+			case 's':
+				cfg.sloppy = true
+			case 'f':
+				cfg.fake = true
+			case 'n':
+				// There is no mtab to leave alone.
+			case 'v':
+				cfg.verbose = true
+			default:
+				return nil, fmt.Errorf("unknown flag -%c", c)
+			}
+		}
+	}
+	if len(positional) != 2 {
+		return nil, fmt.Errorf("need a source and a mount point")
+	}
+	cfg.source = positional[0]
+	var err error
+	if cfg.mountpoint, err = filepath.Abs(positional[1]); err != nil {
+		return nil, err
+	}
+	if cfg.target, cfg.opts.Export, err = parseSource(cfg.source); err != nil {
+		return nil, err
+	}
+	cfg.opts.FsName = cfg.source
+	cfg.opts.Name = "grpcfuse"
+	// Options come after the flags, so -s applies to all of them.
+	for _, o := range options {
+		if err := cfg.parseOptions(o); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.opts.TLS, err = cfg.tlsConfig(); err != nil {
+		return nil, fmt.Errorf("tls: %v", err)
+	}
+	if cfg.opts.SharedMemory && !strings.HasPrefix(cfg.target, "unix:") {
+		return nil, fmt.Errorf("shm needs a unix: source")
+	}
+	return cfg, nil
+}
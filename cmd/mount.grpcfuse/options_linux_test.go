@@ -0,0 +1,19 @@
+package main
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMountFlags(t *testing.T) {
+	cfg, err := parseArgs([]string{"s:1", "/mnt", "-o", "noatime,noexec,suid", "-odev"})
+	require.NoError(t, err)
+	assert.Equal(t, uintptr(syscall.MS_NOATIME|syscall.MS_NOEXEC), cfg.opts.MountFlags)
+
+	cfg, err = parseArgs([]string{"s:1", "/mnt"})
+	require.NoError(t, err)
+	assert.Equal(t, uintptr(syscall.MS_NOSUID|syscall.MS_NODEV), cfg.opts.MountFlags)
+}
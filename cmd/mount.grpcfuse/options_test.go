@@ -0,0 +1,163 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chiyutianyi/grpcfuse/grpc2fuse"
+)
+
+func TestParseSource(t *testing.T) {
+	tests := []struct {
+		source, target, export string
+	}{
+		{source: "server:8760:/export", target: "server:8760", export: "export"},
+		{source: "server:8760:/a/b/", target: "server:8760", export: "a/b"},
+		{source: "server:8760", target: "server:8760"},
+		{source: "[::1]:8760:/export", target: "[::1]:8760", export: "export"},
+		{source: "unix:/run/grpcfuse.sock", target: "unix:/run/grpcfuse.sock"},
+		{source: "unix:/run/grpcfuse.sock:/export", target: "unix:/run/grpcfuse.sock", export: "export"},
+	}
+	for _, tt := range tests {
+		target, export, err := parseSource(tt.source)
+		require.NoError(t, err, tt.source)
+		assert.Equal(t, tt.target, target, tt.source)
+		assert.Equal(t, tt.export, export, tt.source)
+	}
+	_, _, err := parseSource("")
+	assert.Error(t, err)
+}
+
+func TestParseArgs(t *testing.T) {
+	cfg, err := parseArgs([]string{"server:8760:/data", "/mnt/data"})
+	require.NoError(t, err)
+	assert.Equal(t, "server:8760", cfg.target)
+	assert.Equal(t, "/mnt/data", cfg.mountpoint)
+	assert.Equal(t, "data", cfg.opts.Export)
+	assert.Equal(t, "server:8760:/data", cfg.opts.FsName)
+	assert.Equal(t, "grpcfuse", cfg.opts.Name)
+	assert.Equal(t, uintptr(defaultMountFlags), cfg.opts.MountFlags)
+	assert.Equal(t, 30*time.Second, cfg.timeout)
+	assert.Nil(t, cfg.opts.TLS)
+	assert.False(t, cfg.foreground || cfg.fake || cfg.verbose || cfg.sloppy)
+
+	tests := []struct {
+		name  string
+		args  []string
+		check func(t *testing.T, cfg *config)
+		err   string
+	}{
+		{
+			name: "fstab",
+			args: []string{"server:8760:/export", "/mnt", "-o", "_netdev,ro,noauto,nofail,x-systemd.requires=network.target,comment=x"},
+			check: func(t *testing.T, cfg *config) {
+				assert.True(t, cfg.opts.ReadOnly)
+				assert.Equal(t, "export", cfg.opts.Export)
+			},
+		},
+		{
+			name: "flags",
+			args: []string{"-fnv", "s:1", "/mnt", "-s", "-t", "grpcfuse"},
+			check: func(t *testing.T, cfg *config) {
+				assert.True(t, cfg.fake && cfg.verbose && cfg.sloppy)
+			},
+		},
+		{
+			name: "client options",
+			args: []string{"s:1", "/mnt", "-o", "allow_other,default_permissions,export=/other,attr_timeout=1.5,entry_timeout=2,negative_timeout=100ms,attr_cache=1,entry_cache=2,negative_cache=3,readlink_cache=1m,retries=3,retry_backoff=1s,timeout=5,max_write=131072,max_readahead=65536,conns=4,stripe_size=262144,read_ahead=4194304,write_behind=8388608,small_files=65536,cache_dir=/var/cache/grpcfuse,cache_size=10737418240,offline,journal,conflicts=last-writer-wins,max_read=4096,fsname=data,subtype=gf,foreground,debug"},
+			check: func(t *testing.T, cfg *config) {
+				o := cfg.opts
+				assert.True(t, o.AllowOther)
+				assert.Equal(t, "other", o.Export)
+				assert.Equal(t, 1500*time.Millisecond, o.AttrTimeout)
+				assert.Equal(t, 2*time.Second, o.EntryTimeout)
+				assert.Equal(t, 100*time.Millisecond, o.NegativeTimeout)
+				assert.Equal(t, grpc2fuse.AttrCacheOptions{AttrTimeout: time.Second, EntryTimeout: 2 * time.Second, NegativeTimeout: 3 * time.Second, ReadlinkTimeout: time.Minute}, o.AttrCache)
+				assert.Equal(t, 3, o.Retries)
+				assert.Equal(t, time.Second, o.RetryBackoff)
+				assert.Equal(t, 5*time.Second, cfg.timeout)
+				assert.Equal(t, 131072, o.MaxWrite)
+				assert.Equal(t, 65536, o.MaxReadAhead)
+				assert.Equal(t, 4, o.Connections)
+				assert.Equal(t, 262144, o.StripeSize)
+				assert.Equal(t, 4194304, o.ReadAhead)
+				assert.Equal(t, 8388608, o.WriteBehind)
+				assert.Equal(t, 65536, o.SmallFiles)
+				assert.Equal(t, "/var/cache/grpcfuse", o.CacheDir)
+				assert.Equal(t, int64(10737418240), o.CacheSize)
+				assert.True(t, o.Offline)
+				assert.True(t, o.Journal)
+				assert.Equal(t, grpc2fuse.LastWriterWins, o.Conflicts)
+				assert.Equal(t, []string{"max_read=4096"}, o.Options)
+				assert.Equal(t, "data", o.FsName)
+				assert.Equal(t, "gf", o.Name)
+				assert.True(t, cfg.foreground)
+				assert.True(t, o.Debug)
+			},
+		},
+		{
+			name: "tls",
+			args: []string{"s:1", "/mnt", "-o", "tls,servername=files.example.com"},
+			check: func(t *testing.T, cfg *config) {
+				require.NotNil(t, cfg.opts.TLS)
+				assert.Equal(t, "files.example.com", cfg.opts.TLS.ServerName)
+				assert.Nil(t, cfg.opts.TLS.RootCAs)
+			},
+		},
+		{
+			name: "shared memory",
+			args: []string{"unix:/run/grpcfuse.sock:/data", "/mnt", "-o", "shm"},
+			check: func(t *testing.T, cfg *config) {
+				assert.True(t, cfg.opts.SharedMemory)
+				assert.Equal(t, "unix:/run/grpcfuse.sock", cfg.target)
+			},
+		},
+		{
+			name: "sloppy",
+			args: []string{"-s", "s:1", "/mnt", "-o", "nope,ro,retries=x"},
+			check: func(t *testing.T, cfg *config) {
+				assert.True(t, cfg.opts.ReadOnly)
+			},
+		},
+		{name: "unknown option", args: []string{"s:1", "/mnt", "-o", "nope"}, err: "option nope: unknown option"},
+		{name: "flag with value", args: []string{"s:1", "/mnt", "-o", "ro=1"}, err: "takes no value"},
+		{name: "bad number", args: []string{"s:1", "/mnt", "-o", "retries=-1"}, err: "option retries=-1"},
+		{name: "bad duration", args: []string{"s:1", "/mnt", "-o", "attr_timeout=soon"}, err: "bad duration"},
+		{name: "bad conflicts", args: []string{"s:1", "/mnt", "-o", "journal,conflicts=mine"}, err: "unknown conflict policy"},
+		{name: "empty value", args: []string{"s:1", "/mnt", "-o", "fsname="}, err: "needs a value"},
+		{name: "missing ca", args: []string{"s:1", "/mnt", "-o", "ca=/nonexistent"}, err: "tls:"},
+		{name: "cert without key", args: []string{"s:1", "/mnt", "-o", "cert=/c.pem"}, err: "cert and key"},
+		{name: "tcp shm", args: []string{"s:1", "/mnt", "-o", "shm"}, err: "shm needs a unix: source"},
+		{name: "one argument", args: []string{"s:1"}, err: "need a source and a mount point"},
+		{name: "three arguments", args: []string{"s:1", "/mnt", "x"}, err: "need a source and a mount point"},
+		{name: "missing -o value", args: []string{"s:1", "/mnt", "-o"}, err: "-o needs a value"},
+		{name: "unknown flag", args: []string{"-x", "s:1", "/mnt"}, err: "unknown flag -x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := parseArgs(tt.args)
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	for in, want := range map[string]time.Duration{"0": 0, "1": time.Second, "0.25": 250 * time.Millisecond, "2m": 2 * time.Minute} {
+		d, err := parseDuration(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, d, in)
+	}
+	for _, in := range []string{"", "-1", "-1s", "x"} {
+		_, err := parseDuration(in)
+		assert.Error(t, err, in)
+	}
+}
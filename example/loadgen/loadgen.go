@@ -0,0 +1,192 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+
+	"github.com/chiyutianyi/grpcfuse/grpc2fuse"
+	"github.com/chiyutianyi/grpcfuse/grpcfusetest"
+	"github.com/chiyutianyi/grpcfuse/loadgen"
+	"github.com/chiyutianyi/grpcfuse/memfs"
+	"github.com/chiyutianyi/grpcfuse/pb"
+	"github.com/chiyutianyi/grpcfuse/pkg/utils"
+	"github.com/chiyutianyi/grpcfuse/shm"
+)
+
+type config struct {
+	addr        string
+	shm         bool
+	conns       int
+	readAhead   int
+	writeBehind int
+	transport   string
+	threshold   int
+	workloads   string
+	concurrency int
+	duration    time.Duration
+	opts        loadgen.Options
+}
+
+// chunk is the size of the chunks fetched ahead: the size of the reads.
+func (cfg *config) chunk() int {
+	if cfg.opts.Size == 0 {
+		return 64 << 10
+	}
+	return cfg.opts.Size
+}
+
+// connect returns a client of the server at addr, or of memfs served in
+// process over transport.
+func connect(cfg *config) (fuse.RawFileSystem, func(), error) {
+	if cfg.addr != "" {
+		return dial(cfg)
+	}
+
+	opts := &grpcfusetest.Options{MsgSizeThreshold: cfg.threshold, SharedMemory: cfg.transport == "shm", Connections: cfg.conns, ReadAhead: cfg.readAhead, ReadAheadChunk: cfg.chunk(), WriteBehind: cfg.writeBehind}
+	cleanup := func() {}
+	switch cfg.transport {
+	case "bufconn":
+	case "tcp":
+		var err error
+		if opts.Listener, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+			return nil, nil, err
+		}
+	case "unix", "shm":
+		dir, err := ioutil.TempDir("", "loadgen")
+		if err != nil {
+			return nil, nil, err
+		}
+		cleanup = func() { os.RemoveAll(dir) }
+		if opts.Listener, err = net.Listen("unix", filepath.Join(dir, "sock")); err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, fmt.Errorf("unknown transport %q", cfg.transport)
+	}
+	p, err := grpcfusetest.NewPair(memfs.New(nil, nil), opts)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return p.Client, func() { p.Close(); cleanup() }, nil
+}
+
+// dial connects to the server at addr, with the data of reads and writes
+// in shared memory if shm is set and over conns more connections.
+func dial(cfg *config) (fuse.RawFileSystem, func(), error) {
+	opts := []grpc.DialOption{grpc.WithInsecure()}
+	var c *shm.Client
+	if cfg.shm {
+		path := strings.TrimPrefix(strings.TrimPrefix(cfg.addr, "unix://"), "unix:")
+		if path == cfg.addr {
+			return nil, nil, fmt.Errorf("-shm needs a unix: address")
+		}
+		var err error
+		if c, err = shm.Dial(path+".shm", nil); err != nil {
+			return nil, nil, err
+		}
+		opts = append(opts,
+			grpc.WithChainUnaryInterceptor(c.UnaryClientInterceptor()),
+			grpc.WithChainStreamInterceptor(c.StreamClientInterceptor()))
+	}
+	var conns []*grpc.ClientConn
+	cleanup := func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+		if c != nil {
+			c.Close()
+		}
+	}
+	clients := make([]pb.RawFileSystemClient, 1+cfg.conns)
+	for i := range clients {
+		conn, err := grpc.Dial(cfg.addr, opts...)
+		if err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		conns = append(conns, conn)
+		clients[i] = pb.NewRawFileSystemClient(conn)
+	}
+	rfs := grpc2fuse.NewPooledFileSystem(grpc2fuse.NewPool(clients[0], clients[1:], 0))
+	rfs.SetReadAhead(cfg.readAhead, cfg.chunk())
+	rfs.SetWriteBehind(cfg.writeBehind, 0)
+	return rfs, cleanup, nil
+}
+
+// run runs the workloads one after the other and writes their results
+// to out.
+func run(cfg *config, out io.Writer) error {
+	names := loadgen.Names()
+	if cfg.workloads != "all" {
+		names = strings.Split(cfg.workloads, ",")
+	}
+	rfs, cleanup, err := connect(cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	for _, name := range names {
+		// The directory must not exist yet, also on a shared server.
+		dir := fmt.Sprintf("loadgen-%d-%d-%s", os.Getpid(), time.Now().UnixNano(), name)
+		res, err := loadgen.Run(rfs, name, dir, cfg.concurrency, cfg.duration, &cfg.opts)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		fmt.Fprintln(out, res)
+	}
+	return nil
+}
+
+func main() {
+	cfg := &config{}
+	flag.StringVar(&cfg.addr, "addr", "", "fuse2grpc server to load; memfs in process if empty")
+	flag.BoolVar(&cfg.shm, "shm", false, "move the data of reads and writes through shared memory, with a unix: address")
+	flag.IntVar(&cfg.conns, "conns", 0, "connections for reads and writes besides the one for metadata")
+	flag.IntVar(&cfg.readAhead, "read-ahead", 0, "bytes fetched past sequential reads, in chunks of -size")
+	flag.IntVar(&cfg.writeBehind, "write-behind", 0, "bytes of writes on their way to the server before writers wait; writes wait for the server if 0")
+	flag.StringVar(&cfg.transport, "transport", "bufconn", "bufconn, tcp, unix or shm, to reach memfs in process")
+	flag.IntVar(&cfg.threshold, "threshold", 0, "msgSizeThreshold of memfs in process; the fuse2grpc default if 0")
+	flag.StringVar(&cfg.workloads, "workloads", "all", "comma separated workloads: "+strings.Join(loadgen.Names(), ", "))
+	flag.IntVar(&cfg.concurrency, "concurrency", 1, "concurrent operations")
+	flag.DurationVar(&cfg.duration, "duration", 10*time.Second, "duration of each workload")
+	flag.IntVar(&cfg.opts.Size, "size", 0, "bytes per read, write or readdir buffer; 64KiB if 0")
+	flag.IntVar(&cfg.opts.FileSize, "file-size", 0, "size of the file read and written; 64MiB if 0")
+	flag.IntVar(&cfg.opts.Entries, "entries", 0, "entries of the directory listed; 10000 if 0")
+	loggerLevel := flag.String("logger-level", "warn", "log level")
+	flag.Parse()
+
+	log.SetLevel(utils.GetLogLevel(*loggerLevel))
+	if err := run(cfg, os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/chiyutianyi/grpcfuse/fuse2grpc"
+	"github.com/chiyutianyi/grpcfuse/loadgen"
+	"github.com/chiyutianyi/grpcfuse/memfs"
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+func TestRun(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := grpc.NewServer()
+	pb.RegisterRawFileSystemServer(s, fuse2grpc.NewServer(memfs.New(nil, nil)))
+	go s.Serve(l)
+	defer s.Stop()
+
+	opts := loadgen.Options{Size: 4096, FileSize: 1 << 20, Entries: 10}
+	tests := []struct {
+		name  string
+		cfg   config
+		lines int
+		err   bool
+	}{
+		{name: "bufconn", cfg: config{transport: "bufconn", workloads: "all"}, lines: len(loadgen.Names())},
+		{name: "unix", cfg: config{transport: "unix", threshold: 1024, workloads: "read,readdirplus"}, lines: 2},
+		{name: "server", cfg: config{addr: l.Addr().String(), workloads: "write"}, lines: 1},
+		{name: "twice on a server", cfg: config{addr: l.Addr().String(), workloads: "write"}, lines: 1},
+		{name: "tcp", cfg: config{transport: "tcp", workloads: "read"}, lines: 1},
+		{name: "tcp pool", cfg: config{transport: "tcp", conns: 2, workloads: "read,write"}, lines: 2},
+		{name: "pool on a server", cfg: config{addr: l.Addr().String(), conns: 2, readAhead: 64 << 10, workloads: "read"}, lines: 1},
+		{name: "read-ahead", cfg: config{transport: "bufconn", readAhead: 64 << 10, workloads: "read"}, lines: 1},
+		{name: "write-behind", cfg: config{transport: "bufconn", writeBehind: 1 << 20, workloads: "write"}, lines: 1},
+		{name: "shm", cfg: config{transport: "shm", workloads: "read,write"}, lines: 2},
+		{name: "shm on a tcp server", cfg: config{addr: l.Addr().String(), shm: true, workloads: "write"}, err: true},
+		{name: "bad transport", cfg: config{transport: "udp", workloads: "all"}, err: true},
+		{name: "bad workload", cfg: config{transport: "bufconn", workloads: "read,nope"}, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.concurrency = 2
+			tt.cfg.duration = 10 * time.Millisecond
+			tt.cfg.opts = opts
+			var out bytes.Buffer
+			err := run(&tt.cfg, &out)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, strings.Split(strings.TrimSpace(out.String()), "\n"), tt.lines, out.String())
+		})
+	}
+}
@@ -37,6 +37,7 @@ import (
 	"github.com/chiyutianyi/grpcfuse/fuse2grpc"
 	"github.com/chiyutianyi/grpcfuse/pb"
 	"github.com/chiyutianyi/grpcfuse/pkg/utils"
+	"github.com/chiyutianyi/grpcfuse/rpctrace"
 )
 
 func main() {
@@ -45,6 +46,7 @@ func main() {
 	quiet := flag.Bool("q", false, "quiet")
 	ro := flag.Bool("ro", false, "mount read-only")
 	loggerLevel := flag.String("logger-level", "info", "log level")
+	record := flag.String("record", "", "record the calls to this trace file")
 	flag.Parse()
 
 	if flag.NArg() < 1 {
@@ -62,17 +64,35 @@ func main() {
 	logEntry := logrus.NewEntry(logrus.StandardLogger())
 	grpc_logrus.ReplaceGrpcLogger(logEntry)
 
+	streamInterceptors := []grpc.StreamServerInterceptor{
+		grpc_prometheus.StreamServerInterceptor,
+		grpc_logrus.StreamServerInterceptor(logEntry),
+		grpc_recovery.StreamServerInterceptor(),
+	}
+	unaryInterceptors := []grpc.UnaryServerInterceptor{
+		grpc_prometheus.UnaryServerInterceptor,
+		grpc_logrus.UnaryServerInterceptor(logEntry),
+		grpc_recovery.UnaryServerInterceptor(),
+	}
+	var recorder *rpctrace.Recorder
+	if *record != "" {
+		f, err := os.Create(*record)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		defer f.Close()
+		if recorder, err = rpctrace.NewRecorder(f); err != nil {
+			logrus.Fatal(err)
+		}
+		// Outermost, so that panics turned into errors by recovery are
+		// recorded too.
+		streamInterceptors = append([]grpc.StreamServerInterceptor{recorder.StreamServerInterceptor()}, streamInterceptors...)
+		unaryInterceptors = append([]grpc.UnaryServerInterceptor{recorder.UnaryServerInterceptor()}, unaryInterceptors...)
+	}
+
 	s := grpc.NewServer(
-		grpc.StreamInterceptor(grpc_middleware.ChainStreamServer(
-			grpc_prometheus.StreamServerInterceptor,
-			grpc_logrus.StreamServerInterceptor(logEntry),
-			grpc_recovery.StreamServerInterceptor(),
-		)),
-		grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(
-			grpc_prometheus.UnaryServerInterceptor,
-			grpc_logrus.UnaryServerInterceptor(logEntry),
-			grpc_recovery.UnaryServerInterceptor(),
-		)),
+		grpc.StreamInterceptor(grpc_middleware.ChainStreamServer(streamInterceptors...)),
+		grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(unaryInterceptors...)),
 	)
 	grpc_prometheus.Register(s)
 
@@ -122,6 +142,11 @@ func main() {
 	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
 	for range sigCh {
 		s.Stop()
+		if recorder != nil {
+			if err := recorder.Flush(); err != nil {
+				logrus.Errorf("Record: %v", err)
+			}
+		}
 		logrus.Info("Shutdon")
 		return
 	}
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+
+	"github.com/chiyutianyi/grpcfuse/pkg/utils"
+	"github.com/chiyutianyi/grpcfuse/rpctrace"
+)
+
+// replay replays the trace in file and writes the report to out. It
+// reports whether the replay matched the trace.
+func replay(ctx context.Context, file, addr string, client bool, opts *rpctrace.ReplayOptions, out io.Writer) (bool, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	records, err := rpctrace.ReadAll(f)
+	if err != nil {
+		return false, fmt.Errorf("read %s: %w", file, err)
+	}
+
+	var report *rpctrace.Report
+	if client {
+		report, err = rpctrace.ReplayClient(ctx, records, opts)
+	} else {
+		var conn *grpc.ClientConn
+		conn, err = grpc.DialContext(ctx, addr, grpc.WithInsecure())
+		if err != nil {
+			return false, err
+		}
+		defer conn.Close()
+		report, err = rpctrace.Replay(ctx, conn, records, opts)
+	}
+	if report != nil {
+		fmt.Fprint(out, report)
+	}
+	if err != nil {
+		return false, err
+	}
+	return len(report.Divergences) == 0, nil
+}
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:8760", "fuse2grpc server to replay to")
+	client := flag.Bool("client", false, "replay through grpc2fuse against the recorded responses instead of a server")
+	pace := flag.Bool("pace", false, "space the calls out as they were recorded")
+	loggerLevel := flag.String("logger-level", "info", "log level")
+	flag.Parse()
+	if flag.NArg() < 1 {
+		log.Fatalf("Usage: %s <trace>", path.Base(os.Args[0]))
+	}
+	log.SetLevel(utils.GetLogLevel(*loggerLevel))
+
+	ok, err := replay(context.Background(), flag.Arg(0), *addr, *client, &rpctrace.ReplayOptions{Pace: *pace}, os.Stdout)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !ok {
+		os.Exit(1)
+	}
+}
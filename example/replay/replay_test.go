@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/chiyutianyi/grpcfuse/fuse2grpc"
+	"github.com/chiyutianyi/grpcfuse/grpcfusetest"
+	"github.com/chiyutianyi/grpcfuse/memfs"
+	"github.com/chiyutianyi/grpcfuse/pb"
+	"github.com/chiyutianyi/grpcfuse/rpctrace"
+)
+
+// writeTrace records a few calls on memfs to a trace file.
+func writeTrace(t *testing.T) string {
+	file := filepath.Join(t.TempDir(), "trace")
+	f, err := os.Create(file)
+	require.NoError(t, err)
+	defer f.Close()
+	rec, err := rpctrace.NewRecorder(f)
+	require.NoError(t, err)
+
+	p := grpcfusetest.New(t, memfs.New(nil, nil), &grpcfusetest.Options{
+		ServerOptions: []grpc.ServerOption{
+			grpc.ChainUnaryInterceptor(rec.UnaryServerInterceptor()),
+			grpc.ChainStreamInterceptor(rec.StreamServerInterceptor()),
+		},
+	})
+	var out fuse.EntryOut
+	require.Equal(t, fuse.OK, p.Client.Mkdir(nil, &fuse.MkdirIn{InHeader: fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}, Mode: 0755}, "dir", &out))
+	require.Equal(t, fuse.ENOENT, p.Client.Lookup(nil, &fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}, "missing", &out))
+	require.NoError(t, rec.Flush())
+	return file
+}
+
+// serve serves backend on a TCP port.
+func serve(t *testing.T, backend fuse.RawFileSystem) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := grpc.NewServer()
+	pb.RegisterRawFileSystemServer(s, fuse2grpc.NewServer(backend))
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+	return l.Addr().String()
+}
+
+func TestReplay(t *testing.T) {
+	file := writeTrace(t)
+	changed := memfs.New(nil, nil)
+	var out fuse.EntryOut
+	require.Equal(t, fuse.OK, changed.Mkdir(nil, &fuse.MkdirIn{InHeader: fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}, Mode: 0755}, "dir", &out))
+
+	tests := []struct {
+		name   string
+		file   string
+		addr   string
+		client bool
+		ok     bool
+		err    bool
+	}{
+		{name: "server", file: file, addr: serve(t, memfs.New(nil, nil)), ok: true},
+		{name: "changed server", file: file, addr: serve(t, changed)},
+		{name: "client", file: file, client: true, ok: true},
+		{name: "no trace", file: file + ".missing", client: true, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var report bytes.Buffer
+			ok, err := replay(context.Background(), tt.file, tt.addr, tt.client, &rpctrace.ReplayOptions{}, &report)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.ok, ok, report.String())
+			assert.Contains(t, report.String(), "2 calls")
+		})
+	}
+}
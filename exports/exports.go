@@ -0,0 +1,156 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package exports serves several file systems on one gRPC server. Each call
+// names its export in the metadata key Key; calls that name none go to the
+// default export:
+//
+//	r := exports.New()
+//	r.Set(map[string]pb.RawFileSystemServer{"data": data, "scratch": scratch}, "data")
+//	r.Register(s)
+//
+//	conn, err := grpc.Dial(addr, append(exports.DialOptions("scratch"), grpc.WithInsecure())...)
+package exports
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+// Key is the metadata key holding the export of a call. An empty value
+// stands for the default export.
+const Key = "grpcfuse-export"
+
+// Name turns "/data/", "data" or "//data" into the export name "data".
+func Name(name string) string {
+	return strings.Trim(name, "/")
+}
+
+// Router sends each call to the server of its export.
+type Router struct {
+	mu      sync.RWMutex
+	servers map[string]pb.RawFileSystemServer
+	def     string
+}
+
+// New returns a Router without exports.
+func New() *Router {
+	return &Router{servers: map[string]pb.RawFileSystemServer{}}
+}
+
+// Set replaces the exports, by name, and the default export, which may be
+// "" for none. Calls in flight finish on the servers they started on.
+func (r *Router) Set(servers map[string]pb.RawFileSystemServer, def string) {
+	m := make(map[string]pb.RawFileSystemServer, len(servers))
+	for name, srv := range servers {
+		m[Name(name)] = srv
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.servers, r.def = m, Name(def)
+}
+
+// Names returns the names of the exports.
+func (r *Router) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.servers))
+	for name := range r.servers {
+		names = append(names, name)
+	}
+	return names
+}
+
+// server returns the server of the export of a call.
+func (r *Router) server(ctx context.Context) (pb.RawFileSystemServer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	name := r.def
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(Key); len(v) > 0 && Name(v[0]) != "" {
+			name = Name(v[0])
+		}
+	}
+	if name == "" {
+		return nil, status.Error(codes.NotFound, "exports: no export given and no default export")
+	}
+	srv, ok := r.servers[name]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "exports: unknown export %q", name)
+	}
+	return srv, nil
+}
+
+// Register registers the RawFileSystem service of r on s. The handlers
+// are those of pb, called with the server of the export of each call.
+func (r *Router) Register(s *grpc.Server) {
+	desc := pb.RawFileSystem_ServiceDesc
+	// r is not a RawFileSystemServer; the handlers never see it.
+	desc.HandlerType = (*interface{})(nil)
+	desc.Methods = make([]grpc.MethodDesc, len(pb.RawFileSystem_ServiceDesc.Methods))
+	for i, m := range pb.RawFileSystem_ServiceDesc.Methods {
+		handler := m.Handler
+		desc.Methods[i] = grpc.MethodDesc{
+			MethodName: m.MethodName,
+			Handler: func(_ interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				srv, err := r.server(ctx)
+				if err != nil {
+					return nil, err
+				}
+				return handler(srv, ctx, dec, interceptor)
+			},
+		}
+	}
+	desc.Streams = make([]grpc.StreamDesc, len(pb.RawFileSystem_ServiceDesc.Streams))
+	for i, sd := range pb.RawFileSystem_ServiceDesc.Streams {
+		handler := sd.Handler
+		sd.Handler = func(_ interface{}, stream grpc.ServerStream) error {
+			srv, err := r.server(stream.Context())
+			if err != nil {
+				return err
+			}
+			return handler(srv, stream)
+		}
+		desc.Streams[i] = sd
+	}
+	s.RegisterService(&desc, r)
+}
+
+// NewContext returns ctx with its calls going to export name.
+func NewContext(ctx context.Context, name string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, Key, Name(name))
+}
+
+// DialOptions returns the options of a connection whose calls go to
+// export name.
+func DialOptions(name string) []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return invoker(NewContext(ctx, name), method, req, reply, cc, opts...)
+		}),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return streamer(NewContext(ctx, name), desc, cc, method, opts...)
+		}),
+	}
+}
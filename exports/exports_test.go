@@ -0,0 +1,96 @@
+package exports
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/chiyutianyi/grpcfuse/fsclient"
+	"github.com/chiyutianyi/grpcfuse/fuse2grpc"
+	"github.com/chiyutianyi/grpcfuse/memfs"
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+func serve(t *testing.T, r *Router) func(export string) *fsclient.Client {
+	l := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	r.Register(s)
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+
+	return func(export string) *fsclient.Client {
+		opts := []grpc.DialOption{
+			grpc.WithInsecure(),
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return l.DialContext(ctx) }),
+		}
+		if export != "" {
+			opts = append(opts, DialOptions(export)...)
+		}
+		conn, err := grpc.Dial("bufconn", opts...)
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		return fsclient.New(pb.NewRawFileSystemClient(conn), nil)
+	}
+}
+
+func TestRouter(t *testing.T) {
+	r := New()
+	r.Set(map[string]pb.RawFileSystemServer{
+		"a":    fuse2grpc.NewServer(memfs.New(nil, nil)),
+		"/b/c": fuse2grpc.NewServer(memfs.New(nil, nil)),
+	}, "a")
+	assert.ElementsMatch(t, []string{"a", "b/c"}, r.Names())
+	dial := serve(t, r)
+
+	a, b, def := dial("a"), dial("/b/c/"), dial("")
+	require.NoError(t, a.Mkdir("in-a", 0755))
+	require.NoError(t, b.Mkdir("in-b", 0755))
+
+	// Small files go through unary calls, directories through streams.
+	for _, tt := range []struct {
+		name string
+		c    *fsclient.Client
+		want []string
+	}{
+		{name: "a", c: a, want: []string{"in-a"}},
+		{name: "b", c: b, want: []string{"in-b"}},
+		{name: "default", c: def, want: []string{"in-a"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := fs.ReadDir(tt.c, ".")
+			require.NoError(t, err)
+			var names []string
+			for _, e := range entries {
+				names = append(names, e.Name())
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+
+	_, err := dial("nope").Stat(".")
+	assert.Equal(t, codes.NotFound, status.Code(errors.Unwrap(err)), err)
+
+	// Replacing the exports takes effect on open connections.
+	r.Set(map[string]pb.RawFileSystemServer{"b/c": fuse2grpc.NewServer(memfs.New(nil, nil))}, "")
+	_, err = a.Stat(".")
+	assert.Equal(t, codes.NotFound, status.Code(errors.Unwrap(err)), err)
+	_, err = def.Stat(".")
+	assert.Equal(t, codes.NotFound, status.Code(errors.Unwrap(err)), err)
+	_, err = b.Stat("in-b")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestName(t *testing.T) {
+	for in, want := range map[string]string{"": "", "/": "", "data": "data", "/data/": "data", "//a/b": "a/b"} {
+		assert.Equal(t, want, Name(in), in)
+	}
+}
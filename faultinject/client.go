@@ -0,0 +1,119 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package faultinject
+
+import (
+	"context"
+	"io"
+	"syscall"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryClientInterceptor applies faults to unary calls before they leave
+// the client.
+func (i *Injector) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		f, ok := i.fault(method)
+		if !ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		if err := f.before(ctx); err != nil {
+			return err
+		}
+		if f.Errno != 0 {
+			return setErrno(reply, f.Errno)
+		}
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if err == nil && f.Drop {
+			return errDropped
+		}
+		return err
+	}
+}
+
+// StreamClientInterceptor applies faults to streams as the client sees
+// them.
+func (i *Injector) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		f, ok := i.fault(method)
+		if !ok {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+		if err := f.before(ctx); err != nil {
+			return nil, err
+		}
+		if f.Errno != 0 {
+			return &errnoClientStream{ctx: ctx, errno: f.Errno}, nil
+		}
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return &faultClientStream{ClientStream: cs, fault: f}, nil
+	}
+}
+
+// faultClientStream cuts or drops the messages from the server.
+type faultClientStream struct {
+	grpc.ClientStream
+	fault    Fault
+	received int
+}
+
+func (s *faultClientStream) RecvMsg(m interface{}) error {
+	if s.fault.Drop {
+		for {
+			if err := s.ClientStream.RecvMsg(m); err == io.EOF {
+				return errDropped
+			} else if err != nil {
+				return err
+			}
+		}
+	}
+	if s.fault.CutAfter > 0 && s.received >= s.fault.CutAfter {
+		return errCut
+	}
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		s.received++
+	}
+	return err
+}
+
+// errnoClientStream answers a stream with one message carrying errno,
+// without asking the server.
+type errnoClientStream struct {
+	ctx   context.Context
+	errno syscall.Errno
+	done  bool
+}
+
+func (s *errnoClientStream) Header() (metadata.MD, error) { return nil, nil }
+func (s *errnoClientStream) Trailer() metadata.MD         { return nil }
+func (s *errnoClientStream) CloseSend() error             { return nil }
+func (s *errnoClientStream) Context() context.Context     { return s.ctx }
+func (s *errnoClientStream) SendMsg(m interface{}) error  { return nil }
+
+func (s *errnoClientStream) RecvMsg(m interface{}) error {
+	if s.done {
+		return io.EOF
+	}
+	s.done = true
+	return setErrno(m, s.errno)
+}
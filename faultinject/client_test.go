@@ -0,0 +1,18 @@
+package faultinject
+
+import (
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"github.com/chiyutianyi/grpcfuse/grpcfusetest"
+)
+
+func TestClientInterceptors(t *testing.T) {
+	runCases(t, func(inj *Injector) *grpcfusetest.Options {
+		return &grpcfusetest.Options{DialOptions: []grpc.DialOption{
+			grpc.WithChainUnaryInterceptor(inj.UnaryClientInterceptor()),
+			grpc.WithChainStreamInterceptor(inj.StreamClientInterceptor()),
+		}}
+	})
+}
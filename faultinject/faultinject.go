@@ -0,0 +1,227 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package faultinject provides gRPC interceptors that make RawFileSystem
+// calls slow or fail, to see how a mount behaves when the remote file
+// system is flaky. The same Injector works on the client and on the
+// server:
+//
+//	inj := faultinject.New()
+//	inj.Set("Read", faultinject.Fault{Delay: time.Second, CutAfter: 2})
+//	conn, err := grpc.Dial(addr,
+//		grpc.WithChainUnaryInterceptor(inj.UnaryClientInterceptor()),
+//		grpc.WithChainStreamInterceptor(inj.StreamClientInterceptor()))
+//
+// Faults can be changed at any time while calls are in flight.
+package faultinject
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+// AllMethods is the method name of a fault that applies to every method
+// without a fault of its own.
+const AllMethods = "*"
+
+var (
+	errDropped = status.Error(codes.Unavailable, "faultinject: response dropped")
+	errCut     = status.Error(codes.Unavailable, "faultinject: stream cut")
+)
+
+// Fault is what happens to the calls of a method. The delay comes first;
+// then the call either fails without being made (Code, Errno) or is made
+// and its outcome spoiled (CutAfter, Drop).
+type Fault struct {
+	// Delay holds the call back before it is made.
+	Delay time.Duration
+	// Code fails the call with this gRPC code.
+	Code codes.Code
+	// Errno answers the call with a reply carrying this errno. Replies
+	// without a status (Forget, Release, ReleaseDir) are empty.
+	Errno syscall.Errno
+	// CutAfter fails a server stream (Read, ReadDir, ReadDirPlus) with
+	// Unavailable after this many messages. Zero leaves streams alone; use
+	// Code to fail a stream before its first message.
+	CutAfter int
+	// Drop makes the call and throws the reply away, failing with
+	// Unavailable as if the connection broke on the way back.
+	Drop bool
+	// Probability in (0, 1) applies the fault to that share of the calls;
+	// any other value applies it to all of them.
+	Probability float64
+}
+
+func (f Fault) String() string {
+	var parts []string
+	if f.Delay > 0 {
+		parts = append(parts, "delay "+f.Delay.String())
+	}
+	if f.Code != codes.OK {
+		parts = append(parts, "code "+f.Code.String())
+	}
+	if f.Errno != 0 {
+		parts = append(parts, "errno "+f.Errno.Error())
+	}
+	if f.CutAfter > 0 {
+		parts = append(parts, fmt.Sprintf("cut after %d", f.CutAfter))
+	}
+	if f.Drop {
+		parts = append(parts, "drop")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Injector holds the faults by method. The zero value is not usable; use
+// New.
+type Injector struct {
+	mu       sync.RWMutex
+	faults   map[string]Fault
+	disabled bool
+}
+
+// New returns an Injector without faults.
+func New() *Injector {
+	return &Injector{faults: map[string]Fault{}}
+}
+
+// methodName turns "/pb.RawFileSystem/Read" into "Read".
+func methodName(method string) string {
+	return method[strings.LastIndex(method, "/")+1:]
+}
+
+// Set sets the fault of method, given as "Read", "/pb.RawFileSystem/Read"
+// or AllMethods.
+func (i *Injector) Set(method string, f Fault) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.faults[methodName(method)] = f
+}
+
+// Clear removes the fault of method.
+func (i *Injector) Clear(method string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.faults, methodName(method))
+}
+
+// Reset removes all faults.
+func (i *Injector) Reset() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.faults = map[string]Fault{}
+}
+
+// SetEnabled turns all faults on or off without forgetting them. An
+// Injector starts enabled.
+func (i *Injector) SetEnabled(enabled bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.disabled = !enabled
+}
+
+// fault returns the fault to apply to this call of method, if any.
+func (i *Injector) fault(method string) (Fault, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	if i.disabled {
+		return Fault{}, false
+	}
+	f, ok := i.faults[methodName(method)]
+	if !ok {
+		f, ok = i.faults[AllMethods]
+	}
+	if !ok {
+		return Fault{}, false
+	}
+	if f.Probability > 0 && f.Probability < 1 && rand.Float64() >= f.Probability {
+		return Fault{}, false
+	}
+	log.Debugf("faultinject: %s: %v", method, f)
+	return f, true
+}
+
+// before applies the delay and Code.
+func (f Fault) before(ctx context.Context) error {
+	if f.Delay > 0 {
+		t := time.NewTimer(f.Delay)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+	if f.Code != codes.OK {
+		return status.Errorf(f.Code, "faultinject: injected %v", f.Code)
+	}
+	return nil
+}
+
+// setErrno puts errno in the status of reply, if it has one.
+func setErrno(reply interface{}, errno syscall.Errno) error {
+	m, ok := reply.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "faultinject: reply %T is not a proto message", reply)
+	}
+	r := m.ProtoReflect()
+	if fd := r.Descriptor().Fields().ByName("status"); fd != nil {
+		st := &pb.Status{Code: int32(errno)}
+		r.Set(fd, protoreflect.ValueOfMessage(st.ProtoReflect()))
+	}
+	return nil
+}
+
+// errnoReply makes a reply to method carrying errno.
+func errnoReply(method string, errno syscall.Errno) (proto.Message, error) {
+	// method is "/pb.RawFileSystem/Read".
+	parts := strings.Split(strings.TrimPrefix(method, "/"), "/")
+	if len(parts) != 2 {
+		return nil, status.Errorf(codes.Internal, "faultinject: bad method %q", method)
+	}
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(parts[0]))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "faultinject: %s: %v", method, err)
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "faultinject: %s is not a service", parts[0])
+	}
+	md := sd.Methods().ByName(protoreflect.Name(parts[1]))
+	if md == nil {
+		return nil, status.Errorf(codes.Internal, "faultinject: unknown method %q", method)
+	}
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(md.Output().FullName())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "faultinject: %s: %v", method, err)
+	}
+	reply := mt.New().Interface()
+	return reply, setErrno(reply, errno)
+}
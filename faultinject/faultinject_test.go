@@ -0,0 +1,264 @@
+package faultinject
+
+import (
+	"bytes"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/chiyutianyi/grpcfuse/grpcfusetest"
+	"github.com/chiyutianyi/grpcfuse/memfs"
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+// fixture is a file and a directory, both open, seen through grpc2fuse.
+type fixture struct {
+	client       fuse.RawFileSystem
+	backend      fuse.RawFileSystem
+	file, fileFh uint64
+	dir, dirFh   uint64
+	fileContent  []byte
+}
+
+func header(nodeID uint64) fuse.InHeader {
+	return fuse.InHeader{NodeId: nodeID}
+}
+
+func newFixture(t *testing.T, opts *grpcfusetest.Options) *fixture {
+	opts.MsgSizeThreshold = 16
+	p := grpcfusetest.New(t, memfs.New(nil, nil), opts)
+	f := &fixture{client: p.Client, backend: p.Backend, fileContent: bytes.Repeat([]byte("0123456789"), 10)}
+
+	var create fuse.CreateOut
+	require.Equal(t, fuse.OK, p.Client.Create(nil, &fuse.CreateIn{InHeader: header(fuse.FUSE_ROOT_ID), Flags: syscall.O_RDWR, Mode: 0644}, "file", &create))
+	f.file, f.fileFh = create.NodeId, create.Fh
+	_, st := p.Client.Write(nil, &fuse.WriteIn{InHeader: header(f.file), Fh: f.fileFh}, f.fileContent)
+	require.Equal(t, fuse.OK, st)
+
+	var mkdir fuse.EntryOut
+	require.Equal(t, fuse.OK, p.Client.Mkdir(nil, &fuse.MkdirIn{InHeader: header(fuse.FUSE_ROOT_ID), Mode: 0755}, "dir", &mkdir))
+	f.dir = mkdir.NodeId
+	for _, name := range []string{"a", "b", "c", "d"} {
+		require.Equal(t, fuse.OK, p.Client.Mkdir(nil, &fuse.MkdirIn{InHeader: header(f.dir), Mode: 0755}, name, &mkdir))
+	}
+	var open fuse.OpenOut
+	require.Equal(t, fuse.OK, p.Client.OpenDir(nil, &fuse.OpenIn{InHeader: header(f.dir)}, &open))
+	f.dirFh = open.Fh
+	return f
+}
+
+func (f *fixture) read() fuse.Status {
+	res, st := f.client.Read(nil, &fuse.ReadIn{InHeader: header(f.file), Fh: f.fileFh, Size: 1000}, make([]byte, 1000))
+	if st == fuse.OK {
+		data, _ := res.Bytes(make([]byte, 1000))
+		if !bytes.Equal(data, f.fileContent) {
+			return fuse.EINVAL
+		}
+	}
+	return st
+}
+
+func (f *fixture) readDir() fuse.Status {
+	return f.client.ReadDir(nil, &fuse.ReadIn{InHeader: header(f.dir), Fh: f.dirFh, Size: 4096}, fuse.NewDirEntryList(make([]byte, 4096), 0))
+}
+
+func (f *fixture) getAttr(cancel <-chan struct{}) fuse.Status {
+	var out fuse.AttrOut
+	return f.client.GetAttr(cancel, &fuse.GetAttrIn{InHeader: header(f.file)}, &out)
+}
+
+func (f *fixture) mkdir(name string) fuse.Status {
+	var out fuse.EntryOut
+	return f.client.Mkdir(nil, &fuse.MkdirIn{InHeader: header(fuse.FUSE_ROOT_ID), Mode: 0755}, name, &out)
+}
+
+// cases are the grpc2fuse error paths, run with the interceptors on either
+// side of the connection.
+var cases = []struct {
+	name   string
+	method string
+	fault  Fault
+	run    func(t *testing.T, f *fixture) fuse.Status
+	want   fuse.Status
+}{
+	{
+		name:   "grpc error",
+		method: "GetAttr",
+		fault:  Fault{Code: codes.Unavailable},
+		run:    func(t *testing.T, f *fixture) fuse.Status { return f.getAttr(nil) },
+		want:   fuse.EIO,
+	},
+	{
+		name:   "unimplemented",
+		method: "/pb.RawFileSystem/Lookup",
+		fault:  Fault{Code: codes.Unimplemented},
+		run: func(t *testing.T, f *fixture) fuse.Status {
+			var out fuse.EntryOut
+			return f.client.Lookup(nil, &fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}, "file", &out)
+		},
+		want: fuse.ENOSYS,
+	},
+	{
+		name:   "errno",
+		method: "Open",
+		fault:  Fault{Errno: syscall.EACCES},
+		run: func(t *testing.T, f *fixture) fuse.Status {
+			var out fuse.OpenOut
+			return f.client.Open(nil, &fuse.OpenIn{InHeader: header(f.file)}, &out)
+		},
+		want: fuse.EACCES,
+	},
+	{
+		name:   "errno on stream",
+		method: "Read",
+		fault:  Fault{Errno: syscall.ESTALE},
+		run:    func(t *testing.T, f *fixture) fuse.Status { return f.read() },
+		want:   fuse.Status(syscall.ESTALE),
+	},
+	{
+		name:   "cut read",
+		method: "Read",
+		fault:  Fault{CutAfter: 2},
+		run:    func(t *testing.T, f *fixture) fuse.Status { return f.read() },
+		want:   fuse.EIO,
+	},
+	{
+		name:   "cut after the end",
+		method: "Read",
+		fault:  Fault{CutAfter: 100},
+		run:    func(t *testing.T, f *fixture) fuse.Status { return f.read() },
+		want:   fuse.OK,
+	},
+	{
+		name:   "cut readdir",
+		method: "ReadDir",
+		fault:  Fault{CutAfter: 1},
+		run:    func(t *testing.T, f *fixture) fuse.Status { return f.readDir() },
+		want:   fuse.EIO,
+	},
+	{
+		name:   "drop",
+		method: "Mkdir",
+		fault:  Fault{Drop: true},
+		run: func(t *testing.T, f *fixture) fuse.Status {
+			st := f.mkdir("new")
+			// The call was made; only the reply was lost.
+			var out fuse.EntryOut
+			assert.Equal(t, fuse.OK, f.backend.Lookup(nil, &fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}, "new", &out))
+			return st
+		},
+		want: fuse.EIO,
+	},
+	{
+		name:   "drop stream",
+		method: "Read",
+		fault:  Fault{Drop: true},
+		run:    func(t *testing.T, f *fixture) fuse.Status { return f.read() },
+		want:   fuse.EIO,
+	},
+	{
+		name:   "all methods",
+		method: AllMethods,
+		fault:  Fault{Code: codes.Internal},
+		run:    func(t *testing.T, f *fixture) fuse.Status { return f.readDir() },
+		want:   fuse.EIO,
+	},
+	{
+		name:   "delay",
+		method: "GetAttr",
+		fault:  Fault{Delay: 20 * time.Millisecond},
+		run: func(t *testing.T, f *fixture) fuse.Status {
+			start := time.Now()
+			st := f.getAttr(nil)
+			assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+			return st
+		},
+		want: fuse.OK,
+	},
+	{
+		name:   "interrupted delay",
+		method: "GetAttr",
+		fault:  Fault{Delay: time.Hour},
+		run: func(t *testing.T, f *fixture) fuse.Status {
+			cancel := make(chan struct{})
+			time.AfterFunc(10*time.Millisecond, func() { close(cancel) })
+			return f.getAttr(cancel)
+		},
+		want: fuse.EIO,
+	},
+	{
+		name:   "other method",
+		method: "SetAttr",
+		fault:  Fault{Code: codes.Internal},
+		run:    func(t *testing.T, f *fixture) fuse.Status { return f.read() },
+		want:   fuse.OK,
+	},
+}
+
+func runCases(t *testing.T, opts func(inj *Injector) *grpcfusetest.Options) {
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			inj := New()
+			f := newFixture(t, opts(inj))
+			inj.Set(tt.method, tt.fault)
+			assert.Equal(t, tt.want, tt.run(t, f))
+
+			inj.Clear(tt.method)
+			assert.Equal(t, fuse.OK, f.read())
+			assert.Equal(t, fuse.OK, f.readDir())
+		})
+	}
+}
+
+func TestSwitching(t *testing.T) {
+	inj := New()
+	f := newFixture(t, &grpcfusetest.Options{
+		DialOptions: []grpc.DialOption{grpc.WithChainUnaryInterceptor(inj.UnaryClientInterceptor())},
+	})
+
+	inj.Set("GetAttr", Fault{Code: codes.Unavailable})
+	assert.Equal(t, fuse.EIO, f.getAttr(nil))
+	inj.SetEnabled(false)
+	assert.Equal(t, fuse.OK, f.getAttr(nil))
+	inj.SetEnabled(true)
+	assert.Equal(t, fuse.EIO, f.getAttr(nil))
+	inj.Reset()
+	assert.Equal(t, fuse.OK, f.getAttr(nil))
+
+	inj.Set("GetAttr", Fault{Code: codes.Unavailable, Probability: 0.5})
+	failed := 0
+	for i := 0; i < 200; i++ {
+		if f.getAttr(nil) != fuse.OK {
+			failed++
+		}
+	}
+	assert.Greater(t, failed, 0)
+	assert.Less(t, failed, 200)
+}
+
+func TestErrnoReply(t *testing.T) {
+	res, err := errnoReply("/pb.RawFileSystem/Open", syscall.ENOENT)
+	require.NoError(t, err)
+	assert.Equal(t, int32(syscall.ENOENT), res.(*pb.OpenResponse).Status.GetCode())
+
+	// Release has no status to carry the errno.
+	_, err = errnoReply("/pb.RawFileSystem/Release", syscall.ENOENT)
+	assert.NoError(t, err)
+
+	for _, method := range []string{"Open", "/pb.RawFileSystem/Nope", "/pb.Nope/Open", "/pb.Status/Code"} {
+		_, err := errnoReply(method, syscall.ENOENT)
+		assert.Error(t, err, method)
+	}
+}
+
+func TestFaultString(t *testing.T) {
+	assert.Equal(t, "", Fault{}.String())
+	assert.Equal(t, "delay 1s, code Unavailable, errno no such file or directory, cut after 3, drop",
+		Fault{Delay: time.Second, Code: codes.Unavailable, Errno: syscall.ENOENT, CutAfter: 3, Drop: true}.String())
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package faultinject
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// UnaryServerInterceptor applies faults to unary calls before they reach
+// the file system.
+func (i *Injector) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		f, ok := i.fault(info.FullMethod)
+		if !ok {
+			return handler(ctx, req)
+		}
+		if err := f.before(ctx); err != nil {
+			return nil, err
+		}
+		if f.Errno != 0 {
+			return errnoReply(info.FullMethod, f.Errno)
+		}
+		res, err := handler(ctx, req)
+		if err == nil && f.Drop {
+			return nil, errDropped
+		}
+		return res, err
+	}
+}
+
+// StreamServerInterceptor applies faults to streams as the server sends
+// them.
+func (i *Injector) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		f, ok := i.fault(info.FullMethod)
+		if !ok {
+			return handler(srv, ss)
+		}
+		if err := f.before(ss.Context()); err != nil {
+			return err
+		}
+		if f.Errno != 0 {
+			res, err := errnoReply(info.FullMethod, f.Errno)
+			if err != nil {
+				return err
+			}
+			return ss.SendMsg(res)
+		}
+		err := handler(srv, &faultServerStream{ServerStream: ss, fault: f})
+		if err == nil && f.Drop {
+			return errDropped
+		}
+		return err
+	}
+}
+
+// faultServerStream cuts or drops the messages to the client.
+type faultServerStream struct {
+	grpc.ServerStream
+	fault Fault
+	sent  int
+}
+
+func (s *faultServerStream) SendMsg(m interface{}) error {
+	if s.fault.Drop {
+		return nil
+	}
+	if s.fault.CutAfter > 0 && s.sent >= s.fault.CutAfter {
+		return errCut
+	}
+	s.sent++
+	return s.ServerStream.SendMsg(m)
+}
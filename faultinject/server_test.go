@@ -0,0 +1,18 @@
+package faultinject
+
+import (
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"github.com/chiyutianyi/grpcfuse/grpcfusetest"
+)
+
+func TestServerInterceptors(t *testing.T) {
+	runCases(t, func(inj *Injector) *grpcfusetest.Options {
+		return &grpcfusetest.Options{ServerOptions: []grpc.ServerOption{
+			grpc.ChainUnaryInterceptor(inj.UnaryServerInterceptor()),
+			grpc.ChainStreamInterceptor(inj.StreamServerInterceptor()),
+		}}
+	})
+}
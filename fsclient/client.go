@@ -0,0 +1,422 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package fsclient reads and writes the files of a fuse2grpc server
+// without mounting it. A Client is an io/fs.FS, so it works with
+// fs.WalkDir, fs.ReadFile, fs.Glob and friends:
+//
+//	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+//	fsys := fsclient.New(pb.NewRawFileSystemClient(conn), nil)
+//	data, err := fs.ReadFile(fsys, "dir/file")
+//
+// Names are slash-separated paths relative to the root of the export, as
+// checked by fs.ValidPath. Symbolic links are not followed.
+package fsclient
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+const (
+	// defaultIOSize is the most a single Read or Write call moves. It stays
+	// under the 4mb gRPC message limit.
+	defaultIOSize = 1 << 20
+	// dirBufSize is the READDIR buffer size, like the kernel's.
+	dirBufSize = 64 << 10
+)
+
+// Options tunes a Client. The zero value is usable.
+type Options struct {
+	// Uid, Gid and Pid are the caller the server checks permissions
+	// against. Without Options they are those of this process.
+	Uid, Gid, Pid uint32
+	// IOSize is the most a single Read or Write call moves, 1MiB by
+	// default.
+	IOSize int
+	// Timeout bounds every call if non-zero.
+	Timeout time.Duration
+	// CallOptions are passed to every call.
+	CallOptions []grpc.CallOption
+}
+
+// Client is a remote file system. It holds no state of its own besides
+// open Files and is safe for concurrent use.
+type Client struct {
+	client pb.RawFileSystemClient
+	opts   Options
+	caller *pb.Caller
+}
+
+var (
+	_ fs.FS        = (*Client)(nil)
+	_ fs.ReadDirFS = (*Client)(nil)
+	_ fs.StatFS    = (*Client)(nil)
+)
+
+// New returns a Client of the server client talks to.
+func New(client pb.RawFileSystemClient, opts *Options) *Client {
+	c := &Client{client: client}
+	if opts != nil {
+		c.opts = *opts
+	} else {
+		c.opts = Options{Uid: uint32(os.Getuid()), Gid: uint32(os.Getgid()), Pid: uint32(os.Getpid())}
+	}
+	if c.opts.IOSize <= 0 {
+		c.opts.IOSize = defaultIOSize
+	}
+	c.caller = &pb.Caller{Owner: &pb.Owner{Uid: c.opts.Uid, Gid: c.opts.Gid}, Pid: c.opts.Pid}
+	return c
+}
+
+func (c *Client) header(nodeID uint64) *pb.InHeader {
+	return &pb.InHeader{NodeId: nodeID, Caller: c.caller}
+}
+
+func (c *Client) context() (context.Context, context.CancelFunc) {
+	if c.opts.Timeout > 0 {
+		return context.WithTimeout(context.Background(), c.opts.Timeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// response is a reply carrying an errno.
+type response interface {
+	GetStatus() *pb.Status
+}
+
+// check turns a failed call into an error: the errno of its status as a
+// syscall.Errno, so that errors.Is(err, fs.ErrNotExist) and the like
+// work, or the gRPC error.
+func check(res response, err error) error {
+	if err != nil {
+		return err
+	}
+	if code := res.GetStatus().GetCode(); code != 0 {
+		return syscall.Errno(code)
+	}
+	return nil
+}
+
+func pathError(op, name string, err error) error {
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
+
+// forget drops a lookup of nodeID. The root is never looked up.
+func (c *Client) forget(nodeID uint64) {
+	if nodeID == fuse.FUSE_ROOT_ID {
+		return
+	}
+	ctx, cancel := c.context()
+	defer cancel()
+	if _, err := c.client.Forget(ctx, &pb.ForgetRequest{Nodeid: nodeID, Nlookup: 1}, c.opts.CallOptions...); err != nil {
+		log.Debugf("fsclient: forget %d: %v", nodeID, err)
+	}
+}
+
+func (c *Client) lookup(parent uint64, name string) (*pb.EntryOut, error) {
+	ctx, cancel := c.context()
+	defer cancel()
+	res, err := c.client.Lookup(ctx, &pb.LookupRequest{Header: c.header(parent), Name: name}, c.opts.CallOptions...)
+	if err := check(res, err); err != nil {
+		return nil, err
+	}
+	return res.EntryOut, nil
+}
+
+// walk looks up name and returns its entry, holding a lookup the caller
+// must forget. The root has no EntryOut of its own, so its attributes are
+// fetched.
+func (c *Client) walk(op, name string) (*pb.EntryOut, error) {
+	if !fs.ValidPath(name) {
+		return nil, pathError(op, name, fs.ErrInvalid)
+	}
+	if name == "." {
+		attr, err := c.getAttr(fuse.FUSE_ROOT_ID)
+		if err != nil {
+			return nil, pathError(op, name, err)
+		}
+		return &pb.EntryOut{NodeId: fuse.FUSE_ROOT_ID, Attr: attr}, nil
+	}
+	parent, base, err := c.walkParent(op, name)
+	if err != nil {
+		return nil, err
+	}
+	defer c.forget(parent)
+	entry, err := c.lookup(parent, base)
+	if err != nil {
+		return nil, pathError(op, name, err)
+	}
+	return entry, nil
+}
+
+// walkParent looks up the directory name is in and returns it with the
+// last element of name. The caller must forget the directory.
+func (c *Client) walkParent(op, name string) (uint64, string, error) {
+	if !fs.ValidPath(name) || name == "." {
+		return 0, "", pathError(op, name, fs.ErrInvalid)
+	}
+	parts := strings.Split(name, "/")
+	node := uint64(fuse.FUSE_ROOT_ID)
+	for _, part := range parts[:len(parts)-1] {
+		entry, err := c.lookup(node, part)
+		c.forget(node)
+		if err != nil {
+			return 0, "", pathError(op, name, err)
+		}
+		if entry.Attr.GetMode()&syscall.S_IFMT != syscall.S_IFDIR {
+			c.forget(entry.NodeId)
+			return 0, "", pathError(op, name, syscall.ENOTDIR)
+		}
+		node = entry.NodeId
+	}
+	return node, parts[len(parts)-1], nil
+}
+
+func (c *Client) getAttr(nodeID uint64) (*pb.Attr, error) {
+	ctx, cancel := c.context()
+	defer cancel()
+	res, err := c.client.GetAttr(ctx, &pb.GetAttrRequest{Header: c.header(nodeID)}, c.opts.CallOptions...)
+	if err := check(res, err); err != nil {
+		return nil, err
+	}
+	return res.AttrOut.GetAttr(), nil
+}
+
+// Open opens the named file or directory for reading.
+func (c *Client) Open(name string) (fs.File, error) {
+	f, err := c.OpenFile(name, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Stat returns the attributes of the named file.
+func (c *Client) Stat(name string) (fs.FileInfo, error) {
+	entry, err := c.walk("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	defer c.forget(entry.NodeId)
+	return newFileInfo(name, entry.Attr), nil
+}
+
+// ReadDir returns the entries of the named directory sorted by name,
+// without "." and "..".
+func (c *Client) ReadDir(name string) ([]fs.DirEntry, error) {
+	f, err := c.OpenFile(name, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entries, err := f.ReadDir(-1)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, err
+}
+
+// Create creates or truncates the named file, like os.Create.
+func (c *Client) Create(name string) (*File, error) {
+	return c.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+}
+
+// OpenFile opens the named file with the os.O_* flags, creating it with
+// perm if os.O_CREATE is set and it does not exist.
+func (c *Client) OpenFile(name string, flag int, perm fs.FileMode) (*File, error) {
+	if name == "." {
+		entry, err := c.walk("open", name)
+		if err != nil {
+			return nil, err
+		}
+		return c.open(name, entry, flag)
+	}
+
+	parent, base, err := c.walkParent("open", name)
+	if err != nil {
+		return nil, err
+	}
+	defer c.forget(parent)
+	entry, err := c.lookup(parent, base)
+	switch {
+	case err == syscall.ENOENT && flag&os.O_CREATE != 0:
+		return c.create(name, parent, base, flag, perm)
+	case err != nil:
+		return nil, pathError("open", name, err)
+	case flag&(os.O_CREATE|os.O_EXCL) == os.O_CREATE|os.O_EXCL:
+		c.forget(entry.NodeId)
+		return nil, pathError("open", name, syscall.EEXIST)
+	}
+	return c.open(name, entry, flag)
+}
+
+func (c *Client) open(name string, entry *pb.EntryOut, flag int) (*File, error) {
+	f := &File{c: c, name: name, node: entry.NodeId, mode: entry.Attr.GetMode(), owner: newLockOwner()}
+	ctx, cancel := c.context()
+	defer cancel()
+	in := &pb.OpenIn{Header: c.header(entry.NodeId), Flags: uint32(flag &^ (os.O_CREATE | os.O_EXCL))}
+	var out *pb.OpenOut
+	if f.isDir() {
+		res, err := c.client.OpenDir(ctx, &pb.OpenDirRequest{OpenIn: in}, c.opts.CallOptions...)
+		err = check(res, err)
+		out = res.GetOpenOut()
+		if err != nil {
+			c.forget(entry.NodeId)
+			return nil, pathError("open", name, err)
+		}
+	} else {
+		res, err := c.client.Open(ctx, &pb.OpenRequest{OpenIn: in}, c.opts.CallOptions...)
+		err = check(res, err)
+		out = res.GetOpenOut()
+		if err != nil {
+			c.forget(entry.NodeId)
+			return nil, pathError("open", name, err)
+		}
+	}
+	f.fh = out.GetFh()
+	if flag&os.O_APPEND != 0 {
+		f.appending = true
+	}
+	return f, nil
+}
+
+func (c *Client) create(name string, parent uint64, base string, flag int, perm fs.FileMode) (*File, error) {
+	ctx, cancel := c.context()
+	defer cancel()
+	res, err := c.client.Create(ctx, &pb.CreateRequest{
+		Header: c.header(parent),
+		Name:   base,
+		Flags:  uint32(flag),
+		Mode:   syscall.S_IFREG | fromFileMode(perm),
+	}, c.opts.CallOptions...)
+	if err := check(res, err); err != nil {
+		return nil, pathError("open", name, err)
+	}
+	return &File{
+		c:         c,
+		name:      name,
+		node:      res.EntryOut.NodeId,
+		fh:        res.OpenOut.GetFh(),
+		mode:      res.EntryOut.Attr.GetMode(),
+		appending: flag&os.O_APPEND != 0,
+		owner:     newLockOwner(),
+	}, nil
+}
+
+// Mkdir creates the named directory.
+func (c *Client) Mkdir(name string, perm fs.FileMode) error {
+	parent, base, err := c.walkParent("mkdir", name)
+	if err != nil {
+		return err
+	}
+	defer c.forget(parent)
+	ctx, cancel := c.context()
+	defer cancel()
+	res, err := c.client.Mkdir(ctx, &pb.MkdirRequest{Header: c.header(parent), Name: base, Mode: fromFileMode(perm)}, c.opts.CallOptions...)
+	if err := check(res, err); err != nil {
+		return pathError("mkdir", name, err)
+	}
+	c.forget(res.EntryOut.GetNodeId())
+	return nil
+}
+
+// Remove removes the named file or empty directory.
+func (c *Client) Remove(name string) error {
+	parent, base, err := c.walkParent("remove", name)
+	if err != nil {
+		return err
+	}
+	defer c.forget(parent)
+	ctx, cancel := c.context()
+	defer cancel()
+
+	// Like os.Remove, try both and report the error that makes sense.
+	res, err := c.client.Unlink(ctx, &pb.UnlinkRequest{Header: c.header(parent), Name: base}, c.opts.CallOptions...)
+	unlinkErr := check(res, err)
+	if unlinkErr == nil {
+		return nil
+	}
+	rres, err := c.client.Rmdir(ctx, &pb.RmdirRequest{Header: c.header(parent), Name: base}, c.opts.CallOptions...)
+	rmdirErr := check(rres, err)
+	if rmdirErr == nil {
+		return nil
+	}
+	if rmdirErr != syscall.ENOTDIR {
+		unlinkErr = rmdirErr
+	}
+	return pathError("remove", name, unlinkErr)
+}
+
+// Rename moves oldname to newname, replacing newname if it exists.
+func (c *Client) Rename(oldname, newname string) error {
+	oldParent, oldBase, err := c.walkParent("rename", oldname)
+	if err != nil {
+		return err
+	}
+	defer c.forget(oldParent)
+	newParent, newBase, err := c.walkParent("rename", newname)
+	if err != nil {
+		return err
+	}
+	defer c.forget(newParent)
+
+	ctx, cancel := c.context()
+	defer cancel()
+	res, err := c.client.Rename(ctx, &pb.RenameRequest{
+		Header:  c.header(oldParent),
+		OldName: oldBase,
+		NewName: newBase,
+		Newdir:  newParent,
+	}, c.opts.CallOptions...)
+	if err := check(res, err); err != nil {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: err}
+	}
+	return nil
+}
+
+// Chmod changes the mode of the named file.
+func (c *Client) Chmod(name string, mode fs.FileMode) error {
+	entry, err := c.walk("chmod", name)
+	if err != nil {
+		return err
+	}
+	defer c.forget(entry.NodeId)
+	if err := c.setMode(entry.NodeId, mode); err != nil {
+		return pathError("chmod", name, err)
+	}
+	return nil
+}
+
+func (c *Client) setMode(nodeID uint64, mode fs.FileMode) error {
+	ctx, cancel := c.context()
+	defer cancel()
+	res, err := c.client.SetAttr(ctx, &pb.SetAttrRequest{
+		Header: c.header(nodeID),
+		Valid:  fuse.FATTR_MODE,
+		Mode:   fromFileMode(mode),
+	}, c.opts.CallOptions...)
+	return check(res, err)
+}
@@ -0,0 +1,179 @@
+package fsclient
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/chiyutianyi/grpcfuse/grpcfusetest"
+	"github.com/chiyutianyi/grpcfuse/memfs"
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+// lookups counts the lookups the client holds by node.
+type lookups struct {
+	mu    sync.Mutex
+	nodes map[uint64]int
+}
+
+type entryResponse interface {
+	GetEntryOut() *pb.EntryOut
+}
+
+func (l *lookups) interceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if forget, ok := req.(*pb.ForgetRequest); ok {
+		l.nodes[forget.Nodeid] -= int(forget.Nlookup)
+	}
+	if res, ok := reply.(entryResponse); ok && res.GetEntryOut().GetNodeId() != 0 {
+		l.nodes[res.GetEntryOut().GetNodeId()]++
+	}
+	return nil
+}
+
+// held returns the nodes with lookups left.
+func (l *lookups) held() map[uint64]int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	held := map[uint64]int{}
+	for node, n := range l.nodes {
+		if n != 0 {
+			held[node] = n
+		}
+	}
+	return held
+}
+
+func newClient(t *testing.T, opts *Options) (*Client, *lookups) {
+	l := &lookups{nodes: map[uint64]int{}}
+	p := grpcfusetest.New(t, memfs.New(nil, nil), &grpcfusetest.Options{
+		MsgSizeThreshold: 100,
+		DialOptions:      []grpc.DialOption{grpc.WithChainUnaryInterceptor(l.interceptor)},
+	})
+	return New(pb.NewRawFileSystemClient(p.Conn), opts), l
+}
+
+func writeFile(t *testing.T, c *Client, name, content string) {
+	f, err := c.Create(name)
+	require.NoError(t, err)
+	_, err = f.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func TestFS(t *testing.T) {
+	c, l := newClient(t, nil)
+	require.NoError(t, c.Mkdir("dir", 0755))
+	require.NoError(t, c.Mkdir("dir/sub", 0700))
+	writeFile(t, c, "a", "hello")
+	writeFile(t, c, "dir/b", "")
+	writeFile(t, c, "dir/sub/c", string(make([]byte, 3000)))
+	for i := 0; i < 50; i++ {
+		writeFile(t, c, "dir/many"+string(rune('a'+i%26))+string(rune('a'+i/26)), "x")
+	}
+
+	require.NoError(t, fstest.TestFS(c, "a", "dir/b", "dir/sub/c", "dir/manyaa"))
+	assert.Empty(t, l.held())
+}
+
+func TestStatAndReadDir(t *testing.T) {
+	c, l := newClient(t, nil)
+	require.NoError(t, c.Mkdir("dir", 0750))
+	writeFile(t, c, "dir/file", "12345")
+
+	info, err := c.Stat("dir/file")
+	require.NoError(t, err)
+	assert.Equal(t, "file", info.Name())
+	assert.Equal(t, int64(5), info.Size())
+	assert.Equal(t, fs.FileMode(0666), info.Mode())
+	assert.False(t, info.IsDir())
+	assert.IsType(t, &pb.Attr{}, info.Sys())
+
+	info, err = c.Stat(".")
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+
+	entries, err := c.ReadDir(".")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "dir", entries[0].Name())
+	assert.True(t, entries[0].IsDir())
+	info, err = entries[0].Info()
+	require.NoError(t, err)
+	assert.Equal(t, fs.ModeDir|0750, info.Mode())
+
+	tests := []struct {
+		name string
+		want error
+	}{
+		{name: "missing", want: fs.ErrNotExist},
+		{name: "dir/file/below", want: syscall.ENOTDIR},
+		{name: "/dir", want: fs.ErrInvalid},
+		{name: "dir/../dir", want: fs.ErrInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.Stat(tt.name)
+			assert.True(t, errors.Is(err, tt.want), "%v", err)
+			var perr *fs.PathError
+			require.True(t, errors.As(err, &perr))
+			assert.Equal(t, tt.name, perr.Path)
+		})
+	}
+	assert.Empty(t, l.held())
+}
+
+func TestWriteAPIs(t *testing.T) {
+	c, l := newClient(t, nil)
+	writeFile(t, c, "file", "data")
+
+	_, err := c.OpenFile("file", os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	assert.True(t, errors.Is(err, fs.ErrExist), "%v", err)
+	_, err = c.OpenFile("missing", os.O_RDWR, 0)
+	assert.True(t, errors.Is(err, fs.ErrNotExist), "%v", err)
+
+	// O_TRUNC empties the file.
+	f, err := c.OpenFile("file", os.O_RDWR|os.O_TRUNC, 0)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	info, err := c.Stat("file")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), info.Size())
+
+	require.NoError(t, c.Chmod("file", 0600|fs.ModeSetgid))
+	info, err = c.Stat("file")
+	require.NoError(t, err)
+	assert.Equal(t, 0600|fs.ModeSetgid, info.Mode())
+
+	require.NoError(t, c.Mkdir("dir", 0755))
+	assert.True(t, errors.Is(c.Mkdir("dir", 0755), fs.ErrExist))
+	require.NoError(t, c.Rename("file", "dir/moved"))
+	_, err = c.Stat("file")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+	var lerr *os.LinkError
+	assert.True(t, errors.As(c.Rename("file", "other"), &lerr))
+
+	assert.True(t, errors.Is(c.Remove("dir"), syscall.ENOTEMPTY))
+	require.NoError(t, c.Remove("dir/moved"))
+	require.NoError(t, c.Remove("dir"))
+	assert.True(t, errors.Is(c.Remove("dir"), fs.ErrNotExist))
+
+	entries, err := c.ReadDir(".")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	assert.Empty(t, l.held())
+}
@@ -0,0 +1,372 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fsclient
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"sync"
+	"syscall"
+
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+// File is an open file or directory. Its methods are safe for concurrent
+// use; Read, Write and Seek share the file offset.
+type File struct {
+	c         *Client
+	name      string
+	node, fh  uint64
+	mode      uint32
+	appending bool
+	// owner is the lock owner of the File's locks.
+	owner uint64
+
+	mu     sync.Mutex
+	offset int64
+	closed bool
+	// dirOff and dirEntries are where ReadDir is at.
+	dirOff     uint64
+	dirEntries []fs.DirEntry
+	dirEOF     bool
+}
+
+var (
+	_ fs.ReadDirFile = (*File)(nil)
+	_ io.ReaderAt    = (*File)(nil)
+	_ io.WriterAt    = (*File)(nil)
+	_ io.Seeker      = (*File)(nil)
+)
+
+// Name returns the name the file was opened with.
+func (f *File) Name() string {
+	return f.name
+}
+
+func (f *File) isDir() bool {
+	return f.mode&syscall.S_IFMT == syscall.S_IFDIR
+}
+
+func (f *File) error(op string, err error) error {
+	return pathError(op, f.name, err)
+}
+
+// Stat returns the current attributes of the file.
+func (f *File) Stat() (fs.FileInfo, error) {
+	attr, err := f.c.getAttr(f.node)
+	if err != nil {
+		return nil, f.error("stat", err)
+	}
+	return newFileInfo(f.name, attr), nil
+}
+
+// readAt reads up to len(p) bytes at off in one call.
+func (f *File) readAt(p []byte, off int64) (int, error) {
+	ctx, cancel := f.c.context()
+	defer cancel()
+	stream, err := f.c.client.Read(ctx, &pb.ReadRequest{ReadIn: &pb.ReadIn{
+		Header: f.c.header(f.node),
+		Fh:     f.fh,
+		Offset: uint64(off),
+		Size:   uint32(len(p)),
+	}}, f.c.opts.CallOptions...)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return n, nil
+		}
+		if err := check(res, err); err != nil {
+			return n, err
+		}
+		if len(res.Buffer) > len(p)-n {
+			return n, syscall.EIO
+		}
+		n += copy(p[n:], res.Buffer)
+	}
+}
+
+// ReadAt reads len(p) bytes at off, failing with io.EOF if the file ends
+// before.
+func (f *File) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, f.error("read", fs.ErrInvalid)
+	}
+	if f.isDir() {
+		return 0, f.error("read", syscall.EISDIR)
+	}
+	n := 0
+	for n < len(p) {
+		chunk := p[n:]
+		if len(chunk) > f.c.opts.IOSize {
+			chunk = chunk[:f.c.opts.IOSize]
+		}
+		m, err := f.readAt(chunk, off+int64(n))
+		n += m
+		if err != nil {
+			return n, f.error("read", err)
+		}
+		if m == 0 {
+			return n, io.EOF
+		}
+	}
+	return n, nil
+}
+
+// Read reads up to len(p) bytes at the file offset.
+func (f *File) Read(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return 0, f.error("read", fs.ErrClosed)
+	}
+	if len(p) > f.c.opts.IOSize {
+		p = p[:f.c.opts.IOSize]
+	}
+	if f.isDir() {
+		return 0, f.error("read", syscall.EISDIR)
+	}
+	n, err := f.readAt(p, f.offset)
+	f.offset += int64(n)
+	if err != nil {
+		return n, f.error("read", err)
+	}
+	if n == 0 && len(p) > 0 {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+// WriteAt writes p at off.
+func (f *File) WriteAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, f.error("write", fs.ErrInvalid)
+	}
+	if f.appending {
+		return 0, f.error("write", errors.New("WriteAt in append mode"))
+	}
+	return f.writeAt(p, off)
+}
+
+func (f *File) writeAt(p []byte, off int64) (int, error) {
+	n := 0
+	for n < len(p) {
+		chunk := p[n:]
+		if len(chunk) > f.c.opts.IOSize {
+			chunk = chunk[:f.c.opts.IOSize]
+		}
+		ctx, cancel := f.c.context()
+		res, err := f.c.client.Write(ctx, &pb.WriteRequest{
+			Header: f.c.header(f.node),
+			Fh:     f.fh,
+			Offset: uint64(off) + uint64(n),
+			Data:   chunk,
+			Size:   uint32(len(chunk)),
+		}, f.c.opts.CallOptions...)
+		cancel()
+		if err := check(res, err); err != nil {
+			return n, f.error("write", err)
+		}
+		n += int(res.Written)
+		if int(res.Written) < len(chunk) {
+			return n, f.error("write", io.ErrShortWrite)
+		}
+	}
+	return n, nil
+}
+
+// Write writes p at the file offset, or at the end of the file if it was
+// opened with os.O_APPEND.
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return 0, f.error("write", fs.ErrClosed)
+	}
+	if f.appending {
+		// A server that opened the file O_APPEND writes at the end
+		// anyway; the others need to be told where it is.
+		info, err := f.Stat()
+		if err != nil {
+			return 0, err
+		}
+		f.offset = info.Size()
+	}
+	n, err := f.writeAt(p, f.offset)
+	f.offset += int64(n)
+	return n, err
+}
+
+// Seek sets the file offset for the next Read or Write.
+func (f *File) Seek(offset int64, whence int) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return 0, f.error("seek", fs.ErrClosed)
+	}
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		info, err := f.Stat()
+		if err != nil {
+			return 0, err
+		}
+		offset += info.Size()
+	default:
+		return 0, f.error("seek", fs.ErrInvalid)
+	}
+	if offset < 0 {
+		return 0, f.error("seek", fs.ErrInvalid)
+	}
+	f.offset = offset
+	if f.isDir() && offset == 0 {
+		f.dirOff, f.dirEntries, f.dirEOF = 0, nil, false
+	}
+	return offset, nil
+}
+
+// fill reads the next batch of directory entries.
+func (f *File) fill() error {
+	ctx, cancel := f.c.context()
+	defer cancel()
+	stream, err := f.c.client.ReadDir(ctx, &pb.ReadDirRequest{ReadIn: &pb.ReadIn{
+		Header: f.c.header(f.node),
+		Fh:     f.fh,
+		Offset: f.dirOff,
+		Size:   dirBufSize,
+	}}, f.c.opts.CallOptions...)
+	if err != nil {
+		return err
+	}
+	got := false
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err := check(res, err); err != nil {
+			return err
+		}
+		for _, e := range res.Entries {
+			got = true
+			f.dirOff = e.Off
+			name := string(e.Name)
+			if name == "." || name == ".." {
+				continue
+			}
+			f.dirEntries = append(f.dirEntries, &dirEntry{c: f.c, dir: f.name, name: name, mode: e.Mode})
+		}
+	}
+	if !got {
+		f.dirEOF = true
+	}
+	return nil
+}
+
+// ReadDir returns the next n entries of the directory, without "." and
+// "..", as fs.ReadDirFile does.
+func (f *File) ReadDir(n int) ([]fs.DirEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return nil, f.error("readdir", fs.ErrClosed)
+	}
+	if !f.isDir() {
+		return nil, f.error("readdir", syscall.ENOTDIR)
+	}
+	for !f.dirEOF && (n <= 0 || len(f.dirEntries) < n) {
+		if err := f.fill(); err != nil {
+			return nil, f.error("readdir", err)
+		}
+	}
+	entries := f.dirEntries
+	if n > 0 && len(entries) > n {
+		entries = entries[:n]
+	}
+	f.dirEntries = f.dirEntries[len(entries):]
+	if n > 0 && len(entries) == 0 {
+		return nil, io.EOF
+	}
+	return entries, nil
+}
+
+// Chmod changes the mode of the file.
+func (f *File) Chmod(mode fs.FileMode) error {
+	if err := f.c.setMode(f.node, mode); err != nil {
+		return f.error("chmod", err)
+	}
+	return nil
+}
+
+// Sync commits the file to stable storage on the server.
+func (f *File) Sync() error {
+	ctx, cancel := f.c.context()
+	defer cancel()
+	req := &pb.FsyncRequest{Header: f.c.header(f.node), Fh: f.fh}
+	var err error
+	if f.isDir() {
+		res, rerr := f.c.client.FsyncDir(ctx, req, f.c.opts.CallOptions...)
+		err = check(res, rerr)
+	} else {
+		res, rerr := f.c.client.Fsync(ctx, req, f.c.opts.CallOptions...)
+		err = check(res, rerr)
+	}
+	if err != nil {
+		return f.error("sync", err)
+	}
+	return nil
+}
+
+// Close flushes and releases the file, and forgets its node.
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return f.error("close", fs.ErrClosed)
+	}
+	f.closed = true
+	defer f.c.forget(f.node)
+
+	ctx, cancel := f.c.context()
+	defer cancel()
+	release := &pb.ReleaseRequest{Header: f.c.header(f.node), Fh: f.fh, LockOwner: f.owner}
+	if f.isDir() {
+		_, err := f.c.client.ReleaseDir(ctx, release, f.c.opts.CallOptions...)
+		if err != nil {
+			return f.error("close", err)
+		}
+		return nil
+	}
+	res, err := f.c.client.Flush(ctx, &pb.FlushRequest{Header: f.c.header(f.node), Fh: f.fh, LockOwner: f.owner}, f.c.opts.CallOptions...)
+	flushErr := check(res, err)
+	if flushErr == syscall.ENOSYS {
+		flushErr = nil
+	}
+	if _, err := f.c.client.Release(ctx, release, f.c.opts.CallOptions...); err != nil && flushErr == nil {
+		flushErr = err
+	}
+	if flushErr != nil {
+		return f.error("close", flushErr)
+	}
+	return nil
+}
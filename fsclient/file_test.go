@@ -0,0 +1,152 @@
+package fsclient
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadWriteAt(t *testing.T) {
+	// A small IOSize splits the calls.
+	c, l := newClient(t, &Options{IOSize: 7})
+	data := bytes.Repeat([]byte("0123456789"), 10)
+
+	f, err := c.Create("file")
+	require.NoError(t, err)
+	n, err := f.WriteAt(data, 0)
+	require.NoError(t, err)
+	assert.Equal(t, len(data), n)
+	_, err = f.WriteAt([]byte("x"), -1)
+	assert.True(t, errors.Is(err, fs.ErrInvalid))
+
+	tests := []struct {
+		name string
+		off  int64
+		size int
+		want []byte
+		err  error
+	}{
+		{name: "all", off: 0, size: 100, want: data},
+		{name: "middle", off: 33, size: 20, want: data[33:53]},
+		{name: "past the end", off: 90, size: 20, want: data[90:], err: io.EOF},
+		{name: "at the end", off: 100, size: 1, want: []byte{}, err: io.EOF},
+		{name: "negative", off: -1, size: 1, want: []byte{}, err: fs.ErrInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := make([]byte, tt.size)
+			n, err := f.ReadAt(buf, tt.off)
+			assert.True(t, errors.Is(err, tt.err), "%v", err)
+			assert.Equal(t, tt.want, buf[:n])
+		})
+	}
+	require.NoError(t, f.Sync())
+	require.NoError(t, f.Close())
+	assert.True(t, errors.Is(f.Close(), fs.ErrClosed))
+	_, err = f.Read(make([]byte, 1))
+	assert.True(t, errors.Is(err, fs.ErrClosed))
+	assert.Empty(t, l.held())
+}
+
+func TestReadSeek(t *testing.T) {
+	c, _ := newClient(t, &Options{IOSize: 4})
+	writeFile(t, c, "file", "hello, world")
+
+	f, err := c.OpenFile("file", os.O_RDWR, 0)
+	require.NoError(t, err)
+	defer f.Close()
+	// Read never returns more than IOSize.
+	buf := make([]byte, 100)
+	n, err := f.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "hell", string(buf[:n]))
+
+	off, err := f.Seek(-5, io.SeekEnd)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), off)
+	all, err := io.ReadAll(f)
+	require.NoError(t, err)
+	assert.Equal(t, "world", string(all))
+
+	_, err = f.Seek(7, io.SeekStart)
+	require.NoError(t, err)
+	_, err = f.Write([]byte("WORLD!"))
+	require.NoError(t, err)
+	off, err = f.Seek(0, io.SeekCurrent)
+	require.NoError(t, err)
+	assert.Equal(t, int64(13), off)
+	_, err = f.Seek(-20, io.SeekCurrent)
+	assert.True(t, errors.Is(err, fs.ErrInvalid))
+
+	data, err := fs.ReadFile(c, "file")
+	require.NoError(t, err)
+	assert.Equal(t, "hello, WORLD!", string(data))
+}
+
+func TestAppend(t *testing.T) {
+	c, _ := newClient(t, nil)
+	writeFile(t, c, "log", "one\n")
+
+	f, err := c.OpenFile("log", os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.Write([]byte("two\n"))
+	require.NoError(t, err)
+	_, err = f.WriteAt([]byte("x"), 0)
+	assert.Error(t, err)
+	require.NoError(t, f.Close())
+
+	data, err := fs.ReadFile(c, "log")
+	require.NoError(t, err)
+	assert.Equal(t, "one\ntwo\n", string(data))
+}
+
+func TestFileReadDir(t *testing.T) {
+	c, l := newClient(t, nil)
+	require.NoError(t, c.Mkdir("dir", 0755))
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		writeFile(t, c, "dir/"+name, "")
+	}
+
+	f, err := c.Open("dir")
+	require.NoError(t, err)
+	dir := f.(*File)
+	var names []string
+	for {
+		entries, err := dir.ReadDir(2)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(entries), 2)
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+	}
+	assert.ElementsMatch(t, []string{"a", "b", "c", "d", "e"}, names)
+
+	// Rewinding lists the directory again.
+	_, err = dir.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+	entries, err := dir.ReadDir(-1)
+	require.NoError(t, err)
+	assert.Len(t, entries, 5)
+
+	_, err = dir.Read(make([]byte, 1))
+	assert.True(t, errors.Is(err, syscall.EISDIR))
+	require.NoError(t, dir.Sync())
+	require.NoError(t, dir.Close())
+
+	file, err := c.Open("dir/a")
+	require.NoError(t, err)
+	_, err = file.(*File).ReadDir(-1)
+	assert.True(t, errors.Is(err, syscall.ENOTDIR))
+	require.NoError(t, file.Close())
+	assert.Empty(t, l.held())
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fsclient
+
+import (
+	"io/fs"
+	"path"
+	"syscall"
+	"time"
+
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+// toFileMode converts a st_mode to a FileMode.
+func toFileMode(mode uint32) fs.FileMode {
+	m := fs.FileMode(mode & 0777)
+	switch mode & syscall.S_IFMT {
+	case syscall.S_IFDIR:
+		m |= fs.ModeDir
+	case syscall.S_IFLNK:
+		m |= fs.ModeSymlink
+	case syscall.S_IFIFO:
+		m |= fs.ModeNamedPipe
+	case syscall.S_IFSOCK:
+		m |= fs.ModeSocket
+	case syscall.S_IFCHR:
+		m |= fs.ModeDevice | fs.ModeCharDevice
+	case syscall.S_IFBLK:
+		m |= fs.ModeDevice
+	}
+	if mode&syscall.S_ISUID != 0 {
+		m |= fs.ModeSetuid
+	}
+	if mode&syscall.S_ISGID != 0 {
+		m |= fs.ModeSetgid
+	}
+	if mode&syscall.S_ISVTX != 0 {
+		m |= fs.ModeSticky
+	}
+	return m
+}
+
+// fromFileMode converts the permission bits of a FileMode to a st_mode.
+func fromFileMode(m fs.FileMode) uint32 {
+	mode := uint32(m.Perm())
+	if m&fs.ModeSetuid != 0 {
+		mode |= syscall.S_ISUID
+	}
+	if m&fs.ModeSetgid != 0 {
+		mode |= syscall.S_ISGID
+	}
+	if m&fs.ModeSticky != 0 {
+		mode |= syscall.S_ISVTX
+	}
+	return mode
+}
+
+// fileInfo is the fs.FileInfo of a remote file. Sys returns its *pb.Attr.
+type fileInfo struct {
+	name string
+	attr *pb.Attr
+}
+
+func newFileInfo(name string, attr *pb.Attr) *fileInfo {
+	if attr == nil {
+		attr = &pb.Attr{}
+	}
+	return &fileInfo{name: path.Base(name), attr: attr}
+}
+
+func (fi *fileInfo) Name() string      { return fi.name }
+func (fi *fileInfo) Size() int64       { return int64(fi.attr.Size) }
+func (fi *fileInfo) Mode() fs.FileMode { return toFileMode(fi.attr.Mode) }
+func (fi *fileInfo) ModTime() time.Time {
+	return time.Unix(int64(fi.attr.Mtime), int64(fi.attr.Mtimensec))
+}
+func (fi *fileInfo) IsDir() bool      { return fi.Mode().IsDir() }
+func (fi *fileInfo) Sys() interface{} { return fi.attr }
+
+// dirEntry is an entry of a directory listing. Its Info is looked up when
+// asked for.
+type dirEntry struct {
+	c    *Client
+	dir  string
+	name string
+	mode uint32
+}
+
+func (e *dirEntry) Name() string      { return e.name }
+func (e *dirEntry) IsDir() bool       { return e.Type().IsDir() }
+func (e *dirEntry) Type() fs.FileMode { return toFileMode(e.mode).Type() }
+
+func (e *dirEntry) Info() (fs.FileInfo, error) {
+	return e.c.Stat(path.Join(e.dir, e.name))
+}
@@ -0,0 +1,47 @@
+package fsclient
+
+import (
+	"io/fs"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+func TestFileMode(t *testing.T) {
+	tests := []struct {
+		mode uint32
+		want fs.FileMode
+	}{
+		{mode: syscall.S_IFREG | 0644, want: 0644},
+		{mode: syscall.S_IFDIR | 0755, want: fs.ModeDir | 0755},
+		{mode: syscall.S_IFLNK | 0777, want: fs.ModeSymlink | 0777},
+		{mode: syscall.S_IFIFO | 0600, want: fs.ModeNamedPipe | 0600},
+		{mode: syscall.S_IFSOCK | 0600, want: fs.ModeSocket | 0600},
+		{mode: syscall.S_IFCHR | 0600, want: fs.ModeDevice | fs.ModeCharDevice | 0600},
+		{mode: syscall.S_IFBLK | 0600, want: fs.ModeDevice | 0600},
+		{mode: syscall.S_IFREG | syscall.S_ISUID | syscall.S_ISGID | 0755, want: fs.ModeSetuid | fs.ModeSetgid | 0755},
+		{mode: syscall.S_IFDIR | syscall.S_ISVTX | 0777, want: fs.ModeDir | fs.ModeSticky | 0777},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want.String(), func(t *testing.T) {
+			assert.Equal(t, tt.want, toFileMode(tt.mode))
+			assert.Equal(t, tt.mode&^syscall.S_IFMT, fromFileMode(tt.want))
+		})
+	}
+}
+
+func TestFileInfo(t *testing.T) {
+	mtime := time.Unix(1000, 42)
+	fi := newFileInfo("dir/file", &pb.Attr{Size: 7, Mode: syscall.S_IFREG | 0600, Mtime: 1000, Mtimensec: 42})
+	assert.Equal(t, "file", fi.Name())
+	assert.Equal(t, int64(7), fi.Size())
+	assert.Equal(t, fs.FileMode(0600), fi.Mode())
+	assert.True(t, mtime.Equal(fi.ModTime()))
+	assert.False(t, fi.IsDir())
+
+	assert.Equal(t, ".", newFileInfo(".", nil).Name())
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fsclient
+
+import (
+	"os"
+
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+// Symlink creates newname as a symbolic link to oldname, which is stored
+// as is.
+func (c *Client) Symlink(oldname, newname string) error {
+	parent, base, err := c.walkParent("symlink", newname)
+	if err != nil {
+		return err
+	}
+	defer c.forget(parent)
+	ctx, cancel := c.context()
+	defer cancel()
+	res, err := c.client.Symlink(ctx, &pb.SymlinkRequest{Header: c.header(parent), PointedTo: oldname, LinkName: base}, c.opts.CallOptions...)
+	if err := check(res, err); err != nil {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: err}
+	}
+	c.forget(res.EntryOut.GetNodeId())
+	return nil
+}
+
+// Link creates newname as a hard link to oldname.
+func (c *Client) Link(oldname, newname string) error {
+	entry, err := c.walk("link", oldname)
+	if err != nil {
+		return err
+	}
+	defer c.forget(entry.NodeId)
+	parent, base, err := c.walkParent("link", newname)
+	if err != nil {
+		return err
+	}
+	defer c.forget(parent)
+	ctx, cancel := c.context()
+	defer cancel()
+	res, err := c.client.Link(ctx, &pb.LinkRequest{Header: c.header(parent), Oldnodeid: entry.NodeId, Filename: base}, c.opts.CallOptions...)
+	if err := check(res, err); err != nil {
+		return &os.LinkError{Op: "link", Old: oldname, New: newname, Err: err}
+	}
+	c.forget(res.EntryOut.GetNodeId())
+	return nil
+}
+
+// Readlink returns the target of the named symbolic link.
+func (c *Client) Readlink(name string) (string, error) {
+	entry, err := c.walk("readlink", name)
+	if err != nil {
+		return "", err
+	}
+	defer c.forget(entry.NodeId)
+	ctx, cancel := c.context()
+	defer cancel()
+	res, err := c.client.Readlink(ctx, &pb.ReadlinkRequest{Header: c.header(entry.NodeId)}, c.opts.CallOptions...)
+	if err := check(res, err); err != nil {
+		return "", pathError("readlink", name, err)
+	}
+	return string(res.Out), nil
+}
@@ -0,0 +1,38 @@
+package fsclient
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinks(t *testing.T) {
+	c, l := newClient(t, nil)
+	writeFile(t, c, "file", "data")
+
+	require.NoError(t, c.Symlink("file", "sym"))
+	target, err := c.Readlink("sym")
+	require.NoError(t, err)
+	assert.Equal(t, "file", target)
+	info, err := c.Stat("sym")
+	require.NoError(t, err)
+	assert.Equal(t, fs.ModeSymlink, info.Mode().Type())
+
+	require.NoError(t, c.Link("file", "hard"))
+	data, err := fs.ReadFile(c, "hard")
+	require.NoError(t, err)
+	assert.Equal(t, "data", string(data))
+
+	var lerr *os.LinkError
+	assert.True(t, errors.As(c.Symlink("file", "hard"), &lerr))
+	assert.True(t, errors.Is(c.Link("file", "sym"), fs.ErrExist))
+	assert.True(t, errors.Is(c.Link("missing", "other"), fs.ErrNotExist))
+	_, err = c.Readlink("file")
+	assert.True(t, errors.Is(err, syscall.EINVAL), "%v", err)
+	assert.Empty(t, l.held())
+}
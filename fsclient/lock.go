@@ -0,0 +1,101 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fsclient
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+// lockOwners numbers the lock owners of this process's Files.
+var lockOwners uint64
+
+func newLockOwner() uint64 {
+	return uint64(os.Getpid())<<32 | atomic.AddUint64(&lockOwners, 1)
+}
+
+// Lock is a POSIX record lock on the bytes [Start, End] of a file.
+type Lock struct {
+	// Type is syscall.F_RDLCK, F_WRLCK or F_UNLCK.
+	Type       uint32
+	Start, End uint64
+	// Pid is the process holding the lock, as the server knows it.
+	Pid uint32
+}
+
+// GetLk returns the first lock held by another owner that conflicts with
+// lk, or lk with Type F_UNLCK if there is none, like F_GETLK.
+func (f *File) GetLk(lk Lock) (Lock, error) {
+	ctx, cancel := f.c.context()
+	defer cancel()
+	res, err := f.c.client.GetLk(ctx, &pb.LkRequest{
+		Header: f.c.header(f.node),
+		Fh:     f.fh,
+		Owner:  f.owner,
+		Lk:     &pb.FileLock{Start: lk.Start, End: lk.End, Type: lk.Type, Pid: f.c.opts.Pid},
+	}, f.c.opts.CallOptions...)
+	if err := check(res, err); err != nil {
+		return Lock{}, f.error("getlk", err)
+	}
+	return Lock{Type: res.Lk.GetType(), Start: res.Lk.GetStart(), End: res.Lk.GetEnd(), Pid: res.Lk.GetPid()}, nil
+}
+
+// SetLk takes or, with Type F_UNLCK, releases lk, failing with EAGAIN if
+// another owner holds a conflicting lock. Locks are released when the
+// File is closed.
+func (f *File) SetLk(lk Lock) error {
+	ctx, cancel := f.c.context()
+	defer cancel()
+	res, err := f.c.client.SetLk(ctx, &pb.LkRequest{
+		Header: f.c.header(f.node),
+		Fh:     f.fh,
+		Owner:  f.owner,
+		Lk:     &pb.FileLock{Start: lk.Start, End: lk.End, Type: lk.Type, Pid: f.c.opts.Pid},
+	}, f.c.opts.CallOptions...)
+	if err := check(res, err); err != nil {
+		return f.error("setlk", err)
+	}
+	return nil
+}
+
+// lockTypeName names the lock types.
+var lockTypeName = map[uint32]string{
+	syscall.F_RDLCK: "read",
+	syscall.F_WRLCK: "write",
+	syscall.F_UNLCK: "unlocked",
+}
+
+func (lk Lock) String() string {
+	name, ok := lockTypeName[lk.Type]
+	if !ok {
+		return fmt.Sprintf("lock type %d", lk.Type)
+	}
+	if lk.Type == syscall.F_UNLCK {
+		return name
+	}
+	end := strconv.FormatUint(lk.End, 10)
+	if lk.End == math.MaxUint64 || lk.End == math.MaxInt64 {
+		end = "EOF"
+	}
+	return fmt.Sprintf("%s lock on [%d, %s] by pid %d", name, lk.Start, end, lk.Pid)
+}
@@ -0,0 +1,44 @@
+package fsclient
+
+import (
+	"errors"
+	"math"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocks(t *testing.T) {
+	c, _ := newClient(t, nil)
+	writeFile(t, c, "file", "data")
+
+	a, err := c.OpenFile("file", os.O_RDWR, 0)
+	require.NoError(t, err)
+	b, err := c.OpenFile("file", os.O_RDWR, 0)
+	require.NoError(t, err)
+	defer b.Close()
+
+	all := Lock{Type: syscall.F_WRLCK, Start: 0, End: math.MaxInt64}
+	lk, err := b.GetLk(all)
+	require.NoError(t, err)
+	assert.Equal(t, "unlocked", lk.String())
+
+	require.NoError(t, a.SetLk(Lock{Type: syscall.F_WRLCK, Start: 10, End: 19}))
+	lk, err = b.GetLk(all)
+	require.NoError(t, err)
+	assert.Equal(t, Lock{Type: syscall.F_WRLCK, Start: 10, End: 19, Pid: uint32(os.Getpid())}, lk)
+	assert.Contains(t, lk.String(), "write lock on [10, 19] by pid")
+	assert.True(t, errors.Is(b.SetLk(Lock{Type: syscall.F_RDLCK, Start: 15, End: 15}), syscall.EAGAIN))
+	// The holder sees no conflict with itself.
+	lk, err = a.GetLk(all)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(syscall.F_UNLCK), lk.Type)
+
+	// Closing drops the locks.
+	require.NoError(t, a.Close())
+	require.NoError(t, b.SetLk(Lock{Type: syscall.F_RDLCK, Start: 15, End: 15}))
+	assert.Equal(t, "read lock on [0, EOF] by pid 7", Lock{Type: syscall.F_RDLCK, End: math.MaxInt64, Pid: 7}.String())
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fsclient
+
+import (
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+// Statfs is the usage of the file system, as statfs(2) reports it.
+type Statfs struct {
+	// Blocks, Bfree and Bavail are in units of Frsize bytes.
+	Blocks, Bfree, Bavail uint64
+	Files, Ffree          uint64
+	Bsize, Frsize         uint32
+	NameLen               uint32
+}
+
+// StatFs returns the usage of the file system the named file is on.
+func (c *Client) StatFs(name string) (*Statfs, error) {
+	entry, err := c.walk("statfs", name)
+	if err != nil {
+		return nil, err
+	}
+	defer c.forget(entry.NodeId)
+	ctx, cancel := c.context()
+	defer cancel()
+	res, err := c.client.StatFs(ctx, &pb.StatfsRequest{Input: c.header(entry.NodeId)}, c.opts.CallOptions...)
+	if err := check(res, err); err != nil {
+		return nil, pathError("statfs", name, err)
+	}
+	st := &Statfs{
+		Blocks:  res.Blocks,
+		Bfree:   res.Bfree,
+		Bavail:  res.Bavail,
+		Files:   res.Files,
+		Ffree:   res.Ffree,
+		Bsize:   res.Bsize,
+		Frsize:  res.Frsize,
+		NameLen: res.NameLen,
+	}
+	if st.Frsize == 0 {
+		st.Frsize = st.Bsize
+	}
+	return st, nil
+}
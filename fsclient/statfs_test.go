@@ -0,0 +1,29 @@
+package fsclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chiyutianyi/grpcfuse/grpcfusetest"
+	"github.com/chiyutianyi/grpcfuse/memfs"
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+func TestStatFs(t *testing.T) {
+	p := grpcfusetest.New(t, memfs.New(&memfs.Options{Capacity: 1 << 20, MaxInodes: 100, BlockSize: 4096}, nil), nil)
+	c := New(pb.NewRawFileSystemClient(p.Conn), nil)
+	writeFile(t, c, "file", string(make([]byte, 5000)))
+
+	st, err := c.StatFs(".")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(256), st.Blocks)
+	assert.Equal(t, uint64(254), st.Bfree)
+	assert.Equal(t, uint32(4096), st.Bsize)
+	assert.Equal(t, uint32(4096), st.Frsize)
+	assert.Equal(t, uint64(100), st.Files)
+
+	_, err = c.StatFs("missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fsclient
+
+import (
+	"strings"
+	"syscall"
+
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+// maxXAttrTries bounds the retries of a value that keeps growing between
+// asking for its size and reading it.
+const maxXAttrTries = 3
+
+// GetXAttr returns the value of the extended attribute attr of the named
+// file.
+func (c *Client) GetXAttr(name, attr string) ([]byte, error) {
+	entry, err := c.walk("getxattr", name)
+	if err != nil {
+		return nil, err
+	}
+	defer c.forget(entry.NodeId)
+	value, err := c.xattr(func(size uint32) (response, uint32, []byte, error) {
+		ctx, cancel := c.context()
+		defer cancel()
+		res, err := c.client.GetXAttr(ctx, &pb.GetXAttrRequest{Header: c.header(entry.NodeId), Attr: attr, Size: size}, c.opts.CallOptions...)
+		return res, res.GetSize(), res.GetValue(), err
+	})
+	if err != nil {
+		return nil, pathError("getxattr", name, err)
+	}
+	return value, nil
+}
+
+// ListXAttr returns the names of the extended attributes of the named
+// file.
+func (c *Client) ListXAttr(name string) ([]string, error) {
+	entry, err := c.walk("listxattr", name)
+	if err != nil {
+		return nil, err
+	}
+	defer c.forget(entry.NodeId)
+	value, err := c.xattr(func(size uint32) (response, uint32, []byte, error) {
+		ctx, cancel := c.context()
+		defer cancel()
+		res, err := c.client.ListXAttr(ctx, &pb.ListXAttrRequest{Header: c.header(entry.NodeId), Size: size}, c.opts.CallOptions...)
+		return res, res.GetSize(), res.GetValue(), err
+	})
+	if err != nil {
+		return nil, pathError("listxattr", name, err)
+	}
+	var names []string
+	for _, attr := range strings.Split(string(value), "\x00") {
+		if attr != "" {
+			names = append(names, attr)
+		}
+	}
+	return names, nil
+}
+
+// xattr asks for the size of a value, then for the value, again if it
+// grew in between.
+func (c *Client) xattr(get func(size uint32) (response, uint32, []byte, error)) ([]byte, error) {
+	res, size, _, err := get(0)
+	if err := check(res, err); err != nil {
+		return nil, err
+	}
+	for i := 0; i < maxXAttrTries; i++ {
+		if size == 0 {
+			return []byte{}, nil
+		}
+		var value []byte
+		res, size, value, err = get(size)
+		err = check(res, err)
+		if err != syscall.ERANGE {
+			return value, err
+		}
+	}
+	return nil, syscall.ERANGE
+}
+
+// SetXAttr sets the extended attribute attr of the named file. flags are
+// 0, XATTR_CREATE or XATTR_REPLACE.
+func (c *Client) SetXAttr(name, attr string, value []byte, flags uint32) error {
+	entry, err := c.walk("setxattr", name)
+	if err != nil {
+		return err
+	}
+	defer c.forget(entry.NodeId)
+	ctx, cancel := c.context()
+	defer cancel()
+	res, err := c.client.SetXAttr(ctx, &pb.SetXAttrRequest{
+		Header: c.header(entry.NodeId),
+		Attr:   attr,
+		Data:   value,
+		Size:   uint32(len(value)),
+		Flags:  flags,
+	}, c.opts.CallOptions...)
+	if err := check(res, err); err != nil {
+		return pathError("setxattr", name, err)
+	}
+	return nil
+}
+
+// RemoveXAttr removes the extended attribute attr of the named file.
+func (c *Client) RemoveXAttr(name, attr string) error {
+	entry, err := c.walk("removexattr", name)
+	if err != nil {
+		return err
+	}
+	defer c.forget(entry.NodeId)
+	ctx, cancel := c.context()
+	defer cancel()
+	res, err := c.client.RemoveXAttr(ctx, &pb.RemoveXAttrRequest{Header: c.header(entry.NodeId), Attr: attr}, c.opts.CallOptions...)
+	if err := check(res, err); err != nil {
+		return pathError("removexattr", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package fsclient
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestXAttr(t *testing.T) {
+	c, l := newClient(t, nil)
+	writeFile(t, c, "file", "")
+
+	names, err := c.ListXAttr("file")
+	require.NoError(t, err)
+	assert.Empty(t, names)
+
+	require.NoError(t, c.SetXAttr("file", "user.a", []byte("ay"), 0))
+	require.NoError(t, c.SetXAttr("file", "user.empty", nil, 0))
+	assert.True(t, errors.Is(c.SetXAttr("file", "user.a", []byte("x"), 1), syscall.EEXIST))
+
+	value, err := c.GetXAttr("file", "user.a")
+	require.NoError(t, err)
+	assert.Equal(t, "ay", string(value))
+	value, err = c.GetXAttr("file", "user.empty")
+	require.NoError(t, err)
+	assert.Empty(t, value)
+	_, err = c.GetXAttr("file", "user.missing")
+	assert.True(t, errors.Is(err, syscall.ENODATA), "%v", err)
+
+	names, err = c.ListXAttr("file")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user.a", "user.empty"}, names)
+
+	require.NoError(t, c.RemoveXAttr("file", "user.a"))
+	assert.True(t, errors.Is(c.RemoveXAttr("file", "user.a"), syscall.ENODATA))
+	assert.Empty(t, l.held())
+}
@@ -29,6 +29,9 @@ import (
 )
 
 func (s *server) Access(ctx context.Context, req *pb.AccessRequest) (*pb.AccessResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	var (
 		header fuse.InHeader
 	)
@@ -29,6 +29,9 @@ import (
 )
 
 func (s *server) GetAttr(ctx context.Context, req *pb.GetAttrRequest) (*pb.GetAttrResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	var (
 		out    fuse.AttrOut
 		header fuse.InHeader
@@ -56,6 +59,9 @@ func (s *server) GetAttr(ctx context.Context, req *pb.GetAttrRequest) (*pb.GetAt
 }
 
 func (s *server) SetAttr(ctx context.Context, req *pb.SetAttrRequest) (*pb.SetAttrResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	var (
 		out    fuse.AttrOut
 		header fuse.InHeader
@@ -83,8 +89,8 @@ func (s *server) SetAttr(ctx context.Context, req *pb.SetAttrRequest) (*pb.SetAt
 				Mode:      req.Mode,
 				Unused4:   req.Unused4,
 				Owner: fuse.Owner{
-					Uid: req.Owner.Uid,
-					Gid: req.Owner.Gid,
+					Uid: req.Owner.GetUid(),
+					Gid: req.Owner.GetGid(),
 				},
 				Unused5: req.Unused5,
 			},
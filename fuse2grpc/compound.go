@@ -0,0 +1,256 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fuse2grpc
+
+import (
+	"context"
+	"syscall"
+
+	grpc_logrus "github.com/grpc-ecosystem/go-grpc-middleware/logging/logrus"
+	"github.com/hanwen/go-fuse/v2/fuse"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+func (s *server) Compound(ctx context.Context, req *pb.CompoundRequest) (*pb.CompoundResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	grpc_logrus.Extract(ctx).WithFields(log.Fields{
+		"ops": len(req.Ops),
+	}).Debug("Compound")
+
+	res := &pb.CompoundResponse{Status: &pb.Status{Code: 0}}
+	for i, op := range req.Ops {
+		bind(op, res.Results)
+		result, code := s.compoundOp(ctx, op)
+		res.Results = append(res.Results, result)
+		if code != 0 {
+			res.Status.Code = code
+			s.releaseSkipped(ctx, req.Ops[i+1:], res.Results)
+			break
+		}
+	}
+	return res, nil
+}
+
+// bind gives op the node ID and handle of the earlier ops it takes them
+// from, whose results are results.
+func bind(op *pb.CompoundOp, results []*pb.CompoundResult) {
+	if op.NodeFrom != 0 {
+		if header := opHeader(op); header != nil {
+			header.NodeId, _ = produced(results[op.NodeFrom-1])
+		}
+	}
+	if op.FhFrom != 0 {
+		_, fh := produced(results[op.FhFrom-1])
+		setFh(op, fh)
+	}
+}
+
+// releaseSkipped runs the Releases among skipped, the ops left after one
+// failed, of the handles opened by the ops that ran, so that they do not
+// leak. The last of results is that of the op that failed.
+func (s *server) releaseSkipped(ctx context.Context, skipped []*pb.CompoundOp, results []*pb.CompoundResult) {
+	ran := len(results) - 1
+	for _, op := range skipped {
+		if _, ok := op.Op.(*pb.CompoundOp_Release); !ok || op.FhFrom == 0 || int(op.FhFrom) > ran || int(op.NodeFrom) > ran {
+			continue
+		}
+		bind(op, results)
+		if _, code := s.compoundOp(ctx, op); code != 0 {
+			log.Warnf("Compound: releasing handle %d: %v", op.GetRelease().Fh, fuse.Status(code))
+		}
+	}
+}
+
+// compoundOp runs op through the handler of its call, and returns its
+// result and the errno it failed with. Errors of the handler become the
+// errno closest to them.
+func (s *server) compoundOp(ctx context.Context, op *pb.CompoundOp) (*pb.CompoundResult, int32) {
+	var (
+		result = &pb.CompoundResult{}
+		st     *pb.Status
+		err    error
+	)
+	switch op := op.Op.(type) {
+	case *pb.CompoundOp_Lookup:
+		var res *pb.LookupResponse
+		res, err = s.Lookup(ctx, op.Lookup)
+		result.Result, st = &pb.CompoundResult_Lookup{Lookup: res}, res.GetStatus()
+	case *pb.CompoundOp_GetAttr:
+		var res *pb.GetAttrResponse
+		res, err = s.GetAttr(ctx, op.GetAttr)
+		result.Result, st = &pb.CompoundResult_GetAttr{GetAttr: res}, res.GetStatus()
+	case *pb.CompoundOp_Create:
+		var res *pb.CreateResponse
+		res, err = s.Create(ctx, op.Create)
+		result.Result, st = &pb.CompoundResult_Create{Create: res}, res.GetStatus()
+	case *pb.CompoundOp_Open:
+		var res *pb.OpenResponse
+		res, err = s.open(ctx, op.Open)
+		result.Result, st = &pb.CompoundResult_Open{Open: res}, res.GetStatus()
+	case *pb.CompoundOp_Read:
+		var res *pb.ReadResponse
+		res, err = s.readAll(ctx, op.Read)
+		result.Result, st = &pb.CompoundResult_Read{Read: res}, res.GetStatus()
+	case *pb.CompoundOp_Write:
+		var res *pb.WriteResponse
+		res, err = s.Write(ctx, op.Write)
+		result.Result, st = &pb.CompoundResult_Write{Write: res}, res.GetStatus()
+	case *pb.CompoundOp_Flush:
+		var res *pb.FlushResponse
+		res, err = s.Flush(ctx, op.Flush)
+		result.Result, st = &pb.CompoundResult_Flush{Flush: res}, res.GetStatus()
+	case *pb.CompoundOp_Release:
+		res, rerr := s.Release(ctx, op.Release)
+		result.Result, err = &pb.CompoundResult_Release{Release: res}, rerr
+	case *pb.CompoundOp_Fsync:
+		var res *pb.FsyncResponse
+		res, err = s.Fsync(ctx, op.Fsync)
+		result.Result, st = &pb.CompoundResult_Fsync{Fsync: res}, res.GetStatus()
+	}
+	if err != nil {
+		log.Debugf("Compound: %v", err)
+		switch status.Code(err) {
+		case codes.InvalidArgument:
+			return result, int32(syscall.EINVAL)
+		case codes.Unimplemented:
+			return result, int32(syscall.ENOSYS)
+		}
+		return result, int32(syscall.EIO)
+	}
+	return result, st.GetCode()
+}
+
+// readAll is a Read answered in one message, as the reads of a Compound
+// are bounded by validate.
+func (s *server) readAll(ctx context.Context, req *pb.ReadRequest) (*pb.ReadResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	var header fuse.InHeader
+	grpc_logrus.Extract(ctx).WithFields(log.Fields{
+		"nodeId": req.ReadIn.Header.NodeId,
+		"fh":     req.ReadIn.Fh,
+		"offset": req.ReadIn.Offset,
+		"size":   req.ReadIn.Size,
+	}).Debug("Read")
+	toFuseInHeader(req.ReadIn.Header, &header)
+
+	// Not from s.buffers: the data may be buf, which the response keeps.
+	buf := make([]byte, req.ReadIn.Size)
+	res, st := s.fs.Read(ctx.Done(), &fuse.ReadIn{
+		InHeader:  header,
+		Fh:        req.ReadIn.Fh,
+		Offset:    req.ReadIn.Offset,
+		Size:      req.ReadIn.Size,
+		ReadFlags: req.ReadIn.ReadFlags,
+	}, buf)
+	if st == fuse.ENOSYS {
+		return nil, status.Errorf(codes.Unimplemented, "method Read not implemented")
+	}
+	if st != fuse.OK {
+		return &pb.ReadResponse{Status: &pb.Status{Code: int32(st)}}, nil
+	}
+	data, st := res.Bytes(buf)
+	res.Done()
+	if st != fuse.OK {
+		return &pb.ReadResponse{Status: &pb.Status{Code: int32(st)}}, nil
+	}
+	return &pb.ReadResponse{Buffer: data, Status: &pb.Status{Code: 0}}, nil
+}
+
+// opHeader returns the header of op, which a bound node ID goes in.
+func opHeader(op *pb.CompoundOp) *pb.InHeader {
+	switch op := op.Op.(type) {
+	case *pb.CompoundOp_Lookup:
+		return op.Lookup.GetHeader()
+	case *pb.CompoundOp_GetAttr:
+		return op.GetAttr.GetHeader()
+	case *pb.CompoundOp_Create:
+		return op.Create.GetHeader()
+	case *pb.CompoundOp_Open:
+		return op.Open.GetOpenIn().GetHeader()
+	case *pb.CompoundOp_Read:
+		return op.Read.GetReadIn().GetHeader()
+	case *pb.CompoundOp_Write:
+		return op.Write.GetHeader()
+	case *pb.CompoundOp_Flush:
+		return op.Flush.GetHeader()
+	case *pb.CompoundOp_Release:
+		return op.Release.GetHeader()
+	case *pb.CompoundOp_Fsync:
+		return op.Fsync.GetHeader()
+	}
+	return nil
+}
+
+// takesFh reports whether op has a handle to bind.
+func takesFh(op *pb.CompoundOp) bool {
+	switch op.Op.(type) {
+	case *pb.CompoundOp_Read, *pb.CompoundOp_Write, *pb.CompoundOp_Flush, *pb.CompoundOp_Release, *pb.CompoundOp_Fsync:
+		return true
+	}
+	return false
+}
+
+func setFh(op *pb.CompoundOp, fh uint64) {
+	switch op := op.Op.(type) {
+	case *pb.CompoundOp_Read:
+		if op.Read.ReadIn != nil {
+			op.Read.ReadIn.Fh = fh
+		}
+	case *pb.CompoundOp_Write:
+		op.Write.Fh = fh
+	case *pb.CompoundOp_Flush:
+		op.Flush.Fh = fh
+	case *pb.CompoundOp_Release:
+		op.Release.Fh = fh
+	case *pb.CompoundOp_Fsync:
+		op.Fsync.Fh = fh
+	}
+}
+
+// produces reports whether op yields a node ID and a handle to bind.
+func produces(op *pb.CompoundOp) (node, fh bool) {
+	switch op.Op.(type) {
+	case *pb.CompoundOp_Lookup:
+		return true, false
+	case *pb.CompoundOp_Create:
+		return true, true
+	case *pb.CompoundOp_Open:
+		return false, true
+	}
+	return false, false
+}
+
+// produced returns the node ID and handle yielded by the op of result.
+func produced(result *pb.CompoundResult) (node, fh uint64) {
+	switch r := result.Result.(type) {
+	case *pb.CompoundResult_Lookup:
+		return r.Lookup.GetEntryOut().GetNodeId(), 0
+	case *pb.CompoundResult_Create:
+		return r.Create.GetEntryOut().GetNodeId(), r.Create.GetOpenOut().GetFh()
+	case *pb.CompoundResult_Open:
+		return 0, r.Open.GetOpenOut().GetFh()
+	}
+	return 0, 0
+}
@@ -0,0 +1,149 @@
+package fuse2grpc
+
+import (
+	"context"
+	"syscall"
+	"testing"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chiyutianyi/grpcfuse/memfs"
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+func TestCompound(t *testing.T) {
+	s := NewServer(memfs.New(nil, nil))
+	ctx := context.Background()
+	header := func() *pb.InHeader {
+		return &pb.InHeader{NodeId: 1, Caller: validHeader.Caller}
+	}
+
+	// Create, write and close, each op on the node and handle of the
+	// Create.
+	res, err := s.Compound(ctx, &pb.CompoundRequest{Ops: []*pb.CompoundOp{
+		{Op: &pb.CompoundOp_Create{Create: &pb.CreateRequest{Header: header(), Name: "f", Flags: uint32(syscall.O_WRONLY), Mode: 0644}}},
+		{Op: &pb.CompoundOp_Write{Write: &pb.WriteRequest{Header: header(), Data: []byte("hello"), Size: 5}}, NodeFrom: 1, FhFrom: 1},
+		{Op: &pb.CompoundOp_Flush{Flush: &pb.FlushRequest{Header: header()}}, NodeFrom: 1, FhFrom: 1},
+		{Op: &pb.CompoundOp_Release{Release: &pb.ReleaseRequest{Header: header()}}, NodeFrom: 1, FhFrom: 1},
+	}})
+	require.NoError(t, err)
+	assert.Zero(t, res.Status.Code)
+	require.Len(t, res.Results, 4)
+	assert.Equal(t, uint32(5), res.Results[1].GetWrite().Written)
+
+	// Look up, open, read and close.
+	res, err = s.Compound(ctx, &pb.CompoundRequest{Ops: []*pb.CompoundOp{
+		{Op: &pb.CompoundOp_Lookup{Lookup: &pb.LookupRequest{Header: header(), Name: "f"}}},
+		{Op: &pb.CompoundOp_Open{Open: &pb.OpenRequest{OpenIn: &pb.OpenIn{Header: header()}}}, NodeFrom: 1},
+		{Op: &pb.CompoundOp_Read{Read: &pb.ReadRequest{ReadIn: &pb.ReadIn{Header: header(), Size: 100}}}, NodeFrom: 1, FhFrom: 2},
+		{Op: &pb.CompoundOp_Release{Release: &pb.ReleaseRequest{Header: header()}}, NodeFrom: 1, FhFrom: 2},
+	}})
+	require.NoError(t, err)
+	assert.Zero(t, res.Status.Code)
+	require.Len(t, res.Results, 4)
+	assert.Equal(t, "hello", string(res.Results[2].GetRead().Buffer))
+
+	// The first op to fail is the last to run.
+	res, err = s.Compound(ctx, &pb.CompoundRequest{Ops: []*pb.CompoundOp{
+		{Op: &pb.CompoundOp_Lookup{Lookup: &pb.LookupRequest{Header: header(), Name: "nope"}}},
+		{Op: &pb.CompoundOp_GetAttr{GetAttr: &pb.GetAttrRequest{Header: header()}}, NodeFrom: 1},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, int32(syscall.ENOENT), res.Status.Code)
+	require.Len(t, res.Results, 1)
+	assert.Equal(t, int32(syscall.ENOENT), res.Results[0].GetLookup().Status.Code)
+
+	// An op its handler rejects fails with EINVAL.
+	res, err = s.Compound(ctx, &pb.CompoundRequest{Ops: []*pb.CompoundOp{
+		{Op: &pb.CompoundOp_GetAttr{GetAttr: &pb.GetAttrRequest{}}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, int32(syscall.EINVAL), res.Status.Code)
+}
+
+// failingWrites fails every Write, and records the handles released.
+type failingWrites struct {
+	fuse.RawFileSystem
+	released []uint64
+}
+
+func (fs *failingWrites) Write(cancel <-chan struct{}, in *fuse.WriteIn, data []byte) (uint32, fuse.Status) {
+	return 0, fuse.EIO
+}
+
+func (fs *failingWrites) Release(cancel <-chan struct{}, in *fuse.ReleaseIn) {
+	fs.released = append(fs.released, in.Fh)
+	fs.RawFileSystem.Release(cancel, in)
+}
+
+func TestCompoundReleasesOnFailure(t *testing.T) {
+	fs := &failingWrites{RawFileSystem: memfs.New(nil, nil)}
+	s := NewServer(fs)
+	header := func() *pb.InHeader {
+		return &pb.InHeader{NodeId: 1, Caller: validHeader.Caller}
+	}
+
+	// The Write fails: the Flush is skipped, but not the Release of the
+	// handle of the Create.
+	res, err := s.Compound(context.Background(), &pb.CompoundRequest{Ops: []*pb.CompoundOp{
+		{Op: &pb.CompoundOp_Create{Create: &pb.CreateRequest{Header: header(), Name: "f", Flags: uint32(syscall.O_WRONLY), Mode: 0644}}},
+		{Op: &pb.CompoundOp_Write{Write: &pb.WriteRequest{Header: header(), Data: []byte("hello"), Size: 5}}, NodeFrom: 1, FhFrom: 1},
+		{Op: &pb.CompoundOp_Flush{Flush: &pb.FlushRequest{Header: header()}}, NodeFrom: 1, FhFrom: 1},
+		{Op: &pb.CompoundOp_Release{Release: &pb.ReleaseRequest{Header: header()}}, NodeFrom: 1, FhFrom: 1},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, int32(syscall.EIO), res.Status.Code)
+	require.Len(t, res.Results, 2)
+	assert.Equal(t, []uint64{res.Results[0].GetCreate().OpenOut.Fh}, fs.released)
+
+	// Nothing is released for an op that failed to open.
+	fs.released = nil
+	res, err = s.Compound(context.Background(), &pb.CompoundRequest{Ops: []*pb.CompoundOp{
+		{Op: &pb.CompoundOp_Lookup{Lookup: &pb.LookupRequest{Header: header(), Name: "nope"}}},
+		{Op: &pb.CompoundOp_Open{Open: &pb.OpenRequest{OpenIn: &pb.OpenIn{Header: header()}}}, NodeFrom: 1},
+		{Op: &pb.CompoundOp_Release{Release: &pb.ReleaseRequest{Header: header()}}, NodeFrom: 1, FhFrom: 2},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, int32(syscall.ENOENT), res.Status.Code)
+	assert.Empty(t, fs.released)
+}
+
+func TestValidateCompound(t *testing.T) {
+	lookup := &pb.CompoundOp{Op: &pb.CompoundOp_Lookup{Lookup: &pb.LookupRequest{}}}
+	open := &pb.CompoundOp{Op: &pb.CompoundOp_Open{Open: &pb.OpenRequest{}}}
+	tests := []struct {
+		name    string
+		ops     []*pb.CompoundOp
+		wantErr string
+	}{
+		{name: "bound", ops: []*pb.CompoundOp{lookup, {Op: open.Op, NodeFrom: 1}}},
+		{name: "no ops", wantErr: "no ops"},
+		{name: "empty op", ops: []*pb.CompoundOp{{}}, wantErr: "op 1 is empty"},
+		{name: "node of a later op", ops: []*pb.CompoundOp{{Op: open.Op, NodeFrom: 2}, lookup}, wantErr: "not before it"},
+		{name: "node of an open", ops: []*pb.CompoundOp{open, {Op: open.Op, NodeFrom: 1}}, wantErr: "yields none"},
+		{name: "handle of a lookup", ops: []*pb.CompoundOp{lookup, {Op: &pb.CompoundOp_Flush{Flush: &pb.FlushRequest{}}, FhFrom: 1}}, wantErr: "yields none"},
+		{name: "handle for an open", ops: []*pb.CompoundOp{open, {Op: open.Op, FhFrom: 1}}, wantErr: "takes no handle"},
+		{name: "too many ops", ops: make([]*pb.CompoundOp, maxCompoundOps+1), wantErr: "exceed"},
+		{
+			name: "too much data",
+			ops: []*pb.CompoundOp{
+				{Op: &pb.CompoundOp_Read{Read: &pb.ReadRequest{ReadIn: &pb.ReadIn{Size: maxCompoundIO}}}},
+				{Op: &pb.CompoundOp_Write{Write: &pb.WriteRequest{Data: []byte("x")}}},
+			},
+			wantErr: "bytes of data exceed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate(&pb.CompoundRequest{Ops: tt.ops})
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
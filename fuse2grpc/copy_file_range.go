@@ -29,6 +29,9 @@ import (
 )
 
 func (s *server) CopyFileRange(ctx context.Context, req *pb.CopyFileRangeRequest) (*pb.CopyFileRangeResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	var (
 		header fuse.InHeader
 	)
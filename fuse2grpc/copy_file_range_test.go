@@ -178,6 +178,7 @@ func TestCopyFileRange(t *testing.T) {
 						},
 					},
 				},
+				NodeIdOut: 3,
 			},
 			mockReturn: []interface{}{uint32(0), fuse.ENOSYS},
 			want:       nil,
@@ -195,6 +196,7 @@ func TestCopyFileRange(t *testing.T) {
 						},
 					},
 				},
+				NodeIdOut: 3,
 			},
 			mockReturn: []interface{}{uint32(0), fuse.ENOENT},
 			want: &pb.CopyFileRangeResponse{
@@ -19,7 +19,6 @@ package fuse2grpc
 import (
 	"context"
 	"fmt"
-	"unsafe"
 
 	grpc_logrus "github.com/grpc-ecosystem/go-grpc-middleware/logging/logrus"
 	"github.com/hanwen/go-fuse/v2/fuse"
@@ -32,6 +31,9 @@ import (
 )
 
 func (s *server) OpenDir(ctx context.Context, req *pb.OpenDirRequest) (*pb.OpenDirResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	var (
 		header fuse.InHeader
 		out    fuse.OpenOut
@@ -65,12 +67,14 @@ func (s *server) doReadDir(
 	stream pb.RawFileSystem_ReadDirServer,
 	reader func(cancel <-chan struct{}, input *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status,
 	readerName string,
-	prefix uint32,
+	plus bool,
 ) error {
+	if err := validate(req); err != nil {
+		return err
+	}
 	var (
 		header           fuse.InHeader
 		batchSize, delta int
-		pos              uint32
 		batch            []*pb.DirEntry
 	)
 	ctx := stream.Context()
@@ -85,6 +89,11 @@ func (s *server) doReadDir(
 
 	buf := s.buffers.AllocBuffer(req.ReadIn.Size)
 	defer s.buffers.FreeBuffer(buf)
+	// Pooled buffers hold stale records; decodeDirents stops at the
+	// first zeroed one.
+	for i := range buf {
+		buf[i] = 0
+	}
 
 	out := fuse.NewDirEntryList(buf, req.ReadIn.Offset)
 
@@ -119,22 +128,11 @@ func (s *server) doReadDir(
 		return nil
 	}
 
-	buf = (*DirEntryList)(unsafe.Pointer(out)).buf
-	bufsize := len(buf)
-
-	for {
-		if int(pos) >= bufsize || len(buf[pos:]) < int(direntSize) {
-			break
-		}
-
-		pos += prefix
-
-		e := (*_Dirent)(unsafe.Pointer(&buf[pos]))
-		if e.Off == 0 {
-			break
-		}
-		// uint64 Ino uint64 Offset uint32 NameLen uint32 Typ
+	err := decodeDirents(buf, plus, func(e *_Dirent, name []byte, entryOut *fuse.EntryOut) error {
 		delta = deltaSize(e)
+		if entryOut != nil {
+			delta += int(entryOutSize)
+		}
 		if batchSize+delta > s.msgSizeThreshold {
 			if err := flushFunc(); err != nil {
 				return err
@@ -142,22 +140,30 @@ func (s *server) doReadDir(
 			batch = nil
 			batchSize = 0
 		}
-		dirEntry := &pb.DirEntry{Mode: typeToMode(e.Typ), Ino: e.Ino, Name: buf[pos+direntSize : pos+direntSize+e.NameLen]}
+		dirEntry := &pb.DirEntry{
+			Mode: typeToMode(e.Typ),
+			Ino:  e.Ino,
+			Name: name,
+			Off:  e.Off,
+		}
+		if entryOut != nil {
+			dirEntry.EntryOut = toPbEntryOut(entryOut)
+		}
 		batch = append(batch, dirEntry)
 		batchSize += delta
-		// fuse.DirEntryList.Add()
-		// padding := (8 - len(name)&7) & 7
-		padding := (8 - e.NameLen&7) & 7
-		pos += padding + direntSize + e.NameLen
-	}
-	if len(batch) == 0 {
 		return nil
+	})
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		return status.Errorf(codes.Internal, "%s: %v", readerName, err)
 	}
 	return flushFunc()
 }
 
 func (s *server) ReadDir(req *pb.ReadDirRequest, stream pb.RawFileSystem_ReadDirServer) error {
-	return s.doReadDir(req, stream, s.fs.ReadDir, "ReadDir", 0)
+	return s.doReadDir(req, stream, s.fs.ReadDir, "ReadDir", false)
 }
 
 func deltaSize(e *_Dirent) int {
@@ -166,10 +172,13 @@ func deltaSize(e *_Dirent) int {
 }
 
 func (s *server) ReadDirPlus(req *pb.ReadDirRequest, stream pb.RawFileSystem_ReadDirPlusServer) error {
-	return s.doReadDir(req, stream, s.fs.ReadDirPlus, "ReadDirPlus", entryOutSize)
+	return s.doReadDir(req, stream, s.fs.ReadDirPlus, "ReadDirPlus", true)
 }
 
 func (s *server) ReleaseDir(ctx context.Context, req *pb.ReleaseRequest) (*emptypb.Empty, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	var (
 		header fuse.InHeader
 	)
@@ -186,6 +195,9 @@ func (s *server) ReleaseDir(ctx context.Context, req *pb.ReleaseRequest) (*empty
 }
 
 func (s *server) FsyncDir(ctx context.Context, req *pb.FsyncRequest) (*pb.FsyncResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	var (
 		header fuse.InHeader
 	)
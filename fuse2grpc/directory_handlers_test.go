@@ -1,10 +1,15 @@
 package fuse2grpc
 
 import (
+	"context"
 	"testing"
 	"unsafe"
 
+	"github.com/hanwen/go-fuse/v2/fuse"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/chiyutianyi/grpcfuse/pb"
 )
 
 func TestDeltaSize(t *testing.T) {
@@ -63,3 +68,97 @@ func TestDeltaSize(t *testing.T) {
 		})
 	}
 }
+
+type dirListFS struct {
+	fuse.RawFileSystem
+	entries []fuse.DirEntry
+}
+
+func (fs *dirListFS) ReadDir(cancel <-chan struct{}, input *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
+	for _, e := range fs.entries[input.Offset:] {
+		if !out.AddDirEntry(e) {
+			break
+		}
+	}
+	return fuse.OK
+}
+
+func (fs *dirListFS) ReadDirPlus(cancel <-chan struct{}, input *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
+	for _, e := range fs.entries[input.Offset:] {
+		entryOut := out.AddDirLookupEntry(e)
+		if entryOut == nil {
+			break
+		}
+		entryOut.NodeId = e.Ino + 100
+		entryOut.Generation = 1
+		entryOut.EntryValid = 2
+		entryOut.AttrValid = 3
+		entryOut.Attr.Ino = e.Ino
+		entryOut.Attr.Mode = e.Mode | 0644
+		entryOut.Attr.Size = uint64(len(e.Name))
+	}
+	return fuse.OK
+}
+
+type readDirStream struct {
+	grpc.ServerStream
+	responses []*pb.ReadDirResponse
+}
+
+func (s *readDirStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *readDirStream) Send(res *pb.ReadDirResponse) error {
+	s.responses = append(s.responses, res)
+	return nil
+}
+
+func (s *readDirStream) entries() []*pb.DirEntry {
+	var entries []*pb.DirEntry
+	for _, res := range s.responses {
+		entries = append(entries, res.Entries...)
+	}
+	return entries
+}
+
+func TestReadDirPlus(t *testing.T) {
+	fs := &dirListFS{entries: []fuse.DirEntry{
+		{Name: "a", Ino: 2, Mode: fuse.S_IFREG},
+		{Name: "dir", Ino: 3, Mode: fuse.S_IFDIR},
+		{Name: "long-file-name", Ino: 4, Mode: fuse.S_IFREG},
+	}}
+	s := NewServer(fs)
+
+	readIn := &pb.ReadIn{Header: &pb.InHeader{NodeId: 1, Caller: &pb.Caller{Owner: &pb.Owner{}}}, Size: 4096}
+
+	stream := &readDirStream{}
+	require.NoError(t, s.ReadDirPlus(&pb.ReadDirRequest{ReadIn: readIn}, stream))
+	entries := stream.entries()
+	require.Len(t, entries, 3)
+	for i, e := range entries {
+		de := fs.entries[i]
+		require.Equal(t, de.Name, string(e.Name))
+		require.Equal(t, de.Ino, e.Ino)
+		require.Equal(t, de.Mode, e.Mode)
+		require.Equal(t, uint64(i+1), e.Off)
+		require.NotNil(t, e.EntryOut)
+		require.Equal(t, de.Ino+100, e.EntryOut.NodeId)
+		require.Equal(t, uint64(1), e.EntryOut.Generation)
+		require.Equal(t, uint64(2), e.EntryOut.EntryValid)
+		require.Equal(t, uint64(3), e.EntryOut.AttrValid)
+		require.Equal(t, de.Ino, e.EntryOut.Attr.Ino)
+		require.Equal(t, de.Mode|0644, e.EntryOut.Attr.Mode)
+		require.Equal(t, uint64(len(de.Name)), e.EntryOut.Attr.Size)
+	}
+
+	stream = &readDirStream{}
+	require.NoError(t, s.ReadDir(&pb.ReadDirRequest{ReadIn: readIn}, stream))
+	entries = stream.entries()
+	require.Len(t, entries, 3)
+	for i, e := range entries {
+		require.Equal(t, fs.entries[i].Name, string(e.Name))
+		require.Equal(t, uint64(i+1), e.Off)
+		require.Nil(t, e.EntryOut)
+	}
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fuse2grpc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+)
+
+// maxNameLen is FUSE_NAME_MAX.
+const maxNameLen = 1024
+
+var errTruncatedDirent = errors.New("truncated dirent")
+
+// nativeEndian is the byte order the kernel and go-fuse use for the
+// FUSE wire format.
+var nativeEndian binary.ByteOrder = func() binary.ByteOrder {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) == 1 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}()
+
+// direntVisitor is called for each record found by decodeDirents. name
+// aliases the decoded buffer. entryOut is only set for READDIRPLUS.
+type direntVisitor func(e *_Dirent, name []byte, entryOut *fuse.EntryOut) error
+
+// decodeDirents walks the records fuse.DirEntryList serialized into buf.
+// A READDIRPLUS record (plus is true) is a fuse.EntryOut followed by a
+// READDIR record: a _Dirent, the name and padding up to 8 bytes.
+//
+// buf must be zeroed before the file system fills it: the listing ends
+// at the first record with a zero offset, which go-fuse never writes,
+// or at the end of buf.
+func decodeDirents(buf []byte, plus bool, visit direntVisitor) error {
+	var (
+		prefix   int
+		e        _Dirent
+		entryOut fuse.EntryOut
+	)
+	if plus {
+		prefix = int(entryOutSize)
+	}
+
+	for pos := 0; pos < len(buf); {
+		rest := buf[pos:]
+		if len(rest) < prefix+int(direntSize) {
+			if isZero(rest) {
+				return nil
+			}
+			return fmt.Errorf("%w: %d bytes left at %d", errTruncatedDirent, len(rest), pos)
+		}
+
+		d := rest[prefix:]
+		e.Ino = nativeEndian.Uint64(d[0:])
+		e.Off = nativeEndian.Uint64(d[8:])
+		e.NameLen = nativeEndian.Uint32(d[16:])
+		e.Typ = nativeEndian.Uint32(d[20:])
+		if e.Off == 0 {
+			return nil
+		}
+		if e.NameLen == 0 || e.NameLen > maxNameLen {
+			return fmt.Errorf("invalid dirent name length %d at %d", e.NameLen, pos)
+		}
+
+		nameStart := prefix + int(direntSize)
+		nameEnd := nameStart + int(e.NameLen)
+		if nameEnd > len(rest) {
+			return fmt.Errorf("%w: name of %d bytes at %d", errTruncatedDirent, e.NameLen, pos)
+		}
+
+		var out *fuse.EntryOut
+		if plus {
+			if err := binary.Read(bytes.NewReader(rest[:prefix]), nativeEndian, &entryOut); err != nil {
+				return err
+			}
+			out = &entryOut
+		}
+		if err := visit(&e, rest[nameStart:nameEnd], out); err != nil {
+			return err
+		}
+
+		pos += nameEnd + int((8-e.NameLen&7)&7)
+	}
+	return nil
+}
+
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,241 @@
+package fuse2grpc
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type decodedDirent struct {
+	dirent   _Dirent
+	name     string
+	entryOut *fuse.EntryOut
+}
+
+func decodeAll(buf []byte, plus bool) ([]decodedDirent, error) {
+	var entries []decodedDirent
+	err := decodeDirents(buf, plus, func(e *_Dirent, name []byte, entryOut *fuse.EntryOut) error {
+		d := decodedDirent{dirent: *e, name: string(name)}
+		if entryOut != nil {
+			eo := *entryOut
+			d.entryOut = &eo
+		}
+		entries = append(entries, d)
+		return nil
+	})
+	return entries, err
+}
+
+// encodeDirents serializes entries the way a file system does.
+func encodeDirents(size int, off uint64, plus bool, entries []fuse.DirEntry) []byte {
+	buf := make([]byte, size)
+	out := fuse.NewDirEntryList(buf, off)
+	for _, e := range entries {
+		if !plus {
+			if !out.AddDirEntry(e) {
+				break
+			}
+			continue
+		}
+		entryOut := out.AddDirLookupEntry(e)
+		if entryOut == nil {
+			break
+		}
+		entryOut.NodeId = e.Ino * 10
+		entryOut.Generation = 7
+		entryOut.Attr.Ino = e.Ino
+		entryOut.Attr.Mode = e.Mode | 0600
+		entryOut.Attr.Size = uint64(len(e.Name))
+		entryOut.Attr.Uid = 1000
+	}
+	return buf
+}
+
+var testDirents = []fuse.DirEntry{
+	{Name: ".", Ino: 1, Mode: fuse.S_IFDIR},
+	{Name: "..", Ino: 1, Mode: fuse.S_IFDIR},
+	{Name: "file", Ino: 2, Mode: fuse.S_IFREG},
+	{Name: "exactly8", Ino: 3, Mode: fuse.S_IFLNK},
+	{Name: strings.Repeat("n", 255), Ino: 4, Mode: fuse.S_IFDIR},
+}
+
+func TestDecodeDirents(t *testing.T) {
+	for _, plus := range []bool{false, true} {
+		buf := encodeDirents(4096, 5, plus, testDirents)
+		entries, err := decodeAll(buf, plus)
+		require.NoError(t, err)
+		require.Len(t, entries, len(testDirents))
+		for i, e := range entries {
+			want := testDirents[i]
+			assert.Equal(t, want.Name, e.name)
+			assert.Equal(t, want.Ino, e.dirent.Ino)
+			assert.Equal(t, uint64(6+i), e.dirent.Off)
+			assert.Equal(t, want.Mode, typeToMode(e.dirent.Typ))
+			if !plus {
+				assert.Nil(t, e.entryOut)
+				continue
+			}
+			require.NotNil(t, e.entryOut)
+			assert.Equal(t, want.Ino*10, e.entryOut.NodeId)
+			assert.Equal(t, uint64(7), e.entryOut.Generation)
+			assert.Equal(t, want.Ino, e.entryOut.Ino)
+			assert.Equal(t, want.Mode|0600, e.entryOut.Mode)
+			assert.Equal(t, uint64(len(want.Name)), e.entryOut.Size)
+			assert.Equal(t, uint32(1000), e.entryOut.Uid)
+		}
+	}
+}
+
+func TestDecodeDirentsFullBuffer(t *testing.T) {
+	// Each record of "file" takes exactly 32 bytes.
+	entries := []fuse.DirEntry{{Name: "file", Ino: 2}, {Name: "file", Ino: 3}}
+	buf := encodeDirents(64, 0, false, entries)
+	decoded, err := decodeAll(buf, false)
+	require.NoError(t, err)
+	require.Len(t, decoded, 2)
+}
+
+func TestDecodeDirentsMalformed(t *testing.T) {
+	valid := encodeDirents(256, 0, false, []fuse.DirEntry{{Name: "file", Ino: 2}})
+
+	tests := []struct {
+		name    string
+		buf     []byte
+		plus    bool
+		wantErr error
+	}{
+		{
+			name: "empty",
+			buf:  nil,
+		},
+		{
+			name: "zeroed",
+			buf:  make([]byte, 100),
+		},
+		{
+			name:    "truncated header",
+			buf:     valid[:20],
+			wantErr: errTruncatedDirent,
+		},
+		{
+			name:    "truncated name",
+			buf:     valid[:26],
+			wantErr: errTruncatedDirent,
+		},
+		{
+			name:    "truncated entry out",
+			buf:     encodeDirents(256, 0, true, []fuse.DirEntry{{Name: "file", Ino: 2}})[:100],
+			plus:    true,
+			wantErr: errTruncatedDirent,
+		},
+		{
+			name: "huge name length",
+			buf: func() []byte {
+				b := append([]byte(nil), valid...)
+				nativeEndian.PutUint32(b[16:], 1<<31)
+				return b
+			}(),
+			wantErr: errors.New("invalid dirent name length 2147483648 at 0"),
+		},
+		{
+			name: "empty name",
+			buf: func() []byte {
+				b := append([]byte(nil), valid...)
+				nativeEndian.PutUint32(b[16:], 0)
+				return b
+			}(),
+			wantErr: errors.New("invalid dirent name length 0 at 0"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeAll(tt.buf, tt.plus)
+			switch {
+			case tt.wantErr == nil:
+				assert.NoError(t, err)
+			case errors.Is(tt.wantErr, errTruncatedDirent):
+				assert.ErrorIs(t, err, errTruncatedDirent)
+			default:
+				assert.EqualError(t, err, tt.wantErr.Error())
+			}
+		})
+	}
+}
+
+func TestDecodeDirentsVisitorError(t *testing.T) {
+	buf := encodeDirents(4096, 0, false, testDirents)
+	stop := errors.New("stop")
+	calls := 0
+	err := decodeDirents(buf, false, func(*_Dirent, []byte, *fuse.EntryOut) error {
+		calls++
+		return stop
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 1, calls)
+}
+
+func FuzzDecodeDirents(f *testing.F) {
+	for _, plus := range []bool{false, true} {
+		buf := encodeDirents(1024, 0, plus, testDirents)
+		f.Add(buf, plus)
+		f.Add(buf[:len(buf)/2], plus)
+		f.Add(buf[:direntSize+3], plus)
+	}
+	f.Add([]byte{}, false)
+
+	f.Fuzz(func(t *testing.T, buf []byte, plus bool) {
+		err := decodeDirents(buf, plus, func(e *_Dirent, name []byte, entryOut *fuse.EntryOut) error {
+			if int(e.NameLen) != len(name) || len(name) == 0 || len(name) > maxNameLen {
+				t.Fatalf("bad name length %d for %d bytes", e.NameLen, len(name))
+			}
+			if plus != (entryOut != nil) {
+				t.Fatalf("entryOut %v for plus=%v", entryOut, plus)
+			}
+			return nil
+		})
+		if err == nil {
+			return
+		}
+		// Malformed input is reported, never a panic.
+		if !strings.Contains(err.Error(), "dirent") {
+			t.Fatalf("unexpected error %v", err)
+		}
+	})
+}
+
+func FuzzDirentRoundTrip(f *testing.F) {
+	f.Add("a/b/c", uint64(0), uint16(4096), false)
+	f.Add("file/dir/"+strings.Repeat("x", 300), uint64(10), uint16(512), true)
+
+	f.Fuzz(func(t *testing.T, names string, off uint64, size uint16, plus bool) {
+		if off == ^uint64(0) {
+			// Add would hand out a zero offset.
+			return
+		}
+		var entries []fuse.DirEntry
+		for i, name := range strings.Split(names, "/") {
+			if name == "" || len(name) > maxNameLen {
+				continue
+			}
+			entries = append(entries, fuse.DirEntry{Name: name, Ino: uint64(i + 1), Mode: fuse.S_IFREG})
+		}
+		buf := encodeDirents(int(size), off, plus, entries)
+		decoded, err := decodeAll(buf, plus)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(decoded) > len(entries) {
+			t.Fatalf("decoded %d of %d entries", len(decoded), len(entries))
+		}
+		for i, d := range decoded {
+			if d.name != entries[i].Name || d.dirent.Ino != entries[i].Ino || d.dirent.Off != off+uint64(i)+1 {
+				t.Fatalf("entry %d: got %+v, want %+v", i, d, entries[i])
+			}
+		}
+	})
+}
@@ -29,6 +29,9 @@ import (
 )
 
 func (s *server) Fallocate(ctx context.Context, req *pb.FallocateRequest) (*pb.FallocateResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	var (
 		header fuse.InHeader
 	)
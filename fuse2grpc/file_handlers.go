@@ -29,6 +29,9 @@ import (
 )
 
 func (s *server) Create(ctx context.Context, req *pb.CreateRequest) (*pb.CreateResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	var (
 		header fuse.InHeader
 		out    fuse.CreateOut
@@ -60,6 +63,17 @@ func (s *server) Create(ctx context.Context, req *pb.CreateRequest) (*pb.CreateR
 }
 
 func (s *server) Open(ctx context.Context, req *pb.OpenRequest) (*pb.OpenResponse, error) {
+	res, err := s.open(ctx, req)
+	if err == nil {
+		s.inline(ctx, req.OpenIn, res)
+	}
+	return res, err
+}
+
+func (s *server) open(ctx context.Context, req *pb.OpenRequest) (*pb.OpenResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	var (
 		header fuse.InHeader
 		out    fuse.OpenOut
@@ -89,6 +103,9 @@ func (s *server) Open(ctx context.Context, req *pb.OpenRequest) (*pb.OpenRespons
 }
 
 func (s *server) Read(req *pb.ReadRequest, stream pb.RawFileSystem_ReadServer) error {
+	if err := validate(req); err != nil {
+		return err
+	}
 	var (
 		header fuse.InHeader
 		pos    int
@@ -146,20 +163,25 @@ func (s *server) Read(req *pb.ReadRequest, stream pb.RawFileSystem_ReadServer) e
 		return nil
 	}
 
+	// res.Size() is what was asked for with ReadResultFd, so split what
+	// was actually read.
 	for {
-		if pos+s.msgSizeThreshold >= res.Size() {
+		if pos+s.msgSizeThreshold >= len(data) {
 			batch = data[pos:]
-			flushFunc()
-			break
+			return flushFunc()
 		}
 
 		batch = data[pos : pos+s.msgSizeThreshold]
 		pos += s.msgSizeThreshold
-		flushFunc()
+		if err := flushFunc(); err != nil {
+			return err
+		}
 	}
-	return nil
 }
 func (s *server) Lseek(ctx context.Context, req *pb.LseekRequest) (*pb.LseekResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	var (
 		header fuse.InHeader
 		out    fuse.LseekOut
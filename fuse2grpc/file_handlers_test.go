@@ -15,22 +15,22 @@ import (
 	"github.com/chiyutianyi/grpcfuse/pb"
 )
 
-type mockFS struct {
+type fileHandlersFS struct {
 	mock.Mock
 	fuse.RawFileSystem
 }
 
-func (m *mockFS) Create(cancel <-chan struct{}, input *fuse.CreateIn, name string, out *fuse.CreateOut) fuse.Status {
+func (m *fileHandlersFS) Create(cancel <-chan struct{}, input *fuse.CreateIn, name string, out *fuse.CreateOut) fuse.Status {
 	args := m.Called(cancel, input, name, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *mockFS) Open(cancel <-chan struct{}, input *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
+func (m *fileHandlersFS) Open(cancel <-chan struct{}, input *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
 	args := m.Called(cancel, input, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *mockFS) Read(cancel <-chan struct{}, input *fuse.ReadIn, buf []byte) (fuse.ReadResult, fuse.Status) {
+func (m *fileHandlersFS) Read(cancel <-chan struct{}, input *fuse.ReadIn, buf []byte) (fuse.ReadResult, fuse.Status) {
 	args := m.Called(cancel, input, buf)
 	if args.Get(0) == nil {
 		return nil, args.Get(1).(fuse.Status)
@@ -38,7 +38,7 @@ func (m *mockFS) Read(cancel <-chan struct{}, input *fuse.ReadIn, buf []byte) (f
 	return args.Get(0).(fuse.ReadResult), args.Get(1).(fuse.Status)
 }
 
-func (m *mockFS) Lseek(cancel <-chan struct{}, in *fuse.LseekIn, out *fuse.LseekOut) fuse.Status {
+func (m *fileHandlersFS) Lseek(cancel <-chan struct{}, in *fuse.LseekIn, out *fuse.LseekOut) fuse.Status {
 	args := m.Called(cancel, in, out)
 	return args.Get(0).(fuse.Status)
 }
@@ -83,7 +83,7 @@ func (m *mockReadServer) SendMsg(msg interface{}) error {
 }
 
 func TestCreate(t *testing.T) {
-	mockfs := &mockFS{}
+	mockfs := &fileHandlersFS{}
 	server := fuse2grpc.NewServer(mockfs)
 
 	tests := []struct {
@@ -175,7 +175,7 @@ func TestCreate(t *testing.T) {
 }
 
 func TestOpen(t *testing.T) {
-	mockfs := &mockFS{}
+	mockfs := &fileHandlersFS{}
 	server := fuse2grpc.NewServer(mockfs)
 
 	tests := []struct {
@@ -269,7 +269,7 @@ func TestOpen(t *testing.T) {
 }
 
 func TestRead(t *testing.T) {
-	mockfs := &mockFS{}
+	mockfs := &fileHandlersFS{}
 	server := fuse2grpc.NewServer(mockfs)
 
 	tests := []struct {
@@ -366,7 +366,7 @@ func TestRead(t *testing.T) {
 }
 
 func TestLseek(t *testing.T) {
-	mockfs := &mockFS{}
+	mockfs := &fileHandlersFS{}
 	server := fuse2grpc.NewServer(mockfs)
 
 	tests := []struct {
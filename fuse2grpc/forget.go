@@ -27,6 +27,9 @@ import (
 )
 
 func (s *server) Forget(ctx context.Context, req *pb.ForgetRequest) (*emptypb.Empty, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	grpc_logrus.Extract(ctx).WithFields(log.Fields{
 		"nodeid":  req.Nodeid,
 		"nlookup": req.Nlookup,
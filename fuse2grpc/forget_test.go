@@ -60,10 +60,8 @@ func TestForget(t *testing.T) {
 				Nodeid:  0,
 				Nlookup: 1,
 			},
-			setup: func() {
-				mockFS.On("Forget", uint64(0), uint64(1)).Return()
-			},
-			wantErr: false,
+			setup:   func() {},
+			wantErr: true,
 		},
 		{
 			name: "forget with multiple lookups",
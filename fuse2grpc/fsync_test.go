@@ -14,121 +14,121 @@ import (
 	"github.com/chiyutianyi/grpcfuse/pb"
 )
 
-type mockFS struct {
+type fsyncFS struct {
 	mock.Mock
 }
 
-func (m *mockFS) Init(server *fuse.Server) {}
+func (m *fsyncFS) Init(server *fuse.Server) {}
 
-func (m *mockFS) Fsync(cancel <-chan struct{}, in *fuse.FsyncIn) fuse.Status {
+func (m *fsyncFS) Fsync(cancel <-chan struct{}, in *fuse.FsyncIn) fuse.Status {
 	args := m.Called(cancel, in)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *mockFS) String() string                                    { return "mockFS" }
-func (m *mockFS) SetDebug(debug bool)                              {}
-func (m *mockFS) Lookup(cancel <-chan struct{}, header *fuse.InHeader, name string, out *fuse.EntryOut) fuse.Status {
+func (m *fsyncFS) String() string                                    { return "fsyncFS" }
+func (m *fsyncFS) SetDebug(debug bool)                              {}
+func (m *fsyncFS) Lookup(cancel <-chan struct{}, header *fuse.InHeader, name string, out *fuse.EntryOut) fuse.Status {
 	return fuse.OK
 }
-func (m *mockFS) Forget(nodeID uint64, nlookup uint64)            {}
-func (m *mockFS) GetAttr(cancel <-chan struct{}, input *fuse.GetAttrIn, out *fuse.AttrOut) fuse.Status {
+func (m *fsyncFS) Forget(nodeID uint64, nlookup uint64)            {}
+func (m *fsyncFS) GetAttr(cancel <-chan struct{}, input *fuse.GetAttrIn, out *fuse.AttrOut) fuse.Status {
 	return fuse.OK
 }
-func (m *mockFS) SetAttr(cancel <-chan struct{}, input *fuse.SetAttrIn, out *fuse.AttrOut) fuse.Status {
+func (m *fsyncFS) SetAttr(cancel <-chan struct{}, input *fuse.SetAttrIn, out *fuse.AttrOut) fuse.Status {
 	return fuse.OK
 }
-func (m *mockFS) Mknod(cancel <-chan struct{}, input *fuse.MknodIn, name string, out *fuse.EntryOut) fuse.Status {
+func (m *fsyncFS) Mknod(cancel <-chan struct{}, input *fuse.MknodIn, name string, out *fuse.EntryOut) fuse.Status {
 	return fuse.OK
 }
-func (m *mockFS) Mkdir(cancel <-chan struct{}, input *fuse.MkdirIn, name string, out *fuse.EntryOut) fuse.Status {
+func (m *fsyncFS) Mkdir(cancel <-chan struct{}, input *fuse.MkdirIn, name string, out *fuse.EntryOut) fuse.Status {
 	return fuse.OK
 }
-func (m *mockFS) Unlink(cancel <-chan struct{}, header *fuse.InHeader, name string) fuse.Status {
+func (m *fsyncFS) Unlink(cancel <-chan struct{}, header *fuse.InHeader, name string) fuse.Status {
 	return fuse.OK
 }
-func (m *mockFS) Rmdir(cancel <-chan struct{}, header *fuse.InHeader, name string) fuse.Status {
+func (m *fsyncFS) Rmdir(cancel <-chan struct{}, header *fuse.InHeader, name string) fuse.Status {
 	return fuse.OK
 }
-func (m *mockFS) Rename(cancel <-chan struct{}, input *fuse.RenameIn, oldName string, newName string) fuse.Status {
+func (m *fsyncFS) Rename(cancel <-chan struct{}, input *fuse.RenameIn, oldName string, newName string) fuse.Status {
 	return fuse.OK
 }
-func (m *mockFS) Link(cancel <-chan struct{}, input *fuse.LinkIn, name string, out *fuse.EntryOut) fuse.Status {
+func (m *fsyncFS) Link(cancel <-chan struct{}, input *fuse.LinkIn, name string, out *fuse.EntryOut) fuse.Status {
 	return fuse.OK
 }
-func (m *mockFS) Symlink(cancel <-chan struct{}, header *fuse.InHeader, pointedTo string, linkName string, out *fuse.EntryOut) fuse.Status {
+func (m *fsyncFS) Symlink(cancel <-chan struct{}, header *fuse.InHeader, pointedTo string, linkName string, out *fuse.EntryOut) fuse.Status {
 	return fuse.OK
 }
-func (m *mockFS) Readlink(cancel <-chan struct{}, header *fuse.InHeader) ([]byte, fuse.Status) {
+func (m *fsyncFS) Readlink(cancel <-chan struct{}, header *fuse.InHeader) ([]byte, fuse.Status) {
 	return nil, fuse.OK
 }
-func (m *mockFS) Access(cancel <-chan struct{}, input *fuse.AccessIn) fuse.Status {
+func (m *fsyncFS) Access(cancel <-chan struct{}, input *fuse.AccessIn) fuse.Status {
 	return fuse.OK
 }
-func (m *mockFS) GetXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string, dest []byte) (uint32, fuse.Status) {
+func (m *fsyncFS) GetXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string, dest []byte) (uint32, fuse.Status) {
 	return 0, fuse.OK
 }
-func (m *mockFS) ListXAttr(cancel <-chan struct{}, header *fuse.InHeader, dest []byte) (uint32, fuse.Status) {
+func (m *fsyncFS) ListXAttr(cancel <-chan struct{}, header *fuse.InHeader, dest []byte) (uint32, fuse.Status) {
 	return 0, fuse.OK
 }
-func (m *mockFS) SetXAttr(cancel <-chan struct{}, input *fuse.SetXAttrIn, attr string, data []byte) fuse.Status {
+func (m *fsyncFS) SetXAttr(cancel <-chan struct{}, input *fuse.SetXAttrIn, attr string, data []byte) fuse.Status {
 	return fuse.OK
 }
-func (m *mockFS) RemoveXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string) fuse.Status {
+func (m *fsyncFS) RemoveXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string) fuse.Status {
 	return fuse.OK
 }
-func (m *mockFS) Create(cancel <-chan struct{}, input *fuse.CreateIn, name string, out *fuse.CreateOut) fuse.Status {
+func (m *fsyncFS) Create(cancel <-chan struct{}, input *fuse.CreateIn, name string, out *fuse.CreateOut) fuse.Status {
 	return fuse.OK
 }
-func (m *mockFS) Open(cancel <-chan struct{}, input *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
+func (m *fsyncFS) Open(cancel <-chan struct{}, input *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
 	return fuse.OK
 }
-func (m *mockFS) Read(cancel <-chan struct{}, input *fuse.ReadIn, buf []byte) (fuse.ReadResult, fuse.Status) {
+func (m *fsyncFS) Read(cancel <-chan struct{}, input *fuse.ReadIn, buf []byte) (fuse.ReadResult, fuse.Status) {
 	return nil, fuse.OK
 }
-func (m *mockFS) Lseek(cancel <-chan struct{}, in *fuse.LseekIn, out *fuse.LseekOut) fuse.Status {
+func (m *fsyncFS) Lseek(cancel <-chan struct{}, in *fuse.LseekIn, out *fuse.LseekOut) fuse.Status {
 	return fuse.OK
 }
-func (m *mockFS) GetLk(cancel <-chan struct{}, input *fuse.LkIn, out *fuse.LkOut) fuse.Status {
+func (m *fsyncFS) GetLk(cancel <-chan struct{}, input *fuse.LkIn, out *fuse.LkOut) fuse.Status {
 	return fuse.OK
 }
-func (m *mockFS) SetLk(cancel <-chan struct{}, input *fuse.LkIn) fuse.Status {
+func (m *fsyncFS) SetLk(cancel <-chan struct{}, input *fuse.LkIn) fuse.Status {
 	return fuse.OK
 }
-func (m *mockFS) SetLkw(cancel <-chan struct{}, input *fuse.LkIn) fuse.Status {
+func (m *fsyncFS) SetLkw(cancel <-chan struct{}, input *fuse.LkIn) fuse.Status {
 	return fuse.OK
 }
-func (m *mockFS) Release(cancel <-chan struct{}, input *fuse.ReleaseIn)     {}
-func (m *mockFS) Write(cancel <-chan struct{}, input *fuse.WriteIn, data []byte) (uint32, fuse.Status) {
+func (m *fsyncFS) Release(cancel <-chan struct{}, input *fuse.ReleaseIn)     {}
+func (m *fsyncFS) Write(cancel <-chan struct{}, input *fuse.WriteIn, data []byte) (uint32, fuse.Status) {
 	return 0, fuse.OK
 }
-func (m *mockFS) CopyFileRange(cancel <-chan struct{}, input *fuse.CopyFileRangeIn) (uint32, fuse.Status) {
+func (m *fsyncFS) CopyFileRange(cancel <-chan struct{}, input *fuse.CopyFileRangeIn) (uint32, fuse.Status) {
 	return 0, fuse.OK
 }
-func (m *mockFS) Flush(cancel <-chan struct{}, input *fuse.FlushIn) fuse.Status {
+func (m *fsyncFS) Flush(cancel <-chan struct{}, input *fuse.FlushIn) fuse.Status {
 	return fuse.OK
 }
-func (m *mockFS) Fallocate(cancel <-chan struct{}, input *fuse.FallocateIn) fuse.Status {
+func (m *fsyncFS) Fallocate(cancel <-chan struct{}, input *fuse.FallocateIn) fuse.Status {
 	return fuse.OK
 }
-func (m *mockFS) OpenDir(cancel <-chan struct{}, input *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
+func (m *fsyncFS) OpenDir(cancel <-chan struct{}, input *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
 	return fuse.OK
 }
-func (m *mockFS) ReadDir(cancel <-chan struct{}, input *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
+func (m *fsyncFS) ReadDir(cancel <-chan struct{}, input *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
 	return fuse.OK
 }
-func (m *mockFS) ReadDirPlus(cancel <-chan struct{}, input *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
+func (m *fsyncFS) ReadDirPlus(cancel <-chan struct{}, input *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
 	return fuse.OK
 }
-func (m *mockFS) ReleaseDir(input *fuse.ReleaseIn) {}
-func (m *mockFS) FsyncDir(cancel <-chan struct{}, input *fuse.FsyncIn) fuse.Status {
+func (m *fsyncFS) ReleaseDir(input *fuse.ReleaseIn) {}
+func (m *fsyncFS) FsyncDir(cancel <-chan struct{}, input *fuse.FsyncIn) fuse.Status {
 	return fuse.OK
 }
-func (m *mockFS) StatFs(cancel <-chan struct{}, input *fuse.InHeader, out *fuse.StatfsOut) fuse.Status {
+func (m *fsyncFS) StatFs(cancel <-chan struct{}, input *fuse.InHeader, out *fuse.StatfsOut) fuse.Status {
 	return fuse.OK
 }
 
 func TestFsync(t *testing.T) {
-	mockfs := &mockFS{}
+	mockfs := &fsyncFS{}
 	server := fuse2grpc.NewServer(mockfs)
 
 	tests := []struct {
@@ -1,12 +1,12 @@
-package fuse2grpc_test
+package fuse2grpc
 
 import (
 	"testing"
 
-	"github.com/chiyutianyi/grpcfuse/fuse2grpc"
-	"github.com/chiyutianyi/grpcfuse/pb"
 	"github.com/hanwen/go-fuse/v2/fuse"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/chiyutianyi/grpcfuse/pb"
 )
 
 func TestToPbAttr(t *testing.T) {
@@ -36,8 +36,7 @@ func TestToPbAttr(t *testing.T) {
 				Ctimensec: 300,
 				Mode:      0755,
 				Nlink:     2,
-				Uid:       1001,
-				Gid:       1002,
+				Owner:     fuse.Owner{Uid: 1001, Gid: 1002},
 				Rdev:      5,
 				Flags_:    15,
 			},
@@ -65,7 +64,7 @@ func TestToPbAttr(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := fuse2grpc.ToPbAttr(tt.in)
+			got := toPbAttr(tt.in)
 			assert.Equal(t, tt.want, got)
 		})
 	}
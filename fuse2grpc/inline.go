@@ -0,0 +1,63 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fuse2grpc
+
+import (
+	"context"
+	"syscall"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+// SetInlineSize makes read-only opens of files of at most size bytes, up
+// to 1MiB, return the file, so that clients of small files need not read
+// them. A size of 0 turns it off.
+func (s *server) SetInlineSize(size int) {
+	if size > msgSizeThreshold {
+		size = msgSizeThreshold
+	}
+	s.inlineSize = size
+}
+
+// inline reads the file just opened into res if it is small. A failed
+// read is left to the reads of the client.
+func (s *server) inline(ctx context.Context, in *pb.OpenIn, res *pb.OpenResponse) {
+	if s.inlineSize <= 0 || res.GetStatus().GetCode() != 0 || in.Flags&syscall.O_ACCMODE != syscall.O_RDONLY || in.Flags&syscall.O_TRUNC != 0 {
+		return
+	}
+	var (
+		header fuse.InHeader
+		attr   fuse.AttrOut
+	)
+	toFuseInHeader(in.Header, &header)
+	if st := s.fs.GetAttr(ctx.Done(), &fuse.GetAttrIn{InHeader: header}, &attr); st != fuse.OK || attr.Size > uint64(s.inlineSize) {
+		return
+	}
+	// One byte more tells a file that grew since.
+	read, err := s.readAll(ctx, &pb.ReadRequest{ReadIn: &pb.ReadIn{
+		Header: in.Header,
+		Fh:     res.OpenOut.Fh,
+		Size:   uint32(attr.Size) + 1,
+	}})
+	if err != nil || read.GetStatus().GetCode() != 0 || len(read.Buffer) > int(attr.Size) {
+		return
+	}
+	res.InlineData = read.Buffer
+	res.InlineEof = true
+}
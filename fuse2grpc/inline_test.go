@@ -0,0 +1,69 @@
+package fuse2grpc
+
+import (
+	"context"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chiyutianyi/grpcfuse/memfs"
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+func TestInline(t *testing.T) {
+	s := NewServer(memfs.New(nil, nil))
+	ctx := context.Background()
+	header := func(node uint64) *pb.InHeader {
+		return &pb.InHeader{NodeId: node, Caller: validHeader.Caller}
+	}
+	write := func(name, data string) uint64 {
+		res, err := s.Compound(ctx, &pb.CompoundRequest{Ops: []*pb.CompoundOp{
+			{Op: &pb.CompoundOp_Create{Create: &pb.CreateRequest{Header: header(1), Name: name, Flags: uint32(syscall.O_WRONLY), Mode: 0644}}},
+			{Op: &pb.CompoundOp_Write{Write: &pb.WriteRequest{Header: header(1), Data: []byte(data), Size: uint32(len(data))}}, NodeFrom: 1, FhFrom: 1},
+			{Op: &pb.CompoundOp_Release{Release: &pb.ReleaseRequest{Header: header(1)}}, NodeFrom: 1, FhFrom: 1},
+		}})
+		require.NoError(t, err)
+		require.Zero(t, res.Status.Code)
+		return res.Results[0].GetCreate().EntryOut.NodeId
+	}
+	open := func(node uint64, flags int) *pb.OpenResponse {
+		res, err := s.Open(ctx, &pb.OpenRequest{OpenIn: &pb.OpenIn{Header: header(node), Flags: uint32(flags)}})
+		require.NoError(t, err)
+		require.Zero(t, res.Status.Code)
+		return res
+	}
+	small := write("small", "hello")
+	large := write("large", strings.Repeat("x", 100))
+
+	// Off by default.
+	res := open(small, syscall.O_RDONLY)
+	assert.Empty(t, res.InlineData)
+	assert.False(t, res.InlineEof)
+
+	s.SetInlineSize(10)
+	res = open(small, syscall.O_RDONLY)
+	assert.Equal(t, "hello", string(res.InlineData))
+	assert.True(t, res.InlineEof)
+	// Larger files are left to the reads of the client.
+	res = open(large, syscall.O_RDONLY)
+	assert.Empty(t, res.InlineData)
+	assert.False(t, res.InlineEof)
+	res = open(write("empty", ""), syscall.O_RDONLY)
+	assert.Empty(t, res.InlineData)
+	assert.True(t, res.InlineEof)
+
+	// Only for read-only opens, and not for those of a Compound.
+	res = open(small, syscall.O_RDWR)
+	assert.Empty(t, res.InlineData)
+	compound, err := s.Compound(ctx, &pb.CompoundRequest{Ops: []*pb.CompoundOp{
+		{Op: &pb.CompoundOp_Open{Open: &pb.OpenRequest{OpenIn: &pb.OpenIn{Header: header(small)}}}},
+	}})
+	require.NoError(t, err)
+	assert.Empty(t, compound.Results[0].GetOpen().InlineData)
+
+	s.SetInlineSize(1 << 30)
+	assert.Equal(t, msgSizeThreshold, s.inlineSize)
+}
@@ -29,6 +29,9 @@ import (
 )
 
 func (s *server) Link(ctx context.Context, req *pb.LinkRequest) (*pb.LinkResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	var (
 		header fuse.InHeader
 		out    fuse.EntryOut
@@ -48,6 +51,9 @@ func (s *server) Link(ctx context.Context, req *pb.LinkRequest) (*pb.LinkRespons
 }
 
 func (s *server) Symlink(ctx context.Context, req *pb.SymlinkRequest) (*pb.SymlinkResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	var (
 		header fuse.InHeader
 		out    fuse.EntryOut
@@ -67,6 +73,9 @@ func (s *server) Symlink(ctx context.Context, req *pb.SymlinkRequest) (*pb.Symli
 }
 
 func (s *server) Readlink(ctx context.Context, req *pb.ReadlinkRequest) (*pb.ReadlinkResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	var (
 		header fuse.InHeader
 	)
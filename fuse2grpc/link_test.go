@@ -14,161 +14,161 @@ import (
 	"github.com/chiyutianyi/grpcfuse/pb"
 )
 
-type mockFS struct {
+type linkFS struct {
 	mock.Mock
 }
 
-func (m *mockFS) String() string {
-	return "mockFS"
+func (m *linkFS) String() string {
+	return "linkFS"
 }
 
-func (m *mockFS) SetDebug(debug bool) {}
+func (m *linkFS) SetDebug(debug bool) {}
 
-func (m *mockFS) Init(*fuse.Server) {}
+func (m *linkFS) Init(*fuse.Server) {}
 
-func (m *mockFS) StatFs(cancel <-chan struct{}, in *fuse.InHeader, out *fuse.StatfsOut) fuse.Status {
+func (m *linkFS) StatFs(cancel <-chan struct{}, in *fuse.InHeader, out *fuse.StatfsOut) fuse.Status {
 	return fuse.OK
 }
 
-func (m *mockFS) Lookup(cancel <-chan struct{}, header *fuse.InHeader, name string, out *fuse.EntryOut) fuse.Status {
+func (m *linkFS) Lookup(cancel <-chan struct{}, header *fuse.InHeader, name string, out *fuse.EntryOut) fuse.Status {
 	return fuse.OK
 }
 
-func (m *mockFS) Forget(nodeID uint64, nlookup uint64) {}
+func (m *linkFS) Forget(nodeID uint64, nlookup uint64) {}
 
-func (m *mockFS) GetAttr(cancel <-chan struct{}, input *fuse.GetAttrIn, out *fuse.AttrOut) fuse.Status {
+func (m *linkFS) GetAttr(cancel <-chan struct{}, input *fuse.GetAttrIn, out *fuse.AttrOut) fuse.Status {
 	return fuse.OK
 }
 
-func (m *mockFS) SetAttr(cancel <-chan struct{}, input *fuse.SetAttrIn, out *fuse.AttrOut) fuse.Status {
+func (m *linkFS) SetAttr(cancel <-chan struct{}, input *fuse.SetAttrIn, out *fuse.AttrOut) fuse.Status {
 	return fuse.OK
 }
 
-func (m *mockFS) Mknod(cancel <-chan struct{}, input *fuse.MknodIn, name string, out *fuse.EntryOut) fuse.Status {
+func (m *linkFS) Mknod(cancel <-chan struct{}, input *fuse.MknodIn, name string, out *fuse.EntryOut) fuse.Status {
 	return fuse.OK
 }
 
-func (m *mockFS) Mkdir(cancel <-chan struct{}, input *fuse.MkdirIn, name string, out *fuse.EntryOut) fuse.Status {
+func (m *linkFS) Mkdir(cancel <-chan struct{}, input *fuse.MkdirIn, name string, out *fuse.EntryOut) fuse.Status {
 	return fuse.OK
 }
 
-func (m *mockFS) Unlink(cancel <-chan struct{}, header *fuse.InHeader, name string) fuse.Status {
+func (m *linkFS) Unlink(cancel <-chan struct{}, header *fuse.InHeader, name string) fuse.Status {
 	return fuse.OK
 }
 
-func (m *mockFS) Rmdir(cancel <-chan struct{}, header *fuse.InHeader, name string) fuse.Status {
+func (m *linkFS) Rmdir(cancel <-chan struct{}, header *fuse.InHeader, name string) fuse.Status {
 	return fuse.OK
 }
 
-func (m *mockFS) Rename(cancel <-chan struct{}, input *fuse.RenameIn, oldName string, newName string) fuse.Status {
+func (m *linkFS) Rename(cancel <-chan struct{}, input *fuse.RenameIn, oldName string, newName string) fuse.Status {
 	return fuse.OK
 }
 
-func (m *mockFS) Link(cancel <-chan struct{}, input *fuse.LinkIn, name string, out *fuse.EntryOut) fuse.Status {
+func (m *linkFS) Link(cancel <-chan struct{}, input *fuse.LinkIn, name string, out *fuse.EntryOut) fuse.Status {
 	args := m.Called(cancel, input, name, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *mockFS) Symlink(cancel <-chan struct{}, header *fuse.InHeader, pointedTo string, linkName string, out *fuse.EntryOut) fuse.Status {
+func (m *linkFS) Symlink(cancel <-chan struct{}, header *fuse.InHeader, pointedTo string, linkName string, out *fuse.EntryOut) fuse.Status {
 	args := m.Called(cancel, header, pointedTo, linkName, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *mockFS) Readlink(cancel <-chan struct{}, header *fuse.InHeader) ([]byte, fuse.Status) {
+func (m *linkFS) Readlink(cancel <-chan struct{}, header *fuse.InHeader) ([]byte, fuse.Status) {
 	args := m.Called(cancel, header)
 	return args.Get(0).([]byte), args.Get(1).(fuse.Status)
 }
 
-func (m *mockFS) Access(cancel <-chan struct{}, input *fuse.AccessIn) fuse.Status {
+func (m *linkFS) Access(cancel <-chan struct{}, input *fuse.AccessIn) fuse.Status {
 	return fuse.OK
 }
 
-func (m *mockFS) GetXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string, dest []byte) (uint32, fuse.Status) {
+func (m *linkFS) GetXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string, dest []byte) (uint32, fuse.Status) {
 	return 0, fuse.OK
 }
 
-func (m *mockFS) ListXAttr(cancel <-chan struct{}, header *fuse.InHeader, dest []byte) (uint32, fuse.Status) {
+func (m *linkFS) ListXAttr(cancel <-chan struct{}, header *fuse.InHeader, dest []byte) (uint32, fuse.Status) {
 	return 0, fuse.OK
 }
 
-func (m *mockFS) SetXAttr(cancel <-chan struct{}, input *fuse.SetXAttrIn, attr string, data []byte) fuse.Status {
+func (m *linkFS) SetXAttr(cancel <-chan struct{}, input *fuse.SetXAttrIn, attr string, data []byte) fuse.Status {
 	return fuse.OK
 }
 
-func (m *mockFS) RemoveXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string) fuse.Status {
+func (m *linkFS) RemoveXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string) fuse.Status {
 	return fuse.OK
 }
 
-func (m *mockFS) Create(cancel <-chan struct{}, input *fuse.CreateIn, name string, out *fuse.CreateOut) fuse.Status {
+func (m *linkFS) Create(cancel <-chan struct{}, input *fuse.CreateIn, name string, out *fuse.CreateOut) fuse.Status {
 	return fuse.OK
 }
 
-func (m *mockFS) Open(cancel <-chan struct{}, input *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
+func (m *linkFS) Open(cancel <-chan struct{}, input *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
 	return fuse.OK
 }
 
-func (m *mockFS) Read(cancel <-chan struct{}, input *fuse.ReadIn, buf []byte) (fuse.ReadResult, fuse.Status) {
+func (m *linkFS) Read(cancel <-chan struct{}, input *fuse.ReadIn, buf []byte) (fuse.ReadResult, fuse.Status) {
 	return nil, fuse.OK
 }
 
-func (m *mockFS) Lseek(cancel <-chan struct{}, in *fuse.LseekIn, out *fuse.LseekOut) fuse.Status {
+func (m *linkFS) Lseek(cancel <-chan struct{}, in *fuse.LseekIn, out *fuse.LseekOut) fuse.Status {
 	return fuse.OK
 }
 
-func (m *mockFS) GetLk(cancel <-chan struct{}, input *fuse.LkIn, out *fuse.LkOut) fuse.Status {
+func (m *linkFS) GetLk(cancel <-chan struct{}, input *fuse.LkIn, out *fuse.LkOut) fuse.Status {
 	return fuse.OK
 }
 
-func (m *mockFS) SetLk(cancel <-chan struct{}, input *fuse.LkIn) fuse.Status {
+func (m *linkFS) SetLk(cancel <-chan struct{}, input *fuse.LkIn) fuse.Status {
 	return fuse.OK
 }
 
-func (m *mockFS) SetLkw(cancel <-chan struct{}, input *fuse.LkIn) fuse.Status {
+func (m *linkFS) SetLkw(cancel <-chan struct{}, input *fuse.LkIn) fuse.Status {
 	return fuse.OK
 }
 
-func (m *mockFS) Release(cancel <-chan struct{}, input *fuse.ReleaseIn) {}
+func (m *linkFS) Release(cancel <-chan struct{}, input *fuse.ReleaseIn) {}
 
-func (m *mockFS) Write(cancel <-chan struct{}, input *fuse.WriteIn, data []byte) (uint32, fuse.Status) {
+func (m *linkFS) Write(cancel <-chan struct{}, input *fuse.WriteIn, data []byte) (uint32, fuse.Status) {
 	return 0, fuse.OK
 }
 
-func (m *mockFS) CopyFileRange(cancel <-chan struct{}, input *fuse.CopyFileRangeIn) (uint32, fuse.Status) {
+func (m *linkFS) CopyFileRange(cancel <-chan struct{}, input *fuse.CopyFileRangeIn) (uint32, fuse.Status) {
 	return 0, fuse.OK
 }
 
-func (m *mockFS) Flush(cancel <-chan struct{}, input *fuse.FlushIn) fuse.Status {
+func (m *linkFS) Flush(cancel <-chan struct{}, input *fuse.FlushIn) fuse.Status {
 	return fuse.OK
 }
 
-func (m *mockFS) Fsync(cancel <-chan struct{}, input *fuse.FsyncIn) fuse.Status {
+func (m *linkFS) Fsync(cancel <-chan struct{}, input *fuse.FsyncIn) fuse.Status {
 	return fuse.OK
 }
 
-func (m *mockFS) Fallocate(cancel <-chan struct{}, input *fuse.FallocateIn) fuse.Status {
+func (m *linkFS) Fallocate(cancel <-chan struct{}, input *fuse.FallocateIn) fuse.Status {
 	return fuse.OK
 }
 
-func (m *mockFS) OpenDir(cancel <-chan struct{}, input *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
+func (m *linkFS) OpenDir(cancel <-chan struct{}, input *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
 	return fuse.OK
 }
 
-func (m *mockFS) ReadDir(cancel <-chan struct{}, input *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
+func (m *linkFS) ReadDir(cancel <-chan struct{}, input *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
 	return fuse.OK
 }
 
-func (m *mockFS) ReadDirPlus(cancel <-chan struct{}, input *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
+func (m *linkFS) ReadDirPlus(cancel <-chan struct{}, input *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
 	return fuse.OK
 }
 
-func (m *mockFS) ReleaseDir(input *fuse.ReleaseIn) {}
+func (m *linkFS) ReleaseDir(input *fuse.ReleaseIn) {}
 
-func (m *mockFS) FsyncDir(cancel <-chan struct{}, input *fuse.FsyncIn) fuse.Status {
+func (m *linkFS) FsyncDir(cancel <-chan struct{}, input *fuse.FsyncIn) fuse.Status {
 	return fuse.OK
 }
 
 func TestLink(t *testing.T) {
-	mockfs := &mockFS{}
+	mockfs := &linkFS{}
 	server := fuse2grpc.NewServer(mockfs)
 
 	tests := []struct {
@@ -245,7 +245,7 @@ func TestLink(t *testing.T) {
 }
 
 func TestSymlink(t *testing.T) {
-	mockfs := &mockFS{}
+	mockfs := &linkFS{}
 	server := fuse2grpc.NewServer(mockfs)
 
 	tests := []struct {
@@ -322,7 +322,7 @@ func TestSymlink(t *testing.T) {
 }
 
 func TestReadlink(t *testing.T) {
-	mockfs := &mockFS{}
+	mockfs := &linkFS{}
 	server := fuse2grpc.NewServer(mockfs)
 
 	tests := []struct {
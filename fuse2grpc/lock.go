@@ -30,6 +30,9 @@ import (
 )
 
 func (s *server) GetLk(ctx context.Context, req *pb.LkRequest) (*pb.GetLkResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	var (
 		header fuse.InHeader
 		out    fuse.LkOut
@@ -80,6 +83,9 @@ func (s *server) doSetLk(
 	req *pb.LkRequest,
 	fn func(<-chan struct{}, *fuse.LkIn) fuse.Status,
 	funcName string) (*pb.SetLkResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	var (
 		header fuse.InHeader
 	)
@@ -14,132 +14,132 @@ import (
 	"github.com/chiyutianyi/grpcfuse/pb"
 )
 
-type MockRawFileSystem struct {
+type lockFS struct {
 	mock.Mock
 }
 
-func (m *MockRawFileSystem) Init(*fuse.Server) {
+func (m *lockFS) Init(*fuse.Server) {
 	m.Called()
 }
 
-func (m *MockRawFileSystem) String() string {
-	return "MockRawFileSystem"
+func (m *lockFS) String() string {
+	return "lockFS"
 }
 
-func (m *MockRawFileSystem) SetDebug(debug bool) {
+func (m *lockFS) SetDebug(debug bool) {
 	m.Called(debug)
 }
 
-func (m *MockRawFileSystem) StatFs(cancel <-chan struct{}, in *fuse.InHeader, out *fuse.StatfsOut) fuse.Status {
+func (m *lockFS) StatFs(cancel <-chan struct{}, in *fuse.InHeader, out *fuse.StatfsOut) fuse.Status {
 	args := m.Called(cancel, in, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Lookup(cancel <-chan struct{}, header *fuse.InHeader, name string, out *fuse.EntryOut) fuse.Status {
+func (m *lockFS) Lookup(cancel <-chan struct{}, header *fuse.InHeader, name string, out *fuse.EntryOut) fuse.Status {
 	args := m.Called(cancel, header, name, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Forget(nodeID uint64, nlookup uint64) {
+func (m *lockFS) Forget(nodeID uint64, nlookup uint64) {
 	m.Called(nodeID, nlookup)
 }
 
-func (m *MockRawFileSystem) GetAttr(cancel <-chan struct{}, input *fuse.GetAttrIn, out *fuse.AttrOut) fuse.Status {
+func (m *lockFS) GetAttr(cancel <-chan struct{}, input *fuse.GetAttrIn, out *fuse.AttrOut) fuse.Status {
 	args := m.Called(cancel, input, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) SetAttr(cancel <-chan struct{}, input *fuse.SetAttrIn, out *fuse.AttrOut) fuse.Status {
+func (m *lockFS) SetAttr(cancel <-chan struct{}, input *fuse.SetAttrIn, out *fuse.AttrOut) fuse.Status {
 	args := m.Called(cancel, input, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Mknod(cancel <-chan struct{}, input *fuse.MknodIn, name string, out *fuse.EntryOut) fuse.Status {
+func (m *lockFS) Mknod(cancel <-chan struct{}, input *fuse.MknodIn, name string, out *fuse.EntryOut) fuse.Status {
 	args := m.Called(cancel, input, name, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Mkdir(cancel <-chan struct{}, input *fuse.MkdirIn, name string, out *fuse.EntryOut) fuse.Status {
+func (m *lockFS) Mkdir(cancel <-chan struct{}, input *fuse.MkdirIn, name string, out *fuse.EntryOut) fuse.Status {
 	args := m.Called(cancel, input, name, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Unlink(cancel <-chan struct{}, header *fuse.InHeader, name string) fuse.Status {
+func (m *lockFS) Unlink(cancel <-chan struct{}, header *fuse.InHeader, name string) fuse.Status {
 	args := m.Called(cancel, header, name)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Rmdir(cancel <-chan struct{}, header *fuse.InHeader, name string) fuse.Status {
+func (m *lockFS) Rmdir(cancel <-chan struct{}, header *fuse.InHeader, name string) fuse.Status {
 	args := m.Called(cancel, header, name)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Rename(cancel <-chan struct{}, input *fuse.RenameIn, oldName string, newName string) fuse.Status {
+func (m *lockFS) Rename(cancel <-chan struct{}, input *fuse.RenameIn, oldName string, newName string) fuse.Status {
 	args := m.Called(cancel, input, oldName, newName)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Link(cancel <-chan struct{}, input *fuse.LinkIn, name string, out *fuse.EntryOut) fuse.Status {
+func (m *lockFS) Link(cancel <-chan struct{}, input *fuse.LinkIn, name string, out *fuse.EntryOut) fuse.Status {
 	args := m.Called(cancel, input, name, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Symlink(cancel <-chan struct{}, header *fuse.InHeader, pointedTo string, linkName string, out *fuse.EntryOut) fuse.Status {
+func (m *lockFS) Symlink(cancel <-chan struct{}, header *fuse.InHeader, pointedTo string, linkName string, out *fuse.EntryOut) fuse.Status {
 	args := m.Called(cancel, header, pointedTo, linkName, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Readlink(cancel <-chan struct{}, header *fuse.InHeader) ([]byte, fuse.Status) {
+func (m *lockFS) Readlink(cancel <-chan struct{}, header *fuse.InHeader) ([]byte, fuse.Status) {
 	args := m.Called(cancel, header)
 	return args.Get(0).([]byte), args.Get(1).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Access(cancel <-chan struct{}, input *fuse.AccessIn) fuse.Status {
+func (m *lockFS) Access(cancel <-chan struct{}, input *fuse.AccessIn) fuse.Status {
 	args := m.Called(cancel, input)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) GetXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string, dest []byte) (uint32, fuse.Status) {
+func (m *lockFS) GetXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string, dest []byte) (uint32, fuse.Status) {
 	args := m.Called(cancel, header, attr, dest)
 	return args.Get(0).(uint32), args.Get(1).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) ListXAttr(cancel <-chan struct{}, header *fuse.InHeader, dest []byte) (uint32, fuse.Status) {
+func (m *lockFS) ListXAttr(cancel <-chan struct{}, header *fuse.InHeader, dest []byte) (uint32, fuse.Status) {
 	args := m.Called(cancel, header, dest)
 	return args.Get(0).(uint32), args.Get(1).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) SetXAttr(cancel <-chan struct{}, input *fuse.SetXAttrIn, attr string, data []byte) fuse.Status {
+func (m *lockFS) SetXAttr(cancel <-chan struct{}, input *fuse.SetXAttrIn, attr string, data []byte) fuse.Status {
 	args := m.Called(cancel, input, attr, data)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) RemoveXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string) fuse.Status {
+func (m *lockFS) RemoveXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string) fuse.Status {
 	args := m.Called(cancel, header, attr)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Create(cancel <-chan struct{}, input *fuse.CreateIn, name string, out *fuse.CreateOut) fuse.Status {
+func (m *lockFS) Create(cancel <-chan struct{}, input *fuse.CreateIn, name string, out *fuse.CreateOut) fuse.Status {
 	args := m.Called(cancel, input, name, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Open(cancel <-chan struct{}, input *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
+func (m *lockFS) Open(cancel <-chan struct{}, input *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
 	args := m.Called(cancel, input, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Read(cancel <-chan struct{}, input *fuse.ReadIn, buf []byte) (fuse.ReadResult, fuse.Status) {
+func (m *lockFS) Read(cancel <-chan struct{}, input *fuse.ReadIn, buf []byte) (fuse.ReadResult, fuse.Status) {
 	args := m.Called(cancel, input, buf)
 	return args.Get(0).(fuse.ReadResult), args.Get(1).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Lseek(cancel <-chan struct{}, in *fuse.LseekIn, out *fuse.LseekOut) fuse.Status {
+func (m *lockFS) Lseek(cancel <-chan struct{}, in *fuse.LseekIn, out *fuse.LseekOut) fuse.Status {
 	args := m.Called(cancel, in, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) GetLk(cancel <-chan struct{}, in *fuse.LkIn, out *fuse.LkOut) fuse.Status {
+func (m *lockFS) GetLk(cancel <-chan struct{}, in *fuse.LkIn, out *fuse.LkOut) fuse.Status {
 	args := m.Called(cancel, in, out)
 	if args.Get(0) == fuse.OK && out != nil && args.Get(1) != nil {
 		*out = *(args.Get(1).(*fuse.LkOut))
@@ -147,71 +147,71 @@ func (m *MockRawFileSystem) GetLk(cancel <-chan struct{}, in *fuse.LkIn, out *fu
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) SetLk(cancel <-chan struct{}, in *fuse.LkIn) fuse.Status {
+func (m *lockFS) SetLk(cancel <-chan struct{}, in *fuse.LkIn) fuse.Status {
 	args := m.Called(cancel, in)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) SetLkw(cancel <-chan struct{}, in *fuse.LkIn) fuse.Status {
+func (m *lockFS) SetLkw(cancel <-chan struct{}, in *fuse.LkIn) fuse.Status {
 	args := m.Called(cancel, in)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Release(cancel <-chan struct{}, input *fuse.ReleaseIn) {
+func (m *lockFS) Release(cancel <-chan struct{}, input *fuse.ReleaseIn) {
 	m.Called(cancel, input)
 }
 
-func (m *MockRawFileSystem) Write(cancel <-chan struct{}, input *fuse.WriteIn, data []byte) (uint32, fuse.Status) {
+func (m *lockFS) Write(cancel <-chan struct{}, input *fuse.WriteIn, data []byte) (uint32, fuse.Status) {
 	args := m.Called(cancel, input, data)
 	return args.Get(0).(uint32), args.Get(1).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) CopyFileRange(cancel <-chan struct{}, input *fuse.CopyFileRangeIn) (uint32, fuse.Status) {
+func (m *lockFS) CopyFileRange(cancel <-chan struct{}, input *fuse.CopyFileRangeIn) (uint32, fuse.Status) {
 	args := m.Called(cancel, input)
 	return args.Get(0).(uint32), args.Get(1).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Flush(cancel <-chan struct{}, input *fuse.FlushIn) fuse.Status {
+func (m *lockFS) Flush(cancel <-chan struct{}, input *fuse.FlushIn) fuse.Status {
 	args := m.Called(cancel, input)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Fsync(cancel <-chan struct{}, input *fuse.FsyncIn) fuse.Status {
+func (m *lockFS) Fsync(cancel <-chan struct{}, input *fuse.FsyncIn) fuse.Status {
 	args := m.Called(cancel, input)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Fallocate(cancel <-chan struct{}, input *fuse.FallocateIn) fuse.Status {
+func (m *lockFS) Fallocate(cancel <-chan struct{}, input *fuse.FallocateIn) fuse.Status {
 	args := m.Called(cancel, input)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) OpenDir(cancel <-chan struct{}, input *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
+func (m *lockFS) OpenDir(cancel <-chan struct{}, input *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
 	args := m.Called(cancel, input, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) ReadDir(cancel <-chan struct{}, input *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
+func (m *lockFS) ReadDir(cancel <-chan struct{}, input *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
 	args := m.Called(cancel, input, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) ReadDirPlus(cancel <-chan struct{}, input *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
+func (m *lockFS) ReadDirPlus(cancel <-chan struct{}, input *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
 	args := m.Called(cancel, input, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) ReleaseDir(input *fuse.ReleaseIn) {
+func (m *lockFS) ReleaseDir(input *fuse.ReleaseIn) {
 	m.Called(input)
 }
 
-func (m *MockRawFileSystem) FsyncDir(cancel <-chan struct{}, input *fuse.FsyncIn) fuse.Status {
+func (m *lockFS) FsyncDir(cancel <-chan struct{}, input *fuse.FsyncIn) fuse.Status {
 	args := m.Called(cancel, input)
 	return args.Get(0).(fuse.Status)
 }
 
 func TestGetLk(t *testing.T) {
-	mockFS := &MockRawFileSystem{}
+	mockFS := &lockFS{}
 	server := fuse2grpc.NewServer(mockFS)
 
 	tests := []struct {
@@ -324,7 +324,7 @@ func TestGetLk(t *testing.T) {
 }
 
 func TestSetLk(t *testing.T) {
-	mockFS := &MockRawFileSystem{}
+	mockFS := &lockFS{}
 	server := fuse2grpc.NewServer(mockFS)
 
 	tests := []struct {
@@ -419,7 +419,7 @@ func TestSetLk(t *testing.T) {
 }
 
 func TestSetLkw(t *testing.T) {
-	mockFS := &MockRawFileSystem{}
+	mockFS := &lockFS{}
 	server := fuse2grpc.NewServer(mockFS)
 
 	tests := []struct {
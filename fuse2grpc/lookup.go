@@ -29,6 +29,9 @@ import (
 )
 
 func (s *server) Lookup(ctx context.Context, req *pb.LookupRequest) (*pb.LookupResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	var (
 		out    fuse.EntryOut
 		header fuse.InHeader
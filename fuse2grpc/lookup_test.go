@@ -134,7 +134,7 @@ func TestLookup(t *testing.T) {
 				},
 				Name: "",
 			},
-			wantErr: status.Error(codes.Unimplemented, "method Lookup not implemented"),
+			wantErr: status.Error(codes.InvalidArgument, "empty name"),
 		},
 		{
 			name: "large attributes",
@@ -159,8 +159,7 @@ func TestLookup(t *testing.T) {
 							Uid: 9999,
 							Gid: 9999,
 						},
-						Rdev:    1234,
-						Blksize: 4096,
+						Rdev: 1234,
 					}
 					return fuse.OK
 				},
@@ -199,8 +198,7 @@ func TestLookup(t *testing.T) {
 							Uid: 9999,
 							Gid: 9999,
 						},
-						Rdev:    1234,
-						Blksize: 4096,
+						Rdev: 1234,
 					},
 				},
 			},
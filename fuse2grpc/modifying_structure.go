@@ -29,6 +29,9 @@ import (
 )
 
 func (s *server) Mkdir(ctx context.Context, req *pb.MkdirRequest) (*pb.MkdirResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	var (
 		header fuse.InHeader
 		out    fuse.EntryOut
@@ -49,6 +52,9 @@ func (s *server) Mkdir(ctx context.Context, req *pb.MkdirRequest) (*pb.MkdirResp
 }
 
 func (s *server) Unlink(ctx context.Context, req *pb.UnlinkRequest) (*pb.UnlinkResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	var (
 		header fuse.InHeader
 	)
@@ -66,6 +72,9 @@ func (s *server) Unlink(ctx context.Context, req *pb.UnlinkRequest) (*pb.UnlinkR
 }
 
 func (s *server) Rmdir(ctx context.Context, req *pb.RmdirRequest) (*pb.RmdirResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	var (
 		header fuse.InHeader
 	)
@@ -83,6 +92,9 @@ func (s *server) Rmdir(ctx context.Context, req *pb.RmdirRequest) (*pb.RmdirResp
 }
 
 func (s *server) Rename(ctx context.Context, req *pb.RenameRequest) (*pb.RenameResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	var (
 		header fuse.InHeader
 	)
@@ -29,6 +29,9 @@ import (
 )
 
 func (s *server) Mknod(ctx context.Context, req *pb.MknodRequest) (*pb.MknodResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	var (
 		header fuse.InHeader
 		out    fuse.EntryOut
@@ -12,12 +12,12 @@ import (
 	"github.com/chiyutianyi/grpcfuse/pb"
 )
 
-type mockFS struct {
+type modifyingStructureDarwinFS struct {
 	fuse.RawFileSystem
 	mknodFunc func(cancel <-chan struct{}, in *fuse.MknodIn, name string, out *fuse.EntryOut) fuse.Status
 }
 
-func (m *mockFS) Mknod(cancel <-chan struct{}, in *fuse.MknodIn, name string, out *fuse.EntryOut) fuse.Status {
+func (m *modifyingStructureDarwinFS) Mknod(cancel <-chan struct{}, in *fuse.MknodIn, name string, out *fuse.EntryOut) fuse.Status {
 	if m.mknodFunc != nil {
 		return m.mknodFunc(cancel, in, name, out)
 	}
@@ -27,7 +27,7 @@ func (m *mockFS) Mknod(cancel <-chan struct{}, in *fuse.MknodIn, name string, ou
 func TestMknod(t *testing.T) {
 	tests := []struct {
 		name        string
-		fs          *mockFS
+		fs          *modifyingStructureDarwinFS
 		req         *pb.MknodRequest
 		wantErr     bool
 		wantErrCode codes.Code
@@ -36,7 +36,7 @@ func TestMknod(t *testing.T) {
 	}{
 		{
 			name: "success",
-			fs: &mockFS{
+			fs: &modifyingStructureDarwinFS{
 				mknodFunc: func(cancel <-chan struct{}, in *fuse.MknodIn, name string, out *fuse.EntryOut) fuse.Status {
 					out.NodeId = 123
 					out.Generation = 456
@@ -67,7 +67,7 @@ func TestMknod(t *testing.T) {
 		},
 		{
 			name: "not implemented",
-			fs:   &mockFS{},
+			fs:   &modifyingStructureDarwinFS{},
 			req: &pb.MknodRequest{
 				Header: &pb.InHeader{},
 				Name:   "test.txt",
@@ -77,7 +77,7 @@ func TestMknod(t *testing.T) {
 		},
 		{
 			name: "operation failed",
-			fs: &mockFS{
+			fs: &modifyingStructureDarwinFS{
 				mknodFunc: func(cancel <-chan struct{}, in *fuse.MknodIn, name string, out *fuse.EntryOut) fuse.Status {
 					return fuse.EPERM
 				},
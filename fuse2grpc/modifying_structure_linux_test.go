@@ -13,25 +13,25 @@ import (
 	"github.com/chiyutianyi/grpcfuse/pb"
 )
 
-type mockRawFileSystem struct {
+type modifyingStructureLinuxFS struct {
 	mock.Mock
 	fuse.RawFileSystem
 }
 
-func (m *mockRawFileSystem) Mknod(cancel <-chan struct{}, in *fuse.MknodIn, name string, out *fuse.EntryOut) fuse.Status {
+func (m *modifyingStructureLinuxFS) Mknod(cancel <-chan struct{}, in *fuse.MknodIn, name string, out *fuse.EntryOut) fuse.Status {
 	args := m.Called(cancel, in, name, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *mockRawFileSystem) String() string {
-	return "mockRawFileSystem"
+func (m *modifyingStructureLinuxFS) String() string {
+	return "modifyingStructureLinuxFS"
 }
 
 func TestMknod(t *testing.T) {
 	tests := []struct {
 		name        string
 		req         *pb.MknodRequest
-		setupMock   func(*mockRawFileSystem)
+		setupMock   func(*modifyingStructureLinuxFS)
 		wantErr     bool
 		wantErrCode codes.Code
 		wantStatus  int32
@@ -54,7 +54,7 @@ func TestMknod(t *testing.T) {
 				Rdev:  0,
 				Umask: 022,
 			},
-			setupMock: func(m *mockRawFileSystem) {
+			setupMock: func(m *modifyingStructureLinuxFS) {
 				m.On("Mknod", mock.Anything, mock.MatchedBy(func(in *fuse.MknodIn) bool {
 					return in.NodeId == 1 && in.Mode == 0644 && in.Rdev == 0 && in.Umask == 022
 				}), "test.txt", mock.AnythingOfType("*fuse.EntryOut")).
@@ -81,7 +81,7 @@ func TestMknod(t *testing.T) {
 				Rdev:  0,
 				Umask: 022,
 			},
-			setupMock: func(m *mockRawFileSystem) {
+			setupMock: func(m *modifyingStructureLinuxFS) {
 				m.On("Mknod", mock.Anything, mock.Anything, "test.txt", mock.Anything).
 					Return(fuse.ENOSYS)
 			},
@@ -106,7 +106,7 @@ func TestMknod(t *testing.T) {
 				Rdev:  0,
 				Umask: 022,
 			},
-			setupMock: func(m *mockRawFileSystem) {
+			setupMock: func(m *modifyingStructureLinuxFS) {
 				m.On("Mknod", mock.Anything, mock.Anything, "test.txt", mock.Anything).
 					Return(fuse.EPERM)
 			},
@@ -117,7 +117,7 @@ func TestMknod(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockFS := &mockRawFileSystem{}
+			mockFS := &modifyingStructureLinuxFS{}
 			tt.setupMock(mockFS)
 
 			s := &server{
@@ -14,200 +14,200 @@ import (
 	"github.com/chiyutianyi/grpcfuse/pb"
 )
 
-type MockRawFileSystem struct {
+type modifyingStructureFS struct {
 	mock.Mock
 }
 
-func (m *MockRawFileSystem) String() string {
+func (m *modifyingStructureFS) String() string {
 	args := m.Called()
 	return args.String(0)
 }
 
-func (m *MockRawFileSystem) SetDebug(debug bool) {}
+func (m *modifyingStructureFS) SetDebug(debug bool) {}
 
-func (m *MockRawFileSystem) Init(server *fuse.Server) {
+func (m *modifyingStructureFS) Init(server *fuse.Server) {
 	m.Called(server)
 }
 
-func (m *MockRawFileSystem) Lookup(cancel <-chan struct{}, header *fuse.InHeader, name string, out *fuse.EntryOut) fuse.Status {
+func (m *modifyingStructureFS) Lookup(cancel <-chan struct{}, header *fuse.InHeader, name string, out *fuse.EntryOut) fuse.Status {
 	args := m.Called(cancel, header, name, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Forget(nodeID uint64, nlookup uint64) {
+func (m *modifyingStructureFS) Forget(nodeID uint64, nlookup uint64) {
 	m.Called(nodeID, nlookup)
 }
 
-func (m *MockRawFileSystem) GetAttr(cancel <-chan struct{}, input *fuse.GetAttrIn, out *fuse.AttrOut) fuse.Status {
+func (m *modifyingStructureFS) GetAttr(cancel <-chan struct{}, input *fuse.GetAttrIn, out *fuse.AttrOut) fuse.Status {
 	args := m.Called(cancel, input, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) SetAttr(cancel <-chan struct{}, input *fuse.SetAttrIn, out *fuse.AttrOut) fuse.Status {
+func (m *modifyingStructureFS) SetAttr(cancel <-chan struct{}, input *fuse.SetAttrIn, out *fuse.AttrOut) fuse.Status {
 	args := m.Called(cancel, input, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Mknod(cancel <-chan struct{}, input *fuse.MknodIn, name string, out *fuse.EntryOut) fuse.Status {
+func (m *modifyingStructureFS) Mknod(cancel <-chan struct{}, input *fuse.MknodIn, name string, out *fuse.EntryOut) fuse.Status {
 	args := m.Called(cancel, input, name, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Mkdir(cancel <-chan struct{}, input *fuse.MkdirIn, name string, out *fuse.EntryOut) fuse.Status {
+func (m *modifyingStructureFS) Mkdir(cancel <-chan struct{}, input *fuse.MkdirIn, name string, out *fuse.EntryOut) fuse.Status {
 	args := m.Called(cancel, input, name, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Unlink(cancel <-chan struct{}, header *fuse.InHeader, name string) fuse.Status {
+func (m *modifyingStructureFS) Unlink(cancel <-chan struct{}, header *fuse.InHeader, name string) fuse.Status {
 	args := m.Called(cancel, header, name)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Rmdir(cancel <-chan struct{}, header *fuse.InHeader, name string) fuse.Status {
+func (m *modifyingStructureFS) Rmdir(cancel <-chan struct{}, header *fuse.InHeader, name string) fuse.Status {
 	args := m.Called(cancel, header, name)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Rename(cancel <-chan struct{}, input *fuse.RenameIn, oldName string, newName string) fuse.Status {
+func (m *modifyingStructureFS) Rename(cancel <-chan struct{}, input *fuse.RenameIn, oldName string, newName string) fuse.Status {
 	args := m.Called(cancel, input, oldName, newName)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Link(cancel <-chan struct{}, input *fuse.LinkIn, filename string, out *fuse.EntryOut) fuse.Status {
+func (m *modifyingStructureFS) Link(cancel <-chan struct{}, input *fuse.LinkIn, filename string, out *fuse.EntryOut) fuse.Status {
 	args := m.Called(cancel, input, filename, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Symlink(cancel <-chan struct{}, header *fuse.InHeader, pointedTo string, linkName string, out *fuse.EntryOut) fuse.Status {
+func (m *modifyingStructureFS) Symlink(cancel <-chan struct{}, header *fuse.InHeader, pointedTo string, linkName string, out *fuse.EntryOut) fuse.Status {
 	args := m.Called(cancel, header, pointedTo, linkName, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Readlink(cancel <-chan struct{}, header *fuse.InHeader) ([]byte, fuse.Status) {
+func (m *modifyingStructureFS) Readlink(cancel <-chan struct{}, header *fuse.InHeader) ([]byte, fuse.Status) {
 	args := m.Called(cancel, header)
 	return args.Get(0).([]byte), args.Get(1).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Access(cancel <-chan struct{}, input *fuse.AccessIn) fuse.Status {
+func (m *modifyingStructureFS) Access(cancel <-chan struct{}, input *fuse.AccessIn) fuse.Status {
 	args := m.Called(cancel, input)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) GetXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string, data []byte) (uint32, fuse.Status) {
+func (m *modifyingStructureFS) GetXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string, data []byte) (uint32, fuse.Status) {
 	args := m.Called(cancel, header, attr, data)
 	return args.Get(0).(uint32), args.Get(1).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) ListXAttr(cancel <-chan struct{}, header *fuse.InHeader, dest []byte) (uint32, fuse.Status) {
+func (m *modifyingStructureFS) ListXAttr(cancel <-chan struct{}, header *fuse.InHeader, dest []byte) (uint32, fuse.Status) {
 	args := m.Called(cancel, header, dest)
 	return args.Get(0).(uint32), args.Get(1).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) SetXAttr(cancel <-chan struct{}, input *fuse.SetXAttrIn, attr string, data []byte) fuse.Status {
+func (m *modifyingStructureFS) SetXAttr(cancel <-chan struct{}, input *fuse.SetXAttrIn, attr string, data []byte) fuse.Status {
 	args := m.Called(cancel, input, attr, data)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) RemoveXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string) fuse.Status {
+func (m *modifyingStructureFS) RemoveXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string) fuse.Status {
 	args := m.Called(cancel, header, attr)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Create(cancel <-chan struct{}, input *fuse.CreateIn, name string, out *fuse.CreateOut) fuse.Status {
+func (m *modifyingStructureFS) Create(cancel <-chan struct{}, input *fuse.CreateIn, name string, out *fuse.CreateOut) fuse.Status {
 	args := m.Called(cancel, input, name, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Open(cancel <-chan struct{}, input *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
+func (m *modifyingStructureFS) Open(cancel <-chan struct{}, input *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
 	args := m.Called(cancel, input, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Read(cancel <-chan struct{}, input *fuse.ReadIn, buf []byte) (fuse.ReadResult, fuse.Status) {
+func (m *modifyingStructureFS) Read(cancel <-chan struct{}, input *fuse.ReadIn, buf []byte) (fuse.ReadResult, fuse.Status) {
 	args := m.Called(cancel, input, buf)
 	return args.Get(0).(fuse.ReadResult), args.Get(1).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Write(cancel <-chan struct{}, input *fuse.WriteIn, data []byte) (uint32, fuse.Status) {
+func (m *modifyingStructureFS) Write(cancel <-chan struct{}, input *fuse.WriteIn, data []byte) (uint32, fuse.Status) {
 	args := m.Called(cancel, input, data)
 	return args.Get(0).(uint32), args.Get(1).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Flush(cancel <-chan struct{}, input *fuse.FlushIn) fuse.Status {
+func (m *modifyingStructureFS) Flush(cancel <-chan struct{}, input *fuse.FlushIn) fuse.Status {
 	args := m.Called(cancel, input)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Release(cancel <-chan struct{}, input *fuse.ReleaseIn) {
+func (m *modifyingStructureFS) Release(cancel <-chan struct{}, input *fuse.ReleaseIn) {
 	m.Called(cancel, input)
 }
 
-func (m *MockRawFileSystem) Fsync(cancel <-chan struct{}, input *fuse.FsyncIn) fuse.Status {
+func (m *modifyingStructureFS) Fsync(cancel <-chan struct{}, input *fuse.FsyncIn) fuse.Status {
 	args := m.Called(cancel, input)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) OpenDir(cancel <-chan struct{}, input *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
+func (m *modifyingStructureFS) OpenDir(cancel <-chan struct{}, input *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
 	args := m.Called(cancel, input, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) ReadDir(cancel <-chan struct{}, input *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
+func (m *modifyingStructureFS) ReadDir(cancel <-chan struct{}, input *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
 	args := m.Called(cancel, input, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) ReadDirPlus(cancel <-chan struct{}, input *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
+func (m *modifyingStructureFS) ReadDirPlus(cancel <-chan struct{}, input *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
 	args := m.Called(cancel, input, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) ReleaseDir(input *fuse.ReleaseIn) {
+func (m *modifyingStructureFS) ReleaseDir(input *fuse.ReleaseIn) {
 	m.Called(input)
 }
 
-func (m *MockRawFileSystem) FsyncDir(cancel <-chan struct{}, input *fuse.FsyncIn) fuse.Status {
+func (m *modifyingStructureFS) FsyncDir(cancel <-chan struct{}, input *fuse.FsyncIn) fuse.Status {
 	args := m.Called(cancel, input)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) StatFs(cancel <-chan struct{}, input *fuse.InHeader, out *fuse.StatfsOut) fuse.Status {
+func (m *modifyingStructureFS) StatFs(cancel <-chan struct{}, input *fuse.InHeader, out *fuse.StatfsOut) fuse.Status {
 	args := m.Called(cancel, input, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) CopyFileRange(cancel <-chan struct{}, input *fuse.CopyFileRangeIn) (uint32, fuse.Status) {
+func (m *modifyingStructureFS) CopyFileRange(cancel <-chan struct{}, input *fuse.CopyFileRangeIn) (uint32, fuse.Status) {
 	args := m.Called(cancel, input)
 	return args.Get(0).(uint32), args.Get(1).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Lseek(cancel <-chan struct{}, in *fuse.LseekIn, out *fuse.LseekOut) fuse.Status {
+func (m *modifyingStructureFS) Lseek(cancel <-chan struct{}, in *fuse.LseekIn, out *fuse.LseekOut) fuse.Status {
 	args := m.Called(cancel, in, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) GetLk(cancel <-chan struct{}, input *fuse.LkIn, out *fuse.LkOut) fuse.Status {
+func (m *modifyingStructureFS) GetLk(cancel <-chan struct{}, input *fuse.LkIn, out *fuse.LkOut) fuse.Status {
 	args := m.Called(cancel, input, out)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) SetLk(cancel <-chan struct{}, input *fuse.LkIn) fuse.Status {
+func (m *modifyingStructureFS) SetLk(cancel <-chan struct{}, input *fuse.LkIn) fuse.Status {
 	args := m.Called(cancel, input)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) SetLkw(cancel <-chan struct{}, input *fuse.LkIn) fuse.Status {
+func (m *modifyingStructureFS) SetLkw(cancel <-chan struct{}, input *fuse.LkIn) fuse.Status {
 	args := m.Called(cancel, input)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) Fallocate(cancel <-chan struct{}, input *fuse.FallocateIn) fuse.Status {
+func (m *modifyingStructureFS) Fallocate(cancel <-chan struct{}, input *fuse.FallocateIn) fuse.Status {
 	args := m.Called(cancel, input)
 	return args.Get(0).(fuse.Status)
 }
 
 func TestMkdir(t *testing.T) {
-	mockFS := new(MockRawFileSystem)
+	mockFS := new(modifyingStructureFS)
 	server := fuse2grpc.NewServer(mockFS)
 
 	tests := []struct {
@@ -302,7 +302,7 @@ func TestMkdir(t *testing.T) {
 }
 
 func TestUnlink(t *testing.T) {
-	mockFS := new(MockRawFileSystem)
+	mockFS := new(modifyingStructureFS)
 	server := fuse2grpc.NewServer(mockFS)
 
 	tests := []struct {
@@ -389,7 +389,7 @@ func TestUnlink(t *testing.T) {
 }
 
 func TestRmdir(t *testing.T) {
-	mockFS := new(MockRawFileSystem)
+	mockFS := new(modifyingStructureFS)
 	server := fuse2grpc.NewServer(mockFS)
 
 	tests := []struct {
@@ -476,7 +476,7 @@ func TestRmdir(t *testing.T) {
 }
 
 func TestRename(t *testing.T) {
-	mockFS := new(MockRawFileSystem)
+	mockFS := new(modifyingStructureFS)
 	server := fuse2grpc.NewServer(mockFS)
 
 	tests := []struct {
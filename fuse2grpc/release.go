@@ -30,6 +30,9 @@ import (
 )
 
 func (s *server) Release(ctx context.Context, req *pb.ReleaseRequest) (*emptypb.Empty, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	var (
 		header fuse.InHeader
 	)
@@ -47,6 +50,9 @@ func (s *server) Release(ctx context.Context, req *pb.ReleaseRequest) (*emptypb.
 }
 
 func (s *server) Flush(ctx context.Context, req *pb.FlushRequest) (*pb.FlushResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	var (
 		header fuse.InHeader
 	)
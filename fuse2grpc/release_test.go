@@ -12,166 +12,166 @@ import (
 	"github.com/chiyutianyi/grpcfuse/pb"
 )
 
-type mockFS struct {
+type releaseFS struct {
 	mock.Mock
 }
 
-func (m *mockFS) String() string {
+func (m *releaseFS) String() string {
 	return "mock"
 }
 
-func (m *mockFS) SetDebug(debug bool) {}
+func (m *releaseFS) SetDebug(debug bool) {}
 
-func (m *mockFS) Release(cancel <-chan struct{}, input *fuse.ReleaseIn) {
+func (m *releaseFS) Release(cancel <-chan struct{}, input *fuse.ReleaseIn) {
 	m.Called(cancel, input)
 }
 
-func (m *mockFS) Flush(cancel <-chan struct{}, input *fuse.FlushIn) fuse.Status {
+func (m *releaseFS) Flush(cancel <-chan struct{}, input *fuse.FlushIn) fuse.Status {
 	args := m.Called(cancel, input)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *mockFS) Forget(nodeID uint64, nlookup uint64) {
+func (m *releaseFS) Forget(nodeID uint64, nlookup uint64) {
 	m.Called(nodeID, nlookup)
 }
 
-func (m *mockFS) StatFs(cancel <-chan struct{}, in *fuse.InHeader, out *fuse.StatfsOut) fuse.Status {
+func (m *releaseFS) StatFs(cancel <-chan struct{}, in *fuse.InHeader, out *fuse.StatfsOut) fuse.Status {
 	return fuse.ENOSYS
 }
 
-func (m *mockFS) Lookup(cancel <-chan struct{}, h *fuse.InHeader, name string, out *fuse.EntryOut) fuse.Status {
+func (m *releaseFS) Lookup(cancel <-chan struct{}, h *fuse.InHeader, name string, out *fuse.EntryOut) fuse.Status {
 	return fuse.ENOSYS
 }
 
-func (m *mockFS) Init(*fuse.Server) {}
+func (m *releaseFS) Init(*fuse.Server) {}
 
-func (m *mockFS) Destroy() {}
+func (m *releaseFS) Destroy() {}
 
-func (m *mockFS) GetAttr(cancel <-chan struct{}, in *fuse.GetAttrIn, out *fuse.AttrOut) fuse.Status {
+func (m *releaseFS) GetAttr(cancel <-chan struct{}, in *fuse.GetAttrIn, out *fuse.AttrOut) fuse.Status {
 	return fuse.ENOSYS
 }
 
-func (m *mockFS) SetAttr(cancel <-chan struct{}, in *fuse.SetAttrIn, out *fuse.AttrOut) fuse.Status {
+func (m *releaseFS) SetAttr(cancel <-chan struct{}, in *fuse.SetAttrIn, out *fuse.AttrOut) fuse.Status {
 	return fuse.ENOSYS
 }
 
-func (m *mockFS) Mknod(cancel <-chan struct{}, in *fuse.MknodIn, name string, out *fuse.EntryOut) fuse.Status {
+func (m *releaseFS) Mknod(cancel <-chan struct{}, in *fuse.MknodIn, name string, out *fuse.EntryOut) fuse.Status {
 	return fuse.ENOSYS
 }
 
-func (m *mockFS) Mkdir(cancel <-chan struct{}, in *fuse.MkdirIn, name string, out *fuse.EntryOut) fuse.Status {
+func (m *releaseFS) Mkdir(cancel <-chan struct{}, in *fuse.MkdirIn, name string, out *fuse.EntryOut) fuse.Status {
 	return fuse.ENOSYS
 }
 
-func (m *mockFS) Unlink(cancel <-chan struct{}, h *fuse.InHeader, name string) fuse.Status {
+func (m *releaseFS) Unlink(cancel <-chan struct{}, h *fuse.InHeader, name string) fuse.Status {
 	return fuse.ENOSYS
 }
 
-func (m *mockFS) Rmdir(cancel <-chan struct{}, h *fuse.InHeader, name string) fuse.Status {
+func (m *releaseFS) Rmdir(cancel <-chan struct{}, h *fuse.InHeader, name string) fuse.Status {
 	return fuse.ENOSYS
 }
 
-func (m *mockFS) Rename(cancel <-chan struct{}, in *fuse.RenameIn, oldName, newName string) fuse.Status {
+func (m *releaseFS) Rename(cancel <-chan struct{}, in *fuse.RenameIn, oldName, newName string) fuse.Status {
 	return fuse.ENOSYS
 }
 
-func (m *mockFS) Link(cancel <-chan struct{}, in *fuse.LinkIn, name string, out *fuse.EntryOut) fuse.Status {
+func (m *releaseFS) Link(cancel <-chan struct{}, in *fuse.LinkIn, name string, out *fuse.EntryOut) fuse.Status {
 	return fuse.ENOSYS
 }
 
-func (m *mockFS) Symlink(cancel <-chan struct{}, h *fuse.InHeader, pointedTo, linkName string, out *fuse.EntryOut) fuse.Status {
+func (m *releaseFS) Symlink(cancel <-chan struct{}, h *fuse.InHeader, pointedTo, linkName string, out *fuse.EntryOut) fuse.Status {
 	return fuse.ENOSYS
 }
 
-func (m *mockFS) Readlink(cancel <-chan struct{}, h *fuse.InHeader) ([]byte, fuse.Status) {
+func (m *releaseFS) Readlink(cancel <-chan struct{}, h *fuse.InHeader) ([]byte, fuse.Status) {
 	return nil, fuse.ENOSYS
 }
 
-func (m *mockFS) Create(cancel <-chan struct{}, in *fuse.CreateIn, name string, out *fuse.CreateOut) fuse.Status {
+func (m *releaseFS) Create(cancel <-chan struct{}, in *fuse.CreateIn, name string, out *fuse.CreateOut) fuse.Status {
 	return fuse.ENOSYS
 }
 
-func (m *mockFS) Open(cancel <-chan struct{}, in *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
+func (m *releaseFS) Open(cancel <-chan struct{}, in *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
 	return fuse.ENOSYS
 }
 
-func (m *mockFS) Read(cancel <-chan struct{}, input *fuse.ReadIn, buf []byte) (fuse.ReadResult, fuse.Status) {
+func (m *releaseFS) Read(cancel <-chan struct{}, input *fuse.ReadIn, buf []byte) (fuse.ReadResult, fuse.Status) {
 	return nil, fuse.ENOSYS
 }
 
-func (m *mockFS) Write(cancel <-chan struct{}, input *fuse.WriteIn, data []byte) (written uint32, st fuse.Status) {
+func (m *releaseFS) Write(cancel <-chan struct{}, input *fuse.WriteIn, data []byte) (written uint32, st fuse.Status) {
 	return 0, fuse.ENOSYS
 }
 
-func (m *mockFS) Lseek(cancel <-chan struct{}, input *fuse.LseekIn, out *fuse.LseekOut) fuse.Status {
+func (m *releaseFS) Lseek(cancel <-chan struct{}, input *fuse.LseekIn, out *fuse.LseekOut) fuse.Status {
 	return fuse.ENOSYS
 }
 
-func (m *mockFS) GetLk(cancel <-chan struct{}, input *fuse.LkIn, out *fuse.LkOut) fuse.Status {
+func (m *releaseFS) GetLk(cancel <-chan struct{}, input *fuse.LkIn, out *fuse.LkOut) fuse.Status {
 	return fuse.ENOSYS
 }
 
-func (m *mockFS) SetLk(cancel <-chan struct{}, input *fuse.LkIn) fuse.Status {
+func (m *releaseFS) SetLk(cancel <-chan struct{}, input *fuse.LkIn) fuse.Status {
 	return fuse.ENOSYS
 }
 
-func (m *mockFS) SetLkw(cancel <-chan struct{}, input *fuse.LkIn) fuse.Status {
+func (m *releaseFS) SetLkw(cancel <-chan struct{}, input *fuse.LkIn) fuse.Status {
 	return fuse.ENOSYS
 }
 
-func (m *mockFS) Access(cancel <-chan struct{}, in *fuse.AccessIn) fuse.Status {
+func (m *releaseFS) Access(cancel <-chan struct{}, in *fuse.AccessIn) fuse.Status {
 	return fuse.ENOSYS
 }
 
-func (m *mockFS) Fsync(cancel <-chan struct{}, input *fuse.FsyncIn) fuse.Status {
+func (m *releaseFS) Fsync(cancel <-chan struct{}, input *fuse.FsyncIn) fuse.Status {
 	return fuse.ENOSYS
 }
 
-func (m *mockFS) OpenDir(cancel <-chan struct{}, input *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
+func (m *releaseFS) OpenDir(cancel <-chan struct{}, input *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
 	return fuse.ENOSYS
 }
 
-func (m *mockFS) ReadDir(cancel <-chan struct{}, input *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
+func (m *releaseFS) ReadDir(cancel <-chan struct{}, input *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
 	return fuse.ENOSYS
 }
 
-func (m *mockFS) ReadDirPlus(cancel <-chan struct{}, input *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
+func (m *releaseFS) ReadDirPlus(cancel <-chan struct{}, input *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
 	return fuse.ENOSYS
 }
 
-func (m *mockFS) ReleaseDir(input *fuse.ReleaseIn) {}
+func (m *releaseFS) ReleaseDir(input *fuse.ReleaseIn) {}
 
-func (m *mockFS) FsyncDir(cancel <-chan struct{}, input *fuse.FsyncIn) fuse.Status {
+func (m *releaseFS) FsyncDir(cancel <-chan struct{}, input *fuse.FsyncIn) fuse.Status {
 	return fuse.ENOSYS
 }
 
-func (m *mockFS) GetXAttr(cancel <-chan struct{}, header *fuse.InHeader, name string, dest []byte) (uint32, fuse.Status) {
+func (m *releaseFS) GetXAttr(cancel <-chan struct{}, header *fuse.InHeader, name string, dest []byte) (uint32, fuse.Status) {
 	return 0, fuse.ENOSYS
 }
 
-func (m *mockFS) ListXAttr(cancel <-chan struct{}, header *fuse.InHeader, dest []byte) (uint32, fuse.Status) {
+func (m *releaseFS) ListXAttr(cancel <-chan struct{}, header *fuse.InHeader, dest []byte) (uint32, fuse.Status) {
 	return 0, fuse.ENOSYS
 }
 
-func (m *mockFS) SetXAttr(cancel <-chan struct{}, in *fuse.SetXAttrIn, name string, value []byte) fuse.Status {
+func (m *releaseFS) SetXAttr(cancel <-chan struct{}, in *fuse.SetXAttrIn, name string, value []byte) fuse.Status {
 	return fuse.ENOSYS
 }
 
-func (m *mockFS) RemoveXAttr(cancel <-chan struct{}, h *fuse.InHeader, name string) fuse.Status {
+func (m *releaseFS) RemoveXAttr(cancel <-chan struct{}, h *fuse.InHeader, name string) fuse.Status {
 	return fuse.ENOSYS
 }
 
-func (m *mockFS) CopyFileRange(cancel <-chan struct{}, in *fuse.CopyFileRangeIn) (uint32, fuse.Status) {
+func (m *releaseFS) CopyFileRange(cancel <-chan struct{}, in *fuse.CopyFileRangeIn) (uint32, fuse.Status) {
 	return 0, fuse.ENOSYS
 }
 
-func (m *mockFS) Fallocate(cancel <-chan struct{}, in *fuse.FallocateIn) fuse.Status {
+func (m *releaseFS) Fallocate(cancel <-chan struct{}, in *fuse.FallocateIn) fuse.Status {
 	return fuse.ENOSYS
 }
 
 func TestServerRelease(t *testing.T) {
-	mockFS := &mockFS{}
-	server := NewServer(mockFS)
+	releaseFS := &releaseFS{}
+	server := NewServer(releaseFS)
 
 	testCases := []struct {
 		name    string
@@ -197,7 +197,7 @@ func TestServerRelease(t *testing.T) {
 				LockOwner:    0,
 			},
 			setup: func() {
-				mockFS.On("Release", mock.Anything, mock.MatchedBy(func(in *fuse.ReleaseIn) bool {
+				releaseFS.On("Release", mock.Anything, mock.MatchedBy(func(in *fuse.ReleaseIn) bool {
 					return in.NodeId == 1 && in.Fh == 123
 				})).Return()
 			},
@@ -221,7 +221,7 @@ func TestServerRelease(t *testing.T) {
 				assert.IsType(t, &emptypb.Empty{}, resp)
 			}
 
-			mockFS.AssertExpectations(t)
+			releaseFS.AssertExpectations(t)
 		})
 	}
 }
@@ -36,6 +36,9 @@ type server struct {
 	buffers bufferPool
 
 	msgSizeThreshold int
+
+	// inlineSize is how much of a file a read-only open returns.
+	inlineSize int
 }
 
 // NewServer returns a new loopback server.
@@ -29,6 +29,9 @@ import (
 )
 
 func (s *server) StatFs(ctx context.Context, req *pb.StatfsRequest) (*pb.StatfsResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	var (
 		out    fuse.StatfsOut
 		header fuse.InHeader
@@ -17,13 +17,13 @@
 package fuse2grpc
 
 import (
-	"unsafe"
+	"encoding/binary"
 
 	"github.com/hanwen/go-fuse/v2/fuse"
 )
 
-// _Dirent is what we send to the kernel, but we offer DirEntry and
-// DirEntryList to the user.
+// _Dirent is the fixed part of struct fuse_dirent, the record
+// fuse.DirEntryList serializes for READDIR and READDIRPLUS.
 type _Dirent struct {
 	Ino     uint64
 	Off     uint64
@@ -31,23 +31,10 @@ type _Dirent struct {
 	Typ     uint32
 }
 
-// DirEntryList holds the return value for READDIR and READDIRPLUS
-// opcodes.
-type DirEntryList struct {
-	buf []byte
-	// capacity of the underlying buffer
-	size int
-	// offset is the requested location in the directory. go-fuse
-	// currently counts in number of directory entries, but this is an
-	// implementation detail and may change in the future.
-	// If `offset` and `fs.fileEntry.dirOffset` disagree, then a
-	// directory seek has taken place.
-	offset uint64
-	// pointer to the last serialized _Dirent. Used by FixMode().
-	lastDirent *_Dirent
-}
+// direntSize is the size of _Dirent on the wire.
+const direntSize = uint32(8 + 8 + 4 + 4)
 
-const (
-	direntSize   = uint32(unsafe.Sizeof(_Dirent{}))
-	entryOutSize = uint32(unsafe.Sizeof(fuse.EntryOut{}))
-)
+// entryOutSize is the size of the fuse.EntryOut preceding each
+// READDIRPLUS record. fuse.EntryOut has no implicit padding, so its wire
+// size is the sum of its fields.
+var entryOutSize = uint32(binary.Size(fuse.EntryOut{}))
@@ -22,17 +22,6 @@ func TestEntryOutSizeConstant(t *testing.T) {
 	assert.Equal(t, uint32(expectedSize), entryOutSize, "entryOutSize constant should match actual EntryOut struct size")
 }
 
-func TestDirEntryListStructure(t *testing.T) {
-	// Test DirEntryList struct has expected fields
-	var del DirEntryList
-
-	// Verify field types
-	assert.IsType(t, []byte{}, del.buf, "buf field should be []byte")
-	assert.IsType(t, 0, del.size, "size field should be int")
-	assert.IsType(t, uint64(0), del.offset, "offset field should be uint64")
-	assert.IsType(t, (*_Dirent)(nil), del.lastDirent, "lastDirent field should be pointer to _Dirent struct")
-}
-
 func TestDirentFields(t *testing.T) {
 	d := _Dirent{
 		Ino: 1234,
@@ -46,17 +35,3 @@ func TestDirentFields(t *testing.T) {
 	assert.Equal(t, uint32(10), d.NameLen)
 	assert.Equal(t, uint32(1), d.Typ)
 }
-
-func TestDirEntryListInit(t *testing.T) {
-	del := DirEntryList{
-		buf: make([]byte, 100),
-		size: 100,
-		offset: 42,
-		lastDirent: &_Dirent{},
-	}
-
-	assert.Equal(t, 100, len(del.buf))
-	assert.Equal(t, 100, del.size)
-	assert.Equal(t, uint64(42), del.offset)
-	assert.NotNil(t, del.lastDirent)
-}
@@ -0,0 +1,285 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fuse2grpc
+
+import (
+	"strings"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+const (
+	// maxIOSize bounds the buffer a single Read, ReadDir or Write may
+	// use. The kernel never asks for more than 1mb at a time.
+	maxIOSize = 16 << 20
+
+	// maxXAttrNameLen is XATTR_NAME_MAX.
+	maxXAttrNameLen = 255
+	// maxXAttrSize is XATTR_SIZE_MAX.
+	maxXAttrSize = 64 << 10
+	// maxSymlinkLen is PATH_MAX.
+	maxSymlinkLen = 4096
+
+	// maxCompoundOps bounds the ops of a Compound, and maxCompoundIO
+	// the data its reads and writes carry in all, which keeps its
+	// messages well below the 4mb gRPC takes by default.
+	maxCompoundOps = 16
+	maxCompoundIO  = 1 << 20
+)
+
+func invalidArgument(format string, a ...interface{}) error {
+	return status.Errorf(codes.InvalidArgument, format, a...)
+}
+
+// validate checks that a request carries every field its handler
+// dereferences, and that names and sizes are within the limits of the
+// FUSE protocol. It returns an InvalidArgument error otherwise.
+func validate(req interface{}) error {
+	switch req := req.(type) {
+	case *pb.StringRequest:
+		return nil
+	case *pb.LookupRequest:
+		return firstError(checkHeader(req.Header), checkName("name", req.Name, true))
+	case *pb.ForgetRequest:
+		return checkNodeID("nodeid", req.Nodeid)
+	case *pb.GetAttrRequest:
+		return checkHeader(req.Header)
+	case *pb.SetAttrRequest:
+		if req.Valid&(fuse.FATTR_UID|fuse.FATTR_GID) != 0 && req.Owner == nil {
+			return firstError(checkHeader(req.Header), invalidArgument("missing owner"))
+		}
+		return checkHeader(req.Header)
+	case *pb.MknodRequest:
+		return firstError(checkHeader(req.Header), checkName("name", req.Name, false))
+	case *pb.MkdirRequest:
+		return firstError(checkHeader(req.Header), checkName("name", req.Name, false))
+	case *pb.UnlinkRequest:
+		return firstError(checkHeader(req.Header), checkName("name", req.Name, false))
+	case *pb.RmdirRequest:
+		return firstError(checkHeader(req.Header), checkName("name", req.Name, false))
+	case *pb.RenameRequest:
+		return firstError(
+			checkHeader(req.Header),
+			checkNodeID("newdir", req.Newdir),
+			checkName("old_name", req.OldName, false),
+			checkName("new_name", req.NewName, false),
+		)
+	case *pb.LinkRequest:
+		return firstError(
+			checkHeader(req.Header),
+			checkNodeID("oldnodeid", req.Oldnodeid),
+			checkName("filename", req.Filename, false),
+		)
+	case *pb.SymlinkRequest:
+		return firstError(
+			checkHeader(req.Header),
+			checkName("link_name", req.LinkName, false),
+			checkSymlinkTarget(req.PointedTo),
+		)
+	case *pb.ReadlinkRequest:
+		return checkHeader(req.Header)
+	case *pb.AccessRequest:
+		return checkHeader(req.Header)
+	case *pb.GetXAttrRequest:
+		return firstError(checkHeader(req.Header), checkXAttrName(req.Attr), checkXAttrSize("size", int(req.Size)))
+	case *pb.ListXAttrRequest:
+		return firstError(checkHeader(req.Header), checkXAttrSize("size", int(req.Size)))
+	case *pb.SetXAttrRequest:
+		if int(req.Size) != len(req.Data) {
+			return invalidArgument("size %d does not match %d bytes of data", req.Size, len(req.Data))
+		}
+		return firstError(checkHeader(req.Header), checkXAttrName(req.Attr), checkXAttrSize("data", len(req.Data)))
+	case *pb.RemoveXAttrRequest:
+		return firstError(checkHeader(req.Header), checkXAttrName(req.Attr))
+	case *pb.CreateRequest:
+		return firstError(checkHeader(req.Header), checkName("name", req.Name, false))
+	case *pb.OpenRequest:
+		return checkOpenIn(req.OpenIn)
+	case *pb.ReadRequest:
+		return checkReadIn(req.ReadIn)
+	case *pb.LseekRequest:
+		return checkHeader(req.Header)
+	case *pb.LkRequest:
+		if req.Lk == nil {
+			return firstError(checkHeader(req.Header), invalidArgument("missing lk"))
+		}
+		return checkHeader(req.Header)
+	case *pb.ReleaseRequest:
+		return checkHeader(req.Header)
+	case *pb.WriteRequest:
+		if len(req.Data) > maxIOSize {
+			return invalidArgument("data of %d bytes exceeds %d", len(req.Data), maxIOSize)
+		}
+		return checkHeader(req.Header)
+	case *pb.CopyFileRangeRequest:
+		return firstError(checkHeader(req.Header), checkNodeID("node_id_out", req.NodeIdOut))
+	case *pb.FlushRequest:
+		return checkHeader(req.Header)
+	case *pb.FsyncRequest:
+		return checkHeader(req.Header)
+	case *pb.FallocateRequest:
+		return checkHeader(req.Header)
+	case *pb.OpenDirRequest:
+		return checkOpenIn(req.OpenIn)
+	case *pb.ReadDirRequest:
+		return checkReadIn(req.ReadIn)
+	case *pb.StatfsRequest:
+		return checkHeader(req.Input)
+	case *pb.CompoundRequest:
+		return checkCompound(req)
+	}
+	return invalidArgument("unexpected request %T", req)
+}
+
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkNodeID(field string, nodeID uint64) error {
+	if nodeID == 0 {
+		return invalidArgument("invalid %s 0", field)
+	}
+	return nil
+}
+
+func checkHeader(header *pb.InHeader) error {
+	if header == nil {
+		return invalidArgument("missing header")
+	}
+	// The caller is who the file system checks permissions against, so
+	// it may not default to root.
+	if header.Caller == nil || header.Caller.Owner == nil {
+		return invalidArgument("missing caller")
+	}
+	return checkNodeID("node id", header.NodeId)
+}
+
+func checkOpenIn(in *pb.OpenIn) error {
+	if in == nil {
+		return invalidArgument("missing open_in")
+	}
+	return checkHeader(in.Header)
+}
+
+func checkReadIn(in *pb.ReadIn) error {
+	if in == nil {
+		return invalidArgument("missing read_in")
+	}
+	if in.Size > maxIOSize {
+		return invalidArgument("read size %d exceeds %d", in.Size, maxIOSize)
+	}
+	return checkHeader(in.Header)
+}
+
+// checkCompound checks that each op of req is set and takes its node ID
+// and handle from an earlier op that yields them. The ops themselves are
+// checked as they run.
+func checkCompound(req *pb.CompoundRequest) error {
+	if len(req.Ops) == 0 {
+		return invalidArgument("no ops")
+	}
+	if len(req.Ops) > maxCompoundOps {
+		return invalidArgument("%d ops exceed %d", len(req.Ops), maxCompoundOps)
+	}
+	var data int
+	for i, op := range req.Ops {
+		n := i + 1
+		if op.GetOp() == nil {
+			return invalidArgument("op %d is empty", n)
+		}
+		if from := int(op.NodeFrom); from != 0 {
+			if from >= n {
+				return invalidArgument("op %d takes the node of op %d, which is not before it", n, from)
+			}
+			if node, _ := produces(req.Ops[from-1]); !node {
+				return invalidArgument("op %d takes the node of op %d, which yields none", n, from)
+			}
+		}
+		if from := int(op.FhFrom); from != 0 {
+			if !takesFh(op) {
+				return invalidArgument("op %d takes no handle", n)
+			}
+			if from >= n {
+				return invalidArgument("op %d takes the handle of op %d, which is not before it", n, from)
+			}
+			if _, fh := produces(req.Ops[from-1]); !fh {
+				return invalidArgument("op %d takes the handle of op %d, which yields none", n, from)
+			}
+		}
+		data += len(op.GetWrite().GetData()) + int(op.GetRead().GetReadIn().GetSize())
+	}
+	if data > maxCompoundIO {
+		return invalidArgument("%d bytes of data exceed %d", data, maxCompoundIO)
+	}
+	return nil
+}
+
+// checkName checks a single path component. "." and ".." only name an
+// existing entry, so they are only allowed where dots is set.
+func checkName(field, name string, dots bool) error {
+	switch {
+	case name == "":
+		return invalidArgument("empty %s", field)
+	case len(name) > maxNameLen:
+		return invalidArgument("%s of %d bytes exceeds %d", field, len(name), maxNameLen)
+	case strings.ContainsAny(name, "/\x00"):
+		return invalidArgument("%s %q contains '/' or NUL", field, name)
+	case !dots && (name == "." || name == ".."):
+		return invalidArgument("%s may not be %q", field, name)
+	}
+	return nil
+}
+
+func checkSymlinkTarget(target string) error {
+	switch {
+	case target == "":
+		return invalidArgument("empty pointed_to")
+	case len(target) > maxSymlinkLen:
+		return invalidArgument("pointed_to of %d bytes exceeds %d", len(target), maxSymlinkLen)
+	case strings.IndexByte(target, 0) >= 0:
+		return invalidArgument("pointed_to contains NUL")
+	}
+	return nil
+}
+
+func checkXAttrName(name string) error {
+	switch {
+	case name == "":
+		return invalidArgument("empty attr")
+	case len(name) > maxXAttrNameLen:
+		return invalidArgument("attr of %d bytes exceeds %d", len(name), maxXAttrNameLen)
+	case strings.IndexByte(name, 0) >= 0:
+		return invalidArgument("attr contains NUL")
+	}
+	return nil
+}
+
+func checkXAttrSize(field string, size int) error {
+	if size > maxXAttrSize {
+		return invalidArgument("%s of %d bytes exceeds %d", field, size, maxXAttrSize)
+	}
+	return nil
+}
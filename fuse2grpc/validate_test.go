@@ -0,0 +1,216 @@
+package fuse2grpc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+var validHeader = &pb.InHeader{NodeId: 1, Caller: &pb.Caller{Owner: &pb.Owner{Uid: 1000, Gid: 1000}}}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     interface{}
+		wantErr string
+	}{
+		{name: "lookup", req: &pb.LookupRequest{Header: validHeader, Name: "file"}},
+		{name: "lookup dotdot", req: &pb.LookupRequest{Header: validHeader, Name: ".."}},
+		{name: "lookup without header", req: &pb.LookupRequest{Name: "file"}, wantErr: "missing header"},
+		{name: "lookup without caller", req: &pb.LookupRequest{Header: &pb.InHeader{NodeId: 1}, Name: "file"}, wantErr: "missing caller"},
+		{
+			name:    "lookup without owner",
+			req:     &pb.LookupRequest{Header: &pb.InHeader{NodeId: 1, Caller: &pb.Caller{}}, Name: "file"},
+			wantErr: "missing caller",
+		},
+		{
+			name:    "lookup node 0",
+			req:     &pb.LookupRequest{Header: &pb.InHeader{Caller: validHeader.Caller}, Name: "file"},
+			wantErr: "invalid node id 0",
+		},
+		{name: "lookup empty name", req: &pb.LookupRequest{Header: validHeader}, wantErr: "empty name"},
+		{name: "lookup slash", req: &pb.LookupRequest{Header: validHeader, Name: "a/b"}, wantErr: "contains '/' or NUL"},
+		{name: "lookup nul", req: &pb.LookupRequest{Header: validHeader, Name: "a\x00"}, wantErr: "contains '/' or NUL"},
+		{
+			name:    "lookup long name",
+			req:     &pb.LookupRequest{Header: validHeader, Name: strings.Repeat("a", maxNameLen+1)},
+			wantErr: "exceeds 1024",
+		},
+		{name: "forget", req: &pb.ForgetRequest{Nodeid: 2, Nlookup: 1}},
+		{name: "forget node 0", req: &pb.ForgetRequest{Nlookup: 1}, wantErr: "invalid nodeid 0"},
+		{name: "mkdir dot", req: &pb.MkdirRequest{Header: validHeader, Name: "."}, wantErr: `may not be "."`},
+		{name: "unlink dotdot", req: &pb.UnlinkRequest{Header: validHeader, Name: ".."}, wantErr: `may not be ".."`},
+		{name: "rename", req: &pb.RenameRequest{Header: validHeader, OldName: "a", NewName: "b", Newdir: 1}},
+		{name: "rename newdir 0", req: &pb.RenameRequest{Header: validHeader, OldName: "a", NewName: "b"}, wantErr: "invalid newdir 0"},
+		{name: "rename bad new name", req: &pb.RenameRequest{Header: validHeader, OldName: "a", NewName: "b/c", Newdir: 1}, wantErr: "new_name"},
+		{name: "link", req: &pb.LinkRequest{Header: validHeader, Oldnodeid: 2, Filename: "b"}},
+		{name: "link old node 0", req: &pb.LinkRequest{Header: validHeader, Filename: "b"}, wantErr: "invalid oldnodeid 0"},
+		{name: "symlink", req: &pb.SymlinkRequest{Header: validHeader, LinkName: "l", PointedTo: "../a/b"}},
+		{name: "symlink empty target", req: &pb.SymlinkRequest{Header: validHeader, LinkName: "l"}, wantErr: "empty pointed_to"},
+		{
+			name:    "symlink long target",
+			req:     &pb.SymlinkRequest{Header: validHeader, LinkName: "l", PointedTo: strings.Repeat("a", maxSymlinkLen+1)},
+			wantErr: "exceeds 4096",
+		},
+		{name: "setattr owner", req: &pb.SetAttrRequest{Header: validHeader, Valid: fuse.FATTR_UID}, wantErr: "missing owner"},
+		{name: "setattr mode", req: &pb.SetAttrRequest{Header: validHeader, Valid: fuse.FATTR_MODE}},
+		{name: "getxattr", req: &pb.GetXAttrRequest{Header: validHeader, Attr: "user.a"}},
+		{name: "getxattr empty", req: &pb.GetXAttrRequest{Header: validHeader}, wantErr: "empty attr"},
+		{
+			name:    "getxattr long name",
+			req:     &pb.GetXAttrRequest{Header: validHeader, Attr: strings.Repeat("a", maxXAttrNameLen+1)},
+			wantErr: "exceeds 255",
+		},
+		{
+			name:    "getxattr too large",
+			req:     &pb.GetXAttrRequest{Header: validHeader, Attr: "user.a", Size: maxXAttrSize + 1},
+			wantErr: "size of 65537 bytes exceeds 65536",
+		},
+		{name: "listxattr too large", req: &pb.ListXAttrRequest{Header: validHeader, Size: 1 << 31}, wantErr: "exceeds 65536"},
+		{
+			name:    "setxattr size mismatch",
+			req:     &pb.SetXAttrRequest{Header: validHeader, Attr: "user.a", Data: []byte("abc"), Size: 2},
+			wantErr: "does not match",
+		},
+		{
+			name:    "setxattr too large",
+			req:     &pb.SetXAttrRequest{Header: validHeader, Attr: "user.a", Data: make([]byte, maxXAttrSize+1), Size: maxXAttrSize + 1},
+			wantErr: "exceeds 65536",
+		},
+		{name: "open", req: &pb.OpenRequest{OpenIn: &pb.OpenIn{Header: validHeader}}},
+		{name: "open without open_in", req: &pb.OpenRequest{}, wantErr: "missing open_in"},
+		{name: "read", req: &pb.ReadRequest{ReadIn: &pb.ReadIn{Header: validHeader, Size: 4096}}},
+		{name: "read without read_in", req: &pb.ReadRequest{}, wantErr: "missing read_in"},
+		{name: "read too large", req: &pb.ReadRequest{ReadIn: &pb.ReadIn{Header: validHeader, Size: maxIOSize + 1}}, wantErr: "read size"},
+		{name: "readdir too large", req: &pb.ReadDirRequest{ReadIn: &pb.ReadIn{Header: validHeader, Size: 1 << 31}}, wantErr: "read size"},
+		{name: "write too large", req: &pb.WriteRequest{Header: validHeader, Data: make([]byte, maxIOSize+1)}, wantErr: "data of"},
+		{name: "lk without lk", req: &pb.LkRequest{Header: validHeader}, wantErr: "missing lk"},
+		{name: "copy file range", req: &pb.CopyFileRangeRequest{Header: validHeader}, wantErr: "invalid node_id_out 0"},
+		{name: "statfs", req: &pb.StatfsRequest{Input: validHeader}},
+		{name: "statfs without input", req: &pb.StatfsRequest{}, wantErr: "missing header"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate(tt.req)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestHandlersRejectEmptyRequests(t *testing.T) {
+	s := NewServer(fuse.NewDefaultRawFileSystem())
+	desc := pb.RawFileSystem_ServiceDesc
+	for _, m := range desc.Methods {
+		if m.MethodName == "String" {
+			continue
+		}
+		t.Run(m.MethodName, func(t *testing.T) {
+			_, err := m.Handler(s, context.Background(), func(interface{}) error { return nil }, nil)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err), "%v", err)
+		})
+	}
+	for _, sd := range desc.Streams {
+		t.Run(sd.StreamName, func(t *testing.T) {
+			err := sd.Handler(s, &fuzzStream{})
+			assert.Equal(t, codes.InvalidArgument, status.Code(err), "%v", err)
+		})
+	}
+}
+
+// fuzzFS succeeds wherever that makes the server do more work.
+type fuzzFS struct {
+	fuse.RawFileSystem
+}
+
+func (fs *fuzzFS) Lookup(cancel <-chan struct{}, header *fuse.InHeader, name string, out *fuse.EntryOut) fuse.Status {
+	out.NodeId = header.NodeId + 1
+	return fuse.OK
+}
+
+func (fs *fuzzFS) Read(cancel <-chan struct{}, input *fuse.ReadIn, buf []byte) (fuse.ReadResult, fuse.Status) {
+	return fuse.ReadResultData(buf), fuse.OK
+}
+
+func (fs *fuzzFS) ReadDir(cancel <-chan struct{}, input *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
+	out.AddDirEntry(fuse.DirEntry{Name: "a", Ino: 2, Mode: fuse.S_IFREG})
+	return fuse.OK
+}
+
+func (fs *fuzzFS) ReadDirPlus(cancel <-chan struct{}, input *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
+	if entryOut := out.AddDirLookupEntry(fuse.DirEntry{Name: "a", Ino: 2, Mode: fuse.S_IFREG}); entryOut != nil {
+		entryOut.NodeId = 2
+	}
+	return fuse.OK
+}
+
+func (fs *fuzzFS) GetXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string, dest []byte) (uint32, fuse.Status) {
+	return uint32(len(dest)), fuse.OK
+}
+
+func (fs *fuzzFS) ListXAttr(cancel <-chan struct{}, header *fuse.InHeader, dest []byte) (uint32, fuse.Status) {
+	return uint32(len(dest)), fuse.OK
+}
+
+// fuzzStream feeds data to a streaming handler as its request.
+type fuzzStream struct {
+	data []byte
+}
+
+func (s *fuzzStream) SetHeader(metadata.MD) error  { return nil }
+func (s *fuzzStream) SendHeader(metadata.MD) error { return nil }
+func (s *fuzzStream) SetTrailer(metadata.MD)       {}
+func (s *fuzzStream) Context() context.Context     { return context.Background() }
+func (s *fuzzStream) SendMsg(m interface{}) error  { return nil }
+func (s *fuzzStream) RecvMsg(m interface{}) error {
+	return proto.Unmarshal(s.data, m.(proto.Message))
+}
+
+var _ grpc.ServerStream = (*fuzzStream)(nil)
+
+// FuzzServer calls every RPC with a random protobuf, which must never
+// panic the server.
+func FuzzServer(f *testing.F) {
+	seeds := []proto.Message{
+		&pb.LookupRequest{Header: validHeader, Name: "file"},
+		&pb.ReadRequest{ReadIn: &pb.ReadIn{Header: validHeader, Size: 4096}},
+		&pb.ReadDirRequest{ReadIn: &pb.ReadIn{Header: validHeader, Size: 4096}},
+		&pb.SetXAttrRequest{Header: validHeader, Attr: "user.a", Data: []byte("v"), Size: 1},
+		&pb.LkRequest{Header: validHeader, Lk: &pb.FileLock{}},
+		&pb.StatfsRequest{Input: validHeader},
+	}
+	desc := pb.RawFileSystem_ServiceDesc
+	for i := range desc.Methods {
+		for _, seed := range seeds {
+			data, _ := proto.Marshal(seed)
+			f.Add(uint8(i), data)
+		}
+	}
+
+	s := NewServer(&fuzzFS{RawFileSystem: fuse.NewDefaultRawFileSystem()})
+	s.SetMsgSizeThreshold(1024)
+	f.Fuzz(func(t *testing.T, method uint8, data []byte) {
+		n := int(method) % (len(desc.Methods) + len(desc.Streams))
+		if n < len(desc.Methods) {
+			dec := func(m interface{}) error { return proto.Unmarshal(data, m.(proto.Message)) }
+			desc.Methods[n].Handler(s, context.Background(), dec, nil)
+			return
+		}
+		desc.Streams[n-len(desc.Methods)].Handler(s, &fuzzStream{data: data})
+	})
+}
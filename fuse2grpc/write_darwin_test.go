@@ -14,21 +14,22 @@ import (
 	"github.com/chiyutianyi/grpcfuse/pb"
 )
 
-type MockRawFileSystem struct {
+type writeDarwinFS struct {
 	mock.Mock
+	fuse.RawFileSystem
 }
 
-func (m *MockRawFileSystem) Write(cancel <-chan struct{}, input *fuse.WriteIn, data []byte) (written uint32, code fuse.Status) {
+func (m *writeDarwinFS) Write(cancel <-chan struct{}, input *fuse.WriteIn, data []byte) (written uint32, code fuse.Status) {
 	args := m.Called(cancel, input, data)
 	return args.Get(0).(uint32), args.Get(1).(fuse.Status)
 }
 
-func (m *MockRawFileSystem) String() string {
-	return "MockRawFileSystem"
+func (m *writeDarwinFS) String() string {
+	return "writeDarwinFS"
 }
 
 func TestWrite(t *testing.T) {
-	mockFS := &MockRawFileSystem{}
+	mockFS := &writeDarwinFS{}
 	server := fuse2grpc.NewServer(mockFS)
 
 	tests := []struct {
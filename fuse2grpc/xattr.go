@@ -29,41 +29,68 @@ import (
 )
 
 func (s *server) GetXAttr(ctx context.Context, req *pb.GetXAttrRequest) (*pb.GetXAttrResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	var (
 		header fuse.InHeader
 	)
 	grpc_logrus.Extract(ctx).WithFields(log.Fields{
 		"nodeId": req.Header.NodeId,
 		"attr":   req.Attr,
-		"dest":   req.Dest,
+		"size":   req.Size,
 	}).Debug("GetXAttr")
 	toFuseInHeader(req.Header, &header)
 
-	sz, st := s.fs.GetXAttr(ctx.Done(), &header, req.Attr, req.Dest)
+	dest := make([]byte, req.Size)
+	sz, st := s.fs.GetXAttr(ctx.Done(), &header, req.Attr, dest)
 	if st == fuse.ENOSYS {
 		return nil, status.Errorf(codes.Unimplemented, "method GetXAttr not implemented")
 	}
-	return &pb.GetXAttrResponse{Size: sz, Status: &pb.Status{Code: int32(st)}}, nil
+	value, st := xattrValue(dest, sz, st)
+	return &pb.GetXAttrResponse{Size: sz, Value: value, Status: &pb.Status{Code: int32(st)}}, nil
 }
 
 func (s *server) ListXAttr(ctx context.Context, req *pb.ListXAttrRequest) (*pb.ListXAttrResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	var (
 		header fuse.InHeader
 	)
 	grpc_logrus.Extract(ctx).WithFields(log.Fields{
 		"nodeId": req.Header.NodeId,
-		"dest":   req.Dest,
+		"size":   req.Size,
 	}).Debug("ListXAttr")
 	toFuseInHeader(req.Header, &header)
 
-	sz, st := s.fs.ListXAttr(ctx.Done(), &header, req.Dest)
+	dest := make([]byte, req.Size)
+	sz, st := s.fs.ListXAttr(ctx.Done(), &header, dest)
 	if st == fuse.ENOSYS {
 		return nil, status.Errorf(codes.Unimplemented, "method ListXAttr not implemented")
 	}
-	return &pb.ListXAttrResponse{Size: sz, Status: &pb.Status{Code: int32(st)}}, nil
+	value, st := xattrValue(dest, sz, st)
+	return &pb.ListXAttrResponse{Size: sz, Value: value, Status: &pb.Status{Code: int32(st)}}, nil
+}
+
+// xattrValue returns the bytes of dest a successful GetXAttr or ListXAttr
+// filled in. A size probe (empty dest) only returns the size, and a file
+// system claiming more bytes than dest holds gets ERANGE, like the kernel
+// would report.
+func xattrValue(dest []byte, sz uint32, st fuse.Status) ([]byte, fuse.Status) {
+	if st != fuse.OK || len(dest) == 0 {
+		return nil, st
+	}
+	if int(sz) > len(dest) {
+		return nil, fuse.ERANGE
+	}
+	return dest[:sz], fuse.OK
 }
 
 func (s *server) RemoveXAttr(ctx context.Context, req *pb.RemoveXAttrRequest) (*pb.RemoveXAttrResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
 	var (
 		header fuse.InHeader
 	)
@@ -14,24 +14,25 @@ import (
 	"github.com/chiyutianyi/grpcfuse/pb"
 )
 
-type mockFS struct {
+type xattrDarwinFS struct {
 	mock.Mock
+	fuse.RawFileSystem
 }
 
-func (m *mockFS) SetXAttr(cancel <-chan struct{}, in *fuse.SetXAttrIn, attr string, data []byte) fuse.Status {
+func (m *xattrDarwinFS) SetXAttr(cancel <-chan struct{}, in *fuse.SetXAttrIn, attr string, data []byte) fuse.Status {
 	args := m.Called(cancel, in, attr, data)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *mockFS) String() string {
-	return "mockFS"
+func (m *xattrDarwinFS) String() string {
+	return "xattrDarwinFS"
 }
 
 func TestSetXAttr(t *testing.T) {
 	tests := []struct {
 		name        string
 		input       *pb.SetXAttrRequest
-		setupMock   func(*mockFS)
+		setupMock   func(*xattrDarwinFS)
 		expectError error
 		expectResp  *pb.SetXAttrResponse
 	}{
@@ -54,7 +55,7 @@ func TestSetXAttr(t *testing.T) {
 				Position: 0,
 				Padding:  0,
 			},
-			setupMock: func(m *mockFS) {
+			setupMock: func(m *xattrDarwinFS) {
 				m.On("SetXAttr",
 					mock.Anything,
 					mock.MatchedBy(func(in *fuse.SetXAttrIn) bool {
@@ -74,7 +75,7 @@ func TestSetXAttr(t *testing.T) {
 				Header: &pb.InHeader{NodeId: 1},
 				Attr:   "user.test",
 			},
-			setupMock: func(m *mockFS) {
+			setupMock: func(m *xattrDarwinFS) {
 				m.On("SetXAttr",
 					mock.Anything,
 					mock.Anything,
@@ -90,7 +91,7 @@ func TestSetXAttr(t *testing.T) {
 				Header: &pb.InHeader{NodeId: 1},
 				Attr:   "user.test",
 			},
-			setupMock: func(m *mockFS) {
+			setupMock: func(m *xattrDarwinFS) {
 				m.On("SetXAttr",
 					mock.Anything,
 					mock.Anything,
@@ -106,7 +107,7 @@ func TestSetXAttr(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockFs := &mockFS{}
+			mockFs := &xattrDarwinFS{}
 			tt.setupMock(mockFs)
 
 			server := fuse2grpc.NewServer(mockFs)
@@ -10,20 +10,20 @@ import (
 	"github.com/chiyutianyi/grpcfuse/pb"
 )
 
-type MockRawFileSystem struct {
+type xattrLinuxFS struct {
 	fuse.RawFileSystem
 }
 
-func (m *MockRawFileSystem) SetXAttr(cancel <-chan struct{}, in *fuse.SetXAttrIn, attr string, data []byte) fuse.Status {
+func (m *xattrLinuxFS) SetXAttr(cancel <-chan struct{}, in *fuse.SetXAttrIn, attr string, data []byte) fuse.Status {
 	return fuse.OK
 }
 
-func (m *MockRawFileSystem) String() string {
-	return "MockRawFileSystem"
+func (m *xattrLinuxFS) String() string {
+	return "xattrLinuxFS"
 }
 
 func TestSetXAttr_Success(t *testing.T) {
-	mockFS := &MockRawFileSystem{}
+	mockFS := &xattrLinuxFS{}
 	server := fuse2grpc.NewServer(mockFS)
 
 	req := &pb.SetXAttrRequest{
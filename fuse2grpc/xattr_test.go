@@ -14,37 +14,38 @@ import (
 	"github.com/chiyutianyi/grpcfuse/pb"
 )
 
-type mockFS struct {
+type xattrFS struct {
 	mock.Mock
 	fuse.RawFileSystem
 }
 
-func (m *mockFS) GetXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string, dest []byte) (uint32, fuse.Status) {
+func (m *xattrFS) GetXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string, dest []byte) (uint32, fuse.Status) {
 	args := m.Called(cancel, header, attr, dest)
 	return args.Get(0).(uint32), args.Get(1).(fuse.Status)
 }
 
-func (m *mockFS) ListXAttr(cancel <-chan struct{}, header *fuse.InHeader, dest []byte) (uint32, fuse.Status) {
+func (m *xattrFS) ListXAttr(cancel <-chan struct{}, header *fuse.InHeader, dest []byte) (uint32, fuse.Status) {
 	args := m.Called(cancel, header, dest)
 	return args.Get(0).(uint32), args.Get(1).(fuse.Status)
 }
 
-func (m *mockFS) RemoveXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string) fuse.Status {
+func (m *xattrFS) RemoveXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string) fuse.Status {
 	args := m.Called(cancel, header, attr)
 	return args.Get(0).(fuse.Status)
 }
 
-func (m *mockFS) String() string {
-	return "mockFS"
+func (m *xattrFS) String() string {
+	return "xattrFS"
 }
 
 func TestGetXAttr(t *testing.T) {
-	mockfs := &mockFS{}
+	mockfs := &xattrFS{}
 	server := fuse2grpc.NewServer(mockfs)
 
 	tests := []struct {
 		name     string
 		req      *pb.GetXAttrRequest
+		value    []byte
 		mockResp []interface{}
 		want     *pb.GetXAttrResponse
 		wantErr  error
@@ -63,14 +64,81 @@ func TestGetXAttr(t *testing.T) {
 					},
 				},
 				Attr: "user.test",
-				Dest: []byte("test"),
+				Size: 16,
 			},
+			value:    []byte("test"),
 			mockResp: []interface{}{uint32(4), fuse.OK},
 			want: &pb.GetXAttrResponse{
 				Size:   4,
+				Value:  []byte("test"),
 				Status: &pb.Status{Code: 0},
 			},
 		},
+		{
+			name: "size probe",
+			req: &pb.GetXAttrRequest{
+				Header: &pb.InHeader{
+					NodeId: 1,
+					Caller: &pb.Caller{
+						Owner: &pb.Owner{
+							Uid: 1000,
+							Gid: 1000,
+						},
+						Pid: 1234,
+					},
+				},
+				Attr: "user.test",
+			},
+			mockResp: []interface{}{uint32(4), fuse.OK},
+			want: &pb.GetXAttrResponse{
+				Size:   4,
+				Status: &pb.Status{Code: 0},
+			},
+		},
+		{
+			name: "buffer too small",
+			req: &pb.GetXAttrRequest{
+				Header: &pb.InHeader{
+					NodeId: 1,
+					Caller: &pb.Caller{
+						Owner: &pb.Owner{
+							Uid: 1000,
+							Gid: 1000,
+						},
+						Pid: 1234,
+					},
+				},
+				Attr: "user.test",
+				Size: 2,
+			},
+			mockResp: []interface{}{uint32(4), fuse.ERANGE},
+			want: &pb.GetXAttrResponse{
+				Size:   4,
+				Status: &pb.Status{Code: int32(fuse.ERANGE)},
+			},
+		},
+		{
+			name: "size beyond buffer",
+			req: &pb.GetXAttrRequest{
+				Header: &pb.InHeader{
+					NodeId: 1,
+					Caller: &pb.Caller{
+						Owner: &pb.Owner{
+							Uid: 1000,
+							Gid: 1000,
+						},
+						Pid: 1234,
+					},
+				},
+				Attr: "user.test",
+				Size: 2,
+			},
+			mockResp: []interface{}{uint32(4), fuse.OK},
+			want: &pb.GetXAttrResponse{
+				Size:   4,
+				Status: &pb.Status{Code: int32(fuse.ERANGE)},
+			},
+		},
 		{
 			name: "not implemented",
 			req: &pb.GetXAttrRequest{
@@ -114,7 +182,9 @@ func TestGetXAttr(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockfs.On("GetXAttr", mock.Anything, mock.Anything, tt.req.Attr, tt.req.Dest).Return(tt.mockResp...).Once()
+			mockfs.On("GetXAttr", mock.Anything, mock.Anything, tt.req.Attr, make([]byte, tt.req.Size)).Run(func(args mock.Arguments) {
+				copy(args.Get(3).([]byte), tt.value)
+			}).Return(tt.mockResp...).Once()
 
 			got, err := server.GetXAttr(context.Background(), tt.req)
 			if tt.wantErr != nil {
@@ -130,12 +200,13 @@ func TestGetXAttr(t *testing.T) {
 }
 
 func TestListXAttr(t *testing.T) {
-	mockfs := &mockFS{}
+	mockfs := &xattrFS{}
 	server := fuse2grpc.NewServer(mockfs)
 
 	tests := []struct {
 		name     string
 		req      *pb.ListXAttrRequest
+		value    []byte
 		mockResp []interface{}
 		want     *pb.ListXAttrResponse
 		wantErr  error
@@ -153,7 +224,29 @@ func TestListXAttr(t *testing.T) {
 						Pid: 1234,
 					},
 				},
-				Dest: []byte("test"),
+				Size: 16,
+			},
+			value:    []byte("a\x00b\x00"),
+			mockResp: []interface{}{uint32(4), fuse.OK},
+			want: &pb.ListXAttrResponse{
+				Size:   4,
+				Value:  []byte("a\x00b\x00"),
+				Status: &pb.Status{Code: 0},
+			},
+		},
+		{
+			name: "size probe",
+			req: &pb.ListXAttrRequest{
+				Header: &pb.InHeader{
+					NodeId: 1,
+					Caller: &pb.Caller{
+						Owner: &pb.Owner{
+							Uid: 1000,
+							Gid: 1000,
+						},
+						Pid: 1234,
+					},
+				},
 			},
 			mockResp: []interface{}{uint32(4), fuse.OK},
 			want: &pb.ListXAttrResponse{
@@ -161,6 +254,27 @@ func TestListXAttr(t *testing.T) {
 				Status: &pb.Status{Code: 0},
 			},
 		},
+		{
+			name: "buffer too small",
+			req: &pb.ListXAttrRequest{
+				Header: &pb.InHeader{
+					NodeId: 1,
+					Caller: &pb.Caller{
+						Owner: &pb.Owner{
+							Uid: 1000,
+							Gid: 1000,
+						},
+						Pid: 1234,
+					},
+				},
+				Size: 2,
+			},
+			mockResp: []interface{}{uint32(4), fuse.ERANGE},
+			want: &pb.ListXAttrResponse{
+				Size:   4,
+				Status: &pb.Status{Code: int32(fuse.ERANGE)},
+			},
+		},
 		{
 			name: "not implemented",
 			req: &pb.ListXAttrRequest{
@@ -202,7 +316,9 @@ func TestListXAttr(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockfs.On("ListXAttr", mock.Anything, mock.Anything, tt.req.Dest).Return(tt.mockResp...).Once()
+			mockfs.On("ListXAttr", mock.Anything, mock.Anything, make([]byte, tt.req.Size)).Run(func(args mock.Arguments) {
+				copy(args.Get(2).([]byte), tt.value)
+			}).Return(tt.mockResp...).Once()
 
 			got, err := server.ListXAttr(context.Background(), tt.req)
 			if tt.wantErr != nil {
@@ -218,7 +334,7 @@ func TestListXAttr(t *testing.T) {
 }
 
 func TestRemoveXAttr(t *testing.T) {
-	mockfs := &mockFS{}
+	mockfs := &xattrFS{}
 	server := fuse2grpc.NewServer(mockfs)
 
 	tests := []struct {
@@ -0,0 +1,465 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc2fuse
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+)
+
+const defaultAttrCacheSize = 64 << 10
+
+// AttrCacheOptions configures the cache of attributes, names and symlinks
+// kept by the client itself, for callers that do not go through the
+// kernel's cache and for what the kernel does not cache. A zero timeout
+// leaves that part uncached.
+type AttrCacheOptions struct {
+	// AttrTimeout is how long the attributes of a node are kept, and
+	// EntryTimeout how long a name found is. A name is only answered
+	// from the cache while the attributes of its node are too.
+	AttrTimeout  time.Duration
+	EntryTimeout time.Duration
+	// NegativeTimeout is how long a name is known not to exist.
+	NegativeTimeout time.Duration
+	// ReadlinkTimeout is how long the target of a symlink is kept.
+	ReadlinkTimeout time.Duration
+	// Size bounds the attributes, names and symlinks kept, each, 64K by
+	// default.
+	Size int
+}
+
+// AttrCacheStats counts the answers of the attribute cache.
+type AttrCacheStats struct {
+	Hits, Misses uint64
+}
+
+// attrCache answers GetAttr, Lookup and Readlink from earlier replies
+// until they expire. Changes made through fs drop what they touch; the
+// changes of others show once the replies expire, or once invalidated.
+//
+// Lookups answered from the cache are lent: the server did not count
+// them, so they are taken off the Forget of the node before it is passed
+// on.
+type attrCache struct {
+	opts AttrCacheOptions
+	now  func() time.Time
+
+	mu sync.Mutex
+	// gen counts the invalidations, so that replies to calls started
+	// before one are not kept.
+	gen     uint64
+	attrs   map[uint64]cachedAttr
+	entries map[entryKey]cachedEntry
+	links   map[uint64]cachedLink
+	// names are the entries of each node, dropped when it is forgotten.
+	names map[uint64][]entryKey
+	lent  map[uint64]uint64
+	stats AttrCacheStats
+}
+
+type entryKey struct {
+	parent uint64
+	name   string
+}
+
+type cachedAttr struct {
+	attr    fuse.Attr
+	expires time.Time
+}
+
+// cachedEntry is a name found, or known not to exist if node is 0.
+type cachedEntry struct {
+	out     fuse.EntryOut
+	expires time.Time
+}
+
+type cachedLink struct {
+	target  []byte
+	expires time.Time
+}
+
+// SetAttrCache makes fs cache attributes, names and symlinks as opts
+// says. Nil or all timeouts 0 turns the cache off. It must be called
+// before fs is used.
+func (fs *fileSystem) SetAttrCache(opts *AttrCacheOptions) {
+	if opts == nil || opts.AttrTimeout <= 0 && opts.NegativeTimeout <= 0 && opts.ReadlinkTimeout <= 0 {
+		fs.attrCache = nil
+		return
+	}
+	fs.attrCache = newAttrCache(*opts)
+}
+
+func newAttrCache(opts AttrCacheOptions) *attrCache {
+	if opts.Size <= 0 {
+		opts.Size = defaultAttrCacheSize
+	}
+	return &attrCache{
+		opts:    opts,
+		now:     time.Now,
+		attrs:   map[uint64]cachedAttr{},
+		entries: map[entryKey]cachedEntry{},
+		links:   map[uint64]cachedLink{},
+		names:   map[uint64][]entryKey{},
+		lent:    map[uint64]uint64{},
+	}
+}
+
+// AttrCacheStats returns the hits and misses of the attribute cache.
+func (fs *fileSystem) AttrCacheStats() AttrCacheStats {
+	c := fs.attrCache
+	if c == nil {
+		return AttrCacheStats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// Init keeps the server, for the invalidations from outside to reach the
+// kernel.
+func (fs *fileSystem) Init(server *fuse.Server) {
+	fs.mu.Lock()
+	fs.server = server
+	fs.mu.Unlock()
+	fs.RawFileSystem.Init(server)
+}
+
+func (fs *fileSystem) mounted() *fuse.Server {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.server
+}
+
+// notified maps ENOENT, of a node or name the kernel does not have, to
+// OK.
+func notified(st fuse.Status) fuse.Status {
+	if st == fuse.ENOENT {
+		return fuse.OK
+	}
+	return st
+}
+
+// InvalidateNode drops the attributes and symlink target of node, for a
+// server telling that it changed, here and, once mounted, in the kernel.
+func (fs *fileSystem) InvalidateNode(node uint64) fuse.Status {
+	fs.attrCache.invalidate(node)
+	if server := fs.mounted(); server != nil {
+		return notified(server.InodeNotify(node, -1, 0))
+	}
+	return fuse.OK
+}
+
+// InvalidateEntry drops name in parent, found or not, for a server
+// telling that it changed, here and, once mounted, in the kernel.
+func (fs *fileSystem) InvalidateEntry(parent uint64, name string) fuse.Status {
+	fs.attrCache.invalidateEntry(parent, name)
+	if server := fs.mounted(); server != nil {
+		return notified(server.EntryNotify(parent, name))
+	}
+	return fuse.OK
+}
+
+// InvalidateAll drops everything cached, for a server that lost track
+// of what it told. The kernel cannot be told to drop everything, so it
+// keeps what it has until its own timeouts; InvalidateNode and
+// InvalidateEntry reach it for one node or name.
+func (fs *fileSystem) InvalidateAll() {
+	fs.attrCache.clear()
+}
+
+// generation returns the generation replies are kept under.
+func (c *attrCache) generation() uint64 {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gen
+}
+
+func (c *attrCache) count(hit bool) {
+	if hit {
+		c.stats.Hits++
+	} else {
+		c.stats.Misses++
+	}
+}
+
+// getAttr returns the attributes of node if they are cached.
+func (c *attrCache) getAttr(node uint64, out *fuse.AttrOut) bool {
+	if c == nil || c.opts.AttrTimeout <= 0 {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	a, ok := c.attrs[node]
+	ok = ok && c.now().Before(a.expires)
+	c.count(ok)
+	if !ok {
+		return false
+	}
+	out.Attr = a.attr
+	out.SetTimeout(a.expires.Sub(c.now()))
+	return true
+}
+
+// putAttr keeps the attributes of node, unless something was invalidated
+// since gen.
+func (c *attrCache) putAttr(gen, node uint64, attr *fuse.Attr) {
+	if c == nil || c.opts.AttrTimeout <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if gen != c.gen {
+		return
+	}
+	c.putAttrLocked(node, attr)
+}
+
+func (c *attrCache) putAttrLocked(node uint64, attr *fuse.Attr) {
+	if _, ok := c.attrs[node]; !ok && len(c.attrs) >= c.opts.Size {
+		now := c.now()
+		for n, a := range c.attrs {
+			if !now.Before(a.expires) || len(c.attrs) >= c.opts.Size {
+				delete(c.attrs, n)
+			}
+		}
+	}
+	c.attrs[node] = cachedAttr{attr: *attr, expires: c.now().Add(c.opts.AttrTimeout)}
+}
+
+// lookup answers a lookup of name in parent if it is cached: OK with out
+// set, or ENOENT. A name found is lent to the caller.
+func (c *attrCache) lookup(parent uint64, name string, out *fuse.EntryOut) (fuse.Status, bool) {
+	if c == nil {
+		return fuse.OK, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	e, ok := c.entries[entryKey{parent, name}]
+	ok = ok && now.Before(e.expires)
+	var a cachedAttr
+	if ok && e.out.NodeId != 0 {
+		a, ok = c.attrs[e.out.NodeId]
+		ok = ok && now.Before(a.expires)
+	}
+	c.count(ok)
+	if !ok {
+		return fuse.OK, false
+	}
+	if e.out.NodeId == 0 {
+		return fuse.ENOENT, true
+	}
+	*out = e.out
+	out.Attr = a.attr
+	c.lent[out.NodeId]++
+	return fuse.OK, true
+}
+
+// putEntry keeps what a lookup of name in parent found, unless something
+// was invalidated since gen: out, or that it does not exist if out is
+// nil.
+func (c *attrCache) putEntry(gen, parent uint64, name string, out *fuse.EntryOut) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if gen == c.gen {
+		c.putEntryLocked(parent, name, out)
+	}
+}
+
+func (c *attrCache) putEntryLocked(parent uint64, name string, out *fuse.EntryOut) {
+	timeout := c.opts.EntryTimeout
+	if out == nil {
+		timeout = c.opts.NegativeTimeout
+	} else if c.opts.AttrTimeout <= 0 || out.NodeId == 0 {
+		return
+	}
+	if timeout <= 0 {
+		return
+	}
+	key := entryKey{parent, name}
+	c.dropEntryLocked(key)
+	if len(c.entries) >= c.opts.Size {
+		now := c.now()
+		for k, e := range c.entries {
+			if !now.Before(e.expires) || len(c.entries) >= c.opts.Size {
+				c.dropEntryLocked(k)
+			}
+		}
+	}
+	e := cachedEntry{expires: c.now().Add(timeout)}
+	if out != nil {
+		e.out = *out
+		c.names[out.NodeId] = append(c.names[out.NodeId], key)
+		c.putAttrLocked(out.NodeId, &out.Attr)
+	}
+	c.entries[key] = e
+}
+
+func (c *attrCache) dropEntryLocked(key entryKey) {
+	e, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	delete(c.entries, key)
+	if node := e.out.NodeId; node != 0 {
+		keys := c.names[node]
+		for i, k := range keys {
+			if k == key {
+				keys = append(keys[:i], keys[i+1:]...)
+				break
+			}
+		}
+		if len(keys) == 0 {
+			delete(c.names, node)
+		} else {
+			c.names[node] = keys
+		}
+	}
+}
+
+// readlink returns the target of the symlink node if it is cached.
+func (c *attrCache) readlink(node uint64) ([]byte, bool) {
+	if c == nil || c.opts.ReadlinkTimeout <= 0 {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	l, ok := c.links[node]
+	ok = ok && c.now().Before(l.expires)
+	c.count(ok)
+	if !ok {
+		return nil, false
+	}
+	return append([]byte(nil), l.target...), true
+}
+
+// putLink keeps the target of the symlink node, unless something was
+// invalidated since gen.
+func (c *attrCache) putLink(gen, node uint64, target []byte) {
+	if c == nil || c.opts.ReadlinkTimeout <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if gen != c.gen {
+		return
+	}
+	if _, ok := c.links[node]; !ok && len(c.links) >= c.opts.Size {
+		now := c.now()
+		for n, l := range c.links {
+			if !now.Before(l.expires) || len(c.links) >= c.opts.Size {
+				delete(c.links, n)
+			}
+		}
+	}
+	c.links[node] = cachedLink{target: append([]byte(nil), target...), expires: c.now().Add(c.opts.ReadlinkTimeout)}
+}
+
+// invalidate drops the attributes and symlink target of nodes.
+func (c *attrCache) invalidate(nodes ...uint64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	for _, node := range nodes {
+		delete(c.attrs, node)
+		delete(c.links, node)
+	}
+}
+
+// invalidateEntry drops name in parent, with the attributes of parent
+// and of the node it names, whose times and link counts change with it.
+func (c *attrCache) invalidateEntry(parent uint64, name string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	key := entryKey{parent, name}
+	if e, ok := c.entries[key]; ok && e.out.NodeId != 0 {
+		delete(c.attrs, e.out.NodeId)
+	}
+	c.dropEntryLocked(key)
+	delete(c.attrs, parent)
+}
+
+// made keeps the entry of a node made as name in parent, unless
+// something was invalidated since gen, and drops the attributes of
+// parent, which changed with it. The lookups of name started before are
+// not kept.
+func (c *attrCache) made(gen, parent uint64, name string, out *fuse.EntryOut) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.attrs, parent)
+	if gen == c.gen {
+		c.putEntryLocked(parent, name, out)
+	}
+	c.gen++
+}
+
+// forget drops the names of node, which the kernel forgot so the server
+// may reuse its number, and returns how many of the nlookup lookups are
+// the server's to forget.
+func (c *attrCache) forget(node, nlookup uint64) uint64 {
+	if c == nil {
+		return nlookup
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	for _, key := range append([]entryKey(nil), c.names[node]...) {
+		c.dropEntryLocked(key)
+	}
+	delete(c.attrs, node)
+	delete(c.links, node)
+	lent := c.lent[node]
+	if lent > nlookup {
+		lent = nlookup
+	}
+	if c.lent[node] -= lent; c.lent[node] == 0 {
+		delete(c.lent, node)
+	}
+	return nlookup - lent
+}
+
+// clear drops everything but the lookups lent.
+func (c *attrCache) clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	c.attrs = map[uint64]cachedAttr{}
+	c.entries = map[entryKey]cachedEntry{}
+	c.links = map[uint64]cachedLink{}
+	c.names = map[uint64][]entryKey{}
+}
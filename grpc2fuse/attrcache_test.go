@@ -0,0 +1,137 @@
+package grpc2fuse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/chiyutianyi/grpcfuse/exports"
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+func TestAttrCache(t *testing.T) {
+	dialOpts := append(serve(t), grpc.WithInsecure())
+	dialOpts = append(dialOpts, exports.DialOptions("a")...)
+	c := &counter{calls: map[string]int{}}
+	conn, err := grpc.Dial("bufconn", append(dialOpts, c.dialOptions()...)...)
+	require.NoError(t, err)
+	defer conn.Close()
+	fs := NewFileSystem(pb.NewRawFileSystemClient(conn))
+	fs.SetAttrCache(&AttrCacheOptions{AttrTimeout: time.Minute, EntryTimeout: time.Minute, NegativeTimeout: time.Minute, ReadlinkTimeout: time.Minute})
+	now := time.Now()
+	fs.attrCache.now = func() time.Time { return now }
+	root := fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}
+
+	lookup := func(name string) (uint64, fuse.Status) {
+		var out fuse.EntryOut
+		st := fs.Lookup(nil, &root, name, &out)
+		return out.NodeId, st
+	}
+	getAttr := func(node uint64) fuse.Attr {
+		var out fuse.AttrOut
+		require.Equal(t, fuse.OK, fs.GetAttr(nil, &fuse.GetAttrIn{InHeader: fuse.InHeader{NodeId: node}}, &out))
+		return out.Attr
+	}
+
+	// Names that do not exist are asked once, until made.
+	_, st := lookup("f")
+	assert.Equal(t, fuse.ENOENT, st)
+	_, st = lookup("f")
+	assert.Equal(t, fuse.ENOENT, st)
+	assert.Equal(t, 1, c.get("Lookup"))
+	var out fuse.CreateOut
+	require.Equal(t, fuse.OK, fs.Create(nil, &fuse.CreateIn{InHeader: root, Flags: 2, Mode: 0644}, "f", &out))
+	node := out.NodeId
+	found, st := lookup("f")
+	require.Equal(t, fuse.OK, st)
+	assert.Equal(t, node, found)
+	assert.Equal(t, 1, c.get("Lookup"))
+
+	// Attributes are kept until this mount changes them.
+	assert.Equal(t, uint64(0), getAttr(node).Size)
+	assert.Equal(t, 0, c.get("GetAttr"))
+	_, st = fs.Write(nil, &fuse.WriteIn{InHeader: fuse.InHeader{NodeId: node}, Fh: out.Fh, Size: 5}, []byte("hello"))
+	require.Equal(t, fuse.OK, st)
+	assert.Equal(t, uint64(5), getAttr(node).Size)
+	assert.Equal(t, uint64(5), getAttr(node).Size)
+	assert.Equal(t, 1, c.get("GetAttr"))
+	fs.Release(nil, &fuse.ReleaseIn{InHeader: fuse.InHeader{NodeId: node}, Fh: out.Fh})
+	var attrOut fuse.AttrOut
+	require.Equal(t, fuse.OK, fs.SetAttr(nil, &fuse.SetAttrIn{SetAttrInCommon: fuse.SetAttrInCommon{InHeader: fuse.InHeader{NodeId: node}, Valid: fuse.FATTR_MODE, Mode: 0600}}, &attrOut))
+	assert.Equal(t, uint32(0600), getAttr(node).Mode&07777)
+	assert.Equal(t, 1, c.get("GetAttr"))
+
+	// Until they expire.
+	now = now.Add(2 * time.Minute)
+	getAttr(node)
+	assert.Equal(t, 2, c.get("GetAttr"))
+	_, st = lookup("f")
+	require.Equal(t, fuse.OK, st)
+	assert.Equal(t, 2, c.get("Lookup"))
+
+	// Symlink targets.
+	var entry fuse.EntryOut
+	require.Equal(t, fuse.OK, fs.Symlink(nil, &root, "f", "l", &entry))
+	for i := 0; i < 2; i++ {
+		target, st := fs.Readlink(nil, &fuse.InHeader{NodeId: entry.NodeId})
+		require.Equal(t, fuse.OK, st)
+		assert.Equal(t, "f", string(target))
+	}
+	assert.Equal(t, 1, c.get("Readlink"))
+
+	// Removing a name drops it.
+	require.Equal(t, fuse.OK, fs.Rename(nil, &fuse.RenameIn{InHeader: root, Newdir: fuse.FUSE_ROOT_ID}, "l", "m"))
+	_, st = lookup("l")
+	assert.Equal(t, fuse.ENOENT, st)
+	require.Equal(t, fuse.OK, fs.Unlink(nil, &root, "m"))
+	_, st = lookup("m")
+	assert.Equal(t, fuse.ENOENT, st)
+	assert.Equal(t, 4, c.get("Lookup"))
+
+	// Lookups answered from the cache, here the one after Create and this
+	// one, are not the server's to forget.
+	_, st = lookup("f")
+	require.Equal(t, fuse.OK, st)
+	assert.Equal(t, 4, c.get("Lookup"))
+	fs.Forget(node, 2)
+	assert.Equal(t, 0, c.get("Forget"))
+	getAttr(node)
+	fs.Forget(node, 1)
+	assert.Equal(t, 1, c.get("Forget"))
+
+	// Invalidation from outside.
+	_, st = lookup("f")
+	require.Equal(t, fuse.OK, st)
+	assert.Equal(t, fuse.OK, fs.InvalidateEntry(fuse.FUSE_ROOT_ID, "f"), "not mounted")
+	_, st = lookup("f")
+	require.Equal(t, fuse.OK, st)
+	assert.Equal(t, 6, c.get("Lookup"))
+	calls := c.get("GetAttr")
+	assert.Equal(t, fuse.OK, fs.InvalidateNode(node))
+	getAttr(node)
+	fs.InvalidateAll()
+	getAttr(node)
+	assert.Equal(t, calls+2, c.get("GetAttr"))
+	assert.NotZero(t, fs.AttrCacheStats().Hits)
+}
+
+func TestAttrCacheSize(t *testing.T) {
+	c := newAttrCache(AttrCacheOptions{AttrTimeout: time.Minute, NegativeTimeout: time.Minute, Size: 2})
+	for i := uint64(1); i <= 3; i++ {
+		c.putAttr(0, i, &fuse.Attr{Ino: i})
+		c.putEntry(0, 1, string(rune('a'+i)), nil)
+	}
+	assert.Len(t, c.attrs, 2)
+	assert.Len(t, c.entries, 2)
+
+	// Replies to calls started before an invalidation are not kept.
+	gen := c.generation()
+	c.invalidate(4)
+	c.putAttr(gen, 4, &fuse.Attr{Ino: 4})
+	var out fuse.AttrOut
+	assert.False(t, c.getAttr(4, &out))
+}
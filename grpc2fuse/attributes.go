@@ -23,6 +23,11 @@ import (
 
 func (fs *fileSystem) GetAttr(cancel <-chan struct{}, in *fuse.GetAttrIn, out *fuse.AttrOut) (code fuse.Status) {
 	ctx := newContext(cancel)
+	fs.writeBehind.sync(in.NodeId)
+	if fs.attrCache.getAttr(in.NodeId, out) {
+		return fuse.OK
+	}
+	gen := fs.attrCache.generation()
 
 	res, err := fs.client.GetAttr(ctx, &pb.GetAttrRequest{
 		Header: toPbHeader(&in.InHeader),
@@ -36,17 +41,29 @@ func (fs *fileSystem) GetAttr(cancel <-chan struct{}, in *fuse.GetAttrIn, out *f
 		return fuse.Status(res.Status.GetCode())
 	}
 	toFuseAttrOut(out, res.GetAttrOut())
+	fs.attrCache.putAttr(gen, in.NodeId, &out.Attr)
+	fs.smallFiles.saw(in.NodeId, &out.Attr)
 	return fuse.OK
 }
 
 func (fs *fileSystem) SetAttr(cancel <-chan struct{}, in *fuse.SetAttrIn, out *fuse.AttrOut) (code fuse.Status) {
 	ctx := newContext(cancel)
+	fs.writeBehind.sync(in.NodeId)
+	if in.Valid&fuse.FATTR_SIZE != 0 {
+		defer fs.changed(in.NodeId)
+	}
+	fs.attrCache.invalidate(in.NodeId)
+	gen := fs.attrCache.generation()
+	fh, st := fs.handle(ctx, in.Fh)
+	if st != fuse.OK {
+		return st
+	}
 
 	res, err := fs.client.SetAttr(ctx, &pb.SetAttrRequest{
 		Header:    toPbHeader(&in.InHeader),
 		Valid:     in.Valid,
 		Padding:   in.Padding,
-		Fh:        in.Fh,
+		Fh:        fh,
 		Size:      in.Size,
 		LockOwner: in.LockOwner,
 		Atime:     in.Atime,
@@ -72,5 +89,7 @@ func (fs *fileSystem) SetAttr(cancel <-chan struct{}, in *fuse.SetAttrIn, out *f
 		return fuse.Status(res.Status.GetCode())
 	}
 	toFuseAttrOut(out, res.GetAttrOut())
+	fs.attrCache.putAttr(gen, in.NodeId, &out.Attr)
+	fs.smallFiles.saw(in.NodeId, &out.Attr)
 	return fuse.OK
 }
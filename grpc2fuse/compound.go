@@ -0,0 +1,392 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc2fuse
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+const (
+	// snapshotFh marks the handles of files read whole when opened,
+	// which the server closed already, and of small files created.
+	snapshotFh = 1 << 61
+	// maxSmallFile bounds SmallFiles, as a Compound carries at most 1MiB
+	// of data.
+	maxSmallFile = 512 << 10
+	// maxHeldWrites bounds the writes held for the Flush of a new file,
+	// as a Compound has at most 16 ops.
+	maxHeldWrites = 15
+)
+
+// smallFiles collapses the calls of small files into Compound calls. A
+// file opened read-only and known to be small is opened, read and closed
+// at once, and read from memory; the writes of a file created with
+// write-behind are held until its Flush, and sent with it and with the
+// Release of its handle. Create-write-close so takes two round trips:
+// the Create itself cannot wait for the Flush, as the kernel needs the
+// node, attributes and handle it returns, and its failure belongs to
+// the open(2) that created the file.
+type smallFiles struct {
+	size int
+	// unsupported is set once the server turned Compound down.
+	unsupported int32
+
+	mu sync.Mutex
+	// sizes are the sizes last seen of the nodes looked up.
+	sizes     map[uint64]uint64
+	next      uint64
+	snapshots map[uint64]*snapshot
+}
+
+// snapshot is a handle of a file read whole. Once the file changes
+// through this mount, a call needs a handle of the server, or a read
+// past its end follows the first read, the file is opened again for real.
+// It is also the handle of a new small file, whose handle of the server
+// is released with its first Flush, and opened again if used after.
+type snapshot struct {
+	in   fuse.OpenIn
+	data []byte
+	fh   uint64
+	// read is set once the snapshot answered a read.
+	read bool
+	// created is set for a new file, locked once it took a lock, which
+	// keeps its handle of the server open until its Release.
+	created, locked bool
+}
+
+// SetSmallFiles makes fs collapse the calls of files of at most size
+// bytes, up to 512KiB, into Compound calls. A size of 0 turns it off. It
+// must be called before fs is used.
+func (fs *fileSystem) SetSmallFiles(size int) {
+	if size <= 0 {
+		fs.smallFiles = nil
+		return
+	}
+	if size > maxSmallFile {
+		size = maxSmallFile
+	}
+	fs.smallFiles = &smallFiles{size: size, sizes: map[uint64]uint64{}, snapshots: map[uint64]*snapshot{}}
+}
+
+func (sf *smallFiles) supported() bool {
+	return sf != nil && atomic.LoadInt32(&sf.unsupported) == 0
+}
+
+// saw keeps the size of node from its attributes.
+func (sf *smallFiles) saw(node uint64, attr *fuse.Attr) {
+	if sf == nil {
+		return
+	}
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	sf.sizes[node] = attr.Size
+}
+
+// small reports whether node is known to be small.
+func (sf *smallFiles) small(node uint64) bool {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	size, ok := sf.sizes[node]
+	return ok && size <= uint64(sf.size)
+}
+
+func (sf *smallFiles) forget(node uint64) {
+	if sf == nil {
+		return
+	}
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	delete(sf.sizes, node)
+}
+
+// changed drops the size of node and the data of its snapshots.
+func (sf *smallFiles) changed(node uint64) {
+	if sf == nil {
+		return
+	}
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	delete(sf.sizes, node)
+	for _, s := range sf.snapshots {
+		if s.in.NodeId == node {
+			s.data = nil
+		}
+	}
+}
+
+// compound sends ops, and returns false if the server has no Compound.
+func (fs *fileSystem) compound(ctx context.Context, ops []*pb.CompoundOp) (*pb.CompoundResponse, fuse.Status, bool) {
+	res, err := fs.client.Compound(ctx, &pb.CompoundRequest{Ops: ops}, fs.opts...)
+	if status.Code(err) == codes.Unimplemented {
+		log.Debugf("Compound: %v", err)
+		atomic.StoreInt32(&fs.smallFiles.unsupported, 1)
+		return nil, fuse.ENOSYS, false
+	}
+	if st := dealGrpcError("Compound", err); st != fuse.OK {
+		return nil, st, true
+	}
+	return res, fuse.OK, true
+}
+
+// openSmall opens, reads and closes a small file opened read-only, and
+// returns false if it was not.
+func (fs *fileSystem) openSmall(ctx context.Context, in *fuse.OpenIn, out *fuse.OpenOut) (fuse.Status, bool) {
+	sf := fs.smallFiles
+	if !sf.supported() || in.Flags&syscall.O_ACCMODE != syscall.O_RDONLY || in.Flags&syscall.O_TRUNC != 0 || !sf.small(in.NodeId) {
+		return fuse.OK, false
+	}
+	header := toPbHeader(&in.InHeader)
+	res, st, ok := fs.compound(ctx, []*pb.CompoundOp{
+		{Op: &pb.CompoundOp_Open{Open: &pb.OpenRequest{OpenIn: &pb.OpenIn{Header: header, Flags: in.Flags, Mode: in.Mode}}}},
+		// One byte more tells a file that grew.
+		{Op: &pb.CompoundOp_Read{Read: &pb.ReadRequest{ReadIn: &pb.ReadIn{Header: header, Size: uint32(sf.size) + 1}}}, FhFrom: 1},
+		{Op: &pb.CompoundOp_Release{Release: &pb.ReleaseRequest{Header: header, Flags: in.Flags}}, FhFrom: 1},
+	})
+	if !ok {
+		return fuse.OK, false
+	}
+	if st != fuse.OK {
+		return st, true
+	}
+	results := res.Results
+	if len(results) == 0 {
+		return fuse.EIO, true
+	}
+	open := results[0].GetOpen()
+	if code := open.GetStatus().GetCode(); code != 0 {
+		return fuse.Status(code), true
+	}
+	if len(results) < 3 {
+		// The read failed, and the server released the handle: open it
+		// the usual way, for the reads to tell why.
+		log.Debugf("Reading small node %d: %v", in.NodeId, res.Status)
+		return fuse.OK, false
+	}
+	data := results[1].GetRead().GetBuffer()
+	if len(data) > sf.size {
+		return fuse.OK, false
+	}
+
+	toFuseOpenOut(out, open.OpenOut)
+	sf.mu.Lock()
+	sf.next++
+	out.Fh = snapshotFh | sf.next
+	sf.snapshots[out.Fh] = &snapshot{in: *in, data: data}
+	sf.mu.Unlock()
+	return fuse.OK, true
+}
+
+// readSnapshot answers a read of a snapshot from memory, if its data is
+// still good. Past the end of the data, only the first read is answered:
+// the file may have grown since.
+func (sf *smallFiles) readSnapshot(in *fuse.ReadIn) ([]byte, bool) {
+	if sf == nil || in.Fh&snapshotFh == 0 {
+		return nil, false
+	}
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	s := sf.snapshots[in.Fh]
+	if s == nil || s.data == nil {
+		return nil, false
+	}
+	size := uint64(len(s.data))
+	end := in.Offset + uint64(in.Size)
+	if end > size {
+		if s.read {
+			// The file may have grown since: read it from the server
+			// from now on.
+			s.data = nil
+			return nil, false
+		}
+		end = size
+	}
+	s.read = true
+	if in.Offset >= end {
+		return nil, true
+	}
+	return s.data[in.Offset:end], true
+}
+
+// handle returns the handle of the server for fh, opening the file of a
+// snapshot again. Snapshots are never truncated, so the open does not
+// change the file, which would need sf.mu.
+func (fs *fileSystem) handle(ctx context.Context, fh uint64) (uint64, fuse.Status) {
+	sf := fs.smallFiles
+	if sf == nil || fh&snapshotFh == 0 {
+		return fh, fuse.OK
+	}
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	s := sf.snapshots[fh]
+	if s == nil {
+		return 0, fuse.EBADF
+	}
+	if s.fh == 0 {
+		var out fuse.OpenOut
+		if st := fs.open(ctx, &s.in, &out); st != fuse.OK {
+			return 0, st
+		}
+		s.fh = out.Fh
+	}
+	return s.fh, fuse.OK
+}
+
+// opened returns the handle of the server for fh, 0 if it is a snapshot
+// not opened again, and whether it is a snapshot.
+func (sf *smallFiles) opened(fh uint64) (uint64, bool) {
+	if sf == nil || fh&snapshotFh == 0 {
+		return fh, false
+	}
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	if s := sf.snapshots[fh]; s != nil {
+		return s.fh, true
+	}
+	return 0, true
+}
+
+// create holds the writes of a file just created for its Flush and, if
+// its creator may open it again, gives the kernel a handle of its own,
+// for the Release of the handle of the server to go with the Flush.
+func (fs *fileSystem) create(in *fuse.CreateIn, out *fuse.CreateOut) {
+	sf := fs.smallFiles
+	if !sf.supported() || fs.writeBehind == nil {
+		return
+	}
+	fs.writeBehind.hold(out.Fh, out.NodeId, sf.size)
+	if !reopenable(in.Flags, out.Attr.Mode) {
+		return
+	}
+	open := fuse.OpenIn{InHeader: in.InHeader, Flags: in.Flags &^ (syscall.O_CREAT | syscall.O_EXCL | syscall.O_TRUNC), Mode: in.Mode}
+	open.NodeId = out.NodeId
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	sf.next++
+	sf.snapshots[snapshotFh|sf.next] = &snapshot{in: open, fh: out.Fh, created: true}
+	out.Fh = snapshotFh | sf.next
+}
+
+// reopenable tells whether the owner of a file of mode may open it with
+// flags.
+func reopenable(flags, mode uint32) bool {
+	switch flags & syscall.O_ACCMODE {
+	case syscall.O_RDONLY:
+		return mode&0400 != 0
+	case syscall.O_WRONLY:
+		return mode&0200 != 0
+	}
+	return mode&0600 == 0600
+}
+
+// lock keeps the handle of the server of fh open until its Release, for
+// the locks taken through it.
+func (sf *smallFiles) lock(fh uint64) {
+	if sf == nil || fh&snapshotFh == 0 {
+		return
+	}
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	if s := sf.snapshots[fh]; s != nil {
+		s.locked = true
+	}
+}
+
+// closable tells whether the handle of the server of fh may be released
+// with its Flush.
+func (sf *smallFiles) closable(fh uint64) bool {
+	if sf == nil || fh&snapshotFh == 0 {
+		return false
+	}
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	s := sf.snapshots[fh]
+	return s != nil && s.created && !s.locked
+}
+
+// closed records that the server released the handle of fh.
+func (sf *smallFiles) closed(fh uint64) {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	if s := sf.snapshots[fh]; s != nil {
+		s.fh = 0
+	}
+}
+
+// release forgets the snapshot fh, returning its handle of the server,
+// if any.
+func (sf *smallFiles) release(fh uint64) uint64 {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	s := sf.snapshots[fh]
+	delete(sf.snapshots, fh)
+	if s == nil {
+		return 0
+	}
+	return s.fh
+}
+
+// flushWrites sends the writes held for a new file along with its Flush
+// of the handle fh of the server, and its Release if release is set. It
+// returns false if the server has no Compound, and whether the server
+// released fh.
+func (fs *fileSystem) flushWrites(ctx context.Context, in *fuse.FlushIn, fh uint64, writes []*pb.WriteRequest, release bool) (fuse.Status, bool, bool) {
+	defer fs.changed(in.NodeId)
+	header := toPbHeader(&in.InHeader)
+	ops := make([]*pb.CompoundOp, 0, len(writes)+2)
+	for _, w := range writes {
+		ops = append(ops, &pb.CompoundOp{Op: &pb.CompoundOp_Write{Write: w}})
+	}
+	ops = append(ops, &pb.CompoundOp{Op: &pb.CompoundOp_Flush{Flush: &pb.FlushRequest{
+		Header:    header,
+		Fh:        fh,
+		Unused:    in.Unused,
+		Padding:   in.Padding,
+		LockOwner: in.LockOwner,
+	}}})
+	if release {
+		ops = append(ops, &pb.CompoundOp{Op: &pb.CompoundOp_Release{Release: &pb.ReleaseRequest{
+			Header:    header,
+			Fh:        fh,
+			LockOwner: in.LockOwner,
+		}}})
+	}
+	res, st, ok := fs.compound(ctx, ops)
+	if !ok || st != fuse.OK {
+		return st, ok, false
+	}
+	released := release && res.Status.GetCode() == 0 && len(res.Results) == len(ops)
+	for i, r := range res.Results {
+		if i < len(writes) && r.GetWrite().GetStatus().GetCode() == 0 && int(r.GetWrite().GetWritten()) < len(writes[i].Data) {
+			log.Errorf("Write of node %d at %d: short write", in.NodeId, writes[i].Offset)
+			return fuse.EIO, true, released
+		}
+	}
+	if res.Status.GetCode() != 0 {
+		log.Errorf("Writing node %d: %v", in.NodeId, fuse.Status(res.Status.GetCode()))
+	}
+	return fuse.Status(res.Status.GetCode()), true, released
+}
@@ -0,0 +1,208 @@
+package grpc2fuse
+
+import (
+	"context"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/chiyutianyi/grpcfuse/exports"
+	"github.com/chiyutianyi/grpcfuse/fuse2grpc"
+	"github.com/chiyutianyi/grpcfuse/memfs"
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+// noCompound is a server from before Compound.
+type noCompound struct {
+	pb.RawFileSystemServer
+}
+
+func (noCompound) Compound(context.Context, *pb.CompoundRequest) (*pb.CompoundResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Compound not implemented")
+}
+
+func smallFilesFS(t *testing.T, dialOpts []grpc.DialOption) (*fileSystem, *counter) {
+	dialOpts = append(dialOpts, grpc.WithInsecure())
+	dialOpts = append(dialOpts, exports.DialOptions("a")...)
+	c := &counter{calls: map[string]int{}}
+	conn, err := grpc.Dial("bufconn", append(dialOpts, c.dialOptions()...)...)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	fs := NewFileSystem(pb.NewRawFileSystemClient(conn))
+	fs.SetWriteBehind(1<<20, 128<<10)
+	fs.SetSmallFiles(4096)
+	return fs, c
+}
+
+func writeSmall(t *testing.T, fs *fileSystem, name string, chunks ...string) uint64 {
+	root := fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}
+	var out fuse.CreateOut
+	require.Equal(t, fuse.OK, fs.Create(nil, &fuse.CreateIn{InHeader: root, Flags: syscall.O_WRONLY, Mode: 0644}, name, &out))
+	header := fuse.InHeader{NodeId: out.NodeId}
+	offset := uint64(0)
+	for _, chunk := range chunks {
+		n, st := fs.Write(nil, &fuse.WriteIn{InHeader: header, Fh: out.Fh, Offset: offset, Size: uint32(len(chunk))}, []byte(chunk))
+		require.Equal(t, fuse.OK, st)
+		require.Equal(t, uint32(len(chunk)), n)
+		offset += uint64(len(chunk))
+	}
+	require.Equal(t, fuse.OK, fs.Flush(nil, &fuse.FlushIn{InHeader: header, Fh: out.Fh}))
+	fs.Release(nil, &fuse.ReleaseIn{InHeader: header, Fh: out.Fh})
+	return out.NodeId
+}
+
+func readSmall(t *testing.T, fs *fileSystem, in *fuse.OpenIn, fh uint64, offset uint64) string {
+	res, st := fs.Read(nil, &fuse.ReadIn{InHeader: in.InHeader, Fh: fh, Offset: offset, Size: 4096}, make([]byte, 4096))
+	require.Equal(t, fuse.OK, st)
+	data, st := res.Bytes(make([]byte, 4096))
+	require.Equal(t, fuse.OK, st)
+	return string(data)
+}
+
+func TestSmallFiles(t *testing.T) {
+	fs, c := smallFilesFS(t, serve(t))
+	root := fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}
+
+	// The writes of a new file go with its Flush and Release.
+	node := writeSmall(t, fs, "f", "hello ", "world")
+	assert.Equal(t, 1, c.get("Create"))
+	assert.Equal(t, 1, c.get("Compound"))
+	for _, method := range []string{"Write", "Flush", "Release"} {
+		assert.Equal(t, 0, c.get(method), method)
+	}
+
+	// A small file is read whole when opened.
+	var entry fuse.EntryOut
+	require.Equal(t, fuse.OK, fs.Lookup(nil, &root, "f", &entry))
+	require.Equal(t, node, entry.NodeId)
+	in := &fuse.OpenIn{InHeader: fuse.InHeader{NodeId: node}, Flags: syscall.O_RDONLY}
+	var out fuse.OpenOut
+	require.Equal(t, fuse.OK, fs.Open(nil, in, &out))
+	assert.Equal(t, 2, c.get("Compound"))
+	assert.Equal(t, "hello world", readSmall(t, fs, in, out.Fh, 0))
+	res, st := fs.Read(nil, &fuse.ReadIn{InHeader: in.InHeader, Fh: out.Fh, Offset: 6, Size: 5}, make([]byte, 5))
+	require.Equal(t, fuse.OK, st)
+	data, _ := res.Bytes(make([]byte, 5))
+	assert.Equal(t, "world", string(data))
+	require.Equal(t, fuse.OK, fs.Flush(nil, &fuse.FlushIn{InHeader: in.InHeader, Fh: out.Fh}))
+	fs.Release(nil, &fuse.ReleaseIn{InHeader: in.InHeader, Fh: out.Fh})
+	for _, method := range []string{"Open", "Read", "Flush", "Release"} {
+		assert.Equal(t, 0, c.get(method), method)
+	}
+
+	// Reading past its end again, it sees the file grow on the server.
+	require.Equal(t, fuse.OK, fs.Open(nil, in, &out))
+	snapshot := out.Fh
+	assert.Equal(t, "hello world", readSmall(t, fs, in, snapshot, 0))
+	other := NewFileSystem(fs.client)
+	writer := &fuse.OpenIn{InHeader: in.InHeader, Flags: syscall.O_WRONLY}
+	require.Equal(t, fuse.OK, other.Open(nil, writer, &out))
+	_, st = other.Write(nil, &fuse.WriteIn{InHeader: in.InHeader, Fh: out.Fh, Offset: 11, Size: 1}, []byte("!"))
+	require.Equal(t, fuse.OK, st)
+	other.Release(nil, &fuse.ReleaseIn{InHeader: in.InHeader, Fh: out.Fh})
+	assert.Equal(t, "!", readSmall(t, fs, in, snapshot, 11))
+	assert.Equal(t, 2, c.get("Open"))
+	assert.Equal(t, 1, c.get("Read"))
+	fs.Release(nil, &fuse.ReleaseIn{InHeader: in.InHeader, Fh: snapshot})
+	assert.Equal(t, 2, c.get("Release"))
+
+	// Once changed through the mount, it is opened again for real.
+	require.Equal(t, fuse.OK, fs.Open(nil, in, &out))
+	snapshot = out.Fh
+	require.Equal(t, fuse.OK, fs.Open(nil, writer, &out))
+	_, st = fs.Write(nil, &fuse.WriteIn{InHeader: in.InHeader, Fh: out.Fh, Size: 5}, []byte("HELLO"))
+	require.Equal(t, fuse.OK, st)
+	require.Equal(t, fuse.OK, fs.Flush(nil, &fuse.FlushIn{InHeader: in.InHeader, Fh: out.Fh}))
+	fs.Release(nil, &fuse.ReleaseIn{InHeader: in.InHeader, Fh: out.Fh})
+	assert.Equal(t, "HELLO world!", readSmall(t, fs, in, snapshot, 0))
+	assert.Equal(t, 4, c.get("Open"))
+	assert.Equal(t, 2, c.get("Read"))
+	fs.Release(nil, &fuse.ReleaseIn{InHeader: in.InHeader, Fh: snapshot})
+	assert.Equal(t, 4, c.get("Release"))
+
+	// Large files are opened as usual.
+	node = writeSmall(t, fs, "large", string(make([]byte, 5000)))
+	assert.Equal(t, 3, c.get("Write"))
+	var attr fuse.AttrOut
+	require.Equal(t, fuse.OK, fs.GetAttr(nil, &fuse.GetAttrIn{InHeader: fuse.InHeader{NodeId: node}}, &attr))
+	compounds := c.get("Compound")
+	require.Equal(t, fuse.OK, fs.Open(nil, &fuse.OpenIn{InHeader: fuse.InHeader{NodeId: node}}, &out))
+	assert.Equal(t, compounds, c.get("Compound"))
+	assert.Equal(t, 5, c.get("Open"))
+}
+
+func TestSmallFilesUsedAfterFlush(t *testing.T) {
+	fs, c := smallFilesFS(t, serve(t))
+	root := fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}
+
+	// A write after the Flush opens the file again.
+	var out fuse.CreateOut
+	require.Equal(t, fuse.OK, fs.Create(nil, &fuse.CreateIn{InHeader: root, Flags: syscall.O_WRONLY, Mode: 0644}, "f", &out))
+	header := fuse.InHeader{NodeId: out.NodeId}
+	_, st := fs.Write(nil, &fuse.WriteIn{InHeader: header, Fh: out.Fh, Size: 5}, []byte("hello"))
+	require.Equal(t, fuse.OK, st)
+	require.Equal(t, fuse.OK, fs.Flush(nil, &fuse.FlushIn{InHeader: header, Fh: out.Fh}))
+	assert.Equal(t, 0, c.get("Release"))
+	_, st = fs.Write(nil, &fuse.WriteIn{InHeader: header, Fh: out.Fh, Offset: 5, Size: 6}, []byte(" world"))
+	require.Equal(t, fuse.OK, st)
+	require.Equal(t, fuse.OK, fs.Flush(nil, &fuse.FlushIn{InHeader: header, Fh: out.Fh}))
+	fs.Release(nil, &fuse.ReleaseIn{InHeader: header, Fh: out.Fh})
+	assert.Equal(t, 1, c.get("Open"))
+	assert.Equal(t, 1, c.get("Release"))
+	in := &fuse.OpenIn{InHeader: header, Flags: syscall.O_RDONLY}
+	var open fuse.OpenOut
+	require.Equal(t, fuse.OK, fs.Open(nil, in, &open))
+	assert.Equal(t, "hello world", readSmall(t, fs, in, open.Fh, 0))
+	fs.Release(nil, &fuse.ReleaseIn{InHeader: header, Fh: open.Fh})
+	opens, releases := c.get("Open"), c.get("Release")
+
+	// A handle that took a lock keeps its handle of the server.
+	require.Equal(t, fuse.OK, fs.Create(nil, &fuse.CreateIn{InHeader: root, Flags: syscall.O_RDWR, Mode: 0644}, "locked", &out))
+	header = fuse.InHeader{NodeId: out.NodeId}
+	require.Equal(t, fuse.OK, fs.SetLk(nil, &fuse.LkIn{InHeader: header, Fh: out.Fh, Lk: fuse.FileLock{Typ: syscall.F_WRLCK}, LkFlags: fuse.FUSE_LK_FLOCK}))
+	require.Equal(t, fuse.OK, fs.Flush(nil, &fuse.FlushIn{InHeader: header, Fh: out.Fh}))
+	assert.Equal(t, releases, c.get("Release"))
+	fs.Release(nil, &fuse.ReleaseIn{InHeader: header, Fh: out.Fh})
+	assert.Equal(t, releases+1, c.get("Release"))
+	assert.Equal(t, opens, c.get("Open"))
+
+	// So does one its creator may not open again.
+	require.Equal(t, fuse.OK, fs.Create(nil, &fuse.CreateIn{InHeader: root, Flags: syscall.O_WRONLY, Mode: 0444}, "ro", &out))
+	header = fuse.InHeader{NodeId: out.NodeId}
+	require.Equal(t, fuse.OK, fs.Flush(nil, &fuse.FlushIn{InHeader: header, Fh: out.Fh}))
+	fs.Release(nil, &fuse.ReleaseIn{InHeader: header, Fh: out.Fh})
+	assert.Equal(t, releases+2, c.get("Release"))
+}
+
+func TestSmallFilesWithoutCompound(t *testing.T) {
+	l := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	r := exports.New()
+	r.Set(map[string]pb.RawFileSystemServer{"a": noCompound{fuse2grpc.NewServer(memfs.New(nil, nil))}}, "")
+	r.Register(s)
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+	fs, c := smallFilesFS(t, []grpc.DialOption{grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return l.DialContext(ctx) })})
+
+	node := writeSmall(t, fs, "f", "hello")
+	assert.Equal(t, 1, c.get("Compound"))
+	assert.Equal(t, 1, c.get("Write"))
+	assert.Equal(t, 1, c.get("Flush"))
+
+	in := &fuse.OpenIn{InHeader: fuse.InHeader{NodeId: node}, Flags: syscall.O_RDONLY}
+	var out fuse.OpenOut
+	require.Equal(t, fuse.OK, fs.Open(nil, in, &out))
+	assert.Equal(t, "hello", readSmall(t, fs, in, out.Fh, 0))
+	fs.Release(nil, &fuse.ReleaseIn{InHeader: in.InHeader, Fh: out.Fh})
+	assert.Equal(t, 1, c.get("Compound"))
+	assert.Equal(t, 1, c.get("Open"))
+	assert.Equal(t, 1, c.get("Read"))
+}
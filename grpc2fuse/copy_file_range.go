@@ -24,13 +24,24 @@ import (
 
 func (fs *fileSystem) CopyFileRange(cancel <-chan struct{}, input *fuse.CopyFileRangeIn) (written uint32, code fuse.Status) {
 	ctx := newContext(cancel)
+	fs.writeBehind.sync(input.NodeId)
+	fs.writeBehind.sync(input.NodeIdOut)
+	defer fs.changed(input.NodeIdOut)
+	fhIn, st := fs.handle(ctx, input.FhIn)
+	if st != fuse.OK {
+		return 0, st
+	}
+	fhOut, st := fs.handle(ctx, input.FhOut)
+	if st != fuse.OK {
+		return 0, st
+	}
 
 	res, err := fs.client.CopyFileRange(ctx, &pb.CopyFileRangeRequest{
 		Header:    toPbHeader(&input.InHeader),
-		FhIn:      input.FhIn,
+		FhIn:      fhIn,
 		OffIn:     input.OffIn,
 		NodeIdOut: input.NodeIdOut,
-		FhOut:     input.FhOut,
+		FhOut:     fhOut,
 		OffOut:    input.OffOut,
 		Len:       input.Len,
 		Flags:     input.Flags,
@@ -55,14 +55,26 @@ func (fs *fileSystem) doReadDir(
 	cancel <-chan struct{},
 	in *fuse.ReadIn,
 	out *fuse.DirEntryList,
-	reader func(ctx context.Context, in *pb.ReadDirRequest) RawFileSystem_ReadDirClient,
+	reader func(ctx context.Context, in *pb.ReadDirRequest) (RawFileSystem_ReadDirClient, error),
 	funcName string,
+	plus bool,
 ) fuse.Status {
-	var de fuse.DirEntry
-	ctx := newContext(cancel)
+	var (
+		de      fuse.DirEntry
+		dropped []*pb.DirEntry
+		handed  []*pb.DirEntry
+	)
+	ctx, cancelStream := context.WithCancel(newContext(cancel))
+	defer cancelStream()
 
-	stream := reader(ctx, &pb.ReadDirRequest{ReadIn: toPbReadIn(in)})
+	stream, err := reader(ctx, &pb.ReadDirRequest{ReadIn: toPbReadIn(in)})
+	if st := dealGrpcError(funcName, err); st != fuse.OK {
+		return st
+	}
 
+	// off mirrors the offset fuse.DirEntryList assigns to the next entry,
+	// which is what the kernel passes back to continue the listing.
+	off := in.Offset
 	for {
 		res, err := stream.Recv()
 		if err == io.EOF {
@@ -74,54 +86,87 @@ func (fs *fileSystem) doReadDir(
 		if res.Status.GetCode() != 0 {
 			return fuse.Status(res.Status.GetCode())
 		}
-		for _, e := range res.Entries {
+		for i, e := range res.Entries {
+			if e.Off != 0 && e.Off != off+1 {
+				// The kernel can only resume from offsets we hand out, so
+				// stop here and let it ask again from the last one.
+				log.Warnf("%s: unexpected offset %d after %d", funcName, e.Off, off)
+				dropped = res.Entries[i:]
+				break
+			}
 			de.Ino = e.Ino
 			de.Name = string(e.Name)
 			de.Mode = e.Mode
-			if !out.AddDirEntry(de) {
+			if !plus {
+				if !out.AddDirEntry(de) {
+					dropped = res.Entries[i:]
+					break
+				}
+				handed = append(handed, e)
+				off++
+				continue
+			}
+			entryOut := out.AddDirLookupEntry(de)
+			if entryOut == nil {
+				dropped = res.Entries[i:]
 				break
 			}
+			handed = append(handed, e)
+			off++
+			// "." and ".." come with a zero EntryOut, which the kernel ignores.
+			if e.EntryOut != nil && e.EntryOut.Attr != nil {
+				toFuseEntryOut(entryOut, e.EntryOut)
+			}
+		}
+		if dropped != nil {
+			break
+		}
+	}
+	if fs.listed != nil {
+		fs.listed(in, handed)
+	}
+	if dropped == nil {
+		return fuse.OK
+	}
+
+	if !plus {
+		return fuse.OK
+	}
+	// The server looked up every entry it sent; give back the lookups for
+	// the ones the kernel will never see. The rest of the stream is bounded
+	// by in.Size, so draining it is cheap.
+	fs.forgetEntries(dropped)
+	for {
+		res, err := stream.Recv()
+		if err != nil {
+			break
 		}
+		fs.forgetEntries(res.Entries)
 	}
 	return fuse.OK
 }
 
-func (fs *fileSystem) ReadDir(cancel <-chan struct{}, in *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
-	var (
-		stream pb.RawFileSystem_ReadDirClient
-		err    error
-	)
-
-	reader := func(ctx context.Context, in *pb.ReadDirRequest) RawFileSystem_ReadDirClient {
-		stream, err = fs.client.ReadDir(ctx, in, fs.opts...)
-		return stream
+// forgetEntries releases the lookup count READDIRPLUS took on each entry.
+func (fs *fileSystem) forgetEntries(entries []*pb.DirEntry) {
+	for _, e := range entries {
+		if nodeID := e.EntryOut.GetNodeId(); nodeID != 0 {
+			fs.Forget(nodeID, 1)
+		}
 	}
+}
 
-	if err != nil {
-		log.Errorf("ReadDir: %v", err)
-		return fuse.EIO
+func (fs *fileSystem) ReadDir(cancel <-chan struct{}, in *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
+	reader := func(ctx context.Context, in *pb.ReadDirRequest) (RawFileSystem_ReadDirClient, error) {
+		return fs.client.ReadDir(ctx, in, fs.opts...)
 	}
-
-	return fs.doReadDir(cancel, in, out, reader, "ReadDir")
+	return fs.doReadDir(cancel, in, out, reader, "ReadDir", false)
 }
 
 func (fs *fileSystem) ReadDirPlus(cancel <-chan struct{}, in *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
-	var (
-		stream pb.RawFileSystem_ReadDirPlusClient
-		err    error
-	)
-
-	reader := func(ctx context.Context, in *pb.ReadDirRequest) RawFileSystem_ReadDirClient {
-		stream, err = fs.client.ReadDirPlus(ctx, in, fs.opts...)
-		return stream
-	}
-
-	if err != nil {
-		log.Errorf("ReadDirPlus: %v", err)
-		return fuse.EIO
+	reader := func(ctx context.Context, in *pb.ReadDirRequest) (RawFileSystem_ReadDirClient, error) {
+		return fs.client.ReadDirPlus(ctx, in, fs.opts...)
 	}
-
-	return fs.doReadDir(cancel, in, out, reader, "ReadDirPlus")
+	return fs.doReadDir(cancel, in, out, reader, "ReadDirPlus", true)
 }
 
 func (fs *fileSystem) ReleaseDir(in *fuse.ReleaseIn) {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"testing"
+	"unsafe"
 
 	"github.com/chiyutianyi/grpcfuse/pb"
 	"github.com/hanwen/go-fuse/v2/fuse"
@@ -15,7 +16,7 @@ import (
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
-type MockRawFileSystemClient struct {
+type directoryHandlersClient struct {
 	pb.RawFileSystemClient
 	OpenDirFunc     func(context.Context, *pb.OpenDirRequest, ...grpc.CallOption) (*pb.OpenDirResponse, error)
 	ReadDirFunc     func(context.Context, *pb.ReadDirRequest, ...grpc.CallOption) (pb.RawFileSystem_ReadDirClient, error)
@@ -24,23 +25,23 @@ type MockRawFileSystemClient struct {
 	FsyncDirFunc    func(context.Context, *pb.FsyncRequest, ...grpc.CallOption) (*pb.FsyncResponse, error)
 }
 
-func (m *MockRawFileSystemClient) OpenDir(ctx context.Context, req *pb.OpenDirRequest, opts ...grpc.CallOption) (*pb.OpenDirResponse, error) {
+func (m *directoryHandlersClient) OpenDir(ctx context.Context, req *pb.OpenDirRequest, opts ...grpc.CallOption) (*pb.OpenDirResponse, error) {
 	return m.OpenDirFunc(ctx, req, opts...)
 }
 
-func (m *MockRawFileSystemClient) ReadDir(ctx context.Context, req *pb.ReadDirRequest, opts ...grpc.CallOption) (pb.RawFileSystem_ReadDirClient, error) {
+func (m *directoryHandlersClient) ReadDir(ctx context.Context, req *pb.ReadDirRequest, opts ...grpc.CallOption) (pb.RawFileSystem_ReadDirClient, error) {
 	return m.ReadDirFunc(ctx, req, opts...)
 }
 
-func (m *MockRawFileSystemClient) ReadDirPlus(ctx context.Context, req *pb.ReadDirRequest, opts ...grpc.CallOption) (pb.RawFileSystem_ReadDirPlusClient, error) {
+func (m *directoryHandlersClient) ReadDirPlus(ctx context.Context, req *pb.ReadDirRequest, opts ...grpc.CallOption) (pb.RawFileSystem_ReadDirPlusClient, error) {
 	return m.ReadDirPlusFunc(ctx, req, opts...)
 }
 
-func (m *MockRawFileSystemClient) ReleaseDir(ctx context.Context, req *pb.ReleaseRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+func (m *directoryHandlersClient) ReleaseDir(ctx context.Context, req *pb.ReleaseRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	return m.ReleaseDirFunc(ctx, req, opts...)
 }
 
-func (m *MockRawFileSystemClient) FsyncDir(ctx context.Context, req *pb.FsyncRequest, opts ...grpc.CallOption) (*pb.FsyncResponse, error) {
+func (m *directoryHandlersClient) FsyncDir(ctx context.Context, req *pb.FsyncRequest, opts ...grpc.CallOption) (*pb.FsyncResponse, error) {
 	return m.FsyncDirFunc(ctx, req, opts...)
 }
 
@@ -107,7 +108,7 @@ func TestOpenDir(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockClient := &MockRawFileSystemClient{
+			mockClient := &directoryHandlersClient{
 				OpenDirFunc: tt.mock,
 			}
 			fs := &fileSystem{client: mockClient}
@@ -161,7 +162,7 @@ func TestReadDir(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockReadDirClient := &MockReadDirClient{recvFunc: tt.mock}
-			mockClient := &MockRawFileSystemClient{
+			mockClient := &directoryHandlersClient{
 				ReadDirFunc: func(ctx context.Context, req *pb.ReadDirRequest, opts ...grpc.CallOption) (pb.RawFileSystem_ReadDirClient, error) {
 					return mockReadDirClient, nil
 				},
@@ -207,7 +208,7 @@ func TestReadDirPlus(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockReadDirClient := &MockReadDirClient{recvFunc: tt.mock}
-			mockClient := &MockRawFileSystemClient{
+			mockClient := &directoryHandlersClient{
 				ReadDirPlusFunc: func(ctx context.Context, req *pb.ReadDirRequest, opts ...grpc.CallOption) (pb.RawFileSystem_ReadDirPlusClient, error) {
 					return mockReadDirClient, nil
 				},
@@ -244,7 +245,7 @@ func TestReleaseDir(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockClient := &MockRawFileSystemClient{
+			mockClient := &directoryHandlersClient{
 				ReleaseDirFunc: tt.mock,
 			}
 			fs := &fileSystem{client: mockClient}
@@ -288,7 +289,7 @@ func TestFsyncDir(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockClient := &MockRawFileSystemClient{
+			mockClient := &directoryHandlersClient{
 				FsyncDirFunc: tt.mock,
 			}
 			fs := &fileSystem{client: mockClient}
@@ -297,3 +298,149 @@ func TestFsyncDir(t *testing.T) {
 		})
 	}
 }
+
+func TestReadDirPlusEntryOut(t *testing.T) {
+	responses := []*pb.ReadDirResponse{{
+		Status: &pb.Status{Code: 0},
+		Entries: []*pb.DirEntry{{
+			Ino:  7,
+			Name: []byte("file"),
+			Mode: fuse.S_IFREG,
+			Off:  1,
+			EntryOut: &pb.EntryOut{
+				NodeId:     42,
+				Generation: 3,
+				EntryValid: 5,
+				AttrValid:  6,
+				Attr:       &pb.Attr{Ino: 7, Size: 1234, Mode: fuse.S_IFREG | 0644, Nlink: 1, Owner: &pb.Owner{Uid: 1, Gid: 2}},
+			},
+		}},
+	}}
+	mockReadDirClient := &MockReadDirClient{recvFunc: func() (*pb.ReadDirResponse, error) {
+		if len(responses) == 0 {
+			return nil, io.EOF
+		}
+		res := responses[0]
+		responses = responses[1:]
+		return res, nil
+	}}
+	mockClient := &directoryHandlersClient{
+		ReadDirPlusFunc: func(ctx context.Context, req *pb.ReadDirRequest, opts ...grpc.CallOption) (pb.RawFileSystem_ReadDirPlusClient, error) {
+			return mockReadDirClient, nil
+		},
+	}
+	fs := &fileSystem{client: mockClient}
+
+	buf := make([]byte, 1000)
+	out := fuse.NewDirEntryList(buf, 0)
+	assert.Equal(t, fuse.OK, fs.ReadDirPlus(make(<-chan struct{}), &fuse.ReadIn{}, out))
+
+	// READDIRPLUS records start with the EntryOut.
+	entryOut := (*fuse.EntryOut)(unsafe.Pointer(&buf[0]))
+	assert.Equal(t, uint64(42), entryOut.NodeId)
+	assert.Equal(t, uint64(3), entryOut.Generation)
+	assert.Equal(t, uint64(5), entryOut.EntryValid)
+	assert.Equal(t, uint64(6), entryOut.AttrValid)
+	assert.Equal(t, uint64(7), entryOut.Ino)
+	assert.Equal(t, uint64(1234), entryOut.Size)
+	assert.Equal(t, uint32(fuse.S_IFREG|0644), entryOut.Mode)
+	assert.Equal(t, uint32(1), entryOut.Uid)
+	assert.Equal(t, uint32(2), entryOut.Gid)
+}
+
+type forgetRecorder struct {
+	*directoryHandlersClient
+	forgotten []uint64
+}
+
+func (m *forgetRecorder) Forget(ctx context.Context, in *pb.ForgetRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	m.forgotten = append(m.forgotten, in.Nodeid)
+	return &emptypb.Empty{}, nil
+}
+
+func TestReadDirPlusOverflow(t *testing.T) {
+	name := make([]byte, 200)
+	for i := range name {
+		name[i] = 'x'
+	}
+	entry := func(i uint64) *pb.DirEntry {
+		return &pb.DirEntry{
+			Ino:      i,
+			Name:     append([]byte{byte('a' + i)}, name...),
+			Mode:     fuse.S_IFREG,
+			Off:      10 + i,
+			EntryOut: &pb.EntryOut{NodeId: 100 + i, Attr: &pb.Attr{Ino: i, Owner: &pb.Owner{}}},
+		}
+	}
+	responses := []*pb.ReadDirResponse{
+		{Status: &pb.Status{}, Entries: []*pb.DirEntry{entry(1), entry(2)}},
+		{Status: &pb.Status{}, Entries: []*pb.DirEntry{entry(3)}},
+	}
+	stream := &MockReadDirClient{recvFunc: func() (*pb.ReadDirResponse, error) {
+		if len(responses) == 0 {
+			return nil, io.EOF
+		}
+		res := responses[0]
+		responses = responses[1:]
+		return res, nil
+	}}
+	client := &forgetRecorder{directoryHandlersClient: &directoryHandlersClient{
+		ReadDirPlusFunc: func(ctx context.Context, req *pb.ReadDirRequest, opts ...grpc.CallOption) (pb.RawFileSystem_ReadDirPlusClient, error) {
+			assert.Equal(t, uint64(10), req.ReadIn.Offset)
+			return stream, nil
+		},
+	}}
+	fs := &fileSystem{client: client}
+
+	// Room for a single entry.
+	buf := make([]byte, 400)
+	out := fuse.NewDirEntryList(buf, 10)
+	assert.Equal(t, fuse.OK, fs.ReadDirPlus(make(<-chan struct{}), &fuse.ReadIn{Offset: 10, Size: 400}, out))
+
+	entryOut := (*fuse.EntryOut)(unsafe.Pointer(&buf[0]))
+	assert.Equal(t, uint64(101), entryOut.NodeId)
+	// _Dirent.Off follows _Dirent.Ino.
+	off := *(*uint64)(unsafe.Pointer(&buf[unsafe.Sizeof(fuse.EntryOut{})+8]))
+	assert.Equal(t, uint64(11), off)
+
+	assert.Equal(t, []uint64{102, 103}, client.forgotten)
+	assert.Empty(t, responses)
+}
+
+func TestReadDirUnexpectedOffset(t *testing.T) {
+	responses := []*pb.ReadDirResponse{{Status: &pb.Status{}, Entries: []*pb.DirEntry{
+		{Ino: 1, Name: []byte("a"), Off: 1},
+		{Ino: 2, Name: []byte("b"), Off: 5},
+	}}}
+	stream := &MockReadDirClient{recvFunc: func() (*pb.ReadDirResponse, error) {
+		if len(responses) == 0 {
+			return nil, io.EOF
+		}
+		res := responses[0]
+		responses = responses[1:]
+		return res, nil
+	}}
+	fs := &fileSystem{client: &directoryHandlersClient{
+		ReadDirFunc: func(ctx context.Context, req *pb.ReadDirRequest, opts ...grpc.CallOption) (pb.RawFileSystem_ReadDirClient, error) {
+			return stream, nil
+		},
+	}}
+
+	buf := make([]byte, 1000)
+	out := fuse.NewDirEntryList(buf, 0)
+	assert.Equal(t, fuse.OK, fs.ReadDir(make(<-chan struct{}), &fuse.ReadIn{Size: 1000}, out))
+
+	// Only "a" made it; the next record is still zero.
+	next := (*[24]byte)(unsafe.Pointer(&buf[32]))
+	assert.Equal(t, [24]byte{}, *next)
+}
+
+func TestReadDirStartError(t *testing.T) {
+	fs := &fileSystem{client: &directoryHandlersClient{
+		ReadDirFunc: func(ctx context.Context, req *pb.ReadDirRequest, opts ...grpc.CallOption) (pb.RawFileSystem_ReadDirClient, error) {
+			return nil, status.Error(codes.Unavailable, "unavailable")
+		},
+	}}
+	out := fuse.NewDirEntryList(make([]byte, 1000), 0)
+	assert.Equal(t, fuse.EIO, fs.ReadDir(make(<-chan struct{}), &fuse.ReadIn{}, out))
+}
@@ -0,0 +1,183 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc2fuse
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/chiyutianyi/grpcfuse/blockcache"
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+// diskCache serves reads from blocks kept on local disk. A file is
+// checked when opened, like NFS does for close-to-open consistency: its
+// handle reads the version the file had then, until this mount changes
+// the file.
+type diskCache struct {
+	cache *blockcache.Cache
+
+	mu      sync.Mutex
+	handles map[uint64]*cachedHandle
+}
+
+type cachedHandle struct {
+	node uint64
+	key  blockcache.Key
+}
+
+// SetCache makes fs keep the blocks it reads in cache. A nil cache turns
+// caching off. It must be called before fs is used.
+func (fs *fileSystem) SetCache(cache *blockcache.Cache) {
+	if cache == nil {
+		fs.diskCache = nil
+		return
+	}
+	fs.diskCache = &diskCache{cache: cache, handles: map[uint64]*cachedHandle{}}
+}
+
+// cacheKey is the version of a file.
+func cacheKey(attr *fuse.Attr) blockcache.Key {
+	return blockcache.Key{
+		Ino:   attr.Ino,
+		Size:  attr.Size,
+		Mtime: int64(attr.Mtime)*1e9 + int64(attr.Mtimensec),
+		Ctime: int64(attr.Ctime)*1e9 + int64(attr.Ctimensec),
+	}
+}
+
+// cacheOpen looks up the version of the file fh was opened on, which its
+// reads are cached as.
+func (fs *fileSystem) cacheOpen(ctx context.Context, header *fuse.InHeader, fh uint64) {
+	dc := fs.diskCache
+	if dc == nil {
+		return
+	}
+	fs.writeBehind.sync(header.NodeId)
+	res, err := fs.client.GetAttr(ctx, &pb.GetAttrRequest{Header: toPbHeader(header)}, fs.opts...)
+	if err != nil || res.Status.GetCode() != 0 {
+		log.Debugf("Not caching node %d: %v %v", header.NodeId, err, res.GetStatus())
+		return
+	}
+	var out fuse.AttrOut
+	toFuseAttrOut(&out, res.AttrOut)
+	key := cacheKey(&out.Attr)
+	dc.cache.Drop(key)
+	dc.open(fh, header.NodeId, key)
+}
+
+// open caches the reads of fh as the version key of node.
+func (dc *diskCache) open(fh, node uint64, key blockcache.Key) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.handles[fh] = &cachedHandle{node: node, key: key}
+}
+
+// cacheRead reads in from the cache, fetching the blocks it misses from
+// the server if fetch is set; a miss fails with EIO otherwise. It returns
+// false for the reads it does not cache.
+func (fs *fileSystem) cacheRead(ctx context.Context, in *fuse.ReadIn, fetch bool) ([]byte, fuse.Status, bool) {
+	dc := fs.diskCache
+	if dc == nil {
+		return nil, fuse.OK, false
+	}
+	dc.mu.Lock()
+	h := dc.handles[in.Fh]
+	dc.mu.Unlock()
+	if h == nil {
+		return nil, fuse.OK, false
+	}
+
+	key, bs := h.key, uint64(dc.cache.BlockSize())
+	end := in.Offset + uint64(in.Size)
+	if end > key.Size {
+		end = key.Size
+	}
+	var data []byte
+	for off := in.Offset; off < end; {
+		index := off / bs
+		start := index * bs
+		size := key.Size - start
+		if size > bs {
+			size = bs
+		}
+		block, ok := dc.cache.Get(key, index)
+		if !ok && !fetch {
+			return nil, fuse.EIO, true
+		}
+		if !ok {
+			blockIn := *in
+			blockIn.Offset, blockIn.Size = start, uint32(size)
+			var st fuse.Status
+			if block, st = fs.read(ctx, toPbReadIn(&blockIn)); st != fuse.OK {
+				return nil, st, true
+			}
+			if uint64(len(block)) == size {
+				if err := dc.cache.Put(key, index, block); err != nil {
+					log.Warnf("Caching node %d: %v", in.NodeId, err)
+				}
+			}
+		}
+		if uint64(len(block)) != size {
+			// The file changed on the server since it was opened.
+			dc.release(in.Fh)
+			return nil, fuse.OK, false
+		}
+		to := end - start
+		if to > size {
+			to = size
+		}
+		data = append(data, block[off-start:to]...)
+		off = start + to
+	}
+	return data, fuse.OK, true
+}
+
+// invalidate stops caching the handles of node, once it has changed.
+func (dc *diskCache) invalidate(node uint64) {
+	if dc == nil {
+		return
+	}
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	for fh, h := range dc.handles {
+		if h.node == node {
+			delete(dc.handles, fh)
+		}
+	}
+}
+
+func (dc *diskCache) release(fh uint64) {
+	if dc == nil {
+		return
+	}
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	delete(dc.handles, fh)
+}
+
+// changed drops what was kept of node once this mount changed it.
+func (fs *fileSystem) changed(node uint64) {
+	fs.readAhead.invalidate(node)
+	fs.diskCache.invalidate(node)
+	fs.attrCache.invalidate(node)
+	fs.smallFiles.changed(node)
+	fs.inlined.changed(node)
+}
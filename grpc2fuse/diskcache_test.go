@@ -0,0 +1,102 @@
+package grpc2fuse
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/chiyutianyi/grpcfuse/blockcache"
+	"github.com/chiyutianyi/grpcfuse/exports"
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+func TestDiskCache(t *testing.T) {
+	dialOpts := append(serve(t), grpc.WithInsecure())
+	dialOpts = append(dialOpts, exports.DialOptions("a")...)
+	dir := t.TempDir()
+	// mount returns a file system with the cache in dir, as a new mount
+	// would have.
+	mount := func() (*fileSystem, *counter, *blockcache.Cache) {
+		c := &counter{calls: map[string]int{}}
+		conn, err := grpc.Dial("bufconn", append(dialOpts, c.dialOptions()...)...)
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		cache, err := blockcache.Open(dir, &blockcache.Options{BlockSize: 4096})
+		require.NoError(t, err)
+		fs := NewFileSystem(pb.NewRawFileSystemClient(conn))
+		fs.SetCache(cache)
+		return fs, c, cache
+	}
+	fs, c, cache := mount()
+
+	var out fuse.CreateOut
+	require.Equal(t, fuse.OK, fs.Create(nil, &fuse.CreateIn{InHeader: fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}, Flags: 2, Mode: 0644}, "file", &out))
+	header := fuse.InHeader{NodeId: out.NodeId}
+	contents := bytes.Repeat([]byte("0123456789"), 1000)
+	_, st := fs.Write(nil, &fuse.WriteIn{InHeader: header, Fh: out.Fh, Size: uint32(len(contents))}, contents)
+	require.Equal(t, fuse.OK, st)
+	fs.Release(nil, &fuse.ReleaseIn{InHeader: header, Fh: out.Fh})
+
+	open := func(fs *fileSystem) uint64 {
+		var out fuse.OpenOut
+		require.Equal(t, fuse.OK, fs.Open(nil, &fuse.OpenIn{InHeader: header, Flags: 2}, &out))
+		return out.Fh
+	}
+	read := func(fs *fileSystem, fh, off uint64, size uint32) string {
+		res, st := fs.Read(nil, &fuse.ReadIn{InHeader: header, Fh: fh, Offset: off, Size: size}, nil)
+		require.Equal(t, fuse.OK, st)
+		data, _ := res.Bytes(nil)
+		return string(data)
+	}
+	readAll := func(fs *fileSystem, fh uint64) string {
+		var all string
+		for off := uint64(0); off < 12000; off += 1000 {
+			all += read(fs, fh, off, 1000)
+		}
+		return all
+	}
+
+	// The blocks are fetched once.
+	fh := open(fs)
+	assert.Equal(t, string(contents), readAll(fs, fh))
+	assert.Equal(t, 3, c.get("Read"))
+	assert.Equal(t, string(contents), readAll(fs, fh))
+	assert.Equal(t, "3456", read(fs, fh, 4093, 4))
+	assert.Equal(t, "", read(fs, fh, 20000, 4))
+	assert.Equal(t, 3, c.get("Read"))
+	assert.Equal(t, blockcache.Stats{Hits: 23, Misses: 3, Blocks: 3, Bytes: 10000}, cache.Stats())
+
+	// Writes of this mount go around the cache.
+	_, st = fs.Write(nil, &fuse.WriteIn{InHeader: header, Fh: fh, Offset: 5000, Size: 4}, []byte("abcd"))
+	require.Equal(t, fuse.OK, st)
+	assert.Equal(t, "abcd", read(fs, fh, 5000, 4))
+	assert.Equal(t, 4, c.get("Read"))
+	fs.Release(nil, &fuse.ReleaseIn{InHeader: header, Fh: fh})
+	assert.Empty(t, fs.diskCache.handles)
+
+	// A new version is cached anew, and the old one dropped.
+	fh = open(fs)
+	changed := readAll(fs, fh)
+	assert.Equal(t, "abcd", changed[5000:5004])
+	assert.Equal(t, 4+3, c.get("Read"))
+	assert.Equal(t, 3, cache.Stats().Blocks)
+	fs.Release(nil, &fuse.ReleaseIn{InHeader: header, Fh: fh})
+
+	// The cache outlives the mount.
+	fs2, c2, _ := mount()
+	fh = open(fs2)
+	assert.Equal(t, changed, readAll(fs2, fh))
+	assert.Zero(t, c2.get("Read"))
+	assert.Equal(t, 1, c2.get("GetAttr"))
+
+	// A change from another client shows on the next open.
+	_, st = fs.Write(nil, &fuse.WriteIn{InHeader: header, Fh: open(fs), Size: 4}, []byte("ABCD"))
+	require.Equal(t, fuse.OK, st)
+	assert.Equal(t, "0123", read(fs2, fh, 0, 4))
+	assert.Equal(t, "ABCD", read(fs2, open(fs2), 0, 4))
+	assert.Equal(t, 1, c2.get("Read"))
+}
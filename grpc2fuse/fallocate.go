@@ -24,10 +24,16 @@ import (
 
 func (fs *fileSystem) Fallocate(cancel <-chan struct{}, input *fuse.FallocateIn) (code fuse.Status) {
 	ctx := newContext(cancel)
+	fs.writeBehind.sync(input.NodeId)
+	defer fs.changed(input.NodeId)
+	fh, st := fs.handle(ctx, input.Fh)
+	if st != fuse.OK {
+		return st
+	}
 
 	res, err := fs.client.Fallocate(ctx, &pb.FallocateRequest{
 		Header:  toPbHeader(&input.InHeader),
-		Fh:      input.Fh,
+		Fh:      fh,
 		Offset:  input.Offset,
 		Length:  input.Length,
 		Mode:    input.Mode,
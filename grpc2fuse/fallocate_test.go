@@ -12,12 +12,12 @@ import (
 	"github.com/chiyutianyi/grpcfuse/pb"
 )
 
-type MockRawFileSystemClient struct {
+type fallocateClient struct {
 	mock.Mock
 	pb.RawFileSystemClient
 }
 
-func (m *MockRawFileSystemClient) Fallocate(ctx context.Context, in *pb.FallocateRequest, opts ...grpc.CallOption) (*pb.FallocateResponse, error) {
+func (m *fallocateClient) Fallocate(ctx context.Context, in *pb.FallocateRequest, opts ...grpc.CallOption) (*pb.FallocateResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -79,7 +79,7 @@ func TestFileSystem_Fallocate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockClient := &MockRawFileSystemClient{}
+			mockClient := &fallocateClient{}
 			fs := NewFileSystem(mockClient)
 
 			expectedRequest := &pb.FallocateRequest{
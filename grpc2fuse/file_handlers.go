@@ -17,7 +17,8 @@
 package grpc2fuse
 
 import (
-	"io"
+	"context"
+	"syscall"
 
 	"github.com/chiyutianyi/grpcfuse/pb"
 
@@ -26,6 +27,17 @@ import (
 
 func (fs *fileSystem) Open(cancel <-chan struct{}, in *fuse.OpenIn, out *fuse.OpenOut) (status fuse.Status) {
 	ctx := newContext(cancel)
+	if st, ok := fs.openSmall(ctx, in, out); ok {
+		return st
+	}
+	return fs.open(ctx, in, out)
+}
+
+func (fs *fileSystem) open(ctx context.Context, in *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
+	if in.Flags&syscall.O_TRUNC != 0 {
+		fs.writeBehind.sync(in.NodeId)
+		defer fs.changed(in.NodeId)
+	}
 
 	res, err := fs.client.Open(ctx, &pb.OpenRequest{
 		OpenIn: &pb.OpenIn{
@@ -43,33 +55,40 @@ func (fs *fileSystem) Open(cancel <-chan struct{}, in *fuse.OpenIn, out *fuse.Op
 	}
 
 	toFuseOpenOut(out, res.OpenOut)
+	fs.inlined.opened(out.Fh, in.NodeId, res)
+	fs.cacheOpen(ctx, &in.InHeader, out.Fh)
 	return fuse.OK
 }
 
 func (fs *fileSystem) Read(cancel <-chan struct{}, input *fuse.ReadIn, buf []byte) (fuse.ReadResult, fuse.Status) {
 	ctx := newContext(cancel)
-
-	stream, err := fs.client.Read(ctx, &pb.ReadRequest{ReadIn: toPbReadIn(input)}, fs.opts...)
-
-	if st := dealGrpcError("Read", err); st != fuse.OK {
+	if data, ok := fs.smallFiles.readSnapshot(input); ok {
+		return fuse.ReadResultData(data), fuse.OK
+	}
+	fh, st := fs.handle(ctx, input.Fh)
+	if st != fuse.OK {
 		return nil, st
 	}
+	if fh != input.Fh {
+		in := *input
+		in.Fh = fh
+		input = &in
+	}
+	fs.writeBehind.sync(input.NodeId)
+	if data, ok := fs.inlined.read(input); ok {
+		return fuse.ReadResultData(data), fuse.OK
+	}
 
-	var rs []byte
-
-	for {
-		res, err := stream.Recv()
-		if err == io.EOF {
-			break
-		}
-		if st := dealGrpcError("Read", err); st != fuse.OK {
-			return nil, st
+	rs, st, cached := fs.cacheRead(ctx, input, true)
+	if !cached {
+		if fs.readAhead != nil {
+			rs, st = fs.readAheadRead(ctx, input)
+		} else {
+			rs, st = fs.read(ctx, toPbReadIn(input))
 		}
-		if res.Status.GetCode() != 0 {
-			return nil, fuse.Status(res.Status.GetCode())
-		}
-
-		rs = append(rs, res.Buffer...)
+	}
+	if st != fuse.OK {
+		return nil, st
 	}
 
 	return fuse.ReadResultData(rs), fuse.OK
@@ -77,11 +96,16 @@ func (fs *fileSystem) Read(cancel <-chan struct{}, input *fuse.ReadIn, buf []byt
 
 func (fs *fileSystem) Lseek(cancel <-chan struct{}, in *fuse.LseekIn, out *fuse.LseekOut) fuse.Status {
 	ctx := newContext(cancel)
+	fs.writeBehind.sync(in.NodeId)
+	fh, st := fs.handle(ctx, in.Fh)
+	if st != fuse.OK {
+		return st
+	}
 
 	res, err := fs.client.Lseek(ctx,
 		&pb.LseekRequest{
 			Header:  toPbHeader(&in.InHeader),
-			Fh:      in.Fh,
+			Fh:      fh,
 			Offset:  in.Offset,
 			Whence:  in.Whence,
 			Padding: in.Padding,
@@ -24,6 +24,8 @@ import (
 
 func (fs *fileSystem) Create(cancel <-chan struct{}, input *fuse.CreateIn, name string, out *fuse.CreateOut) (code fuse.Status) {
 	ctx := newContext(cancel)
+	fs.attrCache.invalidateEntry(input.NodeId, name)
+	gen := fs.attrCache.generation()
 
 	res, err := fs.client.Create(ctx, &pb.CreateRequest{
 		Header: toPbHeader(&input.InHeader),
@@ -39,6 +41,9 @@ func (fs *fileSystem) Create(cancel <-chan struct{}, input *fuse.CreateIn, name
 		return fuse.Status(res.Status.GetCode())
 	}
 	toFuseEntryOut(&out.EntryOut, res.EntryOut)
+	fs.attrCache.made(gen, input.NodeId, name, &out.EntryOut)
 	toFuseOpenOut(&out.OpenOut, res.OpenOut)
+	fs.smallFiles.saw(out.NodeId, &out.Attr)
+	fs.create(input, out)
 	return fuse.Status(res.Status.GetCode())
 }
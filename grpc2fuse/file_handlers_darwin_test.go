@@ -8,13 +8,15 @@ import (
 	"github.com/hanwen/go-fuse/v2/fuse"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
 )
 
-type MockRawFileSystemClient struct {
+type fileHandlersDarwinClient struct {
 	mock.Mock
+	pb.RawFileSystemClient
 }
 
-func (m *MockRawFileSystemClient) Create(ctx context.Context, in *pb.CreateRequest, opts ...interface{}) (*pb.CreateResponse, error) {
+func (m *fileHandlersDarwinClient) Create(ctx context.Context, in *pb.CreateRequest, opts ...grpc.CallOption) (*pb.CreateResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -23,7 +25,7 @@ func (m *MockRawFileSystemClient) Create(ctx context.Context, in *pb.CreateReque
 }
 
 func TestCreate(t *testing.T) {
-	mockClient := new(MockRawFileSystemClient)
+	mockClient := new(fileHandlersDarwinClient)
 	fs := &fileSystem{
 		client: mockClient,
 	}
@@ -14,6 +14,7 @@ import (
 
 type mockRawFileSystemClient struct {
     mock.Mock
+    pb.RawFileSystemClient
 }
 
 func (m *mockRawFileSystemClient) Create(ctx context.Context, in *pb.CreateRequest, opts ...grpc.CallOption) (*pb.CreateResponse, error) {
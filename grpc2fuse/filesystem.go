@@ -18,6 +18,7 @@ package grpc2fuse
 
 import (
 	"context"
+	"sync"
 
 	"github.com/hanwen/go-fuse/v2/fuse"
 	log "github.com/sirupsen/logrus"
@@ -34,7 +35,29 @@ type fileSystem struct {
 	fuse.RawFileSystem
 
 	client pb.RawFileSystemClient
+	// pool, if not nil, carries the reads and writes.
+	pool *Pool
+	// readAhead, if not nil, fetches past sequential reads.
+	readAhead *readAhead
+	// writeBehind, if not nil, queues writes.
+	writeBehind *writeBehind
+	// diskCache, if not nil, keeps what is read on disk.
+	diskCache *diskCache
+	// attrCache, if not nil, answers lookups, attributes and symlinks.
+	attrCache *attrCache
+	// smallFiles, if not nil, collapses the calls of small files.
+	smallFiles *smallFiles
+	// inlined, if not nil, answers reads from the data returned by opens.
+	inlined *inlined
+	// listed, if not nil, is told the entries each ReadDir and
+	// ReadDirPlus hands to the kernel.
+	listed func(in *fuse.ReadIn, entries []*pb.DirEntry)
 	opts   []grpc.CallOption
+
+	mu sync.Mutex
+	// server, set by Init, is told to drop what the kernel keeps of
+	// nodes and names invalidated from outside.
+	server *fuse.Server
 }
 
 // NewFileSystem creates a new file system.
@@ -42,6 +65,7 @@ func NewFileSystem(client pb.RawFileSystemClient, opts ...grpc.CallOption) *file
 	return &fileSystem{
 		RawFileSystem: fuse.NewDefaultRawFileSystem(),
 		client:        client,
+		inlined:       newInlined(),
 		opts:          opts,
 	}
 }
@@ -13,12 +13,12 @@ import (
 )
 
 // Mock RawFileSystemClient
-type mockRawFileSystemClient struct {
+type filesystemClient struct {
 	mock.Mock
 	pb.RawFileSystemClient
 }
 
-func (m *mockRawFileSystemClient) String(ctx context.Context, in *pb.StringRequest, opts ...grpc.CallOption) (*pb.StringResponse, error) {
+func (m *filesystemClient) String(ctx context.Context, in *pb.StringRequest, opts ...grpc.CallOption) (*pb.StringResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -27,7 +27,7 @@ func (m *mockRawFileSystemClient) String(ctx context.Context, in *pb.StringReque
 }
 
 func TestNewFileSystem(t *testing.T) {
-	mockClient := &mockRawFileSystemClient{}
+	mockClient := &filesystemClient{}
 	opts := []grpc.CallOption{grpc.WaitForReady(true)}
 
 	fs := NewFileSystem(mockClient, opts...)
@@ -40,12 +40,12 @@ func TestNewFileSystem(t *testing.T) {
 func TestFileSystem_String(t *testing.T) {
 	tests := []struct {
 		name     string
-		setup    func(*mockRawFileSystemClient)
+		setup    func(*filesystemClient)
 		expected string
 	}{
 		{
 			name: "success",
-			setup: func(m *mockRawFileSystemClient) {
+			setup: func(m *filesystemClient) {
 				m.On("String", mock.Anything, &pb.StringRequest{}, mock.Anything).
 					Return(&pb.StringResponse{Value: "test-fs"}, nil)
 			},
@@ -53,7 +53,7 @@ func TestFileSystem_String(t *testing.T) {
 		},
 		{
 			name: "grpc error",
-			setup: func(m *mockRawFileSystemClient) {
+			setup: func(m *filesystemClient) {
 				m.On("String", mock.Anything, &pb.StringRequest{}, mock.Anything).
 					Return(&pb.StringResponse{}, status.Error(codes.Internal, "internal error"))
 			},
@@ -61,7 +61,7 @@ func TestFileSystem_String(t *testing.T) {
 		},
 		{
 			name: "empty response",
-			setup: func(m *mockRawFileSystemClient) {
+			setup: func(m *filesystemClient) {
 				m.On("String", mock.Anything, &pb.StringRequest{}, mock.Anything).
 					Return(&pb.StringResponse{}, nil)
 			},
@@ -71,7 +71,7 @@ func TestFileSystem_String(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockClient := &mockRawFileSystemClient{}
+			mockClient := &filesystemClient{}
 			tt.setup(mockClient)
 
 			fs := NewFileSystem(mockClient)
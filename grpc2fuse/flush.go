@@ -24,15 +24,40 @@ import (
 
 func (fs *fileSystem) Flush(cancel <-chan struct{}, input *fuse.FlushIn) (code fuse.Status) {
 	ctx := newContext(cancel)
+	fh, local := fs.smallFiles.opened(input.Fh)
+	if local && fh == 0 {
+		// Nothing of the server is open.
+		return fuse.OK
+	}
+	release := local && fs.smallFiles.closable(input.Fh)
+	if writes, held := fs.writeBehind.takeHeld(fh); held {
+		st, sent, released := fs.flushWrites(ctx, input, fh, writes, release)
+		fs.writeBehind.putBack(fh, writes, sent)
+		if sent {
+			if pending := fs.writeBehind.flush(fh); pending != fuse.OK {
+				st = pending
+			}
+			if released {
+				fs.smallFiles.closed(input.Fh)
+				fs.released(fh)
+			}
+			return st
+		}
+	}
+	pending := fs.writeBehind.flush(fh)
 
 	res, err := fs.client.Flush(ctx, &pb.FlushRequest{
 		Header:    toPbHeader(&input.InHeader),
-		Fh:        input.Fh,
+		Fh:        fh,
 		Unused:    input.Unused,
 		Padding:   input.Padding,
 		LockOwner: input.LockOwner,
 	}, fs.opts...)
 
+	if pending != fuse.OK {
+		// The write that failed comes first.
+		return pending
+	}
 	if st := dealGrpcError("Flush", err); st != fuse.OK {
 		return st
 	}
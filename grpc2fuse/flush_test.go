@@ -11,13 +11,13 @@ import (
 	"google.golang.org/grpc"
 )
 
-// MockRawFileSystemClient is a mock for RawFileSystemClient
-type MockRawFileSystemClient struct {
+// flushClient is a mock for RawFileSystemClient
+type flushClient struct {
 	mock.Mock
 	pb.RawFileSystemClient
 }
 
-func (m *MockRawFileSystemClient) Flush(ctx context.Context, in *pb.FlushRequest, opts ...grpc.CallOption) (*pb.FlushResponse, error) {
+func (m *flushClient) Flush(ctx context.Context, in *pb.FlushRequest, opts ...grpc.CallOption) (*pb.FlushResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -71,7 +71,7 @@ func TestFileSystem_Flush(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockClient := new(MockRawFileSystemClient)
+			mockClient := new(flushClient)
 			fs := &fileSystem{
 				client: mockClient,
 			}
@@ -23,6 +23,10 @@ import (
 )
 
 func (fs *fileSystem) Forget(nodeid, nlookup uint64) {
+	fs.smallFiles.forget(nodeid)
+	if nlookup = fs.attrCache.forget(nodeid, nlookup); nlookup == 0 {
+		return
+	}
 	_, err := fs.client.Forget(context.TODO(), &pb.ForgetRequest{Nodeid: nodeid, Nlookup: nlookup}, fs.opts...)
 	dealGrpcError("Forget", err)
 }
@@ -24,14 +24,23 @@ import (
 
 func (fs *fileSystem) Fsync(cancel <-chan struct{}, input *fuse.FsyncIn) (code fuse.Status) {
 	ctx := newContext(cancel)
+	fh, st := fs.handle(ctx, input.Fh)
+	if st != fuse.OK {
+		return st
+	}
+	pending := fs.writeBehind.flush(fh)
 
 	res, err := fs.client.Fsync(ctx, &pb.FsyncRequest{
 		Header:     toPbHeader(&input.InHeader),
-		Fh:         input.Fh,
+		Fh:         fh,
 		FsyncFlags: input.FsyncFlags,
 		Padding:    input.Padding,
 	}, fs.opts...)
 
+	if pending != fuse.OK {
+		// The write that failed comes first.
+		return pending
+	}
 	if st := dealGrpcError("Fsync", err); st != fuse.OK {
 		return st
 	}
@@ -49,9 +49,7 @@ func TestToPbReadIn(t *testing.T) {
 					Opcode: 1,
 					Unique: 123,
 					NodeId: 456,
-					Uid:    1000,
-					Gid:    1000,
-					Pid:    12345,
+					Caller: fuse.Caller{Owner: fuse.Owner{Uid: 1000, Gid: 1000}, Pid: 12345},
 				},
 				Fh:        789,
 				Offset:    1000,
@@ -82,9 +80,9 @@ func TestToPbReadIn(t *testing.T) {
 			name: "zero values",
 			in: &fuse.ReadIn{
 				InHeader: fuse.InHeader{},
-				Fh:      0,
-				Offset:  0,
-				Size:    0,
+				Fh:       0,
+				Offset:   0,
+				Size:     0,
 			},
 			want: &pb.ReadIn{
 				Header: &pb.InHeader{
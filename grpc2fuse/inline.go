@@ -0,0 +1,105 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc2fuse
+
+import (
+	"sync"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+// inlined keeps the small files a server returns with read-only opens,
+// which answers the first read of those handles unless the file changed
+// through this mount meanwhile.
+type inlined struct {
+	mu      sync.Mutex
+	handles map[uint64]*inlineData
+}
+
+type inlineData struct {
+	node uint64
+	data []byte
+	// eof is set if data is all of the file.
+	eof bool
+}
+
+func newInlined() *inlined {
+	return &inlined{handles: map[uint64]*inlineData{}}
+}
+
+// opened keeps the data of res, the open of fh.
+func (il *inlined) opened(fh, node uint64, res *pb.OpenResponse) {
+	if il == nil || (len(res.InlineData) == 0 && !res.InlineEof) {
+		return
+	}
+	il.mu.Lock()
+	defer il.mu.Unlock()
+	il.handles[fh] = &inlineData{node: node, data: res.InlineData, eof: res.InlineEof}
+}
+
+// read answers in, the first read of its handle, from the data kept for
+// it if it covers in. Later reads go to the server, which sees the file
+// grow.
+func (il *inlined) read(in *fuse.ReadIn) ([]byte, bool) {
+	if il == nil {
+		return nil, false
+	}
+	il.mu.Lock()
+	d := il.handles[in.Fh]
+	delete(il.handles, in.Fh)
+	il.mu.Unlock()
+	if d == nil || d.node != in.NodeId {
+		return nil, false
+	}
+	size := uint64(len(d.data))
+	end := in.Offset + uint64(in.Size)
+	if end > size {
+		if !d.eof {
+			return nil, false
+		}
+		end = size
+	}
+	if in.Offset >= end {
+		return nil, d.eof
+	}
+	return d.data[in.Offset:end], true
+}
+
+func (il *inlined) release(fh uint64) {
+	if il == nil {
+		return
+	}
+	il.mu.Lock()
+	defer il.mu.Unlock()
+	delete(il.handles, fh)
+}
+
+// changed drops the data kept of node.
+func (il *inlined) changed(node uint64) {
+	if il == nil {
+		return
+	}
+	il.mu.Lock()
+	defer il.mu.Unlock()
+	for fh, d := range il.handles {
+		if d.node == node {
+			delete(il.handles, fh)
+		}
+	}
+}
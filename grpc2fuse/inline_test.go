@@ -0,0 +1,85 @@
+package grpc2fuse
+
+import (
+	"context"
+	"net"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/chiyutianyi/grpcfuse/exports"
+	"github.com/chiyutianyi/grpcfuse/fuse2grpc"
+	"github.com/chiyutianyi/grpcfuse/memfs"
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+func TestInlined(t *testing.T) {
+	l := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	srv := fuse2grpc.NewServer(memfs.New(nil, nil))
+	srv.SetInlineSize(4096)
+	r := exports.New()
+	r.Set(map[string]pb.RawFileSystemServer{"a": srv}, "")
+	r.Register(s)
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+	dialOpts := []grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return l.DialContext(ctx) }),
+		grpc.WithInsecure(),
+	}
+	dialOpts = append(dialOpts, exports.DialOptions("a")...)
+	c := &counter{calls: map[string]int{}}
+	conn, err := grpc.Dial("bufconn", append(dialOpts, c.dialOptions()...)...)
+	require.NoError(t, err)
+	defer conn.Close()
+	fs := NewFileSystem(pb.NewRawFileSystemClient(conn))
+
+	small := writeSmall(t, fs, "small", "hello world")
+	large := writeSmall(t, fs, "large", strings.Repeat("x", 5000))
+	open := func(node uint64, flags uint32) (*fuse.OpenIn, uint64) {
+		in := &fuse.OpenIn{InHeader: fuse.InHeader{NodeId: node}, Flags: flags}
+		var out fuse.OpenOut
+		require.Equal(t, fuse.OK, fs.Open(nil, in, &out))
+		return in, out.Fh
+	}
+
+	// The first read of a small file is answered from its open.
+	in, fh := open(small, syscall.O_RDONLY)
+	assert.Equal(t, "hello world", readSmall(t, fs, in, fh, 0))
+	assert.Equal(t, 0, c.get("Read"))
+
+	// Later reads see the file grow on the server.
+	other := NewFileSystem(pb.NewRawFileSystemClient(conn))
+	_, wfh := open(small, syscall.O_WRONLY)
+	_, st := other.Write(nil, &fuse.WriteIn{InHeader: in.InHeader, Fh: wfh, Offset: 11, Size: 1}, []byte("!"))
+	require.Equal(t, fuse.OK, st)
+	assert.Equal(t, "!", readSmall(t, fs, in, fh, 11))
+	assert.Equal(t, 1, c.get("Read"))
+	fs.Release(nil, &fuse.ReleaseIn{InHeader: in.InHeader, Fh: wfh})
+	fs.Release(nil, &fuse.ReleaseIn{InHeader: in.InHeader, Fh: fh})
+
+	// Large files are read as usual.
+	in, fh = open(large, syscall.O_RDONLY)
+	res, st := fs.Read(nil, &fuse.ReadIn{InHeader: in.InHeader, Fh: fh, Size: 100}, make([]byte, 100))
+	require.Equal(t, fuse.OK, st)
+	assert.Equal(t, 100, res.Size())
+	assert.Equal(t, 2, c.get("Read"))
+	fs.Release(nil, &fuse.ReleaseIn{InHeader: in.InHeader, Fh: fh})
+
+	// Files changed through the mount too.
+	in, fh = open(small, syscall.O_RDONLY)
+	writer, wfh := open(small, syscall.O_WRONLY)
+	_, st = fs.Write(nil, &fuse.WriteIn{InHeader: writer.InHeader, Fh: wfh, Size: 5}, []byte("HELLO"))
+	require.Equal(t, fuse.OK, st)
+	assert.Equal(t, "HELLO world!", readSmall(t, fs, in, fh, 0))
+	assert.Equal(t, 3, c.get("Read"))
+	fs.Release(nil, &fuse.ReleaseIn{InHeader: writer.InHeader, Fh: wfh})
+	fs.Release(nil, &fuse.ReleaseIn{InHeader: in.InHeader, Fh: fh})
+	assert.Empty(t, fs.inlined.handles)
+}
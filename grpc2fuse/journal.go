@@ -0,0 +1,639 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc2fuse
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/chiyutianyi/grpcfuse/journal"
+)
+
+const (
+	renameNoReplace = 1
+	renameExchange  = 2
+)
+
+// ConflictPolicy is what replaying the journal of a mount does with a
+// change that meets another made on the server while it was offline.
+type ConflictPolicy int
+
+const (
+	// KeepBoth moves what is on the server aside, to the same name with
+	// ".conflict-<time>" before its extension, and makes the change. The
+	// removal of a file changed on the server is dropped.
+	KeepBoth ConflictPolicy = iota
+	// LastWriterWins makes the change over what is on the server.
+	LastWriterWins
+	// FailConflicts drops the change; the data of a file written offline
+	// stays in the journal until the conflicts are cleared.
+	FailConflicts
+)
+
+var conflictPolicies = []string{"keep-both", "last-writer-wins", "fail"}
+
+func (p ConflictPolicy) String() string {
+	if p >= 0 && int(p) < len(conflictPolicies) {
+		return conflictPolicies[p]
+	}
+	return fmt.Sprintf("ConflictPolicy(%d)", int(p))
+}
+
+// ParseConflictPolicy parses "keep-both", "last-writer-wins" or "fail".
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	for i, name := range conflictPolicies {
+		if s == name {
+			return ConflictPolicy(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown conflict policy %q", s)
+}
+
+// localFile is a handle on the data in the journal of a file written
+// offline.
+type localFile struct {
+	f     *os.File
+	data  string
+	node  uint64
+	write bool
+}
+
+// version is the version of a file with attr.
+func version(attr *fuse.Attr) *journal.Version {
+	key := cacheKey(attr)
+	return &journal.Version{Ino: key.Ino, Size: key.Size, Mtime: key.Mtime, Ctime: key.Ctime}
+}
+
+// base is the version on the server that r is a copy of.
+func base(r *metaRecord) *journal.Version {
+	if r.Changed {
+		return r.Base
+	}
+	return version(&r.Attr)
+}
+
+// changing marks r as changed offline.
+func changing(r *metaRecord) {
+	if !r.Changed {
+		r.Base, r.Changed = version(&r.Attr), true
+	}
+}
+
+// touched sets the modification and change times of attr to now.
+func touched(attr *fuse.Attr) {
+	now := time.Now()
+	attr.SetTimes(nil, &now, &now)
+}
+
+// change makes a change offline, in order with the others and with
+// their replay. Without a journal changes fail with EROFS, as they do on
+// the nodes looked up offline once the mount is back online.
+func (o *offlineFS) change(f func() fuse.Status) fuse.Status {
+	if o.journal == nil {
+		return fuse.EROFS
+	}
+	o.jmu.Lock()
+	defer o.jmu.Unlock()
+	if !o.isOffline() {
+		return fuse.EROFS
+	}
+	return f()
+}
+
+// journals reports whether changes are journaled now.
+func (o *offlineFS) journals() bool {
+	return o.journal != nil && o.isOffline()
+}
+
+// logOp appends op to the journal; jmu is held.
+func (o *offlineFS) logOp(op journal.Op) fuse.Status {
+	if _, err := o.journal.Append(op); err != nil {
+		log.Warnf("Journaling %s: %v", op, err)
+		return fuse.EIO
+	}
+	return fuse.OK
+}
+
+// child returns the path of the directory parent and of name in it.
+func (o *offlineFS) child(parent uint64, name string) (string, string, fuse.Status) {
+	r, st := o.record(parent)
+	if st != fuse.OK {
+		return "", "", st
+	}
+	if !r.Attr.IsDir() {
+		return "", "", fuse.ENOTDIR
+	}
+	return r.Path, path.Join(r.Path, name), fuse.OK
+}
+
+// exists returns the record of p, if p is known to exist.
+func (o *offlineFS) exists(p string) (metaRecord, bool) {
+	r, ok := o.meta.get(p)
+	return r, ok && r.Attr.Mode != 0
+}
+
+// newAttr returns the attributes of a file made offline.
+func (o *offlineFS) newAttr(mode uint32, owner fuse.Owner) fuse.Attr {
+	now := time.Now()
+	attr := fuse.Attr{Ino: o.handle(), Mode: mode, Nlink: 1, Owner: owner}
+	attr.SetTimes(&now, &now, &now)
+	if attr.IsDir() {
+		attr.Nlink = 2
+	}
+	return attr
+}
+
+// made keeps r, made offline as name in the directory dir, and looks it
+// up for the kernel.
+func (o *offlineFS) made(parent uint64, dir, name string, r metaRecord, out *fuse.EntryOut) fuse.Status {
+	o.meta.remove(r.Path)
+	o.meta.update(r.Path, func(old *metaRecord) { *old = r })
+	o.enter(dir, name, &r.Attr)
+	return o.lookup(parent, name, out)
+}
+
+// enter adds name with attr to the listing of dir.
+func (o *offlineFS) enter(dir, name string, attr *fuse.Attr) {
+	o.meta.update(dir, func(r *metaRecord) {
+		touched(&r.Attr)
+		if r.Listed {
+			r.Entries = append(withoutEntry(r.Entries, name), metaEntry{Name: name, Ino: attr.Ino, Mode: attr.Mode})
+		}
+	})
+}
+
+// leave removes name from the listing of dir.
+func (o *offlineFS) leave(dir, name string) {
+	o.meta.update(dir, func(r *metaRecord) {
+		touched(&r.Attr)
+		r.Entries = withoutEntry(r.Entries, name)
+	})
+}
+
+func withoutEntry(entries []metaEntry, name string) []metaEntry {
+	var kept []metaEntry
+	for _, e := range entries {
+		if e.Name != name {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+// empty reports whether the directory r is known to be empty.
+func empty(r *metaRecord) bool {
+	for _, e := range r.Entries {
+		if e.Name != "." && e.Name != ".." {
+			return false
+		}
+	}
+	return r.Listed
+}
+
+// gone detaches the nodes at p and below it from their paths, once
+// removed offline.
+func (o *offlineFS) gone(p string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for id, n := range o.nodes {
+		if n.path == p || strings.HasPrefix(n.path, p+"/") {
+			if o.ids[n.path] == id {
+				delete(o.ids, n.path)
+			}
+			n.path = ""
+		}
+	}
+}
+
+// dirty gives the regular file at p data of its own in the journal, a
+// copy of what is cached of it unless trunc, and journals putting it
+// unless that is pending; jmu is held.
+func (o *offlineFS) dirty(p string, trunc bool, owner fuse.Owner) (string, fuse.Status) {
+	r, ok := o.exists(p)
+	if !ok {
+		return "", fuse.EIO
+	}
+	if r.Attr.IsDir() {
+		return "", fuse.EISDIR
+	}
+	if !r.Attr.IsRegular() {
+		return "", fuse.EINVAL
+	}
+	data := r.Data
+	if data == "" {
+		var f *os.File
+		var err error
+		if data, f, err = o.journal.NewData(); err != nil {
+			log.Warnf("Writing %s offline: %v", p, err)
+			return "", fuse.EIO
+		}
+		if !trunc {
+			err = o.copyCached(f, &r.Attr)
+		}
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			log.Warnf("Writing %s offline: %v", p, err)
+			os.Remove(f.Name())
+			return "", fuse.EIO
+		}
+	} else if trunc {
+		if err := os.Truncate(o.journal.DataPath(data), 0); err != nil {
+			log.Warnf("Writing %s offline: %v", p, err)
+			return "", fuse.EIO
+		}
+	}
+	if !o.putPending(data) {
+		op := journal.Op{Kind: journal.Put, Path: p, Data: data, Mode: r.Attr.Mode & 07777, Uid: owner.Uid, Gid: owner.Gid, Base: base(&r)}
+		if st := o.logOp(op); st != fuse.OK {
+			if r.Data == "" {
+				os.Remove(o.journal.DataPath(data))
+			}
+			return "", st
+		}
+	}
+	o.meta.update(p, func(r *metaRecord) {
+		changing(r)
+		r.Data = data
+		if trunc {
+			r.Attr.Size, r.Attr.Blocks = 0, 0
+			touched(&r.Attr)
+		}
+	})
+	return data, fuse.OK
+}
+
+// putPending reports whether a put of data is in the journal.
+func (o *offlineFS) putPending(data string) bool {
+	for _, op := range o.journal.Pending() {
+		if op.Kind == journal.Put && op.Data == data {
+			return true
+		}
+	}
+	return false
+}
+
+// copyCached writes the blocks cached of the version attr to w, which
+// must all be there.
+func (o *offlineFS) copyCached(w io.Writer, attr *fuse.Attr) error {
+	dc := o.fs.diskCache
+	if dc == nil {
+		return fmt.Errorf("no cache")
+	}
+	key, bs := cacheKey(attr), uint64(dc.cache.BlockSize())
+	for index := uint64(0); index*bs < key.Size; index++ {
+		block, ok := dc.cache.Get(key, index)
+		if !ok {
+			return fmt.Errorf("block %d of %d bytes not cached", index, bs)
+		}
+		if _, err := w.Write(block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openFile opens a handle of node on data; a writer holds the mount
+// offline until released.
+func (o *offlineFS) openFile(data string, node uint64, write bool) (uint64, fuse.Status) {
+	flag := os.O_RDONLY
+	if write {
+		flag = os.O_RDWR
+	}
+	f, err := os.OpenFile(o.journal.DataPath(data), flag, 0)
+	if err != nil {
+		log.Warnf("Opening node %d offline: %v", node, err)
+		return 0, fuse.EIO
+	}
+	fh := o.handle()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.files[fh] = &localFile{f: f, data: data, node: node, write: write}
+	if write {
+		o.writers++
+	}
+	return fh, fuse.OK
+}
+
+func (o *offlineFS) file(fh uint64) *localFile {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.files[fh]
+}
+
+// releaseFile closes fh, if it is a handle on data in the journal.
+func (o *offlineFS) releaseFile(fh uint64) bool {
+	o.mu.Lock()
+	lf := o.files[fh]
+	delete(o.files, fh)
+	if lf != nil && lf.write {
+		o.writers--
+	}
+	o.mu.Unlock()
+	if lf == nil {
+		return false
+	}
+	if err := lf.f.Close(); err != nil {
+		log.Warnf("Closing node %d: %v", lf.node, err)
+	}
+	return true
+}
+
+func (o *offlineFS) openWrite(in *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
+	p, ok := o.nodePath(in.NodeId)
+	if !ok {
+		return fuse.EIO
+	}
+	data, st := o.dirty(p, in.Flags&syscall.O_TRUNC != 0, in.Owner)
+	if st != fuse.OK {
+		return st
+	}
+	out.Fh, st = o.openFile(data, in.NodeId, true)
+	return st
+}
+
+func (o *offlineFS) create(in *fuse.CreateIn, name string, out *fuse.CreateOut) fuse.Status {
+	dir, p, st := o.child(in.NodeId, name)
+	if st != fuse.OK {
+		return st
+	}
+	if _, ok := o.exists(p); ok {
+		if in.Flags&syscall.O_EXCL != 0 {
+			return fuse.Status(syscall.EEXIST)
+		}
+		if st := o.lookup(in.NodeId, name, &out.EntryOut); st != fuse.OK {
+			return st
+		}
+		header := in.InHeader
+		header.NodeId = out.NodeId
+		return o.openWrite(&fuse.OpenIn{InHeader: header, Flags: in.Flags}, &out.OpenOut)
+	}
+
+	data, f, err := o.journal.NewData()
+	if err == nil {
+		err = f.Close()
+	}
+	if err != nil {
+		log.Warnf("Creating %s offline: %v", p, err)
+		return fuse.EIO
+	}
+	mode := in.Mode & 07777
+	if st := o.logOp(journal.Op{Kind: journal.Put, Path: p, Data: data, Mode: mode, Uid: in.Uid, Gid: in.Gid}); st != fuse.OK {
+		os.Remove(f.Name())
+		return st
+	}
+	r := metaRecord{Path: p, Attr: o.newAttr(syscall.S_IFREG|mode, in.Owner), Changed: true, Data: data}
+	if st := o.made(in.NodeId, dir, name, r, &out.EntryOut); st != fuse.OK {
+		return st
+	}
+	out.Fh, st = o.openFile(data, out.NodeId, true)
+	return st
+}
+
+func (o *offlineFS) write(in *fuse.WriteIn, data []byte) (uint32, fuse.Status) {
+	lf := o.file(in.Fh)
+	if lf == nil || !lf.write {
+		return 0, fuse.EBADF
+	}
+	n, err := lf.f.WriteAt(data, int64(in.Offset))
+	if err != nil {
+		return uint32(n), fuse.ToStatus(err)
+	}
+	end := in.Offset + uint64(n)
+	if p, ok := o.nodePath(lf.node); ok {
+		o.meta.update(p, func(r *metaRecord) {
+			if r.Data != lf.data {
+				return
+			}
+			if end > r.Attr.Size {
+				r.Attr.Size, r.Attr.Blocks = end, (end+511)/512
+			}
+			touched(&r.Attr)
+		})
+	}
+	return uint32(n), fuse.OK
+}
+
+func (o *offlineFS) setAttrLocal(in *fuse.SetAttrIn, out *fuse.AttrOut) fuse.Status {
+	p, ok := o.nodePath(in.NodeId)
+	if !ok {
+		return fuse.EIO
+	}
+	r, ok := o.exists(p)
+	if !ok {
+		return fuse.EIO
+	}
+	// Only chown to the owner it has, as cp -p does.
+	if in.Valid&fuse.FATTR_UID != 0 && in.Owner.Uid != r.Attr.Uid ||
+		in.Valid&fuse.FATTR_GID != 0 && in.Owner.Gid != r.Attr.Gid {
+		return fuse.EROFS
+	}
+	if in.Valid&fuse.FATTR_SIZE != 0 {
+		data, st := o.dirty(p, in.Size == 0, in.InHeader.Owner)
+		if st != fuse.OK {
+			return st
+		}
+		if err := os.Truncate(o.journal.DataPath(data), int64(in.Size)); err != nil {
+			log.Warnf("Truncating %s offline: %v", p, err)
+			return fuse.EIO
+		}
+		o.meta.update(p, func(r *metaRecord) {
+			r.Attr.Size, r.Attr.Blocks = in.Size, (in.Size+511)/512
+			touched(&r.Attr)
+		})
+		r, _ = o.exists(p)
+	}
+
+	op := journal.Op{Kind: journal.SetAttr, Path: p, Uid: in.InHeader.Uid, Gid: in.InHeader.Gid, Base: base(&r)}
+	now := time.Now().UnixNano()
+	if in.Valid&fuse.FATTR_MODE != 0 {
+		op.Valid |= fuse.FATTR_MODE
+		op.Mode = in.Mode & 07777
+	}
+	if in.Valid&fuse.FATTR_ATIME_NOW != 0 {
+		op.Valid |= fuse.FATTR_ATIME
+		op.Atime = now
+	} else if in.Valid&fuse.FATTR_ATIME != 0 {
+		op.Valid |= fuse.FATTR_ATIME
+		op.Atime = int64(in.Atime)*1e9 + int64(in.Atimensec)
+	}
+	if in.Valid&fuse.FATTR_MTIME_NOW != 0 {
+		op.Valid |= fuse.FATTR_MTIME
+		op.Mtime = now
+	} else if in.Valid&fuse.FATTR_MTIME != 0 {
+		op.Valid |= fuse.FATTR_MTIME
+		op.Mtime = int64(in.Mtime)*1e9 + int64(in.Mtimensec)
+	}
+	if op.Valid != 0 {
+		if st := o.logOp(op); st != fuse.OK {
+			return st
+		}
+		o.meta.update(p, func(r *metaRecord) {
+			changing(r)
+			ctime := time.Now()
+			r.Attr.SetTimes(nil, nil, &ctime)
+			if op.Valid&fuse.FATTR_MODE != 0 {
+				r.Attr.Mode = r.Attr.Mode&^07777 | op.Mode
+			}
+			if op.Valid&fuse.FATTR_ATIME != 0 {
+				atime := time.Unix(0, op.Atime)
+				r.Attr.SetTimes(&atime, nil, nil)
+			}
+			if op.Valid&fuse.FATTR_MTIME != 0 {
+				mtime := time.Unix(0, op.Mtime)
+				r.Attr.SetTimes(nil, &mtime, nil)
+			}
+		})
+	}
+	return o.getAttr(in.NodeId, out)
+}
+
+func (o *offlineFS) mkdir(in *fuse.MkdirIn, name string, out *fuse.EntryOut) fuse.Status {
+	dir, p, st := o.child(in.NodeId, name)
+	if st != fuse.OK {
+		return st
+	}
+	if _, ok := o.exists(p); ok {
+		return fuse.Status(syscall.EEXIST)
+	}
+	mode := in.Mode & 07777
+	if st := o.logOp(journal.Op{Kind: journal.Mkdir, Path: p, Mode: mode, Uid: in.Uid, Gid: in.Gid}); st != fuse.OK {
+		return st
+	}
+	parent, _ := o.exists(dir)
+	attr := o.newAttr(syscall.S_IFDIR|mode, in.Owner)
+	r := metaRecord{Path: p, Attr: attr, Listed: true, Changed: true, Entries: []metaEntry{
+		{Name: ".", Ino: attr.Ino, Mode: attr.Mode},
+		{Name: "..", Ino: parent.Attr.Ino, Mode: parent.Attr.Mode},
+	}}
+	return o.made(in.NodeId, dir, name, r, out)
+}
+
+func (o *offlineFS) symlink(header *fuse.InHeader, target, name string, out *fuse.EntryOut) fuse.Status {
+	dir, p, st := o.child(header.NodeId, name)
+	if st != fuse.OK {
+		return st
+	}
+	if _, ok := o.exists(p); ok {
+		return fuse.Status(syscall.EEXIST)
+	}
+	if st := o.logOp(journal.Op{Kind: journal.Symlink, Path: p, Target: target, Uid: header.Uid, Gid: header.Gid}); st != fuse.OK {
+		return st
+	}
+	attr := o.newAttr(syscall.S_IFLNK|0777, header.Owner)
+	attr.Size = uint64(len(target))
+	return o.made(header.NodeId, dir, name, metaRecord{Path: p, Attr: attr, Target: target, Changed: true}, out)
+}
+
+// remove removes name from the directory parent, an empty directory if
+// dir is set and a file otherwise.
+func (o *offlineFS) remove(header *fuse.InHeader, name string, dir bool) fuse.Status {
+	parent, p, st := o.child(header.NodeId, name)
+	if st != fuse.OK {
+		return st
+	}
+	r, ok := o.exists(p)
+	if !ok {
+		return fuse.ENOENT
+	}
+	kind := journal.Unlink
+	if dir {
+		kind = journal.Rmdir
+		if !r.Attr.IsDir() {
+			return fuse.ENOTDIR
+		}
+		if !r.Listed {
+			// Not known to be empty.
+			return fuse.EIO
+		}
+		if !empty(&r) {
+			return fuse.Status(syscall.ENOTEMPTY)
+		}
+	} else if r.Attr.IsDir() {
+		return fuse.EISDIR
+	}
+	if st := o.logOp(journal.Op{Kind: kind, Path: p, Uid: header.Uid, Gid: header.Gid, Base: base(&r)}); st != fuse.OK {
+		return st
+	}
+	o.meta.remove(p)
+	o.leave(parent, name)
+	o.gone(p)
+	return fuse.OK
+}
+
+func (o *offlineFS) rename(in *fuse.RenameIn, oldName, newName string) fuse.Status {
+	if in.Flags&renameExchange != 0 {
+		return fuse.EINVAL
+	}
+	oldDir, oldPath, st := o.child(in.NodeId, oldName)
+	if st != fuse.OK {
+		return st
+	}
+	newDir, newPath, st := o.child(in.Newdir, newName)
+	if st != fuse.OK {
+		return st
+	}
+	r, ok := o.exists(oldPath)
+	if !ok {
+		return fuse.ENOENT
+	}
+	if oldPath == newPath {
+		return fuse.OK
+	}
+	if strings.HasPrefix(newPath, oldPath+"/") {
+		return fuse.EINVAL
+	}
+	op := journal.Op{Kind: journal.Rename, Path: oldPath, To: newPath, Uid: in.Uid, Gid: in.Gid, Base: base(&r)}
+	if to, ok := o.exists(newPath); ok {
+		switch {
+		case in.Flags&renameNoReplace != 0:
+			return fuse.Status(syscall.EEXIST)
+		case to.Attr.IsDir() && !r.Attr.IsDir():
+			return fuse.EISDIR
+		case !to.Attr.IsDir() && r.Attr.IsDir():
+			return fuse.ENOTDIR
+		case to.Attr.IsDir() && !to.Listed:
+			return fuse.EIO
+		case to.Attr.IsDir() && !empty(&to):
+			return fuse.Status(syscall.ENOTEMPTY)
+		}
+		op.ToBase = base(&to)
+	}
+	if st := o.logOp(op); st != fuse.OK {
+		return st
+	}
+	o.gone(newPath)
+	o.meta.rename(oldPath, newPath)
+	o.meta.update(newPath, func(r *metaRecord) {
+		changing(r)
+		now := time.Now()
+		r.Attr.SetTimes(nil, nil, &now)
+	})
+	o.leave(oldDir, oldName)
+	o.enter(newDir, newName, &r.Attr)
+	o.moved(oldPath, newPath)
+	return fuse.OK
+}
@@ -0,0 +1,214 @@
+package grpc2fuse
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/chiyutianyi/grpcfuse/blockcache"
+	"github.com/chiyutianyi/grpcfuse/exports"
+	"github.com/chiyutianyi/grpcfuse/fsclient"
+	"github.com/chiyutianyi/grpcfuse/journal"
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+// journaled returns a file system journaling its changes while server is
+// down, and a client going to the server directly.
+func journaled(t *testing.T, policy ConflictPolicy, server *outage) (*offlineFS, *fsclient.Client) {
+	dialOpts := append(serve(t), grpc.WithInsecure())
+	dialOpts = append(dialOpts, exports.DialOptions("a")...)
+	dir := t.TempDir()
+	meta, err := openMetaCache(filepath.Join(dir, "meta"), 0)
+	require.NoError(t, err)
+	cache, err := blockcache.Open(dir, &blockcache.Options{BlockSize: 4})
+	require.NoError(t, err)
+	o := newOfflineFS(meta)
+	o.interval = 10 * time.Millisecond
+	o.journal, err = journal.Open(filepath.Join(dir, "journal"))
+	require.NoError(t, err)
+	o.conflicts = policy
+
+	opts := append(dialOpts, grpc.WithChainUnaryInterceptor(o.unaryInterceptor), grpc.WithChainStreamInterceptor(o.streamInterceptor))
+	conn, err := grpc.Dial("bufconn", append(opts, server.dialOptions()...)...)
+	require.NoError(t, err)
+	direct, err := grpc.Dial("bufconn", dialOpts...)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		o.close()
+		conn.Close()
+		direct.Close()
+	})
+	fs := NewFileSystem(pb.NewRawFileSystemClient(conn))
+	fs.SetCache(cache)
+	fs.listed = o.listed
+	o.RawFileSystem, o.fs = fs, fs
+	o.probe = func(ctx context.Context) error {
+		_, err := fs.client.String(ctx, &pb.StringRequest{})
+		return err
+	}
+	return o, fsclient.New(pb.NewRawFileSystemClient(direct), nil)
+}
+
+func lookupPath(t *testing.T, o *offlineFS, names ...string) uint64 {
+	node := uint64(fuse.FUSE_ROOT_ID)
+	for _, name := range names {
+		var out fuse.EntryOut
+		require.Equal(t, fuse.OK, o.Lookup(nil, &fuse.InHeader{NodeId: node}, name, &out), name)
+		node = out.NodeId
+	}
+	return node
+}
+
+func readNode(t *testing.T, o *offlineFS, node uint64) string {
+	header := fuse.InHeader{NodeId: node}
+	var out fuse.OpenOut
+	require.Equal(t, fuse.OK, o.Open(nil, &fuse.OpenIn{InHeader: header}, &out))
+	defer o.Release(nil, &fuse.ReleaseIn{InHeader: header, Fh: out.Fh})
+	res, st := o.Read(nil, &fuse.ReadIn{InHeader: header, Fh: out.Fh, Size: 100}, make([]byte, 100))
+	require.Equal(t, fuse.OK, st)
+	data, _ := res.Bytes(make([]byte, 100))
+	return string(data)
+}
+
+func writeNode(t *testing.T, o *offlineFS, node uint64, flags uint32, off uint64, data string) {
+	header := fuse.InHeader{NodeId: node}
+	var out fuse.OpenOut
+	require.Equal(t, fuse.OK, o.Open(nil, &fuse.OpenIn{InHeader: header, Flags: flags}, &out))
+	n, st := o.Write(nil, &fuse.WriteIn{InHeader: header, Fh: out.Fh, Offset: off, Size: uint32(len(data))}, []byte(data))
+	require.Equal(t, fuse.OK, st)
+	assert.Equal(t, uint32(len(data)), n)
+	o.Release(nil, &fuse.ReleaseIn{InHeader: header, Fh: out.Fh})
+}
+
+func writeRemote(t *testing.T, c *fsclient.Client, name, data string) {
+	f, err := c.Create(name)
+	require.NoError(t, err)
+	_, err = f.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func readRemote(t *testing.T, c *fsclient.Client, name string) string {
+	f, err := c.Open(name)
+	require.NoError(t, err)
+	defer f.Close()
+	data, err := ioutil.ReadAll(f)
+	require.NoError(t, err)
+	return string(data)
+}
+
+// goOffline takes o offline with the server.
+func goOffline(t *testing.T, o *offlineFS, server *outage) {
+	server.set(true)
+	var attr fuse.AttrOut
+	require.Equal(t, fuse.OK, o.GetAttr(nil, &fuse.GetAttrIn{InHeader: fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}}, &attr))
+	require.True(t, o.isOffline())
+}
+
+func TestJournal(t *testing.T) {
+	var server outage
+	o, c := journaled(t, KeepBoth, &server)
+	require.NoError(t, c.Mkdir("d", 0755))
+	writeRemote(t, c, "d/f", "hello")
+	writeRemote(t, c, "d/g", "gone")
+	writeRemote(t, c, "x", "x")
+
+	root := fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}
+	var attr fuse.AttrOut
+	require.Equal(t, fuse.OK, o.GetAttr(nil, &fuse.GetAttrIn{InHeader: root}, &attr))
+	d, f := lookupPath(t, o, "d"), lookupPath(t, o, "d", "f")
+	lookupPath(t, o, "x")
+	assert.Equal(t, "hello", readNode(t, o, f))
+	listNames(t, o, fuse.FUSE_ROOT_ID, true)
+	listNames(t, o, d, true)
+
+	goOffline(t, o, &server)
+	dh := fuse.InHeader{NodeId: d}
+	writeNode(t, o, f, syscall.O_RDWR, 5, " world")
+	assert.Equal(t, "hello world", readNode(t, o, f))
+	var created fuse.CreateOut
+	require.Equal(t, fuse.OK, o.Create(nil, &fuse.CreateIn{InHeader: dh, Flags: syscall.O_RDWR, Mode: 0640}, "new", &created))
+	_, st := o.Write(nil, &fuse.WriteIn{InHeader: fuse.InHeader{NodeId: created.NodeId}, Fh: created.Fh, Size: 3}, []byte("new"))
+	require.Equal(t, fuse.OK, st)
+	assert.Equal(t, fuse.Status(syscall.EEXIST), o.Create(nil, &fuse.CreateIn{InHeader: dh, Flags: syscall.O_RDWR | syscall.O_EXCL}, "new", &fuse.CreateOut{}))
+	var e, l fuse.EntryOut
+	require.Equal(t, fuse.OK, o.Mkdir(nil, &fuse.MkdirIn{InHeader: root, Mode: 0755}, "e", &e))
+	assert.NotZero(t, e.NodeId&localID)
+	require.Equal(t, fuse.OK, o.Symlink(nil, &root, "d/f", "l", &l))
+	require.Equal(t, fuse.OK, o.Unlink(nil, &dh, "g"))
+	assert.Equal(t, fuse.Status(syscall.ENOTEMPTY), o.Rmdir(nil, &root, "d"))
+	require.Equal(t, fuse.OK, o.Rename(nil, &fuse.RenameIn{InHeader: root, Newdir: e.NodeId}, "x", "x"))
+	require.Equal(t, fuse.OK, o.SetAttr(nil, &fuse.SetAttrIn{SetAttrInCommon: fuse.SetAttrInCommon{InHeader: fuse.InHeader{NodeId: f}, Valid: fuse.FATTR_MODE, Mode: 0600}}, &attr))
+	assert.Equal(t, uint32(syscall.S_IFREG|0600), attr.Mode)
+	assert.Equal(t, uint64(11), attr.Size)
+	assert.Equal(t, fuse.EROFS, o.Link(nil, &fuse.LinkIn{InHeader: root, Oldnodeid: f}, "hard", &fuse.EntryOut{}))
+
+	// Seen as changed while offline, but not on the server.
+	assert.Equal(t, map[string]uint64{".": 0, "..": 0, "f": 0, "new": 0}, zeroIDs(listNames(t, o, d, false)))
+	assert.Equal(t, map[string]uint64{".": 0, "..": 0, "d": 0, "e": 0, "l": 0}, zeroIDs(listNames(t, o, fuse.FUSE_ROOT_ID, false)))
+	assert.Equal(t, "new", readNode(t, o, created.NodeId))
+	target, st := o.Readlink(nil, &fuse.InHeader{NodeId: l.NodeId})
+	require.Equal(t, fuse.OK, st)
+	assert.Equal(t, "d/f", string(target))
+	assert.Equal(t, "hello", readRemote(t, c, "d/f"))
+	var kinds []journal.Kind
+	for _, op := range o.journal.Pending() {
+		kinds = append(kinds, op.Kind)
+	}
+	assert.Equal(t, []journal.Kind{journal.Put, journal.Put, journal.Mkdir, journal.Symlink, journal.Unlink, journal.Rename, journal.SetAttr}, kinds)
+
+	// Not back online while a file written is open.
+	server.set(false)
+	time.Sleep(50 * time.Millisecond)
+	assert.True(t, o.isOffline())
+	o.Release(nil, &fuse.ReleaseIn{InHeader: fuse.InHeader{NodeId: created.NodeId}, Fh: created.Fh})
+	require.Eventually(t, func() bool { return !o.isOffline() }, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, "hello world", readRemote(t, c, "d/f"))
+	fi, err := c.Stat("d/f")
+	require.NoError(t, err)
+	assert.Equal(t, 0600, int(fi.Mode().Perm()))
+	assert.Equal(t, "new", readRemote(t, c, "d/new"))
+	fi, err = c.Stat("d/new")
+	require.NoError(t, err)
+	assert.Equal(t, 0640, int(fi.Mode().Perm()))
+	assert.Equal(t, "x", readRemote(t, c, "e/x"))
+	_, err = c.Stat("x")
+	assert.Error(t, err)
+	_, err = c.Stat("d/g")
+	assert.Error(t, err)
+	link, err := c.Readlink("l")
+	require.NoError(t, err)
+	assert.Equal(t, "d/f", link)
+	assert.Empty(t, o.journal.Pending())
+	conflicts, err := o.journal.Conflicts()
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+	data, err := ioutil.ReadDir(filepath.Join(o.journal.Dir(), "data"))
+	require.NoError(t, err)
+	assert.Empty(t, data, "pruned")
+
+	// What was put reads offline again as the version on the server.
+	r, ok := o.meta.get("/d/f")
+	require.True(t, ok)
+	assert.False(t, r.Changed)
+	goOffline(t, o, &server)
+	assert.Equal(t, "hello world", readNode(t, o, f))
+	d2 := lookupPath(t, o, "e")
+	assert.Contains(t, listNames(t, o, d2, false), "x")
+}
+
+func zeroIDs(names map[string]uint64) map[string]uint64 {
+	for name := range names {
+		names[name] = 0
+	}
+	return names
+}
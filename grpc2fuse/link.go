@@ -24,6 +24,8 @@ import (
 
 func (fs *fileSystem) Link(cancel <-chan struct{}, input *fuse.LinkIn, filename string, out *fuse.EntryOut) (code fuse.Status) {
 	ctx := newContext(cancel)
+	fs.attrCache.invalidateEntry(input.NodeId, filename)
+	gen := fs.attrCache.generation()
 
 	res, err := fs.client.Link(ctx, &pb.LinkRequest{
 		Header:    toPbHeader(&input.InHeader),
@@ -39,11 +41,14 @@ func (fs *fileSystem) Link(cancel <-chan struct{}, input *fuse.LinkIn, filename
 	}
 
 	toFuseEntryOut(out, res.EntryOut)
+	fs.attrCache.made(gen, input.NodeId, filename, out)
 	return fuse.OK
 }
 
 func (fs *fileSystem) Symlink(cancel <-chan struct{}, header *fuse.InHeader, pointedTo string, linkName string, out *fuse.EntryOut) (code fuse.Status) {
 	ctx := newContext(cancel)
+	fs.attrCache.invalidateEntry(header.NodeId, linkName)
+	gen := fs.attrCache.generation()
 
 	res, err := fs.client.Symlink(ctx, &pb.SymlinkRequest{
 		Header:    toPbHeader(header),
@@ -59,11 +64,16 @@ func (fs *fileSystem) Symlink(cancel <-chan struct{}, header *fuse.InHeader, poi
 	}
 
 	toFuseEntryOut(out, res.EntryOut)
+	fs.attrCache.made(gen, header.NodeId, linkName, out)
 	return fuse.OK
 }
 
 func (fs *fileSystem) Readlink(cancel <-chan struct{}, header *fuse.InHeader) (out []byte, code fuse.Status) {
 	ctx := newContext(cancel)
+	if target, ok := fs.attrCache.readlink(header.NodeId); ok {
+		return target, fuse.OK
+	}
+	gen := fs.attrCache.generation()
 
 	res, err := fs.client.Readlink(ctx, &pb.ReadlinkRequest{
 		Header: toPbHeader(header),
@@ -72,6 +82,9 @@ func (fs *fileSystem) Readlink(cancel <-chan struct{}, header *fuse.InHeader) (o
 	if st := dealGrpcError("Readlink", err); st != fuse.OK {
 		return nil, st
 	}
+	if res.Status.GetCode() == 0 {
+		fs.attrCache.putLink(gen, header.NodeId, res.GetOut())
+	}
 
 	return res.GetOut(), fuse.Status(res.Status.GetCode())
 }
@@ -24,10 +24,14 @@ import (
 
 func (fs *fileSystem) GetLk(cancel <-chan struct{}, input *fuse.LkIn, out *fuse.LkOut) (code fuse.Status) {
 	ctx := newContext(cancel)
+	fh, st := fs.handle(ctx, input.Fh)
+	if st != fuse.OK {
+		return st
+	}
 
 	res, err := fs.client.GetLk(ctx, &pb.LkRequest{
 		Header: toPbHeader(&input.InHeader),
-		Fh:     input.Fh,
+		Fh:     fh,
 		Owner:  input.Owner,
 		Lk: &pb.FileLock{
 			Start: input.Lk.Start,
@@ -54,10 +58,15 @@ func (fs *fileSystem) GetLk(cancel <-chan struct{}, input *fuse.LkIn, out *fuse.
 
 func (fs *fileSystem) SetLk(cancel <-chan struct{}, input *fuse.LkIn) (code fuse.Status) {
 	ctx := newContext(cancel)
+	fs.smallFiles.lock(input.Fh)
+	fh, st := fs.handle(ctx, input.Fh)
+	if st != fuse.OK {
+		return st
+	}
 
 	res, err := fs.client.SetLk(ctx, &pb.LkRequest{
 		Header: toPbHeader(&input.InHeader),
-		Fh:     input.Fh,
+		Fh:     fh,
 		Owner:  input.Owner,
 		Lk: &pb.FileLock{
 			Start: input.Lk.Start,
@@ -78,10 +87,15 @@ func (fs *fileSystem) SetLk(cancel <-chan struct{}, input *fuse.LkIn) (code fuse
 
 func (fs *fileSystem) SetLkw(cancel <-chan struct{}, input *fuse.LkIn) (code fuse.Status) {
 	ctx := newContext(cancel)
+	fs.smallFiles.lock(input.Fh)
+	fh, st := fs.handle(ctx, input.Fh)
+	if st != fuse.OK {
+		return st
+	}
 
 	res, err := fs.client.SetLkw(ctx, &pb.LkRequest{
 		Header: toPbHeader(&input.InHeader),
-		Fh:     input.Fh,
+		Fh:     fh,
 		Owner:  input.Owner,
 		Lk: &pb.FileLock{
 			Start: input.Lk.Start,
@@ -11,12 +11,12 @@ import (
 	"google.golang.org/grpc"
 )
 
-type MockRawFileSystemClient struct {
+type lockClient struct {
 	mock.Mock
 	pb.RawFileSystemClient
 }
 
-func (m *MockRawFileSystemClient) GetLk(ctx context.Context, in *pb.LkRequest, opts ...grpc.CallOption) (*pb.GetLkResponse, error) {
+func (m *lockClient) GetLk(ctx context.Context, in *pb.LkRequest, opts ...grpc.CallOption) (*pb.GetLkResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -24,7 +24,7 @@ func (m *MockRawFileSystemClient) GetLk(ctx context.Context, in *pb.LkRequest, o
 	return args.Get(0).(*pb.GetLkResponse), args.Error(1)
 }
 
-func (m *MockRawFileSystemClient) SetLk(ctx context.Context, in *pb.LkRequest, opts ...grpc.CallOption) (*pb.SetLkResponse, error) {
+func (m *lockClient) SetLk(ctx context.Context, in *pb.LkRequest, opts ...grpc.CallOption) (*pb.SetLkResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -32,7 +32,7 @@ func (m *MockRawFileSystemClient) SetLk(ctx context.Context, in *pb.LkRequest, o
 	return args.Get(0).(*pb.SetLkResponse), args.Error(1)
 }
 
-func (m *MockRawFileSystemClient) SetLkw(ctx context.Context, in *pb.LkRequest, opts ...grpc.CallOption) (*pb.SetLkResponse, error) {
+func (m *lockClient) SetLkw(ctx context.Context, in *pb.LkRequest, opts ...grpc.CallOption) (*pb.SetLkResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -41,7 +41,7 @@ func (m *MockRawFileSystemClient) SetLkw(ctx context.Context, in *pb.LkRequest,
 }
 
 func TestFileSystem_GetLk(t *testing.T) {
-	mockClient := new(MockRawFileSystemClient)
+	mockClient := new(lockClient)
 	fs := &fileSystem{
 		client: mockClient,
 	}
@@ -111,7 +111,7 @@ func TestFileSystem_GetLk(t *testing.T) {
 }
 
 func TestFileSystem_SetLk(t *testing.T) {
-	mockClient := new(MockRawFileSystemClient)
+	mockClient := new(lockClient)
 	fs := &fileSystem{
 		client: mockClient,
 	}
@@ -167,7 +167,7 @@ func TestFileSystem_SetLk(t *testing.T) {
 }
 
 func TestFileSystem_SetLkw(t *testing.T) {
-	mockClient := new(MockRawFileSystemClient)
+	mockClient := new(lockClient)
 	fs := &fileSystem{
 		client: mockClient,
 	}
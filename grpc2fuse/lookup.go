@@ -24,6 +24,10 @@ import (
 
 func (fs *fileSystem) Lookup(cancel <-chan struct{}, header *fuse.InHeader, name string, out *fuse.EntryOut) (status fuse.Status) {
 	ctx := newContext(cancel)
+	if st, ok := fs.attrCache.lookup(header.NodeId, name, out); ok {
+		return st
+	}
+	gen := fs.attrCache.generation()
 
 	res, err := fs.client.Lookup(ctx, &pb.LookupRequest{
 		Header: toPbHeader(header),
@@ -35,8 +39,14 @@ func (fs *fileSystem) Lookup(cancel <-chan struct{}, header *fuse.InHeader, name
 	}
 
 	if res.Status.GetCode() != 0 {
-		return fuse.Status(res.Status.GetCode())
+		st := fuse.Status(res.Status.GetCode())
+		if st == fuse.ENOENT {
+			fs.attrCache.putEntry(gen, header.NodeId, name, nil)
+		}
+		return st
 	}
 	toFuseEntryOut(out, res.EntryOut)
+	fs.attrCache.putEntry(gen, header.NodeId, name, out)
+	fs.smallFiles.saw(out.NodeId, &out.Attr)
 	return fuse.OK
 }
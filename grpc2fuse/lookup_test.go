@@ -11,12 +11,12 @@ import (
 	"google.golang.org/grpc"
 )
 
-type MockRawFileSystemClient struct {
+type lookupClient struct {
 	mock.Mock
 	pb.RawFileSystemClient
 }
 
-func (m *MockRawFileSystemClient) Lookup(ctx context.Context, in *pb.LookupRequest, opts ...grpc.CallOption) (*pb.LookupResponse, error) {
+func (m *lookupClient) Lookup(ctx context.Context, in *pb.LookupRequest, opts ...grpc.CallOption) (*pb.LookupResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -83,7 +83,7 @@ func TestLookup(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockClient := new(MockRawFileSystemClient)
+			mockClient := new(lookupClient)
 			fs := &fileSystem{
 				client: mockClient,
 			}
@@ -0,0 +1,334 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc2fuse
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/chiyutianyi/grpcfuse/journal"
+)
+
+// metaCache keeps on disk what a mount learnt of the files of the server,
+// by path, so that it can answer while the server is unreachable, also
+// after a remount. Node IDs are not kept, as they only mean something to
+// the server process that handed them out.
+//
+// It holds at most size records; those least recently used go first, but
+// for the ones changed offline, which stay until replayed.
+type metaCache struct {
+	dir  string
+	size int
+
+	mu      sync.Mutex
+	records map[string]*metaRecord
+	// lru holds the paths of the records not changed offline, the most
+	// recently used first.
+	lru   *list.List
+	elems map[string]*list.Element
+	// dirty holds the paths whose records are to be written out, or
+	// removed if gone.
+	dirty map[string]bool
+
+	// wmu orders the writes to disk, made without mu.
+	wmu sync.Mutex
+}
+
+// metaRecord is what is known of a path.
+type metaRecord struct {
+	Path   string
+	Attr   fuse.Attr
+	Target string `json:",omitempty"`
+	// Listed is set when Entries is the listing of the directory, read to
+	// the end since the directory last changed through this mount.
+	Listed  bool        `json:",omitempty"`
+	Entries []metaEntry `json:",omitempty"`
+	// Changed is set once the path is changed offline, with the journal;
+	// Base is then the version it had on the server, nil if it had
+	// none, and Data names the data in the journal of a file written.
+	Changed bool             `json:",omitempty"`
+	Base    *journal.Version `json:",omitempty"`
+	Data    string           `json:",omitempty"`
+}
+
+type metaEntry struct {
+	Name string
+	Ino  uint64
+	Mode uint32
+}
+
+// defaultMetaRecords is how many records a metaCache holds if not told.
+const defaultMetaRecords = 1 << 16
+
+// openMetaCache loads the records kept in dir, creating it if needed. It
+// holds at most size records, defaultMetaRecords if zero.
+func openMetaCache(dir string, size int) (*metaCache, error) {
+	if size <= 0 {
+		size = defaultMetaRecords
+	}
+	c := &metaCache{
+		dir:     dir,
+		size:    size,
+		records: map[string]*metaRecord{},
+		lru:     list.New(),
+		elems:   map[string]*list.Element{},
+		dirty:   map[string]bool{},
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("metadata cache: %v", err)
+	}
+	type found struct {
+		r       *metaRecord
+		written time.Time
+	}
+	var records []found
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		var r metaRecord
+		data, err := ioutil.ReadFile(path)
+		if err == nil {
+			err = json.Unmarshal(data, &r)
+		}
+		if err != nil || c.file(r.Path) != path {
+			// Torn by a crash while written, or not ours.
+			log.Debugf("Removing %s from the metadata cache: %v", path, err)
+			return os.Remove(path)
+		}
+		records = append(records, found{&r, info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("metadata cache: %v", err)
+	}
+	// Last written is the nearest to last used there is.
+	sort.Slice(records, func(i, j int) bool { return records[i].written.Before(records[j].written) })
+	c.mu.Lock()
+	for _, f := range records {
+		c.set(f.r, false)
+	}
+	c.evict()
+	c.mu.Unlock()
+	c.flush()
+	return c, nil
+}
+
+// file is where the record of path is kept.
+func (c *metaCache) file(path string) string {
+	sum := sha256.Sum256([]byte(path))
+	name := hex.EncodeToString(sum[:16])
+	return filepath.Join(c.dir, name[:2], name)
+}
+
+// get returns the record of path.
+func (c *metaCache) get(path string) (metaRecord, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r, ok := c.records[path]
+	if !ok {
+		return metaRecord{}, false
+	}
+	if elem, ok := c.elems[path]; ok {
+		c.lru.MoveToFront(elem)
+	}
+	return *r, true
+}
+
+// update changes the record of path with f, making it if needed, and
+// writes it out unless only its access time changed.
+func (c *metaCache) update(path string, f func(r *metaRecord)) {
+	c.mu.Lock()
+	old, ok := c.records[path]
+	r := &metaRecord{Path: path}
+	if ok {
+		*r = *old
+	}
+	f(r)
+	c.set(r, !ok || !sameRecord(*old, *r))
+	c.evict()
+	c.mu.Unlock()
+	c.flush()
+}
+
+// set makes r the record of its path, the most recently used, to be
+// written out if write is set; c.mu is held. r is not changed after.
+func (c *metaCache) set(r *metaRecord, write bool) {
+	c.records[r.Path] = r
+	elem, ok := c.elems[r.Path]
+	switch {
+	case r.Changed && ok:
+		c.lru.Remove(elem)
+		delete(c.elems, r.Path)
+	case r.Changed:
+	case ok:
+		c.lru.MoveToFront(elem)
+	default:
+		c.elems[r.Path] = c.lru.PushFront(r.Path)
+	}
+	if write {
+		c.dirty[r.Path] = true
+	}
+}
+
+// del drops the record of path; c.mu is held.
+func (c *metaCache) del(path string) {
+	delete(c.records, path)
+	if elem, ok := c.elems[path]; ok {
+		c.lru.Remove(elem)
+		delete(c.elems, path)
+	}
+	c.dirty[path] = true
+}
+
+// evict drops the records least recently used until they fit; c.mu is
+// held.
+func (c *metaCache) evict() {
+	for c.lru.Len() > c.size {
+		c.del(c.lru.Back().Value.(string))
+	}
+}
+
+// flush writes out the records changed, and removes the files of those
+// gone, as they are when it gets to them.
+func (c *metaCache) flush() {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	c.mu.Lock()
+	dirty := c.dirty
+	c.dirty = map[string]bool{}
+	records := make(map[string]*metaRecord, len(dirty))
+	for p := range dirty {
+		records[p] = c.records[p]
+	}
+	c.mu.Unlock()
+	for p, r := range records {
+		if r == nil {
+			if err := os.Remove(c.file(p)); err != nil && !os.IsNotExist(err) {
+				log.Warnf("Removing metadata of %s: %v", p, err)
+			}
+			continue
+		}
+		if err := c.write(r); err != nil {
+			log.Warnf("Caching metadata of %s: %v", p, err)
+		}
+	}
+}
+
+func sameRecord(a, b metaRecord) bool {
+	a.Attr.Atime, a.Attr.Atimensec = 0, 0
+	b.Attr.Atime, b.Attr.Atimensec = 0, 0
+	return reflect.DeepEqual(a, b)
+}
+
+func (c *metaCache) write(r *metaRecord) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	path := c.file(r.Path)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	// Written aside and renamed, so that a crash leaves no torn record.
+	f, err := ioutil.TempFile(filepath.Dir(path), ".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// unlist forgets the listing of the directory at path.
+func (c *metaCache) unlist(path string) {
+	c.update(path, func(r *metaRecord) {
+		r.Listed, r.Entries = false, nil
+	})
+}
+
+// changed returns the records changed offline.
+func (c *metaCache) changed() []metaRecord {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var records []metaRecord
+	for _, r := range c.records {
+		if r.Changed {
+			records = append(records, *r)
+		}
+	}
+	return records
+}
+
+// rename moves the records of oldPath and of everything below it to
+// newPath, replacing those there.
+func (c *metaCache) rename(oldPath, newPath string) {
+	c.mu.Lock()
+	c.removeLocked(newPath)
+	var moving []*metaRecord
+	for p, r := range c.records {
+		if p == oldPath || strings.HasPrefix(p, oldPath+"/") {
+			moving = append(moving, r)
+		}
+	}
+	for _, r := range moving {
+		moved := *r
+		moved.Path = newPath + strings.TrimPrefix(r.Path, oldPath)
+		c.del(r.Path)
+		c.set(&moved, true)
+	}
+	c.mu.Unlock()
+	c.flush()
+}
+
+// remove drops the records of path and of everything below it.
+func (c *metaCache) remove(path string) {
+	c.mu.Lock()
+	c.removeLocked(path)
+	c.mu.Unlock()
+	c.flush()
+}
+
+func (c *metaCache) removeLocked(path string) {
+	for p := range c.records {
+		if p == path || strings.HasPrefix(p, strings.TrimSuffix(path, "/")+"/") {
+			c.del(p)
+		}
+	}
+}
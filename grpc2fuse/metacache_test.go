@@ -0,0 +1,98 @@
+package grpc2fuse
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetaCache(t *testing.T) {
+	dir := t.TempDir()
+	c, err := openMetaCache(dir, 0)
+	require.NoError(t, err)
+
+	c.update("/d", func(r *metaRecord) {
+		r.Attr = fuse.Attr{Ino: 2, Mode: fuse.S_IFDIR | 0755}
+		r.Listed, r.Entries = true, []metaEntry{{Name: "f", Ino: 3, Mode: fuse.S_IFREG}}
+	})
+	c.update("/d/f", func(r *metaRecord) { r.Attr = fuse.Attr{Ino: 3, Size: 5} })
+	c.update("/l", func(r *metaRecord) { r.Target = "d/f" })
+	r, ok := c.get("/d")
+	require.True(t, ok)
+	assert.Equal(t, []metaEntry{{Name: "f", Ino: 3, Mode: fuse.S_IFREG}}, r.Entries)
+
+	// A change of the access time alone is not written out.
+	file := c.file("/d/f")
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(file, old, old))
+	c.update("/d/f", func(r *metaRecord) { r.Attr.Atime = 100 })
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	assert.True(t, info.ModTime().Equal(old))
+
+	// What is left of a crash is cleaned up.
+	require.NoError(t, ioutil.WriteFile(filepath.Join(filepath.Dir(file), ".tmp123"), []byte("{"), 0600))
+	reopened, err := openMetaCache(dir, 0)
+	require.NoError(t, err)
+	assert.Len(t, reopened.records, 3)
+	assert.Equal(t, c.records["/d"], reopened.records["/d"])
+	_, err = os.Stat(filepath.Join(filepath.Dir(file), ".tmp123"))
+	assert.True(t, os.IsNotExist(err))
+
+	c.unlist("/d")
+	r, _ = c.get("/d")
+	assert.False(t, r.Listed)
+	assert.Empty(t, r.Entries)
+	assert.Equal(t, uint64(2), r.Attr.Ino)
+
+	c.remove("/d")
+	for _, p := range []string{"/d", "/d/f"} {
+		_, ok := c.get(p)
+		assert.False(t, ok, p)
+		_, err := os.Stat(c.file(p))
+		assert.True(t, os.IsNotExist(err), p)
+	}
+	_, ok = c.get("/l")
+	assert.True(t, ok)
+}
+
+func TestMetaCacheEvict(t *testing.T) {
+	dir := t.TempDir()
+	c, err := openMetaCache(dir, 2)
+	require.NoError(t, err)
+	stored := func(p string) bool {
+		_, err := os.Stat(c.file(p))
+		return err == nil
+	}
+
+	c.update("/a", func(r *metaRecord) { r.Attr.Ino = 2 })
+	c.update("/b", func(r *metaRecord) { r.Attr.Ino = 3 })
+	c.update("/c", func(r *metaRecord) { r.Attr.Ino = 4; r.Changed = true })
+	_, ok := c.get("/a")
+	require.True(t, ok)
+	c.update("/d", func(r *metaRecord) { r.Attr.Ino = 5 })
+
+	// The least recently used goes, but for what was changed offline.
+	_, ok = c.get("/b")
+	assert.False(t, ok)
+	assert.False(t, stored("/b"))
+	for _, p := range []string{"/a", "/c", "/d"} {
+		_, ok := c.get(p)
+		assert.True(t, ok, p)
+		assert.True(t, stored(p), p)
+	}
+
+	c.update("/c", func(r *metaRecord) { r.Changed = false })
+	_, ok = c.get("/a")
+	assert.False(t, ok, "once replayed, it counts again")
+
+	reopened, err := openMetaCache(dir, 1)
+	require.NoError(t, err)
+	assert.Len(t, reopened.records, 1)
+}
@@ -24,6 +24,8 @@ import (
 
 func (fs *fileSystem) Mkdir(cancel <-chan struct{}, input *fuse.MkdirIn, name string, out *fuse.EntryOut) (code fuse.Status) {
 	ctx := newContext(cancel)
+	fs.attrCache.invalidateEntry(input.NodeId, name)
+	gen := fs.attrCache.generation()
 
 	res, err := fs.client.Mkdir(ctx, &pb.MkdirRequest{
 		Header: toPbHeader(&input.InHeader),
@@ -40,11 +42,13 @@ func (fs *fileSystem) Mkdir(cancel <-chan struct{}, input *fuse.MkdirIn, name st
 	}
 
 	toFuseEntryOut(out, res.EntryOut)
+	fs.attrCache.made(gen, input.NodeId, name, out)
 	return fuse.OK
 }
 
 func (fs *fileSystem) Unlink(cancel <-chan struct{}, header *fuse.InHeader, name string) (code fuse.Status) {
 	ctx := newContext(cancel)
+	defer fs.attrCache.invalidateEntry(header.NodeId, name)
 
 	res, err := fs.client.Unlink(ctx, &pb.UnlinkRequest{
 		Header: toPbHeader(header),
@@ -59,6 +63,7 @@ func (fs *fileSystem) Unlink(cancel <-chan struct{}, header *fuse.InHeader, name
 
 func (fs *fileSystem) Rmdir(cancel <-chan struct{}, header *fuse.InHeader, name string) (code fuse.Status) {
 	ctx := newContext(cancel)
+	defer fs.attrCache.invalidateEntry(header.NodeId, name)
 
 	res, err := fs.client.Rmdir(ctx, &pb.RmdirRequest{
 		Header: toPbHeader(header),
@@ -73,6 +78,8 @@ func (fs *fileSystem) Rmdir(cancel <-chan struct{}, header *fuse.InHeader, name
 
 func (fs *fileSystem) Rename(cancel <-chan struct{}, input *fuse.RenameIn, oldName string, newName string) (code fuse.Status) {
 	ctx := newContext(cancel)
+	defer fs.attrCache.invalidateEntry(input.NodeId, oldName)
+	defer fs.attrCache.invalidateEntry(input.Newdir, newName)
 
 	res, err := fs.client.Rename(ctx, &pb.RenameRequest{
 		Header:  toPbHeader(&input.InHeader),
@@ -24,6 +24,8 @@ import (
 
 func (fs *fileSystem) Mknod(cancel <-chan struct{}, input *fuse.MknodIn, name string, out *fuse.EntryOut) (code fuse.Status) {
 	ctx := newContext(cancel)
+	fs.attrCache.invalidateEntry(input.NodeId, name)
+	gen := fs.attrCache.generation()
 
 	res, err := fs.client.Mknod(ctx, &pb.MknodRequest{
 		Header: toPbHeader(&input.InHeader),
@@ -40,5 +42,6 @@ func (fs *fileSystem) Mknod(cancel <-chan struct{}, input *fuse.MknodIn, name st
 	}
 
 	toFuseEntryOut(out, res.EntryOut)
+	fs.attrCache.made(gen, input.NodeId, name, out)
 	return fuse.OK
 }
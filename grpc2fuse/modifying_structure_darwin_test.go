@@ -2,6 +2,7 @@ package grpc2fuse_test
 
 import (
 	"context"
+	"syscall"
 	"testing"
 
 	"github.com/chiyutianyi/grpcfuse/grpc2fuse"
@@ -10,10 +11,12 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 type mockRawFileSystemClient struct {
 	mock.Mock
+	pb.RawFileSystemClient
 }
 
 func (m *mockRawFileSystemClient) Mknod(ctx context.Context, in *pb.MknodRequest, opts ...grpc.CallOption) (*pb.MknodResponse, error) {
@@ -52,9 +55,7 @@ func TestMknod(t *testing.T) {
 			input: &fuse.MknodIn{
 				InHeader: fuse.InHeader{
 					NodeId: 1,
-					Uid:    1000,
-					Gid:    1000,
-					Pid:    12345,
+					Caller: fuse.Caller{Owner: fuse.Owner{Uid: 1000, Gid: 1000}, Pid: 12345},
 				},
 				Mode: 0644,
 				Rdev: 0,
@@ -133,11 +134,11 @@ func TestMknod(t *testing.T) {
 			nodeName: "existingnode",
 			mockResp: &pb.MknodResponse{
 				Status: &pb.Status{
-					Code: int32(fuse.EEXIST),
+					Code: int32(syscall.EEXIST),
 				},
 			},
 			mockErr: nil,
-			want:    fuse.EEXIST,
+			want:    fuse.Status(syscall.EEXIST),
 		},
 	}
 
@@ -12,11 +12,12 @@ import (
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
-type mockRawFileSystemClient struct {
+type modifyingStructureLinuxClient struct {
 	mock.Mock
+	pb.RawFileSystemClient
 }
 
-func (m *mockRawFileSystemClient) String(ctx context.Context, in *pb.StringRequest, opts ...grpc.CallOption) (*pb.StringResponse, error) {
+func (m *modifyingStructureLinuxClient) String(ctx context.Context, in *pb.StringRequest, opts ...grpc.CallOption) (*pb.StringResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.StringResponse), args.Error(1)
@@ -24,7 +25,7 @@ func (m *mockRawFileSystemClient) String(ctx context.Context, in *pb.StringReque
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) Lookup(ctx context.Context, in *pb.LookupRequest, opts ...grpc.CallOption) (*pb.LookupResponse, error) {
+func (m *modifyingStructureLinuxClient) Lookup(ctx context.Context, in *pb.LookupRequest, opts ...grpc.CallOption) (*pb.LookupResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.LookupResponse), args.Error(1)
@@ -32,7 +33,7 @@ func (m *mockRawFileSystemClient) Lookup(ctx context.Context, in *pb.LookupReque
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) Forget(ctx context.Context, in *pb.ForgetRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+func (m *modifyingStructureLinuxClient) Forget(ctx context.Context, in *pb.ForgetRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*emptypb.Empty), args.Error(1)
@@ -40,7 +41,7 @@ func (m *mockRawFileSystemClient) Forget(ctx context.Context, in *pb.ForgetReque
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) GetAttr(ctx context.Context, in *pb.GetAttrRequest, opts ...grpc.CallOption) (*pb.GetAttrResponse, error) {
+func (m *modifyingStructureLinuxClient) GetAttr(ctx context.Context, in *pb.GetAttrRequest, opts ...grpc.CallOption) (*pb.GetAttrResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.GetAttrResponse), args.Error(1)
@@ -48,7 +49,7 @@ func (m *mockRawFileSystemClient) GetAttr(ctx context.Context, in *pb.GetAttrReq
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) SetAttr(ctx context.Context, in *pb.SetAttrRequest, opts ...grpc.CallOption) (*pb.SetAttrResponse, error) {
+func (m *modifyingStructureLinuxClient) SetAttr(ctx context.Context, in *pb.SetAttrRequest, opts ...grpc.CallOption) (*pb.SetAttrResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.SetAttrResponse), args.Error(1)
@@ -56,7 +57,7 @@ func (m *mockRawFileSystemClient) SetAttr(ctx context.Context, in *pb.SetAttrReq
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) Mknod(ctx context.Context, in *pb.MknodRequest, opts ...grpc.CallOption) (*pb.MknodResponse, error) {
+func (m *modifyingStructureLinuxClient) Mknod(ctx context.Context, in *pb.MknodRequest, opts ...grpc.CallOption) (*pb.MknodResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.MknodResponse), args.Error(1)
@@ -64,7 +65,7 @@ func (m *mockRawFileSystemClient) Mknod(ctx context.Context, in *pb.MknodRequest
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) Mkdir(ctx context.Context, in *pb.MkdirRequest, opts ...grpc.CallOption) (*pb.MkdirResponse, error) {
+func (m *modifyingStructureLinuxClient) Mkdir(ctx context.Context, in *pb.MkdirRequest, opts ...grpc.CallOption) (*pb.MkdirResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.MkdirResponse), args.Error(1)
@@ -72,7 +73,7 @@ func (m *mockRawFileSystemClient) Mkdir(ctx context.Context, in *pb.MkdirRequest
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) Unlink(ctx context.Context, in *pb.UnlinkRequest, opts ...grpc.CallOption) (*pb.UnlinkResponse, error) {
+func (m *modifyingStructureLinuxClient) Unlink(ctx context.Context, in *pb.UnlinkRequest, opts ...grpc.CallOption) (*pb.UnlinkResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.UnlinkResponse), args.Error(1)
@@ -80,7 +81,7 @@ func (m *mockRawFileSystemClient) Unlink(ctx context.Context, in *pb.UnlinkReque
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) Rmdir(ctx context.Context, in *pb.RmdirRequest, opts ...grpc.CallOption) (*pb.RmdirResponse, error) {
+func (m *modifyingStructureLinuxClient) Rmdir(ctx context.Context, in *pb.RmdirRequest, opts ...grpc.CallOption) (*pb.RmdirResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.RmdirResponse), args.Error(1)
@@ -88,7 +89,7 @@ func (m *mockRawFileSystemClient) Rmdir(ctx context.Context, in *pb.RmdirRequest
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) Rename(ctx context.Context, in *pb.RenameRequest, opts ...grpc.CallOption) (*pb.RenameResponse, error) {
+func (m *modifyingStructureLinuxClient) Rename(ctx context.Context, in *pb.RenameRequest, opts ...grpc.CallOption) (*pb.RenameResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.RenameResponse), args.Error(1)
@@ -96,7 +97,7 @@ func (m *mockRawFileSystemClient) Rename(ctx context.Context, in *pb.RenameReque
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) Link(ctx context.Context, in *pb.LinkRequest, opts ...grpc.CallOption) (*pb.LinkResponse, error) {
+func (m *modifyingStructureLinuxClient) Link(ctx context.Context, in *pb.LinkRequest, opts ...grpc.CallOption) (*pb.LinkResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.LinkResponse), args.Error(1)
@@ -104,7 +105,7 @@ func (m *mockRawFileSystemClient) Link(ctx context.Context, in *pb.LinkRequest,
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) Symlink(ctx context.Context, in *pb.SymlinkRequest, opts ...grpc.CallOption) (*pb.SymlinkResponse, error) {
+func (m *modifyingStructureLinuxClient) Symlink(ctx context.Context, in *pb.SymlinkRequest, opts ...grpc.CallOption) (*pb.SymlinkResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.SymlinkResponse), args.Error(1)
@@ -112,7 +113,7 @@ func (m *mockRawFileSystemClient) Symlink(ctx context.Context, in *pb.SymlinkReq
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) Readlink(ctx context.Context, in *pb.ReadlinkRequest, opts ...grpc.CallOption) (*pb.ReadlinkResponse, error) {
+func (m *modifyingStructureLinuxClient) Readlink(ctx context.Context, in *pb.ReadlinkRequest, opts ...grpc.CallOption) (*pb.ReadlinkResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.ReadlinkResponse), args.Error(1)
@@ -120,7 +121,7 @@ func (m *mockRawFileSystemClient) Readlink(ctx context.Context, in *pb.ReadlinkR
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) Access(ctx context.Context, in *pb.AccessRequest, opts ...grpc.CallOption) (*pb.AccessResponse, error) {
+func (m *modifyingStructureLinuxClient) Access(ctx context.Context, in *pb.AccessRequest, opts ...grpc.CallOption) (*pb.AccessResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.AccessResponse), args.Error(1)
@@ -128,7 +129,7 @@ func (m *mockRawFileSystemClient) Access(ctx context.Context, in *pb.AccessReque
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) GetXAttr(ctx context.Context, in *pb.GetXAttrRequest, opts ...grpc.CallOption) (*pb.GetXAttrResponse, error) {
+func (m *modifyingStructureLinuxClient) GetXAttr(ctx context.Context, in *pb.GetXAttrRequest, opts ...grpc.CallOption) (*pb.GetXAttrResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.GetXAttrResponse), args.Error(1)
@@ -136,7 +137,7 @@ func (m *mockRawFileSystemClient) GetXAttr(ctx context.Context, in *pb.GetXAttrR
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) ListXAttr(ctx context.Context, in *pb.ListXAttrRequest, opts ...grpc.CallOption) (*pb.ListXAttrResponse, error) {
+func (m *modifyingStructureLinuxClient) ListXAttr(ctx context.Context, in *pb.ListXAttrRequest, opts ...grpc.CallOption) (*pb.ListXAttrResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.ListXAttrResponse), args.Error(1)
@@ -144,7 +145,7 @@ func (m *mockRawFileSystemClient) ListXAttr(ctx context.Context, in *pb.ListXAtt
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) SetXAttr(ctx context.Context, in *pb.SetXAttrRequest, opts ...grpc.CallOption) (*pb.SetXAttrResponse, error) {
+func (m *modifyingStructureLinuxClient) SetXAttr(ctx context.Context, in *pb.SetXAttrRequest, opts ...grpc.CallOption) (*pb.SetXAttrResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.SetXAttrResponse), args.Error(1)
@@ -152,7 +153,7 @@ func (m *mockRawFileSystemClient) SetXAttr(ctx context.Context, in *pb.SetXAttrR
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) RemoveXAttr(ctx context.Context, in *pb.RemoveXAttrRequest, opts ...grpc.CallOption) (*pb.RemoveXAttrResponse, error) {
+func (m *modifyingStructureLinuxClient) RemoveXAttr(ctx context.Context, in *pb.RemoveXAttrRequest, opts ...grpc.CallOption) (*pb.RemoveXAttrResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.RemoveXAttrResponse), args.Error(1)
@@ -160,7 +161,7 @@ func (m *mockRawFileSystemClient) RemoveXAttr(ctx context.Context, in *pb.Remove
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) Create(ctx context.Context, in *pb.CreateRequest, opts ...grpc.CallOption) (*pb.CreateResponse, error) {
+func (m *modifyingStructureLinuxClient) Create(ctx context.Context, in *pb.CreateRequest, opts ...grpc.CallOption) (*pb.CreateResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.CreateResponse), args.Error(1)
@@ -168,7 +169,7 @@ func (m *mockRawFileSystemClient) Create(ctx context.Context, in *pb.CreateReque
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) Open(ctx context.Context, in *pb.OpenRequest, opts ...grpc.CallOption) (*pb.OpenResponse, error) {
+func (m *modifyingStructureLinuxClient) Open(ctx context.Context, in *pb.OpenRequest, opts ...grpc.CallOption) (*pb.OpenResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.OpenResponse), args.Error(1)
@@ -176,7 +177,7 @@ func (m *mockRawFileSystemClient) Open(ctx context.Context, in *pb.OpenRequest,
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) Read(ctx context.Context, in *pb.ReadRequest, opts ...grpc.CallOption) (pb.RawFileSystem_ReadClient, error) {
+func (m *modifyingStructureLinuxClient) Read(ctx context.Context, in *pb.ReadRequest, opts ...grpc.CallOption) (pb.RawFileSystem_ReadClient, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(pb.RawFileSystem_ReadClient), args.Error(1)
@@ -184,7 +185,7 @@ func (m *mockRawFileSystemClient) Read(ctx context.Context, in *pb.ReadRequest,
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) Write(ctx context.Context, in *pb.WriteRequest, opts ...grpc.CallOption) (*pb.WriteResponse, error) {
+func (m *modifyingStructureLinuxClient) Write(ctx context.Context, in *pb.WriteRequest, opts ...grpc.CallOption) (*pb.WriteResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.WriteResponse), args.Error(1)
@@ -192,7 +193,7 @@ func (m *mockRawFileSystemClient) Write(ctx context.Context, in *pb.WriteRequest
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) Lseek(ctx context.Context, in *pb.LseekRequest, opts ...grpc.CallOption) (*pb.LseekResponse, error) {
+func (m *modifyingStructureLinuxClient) Lseek(ctx context.Context, in *pb.LseekRequest, opts ...grpc.CallOption) (*pb.LseekResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.LseekResponse), args.Error(1)
@@ -200,7 +201,7 @@ func (m *mockRawFileSystemClient) Lseek(ctx context.Context, in *pb.LseekRequest
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) Release(ctx context.Context, in *pb.ReleaseRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+func (m *modifyingStructureLinuxClient) Release(ctx context.Context, in *pb.ReleaseRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*emptypb.Empty), args.Error(1)
@@ -208,7 +209,7 @@ func (m *mockRawFileSystemClient) Release(ctx context.Context, in *pb.ReleaseReq
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) GetLk(ctx context.Context, in *pb.LkRequest, opts ...grpc.CallOption) (*pb.GetLkResponse, error) {
+func (m *modifyingStructureLinuxClient) GetLk(ctx context.Context, in *pb.LkRequest, opts ...grpc.CallOption) (*pb.GetLkResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.GetLkResponse), args.Error(1)
@@ -216,7 +217,7 @@ func (m *mockRawFileSystemClient) GetLk(ctx context.Context, in *pb.LkRequest, o
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) SetLk(ctx context.Context, in *pb.LkRequest, opts ...grpc.CallOption) (*pb.SetLkResponse, error) {
+func (m *modifyingStructureLinuxClient) SetLk(ctx context.Context, in *pb.LkRequest, opts ...grpc.CallOption) (*pb.SetLkResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.SetLkResponse), args.Error(1)
@@ -224,7 +225,7 @@ func (m *mockRawFileSystemClient) SetLk(ctx context.Context, in *pb.LkRequest, o
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) SetLkw(ctx context.Context, in *pb.LkRequest, opts ...grpc.CallOption) (*pb.SetLkResponse, error) {
+func (m *modifyingStructureLinuxClient) SetLkw(ctx context.Context, in *pb.LkRequest, opts ...grpc.CallOption) (*pb.SetLkResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.SetLkResponse), args.Error(1)
@@ -232,7 +233,7 @@ func (m *mockRawFileSystemClient) SetLkw(ctx context.Context, in *pb.LkRequest,
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) CopyFileRange(ctx context.Context, in *pb.CopyFileRangeRequest, opts ...grpc.CallOption) (*pb.CopyFileRangeResponse, error) {
+func (m *modifyingStructureLinuxClient) CopyFileRange(ctx context.Context, in *pb.CopyFileRangeRequest, opts ...grpc.CallOption) (*pb.CopyFileRangeResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.CopyFileRangeResponse), args.Error(1)
@@ -240,7 +241,7 @@ func (m *mockRawFileSystemClient) CopyFileRange(ctx context.Context, in *pb.Copy
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) Flush(ctx context.Context, in *pb.FlushRequest, opts ...grpc.CallOption) (*pb.FlushResponse, error) {
+func (m *modifyingStructureLinuxClient) Flush(ctx context.Context, in *pb.FlushRequest, opts ...grpc.CallOption) (*pb.FlushResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.FlushResponse), args.Error(1)
@@ -248,7 +249,7 @@ func (m *mockRawFileSystemClient) Flush(ctx context.Context, in *pb.FlushRequest
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) Fsync(ctx context.Context, in *pb.FsyncRequest, opts ...grpc.CallOption) (*pb.FsyncResponse, error) {
+func (m *modifyingStructureLinuxClient) Fsync(ctx context.Context, in *pb.FsyncRequest, opts ...grpc.CallOption) (*pb.FsyncResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.FsyncResponse), args.Error(1)
@@ -256,7 +257,7 @@ func (m *mockRawFileSystemClient) Fsync(ctx context.Context, in *pb.FsyncRequest
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) Fallocate(ctx context.Context, in *pb.FallocateRequest, opts ...grpc.CallOption) (*pb.FallocateResponse, error) {
+func (m *modifyingStructureLinuxClient) Fallocate(ctx context.Context, in *pb.FallocateRequest, opts ...grpc.CallOption) (*pb.FallocateResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.FallocateResponse), args.Error(1)
@@ -264,7 +265,7 @@ func (m *mockRawFileSystemClient) Fallocate(ctx context.Context, in *pb.Fallocat
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) OpenDir(ctx context.Context, in *pb.OpenDirRequest, opts ...grpc.CallOption) (*pb.OpenDirResponse, error) {
+func (m *modifyingStructureLinuxClient) OpenDir(ctx context.Context, in *pb.OpenDirRequest, opts ...grpc.CallOption) (*pb.OpenDirResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.OpenDirResponse), args.Error(1)
@@ -272,7 +273,7 @@ func (m *mockRawFileSystemClient) OpenDir(ctx context.Context, in *pb.OpenDirReq
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) ReadDir(ctx context.Context, in *pb.ReadDirRequest, opts ...grpc.CallOption) (pb.RawFileSystem_ReadDirClient, error) {
+func (m *modifyingStructureLinuxClient) ReadDir(ctx context.Context, in *pb.ReadDirRequest, opts ...grpc.CallOption) (pb.RawFileSystem_ReadDirClient, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(pb.RawFileSystem_ReadDirClient), args.Error(1)
@@ -280,7 +281,7 @@ func (m *mockRawFileSystemClient) ReadDir(ctx context.Context, in *pb.ReadDirReq
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) ReadDirPlus(ctx context.Context, in *pb.ReadDirRequest, opts ...grpc.CallOption) (pb.RawFileSystem_ReadDirPlusClient, error) {
+func (m *modifyingStructureLinuxClient) ReadDirPlus(ctx context.Context, in *pb.ReadDirRequest, opts ...grpc.CallOption) (pb.RawFileSystem_ReadDirPlusClient, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(pb.RawFileSystem_ReadDirPlusClient), args.Error(1)
@@ -288,7 +289,7 @@ func (m *mockRawFileSystemClient) ReadDirPlus(ctx context.Context, in *pb.ReadDi
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) ReleaseDir(ctx context.Context, in *pb.ReleaseRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+func (m *modifyingStructureLinuxClient) ReleaseDir(ctx context.Context, in *pb.ReleaseRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*emptypb.Empty), args.Error(1)
@@ -296,7 +297,7 @@ func (m *mockRawFileSystemClient) ReleaseDir(ctx context.Context, in *pb.Release
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) FsyncDir(ctx context.Context, in *pb.FsyncRequest, opts ...grpc.CallOption) (*pb.FsyncResponse, error) {
+func (m *modifyingStructureLinuxClient) FsyncDir(ctx context.Context, in *pb.FsyncRequest, opts ...grpc.CallOption) (*pb.FsyncResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.FsyncResponse), args.Error(1)
@@ -304,7 +305,7 @@ func (m *mockRawFileSystemClient) FsyncDir(ctx context.Context, in *pb.FsyncRequ
 	return nil, args.Error(1)
 }
 
-func (m *mockRawFileSystemClient) StatFs(ctx context.Context, in *pb.StatfsRequest, opts ...grpc.CallOption) (*pb.StatfsResponse, error) {
+func (m *modifyingStructureLinuxClient) StatFs(ctx context.Context, in *pb.StatfsRequest, opts ...grpc.CallOption) (*pb.StatfsResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if resp := args.Get(0); resp != nil {
 		return resp.(*pb.StatfsResponse), args.Error(1)
@@ -390,7 +391,7 @@ func TestMknod(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockClient := new(mockRawFileSystemClient)
+			mockClient := new(modifyingStructureLinuxClient)
 			fs := &fileSystem{
 				client: mockClient,
 			}
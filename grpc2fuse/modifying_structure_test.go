@@ -12,42 +12,42 @@ import (
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
-type MockRawFileSystemClient struct {
+type modifyingStructureClient struct {
 	mock.Mock
 	pb.RawFileSystemClient
 }
 
-func (m *MockRawFileSystemClient) String(ctx context.Context, in *pb.StringRequest, opts ...grpc.CallOption) (*pb.StringResponse, error) {
+func (m *modifyingStructureClient) String(ctx context.Context, in *pb.StringRequest, opts ...grpc.CallOption) (*pb.StringResponse, error) {
 	args := m.Called(ctx, in, opts)
 	return args.Get(0).(*pb.StringResponse), args.Error(1)
 }
 
-func (m *MockRawFileSystemClient) Lookup(ctx context.Context, in *pb.LookupRequest, opts ...grpc.CallOption) (*pb.LookupResponse, error) {
+func (m *modifyingStructureClient) Lookup(ctx context.Context, in *pb.LookupRequest, opts ...grpc.CallOption) (*pb.LookupResponse, error) {
 	args := m.Called(ctx, in, opts)
 	return args.Get(0).(*pb.LookupResponse), args.Error(1)
 }
 
-func (m *MockRawFileSystemClient) Forget(ctx context.Context, in *pb.ForgetRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+func (m *modifyingStructureClient) Forget(ctx context.Context, in *pb.ForgetRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	args := m.Called(ctx, in, opts)
 	return args.Get(0).(*emptypb.Empty), args.Error(1)
 }
 
-func (m *MockRawFileSystemClient) GetAttr(ctx context.Context, in *pb.GetAttrRequest, opts ...grpc.CallOption) (*pb.GetAttrResponse, error) {
+func (m *modifyingStructureClient) GetAttr(ctx context.Context, in *pb.GetAttrRequest, opts ...grpc.CallOption) (*pb.GetAttrResponse, error) {
 	args := m.Called(ctx, in, opts)
 	return args.Get(0).(*pb.GetAttrResponse), args.Error(1)
 }
 
-func (m *MockRawFileSystemClient) SetAttr(ctx context.Context, in *pb.SetAttrRequest, opts ...grpc.CallOption) (*pb.SetAttrResponse, error) {
+func (m *modifyingStructureClient) SetAttr(ctx context.Context, in *pb.SetAttrRequest, opts ...grpc.CallOption) (*pb.SetAttrResponse, error) {
 	args := m.Called(ctx, in, opts)
 	return args.Get(0).(*pb.SetAttrResponse), args.Error(1)
 }
 
-func (m *MockRawFileSystemClient) Mknod(ctx context.Context, in *pb.MknodRequest, opts ...grpc.CallOption) (*pb.MknodResponse, error) {
+func (m *modifyingStructureClient) Mknod(ctx context.Context, in *pb.MknodRequest, opts ...grpc.CallOption) (*pb.MknodResponse, error) {
 	args := m.Called(ctx, in, opts)
 	return args.Get(0).(*pb.MknodResponse), args.Error(1)
 }
 
-func (m *MockRawFileSystemClient) Mkdir(ctx context.Context, in *pb.MkdirRequest, opts ...grpc.CallOption) (*pb.MkdirResponse, error) {
+func (m *modifyingStructureClient) Mkdir(ctx context.Context, in *pb.MkdirRequest, opts ...grpc.CallOption) (*pb.MkdirResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -55,7 +55,7 @@ func (m *MockRawFileSystemClient) Mkdir(ctx context.Context, in *pb.MkdirRequest
 	return args.Get(0).(*pb.MkdirResponse), args.Error(1)
 }
 
-func (m *MockRawFileSystemClient) Unlink(ctx context.Context, in *pb.UnlinkRequest, opts ...grpc.CallOption) (*pb.UnlinkResponse, error) {
+func (m *modifyingStructureClient) Unlink(ctx context.Context, in *pb.UnlinkRequest, opts ...grpc.CallOption) (*pb.UnlinkResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -63,7 +63,7 @@ func (m *MockRawFileSystemClient) Unlink(ctx context.Context, in *pb.UnlinkReque
 	return args.Get(0).(*pb.UnlinkResponse), args.Error(1)
 }
 
-func (m *MockRawFileSystemClient) Rmdir(ctx context.Context, in *pb.RmdirRequest, opts ...grpc.CallOption) (*pb.RmdirResponse, error) {
+func (m *modifyingStructureClient) Rmdir(ctx context.Context, in *pb.RmdirRequest, opts ...grpc.CallOption) (*pb.RmdirResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -71,7 +71,7 @@ func (m *MockRawFileSystemClient) Rmdir(ctx context.Context, in *pb.RmdirRequest
 	return args.Get(0).(*pb.RmdirResponse), args.Error(1)
 }
 
-func (m *MockRawFileSystemClient) Rename(ctx context.Context, in *pb.RenameRequest, opts ...grpc.CallOption) (*pb.RenameResponse, error) {
+func (m *modifyingStructureClient) Rename(ctx context.Context, in *pb.RenameRequest, opts ...grpc.CallOption) (*pb.RenameResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -80,7 +80,7 @@ func (m *MockRawFileSystemClient) Rename(ctx context.Context, in *pb.RenameReque
 }
 
 func TestFileSystem_Mkdir(t *testing.T) {
-	mockClient := new(MockRawFileSystemClient)
+	mockClient := new(modifyingStructureClient)
 	fs := &fileSystem{
 		client: mockClient,
 	}
@@ -168,7 +168,7 @@ func TestFileSystem_Mkdir(t *testing.T) {
 }
 
 func TestFileSystem_Unlink(t *testing.T) {
-	mockClient := new(MockRawFileSystemClient)
+	mockClient := new(modifyingStructureClient)
 	fs := &fileSystem{
 		client: mockClient,
 	}
@@ -233,7 +233,7 @@ func TestFileSystem_Unlink(t *testing.T) {
 }
 
 func TestFileSystem_Rmdir(t *testing.T) {
-	mockClient := new(MockRawFileSystemClient)
+	mockClient := new(modifyingStructureClient)
 	fs := &fileSystem{
 		client: mockClient,
 	}
@@ -298,7 +298,7 @@ func TestFileSystem_Rmdir(t *testing.T) {
 }
 
 func TestFileSystem_Rename(t *testing.T) {
-	mockClient := new(MockRawFileSystemClient)
+	mockClient := new(modifyingStructureClient)
 	fs := &fileSystem{
 		client: mockClient,
 	}
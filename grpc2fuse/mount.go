@@ -0,0 +1,469 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc2fuse
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/chiyutianyi/grpcfuse/blockcache"
+	"github.com/chiyutianyi/grpcfuse/exports"
+	"github.com/chiyutianyi/grpcfuse/journal"
+	"github.com/chiyutianyi/grpcfuse/pb"
+	"github.com/chiyutianyi/grpcfuse/shm"
+)
+
+const defaultMaxIO = 1 << 20
+
+// shmSlots is how many reads and writes of a mount with SharedMemory go
+// through shared memory at once.
+const shmSlots = 64
+
+// MountOptions configures Mount. The zero value is usable.
+type MountOptions struct {
+	// Export is the export of the server to mount, for servers with
+	// several; the server's default export if empty.
+	Export string
+	// TLS secures the connection; nil connects without TLS.
+	TLS *tls.Config
+	// DialOptions are added to the options of the connection.
+	DialOptions []grpc.DialOption
+	// SharedMemory moves the data of reads and writes through a memfd
+	// shared with the server, for a target "unix:<path>" served with
+	// shared memory on <path>.shm.
+	SharedMemory bool
+	// Connections is how many connections carry reads and writes, besides
+	// the one for the other calls; 0 sends everything over one
+	// connection. With two or more, reads and writes larger than
+	// StripeSize, 256KiB by default, are cut into stripes sent at once
+	// over all of them.
+	Connections int
+	StripeSize  int
+	// ReadAhead is how many bytes past the sequential reads of a handle
+	// are fetched before the kernel asks for them, in chunks of MaxWrite
+	// read at once; 0 leaves read-ahead to the kernel.
+	ReadAhead int
+	// WriteBehind, if non-zero, lets writes return before the server has
+	// them, with up to WriteBehind bytes on their way. Adjacent writes
+	// are merged up to MaxWrite. A write that fails is reported by the
+	// next fsync or close of the file.
+	WriteBehind int
+	// CacheDir, if set, keeps the blocks read in a cache on disk that
+	// outlives the mount, up to CacheSize bytes (1GiB by default) for
+	// each target and export. A file is checked for changes when opened;
+	// until then its handles read the blocks of the version they opened.
+	CacheDir  string
+	CacheSize int64
+	// Offline, with CacheDir, keeps answering from the cache while the
+	// server is unreachable, also when mounting: names, attributes,
+	// listings and symlinks seen before, and the blocks in the cache;
+	// other names do not exist. Changes fail with EROFS until the server
+	// is back. The ModeXAttr of every file reads "online" or "offline".
+	Offline bool
+	// Journal, with Offline, lets files be changed offline: the changes
+	// are made to the cache and kept in a journal, which is replayed to
+	// the server before going back online. A change that meets another
+	// made on the server meanwhile is resolved by Conflicts, and kept
+	// with the conflicts of the journal.
+	Journal   bool
+	Conflicts ConflictPolicy
+
+	// AttrTimeout and EntryTimeout, if non-zero, replace how long the
+	// server lets the kernel cache attributes and names.
+	AttrTimeout  time.Duration
+	EntryTimeout time.Duration
+	// NegativeTimeout, if non-zero, lets the kernel cache that a name
+	// does not exist.
+	NegativeTimeout time.Duration
+	// AttrCache caches attributes, names, names that do not exist and
+	// symlinks in the mount itself, for what the kernel asks again
+	// despite its own cache, such as the lookups of names that do not
+	// exist when NegativeTimeout is 0.
+	AttrCache AttrCacheOptions
+	// SmallFiles, if non-zero, collapses the calls of files of at most
+	// SmallFiles bytes, up to 512KiB, into single Compound calls: a file
+	// opened read-only is opened, read and closed at once, and with
+	// WriteBehind a file created is written and flushed at once. Servers
+	// without Compound get the calls one by one.
+	SmallFiles int
+
+	// Retries is how many times calls that only read are tried again
+	// when the server is unavailable, waiting RetryBackoff before the
+	// first retry and twice as long before each next one.
+	Retries      int
+	RetryBackoff time.Duration
+
+	// MaxWrite and MaxReadAhead are the largest writes and read-ahead the
+	// kernel sends, 1MiB by default.
+	MaxWrite     int
+	MaxReadAhead int
+	AllowOther   bool
+	ReadOnly     bool
+	// FsName and Name are the first and second ("fuse." + Name) columns
+	// of df -T, "GrpcFS" and "grpcfs" by default.
+	FsName string
+	Name   string
+	// Options are more options of the file system, such as
+	// "max_read=131072".
+	Options []string
+	// MountFlags are the flags of mount(2), such as syscall.MS_NOATIME.
+	// They are lost if mounting falls back to fusermount.
+	MountFlags uintptr
+	Debug      bool
+}
+
+func (o MountOptions) withDefaults() MountOptions {
+	if o.MaxWrite == 0 {
+		o.MaxWrite = defaultMaxIO
+	}
+	if o.MaxReadAhead == 0 {
+		o.MaxReadAhead = defaultMaxIO
+	}
+	if o.FsName == "" {
+		o.FsName = "GrpcFS"
+	}
+	if o.Name == "" {
+		o.Name = "grpcfs"
+	}
+	if o.RetryBackoff == 0 {
+		o.RetryBackoff = 100 * time.Millisecond
+	}
+	if o.StripeSize == 0 {
+		o.StripeSize = defaultStripeSize
+	}
+	return o
+}
+
+// fixed returns the options that cannot change once mounted.
+func (o MountOptions) fixed() MountOptions {
+	o = o.withDefaults()
+	o.TLS, o.DialOptions = nil, nil
+	o.AttrTimeout, o.EntryTimeout, o.NegativeTimeout = 0, 0, 0
+	o.Retries, o.RetryBackoff = 0, 0
+	return o
+}
+
+// fuseOptions returns the options of the fuse server.
+func (o MountOptions) fuseOptions() *fuse.MountOptions {
+	o = o.withDefaults()
+	opt := &fuse.MountOptions{
+		FsName:               o.FsName,
+		Name:                 o.Name,
+		MaxBackground:        50,
+		EnableLocks:          true,
+		IgnoreSecurityLabels: true,
+		MaxWrite:             o.MaxWrite,
+		MaxReadAhead:         o.MaxReadAhead,
+		DirectMount:          true,
+		DirectMountFlags:     o.MountFlags,
+		AllowOther:           o.AllowOther,
+		Debug:                o.Debug,
+		Options:              []string{"default_permissions"},
+	}
+	if runtime.GOOS == "darwin" {
+		opt.Options = append(opt.Options, "fssubtype="+o.Name, "volname="+o.Name, "daemon_timeout=60", "iosize=65536", "novncache")
+	}
+	if o.ReadOnly {
+		opt.Options = append(opt.Options, "ro")
+	}
+	opt.Options = append(opt.Options, o.Options...)
+	return opt
+}
+
+func (o MountOptions) dialOptions() []grpc.DialOption {
+	var opts []grpc.DialOption
+	if o.TLS != nil {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(o.TLS)))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+	if o.Export != "" {
+		opts = append(opts, exports.DialOptions(o.Export)...)
+	}
+	return append(opts, o.DialOptions...)
+}
+
+// Mounted is a file system mounted by Mount.
+type Mounted struct {
+	mountpoint, target string
+
+	mu   sync.RWMutex
+	opts MountOptions
+
+	stats   *callStats
+	shm     *shm.Client
+	cache   *blockcache.Cache
+	offline *offlineFS
+	fs      *fileSystem
+	conns   []*grpc.ClientConn
+	server  *fuse.Server
+	done    chan struct{}
+}
+
+// Mount dials target, checks that the server answers and mounts it on
+// mountpoint. ctx bounds the dialing and the handshake; the file system
+// stays mounted until Unmount or an unmount from outside.
+func Mount(ctx context.Context, mountpoint, target string, opts *MountOptions) (*Mounted, error) {
+	if opts == nil {
+		opts = &MountOptions{}
+	}
+	m := &Mounted{
+		mountpoint: mountpoint,
+		target:     target,
+		opts:       opts.withDefaults(),
+		stats:      newCallStats(),
+		done:       make(chan struct{}),
+	}
+
+	if m.opts.Offline && m.opts.CacheDir == "" {
+		return nil, fmt.Errorf("grpc2fuse: offline needs a cache directory")
+	}
+	if m.opts.Journal && !m.opts.Offline {
+		return nil, fmt.Errorf("grpc2fuse: journal needs offline")
+	}
+	var cache *blockcache.Cache
+	var dialOpts []grpc.DialOption
+	if m.opts.CacheDir != "" {
+		dir := filepath.Join(m.opts.CacheDir, cacheName(target, m.opts.Export))
+		var err error
+		if cache, err = blockcache.Open(dir, &blockcache.Options{Size: m.opts.CacheSize}); err != nil {
+			return nil, fmt.Errorf("grpc2fuse: %v", err)
+		}
+		m.cache = cache
+		if m.opts.Offline {
+			meta, err := openMetaCache(filepath.Join(dir, "meta"), 0)
+			if err != nil {
+				return nil, fmt.Errorf("grpc2fuse: %v", err)
+			}
+			m.offline = newOfflineFS(meta)
+			if m.opts.Journal {
+				if m.offline.journal, err = journal.Open(JournalDir(m.opts.CacheDir, target, m.opts.Export)); err != nil {
+					return nil, fmt.Errorf("grpc2fuse: %v", err)
+				}
+				m.offline.conflicts = m.opts.Conflicts
+			}
+			// Outermost, to see the calls that failed after all retries.
+			dialOpts = append(dialOpts,
+				grpc.WithChainUnaryInterceptor(m.offline.unaryInterceptor),
+				grpc.WithChainStreamInterceptor(m.offline.streamInterceptor))
+		}
+	}
+
+	dialOpts = append(dialOpts,
+		grpc.WithChainUnaryInterceptor(m.stats.unaryInterceptor, m.unaryRetryInterceptor),
+		grpc.WithChainStreamInterceptor(m.stats.streamInterceptor, m.streamRetryInterceptor))
+	if m.opts.SharedMemory {
+		path, ok := unixSocket(target)
+		if !ok {
+			m.close()
+			return nil, fmt.Errorf("grpc2fuse: shared memory needs a unix: target, not %s", target)
+		}
+		var err error
+		m.shm, err = shm.Dial(path+".shm", &shm.Options{Size: shmSlots * m.opts.MaxWrite, SlotSize: m.opts.MaxWrite})
+		if err != nil {
+			m.close()
+			return nil, fmt.Errorf("grpc2fuse: %v", err)
+		}
+		dialOpts = append(dialOpts,
+			grpc.WithChainUnaryInterceptor(m.shm.UnaryClientInterceptor()),
+			grpc.WithChainStreamInterceptor(m.shm.StreamClientInterceptor()))
+	}
+	dialOpts = append(dialOpts, m.opts.dialOptions()...)
+	clients := make([]pb.RawFileSystemClient, 1+m.opts.Connections)
+	for i := range clients {
+		conn, err := grpc.DialContext(ctx, target, dialOpts...)
+		if err != nil {
+			m.close()
+			return nil, fmt.Errorf("grpc2fuse: dial %s: %v", target, err)
+		}
+		m.conns = append(m.conns, conn)
+		clients[i] = pb.NewRawFileSystemClient(conn)
+	}
+	fs := NewPooledFileSystem(NewPool(clients[0], clients[1:], m.opts.StripeSize))
+	fs.SetReadAhead(m.opts.ReadAhead, m.opts.MaxWrite)
+	fs.SetWriteBehind(m.opts.WriteBehind, m.opts.MaxWrite)
+	fs.SetCache(cache)
+	fs.SetAttrCache(&m.opts.AttrCache)
+	fs.SetSmallFiles(m.opts.SmallFiles)
+	m.fs = fs
+	var rfs fuse.RawFileSystem = &timeoutFS{RawFileSystem: fs, m: m}
+	if o := m.offline; o != nil {
+		fs.listed = o.listed
+		o.RawFileSystem, o.fs = rfs, fs
+		o.probe = func(ctx context.Context) error {
+			_, err := clients[0].String(ctx, &pb.StringRequest{})
+			return err
+		}
+		rfs = o
+	}
+
+	// An offline mount does not wait for the server, which answering
+	// from the cache is for.
+	res, err := clients[0].String(ctx, &pb.StringRequest{}, grpc.WaitForReady(m.offline == nil))
+	switch {
+	case err == nil:
+		log.Debugf("Mounting %s (%s) on %s over %d connections", target, res.Value, mountpoint, len(m.conns))
+		m.offline.replayNow()
+	case m.offline.isOffline():
+		log.Warnf("Mounting %s on %s offline: %v", target, mountpoint, err)
+	default:
+		m.close()
+		return nil, fmt.Errorf("grpc2fuse: handshake with %s: %v", target, err)
+	}
+
+	server, err := fuse.NewServer(rfs, mountpoint, m.opts.fuseOptions())
+	if err != nil {
+		m.close()
+		return nil, fmt.Errorf("grpc2fuse: mount %s: %v", mountpoint, err)
+	}
+	m.server = server
+	go func() {
+		server.Serve()
+		m.close()
+		close(m.done)
+	}()
+	if err := server.WaitMount(); err != nil {
+		server.Unmount()
+		<-m.done
+		return nil, fmt.Errorf("grpc2fuse: mount %s: %v", mountpoint, err)
+	}
+	return m, nil
+}
+
+// cacheName is the directory in CacheDir of the blocks of an export, as
+// inode numbers are only unique within one.
+func cacheName(target, export string) string {
+	sum := sha256.Sum256([]byte(target + "\x00" + export))
+	return hex.EncodeToString(sum[:8])
+}
+
+// JournalDir is the directory of the journal of a mount of target and
+// export with CacheDir and Journal set.
+func JournalDir(cacheDir, target, export string) string {
+	return filepath.Join(cacheDir, cacheName(target, export), "journal")
+}
+
+// unixSocket returns the path of a "unix:" target.
+func unixSocket(target string) (string, bool) {
+	for _, prefix := range []string{"unix://", "unix:"} {
+		if strings.HasPrefix(target, prefix) {
+			return strings.TrimPrefix(target, prefix), true
+		}
+	}
+	return "", false
+}
+
+// close closes the connections and the shared memory of the mount.
+func (m *Mounted) close() {
+	m.offline.close()
+	for _, conn := range m.conns {
+		conn.Close()
+	}
+	if m.shm == nil {
+		return
+	}
+	if err := m.shm.Close(); err != nil {
+		log.Errorf("grpc2fuse: %v", err)
+	}
+}
+
+// Wait waits until the file system is unmounted.
+func (m *Mounted) Wait() {
+	<-m.done
+}
+
+// Unmount unmounts the file system and waits for the calls in flight.
+func (m *Mounted) Unmount() error {
+	if err := m.server.Unmount(); err != nil {
+		return err
+	}
+	<-m.done
+	return nil
+}
+
+// Reconfigure applies the cache timeouts and retries of opts to the
+// mounted file system. Changing any other option fails, except TLS and
+// DialOptions, which are ignored.
+func (m *Mounted) Reconfigure(opts *MountOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !reflect.DeepEqual(opts.fixed(), m.opts.fixed()) {
+		return fmt.Errorf("grpc2fuse: only cache timeouts and retries can change once mounted")
+	}
+	tls, dialOpts := m.opts.TLS, m.opts.DialOptions
+	m.opts = opts.withDefaults()
+	m.opts.TLS, m.opts.DialOptions = tls, dialOpts
+	return nil
+}
+
+// options returns the current options.
+func (m *Mounted) options() MountOptions {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.opts
+}
+
+// Stats returns the calls made so far.
+func (m *Mounted) Stats() Stats {
+	s := m.stats.snapshot()
+	s.Mountpoint, s.Target = m.mountpoint, m.target
+	if m.shm != nil {
+		s.SharedMemory = m.shm.Stats()
+	}
+	if m.cache != nil {
+		s.Cache = m.cache.Stats()
+	}
+	s.Offline = m.offline.isOffline()
+	if m.fs != nil {
+		s.AttrCache = m.fs.AttrCacheStats()
+	}
+	return s
+}
+
+// InvalidateNode drops what the mount and the kernel keep of node: its
+// attributes, symlink target and data. It is for servers that tell of
+// changes made by others.
+func (m *Mounted) InvalidateNode(node uint64) error {
+	m.fs.changed(node)
+	if st := m.server.InodeNotify(node, 0, -1); st != fuse.OK && st != fuse.ENOENT {
+		return fmt.Errorf("grpc2fuse: invalidate node %d: %v", node, st)
+	}
+	return nil
+}
+
+// InvalidateEntry drops what the mount and the kernel keep of name in
+// parent, found or not.
+func (m *Mounted) InvalidateEntry(parent uint64, name string) error {
+	if st := m.fs.InvalidateEntry(parent, name); st != fuse.OK {
+		return fmt.Errorf("grpc2fuse: invalidate %s in node %d: %v", name, parent, st)
+	}
+	return nil
+}
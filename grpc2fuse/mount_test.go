@@ -0,0 +1,402 @@
+package grpc2fuse
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/chiyutianyi/grpcfuse/exports"
+	"github.com/chiyutianyi/grpcfuse/fuse2grpc"
+	"github.com/chiyutianyi/grpcfuse/journal"
+	"github.com/chiyutianyi/grpcfuse/memfs"
+	"github.com/chiyutianyi/grpcfuse/pb"
+	"github.com/chiyutianyi/grpcfuse/shm"
+)
+
+func TestMountFuseOptions(t *testing.T) {
+	opt := MountOptions{}.fuseOptions()
+	assert.Equal(t, "GrpcFS", opt.FsName)
+	assert.Equal(t, "grpcfs", opt.Name)
+	assert.Equal(t, 50, opt.MaxBackground)
+	assert.True(t, opt.EnableLocks)
+	assert.Equal(t, 1<<20, opt.MaxWrite)
+	assert.Equal(t, 1<<20, opt.MaxReadAhead)
+	assert.True(t, opt.DirectMount)
+	assert.False(t, opt.AllowOther)
+	assert.Contains(t, opt.Options, "default_permissions")
+	assert.NotContains(t, opt.Options, "ro")
+
+	opt = MountOptions{FsName: "srv:/data", MaxWrite: 128 << 10, AllowOther: true, ReadOnly: true, Options: []string{"max_read=4096"}, MountFlags: 1}.fuseOptions()
+	assert.Equal(t, "srv:/data", opt.FsName)
+	assert.Equal(t, 128<<10, opt.MaxWrite)
+	assert.True(t, opt.AllowOther)
+	assert.Contains(t, opt.Options, "ro")
+	assert.Equal(t, "max_read=4096", opt.Options[len(opt.Options)-1])
+	assert.Equal(t, uintptr(1), opt.DirectMountFlags)
+}
+
+func TestReconfigure(t *testing.T) {
+	m := &Mounted{opts: MountOptions{Export: "a", AttrTimeout: time.Second}.withDefaults()}
+	require.NoError(t, m.Reconfigure(&MountOptions{Export: "a", EntryTimeout: time.Minute, Retries: 3}))
+	opts := m.options()
+	assert.Equal(t, time.Duration(0), opts.AttrTimeout)
+	assert.Equal(t, time.Minute, opts.EntryTimeout)
+	assert.Equal(t, 3, opts.Retries)
+
+	assert.Error(t, m.Reconfigure(&MountOptions{Export: "b"}))
+	assert.Error(t, m.Reconfigure(&MountOptions{Export: "a", ReadOnly: true}))
+	assert.Error(t, m.Reconfigure(&MountOptions{Export: "a", Connections: 4}))
+	assert.Equal(t, 3, m.options().Retries)
+}
+
+func TestTimeoutFS(t *testing.T) {
+	// The server's timeout stays unless the mount has one.
+	sec := time.Second
+	backend := memfs.New(nil, &fs.Options{EntryTimeout: &sec})
+	root := &fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}
+	var entry fuse.EntryOut
+	require.Equal(t, fuse.OK, backend.Mkdir(nil, &fuse.MkdirIn{InHeader: *root, Mode: 0755}, "dir", &entry))
+
+	m := &Mounted{}
+	tfs := &timeoutFS{RawFileSystem: backend, m: m}
+	assert.Equal(t, fuse.ENOENT, tfs.Lookup(nil, root, "nope", &entry))
+	require.Equal(t, fuse.OK, tfs.Lookup(nil, root, "dir", &entry))
+	assert.Equal(t, time.Second, entry.EntryTimeout())
+
+	require.NoError(t, m.Reconfigure(&MountOptions{AttrTimeout: time.Minute, EntryTimeout: time.Hour, NegativeTimeout: 5 * time.Second}))
+	require.Equal(t, fuse.OK, tfs.Lookup(nil, root, "nope", &entry))
+	assert.Equal(t, uint64(0), entry.NodeId)
+	assert.Equal(t, 5*time.Second, entry.EntryTimeout())
+
+	require.Equal(t, fuse.OK, tfs.Lookup(nil, root, "dir", &entry))
+	assert.Equal(t, time.Hour, entry.EntryTimeout())
+	assert.Equal(t, time.Minute, entry.AttrTimeout())
+
+	var create fuse.CreateOut
+	require.Equal(t, fuse.OK, tfs.Create(nil, &fuse.CreateIn{InHeader: *root, Mode: 0644}, "file", &create))
+	assert.Equal(t, time.Hour, create.EntryTimeout())
+
+	var attr fuse.AttrOut
+	require.Equal(t, fuse.OK, tfs.GetAttr(nil, &fuse.GetAttrIn{InHeader: fuse.InHeader{NodeId: entry.NodeId}}, &attr))
+	assert.Equal(t, time.Minute, attr.Timeout())
+}
+
+func TestRetry(t *testing.T) {
+	m := &Mounted{stats: newCallStats()}
+	require.NoError(t, m.Reconfigure(&MountOptions{Retries: 2, RetryBackoff: time.Millisecond}))
+
+	tests := []struct {
+		name     string
+		method   string
+		failures int
+		code     codes.Code
+		want     codes.Code
+		calls    int
+	}{
+		{name: "ok", method: "/pb.RawFileSystem/GetAttr", want: codes.OK, calls: 1},
+		{name: "retried", method: "/pb.RawFileSystem/GetAttr", failures: 2, code: codes.Unavailable, want: codes.OK, calls: 3},
+		{name: "too many failures", method: "/pb.RawFileSystem/Read", failures: 3, code: codes.Unavailable, want: codes.Unavailable, calls: 3},
+		{name: "other code", method: "/pb.RawFileSystem/GetAttr", failures: 1, code: codes.Internal, want: codes.Internal, calls: 1},
+		{name: "not retryable", method: "/pb.RawFileSystem/Mkdir", failures: 1, code: codes.Unavailable, want: codes.Unavailable, calls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				calls++
+				if calls <= tt.failures {
+					return status.Error(tt.code, "failed")
+				}
+				return nil
+			}
+			err := m.unaryRetryInterceptor(context.Background(), tt.method, nil, nil, nil, invoker)
+			assert.Equal(t, tt.want, status.Code(err))
+			assert.Equal(t, tt.calls, calls)
+		})
+	}
+	assert.Equal(t, MethodStats{Retries: 2}, m.Stats().Methods["GetAttr"])
+	assert.Equal(t, MethodStats{Retries: 2}, m.Stats().Methods["Read"])
+}
+
+// serve serves memfs as export "a" and returns the options to dial it.
+func serve(t *testing.T) []grpc.DialOption {
+	l := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	r := exports.New()
+	r.Set(map[string]pb.RawFileSystemServer{"a": fuse2grpc.NewServer(memfs.New(nil, nil))}, "")
+	r.Register(s)
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+	return []grpc.DialOption{grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return l.DialContext(ctx) })}
+}
+
+func TestMountErrors(t *testing.T) {
+	dialOpts := serve(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := Mount(ctx, t.TempDir(), "bufconn", &MountOptions{Export: "nope", DialOptions: dialOpts})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "handshake")
+
+	_, err = Mount(ctx, filepath.Join(t.TempDir(), "nonexistent"), "bufconn", &MountOptions{Export: "a", DialOptions: dialOpts})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "mount")
+
+	short, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = Mount(short, t.TempDir(), "unix:"+filepath.Join(t.TempDir(), "nope"), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "handshake")
+
+	_, err = Mount(ctx, t.TempDir(), "bufconn", &MountOptions{SharedMemory: true, DialOptions: dialOpts})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "needs a unix: target")
+	_, err = Mount(ctx, t.TempDir(), "unix:"+filepath.Join(t.TempDir(), "nope"), &MountOptions{SharedMemory: true})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "shm")
+	_, err = Mount(ctx, t.TempDir(), "bufconn", &MountOptions{Offline: true, DialOptions: dialOpts})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cache directory")
+}
+
+func TestUnixSocket(t *testing.T) {
+	tests := []struct {
+		target, path string
+		ok           bool
+	}{
+		{target: "unix:/run/sock", path: "/run/sock", ok: true},
+		{target: "unix:///run/sock", path: "/run/sock", ok: true},
+		{target: "unix:sock", path: "sock", ok: true},
+		{target: "127.0.0.1:8760"},
+	}
+	for _, tt := range tests {
+		path, ok := unixSocket(tt.target)
+		assert.Equal(t, tt.ok, ok, tt.target)
+		assert.Equal(t, tt.path, path, tt.target)
+	}
+}
+
+func TestMount(t *testing.T) {
+	dialOpts := serve(t)
+	mnt := t.TempDir()
+	m, err := Mount(context.Background(), mnt, "bufconn", &MountOptions{Export: "a", DialOptions: dialOpts})
+	if err != nil {
+		t.Skipf("cannot mount: %v", err)
+	}
+	unmounted := false
+	defer func() {
+		if !unmounted {
+			m.Unmount()
+		}
+	}()
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(mnt, "file"), []byte("hello"), 0644))
+	data, err := ioutil.ReadFile(filepath.Join(mnt, "file"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+	_, err = os.Stat(filepath.Join(mnt, "nope"))
+	assert.ErrorIs(t, err, syscall.ENOENT)
+	stats := m.Stats()
+	assert.Equal(t, mnt, stats.Mountpoint)
+	assert.NotZero(t, stats.Methods["Create"].Calls)
+
+	require.NoError(t, m.Unmount())
+	unmounted = true
+	m.Wait()
+}
+
+func TestMountConnections(t *testing.T) {
+	dialOpts := serve(t)
+	mnt := t.TempDir()
+	m, err := Mount(context.Background(), mnt, "bufconn", &MountOptions{Export: "a", DialOptions: dialOpts, Connections: 4, StripeSize: 64 << 10, ReadAhead: 4 << 20, WriteBehind: 8 << 20, CacheDir: t.TempDir()})
+	if err != nil {
+		t.Skipf("cannot mount: %v", err)
+	}
+	defer m.Unmount()
+	assert.Len(t, m.conns, 5)
+
+	data := make([]byte, 4<<20+100)
+	rand.Read(data)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(mnt, "file"), data, 0644))
+	got, err := ioutil.ReadFile(filepath.Join(mnt, "file"))
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(data, got))
+	assert.Greater(t, m.Stats().Methods["Write"].Calls, uint64(4<<20/(64<<10)))
+	assert.Greater(t, m.Stats().Cache.Bytes, int64(0))
+}
+
+func TestMountOffline(t *testing.T) {
+	var server outage
+	opts := &MountOptions{Export: "a", DialOptions: append(serve(t), server.dialOptions()...), CacheDir: t.TempDir(), Offline: true}
+	mnt := t.TempDir()
+	m, err := Mount(context.Background(), mnt, "bufconn", opts)
+	if err != nil {
+		t.Skipf("cannot mount: %v", err)
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(mnt, "d"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(mnt, "d", "f"), []byte("hello"), 0644))
+	read := func() {
+		_, err := os.Stat(mnt)
+		require.NoError(t, err)
+		infos, err := ioutil.ReadDir(filepath.Join(mnt, "d"))
+		require.NoError(t, err)
+		require.Len(t, infos, 1)
+		assert.Equal(t, "f", infos[0].Name())
+		data, err := ioutil.ReadFile(filepath.Join(mnt, "d", "f"))
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(data))
+	}
+	read()
+	assert.False(t, m.Stats().Offline)
+	require.NoError(t, m.Unmount())
+
+	// Mounted again while the server is unreachable, the mount answers
+	// from what the last one kept.
+	server.set(true)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	m, err = Mount(ctx, mnt, "bufconn", opts)
+	require.NoError(t, err)
+	defer m.Unmount()
+	assert.True(t, m.Stats().Offline)
+	read()
+	err = os.Mkdir(filepath.Join(mnt, "e"), 0755)
+	assert.True(t, errors.Is(err, syscall.EROFS), "%v", err)
+}
+
+func TestMountJournal(t *testing.T) {
+	var server outage
+	opts := &MountOptions{Export: "a", DialOptions: append(serve(t), server.dialOptions()...), CacheDir: t.TempDir(), Offline: true, Journal: true}
+	mnt := t.TempDir()
+	m, err := Mount(context.Background(), mnt, "bufconn", opts)
+	if err != nil {
+		t.Skipf("cannot mount: %v", err)
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(mnt, "d"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(mnt, "d", "f"), []byte("hello"), 0644))
+	_, err = ioutil.ReadFile(filepath.Join(mnt, "d", "f"))
+	require.NoError(t, err)
+	_, err = ioutil.ReadDir(filepath.Join(mnt, "d"))
+	require.NoError(t, err)
+	require.NoError(t, m.Unmount())
+
+	// Changed offline, and unmounted before the server is back.
+	server.set(true)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	m, err = Mount(ctx, mnt, "bufconn", opts)
+	require.NoError(t, err)
+	require.True(t, m.Stats().Offline)
+	f, err := os.OpenFile(filepath.Join(mnt, "d", "f"), os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.Write([]byte(" world"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	require.NoError(t, ioutil.WriteFile(filepath.Join(mnt, "d", "new"), []byte("new"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(mnt, "e"), 0755))
+	require.NoError(t, os.Rename(filepath.Join(mnt, "d", "new"), filepath.Join(mnt, "e", "new")))
+	data, err := ioutil.ReadFile(filepath.Join(mnt, "d", "f"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(data))
+	require.NoError(t, m.Unmount())
+
+	// Mounted online, the journal is replayed first.
+	server.set(false)
+	m, err = Mount(ctx, mnt, "bufconn", opts)
+	require.NoError(t, err)
+	defer m.Unmount()
+	assert.False(t, m.Stats().Offline)
+	data, err = ioutil.ReadFile(filepath.Join(mnt, "d", "f"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(data))
+	data, err = ioutil.ReadFile(filepath.Join(mnt, "e", "new"))
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+	j, err := journal.OpenReadOnly(JournalDir(opts.CacheDir, "bufconn", "a"))
+	require.NoError(t, err)
+	assert.Empty(t, j.Pending())
+}
+
+func TestMountAttrCache(t *testing.T) {
+	opts := &MountOptions{Export: "a", DialOptions: serve(t), AttrCache: AttrCacheOptions{AttrTimeout: time.Minute, EntryTimeout: time.Minute, NegativeTimeout: time.Minute}}
+	mnt := t.TempDir()
+	m, err := Mount(context.Background(), mnt, "bufconn", opts)
+	if err != nil {
+		t.Skipf("cannot mount: %v", err)
+	}
+	defer m.Unmount()
+
+	// The kernel asks each time, as NegativeTimeout is 0.
+	for i := 0; i < 3; i++ {
+		_, err := os.Stat(filepath.Join(mnt, "nope"))
+		assert.True(t, os.IsNotExist(err), "%v", err)
+	}
+	assert.Equal(t, uint64(1), m.Stats().Methods["Lookup"].Calls)
+	assert.GreaterOrEqual(t, m.Stats().AttrCache.Hits, uint64(2))
+
+	// Made by someone else, the name shows once invalidated.
+	conn, err := grpc.Dial("bufconn", m.opts.dialOptions()...)
+	require.NoError(t, err)
+	defer conn.Close()
+	var out fuse.EntryOut
+	require.Equal(t, fuse.OK, NewFileSystem(pb.NewRawFileSystemClient(conn)).Mkdir(nil, &fuse.MkdirIn{InHeader: fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}, Mode: 0755}, "nope", &out))
+	_, err = os.Stat(filepath.Join(mnt, "nope"))
+	assert.True(t, os.IsNotExist(err), "%v", err)
+	require.NoError(t, m.InvalidateEntry(fuse.FUSE_ROOT_ID, "nope"))
+	_, err = os.Stat(filepath.Join(mnt, "nope"))
+	assert.NoError(t, err)
+}
+
+func TestMountSharedMemory(t *testing.T) {
+	dir := t.TempDir()
+	sock := filepath.Join(dir, "sock")
+	shmServer := shm.NewServer()
+	sl, err := net.Listen("unix", sock+".shm")
+	require.NoError(t, err)
+	go shmServer.Serve(sl)
+	l, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(shmServer.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(shmServer.StreamServerInterceptor()))
+	pb.RegisterRawFileSystemServer(s, fuse2grpc.NewServer(memfs.New(nil, nil)))
+	go s.Serve(l)
+	defer func() {
+		s.Stop()
+		sl.Close()
+		shmServer.Close()
+	}()
+
+	mnt := t.TempDir()
+	m, err := Mount(context.Background(), mnt, "unix:"+sock, &MountOptions{SharedMemory: true, MaxWrite: 128 << 10})
+	if err != nil {
+		t.Skipf("cannot mount: %v", err)
+	}
+	defer m.Unmount()
+
+	data := make([]byte, 1<<20)
+	rand.Read(data)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(mnt, "file"), data, 0644))
+	got, err := ioutil.ReadFile(filepath.Join(mnt, "file"))
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(data, got))
+	assert.Greater(t, m.Stats().SharedMemory.Shared, int64(0))
+}
@@ -0,0 +1,1062 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc2fuse
+
+import (
+	"context"
+	"io"
+	"path"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/chiyutianyi/grpcfuse/journal"
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+const (
+	// ModeXAttr is the extended attribute of every file of a mount with
+	// Offline set, which reads "online" or "offline".
+	ModeXAttr = "user.grpcfuse.mode"
+
+	// localID marks the node IDs and handles offlineFS hands out itself,
+	// which the server never sees; the server counts from 1.
+	localID = 1 << 62
+	// offlineTimeout is how long the kernel may keep what is answered
+	// from the cache, so that it asks again soon once the server is back.
+	offlineTimeout = time.Second
+	// probeInterval is how often an offline mount checks for the server.
+	probeInterval = 5 * time.Second
+)
+
+// probeKey marks the context of the calls that check for the server,
+// which are made while offline.
+type probeKey struct{}
+
+// offlineFS answers from what it cached while the server is unreachable:
+// names, attributes, listings and symlinks from a metaCache, and data
+// from the disk cache. Changes fail with EROFS, or, with a journal, are
+// made to the cache and journaled, to be replayed once the server is
+// back; files written get data of their own in the journal.
+//
+// Names first looked up while offline get node IDs of their own, which
+// are never sent to the server, as it may not know the node any more.
+// Once the server is back the kernel is told to drop them; until it does
+// they are answered from the cache, read-only.
+type offlineFS struct {
+	fuse.RawFileSystem
+	fs    *fileSystem
+	meta  *metaCache
+	probe func(ctx context.Context) error
+	// interval is how often the server is probed while offline.
+	interval time.Duration
+
+	mu sync.Mutex
+	// offline is set while answering from the cache and unreachable
+	// while calls fail at once; only the latter is cleared while what
+	// was put off is sent on reconnecting.
+	offline, unreachable bool
+	closed               bool
+	server               *fuse.Server
+	stop                 chan struct{}
+	nodes                map[uint64]*offlineNode
+	ids                  map[string]uint64
+	dirs                 map[uint64]*dirListing
+	pending              []func()
+	statfs               fuse.StatfsOut
+	next                 uint64
+	files                map[uint64]*localFile
+	// writers are the files in files open for writing, which keep the
+	// mount offline.
+	writers int
+
+	journal   *journal.Journal
+	conflicts ConflictPolicy
+	// jmu orders the changes made offline and their replay.
+	jmu sync.Mutex
+}
+
+// offlineNode is a node the kernel knows.
+type offlineNode struct {
+	path    string
+	lookups uint64
+	// lent are the lookups answered from the cache, which the server
+	// never counted.
+	lent uint64
+}
+
+// dirListing is a listing of a directory being read from the server.
+type dirListing struct {
+	path    string
+	entries []metaEntry
+}
+
+func newOfflineFS(meta *metaCache) *offlineFS {
+	return &offlineFS{
+		meta:     meta,
+		interval: probeInterval,
+		stop:     make(chan struct{}),
+		nodes:    map[uint64]*offlineNode{fuse.FUSE_ROOT_ID: {path: "/"}},
+		ids:      map[string]uint64{"/": fuse.FUSE_ROOT_ID},
+		dirs:     map[uint64]*dirListing{},
+		files:    map[uint64]*localFile{},
+	}
+}
+
+func (o *offlineFS) isOffline() bool {
+	if o == nil {
+		return false
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.offline
+}
+
+func (o *offlineFS) mode() string {
+	if o.isOffline() {
+		return "offline"
+	}
+	return "online"
+}
+
+// serves reports whether calls on ids are answered from the cache: all
+// of them while offline, and those on IDs handed out offline always.
+func (o *offlineFS) serves(ids ...uint64) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.offline {
+		return true
+	}
+	for _, id := range ids {
+		if id&localID != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// failed takes the mount offline when err says the server is
+// unreachable.
+func (o *offlineFS) failed(err error) {
+	if status.Code(err) != codes.Unavailable {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.unreachable || o.closed {
+		return
+	}
+	log.Warnf("Server unreachable, answering from the cache: %v", err)
+	o.offline, o.unreachable = true, true
+	go o.reconnect()
+}
+
+// check fails calls at once while the server is unreachable, but for
+// probes.
+func (o *offlineFS) check(ctx context.Context) error {
+	if ctx.Value(probeKey{}) != nil {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.unreachable {
+		return status.Error(codes.Unavailable, "offline")
+	}
+	return nil
+}
+
+func (o *offlineFS) unaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if err := o.check(ctx); err != nil {
+		return err
+	}
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	o.failed(err)
+	return err
+}
+
+func (o *offlineFS) streamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	if err := o.check(ctx); err != nil {
+		return nil, err
+	}
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	o.failed(err)
+	return stream, err
+}
+
+// reconnect probes the server until it answers, sends what was put off
+// and goes back online.
+func (o *offlineFS) reconnect() {
+	if !o.waitForServer() {
+		return
+	}
+	o.mu.Lock()
+	o.unreachable = false
+	pending := o.pending
+	o.pending = nil
+	o.mu.Unlock()
+	for _, f := range pending {
+		f()
+	}
+	// The server may have changed while unreachable.
+	o.fs.InvalidateAll()
+
+	// The changes made offline go first, once the files written are
+	// closed. No more are made until online.
+	o.jmu.Lock()
+	for o.writing() {
+		o.jmu.Unlock()
+		select {
+		case <-o.stop:
+			return
+		case <-time.After(o.interval):
+		}
+		o.jmu.Lock()
+	}
+	if err := o.replay(); err != nil {
+		o.jmu.Unlock()
+		log.Warnf("Replaying the changes made offline: %v", err)
+		return
+	}
+
+	type name struct {
+		parent uint64
+		name   string
+	}
+	var local []name
+	o.mu.Lock()
+	if o.unreachable || o.closed {
+		// Gone again; the next reconnect takes over.
+		o.mu.Unlock()
+		o.jmu.Unlock()
+		return
+	}
+	o.offline = false
+	for id, n := range o.nodes {
+		if parent, ok := o.ids[path.Dir(n.path)]; ok && id&localID != 0 {
+			local = append(local, name{parent, path.Base(n.path)})
+		}
+	}
+	server := o.server
+	o.mu.Unlock()
+	o.jmu.Unlock()
+	log.Infof("Server reachable again")
+	if server == nil {
+		return
+	}
+	for _, n := range local {
+		if st := server.EntryNotify(n.parent, n.name); st != fuse.OK {
+			log.Debugf("Dropping %s from the kernel: %v", n.name, st)
+		}
+	}
+}
+
+// waitForServer probes the server until it answers, or the mount is
+// closed.
+func (o *offlineFS) waitForServer() bool {
+	t := time.NewTicker(o.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-o.stop:
+			return false
+		case <-t.C:
+		}
+		ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), probeKey{}, true), o.interval)
+		err := o.probe(ctx)
+		cancel()
+		if err == nil {
+			return true
+		}
+		log.Debugf("Server still unreachable: %v", err)
+	}
+}
+
+func (o *offlineFS) writing() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.writers > 0
+}
+
+// reachable reports whether calls are sent to the server.
+func (o *offlineFS) reachable() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return !o.unreachable
+}
+
+// putOff keeps f for when the server is back, if it is unreachable.
+func (o *offlineFS) putOff(f func()) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !o.unreachable {
+		return false
+	}
+	o.pending = append(o.pending, f)
+	return true
+}
+
+// Init keeps the server, to tell the kernel to drop what it looked up
+// offline.
+func (o *offlineFS) Init(server *fuse.Server) {
+	o.mu.Lock()
+	o.server = server
+	o.mu.Unlock()
+	o.RawFileSystem.Init(server)
+}
+
+func (o *offlineFS) close() {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	if o.closed {
+		o.mu.Unlock()
+		return
+	}
+	o.closed = true
+	close(o.stop)
+	o.mu.Unlock()
+	if o.journal == nil {
+		return
+	}
+	// After a replay in flight, for the next mount to open it.
+	o.jmu.Lock()
+	defer o.jmu.Unlock()
+	if err := o.journal.Close(); err != nil {
+		log.Errorf("grpc2fuse: %v", err)
+	}
+}
+
+// pathOf returns the path of node; o.mu is held.
+func (o *offlineFS) pathOf(node uint64) (string, bool) {
+	n, ok := o.nodes[node]
+	if !ok || n.path == "" {
+		return "", false
+	}
+	return n.path, true
+}
+
+func (o *offlineFS) nodePath(node uint64) (string, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.pathOf(node)
+}
+
+// record returns what is cached of node.
+func (o *offlineFS) record(node uint64) (metaRecord, fuse.Status) {
+	p, ok := o.nodePath(node)
+	if !ok {
+		return metaRecord{}, fuse.EIO
+	}
+	r, ok := o.meta.get(p)
+	if !ok || r.Attr.Mode == 0 {
+		return metaRecord{}, fuse.EIO
+	}
+	return r, fuse.OK
+}
+
+// handle returns a new handle of a file or directory opened offline.
+func (o *offlineFS) handle() uint64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.next++
+	return localID | o.next
+}
+
+// setAttr keeps the attributes of p; a new inode starts a new record.
+func (o *offlineFS) setAttr(p string, attr *fuse.Attr) {
+	o.meta.update(p, func(r *metaRecord) {
+		if r.Attr.Mode != 0 && r.Attr.Ino != attr.Ino {
+			*r = metaRecord{Path: r.Path}
+		}
+		r.Attr = *attr
+	})
+}
+
+// entered counts the lookup of out, found as name in parent, that the
+// kernel got from the server, and keeps its attributes. A lookup without
+// a node is a name that does not exist.
+func (o *offlineFS) entered(parent uint64, name string, out *fuse.EntryOut) {
+	o.mu.Lock()
+	dir, ok := o.pathOf(parent)
+	p := path.Join(dir, name)
+	if out.NodeId != 0 {
+		n := o.nodes[out.NodeId]
+		if n == nil {
+			n = &offlineNode{}
+			o.nodes[out.NodeId] = n
+		}
+		n.lookups++
+		if ok {
+			n.path = p
+			o.ids[p] = out.NodeId
+		}
+	}
+	o.mu.Unlock()
+	if !ok || name == "." || name == ".." {
+		return
+	}
+	if out.NodeId == 0 {
+		o.meta.remove(p)
+		return
+	}
+	o.setAttr(p, &out.Attr)
+}
+
+// changedDir drops the listing of dir after a name in it changed.
+func (o *offlineFS) changedDir(dir uint64) {
+	if p, ok := o.nodePath(dir); ok {
+		o.meta.unlist(p)
+	}
+}
+
+// removed drops what is kept of name in dir.
+func (o *offlineFS) removed(dir uint64, name string) {
+	if p, ok := o.nodePath(dir); ok {
+		o.meta.remove(path.Join(p, name))
+		o.meta.unlist(p)
+	}
+}
+
+// renamed moves the nodes below oldPath to newPath; what is kept of both
+// is dropped.
+func (o *offlineFS) renamed(oldPath, newPath string) {
+	o.meta.remove(oldPath)
+	o.meta.remove(newPath)
+	o.moved(oldPath, newPath)
+}
+
+// moved moves the nodes below oldPath to newPath.
+func (o *offlineFS) moved(oldPath, newPath string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for id, n := range o.nodes {
+		if n.path == oldPath || strings.HasPrefix(n.path, oldPath+"/") {
+			if o.ids[n.path] == id {
+				delete(o.ids, n.path)
+			}
+			n.path = newPath + strings.TrimPrefix(n.path, oldPath)
+			o.ids[n.path] = id
+		}
+	}
+}
+
+// listed keeps the entries of a listing read from the server, and the
+// attributes and lookups that came with READDIRPLUS. A listing read from
+// the start to its end is kept as the listing of its directory.
+func (o *offlineFS) listed(in *fuse.ReadIn, entries []*pb.DirEntry) {
+	for _, e := range entries {
+		if e.EntryOut.GetNodeId() != 0 {
+			var out fuse.EntryOut
+			toFuseEntryOut(&out, e.EntryOut)
+			o.entered(in.NodeId, string(e.Name), &out)
+		}
+	}
+
+	o.mu.Lock()
+	l := o.dirs[in.Fh]
+	if in.Offset == 0 {
+		l = nil
+		if p, ok := o.pathOf(in.NodeId); ok {
+			l = &dirListing{path: p}
+		}
+	}
+	if l == nil || in.Offset != uint64(len(l.entries)) {
+		// Not read from the start, or not in order.
+		delete(o.dirs, in.Fh)
+		o.mu.Unlock()
+		return
+	}
+	for _, e := range entries {
+		l.entries = append(l.entries, metaEntry{Name: string(e.Name), Ino: e.Ino, Mode: e.Mode})
+	}
+	o.dirs[in.Fh] = l
+	if len(entries) > 0 {
+		o.mu.Unlock()
+		return
+	}
+	delete(o.dirs, in.Fh)
+	o.mu.Unlock()
+	o.meta.update(l.path, func(r *metaRecord) {
+		r.Listed, r.Entries = true, l.entries
+	})
+}
+
+func (o *offlineFS) Lookup(cancel <-chan struct{}, header *fuse.InHeader, name string, out *fuse.EntryOut) fuse.Status {
+	if o.serves(header.NodeId) {
+		return o.lookup(header.NodeId, name, out)
+	}
+	st := o.RawFileSystem.Lookup(cancel, header, name, out)
+	switch {
+	case st == fuse.OK:
+		o.entered(header.NodeId, name, out)
+	case st == fuse.ENOENT:
+		o.entered(header.NodeId, name, &fuse.EntryOut{})
+	case st == fuse.EIO && o.serves(header.NodeId):
+		return o.lookup(header.NodeId, name, out)
+	}
+	return st
+}
+
+// lookup answers a lookup from the cache, with the node ID the kernel
+// already has for the name, or a new one of its own.
+func (o *offlineFS) lookup(parent uint64, name string, out *fuse.EntryOut) fuse.Status {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	dir, ok := o.pathOf(parent)
+	if !ok {
+		return fuse.EIO
+	}
+	p := path.Join(dir, name)
+	r, ok := o.meta.get(p)
+	if !ok || r.Attr.Mode == 0 {
+		// Not known to exist, which is as good as not existing while
+		// nothing can be made.
+		return fuse.ENOENT
+	}
+	id, ok := o.ids[p]
+	if !ok {
+		o.next++
+		id = localID | o.next
+		o.nodes[id] = &offlineNode{path: p}
+		o.ids[p] = id
+	}
+	n := o.nodes[id]
+	n.lookups++
+	if id&localID == 0 {
+		n.lent++
+	}
+	*out = fuse.EntryOut{NodeId: id, Attr: r.Attr}
+	out.SetEntryTimeout(offlineTimeout)
+	out.SetAttrTimeout(offlineTimeout)
+	return fuse.OK
+}
+
+// Forget passes on the lookups the server counted, once it can.
+func (o *offlineFS) Forget(nodeid, nlookup uint64) {
+	o.mu.Lock()
+	if n := o.nodes[nodeid]; n != nil && nodeid != fuse.FUSE_ROOT_ID {
+		if nlookup > n.lookups {
+			nlookup = n.lookups
+		}
+		n.lookups -= nlookup
+		lent := n.lent
+		if lent > nlookup {
+			lent = nlookup
+		}
+		n.lent -= lent
+		nlookup -= lent
+		if n.lookups == 0 {
+			delete(o.nodes, nodeid)
+			if o.ids[n.path] == nodeid {
+				delete(o.ids, n.path)
+			}
+		}
+	}
+	o.mu.Unlock()
+	if nlookup == 0 || nodeid&localID != 0 {
+		return
+	}
+	if o.putOff(func() { o.RawFileSystem.Forget(nodeid, nlookup) }) {
+		return
+	}
+	o.RawFileSystem.Forget(nodeid, nlookup)
+}
+
+func (o *offlineFS) GetAttr(cancel <-chan struct{}, in *fuse.GetAttrIn, out *fuse.AttrOut) fuse.Status {
+	if o.serves(in.NodeId) {
+		return o.getAttr(in.NodeId, out)
+	}
+	st := o.RawFileSystem.GetAttr(cancel, in, out)
+	if st == fuse.OK {
+		if p, ok := o.nodePath(in.NodeId); ok {
+			o.setAttr(p, &out.Attr)
+		}
+	}
+	if st == fuse.EIO && o.serves(in.NodeId) {
+		return o.getAttr(in.NodeId, out)
+	}
+	return st
+}
+
+func (o *offlineFS) getAttr(node uint64, out *fuse.AttrOut) fuse.Status {
+	r, st := o.record(node)
+	if st != fuse.OK {
+		return st
+	}
+	out.Attr = r.Attr
+	out.SetTimeout(offlineTimeout)
+	return fuse.OK
+}
+
+func (o *offlineFS) SetAttr(cancel <-chan struct{}, in *fuse.SetAttrIn, out *fuse.AttrOut) fuse.Status {
+	ids := []uint64{in.NodeId}
+	if in.Valid&fuse.FATTR_FH != 0 {
+		ids = append(ids, in.Fh)
+	}
+	if o.serves(ids...) {
+		return o.change(func() fuse.Status { return o.setAttrLocal(in, out) })
+	}
+	st := o.RawFileSystem.SetAttr(cancel, in, out)
+	if st == fuse.OK {
+		if p, ok := o.nodePath(in.NodeId); ok {
+			o.setAttr(p, &out.Attr)
+		}
+	}
+	return st
+}
+
+func (o *offlineFS) Readlink(cancel <-chan struct{}, header *fuse.InHeader) ([]byte, fuse.Status) {
+	if o.serves(header.NodeId) {
+		return o.readlink(header.NodeId)
+	}
+	target, st := o.RawFileSystem.Readlink(cancel, header)
+	if st == fuse.OK {
+		if p, ok := o.nodePath(header.NodeId); ok {
+			o.meta.update(p, func(r *metaRecord) { r.Target = string(target) })
+		}
+	}
+	if st == fuse.EIO && o.serves(header.NodeId) {
+		return o.readlink(header.NodeId)
+	}
+	return target, st
+}
+
+func (o *offlineFS) readlink(node uint64) ([]byte, fuse.Status) {
+	r, st := o.record(node)
+	if st != fuse.OK {
+		return nil, st
+	}
+	if r.Target == "" {
+		return nil, fuse.EIO
+	}
+	return []byte(r.Target), fuse.OK
+}
+
+func (o *offlineFS) Access(cancel <-chan struct{}, in *fuse.AccessIn) fuse.Status {
+	if !o.serves(in.NodeId) {
+		return o.RawFileSystem.Access(cancel, in)
+	}
+	if in.Mask&2 != 0 && !o.journals() { // W_OK
+		return fuse.EROFS
+	}
+	_, st := o.record(in.NodeId)
+	return st
+}
+
+func (o *offlineFS) Mknod(cancel <-chan struct{}, in *fuse.MknodIn, name string, out *fuse.EntryOut) fuse.Status {
+	if o.serves(in.NodeId) {
+		return fuse.EROFS
+	}
+	st := o.RawFileSystem.Mknod(cancel, in, name, out)
+	if st == fuse.OK {
+		o.entered(in.NodeId, name, out)
+		o.changedDir(in.NodeId)
+	}
+	return st
+}
+
+func (o *offlineFS) Mkdir(cancel <-chan struct{}, in *fuse.MkdirIn, name string, out *fuse.EntryOut) fuse.Status {
+	if o.serves(in.NodeId) {
+		return o.change(func() fuse.Status { return o.mkdir(in, name, out) })
+	}
+	st := o.RawFileSystem.Mkdir(cancel, in, name, out)
+	if st == fuse.OK {
+		o.entered(in.NodeId, name, out)
+		o.changedDir(in.NodeId)
+	}
+	return st
+}
+
+func (o *offlineFS) Unlink(cancel <-chan struct{}, header *fuse.InHeader, name string) fuse.Status {
+	if o.serves(header.NodeId) {
+		return o.change(func() fuse.Status { return o.remove(header, name, false) })
+	}
+	st := o.RawFileSystem.Unlink(cancel, header, name)
+	if st == fuse.OK {
+		o.removed(header.NodeId, name)
+	}
+	return st
+}
+
+func (o *offlineFS) Rmdir(cancel <-chan struct{}, header *fuse.InHeader, name string) fuse.Status {
+	if o.serves(header.NodeId) {
+		return o.change(func() fuse.Status { return o.remove(header, name, true) })
+	}
+	st := o.RawFileSystem.Rmdir(cancel, header, name)
+	if st == fuse.OK {
+		o.removed(header.NodeId, name)
+	}
+	return st
+}
+
+func (o *offlineFS) Rename(cancel <-chan struct{}, in *fuse.RenameIn, oldName string, newName string) fuse.Status {
+	if o.serves(in.NodeId, in.Newdir) {
+		return o.change(func() fuse.Status { return o.rename(in, oldName, newName) })
+	}
+	st := o.RawFileSystem.Rename(cancel, in, oldName, newName)
+	if st != fuse.OK {
+		return st
+	}
+	oldDir, ok1 := o.nodePath(in.NodeId)
+	newDir, ok2 := o.nodePath(in.Newdir)
+	if ok1 && ok2 {
+		o.renamed(path.Join(oldDir, oldName), path.Join(newDir, newName))
+	}
+	o.changedDir(in.NodeId)
+	o.changedDir(in.Newdir)
+	return st
+}
+
+func (o *offlineFS) Link(cancel <-chan struct{}, in *fuse.LinkIn, filename string, out *fuse.EntryOut) fuse.Status {
+	if o.serves(in.NodeId, in.Oldnodeid) {
+		return fuse.EROFS
+	}
+	st := o.RawFileSystem.Link(cancel, in, filename, out)
+	if st == fuse.OK {
+		o.entered(in.NodeId, filename, out)
+		o.changedDir(in.NodeId)
+	}
+	return st
+}
+
+func (o *offlineFS) Symlink(cancel <-chan struct{}, header *fuse.InHeader, pointedTo string, linkName string, out *fuse.EntryOut) fuse.Status {
+	if o.serves(header.NodeId) {
+		return o.change(func() fuse.Status { return o.symlink(header, pointedTo, linkName, out) })
+	}
+	st := o.RawFileSystem.Symlink(cancel, header, pointedTo, linkName, out)
+	if st == fuse.OK {
+		o.entered(header.NodeId, linkName, out)
+		o.changedDir(header.NodeId)
+	}
+	return st
+}
+
+func (o *offlineFS) GetXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string, dest []byte) (uint32, fuse.Status) {
+	if attr == ModeXAttr {
+		mode := o.mode()
+		return copyXAttrValue(dest, uint32(len(mode)), []byte(mode), fuse.OK)
+	}
+	if o.serves(header.NodeId) {
+		return 0, fuse.ENOATTR
+	}
+	return o.RawFileSystem.GetXAttr(cancel, header, attr, dest)
+}
+
+func (o *offlineFS) ListXAttr(cancel <-chan struct{}, header *fuse.InHeader, dest []byte) (uint32, fuse.Status) {
+	if o.serves(header.NodeId) {
+		return 0, fuse.OK
+	}
+	return o.RawFileSystem.ListXAttr(cancel, header, dest)
+}
+
+func (o *offlineFS) SetXAttr(cancel <-chan struct{}, in *fuse.SetXAttrIn, attr string, data []byte) fuse.Status {
+	if o.serves(in.NodeId) {
+		return fuse.EROFS
+	}
+	return o.RawFileSystem.SetXAttr(cancel, in, attr, data)
+}
+
+func (o *offlineFS) RemoveXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string) fuse.Status {
+	if o.serves(header.NodeId) {
+		return fuse.EROFS
+	}
+	return o.RawFileSystem.RemoveXAttr(cancel, header, attr)
+}
+
+func (o *offlineFS) Create(cancel <-chan struct{}, in *fuse.CreateIn, name string, out *fuse.CreateOut) fuse.Status {
+	if o.serves(in.NodeId) {
+		return o.change(func() fuse.Status { return o.create(in, name, out) })
+	}
+	st := o.RawFileSystem.Create(cancel, in, name, out)
+	if st == fuse.OK {
+		o.entered(in.NodeId, name, &out.EntryOut)
+		o.changedDir(in.NodeId)
+	}
+	return st
+}
+
+func (o *offlineFS) Open(cancel <-chan struct{}, in *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
+	if o.serves(in.NodeId) {
+		return o.open(in, out)
+	}
+	st := o.RawFileSystem.Open(cancel, in, out)
+	if st == fuse.EIO && o.serves(in.NodeId) {
+		return o.open(in, out)
+	}
+	return st
+}
+
+// open opens a file for reading from the disk cache, as the version
+// last seen, or from its data in the journal once written offline.
+func (o *offlineFS) open(in *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
+	if in.Flags&syscall.O_ACCMODE != syscall.O_RDONLY || in.Flags&syscall.O_TRUNC != 0 {
+		return o.change(func() fuse.Status { return o.openWrite(in, out) })
+	}
+	r, st := o.record(in.NodeId)
+	if st != fuse.OK {
+		return st
+	}
+	if r.Data != "" && o.journal != nil {
+		out.Fh, st = o.openFile(r.Data, in.NodeId, false)
+		return st
+	}
+	if o.fs.diskCache == nil {
+		return fuse.EIO
+	}
+	out.Fh = o.handle()
+	o.fs.diskCache.open(out.Fh, in.NodeId, cacheKey(&r.Attr))
+	return fuse.OK
+}
+
+func (o *offlineFS) Read(cancel <-chan struct{}, in *fuse.ReadIn, buf []byte) (fuse.ReadResult, fuse.Status) {
+	if lf := o.file(in.Fh); lf != nil {
+		data := make([]byte, in.Size)
+		n, err := lf.f.ReadAt(data, int64(in.Offset))
+		if err != nil && err != io.EOF {
+			return nil, fuse.ToStatus(err)
+		}
+		return fuse.ReadResultData(data[:n]), fuse.OK
+	}
+	if !o.serves(in.NodeId, in.Fh) {
+		res, st := o.RawFileSystem.Read(cancel, in, buf)
+		if st != fuse.EIO || !o.serves(in.NodeId) {
+			return res, st
+		}
+	}
+	data, st, cached := o.fs.cacheRead(newContext(cancel), in, false)
+	if !cached {
+		return nil, fuse.EIO
+	}
+	if st != fuse.OK {
+		return nil, st
+	}
+	return fuse.ReadResultData(data), fuse.OK
+}
+
+func (o *offlineFS) Lseek(cancel <-chan struct{}, in *fuse.LseekIn, out *fuse.LseekOut) fuse.Status {
+	if !o.serves(in.NodeId, in.Fh) {
+		return o.RawFileSystem.Lseek(cancel, in, out)
+	}
+	r, st := o.record(in.NodeId)
+	if st != fuse.OK {
+		return st
+	}
+	if in.Offset >= r.Attr.Size {
+		return fuse.Status(syscall.ENXIO)
+	}
+	switch in.Whence {
+	case unix.SEEK_DATA:
+		out.Offset = in.Offset
+	case unix.SEEK_HOLE:
+		out.Offset = r.Attr.Size
+	default:
+		return fuse.EINVAL
+	}
+	return fuse.OK
+}
+
+func (o *offlineFS) GetLk(cancel <-chan struct{}, in *fuse.LkIn, out *fuse.LkOut) fuse.Status {
+	if o.serves(in.NodeId, in.Fh) {
+		return fuse.Status(syscall.ENOLCK)
+	}
+	return o.RawFileSystem.GetLk(cancel, in, out)
+}
+
+func (o *offlineFS) SetLk(cancel <-chan struct{}, in *fuse.LkIn) fuse.Status {
+	if o.serves(in.NodeId, in.Fh) {
+		return fuse.Status(syscall.ENOLCK)
+	}
+	return o.RawFileSystem.SetLk(cancel, in)
+}
+
+func (o *offlineFS) SetLkw(cancel <-chan struct{}, in *fuse.LkIn) fuse.Status {
+	if o.serves(in.NodeId, in.Fh) {
+		return fuse.Status(syscall.ENOLCK)
+	}
+	return o.RawFileSystem.SetLkw(cancel, in)
+}
+
+func (o *offlineFS) Release(cancel <-chan struct{}, in *fuse.ReleaseIn) {
+	if in.Fh&localID != 0 {
+		if !o.releaseFile(in.Fh) {
+			o.fs.diskCache.release(in.Fh)
+		}
+		return
+	}
+	if o.putOff(func() { o.RawFileSystem.Release(nil, in) }) {
+		return
+	}
+	o.RawFileSystem.Release(cancel, in)
+}
+
+func (o *offlineFS) Write(cancel <-chan struct{}, in *fuse.WriteIn, data []byte) (uint32, fuse.Status) {
+	if o.serves(in.NodeId, in.Fh) {
+		if o.file(in.Fh) == nil {
+			return 0, fuse.EROFS
+		}
+		return o.write(in, data)
+	}
+	return o.RawFileSystem.Write(cancel, in, data)
+}
+
+func (o *offlineFS) CopyFileRange(cancel <-chan struct{}, in *fuse.CopyFileRangeIn) (uint32, fuse.Status) {
+	if o.serves(in.NodeId, in.FhIn, in.NodeIdOut, in.FhOut) {
+		if o.journals() {
+			// For the kernel to copy through reads and writes.
+			return 0, fuse.EXDEV
+		}
+		return 0, fuse.EROFS
+	}
+	return o.RawFileSystem.CopyFileRange(cancel, in)
+}
+
+// Flush and Fsync report the writes that failed before going offline,
+// which are all there is to flush but for the files written offline.
+func (o *offlineFS) Flush(cancel <-chan struct{}, in *fuse.FlushIn) fuse.Status {
+	if o.serves(in.NodeId, in.Fh) {
+		return o.fs.writeBehind.flush(in.Fh)
+	}
+	return o.RawFileSystem.Flush(cancel, in)
+}
+
+func (o *offlineFS) Fsync(cancel <-chan struct{}, in *fuse.FsyncIn) fuse.Status {
+	if lf := o.file(in.Fh); lf != nil {
+		return fuse.ToStatus(lf.f.Sync())
+	}
+	if o.serves(in.NodeId, in.Fh) {
+		return o.fs.writeBehind.flush(in.Fh)
+	}
+	return o.RawFileSystem.Fsync(cancel, in)
+}
+
+func (o *offlineFS) Fallocate(cancel <-chan struct{}, in *fuse.FallocateIn) fuse.Status {
+	if o.serves(in.NodeId, in.Fh) {
+		return fuse.EROFS
+	}
+	return o.RawFileSystem.Fallocate(cancel, in)
+}
+
+func (o *offlineFS) OpenDir(cancel <-chan struct{}, in *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
+	if o.serves(in.NodeId) {
+		return o.openDir(in, out)
+	}
+	st := o.RawFileSystem.OpenDir(cancel, in, out)
+	if st == fuse.EIO && o.serves(in.NodeId) {
+		return o.openDir(in, out)
+	}
+	return st
+}
+
+func (o *offlineFS) openDir(in *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
+	if _, st := o.record(in.NodeId); st != fuse.OK {
+		return st
+	}
+	out.Fh = o.handle()
+	return fuse.OK
+}
+
+func (o *offlineFS) ReadDir(cancel <-chan struct{}, in *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
+	if o.serves(in.NodeId, in.Fh) {
+		return o.readDir(in, out, false)
+	}
+	return o.RawFileSystem.ReadDir(cancel, in, out)
+}
+
+func (o *offlineFS) ReadDirPlus(cancel <-chan struct{}, in *fuse.ReadIn, out *fuse.DirEntryList) fuse.Status {
+	if o.serves(in.NodeId, in.Fh) {
+		return o.readDir(in, out, true)
+	}
+	return o.RawFileSystem.ReadDirPlus(cancel, in, out)
+}
+
+// readDir lists a directory from the last listing read to its end.
+func (o *offlineFS) readDir(in *fuse.ReadIn, out *fuse.DirEntryList, plus bool) fuse.Status {
+	r, st := o.record(in.NodeId)
+	if st != fuse.OK {
+		return st
+	}
+	if !r.Listed {
+		return fuse.EIO
+	}
+	for off := in.Offset; off < uint64(len(r.Entries)); off++ {
+		e := r.Entries[off]
+		de := fuse.DirEntry{Name: e.Name, Ino: e.Ino, Mode: e.Mode}
+		if !plus {
+			if !out.AddDirEntry(de) {
+				break
+			}
+			continue
+		}
+		entryOut := out.AddDirLookupEntry(de)
+		if entryOut == nil {
+			break
+		}
+		if e.Name != "." && e.Name != ".." && o.lookup(in.NodeId, e.Name, entryOut) != fuse.OK {
+			// A plain entry, which the kernel looks up when needed.
+			*entryOut = fuse.EntryOut{}
+		}
+	}
+	return fuse.OK
+}
+
+func (o *offlineFS) ReleaseDir(in *fuse.ReleaseIn) {
+	o.mu.Lock()
+	delete(o.dirs, in.Fh)
+	o.mu.Unlock()
+	if in.Fh&localID != 0 {
+		return
+	}
+	if o.putOff(func() { o.RawFileSystem.ReleaseDir(in) }) {
+		return
+	}
+	o.RawFileSystem.ReleaseDir(in)
+}
+
+func (o *offlineFS) FsyncDir(cancel <-chan struct{}, in *fuse.FsyncIn) fuse.Status {
+	if o.serves(in.NodeId, in.Fh) {
+		return fuse.OK
+	}
+	return o.RawFileSystem.FsyncDir(cancel, in)
+}
+
+// StatFs answers what the server last said while offline.
+func (o *offlineFS) StatFs(cancel <-chan struct{}, header *fuse.InHeader, out *fuse.StatfsOut) fuse.Status {
+	if !o.serves(header.NodeId) {
+		st := o.RawFileSystem.StatFs(cancel, header, out)
+		if st != fuse.EIO || !o.serves(header.NodeId) {
+			if st == fuse.OK {
+				o.mu.Lock()
+				o.statfs = *out
+				o.mu.Unlock()
+			}
+			return st
+		}
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	*out = o.statfs
+	return fuse.OK
+}
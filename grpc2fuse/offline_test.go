@@ -0,0 +1,258 @@
+package grpc2fuse
+
+import (
+	"context"
+	"encoding/binary"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/chiyutianyi/grpcfuse/blockcache"
+	"github.com/chiyutianyi/grpcfuse/exports"
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+// outage fails every call with Unavailable while down is set.
+type outage struct {
+	down int32
+}
+
+func (o *outage) set(down bool) {
+	var v int32
+	if down {
+		v = 1
+	}
+	atomic.StoreInt32(&o.down, v)
+}
+
+func (o *outage) dialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			if atomic.LoadInt32(&o.down) != 0 {
+				return status.Error(codes.Unavailable, "down")
+			}
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			if atomic.LoadInt32(&o.down) != 0 {
+				return nil, status.Error(codes.Unavailable, "down")
+			}
+			return streamer(ctx, desc, cc, method, opts...)
+		}),
+	}
+}
+
+// listNames lists the directory node to its end.
+func listNames(t *testing.T, rfs fuse.RawFileSystem, node uint64, plus bool) map[string]uint64 {
+	var openOut fuse.OpenOut
+	header := fuse.InHeader{NodeId: node}
+	require.Equal(t, fuse.OK, rfs.OpenDir(nil, &fuse.OpenIn{InHeader: header}, &openOut))
+	defer rfs.ReleaseDir(&fuse.ReleaseIn{InHeader: header, Fh: openOut.Fh})
+
+	var prefix int
+	if plus {
+		prefix = int(unsafe.Sizeof(fuse.EntryOut{}))
+	}
+	names := map[string]uint64{}
+	for off := uint64(0); ; {
+		buf := make([]byte, 4096)
+		in := &fuse.ReadIn{InHeader: header, Fh: openOut.Fh, Offset: off, Size: uint32(len(buf))}
+		if plus {
+			require.Equal(t, fuse.OK, rfs.ReadDirPlus(nil, in, fuse.NewDirEntryList(buf, off)))
+		} else {
+			require.Equal(t, fuse.OK, rfs.ReadDir(nil, in, fuse.NewDirEntryList(buf, off)))
+		}
+		start := off
+		for pos := 0; pos+prefix+24 <= len(buf); {
+			nodeID := binary.LittleEndian.Uint64(buf[pos:])
+			pos += prefix
+			next := binary.LittleEndian.Uint64(buf[pos+8:])
+			if next == 0 {
+				break
+			}
+			n := int(binary.LittleEndian.Uint32(buf[pos+16:]))
+			name := string(buf[pos+24 : pos+24+n])
+			if !plus {
+				nodeID = 0
+			}
+			names[name] = nodeID
+			off = next
+			pos += 24 + n + (8-n&7)&7
+		}
+		if off == start {
+			return names
+		}
+	}
+}
+
+func TestOffline(t *testing.T) {
+	dialOpts := append(serve(t), grpc.WithInsecure())
+	dialOpts = append(dialOpts, exports.DialOptions("a")...)
+	dir := t.TempDir()
+	var server outage
+	// mount returns a file system answering from dir while the server is
+	// down, as a new mount would have.
+	mount := func() (*offlineFS, *counter) {
+		meta, err := openMetaCache(filepath.Join(dir, "meta"), 0)
+		require.NoError(t, err)
+		cache, err := blockcache.Open(dir, &blockcache.Options{BlockSize: 4096})
+		require.NoError(t, err)
+		o := newOfflineFS(meta)
+		o.interval = 10 * time.Millisecond
+		c := &counter{calls: map[string]int{}}
+		opts := append(dialOpts, grpc.WithChainUnaryInterceptor(o.unaryInterceptor), grpc.WithChainStreamInterceptor(o.streamInterceptor))
+		opts = append(opts, server.dialOptions()...)
+		conn, err := grpc.Dial("bufconn", append(opts, c.dialOptions()...)...)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			o.close()
+			conn.Close()
+		})
+		fs := NewFileSystem(pb.NewRawFileSystemClient(conn))
+		fs.SetCache(cache)
+		fs.listed = o.listed
+		o.RawFileSystem, o.fs = fs, fs
+		o.probe = func(ctx context.Context) error {
+			_, err := fs.client.String(ctx, &pb.StringRequest{})
+			return err
+		}
+		return o, c
+	}
+	o, _ := mount()
+
+	root := fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}
+	var d, f, l fuse.EntryOut
+	require.Equal(t, fuse.OK, o.Mkdir(nil, &fuse.MkdirIn{InHeader: root, Mode: 0755}, "d", &d))
+	var created fuse.CreateOut
+	require.Equal(t, fuse.OK, o.Create(nil, &fuse.CreateIn{InHeader: fuse.InHeader{NodeId: d.NodeId}, Flags: syscall.O_RDWR, Mode: 0644}, "f", &created))
+	f = created.EntryOut
+	_, st := o.Write(nil, &fuse.WriteIn{InHeader: fuse.InHeader{NodeId: f.NodeId}, Fh: created.Fh, Size: 5}, []byte("hello"))
+	require.Equal(t, fuse.OK, st)
+	o.Release(nil, &fuse.ReleaseIn{InHeader: fuse.InHeader{NodeId: f.NodeId}, Fh: created.Fh})
+	require.Equal(t, fuse.OK, o.Symlink(nil, &root, "d/f", "l", &l))
+
+	// What is read online is kept.
+	var attr fuse.AttrOut
+	require.Equal(t, fuse.OK, o.GetAttr(nil, &fuse.GetAttrIn{InHeader: root}, &attr))
+	require.Equal(t, fuse.OK, o.GetAttr(nil, &fuse.GetAttrIn{InHeader: fuse.InHeader{NodeId: f.NodeId}}, &attr))
+	assert.Equal(t, uint64(5), attr.Size)
+	assert.Contains(t, listNames(t, o, fuse.FUSE_ROOT_ID, false), "d")
+	assert.Contains(t, listNames(t, o, d.NodeId, true), "f")
+	target, st := o.Readlink(nil, &fuse.InHeader{NodeId: l.NodeId})
+	require.Equal(t, fuse.OK, st)
+	read := func(o *offlineFS, node uint64) (string, fuse.Status) {
+		header := fuse.InHeader{NodeId: node}
+		var out fuse.OpenOut
+		if st := o.Open(nil, &fuse.OpenIn{InHeader: header}, &out); st != fuse.OK {
+			return "", st
+		}
+		defer o.Release(nil, &fuse.ReleaseIn{InHeader: header, Fh: out.Fh})
+		res, st := o.Read(nil, &fuse.ReadIn{InHeader: header, Fh: out.Fh, Size: 100}, nil)
+		if st != fuse.OK {
+			return "", st
+		}
+		data, _ := res.Bytes(nil)
+		return string(data), fuse.OK
+	}
+	data, st := read(o, f.NodeId)
+	require.Equal(t, fuse.OK, st)
+	assert.Equal(t, "hello", data)
+	var statfs fuse.StatfsOut
+	require.Equal(t, fuse.OK, o.StatFs(nil, &root, &statfs))
+	mode := func(o *offlineFS) string {
+		buf := make([]byte, 16)
+		n, st := o.GetXAttr(nil, &root, ModeXAttr, buf)
+		require.Equal(t, fuse.OK, st)
+		return string(buf[:n])
+	}
+	assert.Equal(t, "online", mode(o))
+
+	// The call that finds the server gone is answered from the cache, as
+	// is everything after it.
+	server.set(true)
+	require.Equal(t, fuse.OK, o.GetAttr(nil, &fuse.GetAttrIn{InHeader: fuse.InHeader{NodeId: d.NodeId}}, &attr))
+	assert.True(t, attr.IsDir())
+	assert.Equal(t, "offline", mode(o))
+	var out fuse.EntryOut
+	require.Equal(t, fuse.OK, o.Lookup(nil, &root, "d", &out))
+	assert.Equal(t, d.NodeId, out.NodeId, "the node the kernel has")
+	assert.Equal(t, fuse.ENOENT, o.Lookup(nil, &fuse.InHeader{NodeId: d.NodeId}, "nope", &out))
+	require.Equal(t, fuse.OK, o.Lookup(nil, &fuse.InHeader{NodeId: d.NodeId}, "f", &out))
+	assert.Equal(t, f.NodeId, out.NodeId)
+	assert.Equal(t, map[string]uint64{".": 0, "..": 0, "f": f.NodeId}, listNames(t, o, d.NodeId, true))
+	got, st := o.Readlink(nil, &fuse.InHeader{NodeId: l.NodeId})
+	require.Equal(t, fuse.OK, st)
+	assert.Equal(t, target, got)
+	data, st = read(o, f.NodeId)
+	require.Equal(t, fuse.OK, st)
+	assert.Equal(t, "hello", data)
+	var statfs2 fuse.StatfsOut
+	require.Equal(t, fuse.OK, o.StatFs(nil, &root, &statfs2))
+	assert.Equal(t, statfs, statfs2)
+
+	var openOut fuse.OpenOut
+	assert.Equal(t, fuse.EROFS, o.Open(nil, &fuse.OpenIn{InHeader: fuse.InHeader{NodeId: f.NodeId}, Flags: syscall.O_RDWR}, &openOut))
+	assert.Equal(t, fuse.EROFS, o.Mkdir(nil, &fuse.MkdirIn{InHeader: root}, "e", &out))
+	assert.Equal(t, fuse.EROFS, o.Unlink(nil, &root, "l"))
+	assert.Equal(t, fuse.EROFS, o.SetAttr(nil, &fuse.SetAttrIn{SetAttrInCommon: fuse.SetAttrInCommon{InHeader: fuse.InHeader{NodeId: f.NodeId}}}, &attr))
+
+	// The lookups made offline are not passed on: f was looked up by
+	// Create and READDIRPLUS online, and twice more offline.
+	o.Forget(f.NodeId, 2)
+	o.mu.Lock()
+	assert.Empty(t, o.pending)
+	assert.Equal(t, uint64(2), o.nodes[f.NodeId].lookups)
+	o.mu.Unlock()
+	o.Forget(f.NodeId, 2)
+	o.mu.Lock()
+	assert.Len(t, o.pending, 1, "put off")
+	assert.NotContains(t, o.nodes, f.NodeId)
+	o.mu.Unlock()
+
+	server.set(false)
+	require.Eventually(t, func() bool { return !o.isOffline() }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "online", mode(o))
+	require.Equal(t, fuse.OK, o.Mkdir(nil, &fuse.MkdirIn{InHeader: root, Mode: 0755}, "e", &out))
+
+	// A new mount answers from what the last one kept.
+	server.set(true)
+	o2, c := mount()
+	assert.Equal(t, fuse.OK, o2.GetAttr(nil, &fuse.GetAttrIn{InHeader: root}, &attr))
+	require.True(t, o2.isOffline())
+	require.Equal(t, fuse.OK, o2.Lookup(nil, &root, "d", &out))
+	local := out.NodeId
+	assert.NotZero(t, local&localID, "a node ID of its own")
+	assert.Contains(t, listNames(t, o2, local, false), "f")
+	require.Equal(t, fuse.OK, o2.Lookup(nil, &fuse.InHeader{NodeId: local}, "f", &out))
+	data, st = read(o2, out.NodeId)
+	require.Equal(t, fuse.OK, st)
+	assert.Equal(t, "hello", data)
+	assert.Equal(t, fuse.OK, o2.Lookup(nil, &root, "e", &out), "made by the last mount")
+	o2.Forget(out.NodeId, 1)
+	assert.Equal(t, fuse.ENOENT, o2.Lookup(nil, &root, "nope", &out))
+
+	// Back online, what was looked up offline stays read-only, without
+	// going to the server, which does not know the node IDs.
+	server.set(false)
+	require.Eventually(t, func() bool { return !o2.isOffline() }, 5*time.Second, 10*time.Millisecond)
+	calls := c.get("GetAttr")
+	require.Equal(t, fuse.OK, o2.GetAttr(nil, &fuse.GetAttrIn{InHeader: fuse.InHeader{NodeId: local}}, &attr))
+	assert.Equal(t, calls, c.get("GetAttr"))
+	assert.Equal(t, fuse.EROFS, o2.Mkdir(nil, &fuse.MkdirIn{InHeader: fuse.InHeader{NodeId: local}}, "g", &out))
+	require.Equal(t, fuse.OK, o2.Lookup(nil, &root, "d", &out))
+	assert.Equal(t, d.NodeId, out.NodeId)
+	o2.Forget(local, 1)
+	o2.mu.Lock()
+	assert.NotContains(t, o2.nodes, local)
+	o2.mu.Unlock()
+}
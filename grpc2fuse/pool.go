@@ -0,0 +1,220 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc2fuse
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"google.golang.org/grpc"
+
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+const defaultStripeSize = 256 << 10
+
+// Pool is a set of connections to one server. Metadata calls go over the
+// first, so a lookup or a stat does not queue behind bulk data in the
+// flow-control window of a busy connection, and reads and writes take
+// turns over the others. Reads and writes larger than the stripe size are
+// cut into stripes sent at once, each over the next connection.
+type Pool struct {
+	meta       pb.RawFileSystemClient
+	data       []pb.RawFileSystemClient
+	stripeSize int
+	next       uint32
+}
+
+// NewPool returns a pool sending metadata calls to meta and reads and
+// writes to data, in stripes of stripeSize bytes, 256KiB if 0. Without
+// data, everything goes to meta.
+func NewPool(meta pb.RawFileSystemClient, data []pb.RawFileSystemClient, stripeSize int) *Pool {
+	if stripeSize <= 0 {
+		stripeSize = defaultStripeSize
+	}
+	return &Pool{meta: meta, data: data, stripeSize: stripeSize}
+}
+
+// NewPooledFileSystem is NewFileSystem over the connections of pool.
+func NewPooledFileSystem(pool *Pool, opts ...grpc.CallOption) *fileSystem {
+	fs := NewFileSystem(pool.meta, opts...)
+	fs.pool = pool
+	return fs
+}
+
+// dataClient returns the client of the next read or write.
+func (p *Pool) dataClient() pb.RawFileSystemClient {
+	if len(p.data) == 0 {
+		return p.meta
+	}
+	n := atomic.AddUint32(&p.next, 1)
+	return p.data[int(n%uint32(len(p.data)))]
+}
+
+// stripes returns the sizes of the stripes a transfer of size bytes is
+// cut into. Transfers are only cut over several data connections.
+func (p *Pool) stripes(size int) []int {
+	if p == nil || len(p.data) < 2 || size <= p.stripeSize {
+		return []int{size}
+	}
+	var sizes []int
+	for ; size > p.stripeSize; size -= p.stripeSize {
+		sizes = append(sizes, p.stripeSize)
+	}
+	return append(sizes, size)
+}
+
+func (fs *fileSystem) dataClient() pb.RawFileSystemClient {
+	if fs.pool == nil {
+		return fs.client
+	}
+	return fs.pool.dataClient()
+}
+
+// read reads in, in stripes read at once. The data ends at the first
+// stripe that comes back short.
+func (fs *fileSystem) read(ctx context.Context, in *pb.ReadIn) ([]byte, fuse.Status) {
+	sizes := fs.pool.stripes(int(in.Size))
+	if len(sizes) == 1 {
+		return fs.readStripe(ctx, fs.dataClient(), in)
+	}
+
+	bufs := make([][]byte, len(sizes))
+	codes := make([]fuse.Status, len(sizes))
+	var wg sync.WaitGroup
+	off := in.Offset
+	for i, size := range sizes {
+		stripe := &pb.ReadIn{
+			Header:    in.Header,
+			Fh:        in.Fh,
+			Offset:    off,
+			Size:      uint32(size),
+			ReadFlags: in.ReadFlags,
+			LockOwner: in.LockOwner,
+			Flags:     in.Flags,
+			Padding:   in.Padding,
+		}
+		client := fs.dataClient()
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			bufs[i], codes[i] = fs.readStripe(ctx, client, stripe)
+		}(i)
+		off += uint64(size)
+	}
+	wg.Wait()
+
+	data := make([]byte, 0, in.Size)
+	for i, size := range sizes {
+		if codes[i] != fuse.OK {
+			return nil, codes[i]
+		}
+		data = append(data, bufs[i]...)
+		if len(bufs[i]) < size {
+			break
+		}
+	}
+	return data, fuse.OK
+}
+
+func (fs *fileSystem) readStripe(ctx context.Context, client pb.RawFileSystemClient, in *pb.ReadIn) ([]byte, fuse.Status) {
+	stream, err := client.Read(ctx, &pb.ReadRequest{ReadIn: in}, fs.opts...)
+	if st := dealGrpcError("Read", err); st != fuse.OK {
+		return nil, st
+	}
+
+	var rs []byte
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if st := dealGrpcError("Read", err); st != fuse.OK {
+			return nil, st
+		}
+		if res.Status.GetCode() != 0 {
+			return nil, fuse.Status(res.Status.GetCode())
+		}
+		rs = append(rs, res.Buffer...)
+	}
+	return rs, fuse.OK
+}
+
+// write writes req, in stripes written at once. Like a short write, the
+// count stops at the first stripe that fails or comes back short; writes
+// to files opened with O_APPEND are never cut, as their offsets are not
+// theirs to choose.
+func (fs *fileSystem) write(ctx context.Context, req *pb.WriteRequest) (uint32, fuse.Status) {
+	defer fs.changed(req.Header.NodeId)
+	sizes := fs.pool.stripes(len(req.Data))
+	if len(sizes) == 1 || req.Flags&syscall.O_APPEND != 0 {
+		return fs.writeStripe(ctx, fs.dataClient(), req)
+	}
+
+	written := make([]uint32, len(sizes))
+	codes := make([]fuse.Status, len(sizes))
+	var wg sync.WaitGroup
+	var off int
+	for i, size := range sizes {
+		stripe := &pb.WriteRequest{
+			Header:     req.Header,
+			Fh:         req.Fh,
+			Offset:     req.Offset + uint64(off),
+			Data:       req.Data[off : off+size],
+			Size:       uint32(size),
+			WriteFlags: req.WriteFlags,
+			LockOwner:  req.LockOwner,
+			Flags:      req.Flags,
+			Padding:    req.Padding,
+		}
+		client := fs.dataClient()
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			written[i], codes[i] = fs.writeStripe(ctx, client, stripe)
+		}(i)
+		off += size
+	}
+	wg.Wait()
+
+	var total uint32
+	for i, size := range sizes {
+		if codes[i] != fuse.OK {
+			if total > 0 {
+				break
+			}
+			return 0, codes[i]
+		}
+		total += written[i]
+		if int(written[i]) < size {
+			break
+		}
+	}
+	return total, fuse.OK
+}
+
+func (fs *fileSystem) writeStripe(ctx context.Context, client pb.RawFileSystemClient, req *pb.WriteRequest) (uint32, fuse.Status) {
+	res, err := client.Write(ctx, req, fs.opts...)
+	if st := dealGrpcError("Write", err); st != fuse.OK {
+		return 0, st
+	}
+	return uint32(res.Written), fuse.Status(res.Status.GetCode())
+}
@@ -0,0 +1,10 @@
+package grpc2fuse
+
+import (
+	"github.com/hanwen/go-fuse/v2/fuse"
+)
+
+// appending is a no-op: the writes of macOS do not carry open flags.
+func appending(in *fuse.WriteIn) *fuse.WriteIn {
+	return in
+}
@@ -0,0 +1,13 @@
+package grpc2fuse
+
+import (
+	"syscall"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+)
+
+// appending marks in as a write of a file opened with O_APPEND.
+func appending(in *fuse.WriteIn) *fuse.WriteIn {
+	in.Flags = syscall.O_APPEND
+	return in
+}
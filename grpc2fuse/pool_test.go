@@ -0,0 +1,172 @@
+package grpc2fuse
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/chiyutianyi/grpcfuse/exports"
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+func TestStripes(t *testing.T) {
+	data := []pb.RawFileSystemClient{nil, nil}
+	tests := []struct {
+		name string
+		pool *Pool
+		size int
+		want []int
+	}{
+		{name: "no pool", size: 100, want: []int{100}},
+		{name: "one data connection", pool: NewPool(nil, data[:1], 10), size: 100, want: []int{100}},
+		{name: "small", pool: NewPool(nil, data, 10), size: 10, want: []int{10}},
+		{name: "even", pool: NewPool(nil, data, 10), size: 30, want: []int{10, 10, 10}},
+		{name: "uneven", pool: NewPool(nil, data, 10), size: 25, want: []int{10, 10, 5}},
+		{name: "default", pool: NewPool(nil, data, 0), size: 1 << 20, want: []int{256 << 10, 256 << 10, 256 << 10, 256 << 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.pool.stripes(tt.size))
+		})
+	}
+}
+
+// counter counts the calls made over a connection, by method name.
+type counter struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (c *counter) add(method string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls[methodName(method)]++
+}
+
+func (c *counter) get(method string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls[method]
+}
+
+func (c *counter) dialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			c.add(method)
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			c.add(method)
+			return streamer(ctx, desc, cc, method, opts...)
+		}),
+	}
+}
+
+func TestPool(t *testing.T) {
+	dialOpts := append(serve(t), grpc.WithInsecure())
+	dialOpts = append(dialOpts, exports.DialOptions("a")...)
+	counters := make([]*counter, 3)
+	clients := make([]pb.RawFileSystemClient, 3)
+	for i := range clients {
+		counters[i] = &counter{calls: map[string]int{}}
+		conn, err := grpc.Dial("bufconn", append(dialOpts, counters[i].dialOptions()...)...)
+		require.NoError(t, err)
+		defer conn.Close()
+		clients[i] = pb.NewRawFileSystemClient(conn)
+	}
+	fs := NewPooledFileSystem(NewPool(clients[0], clients[1:], 4))
+
+	var out fuse.CreateOut
+	require.Equal(t, fuse.OK, fs.Create(nil, &fuse.CreateIn{InHeader: fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}, Mode: 0644}, "file", &out))
+	header := fuse.InHeader{NodeId: out.NodeId}
+
+	// 10 bytes go as 4+4+2.
+	written, st := fs.Write(nil, &fuse.WriteIn{InHeader: header, Fh: out.Fh, Size: 10}, []byte("0123456789"))
+	require.Equal(t, fuse.OK, st)
+	assert.Equal(t, uint32(10), written)
+
+	// Appending is not cut.
+	written, st = fs.Write(nil, appending(&fuse.WriteIn{InHeader: header, Fh: out.Fh, Offset: 10, Size: 10}), []byte("abcdefghij"))
+	require.Equal(t, fuse.OK, st)
+	assert.Equal(t, uint32(10), written)
+
+	tests := []struct {
+		offset uint64
+		size   uint32
+		want   string
+	}{
+		{offset: 0, size: 4, want: "0123"},
+		{offset: 0, size: 100, want: "0123456789abcdefghij"},
+		{offset: 6, size: 8, want: "6789abcd"},
+		{offset: 18, size: 8, want: "ij"},
+		{offset: 30, size: 8, want: ""},
+	}
+	for _, tt := range tests {
+		res, st := fs.Read(nil, &fuse.ReadIn{InHeader: header, Fh: out.Fh, Offset: tt.offset, Size: tt.size}, nil)
+		require.Equal(t, fuse.OK, st)
+		data, st := res.Bytes(nil)
+		require.Equal(t, fuse.OK, st)
+		assert.Equal(t, tt.want, string(data), "%d+%d", tt.offset, tt.size)
+	}
+
+	// Metadata stays on the first connection and data is spread over the
+	// others.
+	assert.Equal(t, 1, counters[0].get("Create"))
+	assert.Zero(t, counters[0].get("Write")+counters[0].get("Read"))
+	writes := counters[1].get("Write") + counters[2].get("Write")
+	reads := counters[1].get("Read") + counters[2].get("Read")
+	assert.Equal(t, 4, writes)
+	assert.Equal(t, 1+25+2+2+2, reads)
+	for _, c := range counters[1:] {
+		assert.NotZero(t, c.get("Write"))
+		assert.NotZero(t, c.get("Read"))
+		assert.Zero(t, c.get("Create"))
+	}
+
+}
+
+// failing fails the reads and writes made over it.
+type failing struct {
+	pb.RawFileSystemClient
+}
+
+func (failing) Read(ctx context.Context, in *pb.ReadRequest, opts ...grpc.CallOption) (pb.RawFileSystem_ReadClient, error) {
+	return nil, status.Error(codes.Unavailable, "down")
+}
+
+func (failing) Write(ctx context.Context, in *pb.WriteRequest, opts ...grpc.CallOption) (*pb.WriteResponse, error) {
+	return nil, status.Error(codes.Unavailable, "down")
+}
+
+func TestPoolErrors(t *testing.T) {
+	dialOpts := append(serve(t), grpc.WithInsecure())
+	conn, err := grpc.Dial("bufconn", append(dialOpts, exports.DialOptions("a")...)...)
+	require.NoError(t, err)
+	defer conn.Close()
+	client := pb.NewRawFileSystemClient(conn)
+	// Stripes take turns starting with the failing connection.
+	fs := NewPooledFileSystem(NewPool(client, []pb.RawFileSystemClient{client, failing{}}, 4))
+
+	var out fuse.CreateOut
+	require.Equal(t, fuse.OK, fs.Create(nil, &fuse.CreateIn{InHeader: fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}, Mode: 0644}, "file", &out))
+	in := &fuse.WriteIn{InHeader: fuse.InHeader{NodeId: out.NodeId}, Fh: out.Fh, Size: 10}
+
+	// The first stripe fails: nothing is written.
+	written, st := fs.Write(nil, in, []byte("0123456789"))
+	assert.Equal(t, fuse.EIO, st)
+	assert.Zero(t, written)
+	// The second stripe fails: the write is short.
+	written, st = fs.Write(nil, in, []byte("0123456789"))
+	assert.Equal(t, fuse.OK, st)
+	assert.Equal(t, uint32(4), written)
+
+	_, st = fs.Read(nil, &fuse.ReadIn{InHeader: in.InHeader, Fh: out.Fh, Size: 10}, nil)
+	assert.Equal(t, fuse.EIO, st)
+}
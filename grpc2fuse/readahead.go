@@ -0,0 +1,245 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc2fuse
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+)
+
+// readAhead fetches the data past the sequential reads of a handle before
+// it is asked for, so that reading a file is not one round trip per read.
+// The chunks fetched are kept until read, at most window bytes past the
+// last read of each handle, and dropped when the file changes.
+type readAhead struct {
+	window, chunk int
+
+	mu      sync.Mutex
+	handles map[uint64]*raHandle
+}
+
+type raHandle struct {
+	node uint64
+	// next is the offset past the furthest read of the handle and ahead
+	// the offset past the last chunk fetched.
+	next, ahead uint64
+	// eof is set once a chunk came back short; nothing is fetched past
+	// it.
+	eof bool
+	// gen counts the resets of the chunks, so that fetches started
+	// before one do not mark the handle.
+	gen    int
+	chunks []*raChunk
+	// wg waits for the fetches of the handle before it is released.
+	wg sync.WaitGroup
+}
+
+type raChunk struct {
+	off  uint64
+	size int
+	done chan struct{}
+	data []byte
+	st   fuse.Status
+}
+
+func newReadAhead(window, chunk int) *readAhead {
+	return &readAhead{window: window, chunk: chunk, handles: map[uint64]*raHandle{}}
+}
+
+// SetReadAhead makes fs fetch window bytes past the sequential reads of
+// a handle, in chunks of chunk bytes read at once. A window of 0 turns
+// read-ahead off. It must be called before fs is used.
+func (fs *fileSystem) SetReadAhead(window, chunk int) {
+	if window <= 0 || chunk <= 0 {
+		fs.readAhead = nil
+		return
+	}
+	fs.readAhead = newReadAhead(window, chunk)
+}
+
+// reset drops the chunks of h.
+func (h *raHandle) reset() {
+	h.chunks, h.ahead, h.eof = nil, 0, false
+	h.gen++
+}
+
+// covering returns the chunks that hold [off, end), or nil if there is a
+// gap.
+func (h *raHandle) covering(off, end uint64) []*raChunk {
+	var chunks []*raChunk
+	for _, c := range h.chunks {
+		if c.off+uint64(c.size) <= off {
+			continue
+		}
+		if c.off > off {
+			return nil
+		}
+		chunks = append(chunks, c)
+		off = c.off + uint64(c.size)
+		if off >= end {
+			return chunks
+		}
+	}
+	return nil
+}
+
+// consume drops the chunks that end at or before off.
+func (h *raHandle) consume(off uint64) {
+	i := 0
+	for i < len(h.chunks) && h.chunks[i].off+uint64(h.chunks[i].size) <= off {
+		i++
+	}
+	h.chunks = h.chunks[i:]
+}
+
+// collect waits for chunks and returns the part of [off, end) they hold,
+// or false if one failed.
+func collect(chunks []*raChunk, off, end uint64) ([]byte, bool) {
+	data := make([]byte, 0, end-off)
+	for _, c := range chunks {
+		<-c.done
+		if c.st != fuse.OK {
+			return nil, false
+		}
+		from, to := uint64(0), uint64(len(c.data))
+		if off > c.off {
+			from = off - c.off
+		}
+		if end < c.off+to {
+			to = end - c.off
+		}
+		if from < to {
+			data = append(data, c.data[from:to]...)
+		}
+		if len(c.data) < c.size {
+			break
+		}
+	}
+	return data, true
+}
+
+func (fs *fileSystem) readAheadRead(ctx context.Context, in *fuse.ReadIn) ([]byte, fuse.Status) {
+	ra := fs.readAhead
+	off, end := in.Offset, in.Offset+uint64(in.Size)
+
+	ra.mu.Lock()
+	h := ra.handles[in.Fh]
+	if h == nil {
+		h = &raHandle{node: in.NodeId}
+		ra.handles[in.Fh] = h
+	}
+	// Reads that the kernel sends at once may come out of order, so a
+	// read anywhere in the chunks fetched is sequential too.
+	sequential := off == h.next || len(h.chunks) > 0 && off >= h.chunks[0].off && off < h.ahead
+	if !sequential {
+		h.reset()
+	}
+	if end > h.next {
+		h.next = end
+	}
+	chunks := h.covering(off, end)
+	ra.mu.Unlock()
+
+	data, ok := collect(chunks, off, end)
+	if chunks == nil || !ok {
+		var st fuse.Status
+		if data, st = fs.read(ctx, toPbReadIn(in)); st != fuse.OK {
+			return nil, st
+		}
+	}
+
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	if ra.handles[in.Fh] != h {
+		// Released meanwhile.
+		return data, fuse.OK
+	}
+	if len(data) < int(in.Size) {
+		// The end of the file, for now.
+		h.reset()
+		return data, fuse.OK
+	}
+	h.consume(end)
+	if sequential {
+		fs.fetchAhead(h, in, end)
+	}
+	return data, fuse.OK
+}
+
+// fetchAhead starts fetching the chunks of h up to window bytes past end.
+// ra.mu must be held.
+func (fs *fileSystem) fetchAhead(h *raHandle, in *fuse.ReadIn, end uint64) {
+	ra := fs.readAhead
+	if h.eof {
+		return
+	}
+	if h.ahead < end {
+		h.ahead = end
+	}
+	for h.ahead < end+uint64(ra.window) {
+		c := &raChunk{off: h.ahead, size: ra.chunk, done: make(chan struct{})}
+		h.chunks = append(h.chunks, c)
+		h.ahead += uint64(ra.chunk)
+
+		chunkIn := *in
+		chunkIn.Offset, chunkIn.Size = c.off, uint32(c.size)
+		gen := h.gen
+		h.wg.Add(1)
+		go func() {
+			defer h.wg.Done()
+			c.data, c.st = fs.read(context.Background(), toPbReadIn(&chunkIn))
+			if c.st == fuse.OK && len(c.data) < c.size {
+				ra.mu.Lock()
+				if h.gen == gen {
+					h.eof = true
+				}
+				ra.mu.Unlock()
+			}
+			close(c.done)
+		}()
+	}
+}
+
+// invalidate drops what was fetched of node, once it has changed.
+func (ra *readAhead) invalidate(node uint64) {
+	if ra == nil {
+		return
+	}
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	for _, h := range ra.handles {
+		if h.node == node {
+			h.reset()
+		}
+	}
+}
+
+// release forgets the handle fh once its fetches are done.
+func (ra *readAhead) release(fh uint64) {
+	if ra == nil {
+		return
+	}
+	ra.mu.Lock()
+	h := ra.handles[fh]
+	delete(ra.handles, fh)
+	ra.mu.Unlock()
+	if h != nil {
+		h.wg.Wait()
+	}
+}
@@ -0,0 +1,111 @@
+package grpc2fuse
+
+import (
+	"testing"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/chiyutianyi/grpcfuse/exports"
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+func doneChunk(off uint64, size int, data string) *raChunk {
+	c := &raChunk{off: off, size: size, done: make(chan struct{}), data: []byte(data)}
+	close(c.done)
+	return c
+}
+
+func TestReadAheadChunks(t *testing.T) {
+	h := &raHandle{chunks: []*raChunk{doneChunk(4, 4, "4567"), doneChunk(8, 4, "89ab"), doneChunk(12, 4, "cd")}}
+	tests := []struct {
+		name     string
+		off, end uint64
+		want     string
+		miss     bool
+	}{
+		{name: "in one chunk", off: 5, end: 7, want: "56"},
+		{name: "across chunks", off: 6, end: 10, want: "6789"},
+		{name: "to the end of the file", off: 10, end: 16, want: "abcd"},
+		{name: "before", off: 2, end: 6, miss: true},
+		{name: "past", off: 16, end: 20, miss: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := h.covering(tt.off, tt.end)
+			if tt.miss {
+				assert.Nil(t, chunks)
+				return
+			}
+			data, ok := collect(chunks, tt.off, tt.end)
+			require.True(t, ok)
+			assert.Equal(t, tt.want, string(data))
+		})
+	}
+
+	failed := doneChunk(16, 4, "")
+	failed.st = fuse.EIO
+	_, ok := collect([]*raChunk{h.chunks[0], failed}, 4, 20)
+	assert.False(t, ok)
+
+	h.consume(12)
+	require.Len(t, h.chunks, 1)
+	assert.Equal(t, uint64(12), h.chunks[0].off)
+}
+
+func TestReadAhead(t *testing.T) {
+	c := &counter{calls: map[string]int{}}
+	dialOpts := append(serve(t), grpc.WithInsecure())
+	dialOpts = append(dialOpts, exports.DialOptions("a")...)
+	conn, err := grpc.Dial("bufconn", append(dialOpts, c.dialOptions()...)...)
+	require.NoError(t, err)
+	defer conn.Close()
+	fs := NewFileSystem(pb.NewRawFileSystemClient(conn))
+	fs.SetReadAhead(16, 4)
+
+	var out fuse.CreateOut
+	require.Equal(t, fuse.OK, fs.Create(nil, &fuse.CreateIn{InHeader: fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}, Mode: 0644}, "file", &out))
+	header := fuse.InHeader{NodeId: out.NodeId}
+	contents := "0123456789abcdefghijklmnopqrstuvwxyzABCD"
+	_, st := fs.Write(nil, &fuse.WriteIn{InHeader: header, Fh: out.Fh, Size: 40}, []byte(contents))
+	require.Equal(t, fuse.OK, st)
+
+	read := func(off uint64, size uint32) string {
+		res, st := fs.Read(nil, &fuse.ReadIn{InHeader: header, Fh: out.Fh, Offset: off, Size: size}, nil)
+		require.Equal(t, fuse.OK, st)
+		data, _ := res.Bytes(nil)
+		return string(data)
+	}
+
+	// Sequential reads are served from the chunks fetched ahead.
+	var got string
+	for off := uint64(0); off <= 40; off += 4 {
+		got += read(off, 4)
+	}
+	assert.Equal(t, contents, got)
+	// 1 read and a chunk for each of the rest, with at most the window
+	// fetched past the end.
+	assert.GreaterOrEqual(t, c.get("Read"), 11)
+	assert.LessOrEqual(t, c.get("Read"), 1+10+4)
+
+	// Writes and truncation drop what was fetched.
+	assert.Equal(t, "0123", read(0, 4))
+	_, st = fs.Write(nil, &fuse.WriteIn{InHeader: header, Fh: out.Fh, Offset: 8, Size: 4}, []byte("WXYZ"))
+	require.Equal(t, fuse.OK, st)
+	assert.Equal(t, "4567", read(4, 4))
+	assert.Equal(t, "WXYZ", read(8, 4))
+	var attr fuse.AttrOut
+	require.Equal(t, fuse.OK, fs.SetAttr(nil, &fuse.SetAttrIn{SetAttrInCommon: fuse.SetAttrInCommon{InHeader: header, Valid: fuse.FATTR_SIZE, Size: 14}}, &attr))
+	assert.Equal(t, "cd", read(12, 4))
+
+	// Random reads fetch nothing ahead.
+	calls := c.get("Read")
+	assert.Equal(t, "2", read(2, 1))
+	assert.Equal(t, "X", read(9, 1))
+	assert.Equal(t, calls+2, c.get("Read"))
+
+	fs.Release(nil, &fuse.ReleaseIn{InHeader: header, Fh: out.Fh})
+	assert.Empty(t, fs.readAhead.handles)
+}
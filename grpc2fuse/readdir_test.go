@@ -0,0 +1,197 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc2fuse_test
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+	"unsafe"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/chiyutianyi/grpcfuse/fuse2grpc"
+	"github.com/chiyutianyi/grpcfuse/grpc2fuse"
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+const bigDirSize = 20000
+
+// bigDir is a directory with bigDirSize synthetic regular files.
+type bigDir struct {
+	fs.Inode
+}
+
+var (
+	_ = (fs.NodeReaddirer)((*bigDir)(nil))
+	_ = (fs.NodeLookuper)((*bigDir)(nil))
+)
+
+func bigDirName(i int) string {
+	return fmt.Sprintf("file-%05d", i)
+}
+
+func (d *bigDir) Readdir(ctx context.Context) (fs.DirStream, syscall.Errno) {
+	entries := make([]fuse.DirEntry, 0, bigDirSize)
+	for i := 0; i < bigDirSize; i++ {
+		entries = append(entries, fuse.DirEntry{Name: bigDirName(i), Ino: uint64(i + 2), Mode: fuse.S_IFREG})
+	}
+	return fs.NewListDirStream(entries), 0
+}
+
+func (d *bigDir) Lookup(ctx context.Context, name string, out *fuse.EntryOut) (*fs.Inode, syscall.Errno) {
+	var i int
+	if _, err := fmt.Sscanf(name, "file-%05d", &i); err != nil || i >= bigDirSize {
+		return nil, syscall.ENOENT
+	}
+	out.Mode = fuse.S_IFREG | 0644
+	out.Size = uint64(i)
+	return d.NewInode(ctx, &fs.MemRegularFile{}, fs.StableAttr{Mode: fuse.S_IFREG, Ino: uint64(i + 2)}), 0
+}
+
+func newBigDirFileSystem(t *testing.T, msgSizeThreshold int) fuse.RawFileSystem {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	srv := fuse2grpc.NewServer(fs.NewNodeFS(&bigDir{}, &fs.Options{}))
+	srv.SetMsgSizeThreshold(msgSizeThreshold)
+	pb.RegisterRawFileSystemServer(server, srv)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufconn",
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return grpc2fuse.NewFileSystem(pb.NewRawFileSystemClient(conn))
+}
+
+type listedEntry struct {
+	name   string
+	off    uint64
+	nodeID uint64
+}
+
+// parseDirents decodes a READDIR or READDIRPLUS buffer the way the kernel
+// would.
+func parseDirents(buf []byte, plus bool) []listedEntry {
+	var (
+		entries []listedEntry
+		prefix  int
+	)
+	if plus {
+		prefix = int(unsafe.Sizeof(fuse.EntryOut{}))
+	}
+	for pos := 0; pos+prefix+24 <= len(buf); {
+		var nodeID uint64
+		if plus {
+			nodeID = binary.LittleEndian.Uint64(buf[pos:])
+		}
+		pos += prefix
+		off := binary.LittleEndian.Uint64(buf[pos+8:])
+		nameLen := int(binary.LittleEndian.Uint32(buf[pos+16:]))
+		if off == 0 {
+			break
+		}
+		entries = append(entries, listedEntry{name: string(buf[pos+24 : pos+24+nameLen]), off: off, nodeID: nodeID})
+		pos += 24 + nameLen + (8-nameLen&7)&7
+	}
+	return entries
+}
+
+// listDir reads the root directory from off onwards, in bufSize chunks.
+func listDir(t *testing.T, rfs fuse.RawFileSystem, plus bool, bufSize uint32, off uint64) []listedEntry {
+	var (
+		openOut fuse.OpenOut
+		all     []listedEntry
+	)
+	header := fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}
+	require.Equal(t, fuse.OK, rfs.OpenDir(nil, &fuse.OpenIn{InHeader: header}, &openOut))
+	defer rfs.ReleaseDir(&fuse.ReleaseIn{InHeader: header, Fh: openOut.Fh})
+
+	for {
+		buf := make([]byte, bufSize)
+		out := fuse.NewDirEntryList(buf, off)
+		in := &fuse.ReadIn{InHeader: header, Fh: openOut.Fh, Offset: off, Size: bufSize}
+		var st fuse.Status
+		if plus {
+			st = rfs.ReadDirPlus(nil, in, out)
+		} else {
+			st = rfs.ReadDir(nil, in, out)
+		}
+		require.Equal(t, fuse.OK, st)
+		entries := parseDirents(buf, plus)
+		if len(entries) == 0 {
+			return all
+		}
+		all = append(all, entries...)
+		off = entries[len(entries)-1].off
+	}
+}
+
+func requireComplete(t *testing.T, entries []listedEntry, plus bool) {
+	require.Len(t, entries, bigDirSize)
+	for i, e := range entries {
+		require.Equal(t, bigDirName(i), e.name)
+		require.Equal(t, uint64(i+1), e.off)
+		if plus {
+			require.NotZero(t, e.nodeID, e.name)
+		}
+	}
+}
+
+func TestReadDirLargeDirectory(t *testing.T) {
+	for _, tt := range []struct {
+		name             string
+		plus             bool
+		bufSize          uint32
+		msgSizeThreshold int
+	}{
+		{name: "readdir", bufSize: 512, msgSizeThreshold: 1 << 20},
+		{name: "readdir small messages", bufSize: 4096, msgSizeThreshold: 64},
+		{name: "readdirplus", plus: true, bufSize: 1024, msgSizeThreshold: 1 << 20},
+		{name: "readdirplus small messages", plus: true, bufSize: 4096, msgSizeThreshold: 200},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rfs := newBigDirFileSystem(t, tt.msgSizeThreshold)
+			requireComplete(t, listDir(t, rfs, tt.plus, tt.bufSize, 0), tt.plus)
+		})
+	}
+}
+
+func TestReadDirSeek(t *testing.T) {
+	rfs := newBigDirFileSystem(t, 1<<20)
+	all := listDir(t, rfs, false, 2048, 0)
+	requireComplete(t, all, false)
+
+	// seekdir(telldir()) on a fresh handle resumes right after the entry
+	// the offset was taken from.
+	for _, i := range []int{0, 1, 999, bigDirSize / 2, bigDirSize - 2} {
+		rest := listDir(t, rfs, false, 512, all[i].off)
+		require.Equal(t, all[i+1:], rest, "seek to %d", all[i].off)
+	}
+	require.Empty(t, listDir(t, rfs, false, 512, all[bigDirSize-1].off))
+}
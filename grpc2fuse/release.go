@@ -24,6 +24,16 @@ import (
 
 func (fs *fileSystem) Release(cancel <-chan struct{}, in *fuse.ReleaseIn) {
 	ctx := newContext(cancel)
+	if _, snapshot := fs.smallFiles.opened(in.Fh); snapshot {
+		fh := fs.smallFiles.release(in.Fh)
+		if fh == 0 {
+			return
+		}
+		released := *in
+		released.Fh = fh
+		in = &released
+	}
+	fs.released(in.Fh)
 
 	if _, err := fs.client.Release(ctx, &pb.ReleaseRequest{
 		Header:       toPbHeader(&in.InHeader),
@@ -35,3 +45,11 @@ func (fs *fileSystem) Release(cancel <-chan struct{}, in *fuse.ReleaseIn) {
 		dealGrpcError("Release", err)
 	}
 }
+
+// released forgets the handle fh of the server.
+func (fs *fileSystem) released(fh uint64) {
+	fs.writeBehind.release(fh)
+	fs.inlined.release(fh)
+	fs.readAhead.release(fh)
+	fs.diskCache.release(fh)
+}
@@ -13,12 +13,12 @@ import (
 )
 
 // Mock RawFileSystemClient
-type mockRawFileSystemClient struct {
+type releaseClient struct {
 	mock.Mock
 	pb.RawFileSystemClient
 }
 
-func (m *mockRawFileSystemClient) Release(ctx context.Context, in *pb.ReleaseRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+func (m *releaseClient) Release(ctx context.Context, in *pb.ReleaseRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	args := m.Called(ctx, in, opts)
 	return args.Get(0).(*emptypb.Empty), args.Error(1)
 }
@@ -28,7 +28,7 @@ func TestFileSystem_Release(t *testing.T) {
 		name    string
 		in      *fuse.ReleaseIn
 		wantErr bool
-		mockFn  func(*mockRawFileSystemClient)
+		mockFn  func(*releaseClient)
 	}{
 		{
 			name: "successful release",
@@ -42,7 +42,7 @@ func TestFileSystem_Release(t *testing.T) {
 				LockOwner:    0,
 			},
 			wantErr: false,
-			mockFn: func(m *mockRawFileSystemClient) {
+			mockFn: func(m *releaseClient) {
 				m.On("Release", mock.Anything, mock.MatchedBy(func(req *pb.ReleaseRequest) bool {
 					return req.Header.NodeId == 1 &&
 						req.Fh == 123 &&
@@ -61,7 +61,7 @@ func TestFileSystem_Release(t *testing.T) {
 				Fh: 456,
 			},
 			wantErr: true,
-			mockFn: func(m *mockRawFileSystemClient) {
+			mockFn: func(m *releaseClient) {
 				m.On("Release", mock.Anything, mock.Anything, mock.Anything).Return(&emptypb.Empty{}, errors.New("release error"))
 			},
 		},
@@ -77,7 +77,7 @@ func TestFileSystem_Release(t *testing.T) {
 				LockOwner:    1000,
 			},
 			wantErr: false,
-			mockFn: func(m *mockRawFileSystemClient) {
+			mockFn: func(m *releaseClient) {
 				m.On("Release", mock.Anything, mock.MatchedBy(func(req *pb.ReleaseRequest) bool {
 					return req.Header.NodeId == 3 &&
 						req.Fh == 789 &&
@@ -91,7 +91,7 @@ func TestFileSystem_Release(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockClient := &mockRawFileSystemClient{}
+			mockClient := &releaseClient{}
 			if tt.mockFn != nil {
 				tt.mockFn(mockClient)
 			}
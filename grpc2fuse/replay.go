@@ -0,0 +1,645 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc2fuse
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/chiyutianyi/grpcfuse/journal"
+)
+
+// replayChunk is the size of the writes replaying a put.
+const replayChunk = 128 << 10
+
+var errUnreachable = errors.New("server unreachable")
+
+// replayer sends the journal to the server, resolving the conflicts it
+// meets.
+type replayer struct {
+	o   *offlineFS
+	now time.Time
+	// produced are the versions the replay left its paths in, nil for
+	// removed; the operations after the first on a path are checked
+	// against them rather than their base.
+	produced map[string]*journal.Version
+	// written are the data put on the server.
+	written map[string]bool
+	// stale are the directories whose listings may be wrong after a
+	// conflict.
+	stale   map[string]bool
+	lookups map[uint64]uint64
+}
+
+// replay sends the changes made offline to the server, in order, and
+// then refreshes what the cache has of the paths they changed; jmu is
+// held. It stops with errUnreachable once the server is, leaving the
+// rest for the next time.
+func (o *offlineFS) replay() error {
+	if o.journal == nil {
+		return nil
+	}
+	r := &replayer{
+		o:        o,
+		now:      time.Now(),
+		produced: map[string]*journal.Version{},
+		written:  map[string]bool{},
+		stale:    map[string]bool{},
+		lookups:  map[uint64]uint64{},
+	}
+	defer r.forget()
+	ops := o.journal.Pending()
+	if len(ops) > 0 {
+		log.Infof("Replaying %d changes made offline", len(ops))
+	}
+	defer func() {
+		if err := o.journal.Compact(); err != nil {
+			log.Warnf("Compacting the journal: %v", err)
+		}
+	}()
+	for _, op := range ops {
+		if err := r.apply(op); err != nil {
+			return err
+		}
+	}
+	if err := r.refresh(); err != nil {
+		return err
+	}
+	if err := o.journal.Prune(nil); err != nil {
+		log.Warnf("Pruning the journal: %v", err)
+	}
+	return nil
+}
+
+// replayNow replays what was left in the journal by an earlier mount,
+// if the server is reachable.
+func (o *offlineFS) replayNow() {
+	if o == nil {
+		return
+	}
+	o.jmu.Lock()
+	defer o.jmu.Unlock()
+	if err := o.replay(); err != nil {
+		log.Warnf("Replaying the changes made offline: %v", err)
+	}
+}
+
+func (r *replayer) forget() {
+	for node, n := range r.lookups {
+		r.o.RawFileSystem.Forget(node, n)
+	}
+}
+
+func (r *replayer) header(op *journal.Op, node uint64) fuse.InHeader {
+	return fuse.InHeader{NodeId: node, Caller: fuse.Caller{Owner: fuse.Owner{Uid: op.Uid, Gid: op.Gid}}}
+}
+
+// walk looks p up on the server, returning its node and attributes, or
+// a node of 0 if it does not exist.
+func (r *replayer) walk(op *journal.Op, p string) (uint64, *fuse.Attr, fuse.Status) {
+	node := uint64(fuse.FUSE_ROOT_ID)
+	var attr *fuse.Attr
+	for _, name := range strings.Split(p, "/") {
+		if name == "" {
+			continue
+		}
+		var out fuse.EntryOut
+		header := r.header(op, node)
+		st := r.o.RawFileSystem.Lookup(nil, &header, name, &out)
+		if st == fuse.ENOENT || st == fuse.OK && out.NodeId == 0 {
+			return 0, nil, fuse.OK
+		}
+		if st != fuse.OK {
+			return 0, nil, st
+		}
+		r.lookups[out.NodeId]++
+		node, attr = out.NodeId, &out.Attr
+	}
+	return node, attr, fuse.OK
+}
+
+// parent looks up the directory of p, which must exist.
+func (r *replayer) parent(op *journal.Op, p string) (uint64, fuse.Status) {
+	node, attr, st := r.walk(op, path.Dir(p))
+	switch {
+	case st != fuse.OK:
+		return 0, st
+	case node == 0:
+		return 0, fuse.ENOENT
+	case attr != nil && !attr.IsDir():
+		return 0, fuse.ENOTDIR
+	}
+	return node, fuse.OK
+}
+
+// expected is the version p is expected in on the server.
+func (r *replayer) expected(p string, base *journal.Version) *journal.Version {
+	if v, ok := r.produced[p]; ok {
+		return v
+	}
+	return base
+}
+
+// differs reports whether cur is not the version expected; directories
+// change with what is in them, so only their inode counts.
+func differs(expected *journal.Version, cur *fuse.Attr) bool {
+	if expected == nil || cur == nil {
+		return (expected == nil) != (cur == nil)
+	}
+	if cur.IsDir() {
+		return expected.Ino != cur.Ino
+	}
+	return *expected != *version(cur)
+}
+
+// stat keeps the version p is in now.
+func (r *replayer) stat(op *journal.Op, p string) {
+	if _, attr, st := r.walk(op, p); st == fuse.OK && attr != nil {
+		r.produced[p] = version(attr)
+	} else {
+		r.produced[p] = nil
+	}
+}
+
+// done drops op from the journal, recording the conflict it met if
+// reason is set.
+func (r *replayer) done(op *journal.Op, reason, resolution string) error {
+	var err error
+	if reason == "" {
+		err = r.o.journal.Done(op.ID)
+	} else {
+		log.Warnf("Conflict replaying %s: %s; %s", op, reason, resolution)
+		r.stale[path.Dir(op.Path)] = true
+		if op.To != "" {
+			r.stale[path.Dir(op.To)] = true
+		}
+		err = r.o.journal.Conflict(journal.Conflict{Op: *op, Reason: reason, Resolution: resolution, Time: r.now})
+	}
+	if err != nil {
+		// It is replayed again next time, which beats stopping here.
+		log.Warnf("Replaying %s: %v", op, err)
+	}
+	return nil
+}
+
+// drop drops op for reason.
+func (r *replayer) drop(op *journal.Op, reason string) error {
+	return r.done(op, reason, "")
+}
+
+// failed drops op after the server failed it with st, unless the server
+// is unreachable.
+func (r *replayer) failed(op *journal.Op, st fuse.Status) error {
+	if !r.o.reachable() {
+		return errUnreachable
+	}
+	return r.drop(op, "failed on the server: "+st.String())
+}
+
+func (r *replayer) apply(op journal.Op) error {
+	node, cur, st := r.walk(&op, op.Path)
+	if st != fuse.OK {
+		return r.failed(&op, st)
+	}
+	changed := differs(r.expected(op.Path, op.Base), cur)
+	switch op.Kind {
+	case journal.Put:
+		return r.put(&op, node, cur, changed)
+	case journal.Mkdir, journal.Symlink:
+		return r.make(&op, cur)
+	case journal.Unlink, journal.Rmdir:
+		return r.remove(&op, cur, changed)
+	case journal.Rename:
+		return r.rename(&op, cur, changed)
+	case journal.SetAttr:
+		return r.setAttr(&op, node, cur, changed)
+	}
+	return r.drop(&op, fmt.Sprintf("unknown operation %q", op.Kind))
+}
+
+// reason says how cur differs from what op expected.
+func (r *replayer) reason(op *journal.Op, p string, cur *fuse.Attr) string {
+	switch {
+	case cur == nil:
+		return p + " is gone from the server"
+	case r.expected(p, op.Base) == nil:
+		return p + " was made on the server too"
+	}
+	return p + " changed on the server"
+}
+
+// aside moves what is at p on the server to a name of its own.
+func (r *replayer) aside(op *journal.Op, p string) (string, fuse.Status) {
+	parent, st := r.parent(op, p)
+	if st != fuse.OK {
+		return "", st
+	}
+	name := path.Base(p)
+	ext := path.Ext(name)
+	if ext == name {
+		ext = ""
+	}
+	stem := strings.TrimSuffix(name, ext) + ".conflict-" + r.now.Format("20060102-150405")
+	for i := 1; ; i++ {
+		aside := stem + ext
+		if i > 1 {
+			aside = fmt.Sprintf("%s-%d%s", stem, i, ext)
+		}
+		node, _, st := r.walk(op, path.Join(path.Dir(p), aside))
+		if st != fuse.OK {
+			return "", st
+		}
+		if node != 0 {
+			continue
+		}
+		header := r.header(op, parent)
+		if st := r.o.RawFileSystem.Rename(nil, &fuse.RenameIn{InHeader: header, Newdir: parent}, name, aside); st != fuse.OK {
+			return "", st
+		}
+		aside = path.Join(path.Dir(p), aside)
+		r.stat(op, aside)
+		return aside, fuse.OK
+	}
+}
+
+// makeWay clears p of cur, found on the server where op expected
+// something else, by the policy. It returns the resolution, or false if
+// op is dropped.
+func (r *replayer) makeWay(op *journal.Op, p string, cur *fuse.Attr, reason string) (string, bool, error) {
+	switch r.o.conflicts {
+	case KeepBoth:
+		aside, st := r.aside(op, p)
+		if st != fuse.OK {
+			return "", false, r.failed(op, st)
+		}
+		return "kept the server's as " + aside, true, nil
+	case LastWriterWins:
+		if cur.IsDir() {
+			return "", false, r.drop(op, reason+", as a directory")
+		}
+		parent, st := r.parent(op, p)
+		if st == fuse.OK {
+			header := r.header(op, parent)
+			st = r.o.RawFileSystem.Unlink(nil, &header, path.Base(p))
+		}
+		if st != fuse.OK {
+			return "", false, r.failed(op, st)
+		}
+		return "removed the server's", true, nil
+	}
+	return "", false, r.drop(op, reason)
+}
+
+// put writes the data of op to the file at its path, node, making it if
+// needed.
+func (r *replayer) put(op *journal.Op, node uint64, cur *fuse.Attr, changed bool) error {
+	var reason, resolution string
+	if changed {
+		reason = r.reason(op, op.Path, cur)
+		switch {
+		case cur == nil && r.o.conflicts != FailConflicts:
+			resolution = "made it again"
+		case cur != nil && cur.IsDir():
+			return r.drop(op, reason+", as a directory")
+		case cur != nil && r.o.conflicts == LastWriterWins:
+			resolution = "overwrote the server's"
+		default:
+			var ok bool
+			var err error
+			if resolution, ok, err = r.makeWay(op, op.Path, cur, reason); !ok {
+				return err
+			}
+			node = 0
+		}
+	}
+
+	data, err := os.Open(r.o.journal.DataPath(op.Data))
+	if err != nil {
+		return r.drop(op, err.Error())
+	}
+	defer data.Close()
+	var fh uint64
+	if node == 0 {
+		parent, st := r.parent(op, op.Path)
+		if st != fuse.OK {
+			return r.failed(op, st)
+		}
+		var out fuse.CreateOut
+		header := r.header(op, parent)
+		in := &fuse.CreateIn{InHeader: header, Flags: syscall.O_WRONLY | syscall.O_CREAT | syscall.O_EXCL, Mode: syscall.S_IFREG | op.Mode}
+		if st := r.o.RawFileSystem.Create(nil, in, path.Base(op.Path), &out); st != fuse.OK {
+			return r.failed(op, st)
+		}
+		r.lookups[out.NodeId]++
+		node, fh = out.NodeId, out.Fh
+	} else {
+		var out fuse.OpenOut
+		header := r.header(op, node)
+		if st := r.o.RawFileSystem.Open(nil, &fuse.OpenIn{InHeader: header, Flags: syscall.O_WRONLY | syscall.O_TRUNC}, &out); st != fuse.OK {
+			return r.failed(op, st)
+		}
+		fh = out.Fh
+	}
+	header := r.header(op, node)
+	st := r.copy(data, header, fh)
+	if st == fuse.OK {
+		st = r.o.RawFileSystem.Flush(nil, &fuse.FlushIn{InHeader: header, Fh: fh})
+	}
+	r.o.RawFileSystem.Release(nil, &fuse.ReleaseIn{InHeader: header, Fh: fh})
+	if st != fuse.OK {
+		return r.failed(op, st)
+	}
+	r.written[op.Data] = true
+	r.stat(op, op.Path)
+	return r.done(op, reason, resolution)
+}
+
+// copy writes data to the handle fh.
+func (r *replayer) copy(data io.ReaderAt, header fuse.InHeader, fh uint64) fuse.Status {
+	buf := make([]byte, replayChunk)
+	for off := int64(0); ; {
+		n, err := data.ReadAt(buf, off)
+		if n > 0 {
+			in := &fuse.WriteIn{InHeader: header, Fh: fh, Offset: uint64(off), Size: uint32(n)}
+			written, st := r.o.RawFileSystem.Write(nil, in, buf[:n])
+			if st != fuse.OK {
+				return st
+			}
+			if int(written) != n {
+				return fuse.EIO
+			}
+			off += int64(n)
+		}
+		if err == io.EOF {
+			return fuse.OK
+		}
+		if err != nil {
+			log.Warnf("Reading the data put on node %d: %v", header.NodeId, err)
+			return fuse.EIO
+		}
+	}
+}
+
+// make makes the directory or symlink of op.
+func (r *replayer) make(op *journal.Op, cur *fuse.Attr) error {
+	var reason, resolution string
+	if cur != nil {
+		if op.Kind == journal.Mkdir && cur.IsDir() {
+			// Made on both sides: what is in it comes from both too.
+			r.stale[op.Path] = true
+			r.produced[op.Path] = version(cur)
+			return r.done(op, "", "")
+		}
+		reason = r.reason(op, op.Path, cur)
+		var ok bool
+		var err error
+		if resolution, ok, err = r.makeWay(op, op.Path, cur, reason); !ok {
+			return err
+		}
+	}
+	parent, st := r.parent(op, op.Path)
+	if st != fuse.OK {
+		return r.failed(op, st)
+	}
+	header := r.header(op, parent)
+	var out fuse.EntryOut
+	if op.Kind == journal.Mkdir {
+		st = r.o.RawFileSystem.Mkdir(nil, &fuse.MkdirIn{InHeader: header, Mode: op.Mode}, path.Base(op.Path), &out)
+	} else {
+		st = r.o.RawFileSystem.Symlink(nil, &header, op.Target, path.Base(op.Path), &out)
+	}
+	if st != fuse.OK {
+		return r.failed(op, st)
+	}
+	r.lookups[out.NodeId]++
+	r.produced[op.Path] = version(&out.Attr)
+	return r.done(op, reason, resolution)
+}
+
+// remove removes the file or empty directory of op. What is gone already
+// is no conflict.
+func (r *replayer) remove(op *journal.Op, cur *fuse.Attr, changed bool) error {
+	if cur == nil {
+		r.produced[op.Path] = nil
+		return r.done(op, "", "")
+	}
+	var reason, resolution string
+	switch {
+	case op.Kind == journal.Unlink && cur.IsDir():
+		return r.drop(op, op.Path+" is a directory on the server")
+	case op.Kind == journal.Rmdir && !cur.IsDir():
+		return r.drop(op, op.Path+" is not a directory on the server")
+	case changed && op.Kind == journal.Unlink:
+		reason = r.reason(op, op.Path, cur)
+		switch r.o.conflicts {
+		case KeepBoth:
+			r.produced[op.Path] = version(cur)
+			return r.done(op, reason, "kept the server's")
+		case LastWriterWins:
+			resolution = "removed the server's"
+		default:
+			return r.drop(op, reason)
+		}
+	}
+	parent, st := r.parent(op, op.Path)
+	if st != fuse.OK {
+		return r.failed(op, st)
+	}
+	header := r.header(op, parent)
+	if op.Kind == journal.Rmdir {
+		st = r.o.RawFileSystem.Rmdir(nil, &header, path.Base(op.Path))
+		if st == fuse.Status(syscall.ENOTEMPTY) {
+			r.produced[op.Path] = version(cur)
+			return r.done(op, op.Path+" is not empty on the server", "kept the server's")
+		}
+	} else {
+		st = r.o.RawFileSystem.Unlink(nil, &header, path.Base(op.Path))
+	}
+	if st != fuse.OK {
+		return r.failed(op, st)
+	}
+	r.produced[op.Path] = nil
+	return r.done(op, reason, resolution)
+}
+
+// rename moves what is at the path of op to To, with what the replay
+// knows of the paths below it.
+func (r *replayer) rename(op *journal.Op, cur *fuse.Attr, changed bool) error {
+	if cur == nil {
+		return r.drop(op, r.reason(op, op.Path, cur))
+	}
+	var reasons, resolutions []string
+	if changed {
+		if r.o.conflicts == FailConflicts {
+			return r.drop(op, r.reason(op, op.Path, cur))
+		}
+		reasons = append(reasons, r.reason(op, op.Path, cur))
+		resolutions = append(resolutions, "moved it anyway")
+	}
+	_, to, st := r.walk(op, op.To)
+	if st != fuse.OK {
+		return r.failed(op, st)
+	}
+	if to != nil && differs(r.expected(op.To, op.ToBase), to) {
+		reason := r.reason(&journal.Op{Base: op.ToBase}, op.To, to)
+		resolution := "replaced the server's"
+		switch r.o.conflicts {
+		case KeepBoth:
+			aside, st := r.aside(op, op.To)
+			if st != fuse.OK {
+				return r.failed(op, st)
+			}
+			resolution = "kept the server's as " + aside
+		case FailConflicts:
+			return r.drop(op, reason)
+		}
+		reasons, resolutions = append(reasons, reason), append(resolutions, resolution)
+	}
+
+	oldParent, st := r.parent(op, op.Path)
+	if st != fuse.OK {
+		return r.failed(op, st)
+	}
+	newParent, st := r.parent(op, op.To)
+	if st != fuse.OK {
+		return r.failed(op, st)
+	}
+	header := r.header(op, oldParent)
+	if st := r.o.RawFileSystem.Rename(nil, &fuse.RenameIn{InHeader: header, Newdir: newParent}, path.Base(op.Path), path.Base(op.To)); st != fuse.OK {
+		return r.failed(op, st)
+	}
+	for p, v := range r.produced {
+		if strings.HasPrefix(p, op.Path+"/") {
+			delete(r.produced, p)
+			r.produced[op.To+strings.TrimPrefix(p, op.Path)] = v
+		}
+	}
+	r.produced[op.Path] = nil
+	r.stat(op, op.To)
+	return r.done(op, strings.Join(reasons, "; "), strings.Join(resolutions, "; "))
+}
+
+// setAttr sets the attributes of op on node.
+func (r *replayer) setAttr(op *journal.Op, node uint64, cur *fuse.Attr, changed bool) error {
+	var reason, resolution string
+	if changed {
+		reason = r.reason(op, op.Path, cur)
+		if cur == nil || r.o.conflicts == FailConflicts {
+			return r.drop(op, reason)
+		}
+		resolution = "set anyway"
+	}
+	in := &fuse.SetAttrIn{SetAttrInCommon: fuse.SetAttrInCommon{
+		InHeader:  r.header(op, node),
+		Valid:     op.Valid,
+		Mode:      cur.Mode&syscall.S_IFMT | op.Mode,
+		Atime:     uint64(op.Atime / 1e9),
+		Atimensec: uint32(op.Atime % 1e9),
+		Mtime:     uint64(op.Mtime / 1e9),
+		Mtimensec: uint32(op.Mtime % 1e9),
+	}}
+	var out fuse.AttrOut
+	if st := r.o.RawFileSystem.SetAttr(nil, in, &out); st != fuse.OK {
+		return r.failed(op, st)
+	}
+	r.produced[op.Path] = version(&out.Attr)
+	return r.done(op, reason, resolution)
+}
+
+// refresh replaces what the cache has of the paths changed offline with
+// what the server has, once the journal is replayed, keeping the data
+// put as the blocks of the files.
+func (r *replayer) refresh() error {
+	op := &journal.Op{}
+	for _, rec := range r.o.meta.changed() {
+		_, attr, st := r.walk(op, rec.Path)
+		if st != fuse.OK && !r.o.reachable() {
+			return errUnreachable
+		}
+		dir, name := path.Dir(rec.Path), path.Base(rec.Path)
+		if st != fuse.OK || attr == nil {
+			r.o.meta.remove(rec.Path)
+			r.o.meta.update(dir, func(d *metaRecord) { d.Entries = withoutEntry(d.Entries, name) })
+			continue
+		}
+		if rec.Data != "" && r.written[rec.Data] {
+			r.cache(rec.Data, attr)
+		}
+		r.o.meta.update(rec.Path, func(m *metaRecord) {
+			m.Attr, m.Changed, m.Base, m.Data = *attr, false, nil, ""
+			for i := range m.Entries {
+				if m.Entries[i].Name == "." {
+					m.Entries[i].Ino = attr.Ino
+				}
+			}
+		})
+		r.o.meta.update(dir, func(d *metaRecord) {
+			for i := range d.Entries {
+				if d.Entries[i].Name == name {
+					d.Entries[i].Ino, d.Entries[i].Mode = attr.Ino, attr.Mode
+				}
+			}
+		})
+	}
+	for dir := range r.stale {
+		if _, ok := r.o.meta.get(dir); ok {
+			r.o.meta.unlist(dir)
+		}
+	}
+	return nil
+}
+
+// cache keeps data as the blocks of the version attr of its file.
+func (r *replayer) cache(data string, attr *fuse.Attr) {
+	dc := r.o.fs.diskCache
+	if dc == nil || !attr.IsRegular() {
+		return
+	}
+	f, err := os.Open(r.o.journal.DataPath(data))
+	if err != nil {
+		log.Warnf("Caching the data put: %v", err)
+		return
+	}
+	defer f.Close()
+	if fi, err := f.Stat(); err != nil || uint64(fi.Size()) != attr.Size {
+		// Changed on the server since.
+		return
+	}
+	key, bs := cacheKey(attr), dc.cache.BlockSize()
+	for index := uint64(0); ; index++ {
+		block := make([]byte, bs)
+		n, err := io.ReadFull(f, block)
+		if n > 0 {
+			if err := dc.cache.Put(key, index, block[:n]); err != nil {
+				log.Warnf("Caching the data put: %v", err)
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
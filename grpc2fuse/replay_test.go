@@ -0,0 +1,146 @@
+package grpc2fuse
+
+import (
+	"io/fs"
+	"sort"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chiyutianyi/grpcfuse/fsclient"
+)
+
+func TestReplayConflicts(t *testing.T) {
+	tests := []struct {
+		policy ConflictPolicy
+		// files are what the server has of d after the replay, the
+		// conflict copies by their prefix.
+		files       map[string]string
+		resolutions []string
+		kept        int
+	}{
+		{
+			policy: KeepBoth,
+			files: map[string]string{
+				"f": "ours", "f.conflict-": "theirs",
+				"n.txt": "mine", "n.conflict-": "their n",
+				"u": "changed",
+				"s": "s2",
+			},
+			resolutions: []string{"kept the server's as /d/f.conflict-", "kept the server's", "kept the server's as /d/n.conflict-", "made it again"},
+		},
+		{
+			policy:      LastWriterWins,
+			files:       map[string]string{"f": "ours", "n.txt": "mine", "s": "s2"},
+			resolutions: []string{"overwrote the server's", "removed the server's", "overwrote the server's", "made it again"},
+		},
+		{
+			policy:      FailConflicts,
+			files:       map[string]string{"f": "theirs", "n.txt": "their n", "u": "changed"},
+			resolutions: []string{"", "", "", ""},
+			kept:        3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			var server outage
+			o, c := journaled(t, tt.policy, &server)
+			require.NoError(t, c.Mkdir("d", 0755))
+			writeRemote(t, c, "d/f", "base")
+			writeRemote(t, c, "d/u", "u")
+			writeRemote(t, c, "d/s", "s")
+
+			var attr fuse.AttrOut
+			require.Equal(t, fuse.OK, o.GetAttr(nil, &fuse.GetAttrIn{InHeader: fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}}, &attr))
+			d, f, s := lookupPath(t, o, "d"), lookupPath(t, o, "d", "f"), lookupPath(t, o, "d", "s")
+			lookupPath(t, o, "d", "u")
+			assert.Equal(t, "base", readNode(t, o, f))
+			assert.Equal(t, "s", readNode(t, o, s))
+
+			goOffline(t, o, &server)
+			dh := fuse.InHeader{NodeId: d}
+			writeNode(t, o, f, syscall.O_WRONLY|syscall.O_TRUNC, 0, "ours")
+			require.Equal(t, fuse.OK, o.Unlink(nil, &dh, "u"))
+			var created fuse.CreateOut
+			require.Equal(t, fuse.OK, o.Create(nil, &fuse.CreateIn{InHeader: dh, Flags: syscall.O_WRONLY, Mode: 0644}, "n.txt", &created))
+			_, st := o.Write(nil, &fuse.WriteIn{InHeader: fuse.InHeader{NodeId: created.NodeId}, Fh: created.Fh, Size: 4}, []byte("mine"))
+			require.Equal(t, fuse.OK, st)
+			o.Release(nil, &fuse.ReleaseIn{InHeader: fuse.InHeader{NodeId: created.NodeId}, Fh: created.Fh})
+			writeNode(t, o, s, syscall.O_WRONLY|syscall.O_TRUNC, 0, "s2")
+
+			// Meanwhile, on the server.
+			writeRemote(t, c, "d/f", "theirs")
+			writeRemote(t, c, "d/u", "changed")
+			writeRemote(t, c, "d/n.txt", "their n")
+			require.NoError(t, c.Remove("d/s"))
+
+			server.set(false)
+			require.Eventually(t, func() bool { return !o.isOffline() }, 5*time.Second, 10*time.Millisecond)
+			assert.Equal(t, tt.files, remoteFiles(t, c, "d"))
+			assert.Empty(t, o.journal.Pending())
+			conflicts, err := o.journal.Conflicts()
+			require.NoError(t, err)
+			var resolutions []string
+			for _, c := range conflicts {
+				resolutions = append(resolutions, conflictPrefix(c.Resolution))
+			}
+			assert.Equal(t, tt.resolutions, resolutions)
+			assert.Contains(t, conflicts[0].Reason, "/d/f changed on the server")
+			assert.Contains(t, conflicts[2].Reason, "/d/n.txt was made on the server too")
+			assert.Contains(t, conflicts[3].Reason, "/d/s is gone from the server")
+
+			// The data of the puts dropped stays for the conflicts.
+			require.NoError(t, o.journal.Prune(nil))
+			var kept int
+			for _, c := range conflicts {
+				if c.Op.Data != "" && c.Resolution == "" {
+					kept++
+					assert.FileExists(t, o.journal.DataPath(c.Op.Data))
+				}
+			}
+			assert.Equal(t, tt.kept, kept)
+
+			// The listing after conflicts is read again, and kept.
+			listNames(t, o, d, true)
+			goOffline(t, o, &server)
+			names := listNames(t, o, d, false)
+			for name := range tt.files {
+				if !strings.HasSuffix(name, "conflict-") {
+					assert.Contains(t, names, name)
+				}
+			}
+			if _, ok := tt.files["u"]; !ok {
+				assert.NotContains(t, names, "u")
+			}
+		})
+	}
+}
+
+// conflictPrefix cuts the time off the names of conflict copies.
+func conflictPrefix(s string) string {
+	if i := strings.Index(s, ".conflict-"); i >= 0 {
+		return s[:i+len(".conflict-")]
+	}
+	return s
+}
+
+// remoteFiles returns the content of the files in dir on the server.
+func remoteFiles(t *testing.T, c *fsclient.Client, dir string) map[string]string {
+	entries, err := c.ReadDir(dir)
+	require.NoError(t, err)
+	files := map[string]string{}
+	var names []string
+	for _, e := range entries {
+		require.Equal(t, fs.FileMode(0), e.Type()&fs.ModeType, e.Name())
+		names = append(names, e.Name())
+		files[conflictPrefix(e.Name())] = readRemote(t, c, dir+"/"+e.Name())
+	}
+	sort.Strings(names)
+	assert.Len(t, files, len(names), "one conflict copy of each: %v", names)
+	return files
+}
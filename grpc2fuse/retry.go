@@ -0,0 +1,86 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc2fuse
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// retryable are the methods that change nothing on the server, so making
+// them twice does no harm. Lookup and ReadDirPlus are left out as they
+// add to the lookup counts of nodes.
+var retryable = map[string]bool{
+	"String":    true,
+	"GetAttr":   true,
+	"Access":    true,
+	"Readlink":  true,
+	"GetXAttr":  true,
+	"ListXAttr": true,
+	"Read":      true,
+	"Lseek":     true,
+	"GetLk":     true,
+	"ReadDir":   true,
+	"StatFs":    true,
+}
+
+// retry calls call until it succeeds, fails with another code than
+// Unavailable, or the retries of the mount are used up.
+func (m *Mounted) retry(ctx context.Context, method string, call func() error) error {
+	err := call()
+	if err == nil || !retryable[methodName(method)] {
+		return err
+	}
+	opts := m.options()
+	backoff := opts.RetryBackoff
+	for i := 0; i < opts.Retries && status.Code(err) == codes.Unavailable; i++ {
+		log.Debugf("%s: %v, retrying in %v", method, err, backoff)
+		t := time.NewTimer(backoff)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		}
+		backoff *= 2
+		m.stats.retried(method)
+		err = call()
+	}
+	return err
+}
+
+func (m *Mounted) unaryRetryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return m.retry(ctx, method, func() error {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	})
+}
+
+// streamRetryInterceptor retries starting a stream; a stream that fails
+// once started is not made again.
+func (m *Mounted) streamRetryInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	var stream grpc.ClientStream
+	err := m.retry(ctx, method, func() (err error) {
+		stream, err = streamer(ctx, desc, cc, method, opts...)
+		return err
+	})
+	return stream, err
+}
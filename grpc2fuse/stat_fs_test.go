@@ -11,12 +11,12 @@ import (
 	"google.golang.org/grpc"
 )
 
-type MockRawFileSystemClient struct {
+type statFsClient struct {
 	mock.Mock
 	pb.RawFileSystemClient
 }
 
-func (m *MockRawFileSystemClient) StatFs(ctx context.Context, in *pb.StatfsRequest, opts ...grpc.CallOption) (*pb.StatfsResponse, error) {
+func (m *statFsClient) StatFs(ctx context.Context, in *pb.StatfsRequest, opts ...grpc.CallOption) (*pb.StatfsResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -27,14 +27,14 @@ func (m *MockRawFileSystemClient) StatFs(ctx context.Context, in *pb.StatfsReque
 func TestFileSystem_StatFs(t *testing.T) {
 	tests := []struct {
 		name     string
-		setup    func(*MockRawFileSystemClient)
+		setup    func(*statFsClient)
 		input    *fuse.InHeader
 		wantCode fuse.Status
 		wantOut  *fuse.StatfsOut
 	}{
 		{
 			name: "successful statfs",
-			setup: func(m *MockRawFileSystemClient) {
+			setup: func(m *statFsClient) {
 				m.On("StatFs", mock.Anything, mock.MatchedBy(func(req *pb.StatfsRequest) bool {
 					return req.Input.Length == 1 &&
 						req.Input.Opcode == 2 &&
@@ -72,7 +72,7 @@ func TestFileSystem_StatFs(t *testing.T) {
 		},
 		{
 			name: "failed statfs with error code",
-			setup: func(m *MockRawFileSystemClient) {
+			setup: func(m *statFsClient) {
 				m.On("StatFs", mock.Anything, mock.Anything, mock.Anything).Return(&pb.StatfsResponse{
 					Status: &pb.Status{Code: int32(fuse.ENOENT)},
 				}, nil)
@@ -85,7 +85,7 @@ func TestFileSystem_StatFs(t *testing.T) {
 		},
 		{
 			name: "grpc error",
-			setup: func(m *MockRawFileSystemClient) {
+			setup: func(m *statFsClient) {
 				m.On("StatFs", mock.Anything, mock.Anything, mock.Anything).Return(nil, grpc.ErrServerStopped)
 			},
 			input: &fuse.InHeader{
@@ -98,7 +98,7 @@ func TestFileSystem_StatFs(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockClient := &MockRawFileSystemClient{}
+			mockClient := &statFsClient{}
 			if tt.setup != nil {
 				tt.setup(mockClient)
 			}
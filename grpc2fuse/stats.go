@@ -0,0 +1,152 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc2fuse
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/chiyutianyi/grpcfuse/blockcache"
+	"github.com/chiyutianyi/grpcfuse/shm"
+)
+
+// MethodStats counts the calls of a method.
+type MethodStats struct {
+	Calls uint64
+	// Errors are the calls that failed in gRPC. Calls answered with an
+	// errno are not errors.
+	Errors  uint64
+	Retries uint64
+}
+
+// Stats are the calls a mount has made, by method name such as "Read".
+type Stats struct {
+	Mountpoint string
+	Target     string
+	Since      time.Time
+	Methods    map[string]MethodStats
+	// SharedMemory counts the payloads of a mount with SharedMemory.
+	SharedMemory shm.Stats
+	// Cache is the disk cache of a mount with CacheDir.
+	Cache blockcache.Stats
+	// Offline is set while a mount with Offline answers from its cache.
+	Offline bool
+	// AttrCache counts the answers of the AttrCache of a mount.
+	AttrCache AttrCacheStats
+}
+
+// Total adds up the calls of all methods.
+func (s Stats) Total() MethodStats {
+	var total MethodStats
+	for _, ms := range s.Methods {
+		total.Calls += ms.Calls
+		total.Errors += ms.Errors
+		total.Retries += ms.Retries
+	}
+	return total
+}
+
+type callStats struct {
+	since   time.Time
+	mu      sync.Mutex
+	methods map[string]*MethodStats
+}
+
+func newCallStats() *callStats {
+	return &callStats{since: time.Now(), methods: map[string]*MethodStats{}}
+}
+
+// methodName turns "/pb.RawFileSystem/Read" into "Read".
+func methodName(method string) string {
+	return method[strings.LastIndex(method, "/")+1:]
+}
+
+func (s *callStats) add(method string, f func(ms *MethodStats)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	name := methodName(method)
+	ms, ok := s.methods[name]
+	if !ok {
+		ms = &MethodStats{}
+		s.methods[name] = ms
+	}
+	f(ms)
+}
+
+func (s *callStats) done(method string, err error) {
+	s.add(method, func(ms *MethodStats) {
+		ms.Calls++
+		if err != nil {
+			ms.Errors++
+		}
+	})
+}
+
+func (s *callStats) retried(method string) {
+	s.add(method, func(ms *MethodStats) { ms.Retries++ })
+}
+
+func (s *callStats) snapshot() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	methods := make(map[string]MethodStats, len(s.methods))
+	for name, ms := range s.methods {
+		methods[name] = *ms
+	}
+	return Stats{Since: s.since, Methods: methods}
+}
+
+func (s *callStats) unaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	s.done(method, err)
+	return err
+}
+
+// streamInterceptor counts a stream when it fails to start or ends.
+func (s *callStats) streamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		s.done(method, err)
+		return nil, err
+	}
+	return &countedStream{ClientStream: stream, stats: s, method: method}, nil
+}
+
+type countedStream struct {
+	grpc.ClientStream
+	stats  *callStats
+	method string
+	once   sync.Once
+}
+
+func (cs *countedStream) RecvMsg(m interface{}) error {
+	err := cs.ClientStream.RecvMsg(m)
+	if err != nil {
+		cs.once.Do(func() {
+			if err == io.EOF {
+				err = nil
+			}
+			cs.stats.done(cs.method, err)
+		})
+	}
+	return err
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc2fuse
+
+import (
+	"github.com/hanwen/go-fuse/v2/fuse"
+)
+
+// timeoutFS replaces the cache timeouts of the replies of the server with
+// those of the mount.
+type timeoutFS struct {
+	fuse.RawFileSystem
+	m *Mounted
+}
+
+func (fs *timeoutFS) entry(out *fuse.EntryOut) {
+	opts := fs.m.options()
+	if opts.EntryTimeout > 0 {
+		out.SetEntryTimeout(opts.EntryTimeout)
+	}
+	if opts.AttrTimeout > 0 {
+		out.SetAttrTimeout(opts.AttrTimeout)
+	}
+}
+
+func (fs *timeoutFS) attr(out *fuse.AttrOut) {
+	if opts := fs.m.options(); opts.AttrTimeout > 0 {
+		out.SetTimeout(opts.AttrTimeout)
+	}
+}
+
+// Lookup turns ENOENT into an entry without a node, which the kernel
+// caches for NegativeTimeout.
+func (fs *timeoutFS) Lookup(cancel <-chan struct{}, header *fuse.InHeader, name string, out *fuse.EntryOut) fuse.Status {
+	st := fs.RawFileSystem.Lookup(cancel, header, name, out)
+	if st == fuse.ENOENT {
+		if timeout := fs.m.options().NegativeTimeout; timeout > 0 {
+			*out = fuse.EntryOut{}
+			out.SetEntryTimeout(timeout)
+			return fuse.OK
+		}
+	}
+	if st == fuse.OK {
+		fs.entry(out)
+	}
+	return st
+}
+
+func (fs *timeoutFS) GetAttr(cancel <-chan struct{}, input *fuse.GetAttrIn, out *fuse.AttrOut) fuse.Status {
+	st := fs.RawFileSystem.GetAttr(cancel, input, out)
+	if st == fuse.OK {
+		fs.attr(out)
+	}
+	return st
+}
+
+func (fs *timeoutFS) SetAttr(cancel <-chan struct{}, input *fuse.SetAttrIn, out *fuse.AttrOut) fuse.Status {
+	st := fs.RawFileSystem.SetAttr(cancel, input, out)
+	if st == fuse.OK {
+		fs.attr(out)
+	}
+	return st
+}
+
+func (fs *timeoutFS) Mknod(cancel <-chan struct{}, input *fuse.MknodIn, name string, out *fuse.EntryOut) fuse.Status {
+	st := fs.RawFileSystem.Mknod(cancel, input, name, out)
+	if st == fuse.OK {
+		fs.entry(out)
+	}
+	return st
+}
+
+func (fs *timeoutFS) Mkdir(cancel <-chan struct{}, input *fuse.MkdirIn, name string, out *fuse.EntryOut) fuse.Status {
+	st := fs.RawFileSystem.Mkdir(cancel, input, name, out)
+	if st == fuse.OK {
+		fs.entry(out)
+	}
+	return st
+}
+
+func (fs *timeoutFS) Link(cancel <-chan struct{}, input *fuse.LinkIn, filename string, out *fuse.EntryOut) fuse.Status {
+	st := fs.RawFileSystem.Link(cancel, input, filename, out)
+	if st == fuse.OK {
+		fs.entry(out)
+	}
+	return st
+}
+
+func (fs *timeoutFS) Symlink(cancel <-chan struct{}, header *fuse.InHeader, pointedTo string, linkName string, out *fuse.EntryOut) fuse.Status {
+	st := fs.RawFileSystem.Symlink(cancel, header, pointedTo, linkName, out)
+	if st == fuse.OK {
+		fs.entry(out)
+	}
+	return st
+}
+
+func (fs *timeoutFS) Create(cancel <-chan struct{}, input *fuse.CreateIn, name string, out *fuse.CreateOut) fuse.Status {
+	st := fs.RawFileSystem.Create(cancel, input, name, out)
+	if st == fuse.OK {
+		fs.entry(&out.EntryOut)
+	}
+	return st
+}
@@ -24,10 +24,14 @@ import (
 
 func (fs *fileSystem) Write(cancel <-chan struct{}, input *fuse.WriteIn, data []byte) (written uint32, code fuse.Status) {
 	ctx := newContext(cancel)
+	fh, st := fs.handle(ctx, input.Fh)
+	if st != fuse.OK {
+		return 0, st
+	}
 
-	res, err := fs.client.Write(ctx, &pb.WriteRequest{
+	req := &pb.WriteRequest{
 		Header:     toPbHeader(&input.InHeader),
-		Fh:         input.Fh,
+		Fh:         fh,
 		Offset:     input.Offset,
 		Data:       data,
 		Size:       input.Size,
@@ -35,11 +39,9 @@ func (fs *fileSystem) Write(cancel <-chan struct{}, input *fuse.WriteIn, data []
 		LockOwner:  input.LockOwner,
 		Flags:      input.Flags,
 		Padding:    input.Padding,
-	}, fs.opts...)
-
-	if st := dealGrpcError("Write", err); st != fuse.OK {
-		return 0, st
 	}
-
-	return uint32(res.Written), fuse.Status(res.Status.GetCode())
+	if fs.writeBehind != nil {
+		return fs.writeBehind.write(req)
+	}
+	return fs.write(ctx, req)
 }
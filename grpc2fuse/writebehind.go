@@ -0,0 +1,269 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc2fuse
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+// writeBehind answers writes before the server has them. The writes of a
+// handle are queued, adjacent ones merged, and sent in order by one
+// goroutine per handle; the first that fails is reported by the next
+// Flush, Fsync or Release of the handle. Writers wait while more than
+// limit bytes are queued.
+type writeBehind struct {
+	fs              *fileSystem
+	limit, maxMerge int
+
+	mu sync.Mutex
+	// sent is signalled when a write was sent.
+	sent    *sync.Cond
+	dirty   int
+	handles map[uint64]*wbHandle
+}
+
+type wbHandle struct {
+	node  uint64
+	queue []*pb.WriteRequest
+	// sending is set while a goroutine sends the queue.
+	sending bool
+	// held, set for a new small file, keeps the queue until the Flush of
+	// the handle sends it along, while it has at most maxHeldWrites
+	// writes of at most heldSize bytes.
+	held     bool
+	heldSize int
+	err      fuse.Status
+}
+
+// SetWriteBehind makes writes of fs return once queued, with at most
+// limit bytes queued and adjacent writes merged up to maxMerge bytes. A
+// limit of 0 turns write-behind off. It must be called before fs is used.
+func (fs *fileSystem) SetWriteBehind(limit, maxMerge int) {
+	if limit <= 0 {
+		fs.writeBehind = nil
+		return
+	}
+	if maxMerge <= 0 {
+		maxMerge = defaultMaxIO
+	}
+	wb := &writeBehind{fs: fs, limit: limit, maxMerge: maxMerge, handles: map[uint64]*wbHandle{}}
+	wb.sent = sync.NewCond(&wb.mu)
+	fs.writeBehind = wb
+}
+
+// write queues req. It waits while the queues are full, unless they are
+// empty: a write larger than the limit still goes through.
+func (wb *writeBehind) write(req *pb.WriteRequest) (uint32, fuse.Status) {
+	n := len(req.Data)
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	for wb.dirty > 0 && wb.dirty+n > wb.limit {
+		for _, h := range wb.handles {
+			wb.unhold(h)
+		}
+		wb.sent.Wait()
+	}
+	wb.dirty += n
+
+	h := wb.handles[req.Fh]
+	if h == nil {
+		h = &wbHandle{node: req.Header.NodeId}
+		wb.handles[req.Fh] = h
+	}
+	if last := len(h.queue) - 1; last >= 0 && mergeable(h.queue[last], req, wb.maxMerge) {
+		h.queue[last].Data = append(h.queue[last].Data, req.Data...)
+		h.queue[last].Size = uint32(len(h.queue[last].Data))
+	} else {
+		// The kernel reuses the buffer of data once answered.
+		req.Data = append([]byte(nil), req.Data...)
+		h.queue = append(h.queue, req)
+	}
+	if h.held && (len(h.queue) > maxHeldWrites || queued(h.queue) > h.heldSize) {
+		wb.unhold(h)
+	}
+	if !h.sending && !h.held {
+		h.sending = true
+		go wb.send(h)
+	}
+	return uint32(n), fuse.OK
+}
+
+// mergeable tells whether req continues prev and fits in it.
+func mergeable(prev, req *pb.WriteRequest, maxMerge int) bool {
+	return prev.Offset+uint64(len(prev.Data)) == req.Offset &&
+		len(prev.Data)+len(req.Data) <= maxMerge &&
+		prev.WriteFlags == req.WriteFlags && prev.Flags == req.Flags && prev.LockOwner == req.LockOwner
+}
+
+// send sends the queue of h until it is empty.
+func (wb *writeBehind) send(h *wbHandle) {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	for len(h.queue) > 0 {
+		req := h.queue[0]
+		h.queue = h.queue[1:]
+		n := len(req.Data)
+		wb.mu.Unlock()
+		written, st := wb.fs.write(context.Background(), req)
+		if st == fuse.OK && int(written) < n {
+			st = fuse.EIO
+		}
+		wb.mu.Lock()
+		if st != fuse.OK {
+			log.Errorf("Write behind of node %d at %d: %v", req.Header.NodeId, req.Offset, st)
+			if h.err == fuse.OK {
+				h.err = st
+			}
+		}
+		wb.dirty -= n
+		wb.sent.Broadcast()
+	}
+	h.queue = nil
+	h.sending = false
+	wb.sent.Broadcast()
+}
+
+func queued(queue []*pb.WriteRequest) int {
+	var n int
+	for _, req := range queue {
+		n += len(req.Data)
+	}
+	return n
+}
+
+// hold makes the handle fh of node, of a file just created, keep its
+// writes for its Flush while they are at most size bytes.
+func (wb *writeBehind) hold(fh, node uint64, size int) {
+	if wb == nil {
+		return
+	}
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	wb.handles[fh] = &wbHandle{node: node, held: true, heldSize: size}
+}
+
+// unhold sends the writes h held. wb.mu must be held.
+func (wb *writeBehind) unhold(h *wbHandle) {
+	if !h.held {
+		return
+	}
+	h.held = false
+	if len(h.queue) > 0 && !h.sending {
+		h.sending = true
+		go wb.send(h)
+	}
+}
+
+// takeHeld returns the writes held by fh, for its Flush to send along,
+// and whether fh held them. They count as being sent until given back to
+// putBack, which must follow if fh held them.
+func (wb *writeBehind) takeHeld(fh uint64) ([]*pb.WriteRequest, bool) {
+	if wb == nil {
+		return nil, false
+	}
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	h := wb.handles[fh]
+	if h == nil || !h.held {
+		return nil, false
+	}
+	writes := h.queue
+	h.held = false
+	h.queue, h.sending = nil, true
+	return writes, true
+}
+
+// putBack ends the sending of the writes taken from fh; if they were not
+// sent after all, they go first in its queue.
+func (wb *writeBehind) putBack(fh uint64, writes []*pb.WriteRequest, sent bool) {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	h := wb.handles[fh]
+	if sent {
+		wb.dirty -= queued(writes)
+	} else {
+		h.queue = append(writes, h.queue...)
+	}
+	if len(h.queue) > 0 {
+		go wb.send(h)
+	} else {
+		h.sending = false
+	}
+	wb.sent.Broadcast()
+}
+
+// wait waits until h is sent. wb.mu must be held.
+func (wb *writeBehind) wait(h *wbHandle) {
+	for h.sending {
+		wb.sent.Wait()
+	}
+}
+
+// sync waits until the writes to node are sent, before a call that must
+// see them.
+func (wb *writeBehind) sync(node uint64) {
+	if wb == nil {
+		return
+	}
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	for _, h := range wb.handles {
+		if h.node == node {
+			wb.unhold(h)
+			wb.wait(h)
+		}
+	}
+}
+
+// flush waits until the writes of fh are sent and returns the first
+// failure since the last flush.
+func (wb *writeBehind) flush(fh uint64) fuse.Status {
+	if wb == nil {
+		return fuse.OK
+	}
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	h := wb.handles[fh]
+	if h == nil {
+		return fuse.OK
+	}
+	wb.unhold(h)
+	wb.wait(h)
+	st := h.err
+	h.err = fuse.OK
+	return st
+}
+
+// release flushes fh and forgets it. Nothing is left to report its
+// failure to but the log.
+func (wb *writeBehind) release(fh uint64) {
+	if st := wb.flush(fh); st != fuse.OK {
+		log.Errorf("Release of handle %d: write behind failed: %v", fh, st)
+	}
+	if wb == nil {
+		return
+	}
+	wb.mu.Lock()
+	delete(wb.handles, fh)
+	wb.mu.Unlock()
+}
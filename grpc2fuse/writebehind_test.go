@@ -0,0 +1,186 @@
+package grpc2fuse
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/chiyutianyi/grpcfuse/exports"
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+// writeGate holds the writes sent to the server while closed, and fails
+// them while failing is set.
+type writeGate struct {
+	mu      sync.Mutex
+	open    chan struct{}
+	entered chan struct{}
+	failing bool
+	sizes   []int
+}
+
+func newWriteGate() *writeGate {
+	g := &writeGate{open: make(chan struct{}), entered: make(chan struct{}, 100)}
+	close(g.open)
+	return g
+}
+
+func (g *writeGate) close() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.open = make(chan struct{})
+}
+
+func (g *writeGate) release() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	close(g.open)
+}
+
+func (g *writeGate) interceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	w, ok := req.(*pb.WriteRequest)
+	if !ok {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	g.mu.Lock()
+	open, failing := g.open, g.failing
+	g.sizes = append(g.sizes, len(w.Data))
+	g.mu.Unlock()
+	g.entered <- struct{}{}
+	<-open
+	if failing {
+		return status.Error(codes.Internal, "failing")
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+func (g *writeGate) written() []int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]int(nil), g.sizes...)
+}
+
+func newWriteBehindFS(t *testing.T, limit, maxMerge int) (*fileSystem, *writeGate) {
+	g := newWriteGate()
+	dialOpts := append(serve(t), grpc.WithInsecure(), grpc.WithChainUnaryInterceptor(g.interceptor))
+	conn, err := grpc.Dial("bufconn", append(dialOpts, exports.DialOptions("a")...)...)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	fs := NewFileSystem(pb.NewRawFileSystemClient(conn))
+	fs.SetWriteBehind(limit, maxMerge)
+	return fs, g
+}
+
+func TestWriteBehind(t *testing.T) {
+	fs, g := newWriteBehindFS(t, 1<<20, 16)
+	var out fuse.CreateOut
+	require.Equal(t, fuse.OK, fs.Create(nil, &fuse.CreateIn{InHeader: fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}, Mode: 0644}, "file", &out))
+	header := fuse.InHeader{NodeId: out.NodeId}
+	write := func(off uint64, data string) {
+		written, st := fs.Write(nil, &fuse.WriteIn{InHeader: header, Fh: out.Fh, Offset: off, Size: uint32(len(data))}, []byte(data))
+		require.Equal(t, fuse.OK, st)
+		require.Equal(t, uint32(len(data)), written)
+	}
+
+	// The writes queued behind the first are merged.
+	g.close()
+	write(0, "0123")
+	<-g.entered
+	contents := "0123"
+	for i := 1; i < 10; i++ {
+		write(uint64(4*i), "abcd")
+		contents += "abcd"
+	}
+	write(100, "tail")
+
+	// Reads wait for the writes.
+	read := make(chan string)
+	go func() {
+		res, st := fs.Read(nil, &fuse.ReadIn{InHeader: header, Fh: out.Fh, Size: 40}, nil)
+		assert.Equal(t, fuse.OK, st)
+		data, _ := res.Bytes(nil)
+		read <- string(data)
+	}()
+	select {
+	case <-read:
+		t.Fatal("read before the writes were sent")
+	case <-time.After(20 * time.Millisecond):
+	}
+	g.release()
+	assert.Equal(t, contents, <-read)
+	assert.Equal(t, []int{4, 16, 16, 4, 4}, g.written())
+
+	assert.Equal(t, fuse.OK, fs.Flush(nil, &fuse.FlushIn{InHeader: header, Fh: out.Fh}))
+	var attr fuse.AttrOut
+	require.Equal(t, fuse.OK, fs.GetAttr(nil, &fuse.GetAttrIn{InHeader: header}, &attr))
+	assert.Equal(t, uint64(104), attr.Size)
+
+	fs.Release(nil, &fuse.ReleaseIn{InHeader: header, Fh: out.Fh})
+	assert.Empty(t, fs.writeBehind.handles)
+}
+
+func TestWriteBehindErrors(t *testing.T) {
+	fs, g := newWriteBehindFS(t, 1<<20, 0)
+	var out fuse.CreateOut
+	require.Equal(t, fuse.OK, fs.Create(nil, &fuse.CreateIn{InHeader: fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}, Mode: 0644}, "file", &out))
+	header := fuse.InHeader{NodeId: out.NodeId}
+
+	g.failing = true
+	_, st := fs.Write(nil, &fuse.WriteIn{InHeader: header, Fh: out.Fh, Size: 4}, []byte("data"))
+	assert.Equal(t, fuse.OK, st)
+	// The failure is reported once, by the next flush.
+	assert.Equal(t, fuse.EIO, fs.Flush(nil, &fuse.FlushIn{InHeader: header, Fh: out.Fh}))
+	assert.Equal(t, fuse.OK, fs.Flush(nil, &fuse.FlushIn{InHeader: header, Fh: out.Fh}))
+
+	_, st = fs.Write(nil, &fuse.WriteIn{InHeader: header, Fh: out.Fh, Size: 4}, []byte("data"))
+	assert.Equal(t, fuse.OK, st)
+	assert.Equal(t, fuse.EIO, fs.Fsync(nil, &fuse.FsyncIn{InHeader: header, Fh: out.Fh}))
+	assert.Equal(t, fuse.OK, fs.Fsync(nil, &fuse.FsyncIn{InHeader: header, Fh: out.Fh}))
+
+	g.failing = false
+	_, st = fs.Write(nil, &fuse.WriteIn{InHeader: header, Fh: out.Fh, Size: 4}, []byte("data"))
+	assert.Equal(t, fuse.OK, st)
+	assert.Equal(t, fuse.OK, fs.Flush(nil, &fuse.FlushIn{InHeader: header, Fh: out.Fh}))
+}
+
+func TestWriteBehindLimit(t *testing.T) {
+	fs, g := newWriteBehindFS(t, 8, 0)
+	var out fuse.CreateOut
+	require.Equal(t, fuse.OK, fs.Create(nil, &fuse.CreateIn{InHeader: fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}, Mode: 0644}, "file", &out))
+	header := fuse.InHeader{NodeId: out.NodeId}
+	write := func(off uint64, data string) fuse.Status {
+		_, st := fs.Write(nil, &fuse.WriteIn{InHeader: header, Fh: out.Fh, Offset: off, Size: uint32(len(data))}, []byte(data))
+		return st
+	}
+
+	g.close()
+	require.Equal(t, fuse.OK, write(0, "0123"))
+	<-g.entered
+	require.Equal(t, fuse.OK, write(4, "4567"))
+	done := make(chan fuse.Status)
+	go func() { done <- write(8, "89") }()
+	select {
+	case <-done:
+		t.Fatal("write past the limit did not wait")
+	case <-time.After(20 * time.Millisecond):
+	}
+	g.release()
+	assert.Equal(t, fuse.OK, <-done)
+
+	// A write larger than the limit waits for the others and goes on.
+	assert.Equal(t, fuse.OK, write(10, "abcdefghijkl"))
+	assert.Equal(t, fuse.OK, fs.Fsync(nil, &fuse.FsyncIn{InHeader: header, Fh: out.Fh}))
+	res, st := fs.Read(nil, &fuse.ReadIn{InHeader: header, Fh: out.Fh, Size: 100}, nil)
+	require.Equal(t, fuse.OK, st)
+	data, _ := res.Bytes(nil)
+	assert.Equal(t, "0123456789abcdefghijkl", string(data))
+	assert.Zero(t, fs.writeBehind.dirty)
+}
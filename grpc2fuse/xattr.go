@@ -28,13 +28,13 @@ func (fs *fileSystem) GetXAttr(cancel <-chan struct{}, header *fuse.InHeader, at
 	res, err := fs.client.GetXAttr(ctx, &pb.GetXAttrRequest{
 		Header: toPbHeader(header),
 		Attr:   attr,
-		Dest:   dest,
+		Size:   uint32(len(dest)),
 	}, fs.opts...)
 
 	if st := dealGrpcError("GetXAttr", err); st != fuse.OK {
 		return 0, st
 	}
-	return res.Size, fuse.Status(res.Status.Code)
+	return copyXAttrValue(dest, res.Size, res.Value, fuse.Status(res.Status.GetCode()))
 }
 
 func (fs *fileSystem) ListXAttr(cancel <-chan struct{}, header *fuse.InHeader, dest []byte) (uint32, fuse.Status) {
@@ -42,17 +42,31 @@ func (fs *fileSystem) ListXAttr(cancel <-chan struct{}, header *fuse.InHeader, d
 
 	res, err := fs.client.ListXAttr(ctx, &pb.ListXAttrRequest{
 		Header: toPbHeader(header),
-		Dest:   dest,
+		Size:   uint32(len(dest)),
 	}, fs.opts...)
 
 	if st := dealGrpcError("ListXAttr", err); st != fuse.OK {
 		return 0, st
 	}
-	return res.Size, fuse.Status(res.Status.Code)
+	return copyXAttrValue(dest, res.Size, res.Value, fuse.Status(res.Status.GetCode()))
+}
+
+// copyXAttrValue fills dest with the value the server returned. On ERANGE
+// and for size probes only sz is passed on, which go-fuse turns into the
+// reply the kernel expects.
+func copyXAttrValue(dest []byte, sz uint32, value []byte, st fuse.Status) (uint32, fuse.Status) {
+	if st != fuse.OK || len(dest) == 0 {
+		return sz, st
+	}
+	if len(value) > len(dest) {
+		return sz, fuse.ERANGE
+	}
+	return uint32(copy(dest, value)), fuse.OK
 }
 
 func (fs *fileSystem) RemoveXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string) (code fuse.Status) {
 	ctx := newContext(cancel)
+	defer fs.attrCache.invalidate(header.NodeId)
 
 	res, err := fs.client.RemoveXAttr(ctx, &pb.RemoveXAttrRequest{
 		Header: toPbHeader(header),
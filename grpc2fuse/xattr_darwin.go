@@ -24,6 +24,7 @@ import (
 
 func (fs *fileSystem) SetXAttr(cancel <-chan struct{}, input *fuse.SetXAttrIn, attr string, data []byte) fuse.Status {
 	ctx := newContext(cancel)
+	defer fs.attrCache.invalidate(input.NodeId)
 
 	res, err := fs.client.SetXAttr(ctx, &pb.SetXAttrRequest{
 		Header:   toPbHeader(&input.InHeader),
@@ -12,12 +12,12 @@ import (
 	"google.golang.org/grpc"
 )
 
-type MockRawFileSystemClient struct {
+type xattrDarwinClient struct {
 	mock.Mock
 	pb.RawFileSystemClient
 }
 
-func (m *MockRawFileSystemClient) SetXAttr(ctx context.Context, in *pb.SetXAttrRequest, opts ...grpc.CallOption) (*pb.SetXAttrResponse, error) {
+func (m *xattrDarwinClient) SetXAttr(ctx context.Context, in *pb.SetXAttrRequest, opts ...grpc.CallOption) (*pb.SetXAttrResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -26,7 +26,7 @@ func (m *MockRawFileSystemClient) SetXAttr(ctx context.Context, in *pb.SetXAttrR
 }
 
 func TestSetXAttr(t *testing.T) {
-	mockClient := new(MockRawFileSystemClient)
+	mockClient := new(xattrDarwinClient)
 	fs := grpc2fuse.NewFileSystem(mockClient)
 
 	tests := []struct {
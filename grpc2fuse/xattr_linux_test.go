@@ -1 +1 @@
-
+package grpc2fuse_test
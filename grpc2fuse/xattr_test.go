@@ -12,12 +12,12 @@ import (
 )
 
 // Mock RawFileSystemClient
-type MockRawFileSystemClient struct {
+type xattrClient struct {
 	mock.Mock
 	pb.RawFileSystemClient
 }
 
-func (m *MockRawFileSystemClient) GetXAttr(ctx context.Context, in *pb.GetXAttrRequest, opts ...grpc.CallOption) (*pb.GetXAttrResponse, error) {
+func (m *xattrClient) GetXAttr(ctx context.Context, in *pb.GetXAttrRequest, opts ...grpc.CallOption) (*pb.GetXAttrResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -25,7 +25,7 @@ func (m *MockRawFileSystemClient) GetXAttr(ctx context.Context, in *pb.GetXAttrR
 	return args.Get(0).(*pb.GetXAttrResponse), args.Error(1)
 }
 
-func (m *MockRawFileSystemClient) ListXAttr(ctx context.Context, in *pb.ListXAttrRequest, opts ...grpc.CallOption) (*pb.ListXAttrResponse, error) {
+func (m *xattrClient) ListXAttr(ctx context.Context, in *pb.ListXAttrRequest, opts ...grpc.CallOption) (*pb.ListXAttrResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -33,7 +33,7 @@ func (m *MockRawFileSystemClient) ListXAttr(ctx context.Context, in *pb.ListXAtt
 	return args.Get(0).(*pb.ListXAttrResponse), args.Error(1)
 }
 
-func (m *MockRawFileSystemClient) RemoveXAttr(ctx context.Context, in *pb.RemoveXAttrRequest, opts ...grpc.CallOption) (*pb.RemoveXAttrResponse, error) {
+func (m *xattrClient) RemoveXAttr(ctx context.Context, in *pb.RemoveXAttrRequest, opts ...grpc.CallOption) (*pb.RemoveXAttrResponse, error) {
 	args := m.Called(ctx, in, opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -42,7 +42,7 @@ func (m *MockRawFileSystemClient) RemoveXAttr(ctx context.Context, in *pb.Remove
 }
 
 func TestGetXAttr(t *testing.T) {
-	mockClient := new(MockRawFileSystemClient)
+	mockClient := new(xattrClient)
 	fs := &fileSystem{
 		client: mockClient,
 	}
@@ -56,6 +56,7 @@ func TestGetXAttr(t *testing.T) {
 		mockErr     error
 		wantSize    uint32
 		wantStatus  fuse.Status
+		wantValue   []byte
 	}{
 		{
 			name: "success",
@@ -64,6 +65,25 @@ func TestGetXAttr(t *testing.T) {
 			},
 			attr: "user.test",
 			dest: make([]byte, 10),
+			mockResp: &pb.GetXAttrResponse{
+				Size:  5,
+				Value: []byte("value"),
+				Status: &pb.Status{
+					Code: 0,
+				},
+			},
+			mockErr:    nil,
+			wantSize:   5,
+			wantStatus: fuse.OK,
+			wantValue:  []byte("value"),
+		},
+		{
+			name: "size_probe",
+			header: &fuse.InHeader{
+				NodeId: 1,
+			},
+			attr: "user.test",
+			dest: []byte{},
 			mockResp: &pb.GetXAttrResponse{
 				Size: 5,
 				Status: &pb.Status{
@@ -74,6 +94,41 @@ func TestGetXAttr(t *testing.T) {
 			wantSize:   5,
 			wantStatus: fuse.OK,
 		},
+		{
+			name: "erange",
+			header: &fuse.InHeader{
+				NodeId: 1,
+			},
+			attr: "user.test",
+			dest: make([]byte, 2),
+			mockResp: &pb.GetXAttrResponse{
+				Size: 5,
+				Status: &pb.Status{
+					Code: int32(fuse.ERANGE),
+				},
+			},
+			mockErr:    nil,
+			wantSize:   5,
+			wantStatus: fuse.ERANGE,
+		},
+		{
+			name: "value_exceeds_dest",
+			header: &fuse.InHeader{
+				NodeId: 1,
+			},
+			attr: "user.test",
+			dest: make([]byte, 2),
+			mockResp: &pb.GetXAttrResponse{
+				Size:  5,
+				Value: []byte("value"),
+				Status: &pb.Status{
+					Code: 0,
+				},
+			},
+			mockErr:    nil,
+			wantSize:   5,
+			wantStatus: fuse.ERANGE,
+		},
 		{
 			name: "error_response",
 			header: &fuse.InHeader{
@@ -97,18 +152,21 @@ func TestGetXAttr(t *testing.T) {
 			mockClient.On("GetXAttr", mock.Anything, &pb.GetXAttrRequest{
 				Header: toPbHeader(tt.header),
 				Attr:   tt.attr,
-				Dest:   tt.dest,
+				Size:   uint32(len(tt.dest)),
 			}, mock.Anything).Return(tt.mockResp, tt.mockErr).Once()
 
 			size, status := fs.GetXAttr(make(chan struct{}), tt.header, tt.attr, tt.dest)
 			assert.Equal(t, tt.wantSize, size)
 			assert.Equal(t, tt.wantStatus, status)
+			if tt.wantValue != nil {
+				assert.Equal(t, tt.wantValue, tt.dest[:size])
+			}
 		})
 	}
 }
 
 func TestListXAttr(t *testing.T) {
-	mockClient := new(MockRawFileSystemClient)
+	mockClient := new(xattrClient)
 	fs := &fileSystem{
 		client: mockClient,
 	}
@@ -121,6 +179,7 @@ func TestListXAttr(t *testing.T) {
 		mockErr     error
 		wantSize    uint32
 		wantStatus  fuse.Status
+		wantValue   []byte
 	}{
 		{
 			name: "success",
@@ -128,6 +187,24 @@ func TestListXAttr(t *testing.T) {
 				NodeId: 1,
 			},
 			dest: make([]byte, 10),
+			mockResp: &pb.ListXAttrResponse{
+				Size:  5,
+				Value: []byte("value"),
+				Status: &pb.Status{
+					Code: 0,
+				},
+			},
+			mockErr:    nil,
+			wantSize:   5,
+			wantStatus: fuse.OK,
+			wantValue:  []byte("value"),
+		},
+		{
+			name: "size_probe",
+			header: &fuse.InHeader{
+				NodeId: 1,
+			},
+			dest: []byte{},
 			mockResp: &pb.ListXAttrResponse{
 				Size: 5,
 				Status: &pb.Status{
@@ -138,6 +215,39 @@ func TestListXAttr(t *testing.T) {
 			wantSize:   5,
 			wantStatus: fuse.OK,
 		},
+		{
+			name: "erange",
+			header: &fuse.InHeader{
+				NodeId: 1,
+			},
+			dest: make([]byte, 2),
+			mockResp: &pb.ListXAttrResponse{
+				Size: 5,
+				Status: &pb.Status{
+					Code: int32(fuse.ERANGE),
+				},
+			},
+			mockErr:    nil,
+			wantSize:   5,
+			wantStatus: fuse.ERANGE,
+		},
+		{
+			name: "value_exceeds_dest",
+			header: &fuse.InHeader{
+				NodeId: 1,
+			},
+			dest: make([]byte, 2),
+			mockResp: &pb.ListXAttrResponse{
+				Size:  5,
+				Value: []byte("value"),
+				Status: &pb.Status{
+					Code: 0,
+				},
+			},
+			mockErr:    nil,
+			wantSize:   5,
+			wantStatus: fuse.ERANGE,
+		},
 		{
 			name: "error_response",
 			header: &fuse.InHeader{
@@ -159,18 +269,21 @@ func TestListXAttr(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockClient.On("ListXAttr", mock.Anything, &pb.ListXAttrRequest{
 				Header: toPbHeader(tt.header),
-				Dest:   tt.dest,
+				Size:   uint32(len(tt.dest)),
 			}, mock.Anything).Return(tt.mockResp, tt.mockErr).Once()
 
 			size, status := fs.ListXAttr(make(chan struct{}), tt.header, tt.dest)
 			assert.Equal(t, tt.wantSize, size)
 			assert.Equal(t, tt.wantStatus, status)
+			if tt.wantValue != nil {
+				assert.Equal(t, tt.wantValue, tt.dest[:size])
+			}
 		})
 	}
 }
 
 func TestRemoveXAttr(t *testing.T) {
-	mockClient := new(MockRawFileSystemClient)
+	mockClient := new(xattrClient)
 	fs := &fileSystem{
 		client: mockClient,
 	}
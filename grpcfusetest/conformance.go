@@ -0,0 +1,258 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpcfusetest
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	fallocKeepSize  = 0x01 // FALLOC_FL_KEEP_SIZE
+	fallocPunchHole = 0x02 // FALLOC_FL_PUNCH_HOLE
+)
+
+// Case is a script of calls run by the conformance suite.
+type Case struct {
+	Name string
+	Run  func(s *Session)
+}
+
+// Cases covers every method of fuse.RawFileSystem that reaches the backend.
+var Cases = []Case{
+	{Name: "lookup", Run: func(s *Session) {
+		s.Lookup(fuse.FUSE_ROOT_ID, "missing")
+		dir := s.Mkdir(fuse.FUSE_ROOT_ID, "dir", 0755)
+		s.Lookup(fuse.FUSE_ROOT_ID, "dir")
+		s.Forget(dir, 2)
+		s.Lookup(fuse.FUSE_ROOT_ID, "dir")
+		s.GetAttr(fuse.FUSE_ROOT_ID)
+	}},
+	{Name: "attributes", Run: func(s *Session) {
+		node, fh := s.Create(fuse.FUSE_ROOT_ID, "file", syscall.O_RDWR, 0644)
+		s.GetAttr(node)
+		s.SetAttr(node, fuse.SetAttrIn{SetAttrInCommon: fuse.SetAttrInCommon{Valid: fuse.FATTR_MODE, Mode: 0600}})
+		s.SetAttr(node, fuse.SetAttrIn{SetAttrInCommon: fuse.SetAttrInCommon{Valid: fuse.FATTR_SIZE, Size: 12345}})
+		s.SetAttr(node, fuse.SetAttrIn{SetAttrInCommon: fuse.SetAttrInCommon{
+			Valid: fuse.FATTR_ATIME | fuse.FATTR_MTIME,
+			Atime: 1000000000,
+			Mtime: 1000000001,
+		}})
+		s.SetAttr(node, fuse.SetAttrIn{SetAttrInCommon: fuse.SetAttrInCommon{Valid: fuse.FATTR_SIZE | fuse.FATTR_FH, Fh: fh, Size: 10}})
+		s.GetAttr(node)
+		s.Access(node, 4)
+		s.Access(fuse.FUSE_ROOT_ID, 7)
+		s.Release(node, fh)
+	}},
+	{Name: "modifying structure", Run: func(s *Session) {
+		dir := s.Mkdir(fuse.FUSE_ROOT_ID, "dir", 0755)
+		s.Mkdir(fuse.FUSE_ROOT_ID, "dir", 0755)
+		s.Mkdir(dir, "sub", 0700)
+		s.Rmdir(fuse.FUSE_ROOT_ID, "dir")
+		s.Mknod(dir, "fifo", syscall.S_IFIFO|0644, 0)
+		s.Rename(dir, "fifo", fuse.FUSE_ROOT_ID, "moved", 0)
+		s.Lookup(dir, "fifo")
+		s.Lookup(fuse.FUSE_ROOT_ID, "moved")
+		s.Rename(fuse.FUSE_ROOT_ID, "missing", dir, "x", 0)
+		s.Unlink(fuse.FUSE_ROOT_ID, "moved")
+		s.Unlink(fuse.FUSE_ROOT_ID, "moved")
+		s.Rmdir(dir, "sub")
+		s.Rmdir(fuse.FUSE_ROOT_ID, "dir")
+		s.Lookup(fuse.FUSE_ROOT_ID, "dir")
+	}},
+	{Name: "links", Run: func(s *Session) {
+		node, fh := s.Create(fuse.FUSE_ROOT_ID, "file", syscall.O_WRONLY, 0644)
+		s.Write(node, fh, 0, []byte("content"))
+		s.Release(node, fh)
+		s.Link(node, fuse.FUSE_ROOT_ID, "hard")
+		s.Link(node, fuse.FUSE_ROOT_ID, "hard")
+		s.GetAttr(node)
+		link := s.Symlink(fuse.FUSE_ROOT_ID, "file", "soft")
+		s.Readlink(link)
+		s.Readlink(node)
+		s.Symlink(fuse.FUSE_ROOT_ID, "file", "soft")
+		s.Unlink(fuse.FUSE_ROOT_ID, "file")
+		s.GetAttr(node)
+	}},
+	{Name: "xattrs", Run: func(s *Session) {
+		node, fh := s.Create(fuse.FUSE_ROOT_ID, "file", syscall.O_RDWR, 0644)
+		s.Release(node, fh)
+		s.GetXAttr(node, "user.a", 64)
+		s.SetXAttr(node, "user.a", []byte("value"), 0)
+		s.SetXAttr(node, "user.b", []byte(""), 0)
+		s.SetXAttr(node, "user.a", []byte("other"), 1) // XATTR_CREATE
+		s.GetXAttr(node, "user.a", 0)
+		s.GetXAttr(node, "user.a", 2)
+		s.GetXAttr(node, "user.a", 64)
+		s.ListXAttr(node, 0)
+		s.ListXAttr(node, 2)
+		s.ListXAttr(node, 64)
+		s.RemoveXAttr(node, "user.a")
+		s.RemoveXAttr(node, "user.a")
+		s.ListXAttr(node, 64)
+	}},
+	{Name: "read write", Run: func(s *Session) {
+		node, fh := s.Create(fuse.FUSE_ROOT_ID, "file", syscall.O_RDWR, 0644)
+		s.Write(node, fh, 0, []byte("hello, world"))
+		s.Write(node, fh, 100, []byte("tail"))
+		s.Flush(node, fh)
+		s.Fsync(node, fh, 0)
+		s.Fsync(node, fh, 1)
+		s.Release(node, fh)
+
+		fh = s.Open(node, syscall.O_RDONLY)
+		s.Read(node, fh, 0, 5)
+		s.Read(node, fh, 7, 100)
+		s.Read(node, fh, 98, 10)
+		s.Read(node, fh, 1000, 10)
+		s.Write(node, fh, 0, []byte("x"))
+		s.Release(node, fh)
+		s.GetAttr(node)
+	}},
+	{Name: "large read write", Run: func(s *Session) {
+		data := make([]byte, 3<<20)
+		for i := range data {
+			data[i] = byte(i * 7)
+		}
+		node, fh := s.Create(fuse.FUSE_ROOT_ID, "big", syscall.O_RDWR, 0644)
+		s.Write(node, fh, 0, data)
+		s.Read(node, fh, 0, 1<<20)
+		s.Read(node, fh, 1<<20+17, 1<<20)
+		s.Release(node, fh)
+	}},
+	{Name: "sequential read after changes", Run: func(s *Session) {
+		data := make([]byte, 64<<10)
+		for i := range data {
+			data[i] = byte(i * 11)
+		}
+		node, fh := s.Create(fuse.FUSE_ROOT_ID, "sequential", syscall.O_RDWR, 0644)
+		s.Write(node, fh, 0, data)
+		reader := s.Open(node, syscall.O_RDONLY)
+		for off := uint64(0); off < 16<<10; off += 4096 {
+			s.Read(node, reader, off, 4096)
+		}
+		s.Write(node, fh, 20<<10, []byte("changed"))
+		s.Read(node, reader, 16<<10, 8192)
+		s.SetAttr(node, fuse.SetAttrIn{SetAttrInCommon: fuse.SetAttrInCommon{Valid: fuse.FATTR_SIZE, Size: 30 << 10}})
+		s.Read(node, reader, 24<<10, 8192)
+		s.Fallocate(node, fh, 28<<10, 4096, fallocPunchHole|fallocKeepSize)
+		s.Read(node, reader, 24<<10, 8192)
+		s.Release(node, reader)
+		s.Release(node, fh)
+	}},
+	{Name: "sparse files", Run: func(s *Session) {
+		node, fh := s.Create(fuse.FUSE_ROOT_ID, "sparse", syscall.O_RDWR, 0644)
+		s.Write(node, fh, 1<<20, []byte("data"))
+		s.Lseek(node, fh, 0, unix.SEEK_DATA)
+		s.Lseek(node, fh, 0, unix.SEEK_HOLE)
+		s.Lseek(node, fh, 1<<20, unix.SEEK_HOLE)
+		s.Lseek(node, fh, 2<<20, unix.SEEK_DATA)
+		s.Fallocate(node, fh, 0, 8192, 0)
+		s.Fallocate(node, fh, 4<<20, 4096, fallocKeepSize)
+		s.GetAttr(node)
+		s.Fallocate(node, fh, 1<<20, 4096, fallocPunchHole|fallocKeepSize)
+		s.Read(node, fh, 1<<20, 4)
+		s.Release(node, fh)
+	}},
+	{Name: "copy file range", Run: func(s *Session) {
+		src, srcFh := s.Create(fuse.FUSE_ROOT_ID, "src", syscall.O_RDWR, 0644)
+		s.Write(src, srcFh, 0, []byte("0123456789"))
+		dst, dstFh := s.Create(fuse.FUSE_ROOT_ID, "dst", syscall.O_RDWR, 0644)
+		s.CopyFileRange(src, srcFh, 2, dst, dstFh, 1, 5)
+		s.Read(dst, dstFh, 0, 100)
+		s.Release(src, srcFh)
+		s.Release(dst, dstFh)
+	}},
+	{Name: "locks", Run: func(s *Session) {
+		node, fh := s.Create(fuse.FUSE_ROOT_ID, "file", syscall.O_RDWR, 0644)
+		fh2 := s.Open(node, syscall.O_RDWR)
+		wrlck := fuse.FileLock{Start: 0, End: 99, Typ: syscall.F_WRLCK}
+		s.GetLk(node, fh, 1, wrlck)
+		s.SetLk(node, fh, 1, wrlck)
+		s.GetLk(node, fh2, 2, wrlck)
+		s.SetLk(node, fh2, 2, wrlck)
+		s.SetLkw(node, fh2, 2, fuse.FileLock{Start: 100, End: 199, Typ: syscall.F_WRLCK})
+		s.SetLk(node, fh, 1, fuse.FileLock{Start: 0, End: 99, Typ: syscall.F_UNLCK})
+		s.SetLk(node, fh2, 2, wrlck)
+		s.Release(node, fh)
+		s.Release(node, fh2)
+	}},
+	{Name: "directories", Run: func(s *Session) {
+		dir := s.Mkdir(fuse.FUSE_ROOT_ID, "dir", 0755)
+		for _, name := range []string{"a", "bb", "ccc", "dddd", "eeeee"} {
+			node, fh := s.Create(dir, name, syscall.O_RDWR, 0644)
+			s.Release(node, fh)
+		}
+		s.Mkdir(dir, "sub", 0755)
+
+		fh := s.OpenDir(dir)
+		s.ReadDir(dir, fh, 0, 4096)
+		s.FsyncDir(dir, fh)
+		s.ReleaseDir(dir, fh)
+
+		fh = s.OpenDir(dir)
+		for off, i := uint64(0), 0; i < 10; i++ {
+			off = s.ReadDir(dir, fh, off, 80)
+		}
+		s.ReleaseDir(dir, fh)
+
+		fh = s.OpenDir(dir)
+		for off, i := uint64(0), 0; i < 10; i++ {
+			off = s.ReadDirPlus(dir, fh, off, 400)
+		}
+		s.ReleaseDir(dir, fh)
+		s.Lookup(dir, "ccc")
+	}},
+	{Name: "file system", Run: func(s *Session) {
+		s.String()
+		s.StatFs(fuse.FUSE_ROOT_ID)
+	}},
+}
+
+// RunConformance runs every case of Cases twice, on a backend called
+// directly and on one served through a Pair, and reports each call whose
+// outcome differs. newBackend must return a fresh backend in the same
+// state every time it is called. opts configures the Pair and may be nil.
+func RunConformance(t *testing.T, newBackend func(t *testing.T) fuse.RawFileSystem, opts *Options) {
+	for _, c := range Cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			want := NewSession(newBackend(t))
+			c.Run(want)
+
+			got := NewSession(New(t, newBackend(t), opts).Client)
+			c.Run(got)
+
+			CompareResults(t, want.Results, got.Results)
+		})
+	}
+}
+
+// CompareResults reports every call in got that differs from want.
+func CompareResults(t testing.TB, want, got []Result) {
+	t.Helper()
+	if !assert.Equal(t, len(want), len(got), "number of calls") {
+		return
+	}
+	for i := range want {
+		assert.Equal(t, want[i], got[i], "call %d: %s", i, want[i].Op)
+	}
+}
@@ -0,0 +1,91 @@
+package grpcfusetest
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chiyutianyi/grpcfuse/shm"
+)
+
+func newLoopback(t *testing.T) fuse.RawFileSystem {
+	root, err := fs.NewLoopbackRoot(t.TempDir())
+	require.NoError(t, err)
+	return fs.NewNodeFS(root, &fs.Options{})
+}
+
+func TestConformanceLoopback(t *testing.T) {
+	RunConformance(t, newLoopback, nil)
+}
+
+func TestConformanceSmallMessages(t *testing.T) {
+	RunConformance(t, newLoopback, &Options{MsgSizeThreshold: 7})
+}
+
+func TestConformanceStriped(t *testing.T) {
+	RunConformance(t, newLoopback, &Options{Connections: 3, StripeSize: 4096})
+}
+
+func TestConformanceReadAhead(t *testing.T) {
+	RunConformance(t, newLoopback, &Options{ReadAhead: 64 << 10, ReadAheadChunk: 4096})
+}
+
+func TestPair(t *testing.T) {
+	l, err := net.Listen("unix", filepath.Join(t.TempDir(), "sock"))
+	require.NoError(t, err)
+
+	shmListener, err := net.Listen("unix", filepath.Join(t.TempDir(), "sock"))
+	require.NoError(t, err)
+
+	for _, opts := range []*Options{{MsgSizeThreshold: 64}, {MsgSizeThreshold: 64, Listener: l}, {Listener: shmListener, SharedMemory: true}, {Connections: 2, StripeSize: 4}, {WriteBehind: 1 << 20}} {
+		testPair(t, opts)
+	}
+
+	_, err = NewPair(newLoopback(t), &Options{SharedMemory: true})
+	assert.Error(t, err)
+}
+
+func testPair(t *testing.T, opts *Options) {
+	backend := newLoopback(t)
+	p := New(t, backend, opts)
+	assert.Equal(t, backend, p.Backend)
+	assert.Len(t, p.DataConns, opts.Connections)
+
+	s := NewSession(p.Client)
+	node, fh := s.Create(fuse.FUSE_ROOT_ID, "file", 2, 0644)
+	s.Write(node, fh, 0, []byte("0123456789"))
+	s.Read(node, fh, 0, 100)
+	require.Len(t, s.Results, 3)
+	for _, r := range s.Results {
+		assert.Equal(t, fuse.OK, r.Status, r.Op)
+	}
+	assert.Equal(t, "0123456789", s.Results[2].Out)
+	if opts.SharedMemory {
+		// The read went through shared memory, the write was too small.
+		assert.Equal(t, shm.Stats{Shared: 1, Inline: 1}, p.SharedMemory.Stats())
+	}
+	assert.NoError(t, p.Close())
+}
+
+func TestCompareResults(t *testing.T) {
+	want := []Result{{Op: "GetAttr", Status: fuse.OK}, {Op: "Read", Status: fuse.OK, Out: "abc"}}
+
+	ft := &testing.T{}
+	CompareResults(ft, want, want)
+	assert.False(t, ft.Failed())
+
+	for _, got := range [][]Result{
+		want[:1],
+		{want[0], {Op: "Read", Status: fuse.EIO}},
+		{want[0], {Op: "Read", Status: fuse.OK, Out: "abd"}},
+	} {
+		ft := &testing.T{}
+		CompareResults(ft, want, got)
+		assert.True(t, ft.Failed(), "%v", got)
+	}
+}
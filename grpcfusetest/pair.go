@@ -0,0 +1,224 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package grpcfusetest connects grpc2fuse to fuse2grpc in process, so a
+// RawFileSystem can be exercised through the whole gRPC stack without
+// /dev/fuse or a network.
+package grpcfusetest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	grpc_recovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/chiyutianyi/grpcfuse/fuse2grpc"
+	"github.com/chiyutianyi/grpcfuse/grpc2fuse"
+	"github.com/chiyutianyi/grpcfuse/pb"
+	"github.com/chiyutianyi/grpcfuse/shm"
+)
+
+const bufSize = 1 << 20
+
+// Options tunes the two ends of a Pair. The zero value is usable.
+type Options struct {
+	// MsgSizeThreshold is passed to the server's SetMsgSizeThreshold if
+	// non-zero.
+	MsgSizeThreshold int
+	// ServerOptions are appended to the options of the gRPC server.
+	ServerOptions []grpc.ServerOption
+	// DialOptions are appended to the options of the client connection.
+	DialOptions []grpc.DialOption
+	// CallOptions are passed to grpc2fuse.NewPooledFileSystem.
+	CallOptions []grpc.CallOption
+	// Listener is served instead of an in-memory listener, to go through
+	// a real transport such as a Unix socket. The Pair closes it.
+	Listener net.Listener
+	// SharedMemory moves the data of reads and writes through shm, over
+	// a Unix socket next to Listener, which must be one too.
+	SharedMemory bool
+	// Connections and StripeSize make a grpc2fuse.Pool with that many
+	// connections for reads and writes besides Conn.
+	Connections int
+	StripeSize  int
+	// ReadAhead and ReadAheadChunk are passed to SetReadAhead of the
+	// client.
+	ReadAhead      int
+	ReadAheadChunk int
+	// WriteBehind is passed to SetWriteBehind of the client, merging
+	// writes up to 1MiB.
+	WriteBehind int
+}
+
+// Pair is a fuse2grpc server and a grpc2fuse client talking over an
+// in-memory listener.
+type Pair struct {
+	// Backend is the file system served by fuse2grpc.
+	Backend fuse.RawFileSystem
+	// Client is the grpc2fuse file system forwarding to Backend.
+	Client fuse.RawFileSystem
+	// Conn is the client connection Client uses.
+	Conn *grpc.ClientConn
+	// DataConns are the connections of the reads and writes of a Pair
+	// with Connections.
+	DataConns []*grpc.ClientConn
+	// SharedMemory is the shm client of a Pair with shared memory.
+	SharedMemory *shm.Client
+
+	server    *grpc.Server
+	listener  net.Listener
+	shmServer *shm.Server
+	shmLis    net.Listener
+}
+
+// NewPair serves backend and returns a client connected to it. Panics in
+// backend are turned into errors like in a real server.
+func NewPair(backend fuse.RawFileSystem, opts *Options) (*Pair, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	var (
+		listener net.Listener
+		dial     func(ctx context.Context, _ string) (net.Conn, error)
+	)
+	if opts.Listener != nil {
+		listener = opts.Listener
+		addr := listener.Addr()
+		dial = func(ctx context.Context, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, addr.Network(), addr.String())
+		}
+	} else {
+		l := bufconn.Listen(bufSize)
+		listener = l
+		dial = func(ctx context.Context, _ string) (net.Conn, error) {
+			return l.DialContext(ctx)
+		}
+	}
+	p := &Pair{Backend: backend, listener: listener}
+	streamInterceptors := []grpc.StreamServerInterceptor{grpc_recovery.StreamServerInterceptor()}
+	unaryInterceptors := []grpc.UnaryServerInterceptor{grpc_recovery.UnaryServerInterceptor()}
+	var dialOpts []grpc.DialOption
+	if opts.SharedMemory {
+		if err := p.startShm(); err != nil {
+			listener.Close()
+			return nil, err
+		}
+		streamInterceptors = append([]grpc.StreamServerInterceptor{p.shmServer.StreamServerInterceptor()}, streamInterceptors...)
+		unaryInterceptors = append([]grpc.UnaryServerInterceptor{p.shmServer.UnaryServerInterceptor()}, unaryInterceptors...)
+		dialOpts = append(dialOpts,
+			grpc.WithChainUnaryInterceptor(p.SharedMemory.UnaryClientInterceptor()),
+			grpc.WithChainStreamInterceptor(p.SharedMemory.StreamClientInterceptor()))
+	}
+	serverOpts := append([]grpc.ServerOption{
+		grpc.StreamInterceptor(grpc_middleware.ChainStreamServer(streamInterceptors...)),
+		grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(unaryInterceptors...)),
+	}, opts.ServerOptions...)
+	server := grpc.NewServer(serverOpts...)
+	p.server = server
+
+	srv := fuse2grpc.NewServer(backend)
+	if opts.MsgSizeThreshold > 0 {
+		srv.SetMsgSizeThreshold(opts.MsgSizeThreshold)
+	}
+	pb.RegisterRawFileSystemServer(server, srv)
+	go server.Serve(listener)
+
+	dialOpts = append(append([]grpc.DialOption{
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(dial),
+	}, dialOpts...), opts.DialOptions...)
+	conns := make([]*grpc.ClientConn, 1+opts.Connections)
+	clients := make([]pb.RawFileSystemClient, len(conns))
+	for i := range conns {
+		conn, err := grpc.Dial("bufconn", dialOpts...)
+		if err != nil {
+			for _, conn := range conns[:i] {
+				conn.Close()
+			}
+			server.Stop()
+			p.stopShm()
+			return nil, err
+		}
+		conns[i], clients[i] = conn, pb.NewRawFileSystemClient(conn)
+	}
+	p.Conn, p.DataConns = conns[0], conns[1:]
+	pool := grpc2fuse.NewPool(clients[0], clients[1:], opts.StripeSize)
+	client := grpc2fuse.NewPooledFileSystem(pool, opts.CallOptions...)
+	client.SetReadAhead(opts.ReadAhead, opts.ReadAheadChunk)
+	client.SetWriteBehind(opts.WriteBehind, 0)
+	p.Client = client
+	return p, nil
+}
+
+// startShm serves shm next to the Unix socket of the pair and registers
+// a client with it.
+func (p *Pair) startShm() error {
+	addr, ok := p.listener.Addr().(*net.UnixAddr)
+	if !ok {
+		return fmt.Errorf("grpcfusetest: shared memory needs a unix listener, not %v", p.listener.Addr())
+	}
+	l, err := net.Listen("unix", addr.Name+".shm")
+	if err != nil {
+		return err
+	}
+	p.shmServer, p.shmLis = shm.NewServer(), l
+	go p.shmServer.Serve(l)
+	if p.SharedMemory, err = shm.Dial(addr.Name+".shm", nil); err != nil {
+		p.stopShm()
+		return err
+	}
+	return nil
+}
+
+func (p *Pair) stopShm() {
+	if p.SharedMemory != nil {
+		p.SharedMemory.Close()
+	}
+	if p.shmServer != nil {
+		p.shmLis.Close()
+		p.shmServer.Close()
+	}
+}
+
+// New is NewPair for tests. The pair is closed when the test ends.
+func New(t testing.TB, backend fuse.RawFileSystem, opts *Options) *Pair {
+	t.Helper()
+	p, err := NewPair(backend, opts)
+	if err != nil {
+		t.Fatalf("NewPair: %v", err)
+	}
+	t.Cleanup(func() { p.Close() })
+	return p
+}
+
+// Close tears down the connection and the server.
+func (p *Pair) Close() error {
+	err := p.Conn.Close()
+	for _, conn := range p.DataConns {
+		conn.Close()
+	}
+	p.server.Stop()
+	p.stopShm()
+	return err
+}
@@ -0,0 +1,555 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpcfusetest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"unsafe"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+)
+
+// Result is the outcome of one call a Session made.
+type Result struct {
+	Op     string
+	Status fuse.Status
+	Out    interface{}
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf("%s: %v %+v", r.Op, r.Status, r.Out)
+}
+
+// Session calls a RawFileSystem the way the kernel would, and records
+// every result with the fields that legitimately differ between two
+// instances of a backend (timestamps, inode numbers, free space) cleared.
+//
+// Calls that need a node or handle which an earlier call failed to
+// produce are recorded as skipped instead of being made, since backends
+// such as fs.NewNodeFS panic on unknown node IDs.
+type Session struct {
+	FS      fuse.RawFileSystem
+	Results []Result
+}
+
+// NewSession returns a session on rfs.
+func NewSession(rfs fuse.RawFileSystem) *Session {
+	return &Session{FS: rfs}
+}
+
+// Header returns the header of a request on nodeID from this process.
+func (s *Session) Header(nodeID uint64) fuse.InHeader {
+	return fuse.InHeader{
+		NodeId: nodeID,
+		Caller: fuse.Caller{
+			Owner: fuse.Owner{Uid: uint32(os.Getuid()), Gid: uint32(os.Getgid())},
+			Pid:   uint32(os.Getpid()),
+		},
+	}
+}
+
+// record appends a result. Outputs of failed calls are undefined, so they
+// are dropped.
+func (s *Session) record(op string, st fuse.Status, out interface{}) {
+	if st != fuse.OK {
+		out = nil
+	}
+	s.Results = append(s.Results, Result{Op: op, Status: st, Out: out})
+}
+
+func (s *Session) skip(op string, ids ...uint64) bool {
+	for _, id := range ids {
+		if id == 0 {
+			s.record(op+" (skipped)", fuse.OK, nil)
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeAttr(a fuse.Attr) fuse.Attr {
+	a.Ino = 0
+	a.Atime, a.Atimensec = 0, 0
+	a.Mtime, a.Mtimensec = 0, 0
+	a.Ctime, a.Ctimensec = 0, 0
+	return a
+}
+
+func normalizeEntryOut(out fuse.EntryOut) fuse.EntryOut {
+	out.Attr = normalizeAttr(out.Attr)
+	return out
+}
+
+// Lookup looks up name in parent and returns the new node ID, or 0.
+func (s *Session) Lookup(parent uint64, name string) uint64 {
+	if s.skip("Lookup "+name, parent) {
+		return 0
+	}
+	var out fuse.EntryOut
+	header := s.Header(parent)
+	st := s.FS.Lookup(nil, &header, name, &out)
+	s.record("Lookup "+name, st, normalizeEntryOut(out))
+	return out.NodeId
+}
+
+// Forget drops nlookup references to nodeID.
+func (s *Session) Forget(nodeID, nlookup uint64) {
+	if s.skip("Forget", nodeID) {
+		return
+	}
+	s.FS.Forget(nodeID, nlookup)
+	s.record("Forget", fuse.OK, nil)
+}
+
+// GetAttr stats nodeID.
+func (s *Session) GetAttr(nodeID uint64) {
+	if s.skip("GetAttr", nodeID) {
+		return
+	}
+	var out fuse.AttrOut
+	st := s.FS.GetAttr(nil, &fuse.GetAttrIn{InHeader: s.Header(nodeID)}, &out)
+	out.Attr = normalizeAttr(out.Attr)
+	s.record("GetAttr", st, out)
+}
+
+// SetAttr changes the attributes of nodeID selected by in.Valid.
+func (s *Session) SetAttr(nodeID uint64, in fuse.SetAttrIn) {
+	if s.skip("SetAttr", nodeID) {
+		return
+	}
+	var out fuse.AttrOut
+	in.InHeader = s.Header(nodeID)
+	st := s.FS.SetAttr(nil, &in, &out)
+	out.Attr = normalizeAttr(out.Attr)
+	s.record("SetAttr", st, out)
+}
+
+// Mknod creates a special file and returns its node ID, or 0.
+func (s *Session) Mknod(parent uint64, name string, mode, rdev uint32) uint64 {
+	if s.skip("Mknod "+name, parent) {
+		return 0
+	}
+	var out fuse.EntryOut
+	st := s.FS.Mknod(nil, &fuse.MknodIn{InHeader: s.Header(parent), Mode: mode, Rdev: rdev}, name, &out)
+	s.record("Mknod "+name, st, normalizeEntryOut(out))
+	return out.NodeId
+}
+
+// Mkdir creates a directory and returns its node ID, or 0.
+func (s *Session) Mkdir(parent uint64, name string, mode uint32) uint64 {
+	if s.skip("Mkdir "+name, parent) {
+		return 0
+	}
+	var out fuse.EntryOut
+	st := s.FS.Mkdir(nil, &fuse.MkdirIn{InHeader: s.Header(parent), Mode: mode}, name, &out)
+	s.record("Mkdir "+name, st, normalizeEntryOut(out))
+	return out.NodeId
+}
+
+// Unlink removes name from parent.
+func (s *Session) Unlink(parent uint64, name string) {
+	if s.skip("Unlink "+name, parent) {
+		return
+	}
+	header := s.Header(parent)
+	s.record("Unlink "+name, s.FS.Unlink(nil, &header, name), nil)
+}
+
+// Rmdir removes the directory name from parent.
+func (s *Session) Rmdir(parent uint64, name string) {
+	if s.skip("Rmdir "+name, parent) {
+		return
+	}
+	header := s.Header(parent)
+	s.record("Rmdir "+name, s.FS.Rmdir(nil, &header, name), nil)
+}
+
+// Rename moves oldName in parent to newName in newParent.
+func (s *Session) Rename(parent uint64, oldName string, newParent uint64, newName string, flags uint32) {
+	op := fmt.Sprintf("Rename %s %s", oldName, newName)
+	if s.skip(op, parent, newParent) {
+		return
+	}
+	in := fuse.RenameIn{InHeader: s.Header(parent), Newdir: newParent, Flags: flags}
+	s.record(op, s.FS.Rename(nil, &in, oldName, newName), nil)
+}
+
+// Link adds a hard link to nodeID as name in parent.
+func (s *Session) Link(nodeID, parent uint64, name string) uint64 {
+	if s.skip("Link "+name, nodeID, parent) {
+		return 0
+	}
+	var out fuse.EntryOut
+	st := s.FS.Link(nil, &fuse.LinkIn{InHeader: s.Header(parent), Oldnodeid: nodeID}, name, &out)
+	s.record("Link "+name, st, normalizeEntryOut(out))
+	return out.NodeId
+}
+
+// Symlink creates name in parent pointing to target.
+func (s *Session) Symlink(parent uint64, target, name string) uint64 {
+	if s.skip("Symlink "+name, parent) {
+		return 0
+	}
+	var out fuse.EntryOut
+	header := s.Header(parent)
+	st := s.FS.Symlink(nil, &header, target, name, &out)
+	s.record("Symlink "+name, st, normalizeEntryOut(out))
+	return out.NodeId
+}
+
+// Readlink reads the target of the symlink nodeID.
+func (s *Session) Readlink(nodeID uint64) {
+	if s.skip("Readlink", nodeID) {
+		return
+	}
+	header := s.Header(nodeID)
+	target, st := s.FS.Readlink(nil, &header)
+	s.record("Readlink", st, string(target))
+}
+
+// Access checks mask against the permissions of nodeID.
+func (s *Session) Access(nodeID uint64, mask uint32) {
+	if s.skip("Access", nodeID) {
+		return
+	}
+	s.record("Access", s.FS.Access(nil, &fuse.AccessIn{InHeader: s.Header(nodeID), Mask: mask}), nil)
+}
+
+// GetXAttr reads attr into a buffer of size bytes.
+func (s *Session) GetXAttr(nodeID uint64, attr string, size int) {
+	if s.skip("GetXAttr "+attr, nodeID) {
+		return
+	}
+	header := s.Header(nodeID)
+	buf := make([]byte, size)
+	sz, st := s.FS.GetXAttr(nil, &header, attr, buf)
+	// The size is also defined on ERANGE.
+	s.Results = append(s.Results, Result{Op: "GetXAttr " + attr, Status: st, Out: xattrResult(buf, sz, st)})
+}
+
+// ListXAttr lists the attributes of nodeID into a buffer of size bytes.
+func (s *Session) ListXAttr(nodeID uint64, size int) {
+	if s.skip("ListXAttr", nodeID) {
+		return
+	}
+	header := s.Header(nodeID)
+	buf := make([]byte, size)
+	sz, st := s.FS.ListXAttr(nil, &header, buf)
+	s.Results = append(s.Results, Result{Op: "ListXAttr", Status: st, Out: xattrResult(buf, sz, st)})
+}
+
+type xattrOut struct {
+	Size  uint32
+	Value string
+}
+
+func xattrResult(buf []byte, sz uint32, st fuse.Status) xattrOut {
+	out := xattrOut{Size: sz}
+	if st == fuse.OK && len(buf) > 0 && int(sz) <= len(buf) {
+		out.Value = string(buf[:sz])
+	}
+	return out
+}
+
+// SetXAttr sets attr on nodeID.
+func (s *Session) SetXAttr(nodeID uint64, attr string, data []byte, flags uint32) {
+	if s.skip("SetXAttr "+attr, nodeID) {
+		return
+	}
+	in := fuse.SetXAttrIn{InHeader: s.Header(nodeID), Size: uint32(len(data)), Flags: flags}
+	s.record("SetXAttr "+attr, s.FS.SetXAttr(nil, &in, attr, data), nil)
+}
+
+// RemoveXAttr removes attr from nodeID.
+func (s *Session) RemoveXAttr(nodeID uint64, attr string) {
+	if s.skip("RemoveXAttr "+attr, nodeID) {
+		return
+	}
+	header := s.Header(nodeID)
+	s.record("RemoveXAttr "+attr, s.FS.RemoveXAttr(nil, &header, attr), nil)
+}
+
+// Create creates and opens name in parent, returning the node ID and the
+// file handle.
+func (s *Session) Create(parent uint64, name string, flags, mode uint32) (uint64, uint64) {
+	if s.skip("Create "+name, parent) {
+		return 0, 0
+	}
+	var out fuse.CreateOut
+	st := s.FS.Create(nil, &fuse.CreateIn{InHeader: s.Header(parent), Flags: flags, Mode: mode}, name, &out)
+	out.EntryOut = normalizeEntryOut(out.EntryOut)
+	s.record("Create "+name, st, out)
+	return out.NodeId, out.Fh
+}
+
+// Open opens nodeID and returns the file handle.
+func (s *Session) Open(nodeID uint64, flags uint32) uint64 {
+	if s.skip("Open", nodeID) {
+		return 0
+	}
+	var out fuse.OpenOut
+	st := s.FS.Open(nil, &fuse.OpenIn{InHeader: s.Header(nodeID), Flags: flags}, &out)
+	s.record("Open", st, out)
+	return out.Fh
+}
+
+// Read reads up to size bytes at off.
+func (s *Session) Read(nodeID, fh, off uint64, size uint32) {
+	if s.skip("Read", nodeID) {
+		return
+	}
+	buf := make([]byte, size)
+	in := fuse.ReadIn{InHeader: s.Header(nodeID), Fh: fh, Offset: off, Size: size}
+	res, st := s.FS.Read(nil, &in, buf)
+	var data []byte
+	if st == fuse.OK && res != nil {
+		data, st = res.Bytes(buf)
+		res.Done()
+	}
+	s.record(fmt.Sprintf("Read %d@%d", size, off), st, string(data))
+}
+
+// Lseek seeks in nodeID with SEEK_DATA or SEEK_HOLE.
+func (s *Session) Lseek(nodeID, fh, off uint64, whence uint32) {
+	if s.skip("Lseek", nodeID) {
+		return
+	}
+	var out fuse.LseekOut
+	in := fuse.LseekIn{InHeader: s.Header(nodeID), Fh: fh, Offset: off, Whence: whence}
+	st := s.FS.Lseek(nil, &in, &out)
+	s.record(fmt.Sprintf("Lseek %d/%d", off, whence), st, out)
+}
+
+func (s *Session) lkIn(nodeID, fh, owner uint64, lk fuse.FileLock) *fuse.LkIn {
+	return &fuse.LkIn{InHeader: s.Header(nodeID), Fh: fh, Owner: owner, Lk: lk}
+}
+
+// GetLk tests for a lock conflicting with lk.
+func (s *Session) GetLk(nodeID, fh, owner uint64, lk fuse.FileLock) {
+	if s.skip("GetLk", nodeID) {
+		return
+	}
+	var out fuse.LkOut
+	st := s.FS.GetLk(nil, s.lkIn(nodeID, fh, owner, lk), &out)
+	// The pid of a conflicting lock is the server's.
+	out.Lk.Pid = 0
+	s.record("GetLk", st, out)
+}
+
+// SetLk takes or releases lk without waiting.
+func (s *Session) SetLk(nodeID, fh, owner uint64, lk fuse.FileLock) {
+	if s.skip("SetLk", nodeID) {
+		return
+	}
+	s.record("SetLk", s.FS.SetLk(nil, s.lkIn(nodeID, fh, owner, lk)), nil)
+}
+
+// SetLkw takes or releases lk, waiting for conflicting locks.
+func (s *Session) SetLkw(nodeID, fh, owner uint64, lk fuse.FileLock) {
+	if s.skip("SetLkw", nodeID) {
+		return
+	}
+	s.record("SetLkw", s.FS.SetLkw(nil, s.lkIn(nodeID, fh, owner, lk)), nil)
+}
+
+// Release closes the file handle fh.
+func (s *Session) Release(nodeID, fh uint64) {
+	if s.skip("Release", nodeID) {
+		return
+	}
+	s.FS.Release(nil, &fuse.ReleaseIn{InHeader: s.Header(nodeID), Fh: fh})
+	s.record("Release", fuse.OK, nil)
+}
+
+// Write writes data at off.
+func (s *Session) Write(nodeID, fh, off uint64, data []byte) {
+	if s.skip("Write", nodeID) {
+		return
+	}
+	in := fuse.WriteIn{InHeader: s.Header(nodeID), Fh: fh, Offset: off, Size: uint32(len(data))}
+	n, st := s.FS.Write(nil, &in, data)
+	s.record(fmt.Sprintf("Write %d@%d", len(data), off), st, n)
+}
+
+// CopyFileRange copies n bytes from one open file to another.
+func (s *Session) CopyFileRange(nodeIn, fhIn, offIn, nodeOut, fhOut, offOut, n uint64) {
+	if s.skip("CopyFileRange", nodeIn, nodeOut) {
+		return
+	}
+	in := fuse.CopyFileRangeIn{
+		InHeader:  s.Header(nodeIn),
+		FhIn:      fhIn,
+		OffIn:     offIn,
+		NodeIdOut: nodeOut,
+		FhOut:     fhOut,
+		OffOut:    offOut,
+		Len:       n,
+	}
+	written, st := s.FS.CopyFileRange(nil, &in)
+	s.record("CopyFileRange", st, written)
+}
+
+// Flush is called on every close of fh.
+func (s *Session) Flush(nodeID, fh uint64) {
+	if s.skip("Flush", nodeID) {
+		return
+	}
+	s.record("Flush", s.FS.Flush(nil, &fuse.FlushIn{InHeader: s.Header(nodeID), Fh: fh}), nil)
+}
+
+// Fsync flushes fh to stable storage.
+func (s *Session) Fsync(nodeID, fh uint64, flags uint32) {
+	if s.skip("Fsync", nodeID) {
+		return
+	}
+	s.record("Fsync", s.FS.Fsync(nil, &fuse.FsyncIn{InHeader: s.Header(nodeID), Fh: fh, FsyncFlags: flags}), nil)
+}
+
+// Fallocate allocates or punches the range [off, off+n).
+func (s *Session) Fallocate(nodeID, fh, off, n uint64, mode uint32) {
+	if s.skip("Fallocate", nodeID) {
+		return
+	}
+	in := fuse.FallocateIn{InHeader: s.Header(nodeID), Fh: fh, Offset: off, Length: n, Mode: mode}
+	s.record(fmt.Sprintf("Fallocate %d@%d/%d", n, off, mode), s.FS.Fallocate(nil, &in), nil)
+}
+
+// OpenDir opens the directory nodeID and returns the handle.
+func (s *Session) OpenDir(nodeID uint64) uint64 {
+	if s.skip("OpenDir", nodeID) {
+		return 0
+	}
+	var out fuse.OpenOut
+	st := s.FS.OpenDir(nil, &fuse.OpenIn{InHeader: s.Header(nodeID)}, &out)
+	s.record("OpenDir", st, out)
+	return out.Fh
+}
+
+// Dirent is an entry of a READDIR or READDIRPLUS reply.
+type Dirent struct {
+	Name     string
+	Off      uint64
+	Type     uint32
+	EntryOut *fuse.EntryOut
+}
+
+// ReadDir lists nodeID from off into a buffer of size bytes and returns the
+// offset of the last entry, or off if there was none.
+func (s *Session) ReadDir(nodeID, fh, off uint64, size uint32) uint64 {
+	return s.readDir("ReadDir", s.FS.ReadDir, false, nodeID, fh, off, size)
+}
+
+// ReadDirPlus is ReadDir with an EntryOut per entry.
+func (s *Session) ReadDirPlus(nodeID, fh, off uint64, size uint32) uint64 {
+	return s.readDir("ReadDirPlus", s.FS.ReadDirPlus, true, nodeID, fh, off, size)
+}
+
+func (s *Session) readDir(
+	op string,
+	read func(<-chan struct{}, *fuse.ReadIn, *fuse.DirEntryList) fuse.Status,
+	plus bool,
+	nodeID, fh, off uint64,
+	size uint32,
+) uint64 {
+	op = fmt.Sprintf("%s %d@%d", op, size, off)
+	if s.skip(op, nodeID) {
+		return off
+	}
+	buf := make([]byte, size)
+	in := fuse.ReadIn{InHeader: s.Header(nodeID), Fh: fh, Offset: off, Size: size}
+	st := read(nil, &in, fuse.NewDirEntryList(buf, off))
+	entries := ParseDirents(buf, plus)
+	for _, e := range entries {
+		if e.EntryOut != nil {
+			*e.EntryOut = normalizeEntryOut(*e.EntryOut)
+		}
+	}
+	s.record(op, st, entries)
+	if len(entries) == 0 {
+		return off
+	}
+	return entries[len(entries)-1].Off
+}
+
+// ReleaseDir closes the directory handle fh.
+func (s *Session) ReleaseDir(nodeID, fh uint64) {
+	if s.skip("ReleaseDir", nodeID) {
+		return
+	}
+	s.FS.ReleaseDir(&fuse.ReleaseIn{InHeader: s.Header(nodeID), Fh: fh})
+	s.record("ReleaseDir", fuse.OK, nil)
+}
+
+// FsyncDir flushes the directory handle fh.
+func (s *Session) FsyncDir(nodeID, fh uint64) {
+	if s.skip("FsyncDir", nodeID) {
+		return
+	}
+	s.record("FsyncDir", s.FS.FsyncDir(nil, &fuse.FsyncIn{InHeader: s.Header(nodeID), Fh: fh}), nil)
+}
+
+// String records the name of the file system.
+func (s *Session) String() {
+	s.record("String", fuse.OK, s.FS.String())
+}
+
+// StatFs reports the file system nodeID lives on.
+func (s *Session) StatFs(nodeID uint64) {
+	if s.skip("StatFs", nodeID) {
+		return
+	}
+	var out fuse.StatfsOut
+	header := s.Header(nodeID)
+	st := s.FS.StatFs(nil, &header, &out)
+	// Free space changes under our feet.
+	out.Bfree, out.Bavail, out.Ffree = 0, 0, 0
+	s.record("StatFs", st, out)
+}
+
+// ParseDirents decodes a READDIR or READDIRPLUS buffer the way the kernel
+// does, stopping at the first zero offset.
+func ParseDirents(buf []byte, plus bool) []Dirent {
+	const direntSize = 24
+	var (
+		entries []Dirent
+		prefix  int
+	)
+	if plus {
+		prefix = int(unsafe.Sizeof(fuse.EntryOut{}))
+	}
+	for pos := 0; pos+prefix+direntSize <= len(buf); {
+		var e Dirent
+		if plus {
+			e.EntryOut = &fuse.EntryOut{}
+			binary.Read(bytes.NewReader(buf[pos:pos+prefix]), binary.LittleEndian, e.EntryOut)
+			pos += prefix
+		}
+		e.Off = binary.LittleEndian.Uint64(buf[pos+8:])
+		nameLen := int(binary.LittleEndian.Uint32(buf[pos+16:]))
+		e.Type = binary.LittleEndian.Uint32(buf[pos+20:])
+		if e.Off == 0 || pos+direntSize+nameLen > len(buf) {
+			break
+		}
+		e.Name = string(buf[pos+direntSize : pos+direntSize+nameLen])
+		entries = append(entries, e)
+		pos += direntSize + nameLen + (8-nameLen&7)&7
+	}
+	return entries
+}
@@ -0,0 +1,543 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package journal keeps the changes made to a file system while its
+// server is unreachable, in a directory on local disk, until they are
+// sent to the server: the operations in the order they were made, the
+// data of the files written, and the conflicts found when sending them.
+//
+// An operation carries the version of its path it was made to, as last
+// seen on the server, so that changes made on the server in the meantime
+// are found before they are overwritten.
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	pendingFile   = "pending"
+	conflictsFile = "conflicts"
+	dataDir       = "data"
+)
+
+// Kind is what an operation does.
+type Kind string
+
+const (
+	// Put makes the file at Path hold Data, creating it with Mode if
+	// needed.
+	Put Kind = "put"
+	// Mkdir makes the directory Path with Mode.
+	Mkdir Kind = "mkdir"
+	// Symlink makes Path a symlink to Target.
+	Symlink Kind = "symlink"
+	// Unlink removes the file at Path, and Rmdir the empty directory.
+	Unlink Kind = "unlink"
+	Rmdir  Kind = "rmdir"
+	// Rename moves Path to To, replacing what is there.
+	Rename Kind = "rename"
+	// SetAttr sets the Mode, Atime and Mtime of Path that Valid has, as
+	// the fuse FATTR_ bits.
+	SetAttr Kind = "setattr"
+)
+
+// Version is a version of a file on the server.
+type Version struct {
+	Ino  uint64
+	Size uint64
+	// Mtime and Ctime are in nanoseconds since the epoch.
+	Mtime, Ctime int64
+}
+
+// Op is an operation made while disconnected. Paths are absolute within
+// the file system.
+type Op struct {
+	ID     uint64
+	Kind   Kind
+	Path   string
+	To     string `json:",omitempty"`
+	Target string `json:",omitempty"`
+	// Data names the file of the journal with the content of a Put.
+	Data  string `json:",omitempty"`
+	Mode  uint32 `json:",omitempty"`
+	Valid uint32 `json:",omitempty"`
+	// Atime and Mtime are in nanoseconds since the epoch.
+	Atime int64 `json:",omitempty"`
+	Mtime int64 `json:",omitempty"`
+	// Uid and Gid are of the process that made the change.
+	Uid, Gid uint32
+	// Base is the version of Path the change was made to, and ToBase of
+	// To for a Rename; nil if there was nothing.
+	Base   *Version `json:",omitempty"`
+	ToBase *Version `json:",omitempty"`
+	Time   time.Time
+}
+
+func (op Op) String() string {
+	switch op.Kind {
+	case Rename:
+		return fmt.Sprintf("%s %s -> %s", op.Kind, op.Path, op.To)
+	case Symlink:
+		return fmt.Sprintf("%s %s -> %s", op.Kind, op.Path, op.Target)
+	}
+	return fmt.Sprintf("%s %s", op.Kind, op.Path)
+}
+
+// Conflict is an operation that met a change made on the server, and
+// what was done about it.
+type Conflict struct {
+	Op Op
+	// Reason is what was found on the server.
+	Reason string
+	// Resolution is what was done, empty if the operation was dropped.
+	Resolution string `json:",omitempty"`
+	Time       time.Time
+}
+
+// Journal is a directory of operations. It is safe for concurrent use.
+// Only one Journal opened with Open may change a directory at a time;
+// others may read it with OpenReadOnly.
+type Journal struct {
+	dir string
+	// lock holds the flock of dir, nil if read only.
+	lock *os.File
+
+	mu   sync.Mutex
+	ops  []Op
+	next uint64
+	// done counts the operations dropped since the pending ones were
+	// last written whole.
+	done int
+}
+
+// done marks the operation of that ID as dropped, in the pending file.
+type done struct {
+	Done uint64
+}
+
+// Open opens the journal in dir, creating it if needed, with the
+// operations left pending there. It fails if another Journal, of this
+// process or another, has dir open until that one is closed.
+func Open(dir string) (*Journal, error) {
+	if err := os.MkdirAll(filepath.Join(dir, dataDir), 0700); err != nil {
+		return nil, fmt.Errorf("journal: %v", err)
+	}
+	lock, err := os.Open(dir)
+	if err != nil {
+		return nil, fmt.Errorf("journal: %v", err)
+	}
+	if err := unix.Flock(int(lock.Fd()), unix.LOCK_EX|unix.LOCK_NB); err != nil {
+		lock.Close()
+		if err == unix.EWOULDBLOCK {
+			return nil, fmt.Errorf("journal: %s is in use", dir)
+		}
+		return nil, fmt.Errorf("journal: %s: %v", dir, err)
+	}
+	j := &Journal{dir: dir, lock: lock, next: 1}
+	torn, err := j.load()
+	if err == nil && (torn || j.done > 0) {
+		// The last line was cut short by a crash while appending it:
+		// leave it out, and the operations marked done.
+		err = j.compact()
+	}
+	if err != nil {
+		lock.Close()
+		return nil, err
+	}
+	return j, nil
+}
+
+// OpenReadOnly opens the journal in dir to read it, while it may be open
+// with Open elsewhere; its methods that change it fail.
+func OpenReadOnly(dir string) (*Journal, error) {
+	j := &Journal{dir: dir, next: 1}
+	if _, err := j.load(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Close releases the journal for others to Open.
+func (j *Journal) Close() error {
+	if j.lock == nil {
+		return nil
+	}
+	return j.lock.Close()
+}
+
+// errReadOnly is returned by the changes to a journal opened read only.
+var errReadOnly = errors.New("journal: opened read only")
+
+// load reads the pending operations, less those marked done, and the
+// next ID. A last line that does not parse is left out, and reported as
+// torn.
+func (j *Journal) load() (torn bool, err error) {
+	var bad error
+	dropped := map[uint64]bool{}
+	if err := readLines(filepath.Join(j.dir, pendingFile), func(data []byte) error {
+		if bad != nil {
+			return bad
+		}
+		var d done
+		if err := json.Unmarshal(data, &d); err == nil && d.Done != 0 {
+			dropped[d.Done] = true
+			if d.Done >= j.next {
+				j.next = d.Done + 1
+			}
+			return nil
+		}
+		var op Op
+		if err := json.Unmarshal(data, &op); err != nil {
+			bad = err
+			return nil
+		}
+		j.ops = append(j.ops, op)
+		return nil
+	}); err != nil {
+		return false, fmt.Errorf("journal: %v", err)
+	}
+	if len(dropped) > 0 {
+		ops := j.ops[:0]
+		for _, op := range j.ops {
+			if !dropped[op.ID] {
+				ops = append(ops, op)
+			}
+		}
+		j.ops = ops
+		j.done = len(dropped)
+	}
+	conflicts, err := j.Conflicts()
+	if err != nil {
+		return false, err
+	}
+	for _, op := range j.ops {
+		if op.ID >= j.next {
+			j.next = op.ID + 1
+		}
+	}
+	for _, c := range conflicts {
+		if c.Op.ID >= j.next {
+			j.next = c.Op.ID + 1
+		}
+	}
+	return bad != nil, nil
+}
+
+// readLines calls f with each line of the file at path, if there is one.
+func readLines(path string, f func(data []byte) error) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	s := bufio.NewScanner(file)
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		if len(s.Bytes()) == 0 {
+			continue
+		}
+		if err := f(s.Bytes()); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	return s.Err()
+}
+
+// Dir returns the directory of the journal.
+func (j *Journal) Dir() string {
+	return j.dir
+}
+
+// Append adds op to the end of the journal, and returns it with its ID
+// and time.
+func (j *Journal) Append(op Op) (Op, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.lock == nil {
+		return Op{}, errReadOnly
+	}
+	op.ID = j.next
+	if op.Time.IsZero() {
+		op.Time = time.Now()
+	}
+	if err := appendLine(filepath.Join(j.dir, pendingFile), op); err != nil {
+		return Op{}, fmt.Errorf("journal: %v", err)
+	}
+	j.ops = append(j.ops, op)
+	j.next++
+	return op, nil
+}
+
+// Pending returns the operations not yet done, in order.
+func (j *Journal) Pending() []Op {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]Op(nil), j.ops...)
+}
+
+// Done drops the operation id, once sent to the server. Its data stays
+// until pruned.
+func (j *Journal) Done(id uint64) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.lock == nil {
+		return errReadOnly
+	}
+	return j.drop(id)
+}
+
+// Conflict records c and drops its operation. The data of the operation
+// is kept until the conflicts are cleared.
+func (j *Journal) Conflict(c Conflict) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.lock == nil {
+		return errReadOnly
+	}
+	if c.Time.IsZero() {
+		c.Time = time.Now()
+	}
+	if err := appendLine(filepath.Join(j.dir, conflictsFile), c); err != nil {
+		return fmt.Errorf("journal: %v", err)
+	}
+	return j.drop(c.Op.ID)
+}
+
+// appendLine appends v to the file at path as a line of JSON, and syncs
+// it.
+func appendLine(path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// drop removes the operation id, appending a mark of it to the pending
+// ones, which are written whole again only once none is left, or by
+// Compact; j.mu is held.
+func (j *Journal) drop(id uint64) error {
+	i := 0
+	for i < len(j.ops) && j.ops[i].ID != id {
+		i++
+	}
+	if i == len(j.ops) {
+		return nil
+	}
+	if len(j.ops) == 1 {
+		j.ops = nil
+		return j.compact()
+	}
+	if err := appendLine(filepath.Join(j.dir, pendingFile), done{Done: id}); err != nil {
+		return fmt.Errorf("journal: %v", err)
+	}
+	if i == 0 {
+		// Operations are mostly done in order.
+		j.ops = j.ops[1:]
+	} else {
+		j.ops = append(j.ops[:i:i], j.ops[i+1:]...)
+	}
+	j.done++
+	return nil
+}
+
+// Compact writes the pending operations whole again, without the marks
+// of those done since, as after replaying them.
+func (j *Journal) Compact() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.lock == nil {
+		return errReadOnly
+	}
+	if j.done == 0 {
+		return nil
+	}
+	return j.compact()
+}
+
+// compact writes the pending operations whole; j.mu is held, or j is
+// being opened.
+func (j *Journal) compact() error {
+	if err := j.write(j.ops); err != nil {
+		return fmt.Errorf("journal: %v", err)
+	}
+	j.done = 0
+	return nil
+}
+
+// used returns the data of ops and of the conflicts.
+func (j *Journal) used(ops []Op) (map[string]bool, error) {
+	conflicts, err := j.Conflicts()
+	if err != nil {
+		return nil, err
+	}
+	used := map[string]bool{}
+	for _, op := range ops {
+		used[op.Data] = true
+	}
+	for _, c := range conflicts {
+		used[c.Op.Data] = true
+	}
+	return used, nil
+}
+
+// Prune removes the data of no pending operation or conflict, but for
+// keep.
+func (j *Journal) Prune(keep map[string]bool) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.lock == nil {
+		return errReadOnly
+	}
+	used, err := j.used(j.ops)
+	if err != nil {
+		return err
+	}
+	names, err := ioutil.ReadDir(filepath.Join(j.dir, dataDir))
+	if err != nil {
+		return fmt.Errorf("journal: %v", err)
+	}
+	for _, fi := range names {
+		if used[fi.Name()] || keep[fi.Name()] {
+			continue
+		}
+		if err := os.Remove(j.DataPath(fi.Name())); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("journal: %v", err)
+		}
+	}
+	return nil
+}
+
+// write replaces the pending operations with ops; it is written aside
+// and renamed, so that a crash leaves the old or the new ones.
+func (j *Journal) write(ops []Op) error {
+	f, err := ioutil.TempFile(j.dir, ".tmp")
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, op := range ops {
+		if err = enc.Encode(op); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), filepath.Join(j.dir, pendingFile))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// Conflicts returns the conflicts recorded since they were last cleared.
+func (j *Journal) Conflicts() ([]Conflict, error) {
+	var conflicts []Conflict
+	err := readLines(filepath.Join(j.dir, conflictsFile), func(data []byte) error {
+		var c Conflict
+		if err := json.Unmarshal(data, &c); err != nil {
+			return err
+		}
+		conflicts = append(conflicts, c)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("journal: %v", err)
+	}
+	return conflicts, nil
+}
+
+// ClearConflicts forgets the conflicts, and removes the data kept for
+// them.
+func (j *Journal) ClearConflicts() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.lock == nil {
+		return errReadOnly
+	}
+	conflicts, err := j.Conflicts()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(j.dir, conflictsFile)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("journal: %v", err)
+	}
+	used, err := j.used(j.ops)
+	if err != nil {
+		return err
+	}
+	for _, c := range conflicts {
+		if c.Op.Data == "" || used[c.Op.Data] {
+			continue
+		}
+		if err := os.Remove(j.DataPath(c.Op.Data)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("journal: %v", err)
+		}
+	}
+	return nil
+}
+
+// NewData creates a file for the content of a Put, returning its name.
+func (j *Journal) NewData() (string, *os.File, error) {
+	if j.lock == nil {
+		return "", nil, errReadOnly
+	}
+	f, err := ioutil.TempFile(filepath.Join(j.dir, dataDir), "")
+	if err != nil {
+		return "", nil, fmt.Errorf("journal: %v", err)
+	}
+	return filepath.Base(f.Name()), f, nil
+}
+
+// DataPath returns where the data of that name is.
+func (j *Journal) DataPath(name string) string {
+	return filepath.Join(j.dir, dataDir, name)
+}
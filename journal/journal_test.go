@@ -0,0 +1,229 @@
+package journal
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournal(t *testing.T) {
+	dir := t.TempDir()
+	j, err := Open(dir)
+	require.NoError(t, err)
+	assert.Empty(t, j.Pending())
+
+	name, f, err := j.NewData()
+	require.NoError(t, err)
+	_, err = f.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	base := &Version{Ino: 3, Size: 1, Mtime: 10, Ctime: 11}
+	put, err := j.Append(Op{Kind: Put, Path: "/d/f", Data: name, Mode: 0644, Base: base})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), put.ID)
+	assert.False(t, put.Time.IsZero())
+	mkdir, err := j.Append(Op{Kind: Mkdir, Path: "/e", Mode: 0755})
+	require.NoError(t, err)
+	rename, err := j.Append(Op{Kind: Rename, Path: "/e", To: "/g", Base: base})
+	require.NoError(t, err)
+	assert.Equal(t, "rename /e -> /g", rename.String())
+	assert.Equal(t, "put /d/f", put.String())
+
+	// What is pending outlives the journal.
+	require.NoError(t, j.Close())
+	j, err = Open(dir)
+	require.NoError(t, err)
+	ops := j.Pending()
+	require.Len(t, ops, 3)
+	assert.Equal(t, []Kind{Put, Mkdir, Rename}, []Kind{ops[0].Kind, ops[1].Kind, ops[2].Kind})
+	assert.Equal(t, base, ops[0].Base)
+	assert.Nil(t, ops[1].Base)
+	assert.True(t, put.Time.Equal(ops[0].Time))
+
+	require.NoError(t, j.Done(mkdir.ID))
+	assert.Len(t, j.Pending(), 2)
+	require.NoError(t, j.Done(mkdir.ID), "done twice")
+
+	// The data of a conflict is kept until the conflicts are cleared.
+	require.NoError(t, j.Conflict(Conflict{Op: ops[0], Reason: "changed on the server"}))
+	assert.Len(t, j.Pending(), 1)
+	conflicts, err := j.Conflicts()
+	require.NoError(t, err)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, "changed on the server", conflicts[0].Reason)
+	assert.Equal(t, "/d/f", conflicts[0].Op.Path)
+	data, err := ioutil.ReadFile(j.DataPath(name))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	require.NoError(t, j.Close())
+	j, err = Open(dir)
+	require.NoError(t, err)
+	defer j.Close()
+	next, err := j.Append(Op{Kind: Unlink, Path: "/x"})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), next.ID, "after the IDs of the conflicts too")
+
+	require.NoError(t, j.ClearConflicts())
+	conflicts, err = j.Conflicts()
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+	_, err = os.Stat(j.DataPath(name))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDone(t *testing.T) {
+	dir := t.TempDir()
+	j, err := Open(dir)
+	require.NoError(t, err)
+	for _, p := range []string{"/a", "/b", "/c"} {
+		_, err := j.Append(Op{Kind: Mkdir, Path: p})
+		require.NoError(t, err)
+	}
+	pending := filepath.Join(dir, pendingFile)
+	before, err := ioutil.ReadFile(pending)
+	require.NoError(t, err)
+
+	// Dropping an operation only appends a mark of it.
+	require.NoError(t, j.Done(1))
+	require.NoError(t, j.Done(3))
+	after, err := ioutil.ReadFile(pending)
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(after[:len(before)]))
+	assert.Equal(t, "{\"Done\":1}\n{\"Done\":3}\n", string(after[len(before):]))
+	assert.Len(t, j.Pending(), 1)
+
+	r, err := OpenReadOnly(dir)
+	require.NoError(t, err)
+	ops := r.Pending()
+	require.Len(t, ops, 1)
+	assert.Equal(t, "/b", ops[0].Path)
+	assert.Error(t, r.Compact())
+
+	// Compacting leaves out the marks and what they drop.
+	require.NoError(t, j.Compact())
+	after, err = ioutil.ReadFile(pending)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(after), "\n"))
+	assert.NotContains(t, string(after), "Done")
+
+	// The marks count for the next ID, and go when the journal is opened.
+	_, err = j.Append(Op{Kind: Mkdir, Path: "/d"})
+	require.NoError(t, err)
+	require.NoError(t, j.Done(4))
+	require.NoError(t, j.Close())
+	j, err = Open(dir)
+	require.NoError(t, err)
+	defer j.Close()
+	require.Len(t, j.Pending(), 1)
+	after, err = ioutil.ReadFile(pending)
+	require.NoError(t, err)
+	assert.NotContains(t, string(after), "Done")
+	op, err := j.Append(Op{Kind: Mkdir, Path: "/e"})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), op.ID)
+
+	// Once none is pending, the file is emptied.
+	require.NoError(t, j.Done(2))
+	require.NoError(t, j.Done(5))
+	after, err = ioutil.ReadFile(pending)
+	require.NoError(t, err)
+	assert.Empty(t, after)
+}
+
+func TestPrune(t *testing.T) {
+	j, err := Open(t.TempDir())
+	require.NoError(t, err)
+	data := func() string {
+		name, f, err := j.NewData()
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		return name
+	}
+	exists := func(name string) bool {
+		_, err := os.Stat(j.DataPath(name))
+		return err == nil
+	}
+	done, pending, conflict, kept := data(), data(), data(), data()
+	op, err := j.Append(Op{Kind: Put, Path: "/a", Data: done})
+	require.NoError(t, err)
+	require.NoError(t, j.Done(op.ID))
+	assert.True(t, exists(done), "until pruned")
+	_, err = j.Append(Op{Kind: Put, Path: "/b", Data: pending})
+	require.NoError(t, err)
+	op, err = j.Append(Op{Kind: Put, Path: "/c", Data: conflict})
+	require.NoError(t, err)
+	require.NoError(t, j.Conflict(Conflict{Op: op, Reason: "gone"}))
+
+	require.NoError(t, j.Prune(map[string]bool{kept: true}))
+	assert.False(t, exists(done))
+	assert.True(t, exists(pending))
+	assert.True(t, exists(conflict))
+	assert.True(t, exists(kept))
+}
+
+func TestOpenTorn(t *testing.T) {
+	dir := t.TempDir()
+	j, err := Open(dir)
+	require.NoError(t, err)
+	_, err = j.Append(Op{Kind: Mkdir, Path: "/a"})
+	require.NoError(t, err)
+	require.NoError(t, j.Close())
+	f, err := os.OpenFile(filepath.Join(dir, pendingFile), os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.Write([]byte("{\"ID\":2"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// Cut short while appended, the last one is left out.
+	j, err = Open(dir)
+	require.NoError(t, err)
+	require.Len(t, j.Pending(), 1)
+	op, err := j.Append(Op{Kind: Mkdir, Path: "/b"})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), op.ID)
+	require.NoError(t, j.Close())
+	j, err = Open(dir)
+	require.NoError(t, err)
+	assert.Len(t, j.Pending(), 2)
+	require.NoError(t, j.Close())
+
+	// Anywhere else, the journal is broken.
+	data, err := ioutil.ReadFile(filepath.Join(dir, pendingFile))
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, pendingFile), append([]byte("{\"ID\":1\n"), data...), 0600))
+	_, err = Open(dir)
+	assert.Error(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, pendingFile), data, 0600))
+	j, err = Open(dir)
+	require.NoError(t, err, "not left locked")
+	require.NoError(t, j.Close())
+}
+
+func TestOpenLocked(t *testing.T) {
+	dir := t.TempDir()
+	j, err := Open(dir)
+	require.NoError(t, err)
+	_, err = j.Append(Op{Kind: Mkdir, Path: "/a"})
+	require.NoError(t, err)
+
+	_, err = Open(dir)
+	assert.EqualError(t, err, "journal: "+dir+" is in use")
+	r, err := OpenReadOnly(dir)
+	require.NoError(t, err)
+	assert.Len(t, r.Pending(), 1)
+	_, err = r.Append(Op{Kind: Mkdir, Path: "/b"})
+	assert.Error(t, err)
+	assert.Error(t, r.ClearConflicts())
+
+	require.NoError(t, j.Close())
+	j, err = Open(dir)
+	require.NoError(t, err)
+	require.NoError(t, j.Close())
+}
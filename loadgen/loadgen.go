@@ -0,0 +1,342 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package loadgen runs file system workloads on a RawFileSystem, usually a
+// grpc2fuse client, to measure throughput and operation rates. The same
+// workloads back the benchmarks of the package and example/loadgen.
+package loadgen
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+
+	"github.com/chiyutianyi/grpcfuse/grpcfusetest"
+)
+
+// Options sizes a workload. The zero value is usable.
+type Options struct {
+	// Size is the size of each read or write, 64KiB by default.
+	Size int
+	// FileSize is the size of the file read from, 64MiB by default;
+	// reads wrap around it.
+	FileSize int
+	// Entries is the number of entries of the listed directory, 10000 by
+	// default.
+	Entries int
+}
+
+func (o *Options) withDefaults() Options {
+	opts := Options{}
+	if o != nil {
+		opts = *o
+	}
+	if opts.Size <= 0 {
+		opts.Size = 64 << 10
+	}
+	if opts.FileSize <= 0 {
+		opts.FileSize = 64 << 20
+	}
+	if opts.FileSize < opts.Size {
+		opts.FileSize = opts.Size
+	}
+	if opts.Entries <= 0 {
+		opts.Entries = 10000
+	}
+	return opts
+}
+
+// Op runs the i-th operation of a workload and returns the bytes it
+// moved, or for the directory workloads the entries it listed. Ops may run
+// concurrently with different i.
+type Op func(i int) (int, error)
+
+// Workload prepares rfs for its operations, under a directory of its own
+// named dir in the root, and returns them.
+type Workload func(rfs fuse.RawFileSystem, dir string, opts *Options) (Op, error)
+
+// Workloads are the workloads by name.
+var Workloads = map[string]Workload{
+	"read":        Read,
+	"write":       Write,
+	"smallfile":   SmallFile,
+	"readdir":     ReadDir,
+	"readdirplus": ReadDirPlus,
+}
+
+// Names returns the names of the Workloads, sorted.
+func Names() []string {
+	var names []string
+	for name := range Workloads {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func header(nodeID uint64) fuse.InHeader {
+	return fuse.InHeader{NodeId: nodeID}
+}
+
+func fail(op string, st fuse.Status) error {
+	return fmt.Errorf("%s: %v", op, st)
+}
+
+func mkdir(rfs fuse.RawFileSystem, parent uint64, name string) (uint64, error) {
+	var out fuse.EntryOut
+	if st := rfs.Mkdir(nil, &fuse.MkdirIn{InHeader: header(parent), Mode: 0755}, name, &out); !st.Ok() {
+		return 0, fail("mkdir "+name, st)
+	}
+	return out.NodeId, nil
+}
+
+func create(rfs fuse.RawFileSystem, parent uint64, name string) (uint64, uint64, error) {
+	var out fuse.CreateOut
+	st := rfs.Create(nil, &fuse.CreateIn{InHeader: header(parent), Flags: syscall.O_RDWR, Mode: 0644}, name, &out)
+	if !st.Ok() {
+		return 0, 0, fail("create "+name, st)
+	}
+	return out.NodeId, out.Fh, nil
+}
+
+// file creates a file of size bytes and returns it open.
+func file(rfs fuse.RawFileSystem, parent uint64, size, chunk int) (uint64, uint64, error) {
+	node, fh, err := create(rfs, parent, "file")
+	if err != nil {
+		return 0, 0, err
+	}
+	data := make([]byte, chunk)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	for off := 0; off < size; off += chunk {
+		n := chunk
+		if off+n > size {
+			n = size - off
+		}
+		if _, st := rfs.Write(nil, &fuse.WriteIn{InHeader: header(node), Fh: fh, Offset: uint64(off)}, data[:n]); !st.Ok() {
+			return 0, 0, fail("write", st)
+		}
+	}
+	return node, fh, nil
+}
+
+// Read reads Size bytes at a time from a file of FileSize bytes.
+func Read(rfs fuse.RawFileSystem, dir string, opts *Options) (Op, error) {
+	o := opts.withDefaults()
+	parent, err := mkdir(rfs, fuse.FUSE_ROOT_ID, dir)
+	if err != nil {
+		return nil, err
+	}
+	node, fh, err := file(rfs, parent, o.FileSize, o.Size)
+	if err != nil {
+		return nil, err
+	}
+	blocks := o.FileSize / o.Size
+	bufs := sync.Pool{New: func() interface{} { return make([]byte, o.Size) }}
+	return func(i int) (int, error) {
+		buf := bufs.Get().([]byte)
+		defer bufs.Put(buf)
+		in := &fuse.ReadIn{InHeader: header(node), Fh: fh, Offset: uint64(i%blocks) * uint64(o.Size), Size: uint32(o.Size)}
+		res, st := rfs.Read(nil, in, buf)
+		if !st.Ok() {
+			return 0, fail("read", st)
+		}
+		n := res.Size()
+		res.Done()
+		return n, nil
+	}, nil
+}
+
+// Write writes Size bytes at a time to a file, wrapping around at
+// FileSize bytes.
+func Write(rfs fuse.RawFileSystem, dir string, opts *Options) (Op, error) {
+	o := opts.withDefaults()
+	parent, err := mkdir(rfs, fuse.FUSE_ROOT_ID, dir)
+	if err != nil {
+		return nil, err
+	}
+	node, fh, err := create(rfs, parent, "file")
+	if err != nil {
+		return nil, err
+	}
+	blocks := o.FileSize / o.Size
+	data := make([]byte, o.Size)
+	return func(i int) (int, error) {
+		in := &fuse.WriteIn{InHeader: header(node), Fh: fh, Offset: uint64(i%blocks) * uint64(o.Size)}
+		n, st := rfs.Write(nil, in, data)
+		if !st.Ok() {
+			return 0, fail("write", st)
+		}
+		return int(n), nil
+	}, nil
+}
+
+// SmallFile creates a file, writes Size bytes to it, stats it, closes it
+// and unlinks it.
+func SmallFile(rfs fuse.RawFileSystem, dir string, opts *Options) (Op, error) {
+	o := opts.withDefaults()
+	parent, err := mkdir(rfs, fuse.FUSE_ROOT_ID, dir)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, o.Size)
+	return func(i int) (int, error) {
+		name := fmt.Sprintf("f%d", i)
+		node, fh, err := create(rfs, parent, name)
+		if err != nil {
+			return 0, err
+		}
+		n, st := rfs.Write(nil, &fuse.WriteIn{InHeader: header(node), Fh: fh}, data)
+		if !st.Ok() {
+			return 0, fail("write", st)
+		}
+		var attr fuse.AttrOut
+		if st := rfs.GetAttr(nil, &fuse.GetAttrIn{InHeader: header(node)}, &attr); !st.Ok() {
+			return 0, fail("getattr", st)
+		}
+		rfs.Release(nil, &fuse.ReleaseIn{InHeader: header(node), Fh: fh})
+		if st := rfs.Unlink(nil, &fuse.InHeader{NodeId: parent}, name); !st.Ok() {
+			return 0, fail("unlink", st)
+		}
+		rfs.Forget(node, 1)
+		return int(n), nil
+	}, nil
+}
+
+// ReadDir lists a directory of Entries files, in buffers of Size bytes.
+func ReadDir(rfs fuse.RawFileSystem, dir string, opts *Options) (Op, error) {
+	return readDir(rfs, dir, opts, false)
+}
+
+// ReadDirPlus is ReadDir with READDIRPLUS. The lookups it adds are not
+// forgotten; the kernel batches its FORGETs and their cost would swamp
+// the listing.
+func ReadDirPlus(rfs fuse.RawFileSystem, dir string, opts *Options) (Op, error) {
+	return readDir(rfs, dir, opts, true)
+}
+
+func readDir(rfs fuse.RawFileSystem, dir string, opts *Options, plus bool) (Op, error) {
+	o := opts.withDefaults()
+	parent, err := mkdir(rfs, fuse.FUSE_ROOT_ID, dir)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < o.Entries; i++ {
+		var out fuse.EntryOut
+		name := fmt.Sprintf("entry%d", i)
+		if st := rfs.Mknod(nil, &fuse.MknodIn{InHeader: header(parent), Mode: syscall.S_IFREG | 0644}, name, &out); !st.Ok() {
+			return nil, fail("mknod "+name, st)
+		}
+	}
+	read, name := rfs.ReadDir, "readdir"
+	if plus {
+		read, name = rfs.ReadDirPlus, "readdirplus"
+	}
+	return func(int) (int, error) {
+		var open fuse.OpenOut
+		if st := rfs.OpenDir(nil, &fuse.OpenIn{InHeader: header(parent)}, &open); !st.Ok() {
+			return 0, fail("opendir", st)
+		}
+		defer rfs.ReleaseDir(&fuse.ReleaseIn{InHeader: header(parent), Fh: open.Fh})
+
+		buf := make([]byte, o.Size)
+		total, off := 0, uint64(0)
+		for {
+			for i := range buf {
+				buf[i] = 0
+			}
+			in := &fuse.ReadIn{InHeader: header(parent), Fh: open.Fh, Offset: off, Size: uint32(len(buf))}
+			if st := read(nil, in, fuse.NewDirEntryList(buf, off)); !st.Ok() {
+				return 0, fail(name, st)
+			}
+			entries := grpcfusetest.ParseDirents(buf, plus)
+			if len(entries) == 0 {
+				return total, nil
+			}
+			off = entries[len(entries)-1].Off
+			total += len(entries)
+		}
+	}, nil
+}
+
+// Result is the outcome of Run.
+type Result struct {
+	Workload string
+	Ops      int64
+	// N is the sum of what the ops returned.
+	N       int64
+	Elapsed time.Duration
+}
+
+func (r Result) String() string {
+	secs := r.Elapsed.Seconds()
+	rate := fmt.Sprintf("%.2f MiB/s", float64(r.N)/secs/(1<<20))
+	if r.Workload == "readdir" || r.Workload == "readdirplus" {
+		rate = fmt.Sprintf("%.0f entries/s", float64(r.N)/secs)
+	}
+	return fmt.Sprintf("%s: %d ops in %v, %.0f ops/s, %s",
+		r.Workload, r.Ops, r.Elapsed.Round(time.Millisecond), float64(r.Ops)/secs, rate)
+}
+
+// Run runs the workload name on rfs with concurrency workers until
+// duration has passed, and reports the rate. dir must not exist yet.
+func Run(rfs fuse.RawFileSystem, name, dir string, concurrency int, duration time.Duration, opts *Options) (Result, error) {
+	workload, ok := Workloads[name]
+	if !ok {
+		return Result{}, fmt.Errorf("unknown workload %q", name)
+	}
+	op, err := workload(rfs, dir, opts)
+	if err != nil {
+		return Result{}, err
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var (
+		next, n  int64
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	start := time.Now()
+	deadline := start.Add(duration)
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for time.Now().Before(deadline) {
+				k, err := op(int(atomic.AddInt64(&next, 1) - 1))
+				if err != nil {
+					errOnce.Do(func() { firstErr = err })
+					return
+				}
+				atomic.AddInt64(&n, int64(k))
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return Result{}, firstErr
+	}
+	return Result{Workload: name, Ops: next, N: n, Elapsed: time.Since(start)}, nil
+}
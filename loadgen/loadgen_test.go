@@ -0,0 +1,131 @@
+package loadgen
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chiyutianyi/grpcfuse/grpcfusetest"
+	"github.com/chiyutianyi/grpcfuse/memfs"
+)
+
+// transports are the listeners the pair is benchmarked over: "shm" is a
+// Unix socket with the data of reads and writes in shared memory.
+var transports = []string{"bufconn", "tcp", "unix", "shm"}
+
+// thresholds are the msgSizeThreshold values benchmarked; 1MiB is the
+// fuse2grpc default.
+var thresholds = []int{16 << 10, 256 << 10, 1 << 20}
+
+func newClient(tb testing.TB, transport string, threshold int) fuse.RawFileSystem {
+	opts := &grpcfusetest.Options{MsgSizeThreshold: threshold, SharedMemory: transport == "shm"}
+	var err error
+	switch transport {
+	case "tcp":
+		opts.Listener, err = net.Listen("tcp", "127.0.0.1:0")
+	case "unix", "shm":
+		opts.Listener, err = net.Listen("unix", filepath.Join(tb.TempDir(), "sock"))
+	}
+	require.NoError(tb, err)
+	return grpcfusetest.New(tb, memfs.New(nil, nil), opts).Client
+}
+
+func TestTransports(t *testing.T) {
+	for _, transport := range transports {
+		t.Run(transport, func(t *testing.T) {
+			rfs := newClient(t, transport, 4096)
+			for _, name := range []string{"read", "write"} {
+				res, err := Run(rfs, name, name, 4, 20*time.Millisecond, &Options{Size: 128 << 10, FileSize: 1 << 20})
+				require.NoError(t, err)
+				assert.Greater(t, res.Ops, int64(0))
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	rfs := newClient(t, "bufconn", 4096)
+	opts := &Options{Size: 4096, FileSize: 1 << 20, Entries: 100}
+	for _, name := range Names() {
+		t.Run(name, func(t *testing.T) {
+			res, err := Run(rfs, name, name, 4, 20*time.Millisecond, opts)
+			require.NoError(t, err)
+			assert.Greater(t, res.Ops, int64(0))
+			assert.Greater(t, res.N, int64(0))
+			t.Log(res)
+		})
+	}
+
+	_, err := Run(rfs, "nope", "nope", 1, time.Millisecond, opts)
+	assert.Error(t, err)
+	// The directory of a workload must not exist.
+	_, err = Run(rfs, "read", "read", 1, time.Millisecond, opts)
+	assert.Error(t, err)
+}
+
+func TestReadDirEntries(t *testing.T) {
+	rfs := newClient(t, "bufconn", 1024)
+	for _, name := range []string{"readdir", "readdirplus"} {
+		op, err := Workloads[name](rfs, name, &Options{Size: 4096, Entries: 500})
+		require.NoError(t, err)
+		n, err := op(0)
+		require.NoError(t, err)
+		// With "." and "..".
+		assert.Equal(t, 502, n, name)
+	}
+}
+
+func benchmark(b *testing.B, workload string, opts *Options, bytes bool) {
+	for _, transport := range transports {
+		for _, threshold := range thresholds {
+			b.Run(fmt.Sprintf("%s/threshold=%d", transport, threshold), func(b *testing.B) {
+				rfs := newClient(b, transport, threshold)
+				op, err := Workloads[workload](rfs, workload, opts)
+				require.NoError(b, err)
+				if bytes {
+					b.SetBytes(int64(opts.withDefaults().Size))
+				}
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if _, err := op(i); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkRead(b *testing.B) {
+	for _, size := range []int{4 << 10, 128 << 10, 1 << 20} {
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			benchmark(b, "read", &Options{Size: size, FileSize: 16 << 20}, true)
+		})
+	}
+}
+
+func BenchmarkWrite(b *testing.B) {
+	for _, size := range []int{4 << 10, 128 << 10, 1 << 20} {
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			benchmark(b, "write", &Options{Size: size, FileSize: 16 << 20}, true)
+		})
+	}
+}
+
+func BenchmarkSmallFile(b *testing.B) {
+	benchmark(b, "smallfile", &Options{Size: 1024}, false)
+}
+
+func BenchmarkReadDir(b *testing.B) {
+	benchmark(b, "readdir", &Options{Entries: 10000}, false)
+}
+
+func BenchmarkReadDirPlus(b *testing.B) {
+	benchmark(b, "readdirplus", &Options{Entries: 10000}, false)
+}
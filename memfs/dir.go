@@ -0,0 +1,354 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memfs
+
+import (
+	"context"
+	"sort"
+	"syscall"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+)
+
+const (
+	renameNoreplace = 1 // RENAME_NOREPLACE
+	renameExchange  = 2 // RENAME_EXCHANGE
+)
+
+var (
+	_ = (fs.NodeLookuper)((*node)(nil))
+	_ = (fs.NodeReaddirer)((*node)(nil))
+	_ = (fs.NodeMkdirer)((*node)(nil))
+	_ = (fs.NodeMknoder)((*node)(nil))
+	_ = (fs.NodeCreater)((*node)(nil))
+	_ = (fs.NodeSymlinker)((*node)(nil))
+	_ = (fs.NodeLinker)((*node)(nil))
+	_ = (fs.NodeUnlinker)((*node)(nil))
+	_ = (fs.NodeRmdirer)((*node)(nil))
+	_ = (fs.NodeRenamer)((*node)(nil))
+)
+
+// child returns the node called name in n, or nil.
+func (n *node) child(name string) *node {
+	ch := n.GetChild(name)
+	if ch == nil {
+		return nil
+	}
+	return ch.Operations().(*node)
+}
+
+// changed updates the times of directory n after an entry was added or
+// removed, and adjusts its link count by the number of subdirectories
+// added.
+func (n *node) changed(subdirs int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.attr.Nlink = uint32(int(n.attr.Nlink) + subdirs)
+	n.touch("mc")
+}
+
+// newChild makes a node to be added to n as name. The caller holds
+// fsys.treeMu.
+func (n *node) newChild(ctx context.Context, name string, mode uint32, out *fuse.EntryOut) (*node, *fs.Inode, syscall.Errno) {
+	if !n.isDir() {
+		return nil, nil, syscall.ENOTDIR
+	}
+	if len(name) > maxNameLen {
+		return nil, nil, syscall.ENAMETOOLONG
+	}
+	if n.GetChild(name) != nil {
+		return nil, nil, syscall.EEXIST
+	}
+	ino, errno := n.fsys.allocIno()
+	if errno != 0 {
+		return nil, nil, errno
+	}
+
+	uid, gid := callerOwner(ctx)
+	ch := n.fsys.newNode(mode, uid, gid)
+	if mode&syscall.S_IFMT == syscall.S_IFDIR {
+		ch.attr.Nlink = 2
+		n.changed(1)
+	} else {
+		n.changed(0)
+	}
+	inode := n.NewPersistentInode(ctx, ch, fs.StableAttr{Mode: mode & syscall.S_IFMT, Ino: ino})
+	ch.fillAttr(&out.Attr)
+	return ch, inode, 0
+}
+
+func (n *node) Lookup(ctx context.Context, name string, out *fuse.EntryOut) (*fs.Inode, syscall.Errno) {
+	if len(name) > maxNameLen {
+		return nil, syscall.ENAMETOOLONG
+	}
+	ch := n.child(name)
+	if ch == nil {
+		return nil, syscall.ENOENT
+	}
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	ch.fillAttr(&out.Attr)
+	return ch.EmbeddedInode(), 0
+}
+
+// Readdir lists "." and ".." followed by the entries sorted by name, so
+// listings are stable across calls.
+func (n *node) Readdir(ctx context.Context) (fs.DirStream, syscall.Errno) {
+	parent := n.EmbeddedInode()
+	if _, p := n.Parent(); p != nil {
+		parent = p
+	}
+	entries := []fuse.DirEntry{
+		{Name: ".", Mode: syscall.S_IFDIR, Ino: n.StableAttr().Ino},
+		{Name: "..", Mode: syscall.S_IFDIR, Ino: parent.StableAttr().Ino},
+	}
+
+	children := n.Children()
+	names := make([]string, 0, len(children))
+	for name := range children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		id := children[name].StableAttr()
+		entries = append(entries, fuse.DirEntry{Name: name, Mode: id.Mode, Ino: id.Ino})
+	}
+	return fs.NewListDirStream(entries), 0
+}
+
+func (n *node) Mkdir(ctx context.Context, name string, mode uint32, out *fuse.EntryOut) (*fs.Inode, syscall.Errno) {
+	n.fsys.treeMu.Lock()
+	defer n.fsys.treeMu.Unlock()
+	_, inode, errno := n.newChild(ctx, name, syscall.S_IFDIR|mode&07777, out)
+	return inode, errno
+}
+
+func (n *node) Mknod(ctx context.Context, name string, mode uint32, dev uint32, out *fuse.EntryOut) (*fs.Inode, syscall.Errno) {
+	switch mode & syscall.S_IFMT {
+	case 0:
+		mode |= syscall.S_IFREG
+	case syscall.S_IFREG, syscall.S_IFCHR, syscall.S_IFBLK, syscall.S_IFIFO, syscall.S_IFSOCK:
+	default:
+		return nil, syscall.EINVAL
+	}
+
+	n.fsys.treeMu.Lock()
+	defer n.fsys.treeMu.Unlock()
+	ch, inode, errno := n.newChild(ctx, name, mode, out)
+	if errno != 0 {
+		return nil, errno
+	}
+	ch.attr.Rdev = dev
+	out.Attr.Rdev = dev
+	return inode, 0
+}
+
+func (n *node) Create(ctx context.Context, name string, flags uint32, mode uint32, out *fuse.EntryOut) (*fs.Inode, fs.FileHandle, uint32, syscall.Errno) {
+	n.fsys.treeMu.Lock()
+	defer n.fsys.treeMu.Unlock()
+	ch, inode, errno := n.newChild(ctx, name, syscall.S_IFREG|mode&07777, out)
+	if errno != 0 {
+		return nil, nil, 0, errno
+	}
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	ch.opens++
+	return inode, newHandle(), 0, 0
+}
+
+func (n *node) Symlink(ctx context.Context, target, name string, out *fuse.EntryOut) (*fs.Inode, syscall.Errno) {
+	n.fsys.treeMu.Lock()
+	defer n.fsys.treeMu.Unlock()
+	ch, inode, errno := n.newChild(ctx, name, syscall.S_IFLNK|0777, out)
+	if errno != 0 {
+		return nil, errno
+	}
+	ch.target = []byte(target)
+	ch.attr.Size = uint64(len(target))
+	out.Attr.Size = ch.attr.Size
+	return inode, 0
+}
+
+func (n *node) Link(ctx context.Context, target fs.InodeEmbedder, name string, out *fuse.EntryOut) (*fs.Inode, syscall.Errno) {
+	tn, ok := target.(*node)
+	if !ok {
+		return nil, syscall.EXDEV
+	}
+	if len(name) > maxNameLen {
+		return nil, syscall.ENAMETOOLONG
+	}
+
+	n.fsys.treeMu.Lock()
+	defer n.fsys.treeMu.Unlock()
+	if n.GetChild(name) != nil {
+		return nil, syscall.EEXIST
+	}
+
+	tn.mu.Lock()
+	defer tn.mu.Unlock()
+	switch {
+	case tn.isDir():
+		return nil, syscall.EPERM
+	case tn.attr.Nlink == 0:
+		return nil, syscall.ENOENT
+	}
+	tn.attr.Nlink++
+	tn.touch("c")
+	tn.fillAttr(&out.Attr)
+	n.changed(0)
+	return tn.EmbeddedInode(), 0
+}
+
+func (n *node) Unlink(ctx context.Context, name string) syscall.Errno {
+	n.fsys.treeMu.Lock()
+	defer n.fsys.treeMu.Unlock()
+	ch := n.child(name)
+	if ch == nil {
+		return syscall.ENOENT
+	}
+	if ch.isDir() {
+		return syscall.EISDIR
+	}
+	ch.unref()
+	n.changed(0)
+	return 0
+}
+
+func (n *node) Rmdir(ctx context.Context, name string) syscall.Errno {
+	n.fsys.treeMu.Lock()
+	defer n.fsys.treeMu.Unlock()
+	ch := n.child(name)
+	if ch == nil {
+		return syscall.ENOENT
+	}
+	if errno := ch.removable(); errno != 0 {
+		return errno
+	}
+	ch.drop()
+	n.changed(-1)
+	return 0
+}
+
+// removable reports whether directory n can be removed or replaced.
+func (n *node) removable() syscall.Errno {
+	if !n.isDir() {
+		return syscall.ENOTDIR
+	}
+	if len(n.Children()) > 0 {
+		return syscall.ENOTEMPTY
+	}
+	return 0
+}
+
+// drop removes all links to directory n.
+func (n *node) drop() {
+	n.mu.Lock()
+	n.attr.Nlink = 0
+	n.mu.Unlock()
+	n.maybeFree()
+}
+
+// contains reports whether dir is n or below n.
+func (n *node) contains(dir *fs.Inode) bool {
+	for p := dir; p != nil; _, p = p.Parent() {
+		if p == n.EmbeddedInode() {
+			return true
+		}
+	}
+	return false
+}
+
+// Rename checks the move and keeps link counts; the bridge moves the
+// entries in the tree once it succeeds.
+func (n *node) Rename(ctx context.Context, name string, newParent fs.InodeEmbedder, newName string, flags uint32) syscall.Errno {
+	np, ok := newParent.(*node)
+	if !ok {
+		return syscall.EXDEV
+	}
+	if flags&^(renameNoreplace|renameExchange) != 0 || flags == renameNoreplace|renameExchange {
+		return syscall.EINVAL
+	}
+	if len(newName) > maxNameLen {
+		return syscall.ENAMETOOLONG
+	}
+
+	n.fsys.treeMu.Lock()
+	defer n.fsys.treeMu.Unlock()
+	src := n.child(name)
+	if src == nil {
+		return syscall.ENOENT
+	}
+	if src.isDir() && src.contains(np.EmbeddedInode()) {
+		return syscall.EINVAL
+	}
+	dst := np.child(newName)
+
+	if flags&renameExchange != 0 {
+		if dst == nil {
+			return syscall.ENOENT
+		}
+		if dst.isDir() && dst.contains(n.EmbeddedInode()) {
+			return syscall.EINVAL
+		}
+		if n != np && src.isDir() != dst.isDir() {
+			moved := 1
+			if dst.isDir() {
+				moved = -1
+			}
+			n.changed(-moved)
+			np.changed(moved)
+		}
+		return 0
+	}
+
+	if dst != nil {
+		if flags&renameNoreplace != 0 {
+			return syscall.EEXIST
+		}
+		if dst == src {
+			return 0
+		}
+		switch {
+		case src.isDir() && !dst.isDir():
+			return syscall.ENOTDIR
+		case !src.isDir() && dst.isDir():
+			return syscall.EISDIR
+		}
+		if dst.isDir() {
+			if errno := dst.removable(); errno != 0 {
+				return errno
+			}
+			dst.drop()
+			np.changed(-1)
+		} else {
+			dst.unref()
+		}
+	}
+
+	if src.isDir() {
+		n.changed(-1)
+		np.changed(1)
+	} else {
+		n.changed(0)
+		np.changed(0)
+	}
+	src.mu.Lock()
+	src.touch("c")
+	src.mu.Unlock()
+	return 0
+}
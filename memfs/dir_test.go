@@ -0,0 +1,157 @@
+package memfs
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chiyutianyi/grpcfuse/grpcfusetest"
+)
+
+func (f *testFS) lookup(parent uint64, name string) (fuse.EntryOut, fuse.Status) {
+	var out fuse.EntryOut
+	st := f.rfs.Lookup(nil, &fuse.InHeader{NodeId: parent}, name, &out)
+	return out, st
+}
+
+func (f *testFS) rename(parent uint64, name string, newParent uint64, newName string, flags uint32) fuse.Status {
+	return f.rfs.Rename(nil, &fuse.RenameIn{InHeader: header(parent), Newdir: newParent, Flags: flags}, name, newName)
+}
+
+func TestRename(t *testing.T) {
+	// Every case starts from
+	//
+	//	/a/file  /a/sub/  /b/other  /c/
+	tests := []struct {
+		name      string
+		from, to  string
+		flags     uint32
+		want      fuse.Status
+		gone      bool
+		nlinkA    uint32
+		nlinkRoot uint32
+	}{
+		{name: "file to other dir", from: "a/file", to: "b/file", want: fuse.OK, gone: true, nlinkA: 3},
+		{name: "replace file", from: "a/file", to: "b/other", want: fuse.OK, gone: true, nlinkA: 3},
+		{name: "noreplace", from: "a/file", to: "b/other", flags: renameNoreplace, want: fuse.Status(syscall.EEXIST), nlinkA: 3},
+		{name: "exchange", from: "a/file", to: "b/other", flags: renameExchange, want: fuse.OK, nlinkA: 3},
+		{name: "exchange missing", from: "a/file", to: "b/none", flags: renameExchange, want: fuse.ENOENT, nlinkA: 3},
+		{name: "dir to other dir", from: "a/sub", to: "b/sub", want: fuse.OK, gone: true, nlinkA: 2},
+		{name: "exchange dir with file", from: "a/sub", to: "b/other", flags: renameExchange, want: fuse.OK, nlinkA: 2},
+		{name: "replace non-empty dir", from: "a", to: "b", want: fuse.Status(syscall.ENOTEMPTY), nlinkA: 3},
+		{name: "dir over file", from: "a/sub", to: "b/other", want: fuse.Status(syscall.ENOTDIR), nlinkA: 3},
+		{name: "file over dir", from: "b/other", to: "a/sub", want: fuse.Status(syscall.EISDIR), nlinkA: 3},
+		{name: "into itself", from: "a", to: "a/sub/a", want: fuse.EINVAL, nlinkA: 3},
+		{name: "dir over empty dir", from: "c", to: "a/sub", want: fuse.OK, gone: true, nlinkA: 3, nlinkRoot: 4},
+		{name: "missing", from: "a/none", to: "b/none", want: fuse.ENOENT, gone: true, nlinkA: 3},
+		{name: "bad flags", from: "a/file", to: "b/file", flags: 4, want: fuse.EINVAL, nlinkA: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFS(t, nil)
+			a := f.mkdir(fuse.FUSE_ROOT_ID, "a")
+			_, _ = f.create(a, "file")
+			sub := f.mkdir(a, "sub")
+			b := f.mkdir(fuse.FUSE_ROOT_ID, "b")
+			_, _ = f.create(b, "other")
+			c := f.mkdir(fuse.FUSE_ROOT_ID, "c")
+			dirs := map[string]uint64{"": fuse.FUSE_ROOT_ID, "a": a, "b": b, "c": c, "a/sub": sub}
+			split := func(p string) (uint64, string) {
+				for i := len(p) - 1; i >= 0; i-- {
+					if p[i] == '/' {
+						return dirs[p[:i]], p[i+1:]
+					}
+				}
+				return fuse.FUSE_ROOT_ID, p
+			}
+			p1, n1 := split(tt.from)
+			p2, n2 := split(tt.to)
+			assert.Equal(t, tt.want, f.rename(p1, n1, p2, n2, tt.flags))
+
+			_, st := f.lookup(p1, n1)
+			assert.Equal(t, tt.gone, st == fuse.ENOENT, "old name")
+			assert.Equal(t, tt.nlinkA, f.getattr(a).Nlink, "nlink of a")
+			if tt.nlinkRoot > 0 {
+				assert.Equal(t, tt.nlinkRoot, f.getattr(fuse.FUSE_ROOT_ID).Nlink, "nlink of /")
+			}
+		})
+	}
+}
+
+func TestLink(t *testing.T) {
+	f := newTestFS(t, nil)
+	node, _ := f.create(fuse.FUSE_ROOT_ID, "file")
+	dir := f.mkdir(fuse.FUSE_ROOT_ID, "dir")
+	assert.Equal(t, uint32(3), f.getattr(fuse.FUSE_ROOT_ID).Nlink)
+
+	var out fuse.EntryOut
+	require.Equal(t, fuse.OK, f.rfs.Link(nil, &fuse.LinkIn{InHeader: header(dir), Oldnodeid: node}, "link", &out))
+	assert.Equal(t, node, out.NodeId)
+	assert.Equal(t, uint32(2), out.Nlink)
+	assert.Equal(t, fuse.Status(syscall.EEXIST), f.rfs.Link(nil, &fuse.LinkIn{InHeader: header(dir), Oldnodeid: node}, "link", &out))
+	assert.Equal(t, fuse.EPERM, f.rfs.Link(nil, &fuse.LinkIn{InHeader: header(fuse.FUSE_ROOT_ID), Oldnodeid: dir}, "dirlink", &out))
+
+	require.Equal(t, fuse.OK, f.rfs.Unlink(nil, &fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}, "file"))
+	assert.Equal(t, uint32(1), f.getattr(node).Nlink)
+	assert.Equal(t, fuse.Status(syscall.ENOTEMPTY), f.rfs.Rmdir(nil, &fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}, "dir"))
+	assert.Equal(t, fuse.Status(syscall.EISDIR), f.rfs.Unlink(nil, &fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}, "dir"))
+	assert.Equal(t, fuse.ENOTDIR, f.rfs.Rmdir(nil, &fuse.InHeader{NodeId: dir}, "link"))
+
+	require.Equal(t, fuse.OK, f.rfs.Unlink(nil, &fuse.InHeader{NodeId: dir}, "link"))
+	require.Equal(t, fuse.OK, f.rfs.Rmdir(nil, &fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}, "dir"))
+	assert.Equal(t, uint32(2), f.getattr(fuse.FUSE_ROOT_ID).Nlink)
+}
+
+func TestSymlinkAndMknod(t *testing.T) {
+	f := newTestFS(t, nil)
+
+	var out fuse.EntryOut
+	require.Equal(t, fuse.OK, f.rfs.Symlink(nil, &fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}, "target", "link", &out))
+	assert.Equal(t, uint32(syscall.S_IFLNK|0777), out.Mode)
+	assert.Equal(t, uint64(len("target")), out.Size)
+	target, st := f.rfs.Readlink(nil, &fuse.InHeader{NodeId: out.NodeId})
+	assert.Equal(t, fuse.OK, st)
+	assert.Equal(t, "target", string(target))
+	_, st = f.rfs.Readlink(nil, &fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID})
+	assert.Equal(t, fuse.EINVAL, st)
+
+	require.Equal(t, fuse.OK, f.rfs.Mknod(nil, &fuse.MknodIn{InHeader: header(fuse.FUSE_ROOT_ID), Mode: syscall.S_IFCHR | 0600, Rdev: 0x0105}, "tty", &out))
+	assert.Equal(t, uint32(syscall.S_IFCHR|0600), out.Mode)
+	assert.Equal(t, uint32(0x0105), f.getattr(out.NodeId).Rdev)
+	assert.Equal(t, fuse.EINVAL, f.rfs.Mknod(nil, &fuse.MknodIn{InHeader: header(fuse.FUSE_ROOT_ID), Mode: syscall.S_IFDIR | 0755}, "dir", &out))
+	assert.Equal(t, fuse.Status(syscall.EEXIST), f.rfs.Mknod(nil, &fuse.MknodIn{InHeader: header(fuse.FUSE_ROOT_ID), Mode: syscall.S_IFIFO | 0600}, "tty", &out))
+
+	long := make([]byte, maxNameLen+1)
+	for i := range long {
+		long[i] = 'x'
+	}
+	_, st = f.lookup(fuse.FUSE_ROOT_ID, string(long))
+	assert.Equal(t, fuse.Status(syscall.ENAMETOOLONG), st)
+}
+
+func TestReaddir(t *testing.T) {
+	f := newTestFS(t, nil)
+	for _, name := range []string{"c", "a", "b"} {
+		f.create(fuse.FUSE_ROOT_ID, name)
+	}
+	f.mkdir(fuse.FUSE_ROOT_ID, "d")
+
+	var open fuse.OpenOut
+	require.Equal(t, fuse.OK, f.rfs.OpenDir(nil, &fuse.OpenIn{InHeader: header(fuse.FUSE_ROOT_ID)}, &open))
+	buf := make([]byte, 4096)
+	out := fuse.NewDirEntryList(buf, 0)
+	require.Equal(t, fuse.OK, f.rfs.ReadDir(nil, &fuse.ReadIn{InHeader: header(fuse.FUSE_ROOT_ID), Fh: open.Fh, Size: uint32(len(buf))}, out))
+
+	var names []string
+	var types []uint32
+	for _, e := range grpcfusetest.ParseDirents(buf, false) {
+		names = append(names, e.Name)
+		types = append(types, e.Type)
+	}
+	assert.Equal(t, []string{".", "..", "a", "b", "c", "d"}, names)
+	assert.Equal(t, uint32(syscall.DT_DIR), types[5])
+	assert.Equal(t, uint32(syscall.DT_REG), types[2])
+}
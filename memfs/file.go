@@ -0,0 +1,374 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memfs
+
+import (
+	"context"
+	"sync"
+	"syscall"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	fallocKeepSize  = 0x1 // FALLOC_FL_KEEP_SIZE
+	fallocPunchHole = 0x2 // FALLOC_FL_PUNCH_HOLE
+
+	// maxCopy bounds a single CopyFileRange, whose result is 32 bits.
+	maxCopy = 1 << 30
+	// maxFileSize is the largest offset the kernel takes, that of off_t.
+	maxFileSize = 1<<63 - 1
+)
+
+var (
+	_ = (fs.NodeOpener)((*node)(nil))
+	_ = (fs.NodeReader)((*node)(nil))
+	_ = (fs.NodeWriter)((*node)(nil))
+	_ = (fs.NodeFlusher)((*node)(nil))
+	_ = (fs.NodeFsyncer)((*node)(nil))
+	_ = (fs.NodeReleaser)((*node)(nil))
+	_ = (fs.NodeAllocater)((*node)(nil))
+	_ = (fs.NodeLseeker)((*node)(nil))
+	_ = (fs.NodeCopyFileRanger)((*node)(nil))
+)
+
+// handle is an open file. It remembers the lock owners that used it, so
+// their locks go away when it is released.
+type handle struct {
+	mu     sync.Mutex
+	owners map[uint64]struct{}
+}
+
+func newHandle() *handle {
+	return &handle{owners: map[uint64]struct{}{}}
+}
+
+func (h *handle) addOwner(owner uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.owners[owner] = struct{}{}
+}
+
+func (h *handle) lockOwners() []uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	owners := make([]uint64, 0, len(h.owners))
+	for owner := range h.owners {
+		owners = append(owners, owner)
+	}
+	return owners
+}
+
+func (n *node) Open(ctx context.Context, flags uint32) (fs.FileHandle, uint32, syscall.Errno) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if flags&syscall.O_TRUNC != 0 && flags&syscall.O_ACCMODE != syscall.O_RDONLY &&
+		n.attr.Mode&syscall.S_IFMT == syscall.S_IFREG {
+		n.truncate(0)
+		n.touch("mc")
+	}
+	n.opens++
+	return newHandle(), 0, 0
+}
+
+func (n *node) Release(ctx context.Context, f fs.FileHandle) syscall.Errno {
+	if h, ok := f.(*handle); ok {
+		for _, owner := range h.lockOwners() {
+			n.unlockOwner(owner)
+		}
+	}
+
+	n.mu.Lock()
+	n.opens--
+	n.mu.Unlock()
+	n.maybeFree()
+	return 0
+}
+
+func (n *node) Flush(ctx context.Context, f fs.FileHandle) syscall.Errno {
+	return 0
+}
+
+func (n *node) Fsync(ctx context.Context, f fs.FileHandle, flags uint32) syscall.Errno {
+	return 0
+}
+
+func (n *node) Read(ctx context.Context, f fs.FileHandle, dest []byte, off int64) (fuse.ReadResult, syscall.Errno) {
+	if off < 0 {
+		return nil, syscall.EINVAL
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.isDir() {
+		return nil, syscall.EISDIR
+	}
+	return fuse.ReadResultData(dest[:n.readAt(dest, uint64(off))]), 0
+}
+
+func (n *node) Write(ctx context.Context, f fs.FileHandle, data []byte, off int64) (uint32, syscall.Errno) {
+	if off < 0 {
+		return 0, syscall.EINVAL
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.isDir() {
+		return 0, syscall.EISDIR
+	}
+	if errno := n.writeAt(data, uint64(off)); errno != 0 {
+		return 0, errno
+	}
+	n.touch("mc")
+	return uint32(len(data)), 0
+}
+
+func (n *node) Allocate(ctx context.Context, f fs.FileHandle, off uint64, size uint64, mode uint32) syscall.Errno {
+	if size == 0 {
+		return syscall.EINVAL
+	}
+	if off > maxFileSize || size > maxFileSize-off {
+		return syscall.EFBIG
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.attr.Mode&syscall.S_IFMT != syscall.S_IFREG {
+		return syscall.ENODEV
+	}
+
+	switch mode {
+	case 0, fallocKeepSize:
+		if errno := n.allocate(off, off+size); errno != 0 {
+			return errno
+		}
+		if mode == 0 && off+size > n.attr.Size {
+			n.attr.Size = off + size
+		}
+	case fallocPunchHole | fallocKeepSize:
+		n.punch(off, off+size)
+	default:
+		return syscall.EOPNOTSUPP
+	}
+	n.touch("mc")
+	return 0
+}
+
+// Lseek finds data and holes. The end of the file counts as a hole.
+func (n *node) Lseek(ctx context.Context, f fs.FileHandle, off uint64, whence uint32) (uint64, syscall.Errno) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if whence != unix.SEEK_DATA && whence != unix.SEEK_HOLE {
+		return 0, syscall.EINVAL
+	}
+	if off >= n.attr.Size {
+		return 0, syscall.ENXIO
+	}
+
+	bs := uint64(n.fsys.opts.BlockSize)
+	last := (n.attr.Size + bs - 1) / bs
+	i := off / bs
+	if whence == unix.SEEK_DATA {
+		// Files can be mostly hole, so look through the blocks rather
+		// than the offsets.
+		next := last
+		for j := range n.data {
+			if j >= i && j < next {
+				next = j
+			}
+		}
+		i = next
+	} else {
+		for ; i < last; i++ {
+			if _, ok := n.data[i]; !ok {
+				break
+			}
+		}
+	}
+	pos := i * bs
+	if pos < off {
+		pos = off
+	}
+	if pos >= n.attr.Size {
+		if whence == unix.SEEK_DATA {
+			return 0, syscall.ENXIO
+		}
+		pos = n.attr.Size
+	}
+	return pos, 0
+}
+
+func (n *node) CopyFileRange(ctx context.Context, fhIn fs.FileHandle, offIn uint64, out *fs.Inode, fhOut fs.FileHandle, offOut uint64, size uint64, flags uint64) (uint32, syscall.Errno) {
+	if flags != 0 {
+		return 0, syscall.EINVAL
+	}
+	dst, ok := out.Operations().(*node)
+	if !ok {
+		return 0, syscall.EXDEV
+	}
+	if size > maxCopy {
+		size = maxCopy
+	}
+	if dst == n && offIn < offOut+size && offOut < offIn+size {
+		return 0, syscall.EINVAL
+	}
+
+	n.mu.Lock()
+	if n.isDir() {
+		n.mu.Unlock()
+		return 0, syscall.EISDIR
+	}
+	if offIn >= n.attr.Size {
+		n.mu.Unlock()
+		return 0, 0
+	}
+	if left := n.attr.Size - offIn; size > left {
+		size = left
+	}
+	buf := make([]byte, size)
+	n.readAt(buf, offIn)
+	n.mu.Unlock()
+
+	dst.mu.Lock()
+	defer dst.mu.Unlock()
+	if dst.isDir() {
+		return 0, syscall.EISDIR
+	}
+	if errno := dst.writeAt(buf, offOut); errno != 0 {
+		return 0, errno
+	}
+	dst.touch("mc")
+	return uint32(len(buf)), 0
+}
+
+// readAt copies the file contents at off into dest and returns the number
+// of bytes read. Must hold n.mu.
+func (n *node) readAt(dest []byte, off uint64) int {
+	if off >= n.attr.Size {
+		return 0
+	}
+	if left := n.attr.Size - off; uint64(len(dest)) > left {
+		dest = dest[:left]
+	}
+
+	bs := uint64(n.fsys.opts.BlockSize)
+	for done := 0; done < len(dest); {
+		pos := off + uint64(done)
+		i, boff := pos/bs, pos%bs
+		chunk := dest[done:]
+		if uint64(len(chunk)) > bs-boff {
+			chunk = chunk[:bs-boff]
+		}
+		if b, ok := n.data[i]; ok {
+			copy(chunk, b[boff:])
+		} else {
+			for j := range chunk {
+				chunk[j] = 0
+			}
+		}
+		done += len(chunk)
+	}
+	return len(dest)
+}
+
+// writeAt stores data at off, allocating the blocks it needs. Must hold
+// n.mu.
+func (n *node) writeAt(data []byte, off uint64) syscall.Errno {
+	if len(data) == 0 {
+		return 0
+	}
+	end := off + uint64(len(data))
+	if errno := n.allocate(off, end); errno != 0 {
+		return errno
+	}
+
+	bs := uint64(n.fsys.opts.BlockSize)
+	for done := 0; done < len(data); {
+		pos := off + uint64(done)
+		i, boff := pos/bs, pos%bs
+		done += copy(n.data[i][boff:], data[done:])
+	}
+	if end > n.attr.Size {
+		n.attr.Size = end
+	}
+	return 0
+}
+
+// allocate makes sure the blocks covering [start, end) exist, with
+// start < end <= maxFileSize. Must hold n.mu.
+func (n *node) allocate(start, end uint64) syscall.Errno {
+	bs := uint64(n.fsys.opts.BlockSize)
+	first, last := start/bs, (end-1)/bs
+	// The blocks are reserved before any is made, so that a range past
+	// the capacity fails at once.
+	missing := last - first + 1
+	for i := range n.data {
+		if i >= first && i <= last {
+			missing--
+		}
+	}
+	if errno := n.fsys.allocBlocks(missing); errno != 0 {
+		return errno
+	}
+	if n.data == nil && missing > 0 {
+		n.data = map[uint64][]byte{}
+	}
+	for i := first; missing > 0; i++ {
+		if _, ok := n.data[i]; !ok {
+			n.data[i] = make([]byte, bs)
+			missing--
+		}
+	}
+	return 0
+}
+
+// punch turns [start, end) into a hole, freeing the blocks it covers
+// entirely. Must hold n.mu.
+func (n *node) punch(start, end uint64) {
+	bs := uint64(n.fsys.opts.BlockSize)
+	var freed uint64
+	for i, b := range n.data {
+		if (i+1)*bs <= start || i*bs >= end {
+			continue
+		}
+		from, to := uint64(0), bs
+		if i*bs < start {
+			from = start - i*bs
+		}
+		if (i+1)*bs > end {
+			to = end - i*bs
+		}
+		if from == 0 && to == bs {
+			delete(n.data, i)
+			freed++
+			continue
+		}
+		for j := from; j < to; j++ {
+			b[j] = 0
+		}
+	}
+	n.fsys.freeBlocks(freed)
+}
+
+// truncate sets the size of a regular file. Must hold n.mu.
+func (n *node) truncate(size uint64) {
+	if size < n.attr.Size {
+		// Everything past size goes, including the blocks past the end
+		// that Allocate kept.
+		n.punch(size, ^uint64(0)-uint64(n.fsys.opts.BlockSize))
+	}
+	n.attr.Size = size
+}
@@ -0,0 +1,139 @@
+package memfs
+
+import (
+	"bytes"
+	"syscall"
+	"testing"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func (f *testFS) lseek(nodeID, fh, off uint64, whence uint32) (uint64, fuse.Status) {
+	var out fuse.LseekOut
+	st := f.rfs.Lseek(nil, &fuse.LseekIn{InHeader: header(nodeID), Fh: fh, Offset: off, Whence: whence}, &out)
+	return out.Offset, st
+}
+
+func (f *testFS) fallocate(nodeID, fh, off, size uint64, mode uint32) fuse.Status {
+	return f.rfs.Fallocate(nil, &fuse.FallocateIn{InHeader: header(nodeID), Fh: fh, Offset: off, Length: size, Mode: mode})
+}
+
+func TestReadWrite(t *testing.T) {
+	f := newTestFS(t, &Options{BlockSize: 8})
+	node, fh := f.create(fuse.FUSE_ROOT_ID, "file")
+
+	require.Equal(t, fuse.OK, f.write(node, fh, 3, []byte("0123456789abcdef")))
+	assert.Equal(t, append([]byte{0, 0, 0}, "0123456789abcdef"...), f.read(node, fh, 0, 100))
+	assert.Equal(t, []byte("5678"), f.read(node, fh, 8, 4))
+	assert.Empty(t, f.read(node, fh, 19, 4))
+	assert.Equal(t, uint64(19), f.getattr(node).Size)
+	st := f.statfs()
+	assert.Equal(t, uint64(3), st.Blocks-st.Bfree)
+
+	// Shrinking zeroes the tail, so growing again reads zeroes.
+	size := uint64(5)
+	var out fuse.AttrOut
+	require.Equal(t, fuse.OK, f.rfs.SetAttr(nil, &fuse.SetAttrIn{SetAttrInCommon: fuse.SetAttrInCommon{InHeader: header(node), Valid: fuse.FATTR_SIZE, Size: size}}, &out))
+	assert.Equal(t, size, out.Size)
+	require.Equal(t, fuse.OK, f.rfs.SetAttr(nil, &fuse.SetAttrIn{SetAttrInCommon: fuse.SetAttrInCommon{InHeader: header(node), Valid: fuse.FATTR_SIZE, Size: 12}}, &out))
+	assert.Equal(t, []byte{0, 0, 0, '0', '1', 0, 0, 0, 0, 0, 0, 0}, f.read(node, fh, 0, 100))
+
+	var copyOut fuse.CreateOut
+	require.Equal(t, fuse.OK, f.rfs.Create(nil, &fuse.CreateIn{InHeader: header(fuse.FUSE_ROOT_ID), Flags: syscall.O_RDWR, Mode: 0644}, "copy", &copyOut))
+	n, status := f.rfs.CopyFileRange(nil, &fuse.CopyFileRangeIn{InHeader: header(node), FhIn: fh, OffIn: 3, NodeIdOut: copyOut.NodeId, FhOut: copyOut.Fh, OffOut: 1, Len: 100})
+	require.Equal(t, fuse.OK, status)
+	assert.Equal(t, uint32(9), n)
+	assert.Equal(t, []byte{0, '0', '1', 0, 0, 0, 0, 0, 0, 0}, f.read(copyOut.NodeId, copyOut.Fh, 0, 100))
+	_, status = f.rfs.CopyFileRange(nil, &fuse.CopyFileRangeIn{InHeader: header(node), FhIn: fh, NodeIdOut: node, FhOut: fh, OffOut: 1, Len: 4})
+	assert.Equal(t, fuse.EINVAL, status)
+}
+
+func TestOpenTrunc(t *testing.T) {
+	f := newTestFS(t, nil)
+	node, fh := f.create(fuse.FUSE_ROOT_ID, "file")
+	require.Equal(t, fuse.OK, f.write(node, fh, 0, []byte("data")))
+
+	var out fuse.OpenOut
+	require.Equal(t, fuse.OK, f.rfs.Open(nil, &fuse.OpenIn{InHeader: header(node), Flags: syscall.O_RDONLY | syscall.O_TRUNC}, &out))
+	assert.Equal(t, uint64(4), f.getattr(node).Size)
+	require.Equal(t, fuse.OK, f.rfs.Open(nil, &fuse.OpenIn{InHeader: header(node), Flags: syscall.O_WRONLY | syscall.O_TRUNC}, &out))
+	assert.Equal(t, uint64(0), f.getattr(node).Size)
+	assert.Equal(t, uint64(0), f.getattr(node).Blocks)
+}
+
+func TestSparse(t *testing.T) {
+	const bs = 1024
+	f := newTestFS(t, &Options{BlockSize: bs})
+	node, fh := f.create(fuse.FUSE_ROOT_ID, "file")
+
+	// data: [bs, 2*bs) and [4*bs, 4*bs+10); size 4*bs+10.
+	require.Equal(t, fuse.OK, f.write(node, fh, bs, bytes.Repeat([]byte{1}, bs)))
+	require.Equal(t, fuse.OK, f.write(node, fh, 4*bs, bytes.Repeat([]byte{2}, 10)))
+	assert.Equal(t, uint64(2*bs/512), f.getattr(node).Blocks)
+
+	tests := []struct {
+		name   string
+		off    uint64
+		whence uint32
+		want   uint64
+		st     fuse.Status
+	}{
+		{name: "data from hole", off: 10, whence: unix.SEEK_DATA, want: bs},
+		{name: "data in data", off: bs + 10, whence: unix.SEEK_DATA, want: bs + 10},
+		{name: "data in last block", off: 2 * bs, whence: unix.SEEK_DATA, want: 4 * bs},
+		{name: "hole in hole", off: 10, whence: unix.SEEK_HOLE, want: 10},
+		{name: "hole from data", off: bs + 10, whence: unix.SEEK_HOLE, want: 2 * bs},
+		{name: "hole at end", off: 4*bs + 1, whence: unix.SEEK_HOLE, want: 4*bs + 10},
+		{name: "past end", off: 4*bs + 10, whence: unix.SEEK_DATA, st: fuse.Status(syscall.ENXIO)},
+		{name: "bad whence", off: 0, whence: 0, st: fuse.EINVAL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			off, st := f.lseek(node, fh, tt.off, tt.whence)
+			assert.Equal(t, tt.st, st)
+			if tt.st == fuse.OK {
+				assert.Equal(t, tt.want, off)
+			}
+		})
+	}
+}
+
+func TestFallocate(t *testing.T) {
+	const bs = 1024
+	tests := []struct {
+		name   string
+		mode   uint32
+		off    uint64
+		size   uint64
+		st     fuse.Status
+		length uint64
+		blocks uint64
+		data   []byte // at bs-2
+	}{
+		{name: "extend", mode: 0, off: 2 * bs, size: 2 * bs, length: 4 * bs, blocks: 4, data: []byte{1, 1, 1, 1}},
+		{name: "keep size", mode: fallocKeepSize, off: 2 * bs, size: 2 * bs, length: 2 * bs, blocks: 4, data: []byte{1, 1, 1, 1}},
+		{name: "punch whole blocks", mode: fallocPunchHole | fallocKeepSize, off: 0, size: bs, length: 2 * bs, blocks: 1, data: []byte{0, 0, 1, 1}},
+		{name: "punch partial", mode: fallocPunchHole | fallocKeepSize, off: bs - 1, size: 2, length: 2 * bs, blocks: 2, data: []byte{1, 0, 0, 1}},
+		{name: "punch without keep size", mode: fallocPunchHole, off: 0, size: bs, st: fuse.Status(syscall.EOPNOTSUPP), length: 2 * bs, blocks: 2, data: []byte{1, 1, 1, 1}},
+		{name: "zero length", mode: 0, off: 0, size: 0, st: fuse.EINVAL, length: 2 * bs, blocks: 2, data: []byte{1, 1, 1, 1}},
+		{name: "past the largest file", mode: 0, off: maxFileSize - 100, size: 200, st: fuse.Status(syscall.EFBIG), length: 2 * bs, blocks: 2, data: []byte{1, 1, 1, 1}},
+		{name: "wrapping around", mode: 0, off: ^uint64(0) - 100, size: 50, st: fuse.Status(syscall.EFBIG), length: 2 * bs, blocks: 2, data: []byte{1, 1, 1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFS(t, &Options{BlockSize: bs})
+			node, fh := f.create(fuse.FUSE_ROOT_ID, "file")
+			require.Equal(t, fuse.OK, f.write(node, fh, 0, bytes.Repeat([]byte{1}, 2*bs)))
+
+			assert.Equal(t, tt.st, f.fallocate(node, fh, tt.off, tt.size, tt.mode))
+			attr := f.getattr(node)
+			assert.Equal(t, tt.length, attr.Size)
+			assert.Equal(t, tt.blocks*bs/512, attr.Blocks)
+			assert.Equal(t, tt.data, f.read(node, fh, bs-2, 4))
+			assert.Equal(t, tt.blocks, f.statfs().Blocks-f.statfs().Bfree)
+		})
+	}
+}
@@ -0,0 +1,178 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memfs
+
+import (
+	"context"
+	"syscall"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+)
+
+// go-fuse looks for FileSetlker on the node rather than on the file
+// handle, so locks are node operations.
+var (
+	_ = (fs.NodeGetlker)((*node)(nil))
+	_ = (fs.NodeSetlker)((*node)(nil))
+	_ = (fs.NodeSetlkwer)((*node)(nil))
+)
+
+// lock is a POSIX record lock on [start, end], end inclusive as in
+// fuse.FileLock.
+type lock struct {
+	owner      uint64
+	pid        uint32
+	typ        uint32
+	start, end uint64
+}
+
+func (l *lock) overlaps(start, end uint64) bool {
+	return l.start <= end && start <= l.end
+}
+
+// conflict returns a lock held by another owner that keeps owner from
+// taking lk, or nil. Must hold n.mu.
+func (n *node) conflict(owner uint64, lk *fuse.FileLock) *lock {
+	for i := range n.locks {
+		l := &n.locks[i]
+		if l.owner == owner || !l.overlaps(lk.Start, lk.End) {
+			continue
+		}
+		if l.typ == syscall.F_WRLCK || lk.Typ == syscall.F_WRLCK {
+			return l
+		}
+	}
+	return nil
+}
+
+// setLock replaces the locks of owner in the range of lk with lk, which
+// may be F_UNLCK. Must hold n.mu.
+func (n *node) setLock(owner uint64, lk *fuse.FileLock) {
+	var locks []lock
+	for _, l := range n.locks {
+		if l.owner != owner || !l.overlaps(lk.Start, lk.End) {
+			locks = append(locks, l)
+			continue
+		}
+		// Keep the parts outside the new range.
+		if l.start < lk.Start {
+			head := l
+			head.end = lk.Start - 1
+			locks = append(locks, head)
+		}
+		if l.end > lk.End {
+			tail := l
+			tail.start = lk.End + 1
+			locks = append(locks, tail)
+		}
+	}
+	if lk.Typ != syscall.F_UNLCK {
+		locks = append(locks, lock{owner: owner, pid: lk.Pid, typ: lk.Typ, start: lk.Start, end: lk.End})
+	}
+	n.locks = locks
+	n.wakeLockers()
+}
+
+// wakeLockers lets waiting Setlkw calls retry. Must hold n.mu.
+func (n *node) wakeLockers() {
+	if n.unlocked != nil {
+		close(n.unlocked)
+		n.unlocked = nil
+	}
+}
+
+// unlockOwner drops all locks of owner.
+func (n *node) unlockOwner(owner uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.setLock(owner, &fuse.FileLock{Start: 0, End: ^uint64(0), Typ: syscall.F_UNLCK})
+}
+
+func checkLock(lk *fuse.FileLock) syscall.Errno {
+	switch {
+	case lk.Typ != syscall.F_RDLCK && lk.Typ != syscall.F_WRLCK && lk.Typ != syscall.F_UNLCK:
+		return syscall.EINVAL
+	case lk.Start > lk.End:
+		return syscall.EINVAL
+	}
+	return 0
+}
+
+func (n *node) Getlk(ctx context.Context, f fs.FileHandle, owner uint64, lk *fuse.FileLock, flags uint32, out *fuse.FileLock) syscall.Errno {
+	if errno := checkLock(lk); errno != 0 {
+		return errno
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if l := n.conflict(owner, lk); l != nil {
+		*out = fuse.FileLock{Start: l.start, End: l.end, Typ: l.typ, Pid: l.pid}
+		return 0
+	}
+	*out = *lk
+	out.Typ = syscall.F_UNLCK
+	return 0
+}
+
+func (n *node) Setlk(ctx context.Context, f fs.FileHandle, owner uint64, lk *fuse.FileLock, flags uint32) syscall.Errno {
+	if errno := checkLock(lk); errno != 0 {
+		return errno
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !n.tryLock(f, owner, lk) {
+		return syscall.EAGAIN
+	}
+	return 0
+}
+
+// Setlkw waits until the lock can be taken or ctx is done.
+func (n *node) Setlkw(ctx context.Context, f fs.FileHandle, owner uint64, lk *fuse.FileLock, flags uint32) syscall.Errno {
+	if errno := checkLock(lk); errno != 0 {
+		return errno
+	}
+	for {
+		n.mu.Lock()
+		if n.tryLock(f, owner, lk) {
+			n.mu.Unlock()
+			return 0
+		}
+		if n.unlocked == nil {
+			n.unlocked = make(chan struct{})
+		}
+		unlocked := n.unlocked
+		n.mu.Unlock()
+
+		select {
+		case <-unlocked:
+		case <-ctx.Done():
+			return syscall.EINTR
+		}
+	}
+}
+
+// tryLock sets lk unless it conflicts with another owner. Must hold n.mu.
+func (n *node) tryLock(f fs.FileHandle, owner uint64, lk *fuse.FileLock) bool {
+	if lk.Typ != syscall.F_UNLCK && n.conflict(owner, lk) != nil {
+		return false
+	}
+	n.setLock(owner, lk)
+	if h, ok := f.(*handle); ok && lk.Typ != syscall.F_UNLCK {
+		h.addOwner(owner)
+	}
+	return true
+}
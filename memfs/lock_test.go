@@ -0,0 +1,127 @@
+package memfs
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lkIn(nodeID, fh, owner uint64, typ uint32, start, end uint64) *fuse.LkIn {
+	return &fuse.LkIn{InHeader: header(nodeID), Fh: fh, Owner: owner, Lk: fuse.FileLock{Start: start, End: end, Typ: typ, Pid: uint32(owner)}}
+}
+
+func TestLocks(t *testing.T) {
+	type step struct {
+		owner      uint64
+		typ        uint32
+		start, end uint64
+		want       fuse.Status
+	}
+	tests := []struct {
+		name  string
+		steps []step
+		// probe is a write lock on [0, 100] by owner 9.
+		conflict fuse.FileLock
+	}{
+		{
+			name:     "no locks",
+			conflict: fuse.FileLock{Start: 0, End: 100, Typ: syscall.F_UNLCK, Pid: 9},
+		},
+		{
+			name: "shared read locks",
+			steps: []step{
+				{owner: 1, typ: syscall.F_RDLCK, start: 0, end: 10},
+				{owner: 2, typ: syscall.F_RDLCK, start: 5, end: 15},
+				{owner: 3, typ: syscall.F_WRLCK, start: 10, end: 20, want: fuse.EAGAIN},
+			},
+			conflict: fuse.FileLock{Start: 0, End: 10, Typ: syscall.F_RDLCK, Pid: 1},
+		},
+		{
+			name: "upgrade own lock",
+			steps: []step{
+				{owner: 1, typ: syscall.F_RDLCK, start: 0, end: 10},
+				{owner: 1, typ: syscall.F_WRLCK, start: 0, end: 10},
+			},
+			conflict: fuse.FileLock{Start: 0, End: 10, Typ: syscall.F_WRLCK, Pid: 1},
+		},
+		{
+			name: "unlock splits",
+			steps: []step{
+				{owner: 1, typ: syscall.F_WRLCK, start: 50, end: 200},
+				{owner: 1, typ: syscall.F_UNLCK, start: 60, end: 100},
+				{owner: 2, typ: syscall.F_WRLCK, start: 60, end: 100},
+				{owner: 2, typ: syscall.F_WRLCK, start: 100, end: 101, want: fuse.EAGAIN},
+			},
+			conflict: fuse.FileLock{Start: 50, End: 59, Typ: syscall.F_WRLCK, Pid: 1},
+		},
+		{
+			name: "disjoint",
+			steps: []step{
+				{owner: 1, typ: syscall.F_WRLCK, start: 101, end: 200},
+			},
+			conflict: fuse.FileLock{Start: 0, End: 100, Typ: syscall.F_UNLCK, Pid: 9},
+		},
+		{
+			name: "invalid",
+			steps: []step{
+				{owner: 1, typ: syscall.F_WRLCK, start: 20, end: 10, want: fuse.EINVAL},
+				{owner: 1, typ: 42, start: 0, end: 10, want: fuse.EINVAL},
+			},
+			conflict: fuse.FileLock{Start: 0, End: 100, Typ: syscall.F_UNLCK, Pid: 9},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFS(t, nil)
+			node, fh := f.create(fuse.FUSE_ROOT_ID, "file")
+			for i, s := range tt.steps {
+				assert.Equal(t, s.want, f.rfs.SetLk(nil, lkIn(node, fh, s.owner, s.typ, s.start, s.end)), "step %d", i)
+			}
+			var out fuse.LkOut
+			require.Equal(t, fuse.OK, f.rfs.GetLk(nil, lkIn(node, fh, 9, syscall.F_WRLCK, 0, 100), &out))
+			assert.Equal(t, tt.conflict, out.Lk)
+		})
+	}
+}
+
+func TestSetLkw(t *testing.T) {
+	f := newTestFS(t, nil)
+	node, fh := f.create(fuse.FUSE_ROOT_ID, "file")
+	require.Equal(t, fuse.OK, f.rfs.SetLk(nil, lkIn(node, fh, 1, syscall.F_WRLCK, 0, 10)))
+
+	done := make(chan fuse.Status)
+	go func() {
+		done <- f.rfs.SetLkw(make(chan struct{}), lkIn(node, fh, 2, syscall.F_WRLCK, 5, 20))
+	}()
+	select {
+	case st := <-done:
+		t.Fatalf("SetLkw returned %v while the lock was held", st)
+	case <-time.After(50 * time.Millisecond):
+	}
+	require.Equal(t, fuse.OK, f.rfs.SetLk(nil, lkIn(node, fh, 1, syscall.F_UNLCK, 0, 10)))
+	assert.Equal(t, fuse.OK, <-done)
+
+	// A cancelled wait gives up.
+	cancel := make(chan struct{})
+	go func() {
+		done <- f.rfs.SetLkw(cancel, lkIn(node, fh, 3, syscall.F_RDLCK, 0, 100))
+	}()
+	close(cancel)
+	assert.Equal(t, fuse.EINTR, <-done)
+}
+
+func TestReleaseDropsLocks(t *testing.T) {
+	f := newTestFS(t, nil)
+	node, fh := f.create(fuse.FUSE_ROOT_ID, "file")
+	var open fuse.OpenOut
+	require.Equal(t, fuse.OK, f.rfs.Open(nil, &fuse.OpenIn{InHeader: header(node), Flags: syscall.O_RDWR}, &open))
+
+	require.Equal(t, fuse.OK, f.rfs.SetLk(nil, lkIn(node, fh, 1, syscall.F_WRLCK, 0, 10)))
+	assert.Equal(t, fuse.EAGAIN, f.rfs.SetLk(nil, lkIn(node, open.Fh, 2, syscall.F_WRLCK, 0, 10)))
+	f.rfs.Release(nil, &fuse.ReleaseIn{InHeader: header(node), Fh: fh})
+	assert.Equal(t, fuse.OK, f.rfs.SetLk(nil, lkIn(node, open.Fh, 2, syscall.F_WRLCK, 0, 10)))
+}
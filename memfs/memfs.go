@@ -0,0 +1,177 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package memfs is a file system held entirely in memory. It supports
+// regular files with holes, directories, hard links, symlinks, special
+// files, extended attributes and POSIX record locks, and can be served
+// with fuse2grpc:
+//
+//	srv := fuse2grpc.NewServer(memfs.New(&memfs.Options{Capacity: 1 << 30}, nil))
+//
+// Permissions are not checked; mount with default_permissions to have the
+// kernel do it.
+package memfs
+
+import (
+	"context"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+)
+
+const (
+	defaultBlockSize = 4096
+
+	// unlimited is what StatFs reports as the size of a file system
+	// without a Capacity or MaxInodes.
+	unlimited = 1 << 50
+
+	maxNameLen = 255
+)
+
+// Options configures a file system. The zero value is usable.
+type Options struct {
+	// Capacity is the number of bytes of file data the file system
+	// holds, 0 for no limit. Writes that need more fail with ENOSPC.
+	Capacity uint64
+	// MaxInodes is the number of inodes the file system holds, 0 for no
+	// limit.
+	MaxInodes uint64
+	// BlockSize is the allocation unit of file data, 4096 by default.
+	BlockSize uint32
+}
+
+// FS is the state shared by all nodes of a file system.
+type FS struct {
+	opts Options
+
+	mu      sync.Mutex
+	nextIno uint64
+	blocks  uint64 // allocated data blocks
+	inodes  uint64
+
+	// treeMu serializes changes to the tree, so checks like "the target
+	// is an empty directory" still hold when the change is made.
+	treeMu sync.Mutex
+}
+
+// NewRoot returns the root directory of a new file system, to be passed to
+// fs.NewNodeFS or fs.Mount.
+func NewRoot(opts *Options) fs.InodeEmbedder {
+	fsys := &FS{nextIno: 2, inodes: 1}
+	if opts != nil {
+		fsys.opts = *opts
+	}
+	if fsys.opts.BlockSize == 0 {
+		fsys.opts.BlockSize = defaultBlockSize
+	}
+	root := fsys.newNode(syscall.S_IFDIR|0755, 0, 0)
+	root.attr.Nlink = 2
+	return root
+}
+
+// New returns a new file system as a fuse.RawFileSystem, ready for
+// fuse2grpc.NewServer. fsOpts may be nil.
+func New(opts *Options, fsOpts *fs.Options) fuse.RawFileSystem {
+	if fsOpts == nil {
+		fsOpts = &fs.Options{}
+	}
+	return fs.NewNodeFS(NewRoot(opts), fsOpts)
+}
+
+func (fsys *FS) newNode(mode, uid, gid uint32) *node {
+	n := &node{fsys: fsys, xattrs: map[string][]byte{}}
+	n.attr.Mode = mode
+	n.attr.Nlink = 1
+	n.attr.Owner = fuse.Owner{Uid: uid, Gid: gid}
+	now := time.Now()
+	n.attr.SetTimes(&now, &now, &now)
+	setBlksize(&n.attr, fsys.opts.BlockSize)
+	return n
+}
+
+// allocIno reserves an inode number for a new node.
+func (fsys *FS) allocIno() (uint64, syscall.Errno) {
+	fsys.mu.Lock()
+	defer fsys.mu.Unlock()
+	if fsys.opts.MaxInodes > 0 && fsys.inodes >= fsys.opts.MaxInodes {
+		return 0, syscall.ENOSPC
+	}
+	fsys.inodes++
+	ino := fsys.nextIno
+	fsys.nextIno++
+	return ino, 0
+}
+
+func (fsys *FS) freeIno() {
+	fsys.mu.Lock()
+	defer fsys.mu.Unlock()
+	fsys.inodes--
+}
+
+// allocBlocks reserves n data blocks.
+func (fsys *FS) allocBlocks(n uint64) syscall.Errno {
+	if n == 0 {
+		return 0
+	}
+	fsys.mu.Lock()
+	defer fsys.mu.Unlock()
+	if capacity := fsys.opts.Capacity; capacity > 0 && (fsys.blocks+n)*uint64(fsys.opts.BlockSize) > capacity {
+		return syscall.ENOSPC
+	}
+	fsys.blocks += n
+	return 0
+}
+
+func (fsys *FS) freeBlocks(n uint64) {
+	fsys.mu.Lock()
+	defer fsys.mu.Unlock()
+	fsys.blocks -= n
+}
+
+func (fsys *FS) statfs(out *fuse.StatfsOut) {
+	fsys.mu.Lock()
+	defer fsys.mu.Unlock()
+
+	bs := uint64(fsys.opts.BlockSize)
+	total := uint64(unlimited) / bs
+	if fsys.opts.Capacity > 0 {
+		total = fsys.opts.Capacity / bs
+	}
+	files := uint64(unlimited)
+	if fsys.opts.MaxInodes > 0 {
+		files = fsys.opts.MaxInodes
+	}
+
+	out.Bsize = fsys.opts.BlockSize
+	out.Frsize = fsys.opts.BlockSize
+	out.NameLen = maxNameLen
+	out.Blocks = total
+	out.Bfree = total - fsys.blocks
+	out.Bavail = out.Bfree
+	out.Files = files
+	out.Ffree = files - fsys.inodes
+}
+
+func callerOwner(ctx context.Context) (uint32, uint32) {
+	if caller, ok := fuse.FromContext(ctx); ok {
+		return caller.Uid, caller.Gid
+	}
+	return 0, 0
+}
@@ -0,0 +1,113 @@
+package memfs
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chiyutianyi/grpcfuse/grpcfusetest"
+)
+
+type testFS struct {
+	t   *testing.T
+	rfs fuse.RawFileSystem
+}
+
+func newTestFS(t *testing.T, opts *Options) *testFS {
+	return &testFS{t: t, rfs: New(opts, nil)}
+}
+
+func header(nodeID uint64) fuse.InHeader {
+	return fuse.InHeader{NodeId: nodeID, Caller: fuse.Caller{Owner: fuse.Owner{Uid: 1, Gid: 2}}}
+}
+
+func (f *testFS) create(parent uint64, name string) (uint64, uint64) {
+	var out fuse.CreateOut
+	st := f.rfs.Create(nil, &fuse.CreateIn{InHeader: header(parent), Flags: syscall.O_RDWR, Mode: 0644}, name, &out)
+	require.Equal(f.t, fuse.OK, st, "create %s", name)
+	return out.NodeId, out.Fh
+}
+
+func (f *testFS) mkdir(parent uint64, name string) uint64 {
+	var out fuse.EntryOut
+	st := f.rfs.Mkdir(nil, &fuse.MkdirIn{InHeader: header(parent), Mode: 0755}, name, &out)
+	require.Equal(f.t, fuse.OK, st, "mkdir %s", name)
+	return out.NodeId
+}
+
+func (f *testFS) write(nodeID, fh, off uint64, data []byte) fuse.Status {
+	_, st := f.rfs.Write(nil, &fuse.WriteIn{InHeader: header(nodeID), Fh: fh, Offset: off}, data)
+	return st
+}
+
+func (f *testFS) read(nodeID, fh, off uint64, size int) []byte {
+	res, st := f.rfs.Read(nil, &fuse.ReadIn{InHeader: header(nodeID), Fh: fh, Offset: off}, make([]byte, size))
+	require.Equal(f.t, fuse.OK, st)
+	data, st := res.Bytes(make([]byte, size))
+	require.Equal(f.t, fuse.OK, st)
+	return data
+}
+
+func (f *testFS) getattr(nodeID uint64) fuse.Attr {
+	var out fuse.AttrOut
+	require.Equal(f.t, fuse.OK, f.rfs.GetAttr(nil, &fuse.GetAttrIn{InHeader: header(nodeID)}, &out))
+	return out.Attr
+}
+
+func (f *testFS) statfs() fuse.StatfsOut {
+	var out fuse.StatfsOut
+	require.Equal(f.t, fuse.OK, f.rfs.StatFs(nil, &fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}, &out))
+	return out
+}
+
+func TestConformance(t *testing.T) {
+	grpcfusetest.RunConformance(t, func(t *testing.T) fuse.RawFileSystem {
+		return New(nil, nil)
+	}, nil)
+}
+
+func TestCapacity(t *testing.T) {
+	f := newTestFS(t, &Options{Capacity: 4 * 1024, MaxInodes: 3, BlockSize: 1024})
+
+	st := f.statfs()
+	assert.Equal(t, uint32(1024), st.Bsize)
+	assert.Equal(t, uint64(4), st.Blocks)
+	assert.Equal(t, uint64(4), st.Bfree)
+	assert.Equal(t, uint64(3), st.Files)
+	assert.Equal(t, uint64(2), st.Ffree)
+
+	node, fh := f.create(fuse.FUSE_ROOT_ID, "file")
+	assert.Equal(t, fuse.OK, f.write(node, fh, 0, make([]byte, 3*1024)))
+	assert.Equal(t, fuse.Status(syscall.ENOSPC), f.write(node, fh, 3*1024, make([]byte, 1025)))
+	assert.Equal(t, fuse.Status(syscall.ENOSPC), f.fallocate(node, fh, 0, 1<<62, 0))
+	assert.Equal(t, fuse.OK, f.write(node, fh, 3*1024, make([]byte, 1024)))
+	st = f.statfs()
+	assert.Equal(t, uint64(0), st.Bfree)
+	assert.Equal(t, uint64(1), st.Ffree)
+	assert.Equal(t, uint64(4*1024/512), f.getattr(node).Blocks)
+
+	f.mkdir(fuse.FUSE_ROOT_ID, "dir")
+	var out fuse.EntryOut
+	assert.Equal(t, fuse.Status(syscall.ENOSPC),
+		f.rfs.Mkdir(nil, &fuse.MkdirIn{InHeader: header(fuse.FUSE_ROOT_ID), Mode: 0755}, "full", &out))
+
+	// Blocks and inodes come back once the file is unlinked and closed.
+	assert.Equal(t, fuse.OK, f.rfs.Unlink(nil, &fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}, "file"))
+	assert.Equal(t, uint64(0), f.statfs().Bfree)
+	f.rfs.Release(nil, &fuse.ReleaseIn{InHeader: header(node), Fh: fh})
+	st = f.statfs()
+	assert.Equal(t, uint64(4), st.Bfree)
+	assert.Equal(t, uint64(1), st.Ffree)
+}
+
+func TestOwner(t *testing.T) {
+	f := newTestFS(t, nil)
+	node, _ := f.create(fuse.FUSE_ROOT_ID, "file")
+	attr := f.getattr(node)
+	assert.Equal(t, fuse.Owner{Uid: 1, Gid: 2}, attr.Owner)
+	assert.Equal(t, uint32(syscall.S_IFREG|0644), attr.Mode)
+	assert.Equal(t, uint32(1), attr.Nlink)
+}
@@ -0,0 +1,250 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memfs
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+)
+
+const (
+	xattrCreate  = 1 // XATTR_CREATE
+	xattrReplace = 2 // XATTR_REPLACE
+
+	maxXAttrSize = 64 << 10
+)
+
+// node is any kind of file. Directories keep their entries in the
+// embedded fs.Inode.
+type node struct {
+	fs.Inode
+	fsys *FS
+
+	mu     sync.Mutex
+	attr   fuse.Attr
+	xattrs map[string][]byte
+	opens  int
+	freed  bool
+
+	// target is the target of a symlink.
+	target []byte
+	// data holds the allocated blocks of a regular file by index.
+	// Missing blocks are holes.
+	data map[uint64][]byte
+
+	locks    []lock
+	unlocked chan struct{}
+}
+
+var (
+	_ = (fs.NodeGetattrer)((*node)(nil))
+	_ = (fs.NodeSetattrer)((*node)(nil))
+	_ = (fs.NodeGetxattrer)((*node)(nil))
+	_ = (fs.NodeSetxattrer)((*node)(nil))
+	_ = (fs.NodeRemovexattrer)((*node)(nil))
+	_ = (fs.NodeListxattrer)((*node)(nil))
+	_ = (fs.NodeReadlinker)((*node)(nil))
+	_ = (fs.NodeStatfser)((*node)(nil))
+)
+
+func (n *node) isDir() bool {
+	return n.attr.Mode&syscall.S_IFMT == syscall.S_IFDIR
+}
+
+// touch updates the times named by which ("a", "m", "c") to now. Must
+// hold n.mu.
+func (n *node) touch(which string) {
+	now := time.Now()
+	for _, c := range which {
+		switch c {
+		case 'a':
+			n.attr.SetTimes(&now, nil, nil)
+		case 'm':
+			n.attr.SetTimes(nil, &now, nil)
+		case 'c':
+			n.attr.SetTimes(nil, nil, &now)
+		}
+	}
+}
+
+// fillAttr copies the attributes to out. Must hold n.mu.
+func (n *node) fillAttr(out *fuse.Attr) {
+	*out = n.attr
+	out.Ino = n.StableAttr().Ino
+	out.Blocks = uint64(len(n.data)) * uint64(n.fsys.opts.BlockSize) / 512
+}
+
+func (n *node) Getattr(ctx context.Context, f fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.fillAttr(&out.Attr)
+	return 0
+}
+
+func (n *node) Setattr(ctx context.Context, f fs.FileHandle, in *fuse.SetAttrIn, out *fuse.AttrOut) syscall.Errno {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if sz, ok := in.GetSize(); ok {
+		switch n.attr.Mode & syscall.S_IFMT {
+		case syscall.S_IFREG:
+			n.truncate(sz)
+			n.touch("m")
+		case syscall.S_IFDIR:
+			return syscall.EISDIR
+		default:
+			return syscall.EINVAL
+		}
+	}
+	if mode, ok := in.GetMode(); ok {
+		n.attr.Mode = n.attr.Mode&syscall.S_IFMT | mode
+	}
+	if uid, ok := in.GetUID(); ok {
+		n.attr.Uid = uid
+	}
+	if gid, ok := in.GetGID(); ok {
+		n.attr.Gid = gid
+	}
+	if atime, ok := in.GetATime(); ok {
+		n.attr.SetTimes(&atime, nil, nil)
+	}
+	if mtime, ok := in.GetMTime(); ok {
+		n.attr.SetTimes(nil, &mtime, nil)
+	}
+	n.touch("c")
+	n.fillAttr(&out.Attr)
+	return 0
+}
+
+func (n *node) Readlink(ctx context.Context) ([]byte, syscall.Errno) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.attr.Mode&syscall.S_IFMT != syscall.S_IFLNK {
+		return nil, syscall.EINVAL
+	}
+	return append([]byte(nil), n.target...), 0
+}
+
+func (n *node) Statfs(ctx context.Context, out *fuse.StatfsOut) syscall.Errno {
+	n.fsys.statfs(out)
+	return 0
+}
+
+func (n *node) Getxattr(ctx context.Context, attr string, dest []byte) (uint32, syscall.Errno) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	value, ok := n.xattrs[attr]
+	if !ok {
+		return 0, fs.ENOATTR
+	}
+	if len(dest) == 0 {
+		return uint32(len(value)), 0
+	}
+	if len(dest) < len(value) {
+		return uint32(len(value)), syscall.ERANGE
+	}
+	return uint32(copy(dest, value)), 0
+}
+
+func (n *node) Setxattr(ctx context.Context, attr string, data []byte, flags uint32) syscall.Errno {
+	if len(attr) > maxNameLen {
+		return syscall.ERANGE
+	}
+	if len(data) > maxXAttrSize {
+		return syscall.E2BIG
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	_, ok := n.xattrs[attr]
+	switch {
+	case flags&xattrCreate != 0 && ok:
+		return syscall.EEXIST
+	case flags&xattrReplace != 0 && !ok:
+		return fs.ENOATTR
+	}
+	n.xattrs[attr] = append([]byte{}, data...)
+	n.touch("c")
+	return 0
+}
+
+func (n *node) Removexattr(ctx context.Context, attr string) syscall.Errno {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if _, ok := n.xattrs[attr]; !ok {
+		return fs.ENOATTR
+	}
+	delete(n.xattrs, attr)
+	n.touch("c")
+	return 0
+}
+
+func (n *node) Listxattr(ctx context.Context, dest []byte) (uint32, syscall.Errno) {
+	n.mu.Lock()
+	names := make([]string, 0, len(n.xattrs))
+	for name := range n.xattrs {
+		names = append(names, name)
+	}
+	n.mu.Unlock()
+	sort.Strings(names)
+
+	var list []byte
+	for _, name := range names {
+		list = append(append(list, name...), 0)
+	}
+	if len(dest) == 0 {
+		return uint32(len(list)), 0
+	}
+	if len(dest) < len(list) {
+		return uint32(len(list)), syscall.ERANGE
+	}
+	return uint32(copy(dest, list)), 0
+}
+
+// unref drops one link to n, and frees it once it has no links and is not
+// open.
+func (n *node) unref() {
+	n.mu.Lock()
+	if n.attr.Nlink > 0 {
+		n.attr.Nlink--
+	}
+	n.touch("c")
+	n.mu.Unlock()
+	n.maybeFree()
+}
+
+// maybeFree releases the blocks and the inode of a node that is gone.
+func (n *node) maybeFree() {
+	n.mu.Lock()
+	if n.attr.Nlink > 0 || n.opens > 0 || n.freed {
+		n.mu.Unlock()
+		return
+	}
+	n.freed = true
+	blocks := uint64(len(n.data))
+	n.data = nil
+	n.mu.Unlock()
+
+	n.fsys.freeBlocks(blocks)
+	n.fsys.freeIno()
+	n.ForgetPersistent()
+}
@@ -0,0 +1,90 @@
+package memfs
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestXAttr(t *testing.T) {
+	f := newTestFS(t, nil)
+	node, _ := f.create(fuse.FUSE_ROOT_ID, "file")
+	set := func(name string, value string, flags uint32) fuse.Status {
+		return f.rfs.SetXAttr(nil, &fuse.SetXAttrIn{InHeader: header(node), Size: uint32(len(value)), Flags: flags}, name, []byte(value))
+	}
+
+	assert.Equal(t, fuse.OK, set("user.b", "bee", 0))
+	assert.Equal(t, fuse.OK, set("user.a", "ay", xattrCreate))
+	assert.Equal(t, fuse.Status(syscall.EEXIST), set("user.a", "x", xattrCreate))
+	assert.Equal(t, fuse.ENODATA, set("user.c", "x", xattrReplace))
+	assert.Equal(t, fuse.OK, set("user.b", "b", xattrReplace))
+	assert.Equal(t, fuse.Status(syscall.E2BIG), set("user.big", string(make([]byte, maxXAttrSize+1)), 0))
+
+	tests := []struct {
+		name  string
+		attr  string
+		size  int
+		want  string
+		sz    uint32
+		st    fuse.Status
+		isAll bool
+	}{
+		{name: "get", attr: "user.a", size: 10, want: "ay", sz: 2},
+		{name: "get replaced", attr: "user.b", size: 10, want: "b", sz: 1},
+		{name: "get size", attr: "user.a", size: 0, sz: 2},
+		{name: "get too small", attr: "user.a", size: 1, sz: 2, st: fuse.ERANGE},
+		{name: "get missing", attr: "user.c", size: 10, st: fuse.ENODATA},
+		{name: "list", isAll: true, size: 100, want: "user.a\x00user.b\x00", sz: 14},
+		{name: "list size", isAll: true, size: 0, sz: 14},
+		{name: "list too small", isAll: true, size: 5, sz: 14, st: fuse.ERANGE},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := make([]byte, tt.size)
+			var sz uint32
+			var st fuse.Status
+			if tt.isAll {
+				sz, st = f.rfs.ListXAttr(nil, &fuse.InHeader{NodeId: node}, dest)
+			} else {
+				sz, st = f.rfs.GetXAttr(nil, &fuse.InHeader{NodeId: node}, tt.attr, dest)
+			}
+			assert.Equal(t, tt.st, st)
+			assert.Equal(t, tt.sz, sz)
+			if tt.want != "" {
+				assert.Equal(t, tt.want, string(dest[:sz]))
+			}
+		})
+	}
+
+	assert.Equal(t, fuse.OK, f.rfs.RemoveXAttr(nil, &fuse.InHeader{NodeId: node}, "user.a"))
+	assert.Equal(t, fuse.ENODATA, f.rfs.RemoveXAttr(nil, &fuse.InHeader{NodeId: node}, "user.a"))
+}
+
+func TestSetAttr(t *testing.T) {
+	f := newTestFS(t, nil)
+	node, _ := f.create(fuse.FUSE_ROOT_ID, "file")
+	mtime := time.Unix(1000, 5)
+
+	var out fuse.AttrOut
+	require.Equal(t, fuse.OK, f.rfs.SetAttr(nil, &fuse.SetAttrIn{SetAttrInCommon: fuse.SetAttrInCommon{
+		InHeader:  header(node),
+		Valid:     fuse.FATTR_MODE | fuse.FATTR_UID | fuse.FATTR_GID | fuse.FATTR_MTIME,
+		Mode:      syscall.S_IFDIR | 04700,
+		Owner:     fuse.Owner{Uid: 7, Gid: 8},
+		Mtime:     uint64(mtime.Unix()),
+		Mtimensec: uint32(mtime.Nanosecond()),
+	}}, &out))
+	assert.Equal(t, uint32(syscall.S_IFREG|04700), out.Mode)
+	assert.Equal(t, fuse.Owner{Uid: 7, Gid: 8}, out.Owner)
+	assert.Equal(t, mtime, time.Unix(int64(out.Mtime), int64(out.Mtimensec)))
+	assert.Equal(t, out.Attr, f.getattr(node))
+
+	assert.Equal(t, fuse.Status(syscall.EISDIR), f.rfs.SetAttr(nil, &fuse.SetAttrIn{SetAttrInCommon: fuse.SetAttrInCommon{
+		InHeader: header(fuse.FUSE_ROOT_ID),
+		Valid:    fuse.FATTR_SIZE,
+	}}, &out))
+}
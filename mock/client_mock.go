@@ -58,6 +58,26 @@ func (mr *MockRawFileSystemClientMockRecorder) Access(ctx, in interface{}, opts
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Access", reflect.TypeOf((*MockRawFileSystemClient)(nil).Access), varargs...)
 }
 
+// Compound mocks base method.
+func (m *MockRawFileSystemClient) Compound(ctx context.Context, in *pb.CompoundRequest, opts ...grpc.CallOption) (*pb.CompoundResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Compound", varargs...)
+	ret0, _ := ret[0].(*pb.CompoundResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Compound indicates an expected call of Compound.
+func (mr *MockRawFileSystemClientMockRecorder) Compound(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Compound", reflect.TypeOf((*MockRawFileSystemClient)(nil).Compound), varargs...)
+}
+
 // CopyFileRange mocks base method.
 func (m *MockRawFileSystemClient) CopyFileRange(ctx context.Context, in *pb.CopyFileRangeRequest, opts ...grpc.CallOption) (*pb.CopyFileRangeResponse, error) {
 	m.ctrl.T.Helper()
@@ -1185,6 +1205,21 @@ func (mr *MockRawFileSystemServerMockRecorder) Access(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Access", reflect.TypeOf((*MockRawFileSystemServer)(nil).Access), arg0, arg1)
 }
 
+// Compound mocks base method.
+func (m *MockRawFileSystemServer) Compound(arg0 context.Context, arg1 *pb.CompoundRequest) (*pb.CompoundResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Compound", arg0, arg1)
+	ret0, _ := ret[0].(*pb.CompoundResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Compound indicates an expected call of Compound.
+func (mr *MockRawFileSystemServerMockRecorder) Compound(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Compound", reflect.TypeOf((*MockRawFileSystemServer)(nil).Compound), arg0, arg1)
+}
+
 // CopyFileRange mocks base method.
 func (m *MockRawFileSystemServer) CopyFileRange(arg0 context.Context, arg1 *pb.CopyFileRangeRequest) (*pb.CopyFileRangeResponse, error) {
 	m.ctrl.T.Helper()
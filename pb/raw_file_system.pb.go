@@ -1666,7 +1666,9 @@ type GetXAttrRequest struct {
 
 	Header *InHeader `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
 	Attr   string    `protobuf:"bytes,2,opt,name=attr,proto3" json:"attr,omitempty"`
-	Dest   []byte    `protobuf:"bytes,3,opt,name=dest,proto3" json:"dest,omitempty"`
+	// size is the size of the caller's buffer. 0 asks for the size of
+	// the attribute only.
+	Size uint32 `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
 }
 
 func (x *GetXAttrRequest) Reset() {
@@ -1715,11 +1717,11 @@ func (x *GetXAttrRequest) GetAttr() string {
 	return ""
 }
 
-func (x *GetXAttrRequest) GetDest() []byte {
+func (x *GetXAttrRequest) GetSize() uint32 {
 	if x != nil {
-		return x.Dest
+		return x.Size
 	}
-	return nil
+	return 0
 }
 
 type GetXAttrResponse struct {
@@ -1728,7 +1730,10 @@ type GetXAttrResponse struct {
 	unknownFields protoimpl.UnknownFields
 
 	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	Size   uint32  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	// size is the size of the attribute, also on ERANGE.
+	Size uint32 `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	// value is only set on success with a non-zero request size.
+	Value []byte `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
 }
 
 func (x *GetXAttrResponse) Reset() {
@@ -1777,13 +1782,22 @@ func (x *GetXAttrResponse) GetSize() uint32 {
 	return 0
 }
 
+func (x *GetXAttrResponse) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
 type ListXAttrRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	Header *InHeader `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
-	Dest   []byte    `protobuf:"bytes,2,opt,name=dest,proto3" json:"dest,omitempty"`
+	// size is the size of the caller's buffer. 0 asks for the size of
+	// the list only.
+	Size uint32 `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
 }
 
 func (x *ListXAttrRequest) Reset() {
@@ -1825,11 +1839,11 @@ func (x *ListXAttrRequest) GetHeader() *InHeader {
 	return nil
 }
 
-func (x *ListXAttrRequest) GetDest() []byte {
+func (x *ListXAttrRequest) GetSize() uint32 {
 	if x != nil {
-		return x.Dest
+		return x.Size
 	}
-	return nil
+	return 0
 }
 
 type ListXAttrResponse struct {
@@ -1838,7 +1852,10 @@ type ListXAttrResponse struct {
 	unknownFields protoimpl.UnknownFields
 
 	Status *Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	Size   uint32  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	// size is the size of the list, also on ERANGE.
+	Size uint32 `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	// value is only set on success with a non-zero request size.
+	Value []byte `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
 }
 
 func (x *ListXAttrResponse) Reset() {
@@ -1887,6 +1904,13 @@ func (x *ListXAttrResponse) GetSize() uint32 {
 	return 0
 }
 
+func (x *ListXAttrResponse) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
 type SetXAttrRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -2336,6 +2360,11 @@ type OpenResponse struct {
 
 	Status  *Status  `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
 	OpenOut *OpenOut `protobuf:"bytes,2,opt,name=open_out,json=openOut,proto3" json:"open_out,omitempty"`
+	// inline_data, on a read-only open of a server that inlines small
+	// files, is the start of the file; inline_eof is set if it is all of
+	// the file.
+	InlineData []byte `protobuf:"bytes,3,opt,name=inline_data,json=inlineData,proto3" json:"inline_data,omitempty"`
+	InlineEof  bool   `protobuf:"varint,4,opt,name=inline_eof,json=inlineEof,proto3" json:"inline_eof,omitempty"`
 }
 
 func (x *OpenResponse) Reset() {
@@ -2384,6 +2413,20 @@ func (x *OpenResponse) GetOpenOut() *OpenOut {
 	return nil
 }
 
+func (x *OpenResponse) GetInlineData() []byte {
+	if x != nil {
+		return x.InlineData
+	}
+	return nil
+}
+
+func (x *OpenResponse) GetInlineEof() bool {
+	if x != nil {
+		return x.InlineEof
+	}
+	return false
+}
+
 type ReadRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -3968,6 +4011,485 @@ func (x *StatfsResponse) GetSpare() []uint32 {
 	return nil
 }
 
+type CompoundOp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Op:
+	//	*CompoundOp_Lookup
+	//	*CompoundOp_GetAttr
+	//	*CompoundOp_Create
+	//	*CompoundOp_Open
+	//	*CompoundOp_Read
+	//	*CompoundOp_Write
+	//	*CompoundOp_Flush
+	//	*CompoundOp_Release
+	//	*CompoundOp_Fsync
+	Op isCompoundOp_Op `protobuf_oneof:"op"`
+	// node_from and fh_from, if not 0, are the position, from 1, of an
+	// earlier op whose node ID (of a Lookup or Create) or handle (of a
+	// Create or Open) this op takes.
+	NodeFrom uint32 `protobuf:"varint,10,opt,name=node_from,json=nodeFrom,proto3" json:"node_from,omitempty"`
+	FhFrom   uint32 `protobuf:"varint,11,opt,name=fh_from,json=fhFrom,proto3" json:"fh_from,omitempty"`
+}
+
+func (x *CompoundOp) Reset() {
+	*x = CompoundOp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_raw_file_system_proto_msgTypes[63]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CompoundOp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompoundOp) ProtoMessage() {}
+
+func (x *CompoundOp) ProtoReflect() protoreflect.Message {
+	mi := &file_raw_file_system_proto_msgTypes[63]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompoundOp.ProtoReflect.Descriptor instead.
+func (*CompoundOp) Descriptor() ([]byte, []int) {
+	return file_raw_file_system_proto_rawDescGZIP(), []int{63}
+}
+
+func (m *CompoundOp) GetOp() isCompoundOp_Op {
+	if m != nil {
+		return m.Op
+	}
+	return nil
+}
+
+func (x *CompoundOp) GetLookup() *LookupRequest {
+	if x, ok := x.GetOp().(*CompoundOp_Lookup); ok {
+		return x.Lookup
+	}
+	return nil
+}
+
+func (x *CompoundOp) GetGetAttr() *GetAttrRequest {
+	if x, ok := x.GetOp().(*CompoundOp_GetAttr); ok {
+		return x.GetAttr
+	}
+	return nil
+}
+
+func (x *CompoundOp) GetCreate() *CreateRequest {
+	if x, ok := x.GetOp().(*CompoundOp_Create); ok {
+		return x.Create
+	}
+	return nil
+}
+
+func (x *CompoundOp) GetOpen() *OpenRequest {
+	if x, ok := x.GetOp().(*CompoundOp_Open); ok {
+		return x.Open
+	}
+	return nil
+}
+
+func (x *CompoundOp) GetRead() *ReadRequest {
+	if x, ok := x.GetOp().(*CompoundOp_Read); ok {
+		return x.Read
+	}
+	return nil
+}
+
+func (x *CompoundOp) GetWrite() *WriteRequest {
+	if x, ok := x.GetOp().(*CompoundOp_Write); ok {
+		return x.Write
+	}
+	return nil
+}
+
+func (x *CompoundOp) GetFlush() *FlushRequest {
+	if x, ok := x.GetOp().(*CompoundOp_Flush); ok {
+		return x.Flush
+	}
+	return nil
+}
+
+func (x *CompoundOp) GetRelease() *ReleaseRequest {
+	if x, ok := x.GetOp().(*CompoundOp_Release); ok {
+		return x.Release
+	}
+	return nil
+}
+
+func (x *CompoundOp) GetFsync() *FsyncRequest {
+	if x, ok := x.GetOp().(*CompoundOp_Fsync); ok {
+		return x.Fsync
+	}
+	return nil
+}
+
+func (x *CompoundOp) GetNodeFrom() uint32 {
+	if x != nil {
+		return x.NodeFrom
+	}
+	return 0
+}
+
+func (x *CompoundOp) GetFhFrom() uint32 {
+	if x != nil {
+		return x.FhFrom
+	}
+	return 0
+}
+
+type isCompoundOp_Op interface {
+	isCompoundOp_Op()
+}
+
+type CompoundOp_Lookup struct {
+	Lookup *LookupRequest `protobuf:"bytes,1,opt,name=lookup,proto3,oneof"`
+}
+
+type CompoundOp_GetAttr struct {
+	GetAttr *GetAttrRequest `protobuf:"bytes,2,opt,name=get_attr,json=getAttr,proto3,oneof"`
+}
+
+type CompoundOp_Create struct {
+	Create *CreateRequest `protobuf:"bytes,3,opt,name=create,proto3,oneof"`
+}
+
+type CompoundOp_Open struct {
+	Open *OpenRequest `protobuf:"bytes,4,opt,name=open,proto3,oneof"`
+}
+
+type CompoundOp_Read struct {
+	Read *ReadRequest `protobuf:"bytes,5,opt,name=read,proto3,oneof"`
+}
+
+type CompoundOp_Write struct {
+	Write *WriteRequest `protobuf:"bytes,6,opt,name=write,proto3,oneof"`
+}
+
+type CompoundOp_Flush struct {
+	Flush *FlushRequest `protobuf:"bytes,7,opt,name=flush,proto3,oneof"`
+}
+
+type CompoundOp_Release struct {
+	Release *ReleaseRequest `protobuf:"bytes,8,opt,name=release,proto3,oneof"`
+}
+
+type CompoundOp_Fsync struct {
+	Fsync *FsyncRequest `protobuf:"bytes,9,opt,name=fsync,proto3,oneof"`
+}
+
+func (*CompoundOp_Lookup) isCompoundOp_Op() {}
+
+func (*CompoundOp_GetAttr) isCompoundOp_Op() {}
+
+func (*CompoundOp_Create) isCompoundOp_Op() {}
+
+func (*CompoundOp_Open) isCompoundOp_Op() {}
+
+func (*CompoundOp_Read) isCompoundOp_Op() {}
+
+func (*CompoundOp_Write) isCompoundOp_Op() {}
+
+func (*CompoundOp_Flush) isCompoundOp_Op() {}
+
+func (*CompoundOp_Release) isCompoundOp_Op() {}
+
+func (*CompoundOp_Fsync) isCompoundOp_Op() {}
+
+type CompoundRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Ops []*CompoundOp `protobuf:"bytes,1,rep,name=ops,proto3" json:"ops,omitempty"`
+}
+
+func (x *CompoundRequest) Reset() {
+	*x = CompoundRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_raw_file_system_proto_msgTypes[64]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CompoundRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompoundRequest) ProtoMessage() {}
+
+func (x *CompoundRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_raw_file_system_proto_msgTypes[64]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompoundRequest.ProtoReflect.Descriptor instead.
+func (*CompoundRequest) Descriptor() ([]byte, []int) {
+	return file_raw_file_system_proto_rawDescGZIP(), []int{64}
+}
+
+func (x *CompoundRequest) GetOps() []*CompoundOp {
+	if x != nil {
+		return x.Ops
+	}
+	return nil
+}
+
+type CompoundResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Result:
+	//	*CompoundResult_Lookup
+	//	*CompoundResult_GetAttr
+	//	*CompoundResult_Create
+	//	*CompoundResult_Open
+	//	*CompoundResult_Read
+	//	*CompoundResult_Write
+	//	*CompoundResult_Flush
+	//	*CompoundResult_Release
+	//	*CompoundResult_Fsync
+	Result isCompoundResult_Result `protobuf_oneof:"result"`
+}
+
+func (x *CompoundResult) Reset() {
+	*x = CompoundResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_raw_file_system_proto_msgTypes[65]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CompoundResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompoundResult) ProtoMessage() {}
+
+func (x *CompoundResult) ProtoReflect() protoreflect.Message {
+	mi := &file_raw_file_system_proto_msgTypes[65]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompoundResult.ProtoReflect.Descriptor instead.
+func (*CompoundResult) Descriptor() ([]byte, []int) {
+	return file_raw_file_system_proto_rawDescGZIP(), []int{65}
+}
+
+func (m *CompoundResult) GetResult() isCompoundResult_Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (x *CompoundResult) GetLookup() *LookupResponse {
+	if x, ok := x.GetResult().(*CompoundResult_Lookup); ok {
+		return x.Lookup
+	}
+	return nil
+}
+
+func (x *CompoundResult) GetGetAttr() *GetAttrResponse {
+	if x, ok := x.GetResult().(*CompoundResult_GetAttr); ok {
+		return x.GetAttr
+	}
+	return nil
+}
+
+func (x *CompoundResult) GetCreate() *CreateResponse {
+	if x, ok := x.GetResult().(*CompoundResult_Create); ok {
+		return x.Create
+	}
+	return nil
+}
+
+func (x *CompoundResult) GetOpen() *OpenResponse {
+	if x, ok := x.GetResult().(*CompoundResult_Open); ok {
+		return x.Open
+	}
+	return nil
+}
+
+func (x *CompoundResult) GetRead() *ReadResponse {
+	if x, ok := x.GetResult().(*CompoundResult_Read); ok {
+		return x.Read
+	}
+	return nil
+}
+
+func (x *CompoundResult) GetWrite() *WriteResponse {
+	if x, ok := x.GetResult().(*CompoundResult_Write); ok {
+		return x.Write
+	}
+	return nil
+}
+
+func (x *CompoundResult) GetFlush() *FlushResponse {
+	if x, ok := x.GetResult().(*CompoundResult_Flush); ok {
+		return x.Flush
+	}
+	return nil
+}
+
+func (x *CompoundResult) GetRelease() *emptypb.Empty {
+	if x, ok := x.GetResult().(*CompoundResult_Release); ok {
+		return x.Release
+	}
+	return nil
+}
+
+func (x *CompoundResult) GetFsync() *FsyncResponse {
+	if x, ok := x.GetResult().(*CompoundResult_Fsync); ok {
+		return x.Fsync
+	}
+	return nil
+}
+
+type isCompoundResult_Result interface {
+	isCompoundResult_Result()
+}
+
+type CompoundResult_Lookup struct {
+	Lookup *LookupResponse `protobuf:"bytes,1,opt,name=lookup,proto3,oneof"`
+}
+
+type CompoundResult_GetAttr struct {
+	GetAttr *GetAttrResponse `protobuf:"bytes,2,opt,name=get_attr,json=getAttr,proto3,oneof"`
+}
+
+type CompoundResult_Create struct {
+	Create *CreateResponse `protobuf:"bytes,3,opt,name=create,proto3,oneof"`
+}
+
+type CompoundResult_Open struct {
+	Open *OpenResponse `protobuf:"bytes,4,opt,name=open,proto3,oneof"`
+}
+
+type CompoundResult_Read struct {
+	Read *ReadResponse `protobuf:"bytes,5,opt,name=read,proto3,oneof"`
+}
+
+type CompoundResult_Write struct {
+	Write *WriteResponse `protobuf:"bytes,6,opt,name=write,proto3,oneof"`
+}
+
+type CompoundResult_Flush struct {
+	Flush *FlushResponse `protobuf:"bytes,7,opt,name=flush,proto3,oneof"`
+}
+
+type CompoundResult_Release struct {
+	Release *emptypb.Empty `protobuf:"bytes,8,opt,name=release,proto3,oneof"`
+}
+
+type CompoundResult_Fsync struct {
+	Fsync *FsyncResponse `protobuf:"bytes,9,opt,name=fsync,proto3,oneof"`
+}
+
+func (*CompoundResult_Lookup) isCompoundResult_Result() {}
+
+func (*CompoundResult_GetAttr) isCompoundResult_Result() {}
+
+func (*CompoundResult_Create) isCompoundResult_Result() {}
+
+func (*CompoundResult_Open) isCompoundResult_Result() {}
+
+func (*CompoundResult_Read) isCompoundResult_Result() {}
+
+func (*CompoundResult_Write) isCompoundResult_Result() {}
+
+func (*CompoundResult_Flush) isCompoundResult_Result() {}
+
+func (*CompoundResult_Release) isCompoundResult_Result() {}
+
+func (*CompoundResult_Fsync) isCompoundResult_Result() {}
+
+type CompoundResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// status is that of the op that failed, the last of results, or 0 if
+	// all ran.
+	Status  *Status           `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Results []*CompoundResult `protobuf:"bytes,2,rep,name=results,proto3" json:"results,omitempty"`
+}
+
+func (x *CompoundResponse) Reset() {
+	*x = CompoundResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_raw_file_system_proto_msgTypes[66]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CompoundResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompoundResponse) ProtoMessage() {}
+
+func (x *CompoundResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_raw_file_system_proto_msgTypes[66]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompoundResponse.ProtoReflect.Descriptor instead.
+func (*CompoundResponse) Descriptor() ([]byte, []int) {
+	return file_raw_file_system_proto_rawDescGZIP(), []int{66}
+}
+
+func (x *CompoundResponse) GetStatus() *Status {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+func (x *CompoundResponse) GetResults() []*CompoundResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
 var File_raw_file_system_proto protoreflect.FileDescriptor
 
 var file_raw_file_system_proto_rawDesc = []byte{
@@ -4139,375 +4661,453 @@ var file_raw_file_system_proto_rawDesc = []byte{
 	0x63, 0x65, 0x73, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a, 0x06,
 	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70,
 	0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
-	0x22, 0x5f, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x58, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x71, 0x75,
+	0x22, 0x6b, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x58, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x71, 0x75,
 	0x65, 0x73, 0x74, 0x12, 0x24, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20,
 	0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x49, 0x6e, 0x48, 0x65, 0x61, 0x64, 0x65,
 	0x72, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x74, 0x74,
 	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x61, 0x74, 0x74, 0x72, 0x12, 0x12, 0x0a,
-	0x04, 0x64, 0x65, 0x73, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x65, 0x73,
-	0x74, 0x22, 0x4a, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x58, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75,
-	0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a,
-	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x22, 0x4c, 0x0a,
-	0x10, 0x4c, 0x69, 0x73, 0x74, 0x58, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x12, 0x24, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x49, 0x6e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52,
-	0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x65, 0x73, 0x74, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x65, 0x73, 0x74, 0x22, 0x4b, 0x0a, 0x11, 0x4c,
-	0x69, 0x73, 0x74, 0x58, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x12, 0x22, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74,
-	0x61, 0x74, 0x75, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x0d, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x22, 0xbf, 0x01, 0x0a, 0x0f, 0x53, 0x65, 0x74,
+	0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x73, 0x69, 0x7a,
+	0x65, 0x4a, 0x04, 0x08, 0x03, 0x10, 0x04, 0x52, 0x04, 0x64, 0x65, 0x73, 0x74, 0x22, 0x60, 0x0a,
+	0x10, 0x47, 0x65, 0x74, 0x58, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x22, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22,
+	0x58, 0x0a, 0x10, 0x4c, 0x69, 0x73, 0x74, 0x58, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x24, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x49, 0x6e, 0x48, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x4a, 0x04, 0x08,
+	0x02, 0x10, 0x03, 0x52, 0x04, 0x64, 0x65, 0x73, 0x74, 0x22, 0x61, 0x0a, 0x11, 0x4c, 0x69, 0x73,
+	0x74, 0x58, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22,
+	0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a,
+	0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0xbf, 0x01, 0x0a,
+	0x0f, 0x53, 0x65, 0x74, 0x58, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x24, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x49, 0x6e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x06,
+	0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x74, 0x74, 0x72, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x61, 0x74, 0x74, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61,
+	0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x12,
+	0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x73, 0x69,
+	0x7a, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x6f, 0x73, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x70, 0x6f, 0x73, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x64, 0x64, 0x69, 0x6e, 0x67, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x70, 0x61, 0x64, 0x64, 0x69, 0x6e, 0x67, 0x22, 0x36,
+	0x0a, 0x10, 0x53, 0x65, 0x74, 0x58, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x22, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06,
+	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x4e, 0x0a, 0x12, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65,
 	0x58, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x24, 0x0a, 0x06,
 	0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70,
 	0x62, 0x2e, 0x49, 0x6e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64,
 	0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x74, 0x74, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x04, 0x61, 0x74, 0x74, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69,
-	0x7a, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x14,
-	0x0a, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x66,
-	0x6c, 0x61, 0x67, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e,
-	0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e,
-	0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x64, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x07, 0x20, 0x01, 0x28,
-	0x0d, 0x52, 0x07, 0x70, 0x61, 0x64, 0x64, 0x69, 0x6e, 0x67, 0x22, 0x36, 0x0a, 0x10, 0x53, 0x65,
-	0x74, 0x58, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22,
-	0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a,
-	0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74,
-	0x75, 0x73, 0x22, 0x4e, 0x0a, 0x12, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x58, 0x41, 0x74, 0x74,
-	0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x24, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64,
-	0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x49, 0x6e,
-	0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x12,
-	0x0a, 0x04, 0x61, 0x74, 0x74, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x61, 0x74,
-	0x74, 0x72, 0x22, 0x39, 0x0a, 0x13, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x58, 0x41, 0x74, 0x74,
-	0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a, 0x06, 0x73, 0x74, 0x61,
-	0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x53,
-	0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0xa3, 0x01,
-	0x0a, 0x0d, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
-	0x24, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x49, 0x6e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x06, 0x68,
-	0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x6c, 0x61,
-	0x67, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x12,
-	0x12, 0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x6d,
-	0x6f, 0x64, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x75, 0x6d, 0x61, 0x73, 0x6b, 0x18, 0x05, 0x20, 0x01,
-	0x28, 0x0d, 0x52, 0x05, 0x75, 0x6d, 0x61, 0x73, 0x6b, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x64,
-	0x64, 0x69, 0x6e, 0x67, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x70, 0x61, 0x64, 0x64,
-	0x69, 0x6e, 0x67, 0x22, 0x87, 0x01, 0x0a, 0x0e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65,
+	0x52, 0x04, 0x61, 0x74, 0x74, 0x72, 0x22, 0x39, 0x0a, 0x13, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65,
+	0x58, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a,
+	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e,
+	0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x22, 0xa3, 0x01, 0x0a, 0x0d, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x24, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x49, 0x6e, 0x48, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a,
+	0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x66, 0x6c,
+	0x61, 0x67, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x75, 0x6d, 0x61, 0x73, 0x6b,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x75, 0x6d, 0x61, 0x73, 0x6b, 0x12, 0x18, 0x0a,
+	0x07, 0x70, 0x61, 0x64, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07,
+	0x70, 0x61, 0x64, 0x64, 0x69, 0x6e, 0x67, 0x22, 0x87, 0x01, 0x0a, 0x0e, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x29,
+	0x0a, 0x09, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x5f, 0x6f, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x4f, 0x75, 0x74, 0x52,
+	0x08, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x4f, 0x75, 0x74, 0x12, 0x26, 0x0a, 0x08, 0x6f, 0x70, 0x65,
+	0x6e, 0x5f, 0x6f, 0x75, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x70, 0x62,
+	0x2e, 0x4f, 0x70, 0x65, 0x6e, 0x4f, 0x75, 0x74, 0x52, 0x07, 0x6f, 0x70, 0x65, 0x6e, 0x4f, 0x75,
+	0x74, 0x22, 0x32, 0x0a, 0x0b, 0x4f, 0x70, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x23, 0x0a, 0x07, 0x6f, 0x70, 0x65, 0x6e, 0x5f, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x4f, 0x70, 0x65, 0x6e, 0x49, 0x6e, 0x52, 0x06, 0x6f,
+	0x70, 0x65, 0x6e, 0x49, 0x6e, 0x22, 0x9a, 0x01, 0x0a, 0x0c, 0x4f, 0x70, 0x65, 0x6e, 0x52, 0x65,
 	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
 	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74,
-	0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x29, 0x0a, 0x09, 0x65, 0x6e,
-	0x74, 0x72, 0x79, 0x5f, 0x6f, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e,
-	0x70, 0x62, 0x2e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x4f, 0x75, 0x74, 0x52, 0x08, 0x65, 0x6e, 0x74,
-	0x72, 0x79, 0x4f, 0x75, 0x74, 0x12, 0x26, 0x0a, 0x08, 0x6f, 0x70, 0x65, 0x6e, 0x5f, 0x6f, 0x75,
-	0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x70, 0x62, 0x2e, 0x4f, 0x70, 0x65,
-	0x6e, 0x4f, 0x75, 0x74, 0x52, 0x07, 0x6f, 0x70, 0x65, 0x6e, 0x4f, 0x75, 0x74, 0x22, 0x32, 0x0a,
-	0x0b, 0x4f, 0x70, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x07,
-	0x6f, 0x70, 0x65, 0x6e, 0x5f, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e,
-	0x70, 0x62, 0x2e, 0x4f, 0x70, 0x65, 0x6e, 0x49, 0x6e, 0x52, 0x06, 0x6f, 0x70, 0x65, 0x6e, 0x49,
-	0x6e, 0x22, 0x5a, 0x0a, 0x0c, 0x4f, 0x70, 0x65, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x12, 0x22, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73,
-	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x26, 0x0a, 0x08, 0x6f, 0x70, 0x65, 0x6e, 0x5f, 0x6f, 0x75,
-	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x70, 0x62, 0x2e, 0x4f, 0x70, 0x65,
-	0x6e, 0x4f, 0x75, 0x74, 0x52, 0x07, 0x6f, 0x70, 0x65, 0x6e, 0x4f, 0x75, 0x74, 0x22, 0x32, 0x0a,
-	0x0b, 0x52, 0x65, 0x61, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x07,
-	0x72, 0x65, 0x61, 0x64, 0x5f, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e,
-	0x70, 0x62, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x49, 0x6e, 0x52, 0x06, 0x72, 0x65, 0x61, 0x64, 0x49,
-	0x6e, 0x22, 0x4a, 0x0a, 0x0c, 0x52, 0x65, 0x61, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x12, 0x22, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73,
-	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x75, 0x66, 0x66, 0x65, 0x72, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x62, 0x75, 0x66, 0x66, 0x65, 0x72, 0x22, 0x8e, 0x01,
-	0x0a, 0x0c, 0x4c, 0x73, 0x65, 0x65, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x24,
+	0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x26, 0x0a, 0x08, 0x6f, 0x70,
+	0x65, 0x6e, 0x5f, 0x6f, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x70,
+	0x62, 0x2e, 0x4f, 0x70, 0x65, 0x6e, 0x4f, 0x75, 0x74, 0x52, 0x07, 0x6f, 0x70, 0x65, 0x6e, 0x4f,
+	0x75, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x69, 0x6e, 0x6c, 0x69, 0x6e, 0x65, 0x5f, 0x64, 0x61, 0x74,
+	0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x69, 0x6e, 0x6c, 0x69, 0x6e, 0x65, 0x44,
+	0x61, 0x74, 0x61, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x6e, 0x6c, 0x69, 0x6e, 0x65, 0x5f, 0x65, 0x6f,
+	0x66, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x69, 0x6e, 0x6c, 0x69, 0x6e, 0x65, 0x45,
+	0x6f, 0x66, 0x22, 0x32, 0x0a, 0x0b, 0x52, 0x65, 0x61, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x23, 0x0a, 0x07, 0x72, 0x65, 0x61, 0x64, 0x5f, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x49, 0x6e, 0x52, 0x06,
+	0x72, 0x65, 0x61, 0x64, 0x49, 0x6e, 0x22, 0x4a, 0x0a, 0x0c, 0x52, 0x65, 0x61, 0x64, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x75,
+	0x66, 0x66, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x62, 0x75, 0x66, 0x66,
+	0x65, 0x72, 0x22, 0x8e, 0x01, 0x0a, 0x0c, 0x4c, 0x73, 0x65, 0x65, 0x6b, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x24, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x49, 0x6e, 0x48, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x0e, 0x0a, 0x02, 0x66, 0x68, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x66, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66,
+	0x73, 0x65, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65,
+	0x74, 0x12, 0x16, 0x0a, 0x06, 0x77, 0x68, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x06, 0x77, 0x68, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x64,
+	0x64, 0x69, 0x6e, 0x67, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x70, 0x61, 0x64, 0x64,
+	0x69, 0x6e, 0x67, 0x22, 0x4b, 0x0a, 0x0d, 0x4c, 0x73, 0x65, 0x65, 0x6b, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73,
+	0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74,
+	0x22, 0xaa, 0x01, 0x0a, 0x09, 0x4c, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x24,
 	0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c,
 	0x2e, 0x70, 0x62, 0x2e, 0x49, 0x6e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x06, 0x68, 0x65,
 	0x61, 0x64, 0x65, 0x72, 0x12, 0x0e, 0x0a, 0x02, 0x66, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04,
-	0x52, 0x02, 0x66, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x16, 0x0a, 0x06,
-	0x77, 0x68, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x77, 0x68,
-	0x65, 0x6e, 0x63, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x64, 0x64, 0x69, 0x6e, 0x67, 0x18,
-	0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x70, 0x61, 0x64, 0x64, 0x69, 0x6e, 0x67, 0x22, 0x4b,
-	0x0a, 0x0d, 0x4c, 0x73, 0x65, 0x65, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
-	0x22, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61,
-	0x74, 0x75, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x04, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x22, 0xaa, 0x01, 0x0a, 0x09,
-	0x4c, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x24, 0x0a, 0x06, 0x68, 0x65, 0x61,
+	0x52, 0x02, 0x66, 0x68, 0x12, 0x14, 0x0a, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x12, 0x1c, 0x0a, 0x02, 0x6c, 0x6b,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x46, 0x69, 0x6c, 0x65,
+	0x4c, 0x6f, 0x63, 0x6b, 0x52, 0x02, 0x6c, 0x6b, 0x12, 0x19, 0x0a, 0x08, 0x6c, 0x6b, 0x5f, 0x66,
+	0x6c, 0x61, 0x67, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x6c, 0x6b, 0x46, 0x6c,
+	0x61, 0x67, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x64, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x70, 0x61, 0x64, 0x64, 0x69, 0x6e, 0x67, 0x22, 0x51, 0x0a,
+	0x0d, 0x47, 0x65, 0x74, 0x4c, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22,
+	0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a,
+	0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x12, 0x1c, 0x0a, 0x02, 0x6c, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c,
+	0x2e, 0x70, 0x62, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x4c, 0x6f, 0x63, 0x6b, 0x52, 0x02, 0x6c, 0x6b,
+	0x22, 0x33, 0x0a, 0x0d, 0x53, 0x65, 0x74, 0x4c, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x22, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0xa0, 0x01, 0x0a, 0x0e, 0x52, 0x65, 0x6c, 0x65, 0x61, 0x73,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x24, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64,
+	0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x49, 0x6e,
+	0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x0e,
+	0x0a, 0x02, 0x66, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x66, 0x68, 0x12, 0x14,
+	0x0a, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x66,
+	0x6c, 0x61, 0x67, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x5f,
+	0x66, 0x6c, 0x61, 0x67, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x72, 0x65, 0x6c,
+	0x65, 0x61, 0x73, 0x65, 0x46, 0x6c, 0x61, 0x67, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x6f, 0x63,
+	0x6b, 0x5f, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x6c,
+	0x6f, 0x63, 0x6b, 0x4f, 0x77, 0x6e, 0x65, 0x72, 0x22, 0xf4, 0x01, 0x0a, 0x0c, 0x57, 0x72, 0x69,
+	0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x24, 0x0a, 0x06, 0x68, 0x65, 0x61,
 	0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x49,
 	0x6e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12,
 	0x0e, 0x0a, 0x02, 0x66, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x66, 0x68, 0x12,
-	0x14, 0x0a, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05,
-	0x6f, 0x77, 0x6e, 0x65, 0x72, 0x12, 0x1c, 0x0a, 0x02, 0x6c, 0x6b, 0x18, 0x04, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x4c, 0x6f, 0x63, 0x6b, 0x52,
-	0x02, 0x6c, 0x6b, 0x12, 0x19, 0x0a, 0x08, 0x6c, 0x6b, 0x5f, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x18,
-	0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x6c, 0x6b, 0x46, 0x6c, 0x61, 0x67, 0x73, 0x12, 0x18,
-	0x0a, 0x07, 0x70, 0x61, 0x64, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52,
-	0x07, 0x70, 0x61, 0x64, 0x64, 0x69, 0x6e, 0x67, 0x22, 0x51, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x4c,
-	0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a, 0x06, 0x73, 0x74, 0x61,
-	0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x53,
-	0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1c, 0x0a,
-	0x02, 0x6c, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x46,
-	0x69, 0x6c, 0x65, 0x4c, 0x6f, 0x63, 0x6b, 0x52, 0x02, 0x6c, 0x6b, 0x22, 0x33, 0x0a, 0x0d, 0x53,
-	0x65, 0x74, 0x4c, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a, 0x06,
-	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70,
-	0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
-	0x22, 0xa0, 0x01, 0x0a, 0x0e, 0x52, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x12, 0x24, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x49, 0x6e, 0x48, 0x65, 0x61, 0x64, 0x65,
-	0x72, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x0e, 0x0a, 0x02, 0x66, 0x68, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x66, 0x68, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x6c, 0x61,
-	0x67, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x12,
-	0x23, 0x0a, 0x0d, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x5f, 0x66, 0x6c, 0x61, 0x67, 0x73,
-	0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x46,
-	0x6c, 0x61, 0x67, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x6f, 0x77, 0x6e,
-	0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x6c, 0x6f, 0x63, 0x6b, 0x4f, 0x77,
-	0x6e, 0x65, 0x72, 0x22, 0xf4, 0x01, 0x0a, 0x0c, 0x57, 0x72, 0x69, 0x74, 0x65, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x12, 0x24, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x49, 0x6e, 0x48, 0x65, 0x61, 0x64,
-	0x65, 0x72, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x0e, 0x0a, 0x02, 0x66, 0x68,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x66, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66,
-	0x66, 0x73, 0x65, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73,
-	0x65, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c,
-	0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x05,
-	0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x77, 0x72,
-	0x69, 0x74, 0x65, 0x5f, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52,
-	0x0a, 0x77, 0x72, 0x69, 0x74, 0x65, 0x46, 0x6c, 0x61, 0x67, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x6c,
-	0x6f, 0x63, 0x6b, 0x5f, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x52,
-	0x09, 0x6c, 0x6f, 0x63, 0x6b, 0x4f, 0x77, 0x6e, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x6c,
-	0x61, 0x67, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73,
-	0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x64, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x09, 0x20, 0x01, 0x28,
-	0x0d, 0x52, 0x07, 0x70, 0x61, 0x64, 0x64, 0x69, 0x6e, 0x67, 0x22, 0x4d, 0x0a, 0x0d, 0x57, 0x72,
-	0x69, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a, 0x06, 0x73,
-	0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62,
-	0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12,
-	0x18, 0x0a, 0x07, 0x77, 0x72, 0x69, 0x74, 0x74, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d,
-	0x52, 0x07, 0x77, 0x72, 0x69, 0x74, 0x74, 0x65, 0x6e, 0x22, 0xe0, 0x01, 0x0a, 0x14, 0x43, 0x6f,
-	0x70, 0x79, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x12, 0x24, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x49, 0x6e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72,
-	0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x13, 0x0a, 0x05, 0x66, 0x68, 0x5f, 0x69,
-	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x66, 0x68, 0x49, 0x6e, 0x12, 0x15, 0x0a,
-	0x06, 0x6f, 0x66, 0x66, 0x5f, 0x69, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x6f,
-	0x66, 0x66, 0x49, 0x6e, 0x12, 0x1e, 0x0a, 0x0b, 0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x69, 0x64, 0x5f,
-	0x6f, 0x75, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x6e, 0x6f, 0x64, 0x65, 0x49,
-	0x64, 0x4f, 0x75, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x66, 0x68, 0x5f, 0x6f, 0x75, 0x74, 0x18, 0x05,
-	0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x66, 0x68, 0x4f, 0x75, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x6f,
-	0x66, 0x66, 0x5f, 0x6f, 0x75, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x6f, 0x66,
-	0x66, 0x4f, 0x75, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6c, 0x65, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28,
-	0x04, 0x52, 0x03, 0x6c, 0x65, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x18,
-	0x08, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x22, 0x55, 0x0a, 0x15,
-	0x43, 0x6f, 0x70, 0x79, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75,
-	0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x77, 0x72, 0x69,
-	0x74, 0x74, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x77, 0x72, 0x69, 0x74,
-	0x74, 0x65, 0x6e, 0x22, 0x95, 0x01, 0x0a, 0x0c, 0x46, 0x6c, 0x75, 0x73, 0x68, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x12, 0x24, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x49, 0x6e, 0x48, 0x65, 0x61, 0x64,
-	0x65, 0x72, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x0e, 0x0a, 0x02, 0x66, 0x68,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x66, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x75, 0x6e,
-	0x75, 0x73, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x75, 0x6e, 0x75, 0x73,
-	0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x64, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x04, 0x20,
-	0x01, 0x28, 0x0d, 0x52, 0x07, 0x70, 0x61, 0x64, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x1d, 0x0a, 0x0a,
-	0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04,
-	0x52, 0x09, 0x6c, 0x6f, 0x63, 0x6b, 0x4f, 0x77, 0x6e, 0x65, 0x72, 0x22, 0x33, 0x0a, 0x0d, 0x46,
-	0x6c, 0x75, 0x73, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a, 0x06,
-	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70,
-	0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
-	0x22, 0x7f, 0x0a, 0x0c, 0x46, 0x73, 0x79, 0x6e, 0x63, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x12, 0x0a, 0x04, 0x73,
+	0x69, 0x7a, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12,
+	0x1f, 0x0a, 0x0b, 0x77, 0x72, 0x69, 0x74, 0x65, 0x5f, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x77, 0x72, 0x69, 0x74, 0x65, 0x46, 0x6c, 0x61, 0x67, 0x73,
+	0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x6c, 0x6f, 0x63, 0x6b, 0x4f, 0x77, 0x6e, 0x65, 0x72, 0x12,
+	0x14, 0x0a, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05,
+	0x66, 0x6c, 0x61, 0x67, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x64, 0x64, 0x69, 0x6e, 0x67,
+	0x18, 0x09, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x70, 0x61, 0x64, 0x64, 0x69, 0x6e, 0x67, 0x22,
+	0x4d, 0x0a, 0x0d, 0x57, 0x72, 0x69, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x22, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x77, 0x72, 0x69, 0x74, 0x74, 0x65, 0x6e, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x77, 0x72, 0x69, 0x74, 0x74, 0x65, 0x6e, 0x22, 0xe0,
+	0x01, 0x0a, 0x14, 0x43, 0x6f, 0x70, 0x79, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x61, 0x6e, 0x67, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x24, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x49, 0x6e, 0x48,
+	0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x13, 0x0a,
+	0x05, 0x66, 0x68, 0x5f, 0x69, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x66, 0x68,
+	0x49, 0x6e, 0x12, 0x15, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x5f, 0x69, 0x6e, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x05, 0x6f, 0x66, 0x66, 0x49, 0x6e, 0x12, 0x1e, 0x0a, 0x0b, 0x6e, 0x6f, 0x64,
+	0x65, 0x5f, 0x69, 0x64, 0x5f, 0x6f, 0x75, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09,
+	0x6e, 0x6f, 0x64, 0x65, 0x49, 0x64, 0x4f, 0x75, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x66, 0x68, 0x5f,
+	0x6f, 0x75, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x66, 0x68, 0x4f, 0x75, 0x74,
+	0x12, 0x17, 0x0a, 0x07, 0x6f, 0x66, 0x66, 0x5f, 0x6f, 0x75, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x4f, 0x75, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6c, 0x65, 0x6e,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x6c, 0x65, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x66,
+	0x6c, 0x61, 0x67, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x66, 0x6c, 0x61, 0x67,
+	0x73, 0x22, 0x55, 0x0a, 0x15, 0x43, 0x6f, 0x70, 0x79, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x61, 0x6e,
+	0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x18,
+	0x0a, 0x07, 0x77, 0x72, 0x69, 0x74, 0x74, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x07, 0x77, 0x72, 0x69, 0x74, 0x74, 0x65, 0x6e, 0x22, 0x95, 0x01, 0x0a, 0x0c, 0x46, 0x6c, 0x75,
+	0x73, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x24, 0x0a, 0x06, 0x68, 0x65, 0x61,
+	0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x49,
+	0x6e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12,
+	0x0e, 0x0a, 0x02, 0x66, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x66, 0x68, 0x12,
+	0x16, 0x0a, 0x06, 0x75, 0x6e, 0x75, 0x73, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x06, 0x75, 0x6e, 0x75, 0x73, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x64, 0x64, 0x69,
+	0x6e, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x70, 0x61, 0x64, 0x64, 0x69, 0x6e,
+	0x67, 0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x6c, 0x6f, 0x63, 0x6b, 0x4f, 0x77, 0x6e, 0x65, 0x72,
+	0x22, 0x33, 0x0a, 0x0d, 0x46, 0x6c, 0x75, 0x73, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x22, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x7f, 0x0a, 0x0c, 0x46, 0x73, 0x79, 0x6e, 0x63, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x24, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x49, 0x6e, 0x48, 0x65, 0x61,
+	0x64, 0x65, 0x72, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x0e, 0x0a, 0x02, 0x66,
+	0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x66, 0x68, 0x12, 0x1f, 0x0a, 0x0b, 0x66,
+	0x73, 0x79, 0x6e, 0x63, 0x5f, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x0a, 0x66, 0x73, 0x79, 0x6e, 0x63, 0x46, 0x6c, 0x61, 0x67, 0x73, 0x12, 0x18, 0x0a, 0x07,
+	0x70, 0x61, 0x64, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x70,
+	0x61, 0x64, 0x64, 0x69, 0x6e, 0x67, 0x22, 0x33, 0x0a, 0x0d, 0x46, 0x73, 0x79, 0x6e, 0x63, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0xa6, 0x01, 0x0a, 0x10,
+	0x46, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
 	0x12, 0x24, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
 	0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x49, 0x6e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x06,
 	0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x0e, 0x0a, 0x02, 0x66, 0x68, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x04, 0x52, 0x02, 0x66, 0x68, 0x12, 0x1f, 0x0a, 0x0b, 0x66, 0x73, 0x79, 0x6e, 0x63, 0x5f,
-	0x66, 0x6c, 0x61, 0x67, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x66, 0x73, 0x79,
-	0x6e, 0x63, 0x46, 0x6c, 0x61, 0x67, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x64, 0x64, 0x69,
-	0x6e, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x70, 0x61, 0x64, 0x64, 0x69, 0x6e,
-	0x67, 0x22, 0x33, 0x0a, 0x0d, 0x46, 0x73, 0x79, 0x6e, 0x63, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x12, 0x22, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06,
-	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0xa6, 0x01, 0x0a, 0x10, 0x46, 0x61, 0x6c, 0x6c, 0x6f,
-	0x63, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x24, 0x0a, 0x06, 0x68,
-	0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62,
-	0x2e, 0x49, 0x6e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65,
-	0x72, 0x12, 0x0e, 0x0a, 0x02, 0x66, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x66,
-	0x68, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x04, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x65, 0x6e,
-	0x67, 0x74, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x6c, 0x65, 0x6e, 0x67, 0x74,
-	0x68, 0x12, 0x12, 0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52,
-	0x04, 0x6d, 0x6f, 0x64, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x64, 0x64, 0x69, 0x6e, 0x67,
-	0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x70, 0x61, 0x64, 0x64, 0x69, 0x6e, 0x67, 0x22,
-	0x37, 0x0a, 0x11, 0x46, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
-	0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x35, 0x0a, 0x0e, 0x4f, 0x70, 0x65, 0x6e,
-	0x44, 0x69, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x07, 0x6f, 0x70,
-	0x65, 0x6e, 0x5f, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62,
-	0x2e, 0x4f, 0x70, 0x65, 0x6e, 0x49, 0x6e, 0x52, 0x06, 0x6f, 0x70, 0x65, 0x6e, 0x49, 0x6e, 0x22,
-	0x5d, 0x0a, 0x0f, 0x4f, 0x70, 0x65, 0x6e, 0x44, 0x69, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x12, 0x22, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06,
-	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x26, 0x0a, 0x08, 0x6f, 0x70, 0x65, 0x6e, 0x5f, 0x6f,
-	0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x70, 0x62, 0x2e, 0x4f, 0x70,
-	0x65, 0x6e, 0x4f, 0x75, 0x74, 0x52, 0x07, 0x6f, 0x70, 0x65, 0x6e, 0x4f, 0x75, 0x74, 0x22, 0x35,
-	0x0a, 0x0e, 0x52, 0x65, 0x61, 0x64, 0x44, 0x69, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x12, 0x23, 0x0a, 0x07, 0x72, 0x65, 0x61, 0x64, 0x5f, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x49, 0x6e, 0x52, 0x06, 0x72,
-	0x65, 0x61, 0x64, 0x49, 0x6e, 0x22, 0x5d, 0x0a, 0x0f, 0x52, 0x65, 0x61, 0x64, 0x44, 0x69, 0x72,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74,
-	0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74,
-	0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x26, 0x0a, 0x07,
-	0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e,
-	0x70, 0x62, 0x2e, 0x44, 0x69, 0x72, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x65, 0x6e, 0x74,
-	0x72, 0x69, 0x65, 0x73, 0x22, 0x33, 0x0a, 0x0d, 0x53, 0x74, 0x61, 0x74, 0x66, 0x73, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x22, 0x0a, 0x05, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x49, 0x6e, 0x48, 0x65, 0x61, 0x64,
-	0x65, 0x72, 0x52, 0x05, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x22, 0x9e, 0x02, 0x0a, 0x0e, 0x53, 0x74,
-	0x61, 0x74, 0x66, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a, 0x06,
-	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70,
-	0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
-	0x12, 0x16, 0x0a, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04,
-	0x52, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x66, 0x72, 0x65,
-	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x62, 0x66, 0x72, 0x65, 0x65, 0x12, 0x16,
-	0x0a, 0x06, 0x62, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06,
-	0x62, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18,
-	0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05,
-	0x66, 0x66, 0x72, 0x65, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x66, 0x66, 0x72,
-	0x65, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28,
-	0x0d, 0x52, 0x05, 0x62, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6e, 0x61, 0x6d, 0x65,
-	0x4c, 0x65, 0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x6e, 0x61, 0x6d, 0x65, 0x4c,
-	0x65, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x72, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x09, 0x20, 0x01,
-	0x28, 0x0d, 0x52, 0x06, 0x66, 0x72, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61,
-	0x64, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x70, 0x61, 0x64,
-	0x64, 0x69, 0x6e, 0x67, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x70, 0x61, 0x72, 0x65, 0x18, 0x0b, 0x20,
-	0x03, 0x28, 0x0d, 0x52, 0x05, 0x73, 0x70, 0x61, 0x72, 0x65, 0x32, 0xad, 0x0f, 0x0a, 0x0d, 0x52,
-	0x61, 0x77, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x12, 0x31, 0x0a, 0x06,
-	0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x12, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x72, 0x69,
-	0x6e, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x53,
-	0x74, 0x72, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12,
-	0x31, 0x0a, 0x06, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x12, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x4c,
-	0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x70,
-	0x62, 0x2e, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x22, 0x00, 0x12, 0x35, 0x0a, 0x06, 0x46, 0x6f, 0x72, 0x67, 0x65, 0x74, 0x12, 0x11, 0x2e, 0x70,
-	0x62, 0x2e, 0x46, 0x6f, 0x72, 0x67, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
-	0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
-	0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x34, 0x0a, 0x07, 0x47, 0x65, 0x74,
-	0x41, 0x74, 0x74, 0x72, 0x12, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x74, 0x74,
-	0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x70, 0x62, 0x2e, 0x47, 0x65,
-	0x74, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12,
-	0x34, 0x0a, 0x07, 0x53, 0x65, 0x74, 0x41, 0x74, 0x74, 0x72, 0x12, 0x12, 0x2e, 0x70, 0x62, 0x2e,
-	0x53, 0x65, 0x74, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13,
-	0x2e, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x74, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x2e, 0x0a, 0x05, 0x4d, 0x6b, 0x6e, 0x6f, 0x64, 0x12, 0x10,
-	0x2e, 0x70, 0x62, 0x2e, 0x4d, 0x6b, 0x6e, 0x6f, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x4d, 0x6b, 0x6e, 0x6f, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x2e, 0x0a, 0x05, 0x4d, 0x6b, 0x64, 0x69, 0x72, 0x12, 0x10,
-	0x2e, 0x70, 0x62, 0x2e, 0x4d, 0x6b, 0x64, 0x69, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x4d, 0x6b, 0x64, 0x69, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x31, 0x0a, 0x06, 0x55, 0x6e, 0x6c, 0x69, 0x6e, 0x6b, 0x12,
-	0x11, 0x2e, 0x70, 0x62, 0x2e, 0x55, 0x6e, 0x6c, 0x69, 0x6e, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x1a, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x55, 0x6e, 0x6c, 0x69, 0x6e, 0x6b, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x2e, 0x0a, 0x05, 0x52, 0x6d, 0x64, 0x69,
-	0x72, 0x12, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x6d, 0x64, 0x69, 0x72, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x6d, 0x64, 0x69, 0x72, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x31, 0x0a, 0x06, 0x52, 0x65, 0x6e, 0x61,
-	0x6d, 0x65, 0x12, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x6e, 0x61, 0x6d,
-	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x2b, 0x0a, 0x04, 0x4c,
-	0x69, 0x6e, 0x6b, 0x12, 0x0f, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x69, 0x6e, 0x6b, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x69, 0x6e, 0x6b, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x34, 0x0a, 0x07, 0x53, 0x79, 0x6d, 0x6c,
-	0x69, 0x6e, 0x6b, 0x12, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x79, 0x6d, 0x6c, 0x69, 0x6e, 0x6b,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x79, 0x6d,
-	0x6c, 0x69, 0x6e, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x37,
-	0x0a, 0x08, 0x52, 0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x6b, 0x12, 0x13, 0x2e, 0x70, 0x62, 0x2e,
-	0x52, 0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
-	0x14, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x6b, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x31, 0x0a, 0x06, 0x41, 0x63, 0x63, 0x65, 0x73,
-	0x73, 0x12, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x37, 0x0a, 0x08, 0x47, 0x65,
-	0x74, 0x58, 0x41, 0x74, 0x74, 0x72, 0x12, 0x13, 0x2e, 0x70, 0x62, 0x2e, 0x47, 0x65, 0x74, 0x58,
-	0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x70, 0x62,
-	0x2e, 0x47, 0x65, 0x74, 0x58, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x22, 0x00, 0x12, 0x3a, 0x0a, 0x09, 0x4c, 0x69, 0x73, 0x74, 0x58, 0x41, 0x74, 0x74, 0x72,
-	0x12, 0x14, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x58, 0x41, 0x74, 0x74, 0x72, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x69, 0x73, 0x74,
-	0x58, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12,
-	0x37, 0x0a, 0x08, 0x53, 0x65, 0x74, 0x58, 0x41, 0x74, 0x74, 0x72, 0x12, 0x13, 0x2e, 0x70, 0x62,
-	0x2e, 0x53, 0x65, 0x74, 0x58, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x14, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x74, 0x58, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x40, 0x0a, 0x0b, 0x52, 0x65, 0x6d, 0x6f,
-	0x76, 0x65, 0x58, 0x41, 0x74, 0x74, 0x72, 0x12, 0x16, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x6d,
-	0x6f, 0x76, 0x65, 0x58, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
-	0x17, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x58, 0x41, 0x74, 0x74, 0x72,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x31, 0x0a, 0x06, 0x43, 0x72,
-	0x65, 0x61, 0x74, 0x65, 0x12, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x43, 0x72, 0x65,
-	0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x2b, 0x0a,
-	0x04, 0x4f, 0x70, 0x65, 0x6e, 0x12, 0x0f, 0x2e, 0x70, 0x62, 0x2e, 0x4f, 0x70, 0x65, 0x6e, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x4f, 0x70, 0x65, 0x6e,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x2d, 0x0a, 0x04, 0x52, 0x65,
-	0x61, 0x64, 0x12, 0x0f, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x30, 0x01, 0x12, 0x2e, 0x0a, 0x05, 0x4c, 0x73, 0x65,
-	0x65, 0x6b, 0x12, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x73, 0x65, 0x65, 0x6b, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x73, 0x65, 0x65, 0x6b, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x2b, 0x0a, 0x05, 0x47, 0x65, 0x74,
-	0x4c, 0x6b, 0x12, 0x0d, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x47, 0x65, 0x74, 0x4c, 0x6b, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x2b, 0x0a, 0x05, 0x53, 0x65, 0x74, 0x4c, 0x6b, 0x12,
+	0x28, 0x04, 0x52, 0x02, 0x66, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x16,
+	0x0a, 0x06, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06,
+	0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12, 0x12, 0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61,
+	0x64, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x70, 0x61, 0x64,
+	0x64, 0x69, 0x6e, 0x67, 0x22, 0x37, 0x0a, 0x11, 0x46, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x35, 0x0a,
+	0x0e, 0x4f, 0x70, 0x65, 0x6e, 0x44, 0x69, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x23, 0x0a, 0x07, 0x6f, 0x70, 0x65, 0x6e, 0x5f, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x4f, 0x70, 0x65, 0x6e, 0x49, 0x6e, 0x52, 0x06, 0x6f, 0x70,
+	0x65, 0x6e, 0x49, 0x6e, 0x22, 0x5d, 0x0a, 0x0f, 0x4f, 0x70, 0x65, 0x6e, 0x44, 0x69, 0x72, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x26, 0x0a, 0x08, 0x6f,
+	0x70, 0x65, 0x6e, 0x5f, 0x6f, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e,
+	0x70, 0x62, 0x2e, 0x4f, 0x70, 0x65, 0x6e, 0x4f, 0x75, 0x74, 0x52, 0x07, 0x6f, 0x70, 0x65, 0x6e,
+	0x4f, 0x75, 0x74, 0x22, 0x35, 0x0a, 0x0e, 0x52, 0x65, 0x61, 0x64, 0x44, 0x69, 0x72, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x07, 0x72, 0x65, 0x61, 0x64, 0x5f, 0x69, 0x6e,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x61, 0x64,
+	0x49, 0x6e, 0x52, 0x06, 0x72, 0x65, 0x61, 0x64, 0x49, 0x6e, 0x22, 0x5d, 0x0a, 0x0f, 0x52, 0x65,
+	0x61, 0x64, 0x44, 0x69, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a,
+	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e,
+	0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x12, 0x26, 0x0a, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x44, 0x69, 0x72, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x52, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x22, 0x33, 0x0a, 0x0d, 0x53, 0x74, 0x61,
+	0x74, 0x66, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x22, 0x0a, 0x05, 0x69, 0x6e,
+	0x70, 0x75, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x49,
+	0x6e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x05, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x22, 0x9e,
+	0x02, 0x0a, 0x0e, 0x53, 0x74, 0x61, 0x74, 0x66, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x22, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x14, 0x0a,
+	0x05, 0x62, 0x66, 0x72, 0x65, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x62, 0x66,
+	0x72, 0x65, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x06, 0x62, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x66,
+	0x69, 0x6c, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x66, 0x69, 0x6c, 0x65,
+	0x73, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x66, 0x72, 0x65, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x05, 0x66, 0x66, 0x72, 0x65, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x73, 0x69, 0x7a, 0x65,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x62, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x18, 0x0a,
+	0x07, 0x6e, 0x61, 0x6d, 0x65, 0x4c, 0x65, 0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07,
+	0x6e, 0x61, 0x6d, 0x65, 0x4c, 0x65, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x72, 0x73, 0x69, 0x7a,
+	0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x66, 0x72, 0x73, 0x69, 0x7a, 0x65, 0x12,
+	0x18, 0x0a, 0x07, 0x70, 0x61, 0x64, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x07, 0x70, 0x61, 0x64, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x70, 0x61,
+	0x72, 0x65, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x05, 0x73, 0x70, 0x61, 0x72, 0x65, 0x22,
+	0xcf, 0x03, 0x0a, 0x0a, 0x43, 0x6f, 0x6d, 0x70, 0x6f, 0x75, 0x6e, 0x64, 0x4f, 0x70, 0x12, 0x2b,
+	0x0a, 0x06, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11,
+	0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x48, 0x00, 0x52, 0x06, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x12, 0x2f, 0x0a, 0x08, 0x67,
+	0x65, 0x74, 0x5f, 0x61, 0x74, 0x74, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e,
+	0x70, 0x62, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x48, 0x00, 0x52, 0x07, 0x67, 0x65, 0x74, 0x41, 0x74, 0x74, 0x72, 0x12, 0x2b, 0x0a, 0x06,
+	0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x70,
+	0x62, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48,
+	0x00, 0x52, 0x06, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x12, 0x25, 0x0a, 0x04, 0x6f, 0x70, 0x65,
+	0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x62, 0x2e, 0x4f, 0x70, 0x65,
+	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x04, 0x6f, 0x70, 0x65, 0x6e,
+	0x12, 0x25, 0x0a, 0x04, 0x72, 0x65, 0x61, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f,
+	0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48,
+	0x00, 0x52, 0x04, 0x72, 0x65, 0x61, 0x64, 0x12, 0x28, 0x0a, 0x05, 0x77, 0x72, 0x69, 0x74, 0x65,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x57, 0x72, 0x69, 0x74,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x05, 0x77, 0x72, 0x69, 0x74,
+	0x65, 0x12, 0x28, 0x0a, 0x05, 0x66, 0x6c, 0x75, 0x73, 0x68, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x46, 0x6c, 0x75, 0x73, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x48, 0x00, 0x52, 0x05, 0x66, 0x6c, 0x75, 0x73, 0x68, 0x12, 0x2e, 0x0a, 0x07, 0x72,
+	0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x70,
+	0x62, 0x2e, 0x52, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x48, 0x00, 0x52, 0x07, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x12, 0x28, 0x0a, 0x05, 0x66,
+	0x73, 0x79, 0x6e, 0x63, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x70, 0x62, 0x2e,
+	0x46, 0x73, 0x79, 0x6e, 0x63, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x05,
+	0x66, 0x73, 0x79, 0x6e, 0x63, 0x12, 0x1b, 0x0a, 0x09, 0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x66, 0x72,
+	0x6f, 0x6d, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x6e, 0x6f, 0x64, 0x65, 0x46, 0x72,
+	0x6f, 0x6d, 0x12, 0x17, 0x0a, 0x07, 0x66, 0x68, 0x5f, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x0b, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x06, 0x66, 0x68, 0x46, 0x72, 0x6f, 0x6d, 0x42, 0x04, 0x0a, 0x02, 0x6f,
+	0x70, 0x22, 0x33, 0x0a, 0x0f, 0x43, 0x6f, 0x6d, 0x70, 0x6f, 0x75, 0x6e, 0x64, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x20, 0x0a, 0x03, 0x6f, 0x70, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x0e, 0x2e, 0x70, 0x62, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x6f, 0x75, 0x6e, 0x64, 0x4f,
+	0x70, 0x52, 0x03, 0x6f, 0x70, 0x73, 0x22, 0xad, 0x03, 0x0a, 0x0e, 0x43, 0x6f, 0x6d, 0x70, 0x6f,
+	0x75, 0x6e, 0x64, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x2c, 0x0a, 0x06, 0x6c, 0x6f, 0x6f,
+	0x6b, 0x75, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x4c,
+	0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52,
+	0x06, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x12, 0x30, 0x0a, 0x08, 0x67, 0x65, 0x74, 0x5f, 0x61,
+	0x74, 0x74, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x70, 0x62, 0x2e, 0x47,
+	0x65, 0x74, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00,
+	0x52, 0x07, 0x67, 0x65, 0x74, 0x41, 0x74, 0x74, 0x72, 0x12, 0x2c, 0x0a, 0x06, 0x63, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x43,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52,
+	0x06, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x12, 0x26, 0x0a, 0x04, 0x6f, 0x70, 0x65, 0x6e, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x4f, 0x70, 0x65, 0x6e, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x04, 0x6f, 0x70, 0x65, 0x6e, 0x12,
+	0x26, 0x0a, 0x04, 0x72, 0x65, 0x61, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e,
+	0x70, 0x62, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48,
+	0x00, 0x52, 0x04, 0x72, 0x65, 0x61, 0x64, 0x12, 0x29, 0x0a, 0x05, 0x77, 0x72, 0x69, 0x74, 0x65,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x57, 0x72, 0x69, 0x74,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x05, 0x77, 0x72, 0x69,
+	0x74, 0x65, 0x12, 0x29, 0x0a, 0x05, 0x66, 0x6c, 0x75, 0x73, 0x68, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x46, 0x6c, 0x75, 0x73, 0x68, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x05, 0x66, 0x6c, 0x75, 0x73, 0x68, 0x12, 0x32, 0x0a,
+	0x07, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x48, 0x00, 0x52, 0x07, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73,
+	0x65, 0x12, 0x29, 0x0a, 0x05, 0x66, 0x73, 0x79, 0x6e, 0x63, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x46, 0x73, 0x79, 0x6e, 0x63, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x05, 0x66, 0x73, 0x79, 0x6e, 0x63, 0x42, 0x08, 0x0a, 0x06,
+	0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x64, 0x0a, 0x10, 0x43, 0x6f, 0x6d, 0x70, 0x6f, 0x75,
+	0x6e, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x2c,
+	0x0a, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x12, 0x2e, 0x70, 0x62, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x6f, 0x75, 0x6e, 0x64, 0x52, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x52, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x32, 0xe6, 0x0f, 0x0a,
+	0x0d, 0x52, 0x61, 0x77, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x12, 0x31,
+	0x0a, 0x06, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x12, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74,
+	0x72, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x70, 0x62,
+	0x2e, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x00, 0x12, 0x31, 0x0a, 0x06, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x12, 0x11, 0x2e, 0x70, 0x62,
+	0x2e, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12,
+	0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x00, 0x12, 0x35, 0x0a, 0x06, 0x46, 0x6f, 0x72, 0x67, 0x65, 0x74, 0x12, 0x11,
+	0x2e, 0x70, 0x62, 0x2e, 0x46, 0x6f, 0x72, 0x67, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x34, 0x0a, 0x07, 0x47,
+	0x65, 0x74, 0x41, 0x74, 0x74, 0x72, 0x12, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x47, 0x65, 0x74, 0x41,
+	0x74, 0x74, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x70, 0x62, 0x2e,
+	0x47, 0x65, 0x74, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x00, 0x12, 0x34, 0x0a, 0x07, 0x53, 0x65, 0x74, 0x41, 0x74, 0x74, 0x72, 0x12, 0x12, 0x2e, 0x70,
+	0x62, 0x2e, 0x53, 0x65, 0x74, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x13, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x74, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x2e, 0x0a, 0x05, 0x4d, 0x6b, 0x6e, 0x6f, 0x64,
+	0x12, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x4d, 0x6b, 0x6e, 0x6f, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x4d, 0x6b, 0x6e, 0x6f, 0x64, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x2e, 0x0a, 0x05, 0x4d, 0x6b, 0x64, 0x69, 0x72,
+	0x12, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x4d, 0x6b, 0x64, 0x69, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x4d, 0x6b, 0x64, 0x69, 0x72, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x31, 0x0a, 0x06, 0x55, 0x6e, 0x6c, 0x69, 0x6e,
+	0x6b, 0x12, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x55, 0x6e, 0x6c, 0x69, 0x6e, 0x6b, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x55, 0x6e, 0x6c, 0x69, 0x6e, 0x6b,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x2e, 0x0a, 0x05, 0x52, 0x6d,
+	0x64, 0x69, 0x72, 0x12, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x6d, 0x64, 0x69, 0x72, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x6d, 0x64, 0x69, 0x72,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x31, 0x0a, 0x06, 0x52, 0x65,
+	0x6e, 0x61, 0x6d, 0x65, 0x12, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x6e, 0x61, 0x6d, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x6e,
+	0x61, 0x6d, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x2b, 0x0a,
+	0x04, 0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x0f, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x69, 0x6e, 0x6b, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x69, 0x6e, 0x6b,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x34, 0x0a, 0x07, 0x53, 0x79,
+	0x6d, 0x6c, 0x69, 0x6e, 0x6b, 0x12, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x79, 0x6d, 0x6c, 0x69,
+	0x6e, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x70, 0x62, 0x2e, 0x53,
+	0x79, 0x6d, 0x6c, 0x69, 0x6e, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00,
+	0x12, 0x37, 0x0a, 0x08, 0x52, 0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x6b, 0x12, 0x13, 0x2e, 0x70,
+	0x62, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x14, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x6b, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x31, 0x0a, 0x06, 0x41, 0x63, 0x63,
+	0x65, 0x73, 0x73, 0x12, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x41, 0x63, 0x63, 0x65,
+	0x73, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x37, 0x0a, 0x08,
+	0x47, 0x65, 0x74, 0x58, 0x41, 0x74, 0x74, 0x72, 0x12, 0x13, 0x2e, 0x70, 0x62, 0x2e, 0x47, 0x65,
+	0x74, 0x58, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e,
+	0x70, 0x62, 0x2e, 0x47, 0x65, 0x74, 0x58, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x3a, 0x0a, 0x09, 0x4c, 0x69, 0x73, 0x74, 0x58, 0x41, 0x74,
+	0x74, 0x72, 0x12, 0x14, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x58, 0x41, 0x74, 0x74,
+	0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x69,
+	0x73, 0x74, 0x58, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x00, 0x12, 0x37, 0x0a, 0x08, 0x53, 0x65, 0x74, 0x58, 0x41, 0x74, 0x74, 0x72, 0x12, 0x13, 0x2e,
+	0x70, 0x62, 0x2e, 0x53, 0x65, 0x74, 0x58, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x14, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x74, 0x58, 0x41, 0x74, 0x74, 0x72,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x40, 0x0a, 0x0b, 0x52, 0x65,
+	0x6d, 0x6f, 0x76, 0x65, 0x58, 0x41, 0x74, 0x74, 0x72, 0x12, 0x16, 0x2e, 0x70, 0x62, 0x2e, 0x52,
+	0x65, 0x6d, 0x6f, 0x76, 0x65, 0x58, 0x41, 0x74, 0x74, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x17, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x58, 0x41, 0x74,
+	0x74, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x31, 0x0a, 0x06,
+	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x12, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x43,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12,
+	0x2b, 0x0a, 0x04, 0x4f, 0x70, 0x65, 0x6e, 0x12, 0x0f, 0x2e, 0x70, 0x62, 0x2e, 0x4f, 0x70, 0x65,
+	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x4f, 0x70,
+	0x65, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x2d, 0x0a, 0x04,
+	0x52, 0x65, 0x61, 0x64, 0x12, 0x0f, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x30, 0x01, 0x12, 0x2e, 0x0a, 0x05, 0x4c,
+	0x73, 0x65, 0x65, 0x6b, 0x12, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x73, 0x65, 0x65, 0x6b, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x73, 0x65, 0x65,
+	0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x2b, 0x0a, 0x05, 0x47,
+	0x65, 0x74, 0x4c, 0x6b, 0x12, 0x0d, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x6b, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x47, 0x65, 0x74, 0x4c, 0x6b, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x2b, 0x0a, 0x05, 0x53, 0x65, 0x74, 0x4c,
+	0x6b, 0x12, 0x0d, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x74, 0x4c, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x2c, 0x0a, 0x06, 0x53, 0x65, 0x74, 0x4c, 0x6b, 0x77, 0x12,
 	0x0d, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11,
 	0x2e, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x74, 0x4c, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x22, 0x00, 0x12, 0x2c, 0x0a, 0x06, 0x53, 0x65, 0x74, 0x4c, 0x6b, 0x77, 0x12, 0x0d, 0x2e,
-	0x70, 0x62, 0x2e, 0x4c, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x70,
-	0x62, 0x2e, 0x53, 0x65, 0x74, 0x4c, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
-	0x00, 0x12, 0x37, 0x0a, 0x07, 0x52, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x12, 0x12, 0x2e, 0x70,
-	0x62, 0x2e, 0x52, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
-	0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x2e, 0x0a, 0x05, 0x57, 0x72,
-	0x69, 0x74, 0x65, 0x12, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x57, 0x72, 0x69, 0x74, 0x65, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x57, 0x72, 0x69, 0x74, 0x65,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x46, 0x0a, 0x0d, 0x43, 0x6f,
-	0x70, 0x79, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x18, 0x2e, 0x70, 0x62,
-	0x2e, 0x43, 0x6f, 0x70, 0x79, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x70, 0x62, 0x2e, 0x43, 0x6f, 0x70, 0x79, 0x46,
-	0x69, 0x6c, 0x65, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x22, 0x00, 0x12, 0x2e, 0x0a, 0x05, 0x46, 0x6c, 0x75, 0x73, 0x68, 0x12, 0x10, 0x2e, 0x70, 0x62,
-	0x2e, 0x46, 0x6c, 0x75, 0x73, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e,
-	0x70, 0x62, 0x2e, 0x46, 0x6c, 0x75, 0x73, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x22, 0x00, 0x12, 0x2e, 0x0a, 0x05, 0x46, 0x73, 0x79, 0x6e, 0x63, 0x12, 0x10, 0x2e, 0x70, 0x62,
-	0x2e, 0x46, 0x73, 0x79, 0x6e, 0x63, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e,
-	0x70, 0x62, 0x2e, 0x46, 0x73, 0x79, 0x6e, 0x63, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x22, 0x00, 0x12, 0x3a, 0x0a, 0x09, 0x46, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65, 0x12,
-	0x14, 0x2e, 0x70, 0x62, 0x2e, 0x46, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x70, 0x62, 0x2e, 0x46, 0x61, 0x6c, 0x6c, 0x6f,
-	0x63, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x34,
-	0x0a, 0x07, 0x4f, 0x70, 0x65, 0x6e, 0x44, 0x69, 0x72, 0x12, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x4f,
-	0x70, 0x65, 0x6e, 0x44, 0x69, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e,
-	0x70, 0x62, 0x2e, 0x4f, 0x70, 0x65, 0x6e, 0x44, 0x69, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x22, 0x00, 0x12, 0x36, 0x0a, 0x07, 0x52, 0x65, 0x61, 0x64, 0x44, 0x69, 0x72, 0x12,
-	0x12, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x44, 0x69, 0x72, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x44, 0x69, 0x72,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x30, 0x01, 0x12, 0x3a, 0x0a, 0x0b,
-	0x52, 0x65, 0x61, 0x64, 0x44, 0x69, 0x72, 0x50, 0x6c, 0x75, 0x73, 0x12, 0x12, 0x2e, 0x70, 0x62,
-	0x2e, 0x52, 0x65, 0x61, 0x64, 0x44, 0x69, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
-	0x13, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x44, 0x69, 0x72, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x30, 0x01, 0x12, 0x3a, 0x0a, 0x0a, 0x52, 0x65, 0x6c, 0x65,
-	0x61, 0x73, 0x65, 0x44, 0x69, 0x72, 0x12, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x6c, 0x65,
-	0x61, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f,
-	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70,
-	0x74, 0x79, 0x22, 0x00, 0x12, 0x31, 0x0a, 0x08, 0x46, 0x73, 0x79, 0x6e, 0x63, 0x44, 0x69, 0x72,
-	0x12, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x46, 0x73, 0x79, 0x6e, 0x63, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x1a, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x46, 0x73, 0x79, 0x6e, 0x63, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x31, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x46,
-	0x73, 0x12, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x66, 0x73, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x66, 0x73,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x24, 0x5a, 0x22, 0x67, 0x69,
-	0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x63, 0x68, 0x69, 0x79, 0x75, 0x74, 0x69,
-	0x61, 0x6e, 0x79, 0x69, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x66, 0x75, 0x73, 0x65, 0x2f, 0x70, 0x62,
-	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x65, 0x22, 0x00, 0x12, 0x37, 0x0a, 0x07, 0x52, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x12, 0x12,
+	0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x2e, 0x0a, 0x05,
+	0x57, 0x72, 0x69, 0x74, 0x65, 0x12, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x57, 0x72, 0x69, 0x74, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x57, 0x72, 0x69,
+	0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x46, 0x0a, 0x0d,
+	0x43, 0x6f, 0x70, 0x79, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x18, 0x2e,
+	0x70, 0x62, 0x2e, 0x43, 0x6f, 0x70, 0x79, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x61, 0x6e, 0x67, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x70, 0x62, 0x2e, 0x43, 0x6f, 0x70,
+	0x79, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x00, 0x12, 0x2e, 0x0a, 0x05, 0x46, 0x6c, 0x75, 0x73, 0x68, 0x12, 0x10, 0x2e,
+	0x70, 0x62, 0x2e, 0x46, 0x6c, 0x75, 0x73, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x11, 0x2e, 0x70, 0x62, 0x2e, 0x46, 0x6c, 0x75, 0x73, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x00, 0x12, 0x2e, 0x0a, 0x05, 0x46, 0x73, 0x79, 0x6e, 0x63, 0x12, 0x10, 0x2e,
+	0x70, 0x62, 0x2e, 0x46, 0x73, 0x79, 0x6e, 0x63, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x11, 0x2e, 0x70, 0x62, 0x2e, 0x46, 0x73, 0x79, 0x6e, 0x63, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x00, 0x12, 0x3a, 0x0a, 0x09, 0x46, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74,
+	0x65, 0x12, 0x14, 0x2e, 0x70, 0x62, 0x2e, 0x46, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x70, 0x62, 0x2e, 0x46, 0x61, 0x6c,
+	0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00,
+	0x12, 0x34, 0x0a, 0x07, 0x4f, 0x70, 0x65, 0x6e, 0x44, 0x69, 0x72, 0x12, 0x12, 0x2e, 0x70, 0x62,
+	0x2e, 0x4f, 0x70, 0x65, 0x6e, 0x44, 0x69, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x13, 0x2e, 0x70, 0x62, 0x2e, 0x4f, 0x70, 0x65, 0x6e, 0x44, 0x69, 0x72, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x36, 0x0a, 0x07, 0x52, 0x65, 0x61, 0x64, 0x44, 0x69,
+	0x72, 0x12, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x44, 0x69, 0x72, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x44,
+	0x69, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x30, 0x01, 0x12, 0x3a,
+	0x0a, 0x0b, 0x52, 0x65, 0x61, 0x64, 0x44, 0x69, 0x72, 0x50, 0x6c, 0x75, 0x73, 0x12, 0x12, 0x2e,
+	0x70, 0x62, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x44, 0x69, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x13, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x44, 0x69, 0x72, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x30, 0x01, 0x12, 0x3a, 0x0a, 0x0a, 0x52, 0x65,
+	0x6c, 0x65, 0x61, 0x73, 0x65, 0x44, 0x69, 0x72, 0x12, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65,
+	0x6c, 0x65, 0x61, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45,
+	0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x31, 0x0a, 0x08, 0x46, 0x73, 0x79, 0x6e, 0x63, 0x44,
+	0x69, 0x72, 0x12, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x46, 0x73, 0x79, 0x6e, 0x63, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x46, 0x73, 0x79, 0x6e, 0x63, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x31, 0x0a, 0x06, 0x53, 0x74, 0x61,
+	0x74, 0x46, 0x73, 0x12, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x66, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74,
+	0x66, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x37, 0x0a, 0x08,
+	0x43, 0x6f, 0x6d, 0x70, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x13, 0x2e, 0x70, 0x62, 0x2e, 0x43, 0x6f,
+	0x6d, 0x70, 0x6f, 0x75, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e,
+	0x70, 0x62, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x6f, 0x75, 0x6e, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x24, 0x5a, 0x22, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e,
+	0x63, 0x6f, 0x6d, 0x2f, 0x63, 0x68, 0x69, 0x79, 0x75, 0x74, 0x69, 0x61, 0x6e, 0x79, 0x69, 0x2f,
+	0x67, 0x72, 0x70, 0x63, 0x66, 0x75, 0x73, 0x65, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
 }
 
 var (
@@ -4522,7 +5122,7 @@ func file_raw_file_system_proto_rawDescGZIP() []byte {
 	return file_raw_file_system_proto_rawDescData
 }
 
-var file_raw_file_system_proto_msgTypes = make([]protoimpl.MessageInfo, 63)
+var file_raw_file_system_proto_msgTypes = make([]protoimpl.MessageInfo, 67)
 var file_raw_file_system_proto_goTypes = []interface{}{
 	(*StringRequest)(nil),         // 0: pb.StringRequest
 	(*StringResponse)(nil),        // 1: pb.StringResponse
@@ -4587,173 +5187,200 @@ var file_raw_file_system_proto_goTypes = []interface{}{
 	(*ReadDirResponse)(nil),       // 60: pb.ReadDirResponse
 	(*StatfsRequest)(nil),         // 61: pb.StatfsRequest
 	(*StatfsResponse)(nil),        // 62: pb.StatfsResponse
-	(*InHeader)(nil),              // 63: pb.InHeader
-	(*Status)(nil),                // 64: pb.Status
-	(*EntryOut)(nil),              // 65: pb.EntryOut
-	(*AttrOut)(nil),               // 66: pb.AttrOut
-	(*Owner)(nil),                 // 67: pb.Owner
-	(*OpenOut)(nil),               // 68: pb.OpenOut
-	(*OpenIn)(nil),                // 69: pb.OpenIn
-	(*ReadIn)(nil),                // 70: pb.ReadIn
-	(*FileLock)(nil),              // 71: pb.FileLock
-	(*DirEntry)(nil),              // 72: pb.DirEntry
-	(*emptypb.Empty)(nil),         // 73: google.protobuf.Empty
+	(*CompoundOp)(nil),            // 63: pb.CompoundOp
+	(*CompoundRequest)(nil),       // 64: pb.CompoundRequest
+	(*CompoundResult)(nil),        // 65: pb.CompoundResult
+	(*CompoundResponse)(nil),      // 66: pb.CompoundResponse
+	(*InHeader)(nil),              // 67: pb.InHeader
+	(*Status)(nil),                // 68: pb.Status
+	(*EntryOut)(nil),              // 69: pb.EntryOut
+	(*AttrOut)(nil),               // 70: pb.AttrOut
+	(*Owner)(nil),                 // 71: pb.Owner
+	(*OpenOut)(nil),               // 72: pb.OpenOut
+	(*OpenIn)(nil),                // 73: pb.OpenIn
+	(*ReadIn)(nil),                // 74: pb.ReadIn
+	(*FileLock)(nil),              // 75: pb.FileLock
+	(*DirEntry)(nil),              // 76: pb.DirEntry
+	(*emptypb.Empty)(nil),         // 77: google.protobuf.Empty
 }
 var file_raw_file_system_proto_depIdxs = []int32{
-	63,  // 0: pb.LookupRequest.header:type_name -> pb.InHeader
-	64,  // 1: pb.LookupResponse.status:type_name -> pb.Status
-	65,  // 2: pb.LookupResponse.entry_out:type_name -> pb.EntryOut
-	63,  // 3: pb.GetAttrRequest.header:type_name -> pb.InHeader
-	64,  // 4: pb.GetAttrResponse.status:type_name -> pb.Status
-	66,  // 5: pb.GetAttrResponse.attr_out:type_name -> pb.AttrOut
-	63,  // 6: pb.SetAttrRequest.header:type_name -> pb.InHeader
-	67,  // 7: pb.SetAttrRequest.owner:type_name -> pb.Owner
-	64,  // 8: pb.SetAttrResponse.status:type_name -> pb.Status
-	66,  // 9: pb.SetAttrResponse.attr_out:type_name -> pb.AttrOut
-	63,  // 10: pb.MknodRequest.header:type_name -> pb.InHeader
-	64,  // 11: pb.MknodResponse.status:type_name -> pb.Status
-	65,  // 12: pb.MknodResponse.entry_out:type_name -> pb.EntryOut
-	63,  // 13: pb.MkdirRequest.header:type_name -> pb.InHeader
-	64,  // 14: pb.MkdirResponse.status:type_name -> pb.Status
-	65,  // 15: pb.MkdirResponse.entry_out:type_name -> pb.EntryOut
-	63,  // 16: pb.UnlinkRequest.header:type_name -> pb.InHeader
-	64,  // 17: pb.UnlinkResponse.status:type_name -> pb.Status
-	63,  // 18: pb.RmdirRequest.header:type_name -> pb.InHeader
-	64,  // 19: pb.RmdirResponse.status:type_name -> pb.Status
-	63,  // 20: pb.RenameRequest.header:type_name -> pb.InHeader
-	64,  // 21: pb.RenameResponse.status:type_name -> pb.Status
-	63,  // 22: pb.LinkRequest.header:type_name -> pb.InHeader
-	64,  // 23: pb.LinkResponse.status:type_name -> pb.Status
-	65,  // 24: pb.LinkResponse.entry_out:type_name -> pb.EntryOut
-	63,  // 25: pb.SymlinkRequest.header:type_name -> pb.InHeader
-	64,  // 26: pb.SymlinkResponse.status:type_name -> pb.Status
-	65,  // 27: pb.SymlinkResponse.entry_out:type_name -> pb.EntryOut
-	63,  // 28: pb.ReadlinkRequest.header:type_name -> pb.InHeader
-	64,  // 29: pb.ReadlinkResponse.status:type_name -> pb.Status
-	63,  // 30: pb.AccessRequest.header:type_name -> pb.InHeader
-	64,  // 31: pb.AccessResponse.status:type_name -> pb.Status
-	63,  // 32: pb.GetXAttrRequest.header:type_name -> pb.InHeader
-	64,  // 33: pb.GetXAttrResponse.status:type_name -> pb.Status
-	63,  // 34: pb.ListXAttrRequest.header:type_name -> pb.InHeader
-	64,  // 35: pb.ListXAttrResponse.status:type_name -> pb.Status
-	63,  // 36: pb.SetXAttrRequest.header:type_name -> pb.InHeader
-	64,  // 37: pb.SetXAttrResponse.status:type_name -> pb.Status
-	63,  // 38: pb.RemoveXAttrRequest.header:type_name -> pb.InHeader
-	64,  // 39: pb.RemoveXAttrResponse.status:type_name -> pb.Status
-	63,  // 40: pb.CreateRequest.header:type_name -> pb.InHeader
-	64,  // 41: pb.CreateResponse.status:type_name -> pb.Status
-	65,  // 42: pb.CreateResponse.entry_out:type_name -> pb.EntryOut
-	68,  // 43: pb.CreateResponse.open_out:type_name -> pb.OpenOut
-	69,  // 44: pb.OpenRequest.open_in:type_name -> pb.OpenIn
-	64,  // 45: pb.OpenResponse.status:type_name -> pb.Status
-	68,  // 46: pb.OpenResponse.open_out:type_name -> pb.OpenOut
-	70,  // 47: pb.ReadRequest.read_in:type_name -> pb.ReadIn
-	64,  // 48: pb.ReadResponse.status:type_name -> pb.Status
-	63,  // 49: pb.LseekRequest.header:type_name -> pb.InHeader
-	64,  // 50: pb.LseekResponse.status:type_name -> pb.Status
-	63,  // 51: pb.LkRequest.header:type_name -> pb.InHeader
-	71,  // 52: pb.LkRequest.lk:type_name -> pb.FileLock
-	64,  // 53: pb.GetLkResponse.status:type_name -> pb.Status
-	71,  // 54: pb.GetLkResponse.lk:type_name -> pb.FileLock
-	64,  // 55: pb.SetLkResponse.status:type_name -> pb.Status
-	63,  // 56: pb.ReleaseRequest.header:type_name -> pb.InHeader
-	63,  // 57: pb.WriteRequest.header:type_name -> pb.InHeader
-	64,  // 58: pb.WriteResponse.status:type_name -> pb.Status
-	63,  // 59: pb.CopyFileRangeRequest.header:type_name -> pb.InHeader
-	64,  // 60: pb.CopyFileRangeResponse.status:type_name -> pb.Status
-	63,  // 61: pb.FlushRequest.header:type_name -> pb.InHeader
-	64,  // 62: pb.FlushResponse.status:type_name -> pb.Status
-	63,  // 63: pb.FsyncRequest.header:type_name -> pb.InHeader
-	64,  // 64: pb.FsyncResponse.status:type_name -> pb.Status
-	63,  // 65: pb.FallocateRequest.header:type_name -> pb.InHeader
-	64,  // 66: pb.FallocateResponse.status:type_name -> pb.Status
-	69,  // 67: pb.OpenDirRequest.open_in:type_name -> pb.OpenIn
-	64,  // 68: pb.OpenDirResponse.status:type_name -> pb.Status
-	68,  // 69: pb.OpenDirResponse.open_out:type_name -> pb.OpenOut
-	70,  // 70: pb.ReadDirRequest.read_in:type_name -> pb.ReadIn
-	64,  // 71: pb.ReadDirResponse.status:type_name -> pb.Status
-	72,  // 72: pb.ReadDirResponse.entries:type_name -> pb.DirEntry
-	63,  // 73: pb.StatfsRequest.input:type_name -> pb.InHeader
-	64,  // 74: pb.StatfsResponse.status:type_name -> pb.Status
-	0,   // 75: pb.RawFileSystem.String:input_type -> pb.StringRequest
-	2,   // 76: pb.RawFileSystem.Lookup:input_type -> pb.LookupRequest
-	4,   // 77: pb.RawFileSystem.Forget:input_type -> pb.ForgetRequest
-	5,   // 78: pb.RawFileSystem.GetAttr:input_type -> pb.GetAttrRequest
-	7,   // 79: pb.RawFileSystem.SetAttr:input_type -> pb.SetAttrRequest
-	9,   // 80: pb.RawFileSystem.Mknod:input_type -> pb.MknodRequest
-	11,  // 81: pb.RawFileSystem.Mkdir:input_type -> pb.MkdirRequest
-	13,  // 82: pb.RawFileSystem.Unlink:input_type -> pb.UnlinkRequest
-	15,  // 83: pb.RawFileSystem.Rmdir:input_type -> pb.RmdirRequest
-	17,  // 84: pb.RawFileSystem.Rename:input_type -> pb.RenameRequest
-	19,  // 85: pb.RawFileSystem.Link:input_type -> pb.LinkRequest
-	21,  // 86: pb.RawFileSystem.Symlink:input_type -> pb.SymlinkRequest
-	23,  // 87: pb.RawFileSystem.Readlink:input_type -> pb.ReadlinkRequest
-	25,  // 88: pb.RawFileSystem.Access:input_type -> pb.AccessRequest
-	27,  // 89: pb.RawFileSystem.GetXAttr:input_type -> pb.GetXAttrRequest
-	29,  // 90: pb.RawFileSystem.ListXAttr:input_type -> pb.ListXAttrRequest
-	31,  // 91: pb.RawFileSystem.SetXAttr:input_type -> pb.SetXAttrRequest
-	33,  // 92: pb.RawFileSystem.RemoveXAttr:input_type -> pb.RemoveXAttrRequest
-	35,  // 93: pb.RawFileSystem.Create:input_type -> pb.CreateRequest
-	37,  // 94: pb.RawFileSystem.Open:input_type -> pb.OpenRequest
-	39,  // 95: pb.RawFileSystem.Read:input_type -> pb.ReadRequest
-	41,  // 96: pb.RawFileSystem.Lseek:input_type -> pb.LseekRequest
-	43,  // 97: pb.RawFileSystem.GetLk:input_type -> pb.LkRequest
-	43,  // 98: pb.RawFileSystem.SetLk:input_type -> pb.LkRequest
-	43,  // 99: pb.RawFileSystem.SetLkw:input_type -> pb.LkRequest
-	46,  // 100: pb.RawFileSystem.Release:input_type -> pb.ReleaseRequest
-	47,  // 101: pb.RawFileSystem.Write:input_type -> pb.WriteRequest
-	49,  // 102: pb.RawFileSystem.CopyFileRange:input_type -> pb.CopyFileRangeRequest
-	51,  // 103: pb.RawFileSystem.Flush:input_type -> pb.FlushRequest
-	53,  // 104: pb.RawFileSystem.Fsync:input_type -> pb.FsyncRequest
-	55,  // 105: pb.RawFileSystem.Fallocate:input_type -> pb.FallocateRequest
-	57,  // 106: pb.RawFileSystem.OpenDir:input_type -> pb.OpenDirRequest
-	59,  // 107: pb.RawFileSystem.ReadDir:input_type -> pb.ReadDirRequest
-	59,  // 108: pb.RawFileSystem.ReadDirPlus:input_type -> pb.ReadDirRequest
-	46,  // 109: pb.RawFileSystem.ReleaseDir:input_type -> pb.ReleaseRequest
-	53,  // 110: pb.RawFileSystem.FsyncDir:input_type -> pb.FsyncRequest
-	61,  // 111: pb.RawFileSystem.StatFs:input_type -> pb.StatfsRequest
-	1,   // 112: pb.RawFileSystem.String:output_type -> pb.StringResponse
-	3,   // 113: pb.RawFileSystem.Lookup:output_type -> pb.LookupResponse
-	73,  // 114: pb.RawFileSystem.Forget:output_type -> google.protobuf.Empty
-	6,   // 115: pb.RawFileSystem.GetAttr:output_type -> pb.GetAttrResponse
-	8,   // 116: pb.RawFileSystem.SetAttr:output_type -> pb.SetAttrResponse
-	10,  // 117: pb.RawFileSystem.Mknod:output_type -> pb.MknodResponse
-	12,  // 118: pb.RawFileSystem.Mkdir:output_type -> pb.MkdirResponse
-	14,  // 119: pb.RawFileSystem.Unlink:output_type -> pb.UnlinkResponse
-	16,  // 120: pb.RawFileSystem.Rmdir:output_type -> pb.RmdirResponse
-	18,  // 121: pb.RawFileSystem.Rename:output_type -> pb.RenameResponse
-	20,  // 122: pb.RawFileSystem.Link:output_type -> pb.LinkResponse
-	22,  // 123: pb.RawFileSystem.Symlink:output_type -> pb.SymlinkResponse
-	24,  // 124: pb.RawFileSystem.Readlink:output_type -> pb.ReadlinkResponse
-	26,  // 125: pb.RawFileSystem.Access:output_type -> pb.AccessResponse
-	28,  // 126: pb.RawFileSystem.GetXAttr:output_type -> pb.GetXAttrResponse
-	30,  // 127: pb.RawFileSystem.ListXAttr:output_type -> pb.ListXAttrResponse
-	32,  // 128: pb.RawFileSystem.SetXAttr:output_type -> pb.SetXAttrResponse
-	34,  // 129: pb.RawFileSystem.RemoveXAttr:output_type -> pb.RemoveXAttrResponse
-	36,  // 130: pb.RawFileSystem.Create:output_type -> pb.CreateResponse
-	38,  // 131: pb.RawFileSystem.Open:output_type -> pb.OpenResponse
-	40,  // 132: pb.RawFileSystem.Read:output_type -> pb.ReadResponse
-	42,  // 133: pb.RawFileSystem.Lseek:output_type -> pb.LseekResponse
-	44,  // 134: pb.RawFileSystem.GetLk:output_type -> pb.GetLkResponse
-	45,  // 135: pb.RawFileSystem.SetLk:output_type -> pb.SetLkResponse
-	45,  // 136: pb.RawFileSystem.SetLkw:output_type -> pb.SetLkResponse
-	73,  // 137: pb.RawFileSystem.Release:output_type -> google.protobuf.Empty
-	48,  // 138: pb.RawFileSystem.Write:output_type -> pb.WriteResponse
-	50,  // 139: pb.RawFileSystem.CopyFileRange:output_type -> pb.CopyFileRangeResponse
-	52,  // 140: pb.RawFileSystem.Flush:output_type -> pb.FlushResponse
-	54,  // 141: pb.RawFileSystem.Fsync:output_type -> pb.FsyncResponse
-	56,  // 142: pb.RawFileSystem.Fallocate:output_type -> pb.FallocateResponse
-	58,  // 143: pb.RawFileSystem.OpenDir:output_type -> pb.OpenDirResponse
-	60,  // 144: pb.RawFileSystem.ReadDir:output_type -> pb.ReadDirResponse
-	60,  // 145: pb.RawFileSystem.ReadDirPlus:output_type -> pb.ReadDirResponse
-	73,  // 146: pb.RawFileSystem.ReleaseDir:output_type -> google.protobuf.Empty
-	54,  // 147: pb.RawFileSystem.FsyncDir:output_type -> pb.FsyncResponse
-	62,  // 148: pb.RawFileSystem.StatFs:output_type -> pb.StatfsResponse
-	112, // [112:149] is the sub-list for method output_type
-	75,  // [75:112] is the sub-list for method input_type
-	75,  // [75:75] is the sub-list for extension type_name
-	75,  // [75:75] is the sub-list for extension extendee
-	0,   // [0:75] is the sub-list for field type_name
+	67,  // 0: pb.LookupRequest.header:type_name -> pb.InHeader
+	68,  // 1: pb.LookupResponse.status:type_name -> pb.Status
+	69,  // 2: pb.LookupResponse.entry_out:type_name -> pb.EntryOut
+	67,  // 3: pb.GetAttrRequest.header:type_name -> pb.InHeader
+	68,  // 4: pb.GetAttrResponse.status:type_name -> pb.Status
+	70,  // 5: pb.GetAttrResponse.attr_out:type_name -> pb.AttrOut
+	67,  // 6: pb.SetAttrRequest.header:type_name -> pb.InHeader
+	71,  // 7: pb.SetAttrRequest.owner:type_name -> pb.Owner
+	68,  // 8: pb.SetAttrResponse.status:type_name -> pb.Status
+	70,  // 9: pb.SetAttrResponse.attr_out:type_name -> pb.AttrOut
+	67,  // 10: pb.MknodRequest.header:type_name -> pb.InHeader
+	68,  // 11: pb.MknodResponse.status:type_name -> pb.Status
+	69,  // 12: pb.MknodResponse.entry_out:type_name -> pb.EntryOut
+	67,  // 13: pb.MkdirRequest.header:type_name -> pb.InHeader
+	68,  // 14: pb.MkdirResponse.status:type_name -> pb.Status
+	69,  // 15: pb.MkdirResponse.entry_out:type_name -> pb.EntryOut
+	67,  // 16: pb.UnlinkRequest.header:type_name -> pb.InHeader
+	68,  // 17: pb.UnlinkResponse.status:type_name -> pb.Status
+	67,  // 18: pb.RmdirRequest.header:type_name -> pb.InHeader
+	68,  // 19: pb.RmdirResponse.status:type_name -> pb.Status
+	67,  // 20: pb.RenameRequest.header:type_name -> pb.InHeader
+	68,  // 21: pb.RenameResponse.status:type_name -> pb.Status
+	67,  // 22: pb.LinkRequest.header:type_name -> pb.InHeader
+	68,  // 23: pb.LinkResponse.status:type_name -> pb.Status
+	69,  // 24: pb.LinkResponse.entry_out:type_name -> pb.EntryOut
+	67,  // 25: pb.SymlinkRequest.header:type_name -> pb.InHeader
+	68,  // 26: pb.SymlinkResponse.status:type_name -> pb.Status
+	69,  // 27: pb.SymlinkResponse.entry_out:type_name -> pb.EntryOut
+	67,  // 28: pb.ReadlinkRequest.header:type_name -> pb.InHeader
+	68,  // 29: pb.ReadlinkResponse.status:type_name -> pb.Status
+	67,  // 30: pb.AccessRequest.header:type_name -> pb.InHeader
+	68,  // 31: pb.AccessResponse.status:type_name -> pb.Status
+	67,  // 32: pb.GetXAttrRequest.header:type_name -> pb.InHeader
+	68,  // 33: pb.GetXAttrResponse.status:type_name -> pb.Status
+	67,  // 34: pb.ListXAttrRequest.header:type_name -> pb.InHeader
+	68,  // 35: pb.ListXAttrResponse.status:type_name -> pb.Status
+	67,  // 36: pb.SetXAttrRequest.header:type_name -> pb.InHeader
+	68,  // 37: pb.SetXAttrResponse.status:type_name -> pb.Status
+	67,  // 38: pb.RemoveXAttrRequest.header:type_name -> pb.InHeader
+	68,  // 39: pb.RemoveXAttrResponse.status:type_name -> pb.Status
+	67,  // 40: pb.CreateRequest.header:type_name -> pb.InHeader
+	68,  // 41: pb.CreateResponse.status:type_name -> pb.Status
+	69,  // 42: pb.CreateResponse.entry_out:type_name -> pb.EntryOut
+	72,  // 43: pb.CreateResponse.open_out:type_name -> pb.OpenOut
+	73,  // 44: pb.OpenRequest.open_in:type_name -> pb.OpenIn
+	68,  // 45: pb.OpenResponse.status:type_name -> pb.Status
+	72,  // 46: pb.OpenResponse.open_out:type_name -> pb.OpenOut
+	74,  // 47: pb.ReadRequest.read_in:type_name -> pb.ReadIn
+	68,  // 48: pb.ReadResponse.status:type_name -> pb.Status
+	67,  // 49: pb.LseekRequest.header:type_name -> pb.InHeader
+	68,  // 50: pb.LseekResponse.status:type_name -> pb.Status
+	67,  // 51: pb.LkRequest.header:type_name -> pb.InHeader
+	75,  // 52: pb.LkRequest.lk:type_name -> pb.FileLock
+	68,  // 53: pb.GetLkResponse.status:type_name -> pb.Status
+	75,  // 54: pb.GetLkResponse.lk:type_name -> pb.FileLock
+	68,  // 55: pb.SetLkResponse.status:type_name -> pb.Status
+	67,  // 56: pb.ReleaseRequest.header:type_name -> pb.InHeader
+	67,  // 57: pb.WriteRequest.header:type_name -> pb.InHeader
+	68,  // 58: pb.WriteResponse.status:type_name -> pb.Status
+	67,  // 59: pb.CopyFileRangeRequest.header:type_name -> pb.InHeader
+	68,  // 60: pb.CopyFileRangeResponse.status:type_name -> pb.Status
+	67,  // 61: pb.FlushRequest.header:type_name -> pb.InHeader
+	68,  // 62: pb.FlushResponse.status:type_name -> pb.Status
+	67,  // 63: pb.FsyncRequest.header:type_name -> pb.InHeader
+	68,  // 64: pb.FsyncResponse.status:type_name -> pb.Status
+	67,  // 65: pb.FallocateRequest.header:type_name -> pb.InHeader
+	68,  // 66: pb.FallocateResponse.status:type_name -> pb.Status
+	73,  // 67: pb.OpenDirRequest.open_in:type_name -> pb.OpenIn
+	68,  // 68: pb.OpenDirResponse.status:type_name -> pb.Status
+	72,  // 69: pb.OpenDirResponse.open_out:type_name -> pb.OpenOut
+	74,  // 70: pb.ReadDirRequest.read_in:type_name -> pb.ReadIn
+	68,  // 71: pb.ReadDirResponse.status:type_name -> pb.Status
+	76,  // 72: pb.ReadDirResponse.entries:type_name -> pb.DirEntry
+	67,  // 73: pb.StatfsRequest.input:type_name -> pb.InHeader
+	68,  // 74: pb.StatfsResponse.status:type_name -> pb.Status
+	2,   // 75: pb.CompoundOp.lookup:type_name -> pb.LookupRequest
+	5,   // 76: pb.CompoundOp.get_attr:type_name -> pb.GetAttrRequest
+	35,  // 77: pb.CompoundOp.create:type_name -> pb.CreateRequest
+	37,  // 78: pb.CompoundOp.open:type_name -> pb.OpenRequest
+	39,  // 79: pb.CompoundOp.read:type_name -> pb.ReadRequest
+	47,  // 80: pb.CompoundOp.write:type_name -> pb.WriteRequest
+	51,  // 81: pb.CompoundOp.flush:type_name -> pb.FlushRequest
+	46,  // 82: pb.CompoundOp.release:type_name -> pb.ReleaseRequest
+	53,  // 83: pb.CompoundOp.fsync:type_name -> pb.FsyncRequest
+	63,  // 84: pb.CompoundRequest.ops:type_name -> pb.CompoundOp
+	3,   // 85: pb.CompoundResult.lookup:type_name -> pb.LookupResponse
+	6,   // 86: pb.CompoundResult.get_attr:type_name -> pb.GetAttrResponse
+	36,  // 87: pb.CompoundResult.create:type_name -> pb.CreateResponse
+	38,  // 88: pb.CompoundResult.open:type_name -> pb.OpenResponse
+	40,  // 89: pb.CompoundResult.read:type_name -> pb.ReadResponse
+	48,  // 90: pb.CompoundResult.write:type_name -> pb.WriteResponse
+	52,  // 91: pb.CompoundResult.flush:type_name -> pb.FlushResponse
+	77,  // 92: pb.CompoundResult.release:type_name -> google.protobuf.Empty
+	54,  // 93: pb.CompoundResult.fsync:type_name -> pb.FsyncResponse
+	68,  // 94: pb.CompoundResponse.status:type_name -> pb.Status
+	65,  // 95: pb.CompoundResponse.results:type_name -> pb.CompoundResult
+	0,   // 96: pb.RawFileSystem.String:input_type -> pb.StringRequest
+	2,   // 97: pb.RawFileSystem.Lookup:input_type -> pb.LookupRequest
+	4,   // 98: pb.RawFileSystem.Forget:input_type -> pb.ForgetRequest
+	5,   // 99: pb.RawFileSystem.GetAttr:input_type -> pb.GetAttrRequest
+	7,   // 100: pb.RawFileSystem.SetAttr:input_type -> pb.SetAttrRequest
+	9,   // 101: pb.RawFileSystem.Mknod:input_type -> pb.MknodRequest
+	11,  // 102: pb.RawFileSystem.Mkdir:input_type -> pb.MkdirRequest
+	13,  // 103: pb.RawFileSystem.Unlink:input_type -> pb.UnlinkRequest
+	15,  // 104: pb.RawFileSystem.Rmdir:input_type -> pb.RmdirRequest
+	17,  // 105: pb.RawFileSystem.Rename:input_type -> pb.RenameRequest
+	19,  // 106: pb.RawFileSystem.Link:input_type -> pb.LinkRequest
+	21,  // 107: pb.RawFileSystem.Symlink:input_type -> pb.SymlinkRequest
+	23,  // 108: pb.RawFileSystem.Readlink:input_type -> pb.ReadlinkRequest
+	25,  // 109: pb.RawFileSystem.Access:input_type -> pb.AccessRequest
+	27,  // 110: pb.RawFileSystem.GetXAttr:input_type -> pb.GetXAttrRequest
+	29,  // 111: pb.RawFileSystem.ListXAttr:input_type -> pb.ListXAttrRequest
+	31,  // 112: pb.RawFileSystem.SetXAttr:input_type -> pb.SetXAttrRequest
+	33,  // 113: pb.RawFileSystem.RemoveXAttr:input_type -> pb.RemoveXAttrRequest
+	35,  // 114: pb.RawFileSystem.Create:input_type -> pb.CreateRequest
+	37,  // 115: pb.RawFileSystem.Open:input_type -> pb.OpenRequest
+	39,  // 116: pb.RawFileSystem.Read:input_type -> pb.ReadRequest
+	41,  // 117: pb.RawFileSystem.Lseek:input_type -> pb.LseekRequest
+	43,  // 118: pb.RawFileSystem.GetLk:input_type -> pb.LkRequest
+	43,  // 119: pb.RawFileSystem.SetLk:input_type -> pb.LkRequest
+	43,  // 120: pb.RawFileSystem.SetLkw:input_type -> pb.LkRequest
+	46,  // 121: pb.RawFileSystem.Release:input_type -> pb.ReleaseRequest
+	47,  // 122: pb.RawFileSystem.Write:input_type -> pb.WriteRequest
+	49,  // 123: pb.RawFileSystem.CopyFileRange:input_type -> pb.CopyFileRangeRequest
+	51,  // 124: pb.RawFileSystem.Flush:input_type -> pb.FlushRequest
+	53,  // 125: pb.RawFileSystem.Fsync:input_type -> pb.FsyncRequest
+	55,  // 126: pb.RawFileSystem.Fallocate:input_type -> pb.FallocateRequest
+	57,  // 127: pb.RawFileSystem.OpenDir:input_type -> pb.OpenDirRequest
+	59,  // 128: pb.RawFileSystem.ReadDir:input_type -> pb.ReadDirRequest
+	59,  // 129: pb.RawFileSystem.ReadDirPlus:input_type -> pb.ReadDirRequest
+	46,  // 130: pb.RawFileSystem.ReleaseDir:input_type -> pb.ReleaseRequest
+	53,  // 131: pb.RawFileSystem.FsyncDir:input_type -> pb.FsyncRequest
+	61,  // 132: pb.RawFileSystem.StatFs:input_type -> pb.StatfsRequest
+	64,  // 133: pb.RawFileSystem.Compound:input_type -> pb.CompoundRequest
+	1,   // 134: pb.RawFileSystem.String:output_type -> pb.StringResponse
+	3,   // 135: pb.RawFileSystem.Lookup:output_type -> pb.LookupResponse
+	77,  // 136: pb.RawFileSystem.Forget:output_type -> google.protobuf.Empty
+	6,   // 137: pb.RawFileSystem.GetAttr:output_type -> pb.GetAttrResponse
+	8,   // 138: pb.RawFileSystem.SetAttr:output_type -> pb.SetAttrResponse
+	10,  // 139: pb.RawFileSystem.Mknod:output_type -> pb.MknodResponse
+	12,  // 140: pb.RawFileSystem.Mkdir:output_type -> pb.MkdirResponse
+	14,  // 141: pb.RawFileSystem.Unlink:output_type -> pb.UnlinkResponse
+	16,  // 142: pb.RawFileSystem.Rmdir:output_type -> pb.RmdirResponse
+	18,  // 143: pb.RawFileSystem.Rename:output_type -> pb.RenameResponse
+	20,  // 144: pb.RawFileSystem.Link:output_type -> pb.LinkResponse
+	22,  // 145: pb.RawFileSystem.Symlink:output_type -> pb.SymlinkResponse
+	24,  // 146: pb.RawFileSystem.Readlink:output_type -> pb.ReadlinkResponse
+	26,  // 147: pb.RawFileSystem.Access:output_type -> pb.AccessResponse
+	28,  // 148: pb.RawFileSystem.GetXAttr:output_type -> pb.GetXAttrResponse
+	30,  // 149: pb.RawFileSystem.ListXAttr:output_type -> pb.ListXAttrResponse
+	32,  // 150: pb.RawFileSystem.SetXAttr:output_type -> pb.SetXAttrResponse
+	34,  // 151: pb.RawFileSystem.RemoveXAttr:output_type -> pb.RemoveXAttrResponse
+	36,  // 152: pb.RawFileSystem.Create:output_type -> pb.CreateResponse
+	38,  // 153: pb.RawFileSystem.Open:output_type -> pb.OpenResponse
+	40,  // 154: pb.RawFileSystem.Read:output_type -> pb.ReadResponse
+	42,  // 155: pb.RawFileSystem.Lseek:output_type -> pb.LseekResponse
+	44,  // 156: pb.RawFileSystem.GetLk:output_type -> pb.GetLkResponse
+	45,  // 157: pb.RawFileSystem.SetLk:output_type -> pb.SetLkResponse
+	45,  // 158: pb.RawFileSystem.SetLkw:output_type -> pb.SetLkResponse
+	77,  // 159: pb.RawFileSystem.Release:output_type -> google.protobuf.Empty
+	48,  // 160: pb.RawFileSystem.Write:output_type -> pb.WriteResponse
+	50,  // 161: pb.RawFileSystem.CopyFileRange:output_type -> pb.CopyFileRangeResponse
+	52,  // 162: pb.RawFileSystem.Flush:output_type -> pb.FlushResponse
+	54,  // 163: pb.RawFileSystem.Fsync:output_type -> pb.FsyncResponse
+	56,  // 164: pb.RawFileSystem.Fallocate:output_type -> pb.FallocateResponse
+	58,  // 165: pb.RawFileSystem.OpenDir:output_type -> pb.OpenDirResponse
+	60,  // 166: pb.RawFileSystem.ReadDir:output_type -> pb.ReadDirResponse
+	60,  // 167: pb.RawFileSystem.ReadDirPlus:output_type -> pb.ReadDirResponse
+	77,  // 168: pb.RawFileSystem.ReleaseDir:output_type -> google.protobuf.Empty
+	54,  // 169: pb.RawFileSystem.FsyncDir:output_type -> pb.FsyncResponse
+	62,  // 170: pb.RawFileSystem.StatFs:output_type -> pb.StatfsResponse
+	66,  // 171: pb.RawFileSystem.Compound:output_type -> pb.CompoundResponse
+	134, // [134:172] is the sub-list for method output_type
+	96,  // [96:134] is the sub-list for method input_type
+	96,  // [96:96] is the sub-list for extension type_name
+	96,  // [96:96] is the sub-list for extension extendee
+	0,   // [0:96] is the sub-list for field type_name
 }
 
 func init() { file_raw_file_system_proto_init() }
@@ -5519,6 +6146,76 @@ func file_raw_file_system_proto_init() {
 				return nil
 			}
 		}
+		file_raw_file_system_proto_msgTypes[63].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CompoundOp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_raw_file_system_proto_msgTypes[64].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CompoundRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_raw_file_system_proto_msgTypes[65].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CompoundResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_raw_file_system_proto_msgTypes[66].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CompoundResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_raw_file_system_proto_msgTypes[63].OneofWrappers = []interface{}{
+		(*CompoundOp_Lookup)(nil),
+		(*CompoundOp_GetAttr)(nil),
+		(*CompoundOp_Create)(nil),
+		(*CompoundOp_Open)(nil),
+		(*CompoundOp_Read)(nil),
+		(*CompoundOp_Write)(nil),
+		(*CompoundOp_Flush)(nil),
+		(*CompoundOp_Release)(nil),
+		(*CompoundOp_Fsync)(nil),
+	}
+	file_raw_file_system_proto_msgTypes[65].OneofWrappers = []interface{}{
+		(*CompoundResult_Lookup)(nil),
+		(*CompoundResult_GetAttr)(nil),
+		(*CompoundResult_Create)(nil),
+		(*CompoundResult_Open)(nil),
+		(*CompoundResult_Read)(nil),
+		(*CompoundResult_Write)(nil),
+		(*CompoundResult_Flush)(nil),
+		(*CompoundResult_Release)(nil),
+		(*CompoundResult_Fsync)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -5526,7 +6223,7 @@ func file_raw_file_system_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_raw_file_system_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   63,
+			NumMessages:   67,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -83,6 +83,12 @@ type RawFileSystemClient interface {
 	ReleaseDir(ctx context.Context, in *ReleaseRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	FsyncDir(ctx context.Context, in *FsyncRequest, opts ...grpc.CallOption) (*FsyncResponse, error)
 	StatFs(ctx context.Context, in *StatfsRequest, opts ...grpc.CallOption) (*StatfsResponse, error)
+	// Compound runs ops in order, in one round trip, and stops at the
+	// first that fails. An op can take its node ID and handle from those
+	// of an earlier one, such as a Write from the Create before it. The
+	// Releases left after an op fails still run, for the handles of the
+	// ops before it.
+	Compound(ctx context.Context, in *CompoundRequest, opts ...grpc.CallOption) (*CompoundResponse, error)
 }
 
 type rawFileSystemClient struct {
@@ -495,6 +501,15 @@ func (c *rawFileSystemClient) StatFs(ctx context.Context, in *StatfsRequest, opt
 	return out, nil
 }
 
+func (c *rawFileSystemClient) Compound(ctx context.Context, in *CompoundRequest, opts ...grpc.CallOption) (*CompoundResponse, error) {
+	out := new(CompoundResponse)
+	err := c.cc.Invoke(ctx, "/pb.RawFileSystem/Compound", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // RawFileSystemServer is the server API for RawFileSystem service.
 // All implementations must embed UnimplementedRawFileSystemServer
 // for forward compatibility
@@ -559,6 +574,12 @@ type RawFileSystemServer interface {
 	ReleaseDir(context.Context, *ReleaseRequest) (*emptypb.Empty, error)
 	FsyncDir(context.Context, *FsyncRequest) (*FsyncResponse, error)
 	StatFs(context.Context, *StatfsRequest) (*StatfsResponse, error)
+	// Compound runs ops in order, in one round trip, and stops at the
+	// first that fails. An op can take its node ID and handle from those
+	// of an earlier one, such as a Write from the Create before it. The
+	// Releases left after an op fails still run, for the handles of the
+	// ops before it.
+	Compound(context.Context, *CompoundRequest) (*CompoundResponse, error)
 	mustEmbedUnimplementedRawFileSystemServer()
 }
 
@@ -677,6 +698,9 @@ func (UnimplementedRawFileSystemServer) FsyncDir(context.Context, *FsyncRequest)
 func (UnimplementedRawFileSystemServer) StatFs(context.Context, *StatfsRequest) (*StatfsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method StatFs not implemented")
 }
+func (UnimplementedRawFileSystemServer) Compound(context.Context, *CompoundRequest) (*CompoundResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Compound not implemented")
+}
 func (UnimplementedRawFileSystemServer) mustEmbedUnimplementedRawFileSystemServer() {}
 
 // UnsafeRawFileSystemServer may be embedded to opt out of forward compatibility for this service.
@@ -1365,6 +1389,24 @@ func _RawFileSystem_StatFs_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RawFileSystem_Compound_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompoundRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RawFileSystemServer).Compound(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.RawFileSystem/Compound",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RawFileSystemServer).Compound(ctx, req.(*CompoundRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // RawFileSystem_ServiceDesc is the grpc.ServiceDesc for RawFileSystem service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -1508,6 +1550,10 @@ var RawFileSystem_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "StatFs",
 			Handler:    _RawFileSystem_StatFs_Handler,
 		},
+		{
+			MethodName: "Compound",
+			Handler:    _RawFileSystem_Compound_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -0,0 +1,34 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peercred
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// getCred leaves Pid at 0: LOCAL_PEERCRED has none.
+func getCred(fd int) (*Cred, error) {
+	xucred, err := unix.GetsockoptXucred(fd, unix.SOL_LOCAL, unix.LOCAL_PEERCRED)
+	if err != nil {
+		return nil, err
+	}
+	cred := &Cred{Uid: xucred.Uid}
+	if xucred.Ngroups > 0 {
+		cred.Gid = xucred.Groups[0]
+	}
+	return cred, nil
+}
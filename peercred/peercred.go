@@ -0,0 +1,166 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package peercred tells who is at the other end of a Unix socket. The
+// kernel reports the user, group and process of the peer when it
+// connects (SO_PEERCRED on Linux), so a server next to its clients can
+// trust that identity instead of the caller sent in each request:
+//
+//	server := grpc.NewServer(
+//		grpc.Creds(peercred.NewCredentials(nil)),
+//		grpc.ChainUnaryInterceptor(policy.UnaryServerInterceptor()),
+//		grpc.ChainStreamInterceptor(policy.StreamServerInterceptor()))
+//
+// Connections over other transports carry no credentials and are left
+// alone.
+package peercred
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"syscall"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/peer"
+)
+
+// AuthType is what the AuthInfo of a connection with peer credentials
+// reports.
+const AuthType = "peercred"
+
+// Cred is the identity of the process at the other end of a socket.
+type Cred struct {
+	Uid uint32
+	Gid uint32
+	// Pid is 0 where the system does not report it.
+	Pid int32
+}
+
+func (c Cred) String() string {
+	return fmt.Sprintf("uid=%d gid=%d pid=%d", c.Uid, c.Gid, c.Pid)
+}
+
+// AuthInfo is the AuthInfo of connections accepted with the credentials
+// of NewCredentials.
+type AuthInfo struct {
+	credentials.CommonAuthInfo
+	// Cred is nil when the connection is not a Unix socket.
+	Cred *Cred
+	// Inner is the AuthInfo of the wrapped credentials, such as TLS.
+	Inner credentials.AuthInfo
+}
+
+// AuthType implements credentials.AuthInfo.
+func (a *AuthInfo) AuthType() string {
+	return AuthType
+}
+
+// FromConn returns the credentials of the peer of a Unix socket.
+func FromConn(conn net.Conn) (*Cred, error) {
+	sc, ok := conn.(syscall.Conn)
+	if !ok {
+		return nil, fmt.Errorf("peercred: %T is not a socket", conn)
+	}
+	raw, err := sc.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		cred    *Cred
+		credErr error
+	)
+	if err := raw.Control(func(fd uintptr) {
+		cred, credErr = getCred(int(fd))
+	}); err != nil {
+		return nil, err
+	}
+	if credErr != nil {
+		return nil, fmt.Errorf("peercred: %v", credErr)
+	}
+	return cred, nil
+}
+
+// FromContext returns the peer credentials of the connection a call came
+// in on.
+func FromContext(ctx context.Context) (*Cred, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	ai, ok := p.AuthInfo.(*AuthInfo)
+	if !ok || ai.Cred == nil {
+		return nil, false
+	}
+	return ai.Cred, true
+}
+
+type transportCredentials struct {
+	inner credentials.TransportCredentials
+}
+
+// NewCredentials returns server credentials that read the peer
+// credentials of Unix socket connections, then hand the connection to
+// inner, which may be TLS. A nil inner means no encryption, like
+// grpc.WithInsecure on the client.
+func NewCredentials(inner credentials.TransportCredentials) credentials.TransportCredentials {
+	if inner == nil {
+		inner = insecure.NewCredentials()
+	}
+	return &transportCredentials{inner: inner}
+}
+
+func (c *transportCredentials) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return c.inner.ClientHandshake(ctx, authority, conn)
+}
+
+func (c *transportCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	var cred *Cred
+	if _, ok := conn.(*net.UnixConn); ok {
+		var err error
+		if cred, err = FromConn(conn); err != nil {
+			return nil, nil, err
+		}
+	}
+	conn, inner, err := c.inner.ServerHandshake(conn)
+	if err != nil {
+		return nil, nil, err
+	}
+	ai := &AuthInfo{
+		CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.NoSecurity},
+		Cred:           cred,
+		Inner:          inner,
+	}
+	if common, ok := inner.(interface {
+		GetCommonAuthInfo() credentials.CommonAuthInfo
+	}); ok {
+		ai.CommonAuthInfo = common.GetCommonAuthInfo()
+	}
+	return conn, ai, nil
+}
+
+func (c *transportCredentials) Info() credentials.ProtocolInfo {
+	return c.inner.Info()
+}
+
+func (c *transportCredentials) Clone() credentials.TransportCredentials {
+	return &transportCredentials{inner: c.inner.Clone()}
+}
+
+func (c *transportCredentials) OverrideServerName(name string) error {
+	return c.inner.OverrideServerName(name)
+}
@@ -0,0 +1,126 @@
+package peercred_test
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/chiyutianyi/grpcfuse/grpcfusetest"
+	"github.com/chiyutianyi/grpcfuse/memfs"
+	"github.com/chiyutianyi/grpcfuse/pb"
+	"github.com/chiyutianyi/grpcfuse/peercred"
+)
+
+// seen records the peer credentials of the calls it lets through.
+type seen struct {
+	creds []*peercred.Cred
+}
+
+func (s *seen) interceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		cred, _ := peercred.FromContext(ctx)
+		s.creds = append(s.creds, cred)
+		return handler(ctx, req)
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	unixListener, err := net.Listen("unix", filepath.Join(t.TempDir(), "sock"))
+	require.NoError(t, err)
+	tcpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		listener net.Listener
+		want     *peercred.Cred
+	}{
+		{name: "unix", listener: unixListener, want: &peercred.Cred{Uid: uint32(os.Getuid()), Gid: uint32(os.Getgid()), Pid: int32(os.Getpid())}},
+		{name: "tcp", listener: tcpListener},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &seen{}
+			p := grpcfusetest.New(t, memfs.New(nil, nil), &grpcfusetest.Options{
+				Listener: tt.listener,
+				ServerOptions: []grpc.ServerOption{
+					grpc.Creds(peercred.NewCredentials(nil)),
+					grpc.ChainUnaryInterceptor(s.interceptor()),
+				},
+			})
+			var out fuse.AttrOut
+			require.Equal(t, fuse.OK, p.Client.GetAttr(nil, &fuse.GetAttrIn{InHeader: fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}}, &out))
+			require.Len(t, s.creds, 1)
+			assert.Equal(t, tt.want, s.creds[0])
+		})
+	}
+}
+
+func TestFromConn(t *testing.T) {
+	_, err := peercred.FromConn(&net.TCPConn{})
+	assert.Error(t, err)
+
+	_, ok := peercred.FromContext(context.Background())
+	assert.False(t, ok)
+}
+
+func TestPolicy(t *testing.T) {
+	uid, gid := uint32(os.Getuid()), uint32(os.Getgid())
+	tests := []struct {
+		name   string
+		policy peercred.Policy
+		// owner is the owner of a directory made by a caller claiming to
+		// be 4242.
+		owner fuse.Owner
+		want  fuse.Status
+	}{
+		{name: "untrusted", policy: peercred.Policy{}, owner: fuse.Owner{Uid: uid, Gid: gid}},
+		{name: "trusted", policy: peercred.Policy{TrustedUIDs: []uint32{uid}}, owner: fuse.Owner{Uid: 4242, Gid: 4242}},
+		{name: "allowed", policy: peercred.Policy{AllowedUIDs: []uint32{uid}}, owner: fuse.Owner{Uid: uid, Gid: gid}},
+		{name: "not allowed", policy: peercred.Policy{AllowedUIDs: []uint32{uid + 1}}, want: fuse.EIO},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := net.Listen("unix", filepath.Join(t.TempDir(), "sock"))
+			require.NoError(t, err)
+			p := grpcfusetest.New(t, memfs.New(nil, nil), &grpcfusetest.Options{
+				Listener: l,
+				ServerOptions: []grpc.ServerOption{
+					grpc.Creds(peercred.NewCredentials(nil)),
+					grpc.ChainUnaryInterceptor(tt.policy.UnaryServerInterceptor()),
+					grpc.ChainStreamInterceptor(tt.policy.StreamServerInterceptor()),
+				},
+			})
+
+			in := &fuse.MkdirIn{InHeader: fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID, Caller: fuse.Caller{Owner: fuse.Owner{Uid: 4242, Gid: 4242}}}, Mode: 0777}
+			var out fuse.EntryOut
+			require.Equal(t, tt.want, p.Client.Mkdir(nil, in, "dir", &out))
+			if tt.want != fuse.OK {
+				return
+			}
+			assert.Equal(t, tt.owner, out.Owner)
+
+			// Streams are checked too.
+			assert.Equal(t, fuse.OK, p.Client.ReadDir(nil, &fuse.ReadIn{InHeader: fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}, Size: 4096}, fuse.NewDirEntryList(make([]byte, 4096), 0)))
+
+			// So are the ops of a Compound.
+			spoofed := &pb.InHeader{NodeId: fuse.FUSE_ROOT_ID, Caller: &pb.Caller{Owner: &pb.Owner{Uid: 4242, Gid: 4242}}}
+			res, err := pb.NewRawFileSystemClient(p.Conn).Compound(context.Background(), &pb.CompoundRequest{Ops: []*pb.CompoundOp{
+				{Op: &pb.CompoundOp_Create{Create: &pb.CreateRequest{Header: spoofed, Name: "file", Flags: syscall.O_RDWR, Mode: 0644}}},
+				{Op: &pb.CompoundOp_Release{Release: &pb.ReleaseRequest{Header: &pb.InHeader{Caller: spoofed.Caller}}}, NodeFrom: 1, FhFrom: 1},
+			}})
+			require.NoError(t, err)
+			require.Zero(t, res.Status.GetCode())
+			owner := res.Results[0].GetCreate().EntryOut.Attr.Owner
+			assert.Equal(t, tt.owner, fuse.Owner{Uid: owner.Uid, Gid: owner.Gid})
+		})
+	}
+}
@@ -0,0 +1,160 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peercred
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+var inHeaderName = (&pb.InHeader{}).ProtoReflect().Descriptor().FullName()
+
+// Policy decides what the peers of a Unix socket may do. Calls without
+// peer credentials, such as over TCP, are not checked.
+type Policy struct {
+	// AllowedUIDs are the users that may connect; empty allows all.
+	AllowedUIDs []uint32
+	// TrustedUIDs may make calls on behalf of other users, like a FUSE
+	// mount passing on the callers the kernel gives it. The calls of
+	// other peers are made as the peer, whatever caller they carry.
+	TrustedUIDs []uint32
+}
+
+func contains(uids []uint32, uid uint32) bool {
+	for _, u := range uids {
+		if u == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// check fails calls from peers that are not allowed, and returns the
+// caller to put in the requests of untrusted peers.
+func (p *Policy) check(ctx context.Context, method string) (*pb.Caller, error) {
+	cred, ok := FromContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+	if len(p.AllowedUIDs) > 0 && !contains(p.AllowedUIDs, cred.Uid) {
+		log.Warnf("peercred: %s: %v not allowed", method, cred)
+		return nil, status.Errorf(codes.PermissionDenied, "peercred: uid %d not allowed", cred.Uid)
+	}
+	if contains(p.TrustedUIDs, cred.Uid) {
+		return nil, nil
+	}
+	return &pb.Caller{Owner: &pb.Owner{Uid: cred.Uid, Gid: cred.Gid}, Pid: uint32(cred.Pid)}, nil
+}
+
+// hasHeader tells whether messages of md hold an InHeader, also in lists
+// such as the ops of a Compound.
+func hasHeader(md protoreflect.MessageDescriptor, depth int) bool {
+	if depth > 3 {
+		return false
+	}
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.Message() == nil || fd.IsMap() {
+			continue
+		}
+		if fd.Message().FullName() == inHeaderName || hasHeader(fd.Message(), depth+1) {
+			return true
+		}
+	}
+	return false
+}
+
+// setCaller puts caller in the InHeaders of m, adding those missing so
+// that an absent header does not read as root. Only the member of a oneof
+// that is set is changed.
+func setCaller(m protoreflect.Message, caller *pb.Caller) {
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.Message() == nil || fd.IsMap() || (fd.ContainingOneof() != nil && !m.Has(fd)) {
+			continue
+		}
+		switch {
+		case fd.IsList():
+			if !hasHeader(fd.Message(), 0) {
+				continue
+			}
+			list := m.Get(fd).List()
+			for j := 0; j < list.Len(); j++ {
+				setCaller(list.Get(j).Message(), caller)
+			}
+		case fd.Message().FullName() == inHeaderName:
+			m.Mutable(fd).Message().Interface().(*pb.InHeader).Caller = proto.Clone(caller).(*pb.Caller)
+		case hasHeader(fd.Message(), 0):
+			setCaller(m.Mutable(fd).Message(), caller)
+		}
+	}
+}
+
+func rewrite(req interface{}, caller *pb.Caller) {
+	if m, ok := req.(proto.Message); ok && caller != nil {
+		setCaller(m.ProtoReflect(), caller)
+	}
+}
+
+// UnaryServerInterceptor applies p to unary calls.
+func (p *Policy) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		caller, err := p.check(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		rewrite(req, caller)
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor applies p to streaming calls.
+func (p *Policy) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		caller, err := p.check(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		if caller != nil {
+			ss = &callerStream{ServerStream: ss, caller: caller}
+		}
+		return handler(srv, ss)
+	}
+}
+
+type callerStream struct {
+	grpc.ServerStream
+	caller *pb.Caller
+}
+
+func (s *callerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	rewrite(m, s.caller)
+	return nil
+}
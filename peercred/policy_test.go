@@ -0,0 +1,66 @@
+package peercred
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+func TestSetCaller(t *testing.T) {
+	caller := &pb.Caller{Owner: &pb.Owner{Uid: 1000, Gid: 100}, Pid: 42}
+	header := func(c *pb.Caller) *pb.InHeader { return &pb.InHeader{NodeId: 1, Caller: c} }
+	root := &pb.Caller{Owner: &pb.Owner{}}
+
+	tests := []struct {
+		name      string
+		req, want proto.Message
+	}{
+		{
+			name: "header",
+			req:  &pb.MkdirRequest{Header: header(root), Name: "d"},
+			want: &pb.MkdirRequest{Header: header(caller), Name: "d"},
+		},
+		{
+			name: "nested header",
+			req:  &pb.ReadRequest{ReadIn: &pb.ReadIn{Header: header(root), Size: 10}},
+			want: &pb.ReadRequest{ReadIn: &pb.ReadIn{Header: header(caller), Size: 10}},
+		},
+		{
+			name: "missing header",
+			req:  &pb.LookupRequest{Name: "f"},
+			want: &pb.LookupRequest{Header: &pb.InHeader{Caller: caller}, Name: "f"},
+		},
+		{
+			name: "statfs",
+			req:  &pb.StatfsRequest{Input: header(nil)},
+			want: &pb.StatfsRequest{Input: header(caller)},
+		},
+		{
+			name: "compound",
+			req: &pb.CompoundRequest{Ops: []*pb.CompoundOp{
+				{Op: &pb.CompoundOp_Create{Create: &pb.CreateRequest{Header: header(root), Name: "f"}}},
+				{Op: &pb.CompoundOp_Write{Write: &pb.WriteRequest{Header: header(root)}}, FhFrom: 1},
+				{Op: &pb.CompoundOp_Release{Release: &pb.ReleaseRequest{}}, FhFrom: 1},
+			}},
+			want: &pb.CompoundRequest{Ops: []*pb.CompoundOp{
+				{Op: &pb.CompoundOp_Create{Create: &pb.CreateRequest{Header: header(caller), Name: "f"}}},
+				{Op: &pb.CompoundOp_Write{Write: &pb.WriteRequest{Header: header(caller)}}, FhFrom: 1},
+				{Op: &pb.CompoundOp_Release{Release: &pb.ReleaseRequest{Header: &pb.InHeader{Caller: caller}}}, FhFrom: 1},
+			}},
+		},
+		{
+			name: "no header",
+			req:  &pb.StringRequest{},
+			want: &pb.StringRequest{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rewrite(tt.req, caller)
+			assert.True(t, proto.Equal(tt.want, tt.req), "got %v", tt.req)
+		})
+	}
+}
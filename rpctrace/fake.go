@@ -0,0 +1,176 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpctrace
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/chiyutianyi/grpcfuse/grpc2fuse"
+	"github.com/chiyutianyi/grpcfuse/grpcfusetest"
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+// Fake is a gRPC service answering calls with the responses of a trace,
+// standing in for the server the trace was recorded against.
+type Fake struct {
+	records []*pb.TraceRecord
+
+	mu          sync.Mutex
+	used        []bool
+	divergences []Divergence
+}
+
+// NewFake returns a Fake answering from records.
+func NewFake(records []*pb.TraceRecord) *Fake {
+	return &Fake{records: records, used: make([]bool, len(records))}
+}
+
+// ServerOption makes a gRPC server without services of its own answer
+// every call from the trace.
+func (f *Fake) ServerOption() grpc.ServerOption {
+	return grpc.UnknownServiceHandler(f.handle)
+}
+
+// Divergences returns the calls made with a request different from the
+// recorded one.
+func (f *Fake) Divergences() []Divergence {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Divergence(nil), f.divergences...)
+}
+
+// Remaining returns how many recorded calls have not been made.
+func (f *Fake) Remaining() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, used := range f.used {
+		if !used {
+			n++
+		}
+	}
+	return n
+}
+
+// match takes the first unused record of method with an equal request, or
+// else the first unused record of method, noting the divergence.
+func (f *Fake) match(name string, m *method, req proto.Message) (*pb.TraceRecord, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	first := -1
+	for i, rec := range f.records {
+		if f.used[i] || rec.Method != name {
+			continue
+		}
+		want, err := m.request(rec)
+		if err != nil {
+			return nil, err
+		}
+		if proto.Equal(want, req) {
+			f.used[i] = true
+			return rec, nil
+		}
+		if first < 0 {
+			first = i
+		}
+	}
+	if first < 0 {
+		return nil, status.Errorf(codes.FailedPrecondition, "rpctrace: no recorded call of %s left", name)
+	}
+	f.used[first] = true
+	rec := f.records[first]
+	want, _ := m.request(rec)
+	f.divergences = append(f.divergences, Divergence{
+		Index:  first,
+		Method: m.name,
+		Want:   "request " + prototext.MarshalOptions{}.Format(want),
+		Got:    "request " + prototext.MarshalOptions{}.Format(req),
+	})
+	return rec, nil
+}
+
+func (f *Fake) handle(_ interface{}, stream grpc.ServerStream) error {
+	name, ok := grpc.MethodFromServerStream(stream)
+	if !ok {
+		return status.Error(codes.Internal, "rpctrace: no method in stream")
+	}
+	m, err := lookupMethod(name)
+	if err != nil {
+		return status.Error(codes.Unimplemented, err.Error())
+	}
+	req := m.in.New().Interface()
+	if err := stream.RecvMsg(req); err != nil {
+		return err
+	}
+	rec, err := f.match(name, m, req)
+	if err != nil {
+		return err
+	}
+	for _, b := range rec.Responses {
+		res := m.out.New().Interface()
+		if err := proto.Unmarshal(b, res); err != nil {
+			return status.Errorf(codes.Internal, "rpctrace: %s response: %v", name, err)
+		}
+		if err := stream.SendMsg(res); err != nil {
+			return err
+		}
+	}
+	return status.Error(codes.Code(rec.Code), rec.Message)
+}
+
+// ReplayClient replays a trace through grpc2fuse: the calls are made on a
+// fuse2grpc server whose file system is a grpc2fuse client of a Fake
+// serving the trace. A divergence means that a call did not survive the
+// trip through both, or that grpc2fuse did not send the recorded request.
+func ReplayClient(ctx context.Context, records []*pb.TraceRecord, opts *ReplayOptions) (*Report, error) {
+	fake := NewFake(records)
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(fake.ServerOption())
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.DialContext(ctx, "bufconn",
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	pair, err := grpcfusetest.NewPair(grpc2fuse.NewFileSystem(pb.NewRawFileSystemClient(conn)), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer pair.Close()
+
+	report, err := Replay(ctx, pair.Conn, records, opts)
+	if report != nil {
+		report.Divergences = append(report.Divergences, fake.Divergences()...)
+	}
+	return report, err
+}
@@ -0,0 +1,79 @@
+package rpctrace
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+func newFakeClient(t *testing.T, fake *Fake) pb.RawFileSystemClient {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(fake.ServerOption())
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufconn", grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewRawFileSystemClient(conn)
+}
+
+func lookupRecord(t *testing.T, name string, ino uint64) *pb.TraceRecord {
+	req, err := proto.Marshal(&pb.LookupRequest{Header: &pb.InHeader{NodeId: 1}, Name: name})
+	require.NoError(t, err)
+	res, err := proto.Marshal(&pb.LookupResponse{EntryOut: &pb.EntryOut{NodeId: ino}})
+	require.NoError(t, err)
+	return &pb.TraceRecord{Method: "/pb.RawFileSystem/Lookup", Request: req, Responses: [][]byte{res}}
+}
+
+func TestFake(t *testing.T) {
+	fake := NewFake([]*pb.TraceRecord{
+		lookupRecord(t, "a", 2),
+		lookupRecord(t, "b", 3),
+		lookupRecord(t, "c", 4),
+		{Method: "/pb.RawFileSystem/GetAttr", Code: uint32(codes.Unavailable), Message: "gone"},
+	})
+	client := newFakeClient(t, fake)
+	ctx := context.Background()
+	lookup := func(name string) (uint64, error) {
+		res, err := client.Lookup(ctx, &pb.LookupRequest{Header: &pb.InHeader{NodeId: 1}, Name: name})
+		return res.GetEntryOut().GetNodeId(), err
+	}
+	assert.Equal(t, 4, fake.Remaining())
+
+	// Calls are matched by request, whatever their order.
+	ino, err := lookup("b")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), ino)
+	assert.Empty(t, fake.Divergences())
+
+	// Without an equal request the first unused call answers.
+	ino, err = lookup("x")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), ino)
+	require.Len(t, fake.Divergences(), 1)
+	assert.Equal(t, 0, fake.Divergences()[0].Index)
+	assert.Contains(t, fake.Divergences()[0].Want, `name:"a"`)
+	assert.Contains(t, fake.Divergences()[0].Got, `name:"x"`)
+
+	_, err = client.GetAttr(ctx, &pb.GetAttrRequest{})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	_, err = lookup("c")
+	require.NoError(t, err)
+	assert.Equal(t, 0, fake.Remaining())
+	_, err = lookup("c")
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
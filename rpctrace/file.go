@@ -0,0 +1,158 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package rpctrace records the RawFileSystem calls going through a gRPC
+// client or server to a trace file, and replays traces to find where a
+// server or a client now behaves differently.
+//
+// Record on the server with
+//
+//	rec, err := rpctrace.NewRecorder(file)
+//	s := grpc.NewServer(
+//		grpc.ChainUnaryInterceptor(rec.UnaryServerInterceptor()),
+//		grpc.ChainStreamInterceptor(rec.StreamServerInterceptor()))
+//
+// and replay the trace with Replay against a server, or with ReplayClient
+// against grpc2fuse.
+package rpctrace
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+// maxRecordSize bounds the records Reader accepts, so a corrupt length
+// does not allocate the world.
+const maxRecordSize = 1 << 30
+
+// Writer writes a trace file.
+type Writer struct {
+	w   *bufio.Writer
+	buf []byte
+}
+
+// NewWriter writes the header of a trace that started at start, in
+// nanoseconds since the Unix epoch.
+func NewWriter(w io.Writer, start int64) (*Writer, error) {
+	tw := &Writer{w: bufio.NewWriter(w)}
+	if err := tw.write(&pb.TraceHeader{Start: start}); err != nil {
+		return nil, err
+	}
+	return tw, nil
+}
+
+func (w *Writer) write(m proto.Message) error {
+	var err error
+	w.buf, err = proto.MarshalOptions{}.MarshalAppend(w.buf[:0], m)
+	if err != nil {
+		return err
+	}
+	var size [binary.MaxVarintLen64]byte
+	if _, err := w.w.Write(size[:binary.PutUvarint(size[:], uint64(len(w.buf)))]); err != nil {
+		return err
+	}
+	_, err = w.w.Write(w.buf)
+	return err
+}
+
+// Write appends a record.
+func (w *Writer) Write(r *pb.TraceRecord) error {
+	return w.write(r)
+}
+
+// Flush writes out buffered records.
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+// Reader reads a trace file.
+type Reader struct {
+	r      *bufio.Reader
+	header pb.TraceHeader
+	buf    []byte
+}
+
+// NewReader reads the header of a trace.
+func NewReader(r io.Reader) (*Reader, error) {
+	tr := &Reader{r: bufio.NewReader(r)}
+	if err := tr.read(&tr.header); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("trace header: %w", err)
+	}
+	return tr, nil
+}
+
+func (r *Reader) read(m proto.Message) error {
+	size, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return err
+	}
+	if size > maxRecordSize {
+		return fmt.Errorf("record of %d bytes", size)
+	}
+	if uint64(cap(r.buf)) < size {
+		r.buf = make([]byte, size)
+	}
+	r.buf = r.buf[:size]
+	if _, err := io.ReadFull(r.r, r.buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	return proto.Unmarshal(r.buf, m)
+}
+
+// Header returns the header of the trace.
+func (r *Reader) Header() *pb.TraceHeader {
+	return &r.header
+}
+
+// Next returns the next record, or io.EOF at the end of the trace.
+func (r *Reader) Next() (*pb.TraceRecord, error) {
+	rec := &pb.TraceRecord{}
+	if err := r.read(rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// ReadAll reads the records of a trace.
+func ReadAll(r io.Reader) ([]*pb.TraceRecord, error) {
+	tr, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	var records []*pb.TraceRecord
+	for {
+		rec, err := tr.Next()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, fmt.Errorf("trace record %d: %w", len(records), err)
+		}
+		records = append(records, rec)
+	}
+}
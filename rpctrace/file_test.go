@@ -0,0 +1,68 @@
+package rpctrace
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+func TestFileRoundTrip(t *testing.T) {
+	records := []*pb.TraceRecord{
+		{Method: "/pb.RawFileSystem/GetAttr", Start: 1, Duration: 2, Request: []byte{1, 2}, Responses: [][]byte{{3}}},
+		{Method: "/pb.RawFileSystem/Read", Start: 5, Responses: [][]byte{{4}, {5}}, Code: 14, Message: "cut"},
+		{Method: "/pb.RawFileSystem/Release"},
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, 42)
+	require.NoError(t, err)
+	for _, rec := range records {
+		require.NoError(t, w.Write(rec))
+	}
+	require.NoError(t, w.Flush())
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), r.Header().Start)
+	for _, want := range records {
+		got, err := r.Next()
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(want, got), "want %v, got %v", want, got)
+	}
+	_, err = r.Next()
+	assert.Equal(t, io.EOF, err)
+
+	all, err := ReadAll(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Len(t, all, len(records))
+}
+
+func TestFileCorrupt(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, 42)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(&pb.TraceRecord{Method: "/pb.RawFileSystem/GetAttr", Request: make([]byte, 100)}))
+	require.NoError(t, w.Flush())
+	data := buf.Bytes()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: nil},
+		{name: "truncated record", data: data[:len(data)-10]},
+		{name: "huge record", data: append(append([]byte{}, data...), 0xff, 0xff, 0xff, 0xff, 0xff, 0x0f)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadAll(bytes.NewReader(tt.data))
+			assert.Error(t, err)
+			assert.NotEqual(t, io.EOF, err)
+		})
+	}
+}
@@ -0,0 +1,250 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpctrace
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+// Recorder writes every call passing through its interceptors to a trace.
+// Records are written when calls end.
+type Recorder struct {
+	start time.Time
+
+	mu  sync.Mutex
+	w   *Writer
+	err error
+}
+
+// NewRecorder starts a trace on w.
+func NewRecorder(w io.Writer) (*Recorder, error) {
+	start := time.Now()
+	tw, err := NewWriter(w, start.UnixNano())
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{start: start, w: tw}, nil
+}
+
+// Flush writes out buffered records and returns the first error the
+// Recorder ran into, after which it stopped recording.
+func (r *Recorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = r.w.Flush()
+	}
+	return r.err
+}
+
+// call is a call being recorded.
+type call struct {
+	rec *pb.TraceRecord
+	t   time.Time
+}
+
+func (r *Recorder) begin(method string) *call {
+	t := time.Now()
+	return &call{rec: &pb.TraceRecord{Method: method, Start: int64(t.Sub(r.start))}, t: t}
+}
+
+func marshal(m interface{}) []byte {
+	pm, ok := m.(proto.Message)
+	if !ok {
+		return nil
+	}
+	b, err := proto.Marshal(pm)
+	if err != nil {
+		log.Warnf("rpctrace: marshal %T: %v", m, err)
+	}
+	return b
+}
+
+func (c *call) request(m interface{})  { c.rec.Request = marshal(m) }
+func (c *call) response(m interface{}) { c.rec.Responses = append(c.rec.Responses, marshal(m)) }
+
+func (r *Recorder) end(c *call, err error) {
+	c.rec.Duration = int64(time.Since(c.t))
+	st := status.Convert(err)
+	c.rec.Code = uint32(st.Code())
+	c.rec.Message = st.Message()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return
+	}
+	if r.err = r.w.Write(c.rec); r.err != nil {
+		log.Errorf("rpctrace: stop recording: %v", r.err)
+	}
+}
+
+// endPanic records a call whose handler panicked as failed with Internal,
+// and lets the panic go on to the recovery interceptor, if any.
+func (r *Recorder) endPanic(c *call) {
+	if p := recover(); p != nil {
+		r.end(c, status.Errorf(codes.Internal, "panic: %v", p))
+		panic(p)
+	}
+}
+
+// UnaryServerInterceptor records unary calls handled by a server.
+func (r *Recorder) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		c := r.begin(info.FullMethod)
+		defer r.endPanic(c)
+		c.request(req)
+		res, err := handler(ctx, req)
+		if err == nil {
+			c.response(res)
+		}
+		r.end(c, err)
+		return res, err
+	}
+}
+
+// StreamServerInterceptor records streams handled by a server.
+func (r *Recorder) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		c := r.begin(info.FullMethod)
+		defer r.endPanic(c)
+		err := handler(srv, &serverStream{ServerStream: ss, c: c})
+		r.end(c, err)
+		return err
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	c *call
+}
+
+func (s *serverStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.c.request(m)
+	}
+	return err
+}
+
+func (s *serverStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.c.response(m)
+	}
+	return err
+}
+
+// UnaryClientInterceptor records unary calls made by a client.
+func (r *Recorder) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		c := r.begin(method)
+		c.request(req)
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if err == nil {
+			c.response(reply)
+		}
+		r.end(c, err)
+		return err
+	}
+}
+
+// StreamClientInterceptor records streams made by a client. A stream is
+// recorded once it has been read to the end or failed, or its context is
+// done, for one given up on.
+func (r *Recorder) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		c := r.begin(method)
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			r.end(c, err)
+			return nil, err
+		}
+		s := &clientStream{ClientStream: cs, r: r, c: c, done: make(chan struct{})}
+		go func() {
+			select {
+			case <-ctx.Done():
+				s.end(status.FromContextError(ctx.Err()).Err())
+			case <-s.done:
+			}
+		}()
+		return s, nil
+	}
+}
+
+type clientStream struct {
+	grpc.ClientStream
+	r    *Recorder
+	once sync.Once
+	done chan struct{}
+
+	// mu guards c, which is not added to once recorded.
+	mu    sync.Mutex
+	c     *call
+	ended bool
+}
+
+// end records the stream, the first time only.
+func (s *clientStream) end(err error) {
+	s.once.Do(func() {
+		close(s.done)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.ended = true
+		s.r.end(s.c, err)
+	})
+}
+
+func (s *clientStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		s.mu.Lock()
+		if !s.ended {
+			s.c.request(m)
+		}
+		s.mu.Unlock()
+	}
+	return err
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch err {
+	case nil:
+		s.mu.Lock()
+		if !s.ended {
+			s.c.response(m)
+		}
+		s.mu.Unlock()
+	case io.EOF:
+		s.end(nil)
+	default:
+		s.end(err)
+	}
+	return err
+}
@@ -0,0 +1,199 @@
+package rpctrace
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/chiyutianyi/grpcfuse/grpcfusetest"
+	"github.com/chiyutianyi/grpcfuse/memfs"
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+func header(nodeID uint64) fuse.InHeader {
+	return fuse.InHeader{NodeId: nodeID}
+}
+
+var content = bytes.Repeat([]byte("0123456789"), 10)
+
+// workload makes a few calls of every kind through rfs.
+func workload(t *testing.T, rfs fuse.RawFileSystem) {
+	var entry fuse.EntryOut
+	require.Equal(t, fuse.OK, rfs.Mkdir(nil, &fuse.MkdirIn{InHeader: header(fuse.FUSE_ROOT_ID), Mode: 0755}, "dir", &entry))
+	dir := entry.NodeId
+
+	var create fuse.CreateOut
+	require.Equal(t, fuse.OK, rfs.Create(nil, &fuse.CreateIn{InHeader: header(dir), Flags: syscall.O_RDWR, Mode: 0644}, "file", &create))
+	_, st := rfs.Write(nil, &fuse.WriteIn{InHeader: header(create.NodeId), Fh: create.Fh}, content)
+	require.Equal(t, fuse.OK, st)
+	res, st := rfs.Read(nil, &fuse.ReadIn{InHeader: header(create.NodeId), Fh: create.Fh, Size: 1000}, make([]byte, 1000))
+	require.Equal(t, fuse.OK, st)
+	data, _ := res.Bytes(make([]byte, 1000))
+	require.Equal(t, content, data)
+	rfs.Release(nil, &fuse.ReleaseIn{InHeader: header(create.NodeId), Fh: create.Fh})
+
+	var open fuse.OpenOut
+	require.Equal(t, fuse.OK, rfs.OpenDir(nil, &fuse.OpenIn{InHeader: header(dir)}, &open))
+	require.Equal(t, fuse.OK, rfs.ReadDir(nil, &fuse.ReadIn{InHeader: header(dir), Fh: open.Fh, Size: 4096}, fuse.NewDirEntryList(make([]byte, 4096), 0)))
+	rfs.ReleaseDir(&fuse.ReleaseIn{InHeader: header(dir), Fh: open.Fh})
+
+	var attr fuse.AttrOut
+	require.Equal(t, fuse.OK, rfs.GetAttr(nil, &fuse.GetAttrIn{InHeader: header(create.NodeId)}, &attr))
+	require.Equal(t, fuse.ENOENT, rfs.Lookup(nil, &fuse.InHeader{NodeId: dir}, "missing", &entry))
+}
+
+// record runs workload through a pair serving memfs, recording on the
+// server or on the client.
+func record(t *testing.T, client bool) []*pb.TraceRecord {
+	var buf bytes.Buffer
+	rec, err := NewRecorder(&buf)
+	require.NoError(t, err)
+
+	opts := &grpcfusetest.Options{MsgSizeThreshold: 16}
+	if client {
+		opts.DialOptions = []grpc.DialOption{
+			grpc.WithChainUnaryInterceptor(rec.UnaryClientInterceptor()),
+			grpc.WithChainStreamInterceptor(rec.StreamClientInterceptor()),
+		}
+	} else {
+		opts.ServerOptions = []grpc.ServerOption{
+			grpc.ChainUnaryInterceptor(rec.UnaryServerInterceptor()),
+			grpc.ChainStreamInterceptor(rec.StreamServerInterceptor()),
+		}
+	}
+	p := grpcfusetest.New(t, memfs.New(nil, nil), opts)
+	workload(t, p.Client)
+	require.NoError(t, p.Close())
+	require.NoError(t, rec.Flush())
+
+	records, err := ReadAll(&buf)
+	require.NoError(t, err)
+	return records
+}
+
+func TestRecorder(t *testing.T) {
+	for _, client := range []bool{false, true} {
+		name := "server"
+		if client {
+			name = "client"
+		}
+		t.Run(name, func(t *testing.T) {
+			records := record(t, client)
+			var methods []string
+			for _, rec := range records {
+				methods = append(methods, rec.Method[len("/pb.RawFileSystem/"):])
+				assert.Equal(t, uint32(codes.OK), rec.Code, rec.Method)
+				assert.GreaterOrEqual(t, rec.Duration, int64(0))
+			}
+			assert.Equal(t, []string{
+				"Mkdir", "Create", "Write", "Read", "Release",
+				"OpenDir", "ReadDir", "ReleaseDir", "GetAttr", "Lookup",
+			}, methods)
+
+			read := records[3]
+			req := &pb.ReadRequest{}
+			require.NoError(t, proto.Unmarshal(read.Request, req))
+			assert.Equal(t, uint32(1000), req.ReadIn.Size)
+			// The server sent the data in chunks of 16 bytes.
+			var data []byte
+			for _, b := range read.Responses {
+				res := &pb.ReadResponse{}
+				require.NoError(t, proto.Unmarshal(b, res))
+				data = append(data, res.Buffer...)
+			}
+			assert.Greater(t, len(read.Responses), 1)
+			assert.Equal(t, content, data)
+
+			lookup := &pb.LookupResponse{}
+			require.Len(t, records[9].Responses, 1)
+			require.NoError(t, proto.Unmarshal(records[9].Responses[0], lookup))
+			assert.Equal(t, int32(syscall.ENOENT), lookup.Status.GetCode())
+		})
+	}
+}
+
+func TestRecorderPanic(t *testing.T) {
+	var buf bytes.Buffer
+	rec, err := NewRecorder(&buf)
+	require.NoError(t, err)
+	p := grpcfusetest.New(t, memfs.New(nil, nil), &grpcfusetest.Options{
+		ServerOptions: []grpc.ServerOption{grpc.ChainUnaryInterceptor(rec.UnaryServerInterceptor())},
+	})
+	var attr fuse.AttrOut
+	// go-fuse panics on the unknown node 42.
+	p.Client.GetAttr(nil, &fuse.GetAttrIn{InHeader: header(42)}, &attr)
+	require.NoError(t, rec.Flush())
+
+	records, err := ReadAll(&buf)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, uint32(codes.Internal), records[0].Code)
+	assert.Contains(t, records[0].Message, "unknown node 42")
+}
+
+// syncBuffer is a bytes.Buffer safe to read while recorded to.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) records() ([]*pb.TraceRecord, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return ReadAll(bytes.NewReader(b.buf.Bytes()))
+}
+
+func TestRecorderAbandonedStream(t *testing.T) {
+	var buf syncBuffer
+	rec, err := NewRecorder(&buf)
+	require.NoError(t, err)
+	p := grpcfusetest.New(t, memfs.New(nil, nil), &grpcfusetest.Options{
+		MsgSizeThreshold: 16,
+		DialOptions:      []grpc.DialOption{grpc.WithChainStreamInterceptor(rec.StreamClientInterceptor())},
+	})
+	var create fuse.CreateOut
+	require.Equal(t, fuse.OK, p.Client.Create(nil, &fuse.CreateIn{InHeader: header(fuse.FUSE_ROOT_ID), Flags: syscall.O_RDWR, Mode: 0644}, "file", &create))
+	_, st := p.Client.Write(nil, &fuse.WriteIn{InHeader: header(create.NodeId), Fh: create.Fh}, content)
+	require.Equal(t, fuse.OK, st)
+
+	// Read the first chunk only, and give up on the rest.
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := pb.NewRawFileSystemClient(p.Conn).Read(ctx, &pb.ReadRequest{ReadIn: &pb.ReadIn{
+		Header: &pb.InHeader{NodeId: create.NodeId, Caller: &pb.Caller{Owner: &pb.Owner{}}},
+		Fh:     create.Fh,
+		Size:   1000,
+	}})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+	cancel()
+
+	var records []*pb.TraceRecord
+	assert.Eventually(t, func() bool {
+		if rec.Flush() != nil {
+			return false
+		}
+		records, _ = buf.records()
+		return len(records) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Len(t, records, 1)
+	assert.Equal(t, "/pb.RawFileSystem/Read", records[0].Method)
+	assert.Equal(t, uint32(codes.Canceled), records[0].Code)
+	assert.Len(t, records[0].Responses, 1)
+}
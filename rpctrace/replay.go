@@ -0,0 +1,362 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpctrace
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+// ReplayOptions tunes Replay. The zero value is usable.
+type ReplayOptions struct {
+	// Pace spaces the calls out as they were recorded; by default they
+	// are made back to back.
+	Pace bool
+	// Normalize is applied to the recorded and the replayed responses
+	// before they are compared, to hide what legitimately differs between
+	// runs. Defaults to IgnoreTimes.
+	Normalize func(proto.Message)
+	// CallOptions are passed to every call.
+	CallOptions []grpc.CallOption
+}
+
+// Divergence is a call whose outcome differs from the trace.
+type Divergence struct {
+	// Index is the position of the call in the trace.
+	Index  int
+	Method string
+	// Want and Got describe the recorded and the replayed outcome.
+	Want, Got string
+}
+
+func (d Divergence) String() string {
+	return fmt.Sprintf("#%d %s:\n  want: %s\n  got:  %s", d.Index, d.Method, d.Want, d.Got)
+}
+
+// MethodStats sums up the calls of a method.
+type MethodStats struct {
+	Calls int
+	// Recorded and Replayed are the total time the calls took.
+	Recorded, Replayed time.Duration
+}
+
+// Report is the outcome of a replay.
+type Report struct {
+	Calls       int
+	Divergences []Divergence
+	// Methods are the stats by short method name, like "Read".
+	Methods map[string]*MethodStats
+}
+
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d calls, %d divergences\n", r.Calls, len(r.Divergences))
+	names := make([]string, 0, len(r.Methods))
+	for name := range r.Methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(&b, "%-16s %8s %14s %14s\n", "method", "calls", "recorded avg", "replayed avg")
+	for _, name := range names {
+		s := r.Methods[name]
+		fmt.Fprintf(&b, "%-16s %8d %14v %14v\n", name, s.Calls,
+			s.Recorded/time.Duration(s.Calls), s.Replayed/time.Duration(s.Calls))
+	}
+	for _, d := range r.Divergences {
+		fmt.Fprintln(&b, d)
+	}
+	return b.String()
+}
+
+// IgnoreTimes clears the access, modification and change times in m,
+// which never match between runs.
+func IgnoreTimes(m proto.Message) {
+	ClearFields("atime", "mtime", "ctime", "atimensec", "mtimensec", "ctimensec")(m)
+}
+
+// ClearFields returns a Normalize function clearing the fields with these
+// names wherever they are in a message.
+func ClearFields(names ...string) func(proto.Message) {
+	set := map[protoreflect.Name]bool{}
+	for _, name := range names {
+		set[protoreflect.Name(name)] = true
+	}
+	var clear func(m protoreflect.Message)
+	clear = func(m protoreflect.Message) {
+		m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+			switch {
+			case set[fd.Name()]:
+				m.Clear(fd)
+			case fd.IsList() && fd.Message() != nil:
+				l := v.List()
+				for i := 0; i < l.Len(); i++ {
+					clear(l.Get(i).Message())
+				}
+			case !fd.IsList() && !fd.IsMap() && fd.Message() != nil:
+				clear(v.Message())
+			}
+			return true
+		})
+	}
+	return func(m proto.Message) { clear(m.ProtoReflect()) }
+}
+
+// method is what Replay needs to know about a traced method.
+type method struct {
+	name     string
+	in, out  protoreflect.MessageType
+	isStream bool
+}
+
+func lookupMethod(fullName string) (*method, error) {
+	// fullName is "/pb.RawFileSystem/Read".
+	parts := strings.Split(strings.TrimPrefix(fullName, "/"), "/")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("bad method %q", fullName)
+	}
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(parts[0]))
+	if err != nil {
+		return nil, fmt.Errorf("method %q: %w", fullName, err)
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("method %q: %s is not a service", fullName, parts[0])
+	}
+	md := sd.Methods().ByName(protoreflect.Name(parts[1]))
+	if md == nil {
+		return nil, fmt.Errorf("unknown method %q", fullName)
+	}
+	in, err := protoregistry.GlobalTypes.FindMessageByName(md.Input().FullName())
+	if err != nil {
+		return nil, err
+	}
+	out, err := protoregistry.GlobalTypes.FindMessageByName(md.Output().FullName())
+	if err != nil {
+		return nil, err
+	}
+	return &method{name: parts[1], in: in, out: out, isStream: md.IsStreamingServer()}, nil
+}
+
+func (m *method) request(rec *pb.TraceRecord) (proto.Message, error) {
+	req := m.in.New().Interface()
+	if err := proto.Unmarshal(rec.Request, req); err != nil {
+		return nil, fmt.Errorf("%s request: %w", rec.Method, err)
+	}
+	return req, nil
+}
+
+// outcome is the status and the responses of a call, the responses of a
+// stream merged into one so that servers sending different sized chunks
+// agree.
+type outcome struct {
+	st  *status.Status
+	res proto.Message
+}
+
+func (m *method) recorded(rec *pb.TraceRecord) (*outcome, error) {
+	o := &outcome{st: status.New(codes.Code(rec.Code), rec.Message)}
+	for _, b := range rec.Responses {
+		res := m.out.New().Interface()
+		if err := proto.Unmarshal(b, res); err != nil {
+			return nil, fmt.Errorf("%s response: %w", rec.Method, err)
+		}
+		o.add(res)
+	}
+	return o, nil
+}
+
+func (o *outcome) add(res proto.Message) {
+	if o.res == nil {
+		o.res = res
+		return
+	}
+	appendMessage(o.res.ProtoReflect(), res.ProtoReflect())
+}
+
+// appendMessage merges src into dst like proto.Merge, except that bytes
+// are appended, as they are chunks of one buffer.
+func appendMessage(dst, src protoreflect.Message) {
+	src.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsList():
+			l := dst.Mutable(fd).List()
+			for i := 0; i < v.List().Len(); i++ {
+				l.Append(v.List().Get(i))
+			}
+		case fd.IsMap():
+			m := dst.Mutable(fd).Map()
+			v.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+				m.Set(k, v)
+				return true
+			})
+		case fd.Kind() == protoreflect.BytesKind:
+			dst.Set(fd, protoreflect.ValueOfBytes(append(dst.Get(fd).Bytes(), v.Bytes()...)))
+		case fd.Message() != nil:
+			appendMessage(dst.Mutable(fd).Message(), v.Message())
+		default:
+			dst.Set(fd, v)
+		}
+		return true
+	})
+}
+
+func (o *outcome) String() string {
+	if o.st.Code() != codes.OK {
+		return fmt.Sprintf("code %v: %s", o.st.Code(), o.st.Message())
+	}
+	if o.res == nil {
+		return "no response"
+	}
+	return prototext.MarshalOptions{}.Format(o.res)
+}
+
+// call replays one call on conn.
+func (m *method) call(ctx context.Context, conn grpc.ClientConnInterface, fullName string, req proto.Message, opts []grpc.CallOption) *outcome {
+	o := &outcome{}
+	if !m.isStream {
+		res := m.out.New().Interface()
+		err := conn.Invoke(ctx, fullName, req, res, opts...)
+		if o.st = status.Convert(err); err == nil {
+			o.res = res
+		}
+		return o
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, fullName, opts...)
+	if err == nil {
+		err = stream.SendMsg(req)
+	}
+	if err == nil {
+		err = stream.CloseSend()
+	}
+	for err == nil {
+		res := m.out.New().Interface()
+		if err = stream.RecvMsg(res); err == nil {
+			o.add(res)
+		}
+	}
+	if err == io.EOF {
+		err = nil
+	}
+	o.st = status.Convert(err)
+	return o
+}
+
+// diverges compares a replayed outcome with the recorded one.
+func diverges(want, got *outcome, normalize func(proto.Message)) bool {
+	if want.st.Code() != got.st.Code() {
+		return true
+	}
+	if want.st.Code() != codes.OK {
+		return false
+	}
+	if (want.res == nil) != (got.res == nil) {
+		return true
+	}
+	if want.res == nil {
+		return false
+	}
+	normalize(want.res)
+	normalize(got.res)
+	return !proto.Equal(want.res, got.res)
+}
+
+// Replay makes the calls of a trace on conn, in the order they started,
+// and reports those whose outcome differs. conn usually leads to a
+// fuse2grpc server whose file system is in the state the recording began
+// in, so that node IDs and file handles come out the same.
+func Replay(ctx context.Context, conn grpc.ClientConnInterface, records []*pb.TraceRecord, opts *ReplayOptions) (*Report, error) {
+	if opts == nil {
+		opts = &ReplayOptions{}
+	}
+	normalize := opts.Normalize
+	if normalize == nil {
+		normalize = IgnoreTimes
+	}
+
+	order := make([]int, len(records))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return records[order[a]].Start < records[order[b]].Start })
+
+	report := &Report{Methods: map[string]*MethodStats{}}
+	start := time.Now()
+	for _, i := range order {
+		rec := records[i]
+		m, err := lookupMethod(rec.Method)
+		if err != nil {
+			return report, err
+		}
+		req, err := m.request(rec)
+		if err != nil {
+			return report, err
+		}
+		want, err := m.recorded(rec)
+		if err != nil {
+			return report, err
+		}
+
+		if opts.Pace {
+			if d := time.Duration(rec.Start) - time.Since(start); d > 0 {
+				select {
+				case <-time.After(d):
+				case <-ctx.Done():
+					return report, ctx.Err()
+				}
+			}
+		}
+		t := time.Now()
+		got := m.call(ctx, conn, rec.Method, req, opts.CallOptions)
+		took := time.Since(t)
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		report.Calls++
+		stats := report.Methods[m.name]
+		if stats == nil {
+			stats = &MethodStats{}
+			report.Methods[m.name] = stats
+		}
+		stats.Calls++
+		stats.Recorded += time.Duration(rec.Duration)
+		stats.Replayed += took
+		if diverges(want, got, normalize) {
+			report.Divergences = append(report.Divergences, Divergence{
+				Index: i, Method: m.name, Want: want.String(), Got: got.String(),
+			})
+		}
+	}
+	return report, nil
+}
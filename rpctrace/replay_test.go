@@ -0,0 +1,105 @@
+package rpctrace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/chiyutianyi/grpcfuse/grpcfusetest"
+	"github.com/chiyutianyi/grpcfuse/memfs"
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+func TestReplay(t *testing.T) {
+	records := record(t, false)
+
+	tests := []struct {
+		name string
+		// prepare changes the backend before the replay.
+		prepare   func(t *testing.T, rfs fuse.RawFileSystem)
+		threshold int
+		opts      *ReplayOptions
+		diverged  []string
+	}{
+		{name: "same backend"},
+		{name: "other chunking", threshold: 1000},
+		{name: "paced", opts: &ReplayOptions{Pace: true}},
+		{
+			name: "changed backend",
+			prepare: func(t *testing.T, rfs fuse.RawFileSystem) {
+				var out fuse.EntryOut
+				require.Equal(t, fuse.OK, rfs.Mkdir(nil, &fuse.MkdirIn{InHeader: header(fuse.FUSE_ROOT_ID), Mode: 0755}, "other", &out))
+			},
+			// "other" took the node ID of "dir", so calls on the nodes of
+			// the trace fail or answer differently.
+			diverged: []string{"Mkdir", "Create", "Write", "Read", "Release", "OpenDir", "ReadDir", "GetAttr"},
+		},
+		{
+			name:     "no normalization",
+			opts:     &ReplayOptions{Normalize: func(proto.Message) {}},
+			diverged: []string{"Mkdir", "Create", "GetAttr"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := memfs.New(nil, nil)
+			if tt.prepare != nil {
+				tt.prepare(t, backend)
+			}
+			p := grpcfusetest.New(t, backend, &grpcfusetest.Options{MsgSizeThreshold: tt.threshold})
+
+			report, err := Replay(context.Background(), p.Conn, records, tt.opts)
+			require.NoError(t, err)
+			assert.Equal(t, len(records), report.Calls)
+			var diverged []string
+			for _, d := range report.Divergences {
+				diverged = append(diverged, d.Method)
+			}
+			assert.Equal(t, tt.diverged, diverged, "%v", report)
+			assert.Equal(t, 1, report.Methods["Read"].Calls)
+		})
+	}
+}
+
+func TestReplayBadTrace(t *testing.T) {
+	p := grpcfusetest.New(t, memfs.New(nil, nil), nil)
+	tests := []struct {
+		name string
+		rec  *pb.TraceRecord
+	}{
+		{name: "bad method", rec: &pb.TraceRecord{Method: "GetAttr"}},
+		{name: "unknown service", rec: &pb.TraceRecord{Method: "/pb.Nope/GetAttr"}},
+		{name: "unknown method", rec: &pb.TraceRecord{Method: "/pb.RawFileSystem/Nope"}},
+		{name: "bad request", rec: &pb.TraceRecord{Method: "/pb.RawFileSystem/GetAttr", Request: []byte{0xff}}},
+		{name: "bad response", rec: &pb.TraceRecord{Method: "/pb.RawFileSystem/GetAttr", Responses: [][]byte{{0xff}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Replay(context.Background(), p.Conn, []*pb.TraceRecord{tt.rec}, nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestReplayClient(t *testing.T) {
+	records := record(t, true)
+	report, err := ReplayClient(context.Background(), records, nil)
+	require.NoError(t, err)
+	assert.Equal(t, len(records), report.Calls)
+	assert.Empty(t, report.Divergences, "%v", report)
+
+}
+
+func TestIgnoreTimes(t *testing.T) {
+	res := &pb.GetAttrResponse{AttrOut: &pb.AttrOut{Attr: &pb.Attr{Ino: 7, Atime: 1, Mtimensec: 2, Ctime: 3}}}
+	IgnoreTimes(res)
+	assert.True(t, proto.Equal(&pb.GetAttrResponse{AttrOut: &pb.AttrOut{Attr: &pb.Attr{Ino: 7}}}, res), "%v", res)
+
+	dirs := &pb.ReadDirResponse{Entries: []*pb.DirEntry{{Name: []byte("a")}}}
+	IgnoreTimes(dirs)
+	assert.Len(t, dirs.Entries, 1)
+}
@@ -0,0 +1,335 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package shm
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/chiyutianyi/grpcfuse/pb"
+)
+
+// quarantine is how long a slot whose call failed is kept out of use: the
+// server may still be working on a call the client gave up on.
+const quarantine = time.Minute
+
+var errBadReply = errors.New("shm: bad reply")
+
+// Stats counts the payloads of a Client.
+type Stats struct {
+	// Shared went through shared memory.
+	Shared int64
+	// Inline were sent in gRPC messages.
+	Inline int64
+}
+
+// Client is a memfd registered with a Server, and the interceptors that
+// use it.
+type Client struct {
+	opts Options
+	path string
+	fd   int
+	mem  []byte
+	// free holds the offsets of the free slots.
+	free chan int
+
+	shared, inline int64
+
+	mu    sync.Mutex
+	conn  net.Conn
+	token string
+	// disabled stops using shared memory, for a server without the
+	// interceptors.
+	disabled bool
+}
+
+// Dial makes a memfd and registers it with the Server listening on the
+// Unix socket path.
+func Dial(path string, opts *Options) (*Client, error) {
+	o := opts.withDefaults()
+	if o.SlotSize <= 0 || o.Size < o.SlotSize {
+		return nil, fmt.Errorf("shm: size %d holds no slot of %d", o.Size, o.SlotSize)
+	}
+	fd, mem, err := newMemfd(o.Size)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{opts: o, path: path, fd: fd, mem: mem, free: make(chan int, o.Size/o.SlotSize)}
+	for off := 0; off+o.SlotSize <= o.Size; off += o.SlotSize {
+		c.free <- off
+	}
+	if err := c.register(); err != nil {
+		unmap(mem)
+		closeFd(fd)
+		return nil, err
+	}
+	return c, nil
+}
+
+// register passes the memfd to the server; c.mu is held or c not shared
+// yet.
+func (c *Client) register() error {
+	conn, err := net.DialTimeout("unix", c.path, registerTimeout)
+	if err != nil {
+		return fmt.Errorf("shm: %v", err)
+	}
+	conn.SetDeadline(time.Now().Add(registerTimeout))
+	if err := sendFd(conn.(*net.UnixConn), c.fd); err != nil {
+		conn.Close()
+		return fmt.Errorf("shm: %s: %v", c.path, err)
+	}
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("shm: %s: %v", c.path, err)
+	}
+	fields := strings.Fields(line)
+	if len(fields) != 2 || fields[0] != "ok" {
+		conn.Close()
+		return fmt.Errorf("%s: %s", c.path, strings.TrimSpace(strings.TrimPrefix(line, "error ")))
+	}
+	conn.SetDeadline(time.Time{})
+	if c.conn != nil {
+		c.conn.Close()
+	}
+	c.conn, c.token = conn, fields[1]
+	return nil
+}
+
+// session returns the token to send, or false if shared memory is off.
+func (c *Client) session() (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token, !c.disabled
+}
+
+// reregister registers the memfd again when the server no longer knows
+// token, as after a restart, and turns shared memory off if it cannot.
+func (c *Client) reregister(token string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.disabled {
+		return false
+	}
+	if c.token != token {
+		return true
+	}
+	if err := c.register(); err != nil {
+		log.Warnf("shm: %v, sending data inline", err)
+		c.disabled = true
+		return false
+	}
+	return true
+}
+
+func (c *Client) disable(reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.disabled {
+		log.Warnf("shm: %s, sending data inline", reason)
+		c.disabled = true
+	}
+}
+
+// slot takes a free slot, if any.
+func (c *Client) slot() (int, bool) {
+	select {
+	case off := <-c.free:
+		return off, true
+	default:
+		return 0, false
+	}
+}
+
+// release gives a slot back, after the quarantine if its call failed.
+func (c *Client) release(off int, err error) {
+	if err == nil {
+		c.free <- off
+		return
+	}
+	time.AfterFunc(quarantine, func() { c.free <- off })
+}
+
+// Stats returns the payloads sent so far.
+func (c *Client) Stats() Stats {
+	return Stats{Shared: atomic.LoadInt64(&c.shared), Inline: atomic.LoadInt64(&c.inline)}
+}
+
+// Close unregisters and frees the memfd. The connection using the
+// interceptors must be closed first.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn != nil {
+		c.conn.Close()
+	}
+	c.disabled = true
+	if err := unmap(c.mem); err != nil {
+		return err
+	}
+	return closeFd(c.fd)
+}
+
+// sessionLost tells whether err is the server not knowing the session.
+func sessionLost(err error) bool {
+	st, _ := status.FromError(err)
+	return st.Code() == codes.FailedPrecondition && strings.HasPrefix(st.Message(), "shm:")
+}
+
+// UnaryClientInterceptor sends the data of Write calls through shared
+// memory.
+func (c *Client) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		w, ok := req.(*pb.WriteRequest)
+		if !ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		if len(w.Data) >= c.opts.MinSize && len(w.Data) <= c.opts.SlotSize {
+			for retried := false; ; retried = true {
+				token, ok := c.session()
+				if !ok {
+					break
+				}
+				off, ok := c.slot()
+				if !ok {
+					break
+				}
+				r := region{off: off, len: len(w.Data)}
+				copy(c.mem[off:], w.Data)
+				data := w.Data
+				w.Data = nil
+				var header metadata.MD
+				err := invoker(metadata.AppendToOutgoingContext(ctx, sessionKey, token, writeKey, r.String()),
+					method, req, reply, cc, append(opts, grpc.Header(&header))...)
+				w.Data = data
+				if sessionLost(err) {
+					// The server never looked at the slot.
+					c.release(off, nil)
+					if !retried && c.reregister(token) {
+						continue
+					}
+					break
+				}
+				c.release(off, err)
+				if err == nil && len(header.Get(doneKey)) == 0 {
+					// The server wrote nothing; write again inline.
+					c.disable(fmt.Sprintf("%s does not take writes from shared memory", cc.Target()))
+					break
+				}
+				atomic.AddInt64(&c.shared, 1)
+				return err
+			}
+		}
+		atomic.AddInt64(&c.inline, 1)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor has the data of Read calls put in shared
+// memory. The size of a read is not known when the stream starts, so
+// every read takes a slot; the server answers those that do not fit
+// inline.
+func (c *Client) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if method != readMethod {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+		token, ok := c.session()
+		if !ok {
+			atomic.AddInt64(&c.inline, 1)
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+		off, ok := c.slot()
+		if !ok {
+			atomic.AddInt64(&c.inline, 1)
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+		r := region{off: off, len: c.opts.SlotSize}
+		stream, err := streamer(metadata.AppendToOutgoingContext(ctx, sessionKey, token, readKey, r.String()), desc, cc, method, opts...)
+		if err != nil {
+			c.release(off, nil)
+			return nil, err
+		}
+		return &clientReadStream{ClientStream: stream, c: c, off: off}, nil
+	}
+}
+
+// clientReadStream fills the data of the reply to a Read from shared
+// memory, and frees the slot when the stream ends.
+type clientReadStream struct {
+	grpc.ClientStream
+	c        *Client
+	off      int
+	released bool
+}
+
+func (s *clientReadStream) release(err error) {
+	if !s.released {
+		s.released = true
+		s.c.release(s.off, err)
+	}
+}
+
+func (s *clientReadStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == io.EOF {
+		s.release(nil)
+		return err
+	}
+	if err != nil {
+		s.release(err)
+		return err
+	}
+	res, ok := m.(*pb.ReadResponse)
+	if !ok || s.released {
+		return nil
+	}
+	if res.Status.GetCode() != 0 {
+		// An error comes before any data.
+		s.release(nil)
+		return nil
+	}
+	header, err := s.ClientStream.Header()
+	v := header.Get(doneKey)
+	if err != nil || len(v) != 1 {
+		atomic.AddInt64(&s.c.inline, 1)
+		return nil
+	}
+	n, err := strconv.Atoi(v[0])
+	if err != nil || n < 0 || n > s.c.opts.SlotSize || len(res.Buffer) > 0 {
+		s.release(errBadReply)
+		return status.Errorf(codes.Internal, "shm: bad reply of %d bytes and length %q", len(res.Buffer), v[0])
+	}
+	res.Buffer = append([]byte(nil), s.c.mem[s.off:s.off+n]...)
+	atomic.AddInt64(&s.c.shared, 1)
+	return nil
+}
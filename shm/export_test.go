@@ -0,0 +1,20 @@
+package shm
+
+// FreeSlots returns how many slots are free.
+func (c *Client) FreeSlots() int {
+	return len(c.free)
+}
+
+// Session returns the session token of c, and whether shared memory is
+// on.
+func (c *Client) Session() (string, bool) {
+	return c.session()
+}
+
+// Forget drops a session as if the server had restarted.
+func (s *Server) Forget(token string) {
+	s.mu.Lock()
+	sess := s.sessions[token]
+	s.mu.Unlock()
+	s.drop(sess)
+}
@@ -0,0 +1,47 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package shm
+
+import (
+	"net"
+)
+
+// There is no memfd on macOS.
+
+func newMemfd(size int) (int, []byte, error) {
+	return -1, nil, errUnsupported
+}
+
+func mapMemfd(fd int) ([]byte, error) {
+	return nil, errUnsupported
+}
+
+func unmap(mem []byte) error {
+	return errUnsupported
+}
+
+func closeFd(fd int) error {
+	return errUnsupported
+}
+
+func sendFd(conn *net.UnixConn, fd int) error {
+	return errUnsupported
+}
+
+func recvFd(conn *net.UnixConn) (int, error) {
+	return -1, errUnsupported
+}
@@ -0,0 +1,30 @@
+package shm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestMapMemfd(t *testing.T) {
+	// A memfd that can shrink is refused.
+	fd, err := unix.MemfdCreate("test", 0)
+	require.NoError(t, err)
+	defer unix.Close(fd)
+	require.NoError(t, unix.Ftruncate(fd, 4096))
+	_, err = mapMemfd(fd)
+	assert.Error(t, err)
+
+	fd, mem, err := newMemfd(4096)
+	require.NoError(t, err)
+	defer closeFd(fd)
+	defer unmap(mem)
+	got, err := mapMemfd(fd)
+	require.NoError(t, err)
+	got[0] = 42
+	assert.Equal(t, byte(42), mem[0])
+	assert.Error(t, unix.Ftruncate(fd, 0))
+	assert.NoError(t, unmap(got))
+}
@@ -0,0 +1,327 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package shm
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/chiyutianyi/grpcfuse/pb"
+	"github.com/chiyutianyi/grpcfuse/peercred"
+)
+
+// registerTimeout bounds passing the memfd.
+const registerTimeout = 5 * time.Second
+
+// session is a memfd registered by a client. It is unmapped when the
+// client hangs up, once the calls using it are done.
+type session struct {
+	token string
+	cred  *peercred.Cred
+
+	mu sync.RWMutex
+	// mem is nil once unmapped.
+	mem []byte
+}
+
+// Server maps the memfds of clients and reads and writes their payloads
+// there. The zero value is not usable; use NewServer.
+type Server struct {
+	mu       sync.Mutex
+	sessions map[string]*session
+	conns    map[net.Conn]bool
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+// NewServer returns a Server without sessions.
+func NewServer() *Server {
+	return &Server{
+		sessions: map[string]*session{},
+		conns:    map[net.Conn]bool{},
+	}
+}
+
+// Serve takes the memfds of the clients connecting to l, a Unix socket,
+// until l is closed.
+func (s *Server) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		if !s.track(conn) {
+			conn.Close()
+			return nil
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) track(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.conns[conn] = true
+	s.wg.Add(1)
+	return true
+}
+
+func (s *Server) untrack(conn net.Conn) {
+	s.mu.Lock()
+	delete(s.conns, conn)
+	s.mu.Unlock()
+	conn.Close()
+	s.wg.Done()
+}
+
+// handle registers the memfd of a client. The session lasts as long as
+// the connection.
+func (s *Server) handle(conn net.Conn) {
+	defer s.untrack(conn)
+	uc, ok := conn.(*net.UnixConn)
+	if !ok {
+		return
+	}
+	sess, err := s.register(uc)
+	if err != nil {
+		log.Warnf("shm: %v", err)
+		fmt.Fprintf(conn, "error %v\n", err)
+		return
+	}
+	defer s.drop(sess)
+	if _, err := fmt.Fprintf(conn, "ok %s\n", sess.token); err != nil {
+		return
+	}
+	conn.SetDeadline(time.Time{})
+	io.Copy(ioutil.Discard, conn)
+}
+
+func (s *Server) register(conn *net.UnixConn) (*session, error) {
+	conn.SetDeadline(time.Now().Add(registerTimeout))
+	cred, err := peercred.FromConn(conn)
+	if err != nil {
+		return nil, err
+	}
+	fd, err := recvFd(conn)
+	if err != nil {
+		return nil, fmt.Errorf("shm: %v: %v", cred, err)
+	}
+	defer closeFd(fd)
+	mem, err := mapMemfd(fd)
+	if err != nil {
+		return nil, fmt.Errorf("%v (%v)", err, cred)
+	}
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		unmap(mem)
+		return nil, err
+	}
+	sess := &session{token: hex.EncodeToString(token), cred: cred, mem: mem}
+	s.mu.Lock()
+	s.sessions[sess.token] = sess
+	s.mu.Unlock()
+	log.Debugf("shm: session of %v, %d bytes", cred, len(mem))
+	return sess, nil
+}
+
+func (s *Server) drop(sess *session) {
+	s.mu.Lock()
+	delete(s.sessions, sess.token)
+	s.mu.Unlock()
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	if sess.mem == nil {
+		return
+	}
+	if err := unmap(sess.mem); err != nil {
+		log.Errorf("shm: %v", err)
+	}
+	sess.mem = nil
+	log.Debugf("shm: session of %v closed", sess.cred)
+}
+
+// Close ends all sessions. The listeners given to Serve are left to
+// their owner.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return nil
+}
+
+// session returns the session a call names, read-locked. Calls over a
+// connection with peer credentials must come from the user who
+// registered the memfd.
+func (s *Server) session(ctx context.Context, md metadata.MD) (*session, error) {
+	v := md.Get(sessionKey)
+	if len(v) != 1 {
+		return nil, status.Error(codes.FailedPrecondition, "shm: no session")
+	}
+	s.mu.Lock()
+	sess := s.sessions[v[0]]
+	s.mu.Unlock()
+	if sess == nil {
+		return nil, status.Error(codes.FailedPrecondition, "shm: unknown session")
+	}
+	if cred, ok := peercred.FromContext(ctx); ok && cred.Uid != sess.cred.Uid {
+		return nil, status.Errorf(codes.PermissionDenied, "shm: session of uid %d used by uid %d", sess.cred.Uid, cred.Uid)
+	}
+	sess.mu.RLock()
+	if sess.mem == nil {
+		sess.mu.RUnlock()
+		return nil, status.Error(codes.FailedPrecondition, "shm: session closed")
+	}
+	return sess, nil
+}
+
+// UnaryServerInterceptor takes the data of Write calls from shared
+// memory. It must come before the interceptors that look at the data.
+func (s *Server) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		w, ok := req.(*pb.WriteRequest)
+		if !ok {
+			return handler(ctx, req)
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		v := md.Get(writeKey)
+		if len(v) == 0 {
+			return handler(ctx, req)
+		}
+		r, err := parseRegion(v[0])
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		sess, err := s.session(ctx, md)
+		if err != nil {
+			return nil, err
+		}
+		defer sess.mu.RUnlock()
+		data, err := r.in(sess.mem)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if err := grpc.SetHeader(ctx, metadata.Pairs(doneKey, "0")); err != nil {
+			return nil, err
+		}
+		// The backend writes straight from shared memory; the client does
+		// not touch the slot until the reply.
+		w.Data = data
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor puts the data of Read replies in shared
+// memory. Reads that do not fit, or name a session that is gone, are
+// answered inline.
+func (s *Server) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if info.FullMethod != readMethod {
+			return handler(srv, ss)
+		}
+		md, _ := metadata.FromIncomingContext(ss.Context())
+		v := md.Get(readKey)
+		if len(v) == 0 {
+			return handler(srv, ss)
+		}
+		r, err := parseRegion(v[0])
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		sess, err := s.session(ss.Context(), md)
+		if err != nil {
+			log.Debugf("shm: %v, reading inline", err)
+			return handler(srv, ss)
+		}
+		defer sess.mu.RUnlock()
+		dst, err := r.in(sess.mem)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		rs := &serverReadStream{ServerStream: ss, dst: dst}
+		if err := handler(srv, rs); err != nil {
+			return err
+		}
+		return rs.flush()
+	}
+}
+
+// serverReadStream holds back the data of Read replies, copying it to
+// dst, and sends a single reply without data at the end.
+type serverReadStream struct {
+	grpc.ServerStream
+	dst []byte
+	n   int
+	// held tells that data was copied to dst and not sent.
+	held bool
+	// inline sends the replies as they come, once dst is too small.
+	inline bool
+}
+
+func (rs *serverReadStream) SendMsg(m interface{}) error {
+	res, ok := m.(*pb.ReadResponse)
+	if !ok || rs.inline || res.Status.GetCode() != 0 {
+		return rs.ServerStream.SendMsg(m)
+	}
+	if len(res.Buffer) > len(rs.dst)-rs.n {
+		rs.inline = true
+		if rs.held {
+			if err := rs.ServerStream.SendMsg(&pb.ReadResponse{Status: &pb.Status{}, Buffer: rs.dst[:rs.n]}); err != nil {
+				return err
+			}
+		}
+		return rs.ServerStream.SendMsg(m)
+	}
+	rs.n += copy(rs.dst[rs.n:], res.Buffer)
+	rs.held = true
+	return nil
+}
+
+func (rs *serverReadStream) flush() error {
+	if !rs.held || rs.inline {
+		return nil
+	}
+	if err := rs.SetHeader(metadata.Pairs(doneKey, strconv.Itoa(rs.n))); err != nil {
+		return err
+	}
+	return rs.ServerStream.SendMsg(&pb.ReadResponse{Status: &pb.Status{}})
+}
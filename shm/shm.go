@@ -0,0 +1,129 @@
+/*
+ * Copyright 2022 Han Xin, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package shm moves the data of Read and Write calls through shared
+// memory when the client and the fuse2grpc server are on the same host.
+// The client makes a memfd, passes it to the server over a Unix socket
+// next to the gRPC one, and from then on sends in gRPC metadata only
+// where in the memfd a payload is:
+//
+//	// Server.
+//	srv := shm.NewServer()
+//	go srv.Serve(shmListener)
+//	server := grpc.NewServer(
+//		grpc.ChainUnaryInterceptor(srv.UnaryServerInterceptor()),
+//		grpc.ChainStreamInterceptor(srv.StreamServerInterceptor()))
+//
+//	// Client.
+//	c, err := shm.Dial("/run/grpcfuse.sock.shm", nil)
+//	conn, err := grpc.Dial("unix:/run/grpcfuse.sock", grpc.WithInsecure(),
+//		grpc.WithChainUnaryInterceptor(c.UnaryClientInterceptor()),
+//		grpc.WithChainStreamInterceptor(c.StreamClientInterceptor()))
+//
+// The memfd is cut in slots, one per call in flight. Payloads that are
+// small, larger than a slot, or that find no free slot are sent inline
+// as usual, and so is everything when the server has no interceptors.
+// Shared memory needs Linux.
+package shm
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Metadata keys of the calls using shared memory.
+const (
+	// sessionKey is the session the memfd was registered as.
+	sessionKey = "grpcfuse-shm-session"
+	// writeKey is "offset:length" of the data of a WriteRequest.
+	writeKey = "grpcfuse-shm-write"
+	// readKey is "offset:length" of where to put the data of a Read.
+	readKey = "grpcfuse-shm-read"
+	// doneKey is set in the header of replies whose data is in shared
+	// memory: the length of the data of a Read, and "0" for a Write.
+	doneKey = "grpcfuse-shm-done"
+)
+
+const (
+	writeMethod = "/pb.RawFileSystem/Write"
+	readMethod  = "/pb.RawFileSystem/Read"
+)
+
+var errUnsupported = errors.New("shm: shared memory is not supported on this system")
+
+// Options sizes the memfd of a Client.
+type Options struct {
+	// Size is the size of the memfd, 64MiB if zero.
+	Size int
+	// SlotSize is the largest payload sent through shared memory, 1MiB if
+	// zero.
+	SlotSize int
+	// MinSize is the smallest payload sent through shared memory, 16KiB
+	// if zero; copying smaller ones inline costs less.
+	MinSize int
+}
+
+func (o *Options) withDefaults() Options {
+	opts := Options{}
+	if o != nil {
+		opts = *o
+	}
+	if opts.Size == 0 {
+		opts.Size = 64 << 20
+	}
+	if opts.SlotSize == 0 {
+		opts.SlotSize = 1 << 20
+	}
+	if opts.MinSize == 0 {
+		opts.MinSize = 16 << 10
+	}
+	return opts
+}
+
+// region is a part of the memfd.
+type region struct {
+	off, len int
+}
+
+func (r region) String() string {
+	return fmt.Sprintf("%d:%d", r.off, r.len)
+}
+
+func parseRegion(s string) (region, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 {
+		return region{}, fmt.Errorf("shm: bad region %q", s)
+	}
+	off, err := strconv.Atoi(parts[0])
+	if err != nil || off < 0 {
+		return region{}, fmt.Errorf("shm: bad region %q", s)
+	}
+	n, err := strconv.Atoi(parts[1])
+	if err != nil || n < 0 {
+		return region{}, fmt.Errorf("shm: bad region %q", s)
+	}
+	return region{off: off, len: n}, nil
+}
+
+// in returns the bytes of r in mem.
+func (r region) in(mem []byte) ([]byte, error) {
+	if r.off > len(mem) || r.len > len(mem)-r.off {
+		return nil, fmt.Errorf("shm: region %v out of %d bytes", r, len(mem))
+	}
+	return mem[r.off : r.off+r.len : r.off+r.len], nil
+}
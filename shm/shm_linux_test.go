@@ -0,0 +1,213 @@
+package shm_test
+
+import (
+	"bytes"
+	"net"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/chiyutianyi/grpcfuse/grpcfusetest"
+	"github.com/chiyutianyi/grpcfuse/memfs"
+	"github.com/chiyutianyi/grpcfuse/peercred"
+	"github.com/chiyutianyi/grpcfuse/shm"
+)
+
+type fixture struct {
+	pair   *grpcfusetest.Pair
+	client *shm.Client
+	server *shm.Server
+	path   string
+	node   uint64
+	fh     uint64
+}
+
+// newFixture serves memfs with its data in shared memory, on a server
+// with the interceptors if intercept is set.
+func newFixture(t *testing.T, opts *shm.Options, intercept bool) *fixture {
+	dir := t.TempDir()
+	f := &fixture{server: shm.NewServer(), path: filepath.Join(dir, "sock.shm")}
+	l, err := net.Listen("unix", f.path)
+	require.NoError(t, err)
+	go f.server.Serve(l)
+	t.Cleanup(func() {
+		l.Close()
+		f.server.Close()
+	})
+
+	f.client, err = shm.Dial(f.path, opts)
+	require.NoError(t, err)
+	t.Cleanup(func() { f.client.Close() })
+
+	grpcListener, err := net.Listen("unix", filepath.Join(dir, "sock"))
+	require.NoError(t, err)
+	popts := &grpcfusetest.Options{
+		Listener:         grpcListener,
+		MsgSizeThreshold: 64 << 10,
+		ServerOptions:    []grpc.ServerOption{grpc.Creds(peercred.NewCredentials(nil))},
+		DialOptions: []grpc.DialOption{
+			grpc.WithChainUnaryInterceptor(f.client.UnaryClientInterceptor()),
+			grpc.WithChainStreamInterceptor(f.client.StreamClientInterceptor()),
+		},
+	}
+	if intercept {
+		popts.ServerOptions = append(popts.ServerOptions,
+			grpc.ChainUnaryInterceptor(f.server.UnaryServerInterceptor()),
+			grpc.ChainStreamInterceptor(f.server.StreamServerInterceptor()))
+	}
+	f.pair = grpcfusetest.New(t, memfs.New(nil, nil), popts)
+
+	var out fuse.CreateOut
+	require.Equal(t, fuse.OK, f.pair.Client.Create(nil, &fuse.CreateIn{InHeader: fuse.InHeader{NodeId: fuse.FUSE_ROOT_ID}, Flags: syscall.O_RDWR, Mode: 0644}, "file", &out))
+	f.node, f.fh = out.NodeId, out.Fh
+	return f
+}
+
+func (f *fixture) write(t *testing.T, off uint64, data []byte) {
+	t.Helper()
+	n, st := f.pair.Client.Write(nil, &fuse.WriteIn{InHeader: fuse.InHeader{NodeId: f.node}, Fh: f.fh, Offset: off, Size: uint32(len(data))}, data)
+	require.Equal(t, fuse.OK, st)
+	require.Equal(t, uint32(len(data)), n)
+}
+
+func (f *fixture) read(t *testing.T, off uint64, size int) []byte {
+	t.Helper()
+	buf := make([]byte, size)
+	res, st := f.pair.Client.Read(nil, &fuse.ReadIn{InHeader: fuse.InHeader{NodeId: f.node}, Fh: f.fh, Offset: off, Size: uint32(size)}, buf)
+	require.Equal(t, fuse.OK, st)
+	data, st := res.Bytes(buf)
+	require.Equal(t, fuse.OK, st)
+	return data
+}
+
+func pattern(n int) []byte {
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	return data
+}
+
+func TestReadWrite(t *testing.T) {
+	f := newFixture(t, &shm.Options{Size: 1 << 20, SlotSize: 256 << 10, MinSize: 1024}, true)
+	data := pattern(512 << 10)
+
+	tests := []struct {
+		name   string
+		off    int
+		size   int
+		shared bool
+	}{
+		{name: "large", off: 0, size: 200 << 10, shared: true},
+		{name: "whole slot", off: 200 << 10, size: 256 << 10, shared: true},
+		{name: "small", off: 456 << 10, size: 100, shared: false},
+		{name: "larger than a slot", off: 0, size: 300 << 10, shared: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := f.client.Stats()
+			f.write(t, uint64(tt.off), data[tt.off:tt.off+tt.size])
+			after := f.client.Stats()
+			if tt.shared {
+				assert.Equal(t, before.Shared+1, after.Shared)
+			} else {
+				assert.Equal(t, before.Inline+1, after.Inline)
+			}
+		})
+	}
+
+	// Every read takes a slot; those larger than it come back inline.
+	for _, size := range []int{100, 64 << 10, 256 << 10, 300 << 10} {
+		assert.True(t, bytes.Equal(data[:size], f.read(t, 0, size)), "read of %d", size)
+	}
+	// Past the end of the file.
+	assert.Empty(t, f.read(t, 1<<20, 4096))
+	// Up to the end of the file.
+	assert.True(t, bytes.Equal(data[400<<10:456<<10+100], f.read(t, 400<<10, 256<<10)))
+	// All slots are back.
+	assert.Equal(t, 4, f.client.FreeSlots())
+}
+
+func TestConcurrent(t *testing.T) {
+	f := newFixture(t, &shm.Options{Size: 128 << 10, SlotSize: 64 << 10, MinSize: 1}, true)
+	data := pattern(64 << 10)
+	f.write(t, 0, data)
+	done := make(chan bool)
+	for i := 0; i < 8; i++ {
+		go func() {
+			ok := true
+			for j := 0; j < 20; j++ {
+				buf := make([]byte, len(data))
+				res, st := f.pair.Client.Read(nil, &fuse.ReadIn{InHeader: fuse.InHeader{NodeId: f.node}, Fh: f.fh, Size: uint32(len(data))}, buf)
+				got, _ := res.Bytes(buf)
+				ok = ok && st == fuse.OK && bytes.Equal(data, got)
+			}
+			done <- ok
+		}()
+	}
+	for i := 0; i < 8; i++ {
+		assert.True(t, <-done)
+	}
+	// With two slots, some reads went inline.
+	assert.Greater(t, f.client.Stats().Inline, int64(0))
+	assert.Greater(t, f.client.Stats().Shared, int64(0))
+}
+
+func TestServerWithoutInterceptors(t *testing.T) {
+	f := newFixture(t, &shm.Options{Size: 1 << 20, MinSize: 1}, false)
+	data := pattern(100 << 10)
+	f.write(t, 0, data)
+	assert.Equal(t, data, f.read(t, 0, len(data)))
+	assert.Equal(t, int64(0), f.client.Stats().Shared)
+
+	_, ok := f.client.Session()
+	assert.False(t, ok)
+}
+
+func TestLostSession(t *testing.T) {
+	f := newFixture(t, &shm.Options{Size: 1 << 20, MinSize: 1}, true)
+	data := pattern(100 << 10)
+	f.write(t, 0, data)
+
+	// The server forgets the session, as if restarted; the client
+	// registers again.
+	token, _ := f.client.Session()
+	f.server.Forget(token)
+
+	f.write(t, 0, data)
+	assert.Equal(t, data, f.read(t, 0, len(data)))
+	newToken, ok := f.client.Session()
+	assert.True(t, ok)
+	assert.NotEqual(t, token, newToken)
+	assert.Equal(t, shm.Stats{Shared: 3}, f.client.Stats())
+}
+
+func TestRegisterErrors(t *testing.T) {
+	_, err := shm.Dial(filepath.Join(t.TempDir(), "nope"), nil)
+	assert.Error(t, err)
+
+	_, err = shm.Dial(filepath.Join(t.TempDir(), "nope"), &shm.Options{Size: 10, SlotSize: 20})
+	assert.Error(t, err)
+
+	// A client sending no descriptor.
+	s := shm.NewServer()
+	path := filepath.Join(t.TempDir(), "sock")
+	l, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	defer l.Close()
+	go s.Serve(l)
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte{0})
+	require.NoError(t, err)
+	reply := make([]byte, 100)
+	n, _ := conn.Read(reply)
+	assert.Contains(t, string(reply[:n]), "error ")
+	conn.Close()
+	assert.NoError(t, s.Close())
+}
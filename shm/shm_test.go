@@ -0,0 +1,53 @@
+package shm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegion(t *testing.T) {
+	mem := make([]byte, 100)
+	tests := []struct {
+		s    string
+		want region
+		err  bool
+		out  bool
+	}{
+		{s: "0:100", want: region{0, 100}},
+		{s: "40:10", want: region{40, 10}},
+		{s: "100:0", want: region{100, 0}},
+		{s: "90:11", want: region{90, 11}, out: true},
+		{s: "101:0", want: region{101, 0}, out: true},
+		{s: "1", err: true},
+		{s: "-1:3", err: true},
+		{s: "1:x", err: true},
+		{s: "1:2:3", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			r, err := parseRegion(tt.s)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, r)
+			assert.Equal(t, tt.s, r.String())
+			b, err := r.in(mem)
+			if tt.out {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, b, r.len)
+			assert.Equal(t, r.len, cap(b))
+		})
+	}
+}
+
+func TestOptions(t *testing.T) {
+	assert.Equal(t, Options{Size: 64 << 20, SlotSize: 1 << 20, MinSize: 16 << 10}, (*Options)(nil).withDefaults())
+	assert.Equal(t, Options{Size: 1 << 20, SlotSize: 1 << 20, MinSize: 1}, (&Options{Size: 1 << 20, MinSize: 1}).withDefaults())
+}